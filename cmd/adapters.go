@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/memorypilot/memorypilot/internal/adapters"
+	"github.com/spf13/cobra"
+)
+
+var adaptersCmd = &cobra.Command{
+	Use:   "adapters [path]",
+	Short: "Write project context for aider and continue.dev",
+	Long: `Detect coding tools configured in a project and write MemoryPilot
+context where they'll load it:
+
+  aider        .aider.memorypilot.md, registered under read: in .aider.conf.yml
+  continue     .continue/rules/memorypilot.md
+
+The daemon does this hourly for every known repository.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := os.Getwd()
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		dir, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+
+		detected := adapters.Detected(dir)
+		if len(detected) == 0 {
+			fmt.Println("🔍 No supported tools configured in", dir)
+			fmt.Println("   Supported: aider (.aider.conf.yml), continue.dev (.continue/)")
+			return nil
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		budget, _ := cmd.Flags().GetInt("budget")
+		written, err := adapters.Sync(s, dir, budget)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range detected {
+			fmt.Printf("🔌 Detected %s\n", a.Name())
+		}
+		if len(written) == 0 {
+			fmt.Println("✅ Tool context already up to date")
+			return nil
+		}
+		for _, path := range written {
+			fmt.Printf("✅ Wrote %s\n", path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	adaptersCmd.Flags().IntP("budget", "b", adapters.DefaultBudget, "Token budget for the written context")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/rag"
+	"github.com/spf13/cobra"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask [question]",
+	Short: "Ask a question and get an answer synthesized from your memories",
+	Long: `Retrieve the most relevant memories and have the LLM answer the question
+from them, citing the memory IDs it relied on.
+
+Examples:
+  memorypilot ask "why did we pick sqlite?"
+  memorypilot ask --limit 12 "how do we deploy the API?"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		question := strings.Join(args, " ")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		memories, err := searchMemories(cmd, s, question, embedQuery(cmd, question))
+		if err != nil {
+			return err
+		}
+
+		model, _ := cmd.Flags().GetString("model")
+		answer, err := rag.Ask(extractor.NewOllamaExtractor("", model), question, memories)
+		if err != nil {
+			return fmt.Errorf("failed to generate answer: %w", err)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, _ := json.MarshalIndent(answer, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("💬 %s\n", answer.Text)
+		if len(answer.Citations) > 0 {
+			fmt.Println()
+			fmt.Println("📚 Sources")
+			for _, m := range answer.Citations {
+				fmt.Printf("   %s %s [%s] %s\n", getTypeEmoji(m.Type), m.ID, m.Type, m.Summary)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	askCmd.Flags().IntP("limit", "l", 8, "Number of memories to retrieve as context")
+	askCmd.Flags().StringP("type", "t", "", "Only use memories of this type")
+	askCmd.Flags().StringSliceP("scope", "s", []string{}, "Only use memories in these scopes")
+	askCmd.Flags().BoolP("semantic", "S", true, "Use semantic search (requires Ollama)")
+	askCmd.Flags().String("model", "llama3.2", "Ollama model used to write the answer")
+	askCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/guard"
+	"github.com/memorypilot/memorypilot/internal/review"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var ciContextCmd = &cobra.Command{
+	Use:   "ci-context",
+	Short: "Render memories relevant to a diff as a PR comment",
+	Long: `Given a diff, emit the decisions, patterns and known mistakes related to
+the changed files as a markdown pull request comment body.
+
+Intended for CI: run 'memorypilot team refresh' first and the team cache is
+used; otherwise the default memory database is read. Nothing is written.
+With MEMORYPILOT_REMOTE_URL set, the remote team server is queried instead
+and no local database is needed.
+
+Examples:
+  git diff origin/main... > diff.patch
+  memorypilot ci-context --pr-diff diff.patch > comment.md
+  gh pr diff | memorypilot ci-context --pr-diff - --no-semantic
+  memorypilot ci-context --pr-diff diff.patch --github`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		diffPath, _ := cmd.Flags().GetString("pr-diff")
+		if diffPath == "" {
+			return fmt.Errorf("--pr-diff is required")
+		}
+
+		var diff []byte
+		var err error
+		if diffPath == "-" {
+			diff, err = io.ReadAll(os.Stdin)
+		} else {
+			diff, err = os.ReadFile(diffPath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read diff: %w", err)
+		}
+
+		var findings []review.Finding
+		chunks := guard.SplitDiff(string(diff))
+		client, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if client != nil {
+			findings, err = review.RelevantRemote(client, chunks)
+		} else {
+			findings, err = relevantLocal(cmd, chunks)
+		}
+		if err != nil {
+			return err
+		}
+
+		github, _ := cmd.Flags().GetBool("github")
+		if github {
+			for _, f := range findings {
+				for _, file := range f.Files {
+					fmt.Println(githubAnnotation(file, f.Memory))
+				}
+			}
+			return nil
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, _ := json.MarshalIndent(findings, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Print(review.Comment(findings))
+		return nil
+	},
+}
+
+// relevantLocal matches the diff against a local database, falling back to
+// file mentions when the embedder is unavailable
+func relevantLocal(cmd *cobra.Command, chunks []guard.Chunk) ([]review.Finding, error) {
+	s, err := openCIStore(cmd)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	var emb embedding.Embedder
+	if noSemantic, _ := cmd.Flags().GetBool("no-semantic"); !noSemantic {
+		emb = configuredEmbedder()
+	}
+
+	threshold, _ := cmd.Flags().GetFloat32("threshold")
+	findings, err := review.Relevant(s, emb, chunks, threshold)
+	if err != nil && emb != nil {
+		fmt.Fprintf(os.Stderr, "Warning: semantic matching unavailable (%v), using file mentions only\n", err)
+		findings, err = review.Relevant(s, nil, chunks, threshold)
+	}
+	return findings, err
+}
+
+// openCIStore opens the database named by --db, else the team cache, else
+// the default memory database
+func openCIStore(cmd *cobra.Command) (*store.Store, error) {
+	dbPath, _ := cmd.Flags().GetString("db")
+	if dbPath == "" {
+		dbPath = getDataDir() + "/memories.db"
+		if _, err := os.Stat(getTeamCachePath()); err == nil {
+			dbPath = getTeamCachePath()
+		}
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("no memory database at %s (run 'memorypilot team refresh' first)", dbPath)
+	}
+	return store.New(dbPath)
+}
+
+func init() {
+	ciContextCmd.Flags().String("pr-diff", "", "Unified diff to analyze (- for stdin)")
+	ciContextCmd.Flags().String("db", "", "Memory database to read (default: team cache, then personal)")
+	ciContextCmd.Flags().Float32("threshold", review.DefaultThreshold, "Minimum similarity for semantic matches (0-1)")
+	ciContextCmd.Flags().Bool("no-semantic", false, "Match on file mentions only (no Ollama needed)")
+	ciContextCmd.Flags().Bool("json", false, "Output findings as JSON")
+	ciContextCmd.Flags().Bool("github", false, "Output as GitHub Actions annotations on the changed files")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/analysis"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/spf13/cobra"
+)
+
+var clustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Group memories by meaning to map what MemoryPilot knows",
+	Long: `Cluster memories by their embeddings (k-means over cosine distance) and
+list the clusters by size. Clusters are labelled with their most common
+topics, or with a short name written by the LLM when --llm is set.
+
+Examples:
+  memorypilot clusters
+  memorypilot clusters -k 8 --llm`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		memories, err := s.EmbeddedMemories()
+		if err != nil {
+			return fmt.Errorf("failed to load embeddings: %w", err)
+		}
+
+		k, _ := cmd.Flags().GetInt("k")
+		clusters := analysis.ClusterMemories(memories, k)
+
+		useLLM, _ := cmd.Flags().GetBool("llm")
+		if useLLM && len(clusters) > 0 {
+			model, _ := cmd.Flags().GetString("model")
+			if err := analysis.LabelClusters(extractor.NewOllamaExtractor("", model), clusters); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: LLM labels unavailable (%v), using topics\n", err)
+			}
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			if clusters == nil {
+				clusters = []analysis.Cluster{}
+			}
+			data, _ := json.MarshalIndent(clusters, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(clusters) == 0 {
+			fmt.Println("🔍 No memories with embeddings to cluster yet")
+			return nil
+		}
+
+		total := 0
+		for _, c := range clusters {
+			total += c.Size
+		}
+
+		samples, _ := cmd.Flags().GetInt("samples")
+		fmt.Printf("🗺️  %d memories in %d clusters\n", total, len(clusters))
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for _, c := range clusters {
+			share := float64(c.Size) / float64(total)
+			bar := strings.Repeat("█", int(share*20+0.5))
+			fmt.Printf("\n%4d  %-20s  %s\n", c.Size, bar, c.Label)
+			fmt.Printf("      %s | cohesion %.2f\n", typeBreakdown(c.Types), c.Cohesion)
+			for i, m := range c.Members {
+				if i == samples {
+					break
+				}
+				fmt.Printf("      %s %s\n", getTypeEmoji(m.Type), m.Summary)
+			}
+		}
+		return nil
+	},
+}
+
+// typeBreakdown lists memory types by count, e.g. "5 fact, 2 decision"
+func typeBreakdown(types map[string]int) string {
+	names := make([]string, 0, len(types))
+	for t := range types {
+		names = append(names, t)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if types[names[i]] != types[names[j]] {
+			return types[names[i]] > types[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, t := range names {
+		parts[i] = fmt.Sprintf("%d %s", types[t], t)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	clustersCmd.Flags().IntP("k", "k", 0, "Number of clusters (0 picks one from the number of memories)")
+	clustersCmd.Flags().Bool("llm", false, "Name clusters with the LLM instead of their top topics")
+	clustersCmd.Flags().String("model", "llama3.2", "Ollama model used for --llm labels")
+	clustersCmd.Flags().Int("samples", 3, "Example memories shown per cluster")
+	clustersCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/memorypilot/memorypilot/internal/agent"
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/daemon"
+	"github.com/memorypilot/memorypilot/internal/privacy"
 	"github.com/spf13/cobra"
 )
 
@@ -20,35 +26,132 @@ var daemonStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the MemoryPilot daemon",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		pidPath := getPIDPath()
+		if info, ok := daemon.Running(pidPath); ok {
+			return fmt.Errorf("daemon already running (PID %d)", info.PID)
+		}
+		if detach, _ := cmd.Flags().GetBool("detach"); detach {
+			return startDetached(cmd, pidPath)
+		}
+
 		fmt.Println("🧠 Starting MemoryPilot daemon...")
-		
+
 		// Create and start the agent
 		cfg := agent.DefaultConfig()
 		cfg.DataDir = getDataDir()
-		
+		cfg.ConfigPath = getConfigPath()
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.MinConfidence = settings.MinConfidence
+		cfg.ReviewBelow = settings.ReviewBelow
+		cfg.DedupSimilarity = settings.DedupSimilarity
+		cfg.MinSignificance = settings.MinSignificance
+		cfg.BatchSize = settings.BatchSize
+		cfg.BatchWait = settings.BatchWait
+		cfg.GitInterval = settings.GitInterval
+		cfg.SearchWeights = settings.Search
+		cfg.Providers = settings.Providers
+		cfg.ExtractionModel = settings.Model
+		cfg.ClaudeAPIKey = settings.ClaudeAPIKey
+		cfg.ClaudeDailyBudget = settings.ClaudeDailyBudget
+		cfg.EmbeddingProviders = settings.EmbeddingProviders
+		cfg.EmbeddingModel = settings.EmbeddingModel
+		cfg.LocalModelDir = getLocalModelDir()
+		cfg.DisableGit = !settings.GitEnabled
+		cfg.GitAuthors = settings.GitAuthors
+		cfg.GitTeamCapture = settings.GitTeamCapture
+		cfg.GitStashes = settings.GitStashes
+		cfg.DisableFile = !settings.FileEnabled
+		cfg.FileDebounce = settings.FileDebounce
+		cfg.FileIgnore = settings.FileIgnore
+		cfg.DisableTerminal = !settings.TermEnabled
+		cfg.HistoryFiles = settings.HistoryFiles
+		cfg.ScanBudget = settings.ScanBudget
+		cfg.APIAddr = settings.APIAddr()
+		cfg.APIToken = settings.APIToken
+		cfg.Offline = settings.Offline
+		cfg.AggregateInsights = settings.InsightsEnabled
+		cfg.Insights = insightsOptions(settings)
+		cfg.ExpiryAction = settings.ExpiryAction
+		if cfg.RetentionPolicies, err = loadRetentionPolicies(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		cfg.SyncEndpoint = os.Getenv("MEMORYPILOT_SYNC_ENDPOINT")
+		cfg.SyncToken = os.Getenv("MEMORYPILOT_SYNC_TOKEN")
+		cfg.AllowedSigners = getAllowedSignersPath()
+		cfg.CaptureQuietAfter, _ = cmd.Flags().GetDuration("capture-alert-after")
+		cfg.DesktopNotify, _ = cmd.Flags().GetBool("notify")
+		if os.Getenv("MEMORYPILOT_NOTIFY") != "" {
+			cfg.DesktopNotify = true
+		}
+		cfg.TmuxSessions, _ = cmd.Flags().GetStringSlice("tmux-session")
+		if sessions := os.Getenv("MEMORYPILOT_TMUX_SESSIONS"); sessions != "" {
+			cfg.TmuxSessions = append(cfg.TmuxSessions, strings.Split(sessions, ",")...)
+		}
+		cfg.RemoteHosts, _ = cmd.Flags().GetStringSlice("remote")
+		if hosts := os.Getenv("MEMORYPILOT_REMOTE_HOSTS"); hosts != "" {
+			cfg.RemoteHosts = append(cfg.RemoteHosts, strings.Split(hosts, ",")...)
+		}
+		cfg.RemindExpiring, _ = cmd.Flags().GetDuration("remind-expiring")
+		if v := os.Getenv("MEMORYPILOT_REMIND_EXPIRING"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid MEMORYPILOT_REMIND_EXPIRING: %w", err)
+			}
+			cfg.RemindExpiring = d
+		}
+		cfg.ReminderCmd, _ = cmd.Flags().GetString("reminder-cmd")
+		if v := os.Getenv("MEMORYPILOT_REMINDER_CMD"); v != "" {
+			cfg.ReminderCmd = v
+		}
+		if cfg.ReminderCmd != "" && cfg.RemindExpiring == 0 {
+			cfg.RemindExpiring = 24 * time.Hour
+		}
+		if cmd.Flags().Changed("api-addr") {
+			cfg.APIAddr, _ = cmd.Flags().GetString("api-addr")
+		}
+		if offline, _ := cmd.Flags().GetBool("offline"); offline {
+			cfg.Offline = true
+		}
+
 		a, err := agent.New(cfg)
 		if err != nil {
 			return fmt.Errorf("failed to create agent: %w", err)
 		}
-		
+
 		// Start the agent
 		if err := a.Start(); err != nil {
 			return fmt.Errorf("failed to start agent: %w", err)
 		}
-		
+		if err := daemon.WritePID(pidPath); err != nil {
+			fmt.Printf("⚠️  Failed to write PID file: %v\n", err)
+		}
+		defer daemon.RemovePID(pidPath)
+
 		fmt.Println("✅ MemoryPilot daemon started")
 		fmt.Println("   Watching for events...")
+		if cfg.APIAddr != "" {
+			fmt.Printf("   🌐 API on http://%s\n", cfg.APIAddr)
+		}
+		if cfg.Offline {
+			fmt.Println("   📴 Offline: extraction deferred until restarted online")
+		}
+		if privacy.Enabled() {
+			fmt.Println("   🔒 Local-only: no content leaves this machine")
+		}
 		fmt.Println("   Press Ctrl+C to stop")
-		
+
 		// Wait for shutdown signal
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
-		
+
 		fmt.Println("\n🛑 Shutting down...")
 		a.Stop()
 		fmt.Println("✅ MemoryPilot daemon stopped")
-		
+
 		return nil
 	},
 }
@@ -57,8 +160,16 @@ var daemonStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the MemoryPilot daemon",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// TODO: Implement daemon stop via PID file or IPC
-		fmt.Println("Stopping MemoryPilot daemon...")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		info, err := daemon.Stop(getPIDPath(), timeout)
+		if errors.Is(err, daemon.ErrNotRunning) {
+			fmt.Println("⚪ MemoryPilot daemon is not running")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🛑 MemoryPilot daemon stopped (PID %d, up %s)\n", info.PID, info.Uptime())
 		return nil
 	},
 }
@@ -67,14 +178,84 @@ var daemonStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check daemon status",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// TODO: Implement status check
-		fmt.Println("Checking MemoryPilot daemon status...")
+		info, ok := daemon.Running(getPIDPath())
+		if !ok {
+			fmt.Println(getStatusEmoji(false))
+			fmt.Println("   Start it with 'memorypilot daemon start --detach'")
+			return nil
+		}
+		fmt.Println(getStatusEmoji(true))
+		fmt.Printf("   PID:     %d\n", info.PID)
+		fmt.Printf("   Uptime:  %s\n", info.Uptime())
+		fmt.Printf("   Started: %s\n", info.Started.Local().Format("2006-01-02 15:04:05"))
+		if _, err := os.Stat(getLogPath()); err == nil {
+			fmt.Printf("   Logs:    %s\n", getLogPath())
+		}
 		return nil
 	},
 }
 
+// startDetached re-runs 'daemon start' in the background, logging to the
+// log file, and waits for it to write its PID file
+func startDetached(cmd *cobra.Command, pidPath string) error {
+	if _, err := loadSettings(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	logPath, _ := cmd.Flags().GetString("log-file")
+	if logPath == "" {
+		logPath = getLogPath()
+	}
+	logPath = config.ExpandHome(logPath)
+
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if arg == "--detach" || strings.HasPrefix(arg, "--detach=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+	pid, err := daemon.Detach(args, logPath)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if info, ok := daemon.Running(pidPath); ok && info.PID == pid {
+			fmt.Printf("✅ MemoryPilot daemon started in the background (PID %d)\n", pid)
+			fmt.Printf("   📄 Logs: %s\n", logPath)
+			fmt.Println("   Stop it with 'memorypilot daemon stop'")
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("daemon (PID %d) did not start; see %s", pid, logPath)
+}
+
+// getPIDPath returns the daemon's PID file path
+func getPIDPath() string {
+	return getConfigDir() + "/" + daemon.PIDFileName
+}
+
+// getLogPath returns the default log file of a detached daemon
+func getLogPath() string {
+	return getConfigDir() + "/logs/" + daemon.LogFileName
+}
+
 func init() {
 	daemonCmd.AddCommand(daemonStartCmd)
 	daemonCmd.AddCommand(daemonStopCmd)
 	daemonCmd.AddCommand(daemonStatusCmd)
+
+	daemonStartCmd.Flags().Bool("detach", false, "Run in the background, logging to ~/.memorypilot/logs/daemon.log")
+	daemonStartCmd.Flags().String("log-file", "", "Log file for --detach (default ~/.memorypilot/logs/daemon.log)")
+	daemonStartCmd.Flags().Bool("offline", false, "Capture events but defer extraction (also MEMORYPILOT_OFFLINE)")
+	daemonStartCmd.Flags().Duration("capture-alert-after", agent.DefaultConfig().CaptureQuietAfter, "Warn when a normally active watcher is silent this long (0 disables)")
+	daemonStartCmd.Flags().Bool("notify", false, "Show capture alerts as desktop notifications (also MEMORYPILOT_NOTIFY)")
+	daemonStartCmd.Flags().StringSlice("tmux-session", nil, "Capture REPL and ssh commands typed in this tmux session (repeatable, also MEMORYPILOT_TMUX_SESSIONS)")
+	daemonStartCmd.Flags().Duration("remind-expiring", 0, "Remind this long before a memory expires, e.g. 24h (also MEMORYPILOT_REMIND_EXPIRING)")
+	daemonStartCmd.Flags().String("reminder-cmd", "", "Shell command run for each reminder, with MEMORYPILOT_MEMORY_* set (also MEMORYPILOT_REMINDER_CMD)")
+	daemonStartCmd.Flags().String("api-addr", agent.DefaultConfig().APIAddr, "Serve the HTTP API on this address, overriding api.host/api.port (empty disables)")
+	daemonStartCmd.Flags().StringSlice("remote", nil, "Capture events from 'memorypilot remote-agent' on this ssh host (repeatable, also MEMORYPILOT_REMOTE_HOSTS)")
+	daemonStopCmd.Flags().Duration("timeout", 15*time.Second, "How long to wait for the daemon to exit")
 }
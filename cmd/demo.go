@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/demo"
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Try MemoryPilot on synthetic data",
+}
+
+var demoSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Fill a profile with realistic synthetic projects, events and memories",
+	Long: `Create a profile (default "demo") holding three synthetic projects, a month
+of commits and terminal commands, and the memories extracted from them.
+Your own memories are not touched.
+
+Memories are embedded with the configured embedder (Ollama by default) so
+semantic recall can be judged; without one, keyword recall still works.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("profile")
+		reset, _ := cmd.Flags().GetBool("reset")
+		if name == "" || name == "default" || name == teamCacheProfile || filepath.Base(name) != name {
+			return fmt.Errorf("invalid demo profile name %q", name)
+		}
+
+		dir := filepath.Join(getProfilesDir(), name)
+		dbPath := filepath.Join(dir, "memories.db")
+		if _, err := os.Stat(dbPath); err == nil {
+			if !reset {
+				return fmt.Errorf("profile %q already exists; use --reset to replace it", name)
+			}
+			for _, suffix := range []string{"", "-wal", "-shm"} {
+				if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		s, err := store.New(dbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open store: %w", err)
+		}
+		defer s.Close()
+
+		fmt.Printf("🌱 Seeding demo profile %q...\n", name)
+		emb := configuredEmbedder()
+		s.SetEmbeddingModel(embedding.ModelOf(emb))
+		sum, err := demo.Seed(s, emb, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to seed demo data: %w", err)
+		}
+
+		fmt.Printf("   ✓ %d projects\n", sum.Projects)
+		fmt.Printf("   ✓ %d events over %d days\n", sum.Events, demo.Days)
+		fmt.Printf("   ✓ %d memories\n", sum.Memories)
+		if sum.Embedded == 0 {
+			fmt.Println("   ⚠️  Embedder unavailable: memories were stored without embeddings (keyword recall only)")
+		} else {
+			fmt.Printf("   ✓ %d embeddings\n", sum.Embedded)
+		}
+
+		fmt.Println()
+		fmt.Println("Try it:")
+		fmt.Printf("  memorypilot --data-dir %s recall \"postgres connection problems\"\n", dir)
+		fmt.Printf("  memorypilot --data-dir %s status\n", dir)
+		fmt.Println("  memorypilot recall --all-profiles \"how do we deploy\"")
+		fmt.Printf("  memorypilot mcp --db %s   # point your AI tool's MCP config here\n", dbPath)
+		return nil
+	},
+}
+
+func init() {
+	demoCmd.AddCommand(demoSeedCmd)
+
+	demoSeedCmd.Flags().String("profile", "demo", "Profile to fill")
+	demoSeedCmd.Flags().Bool("reset", false, "Replace the profile if it already exists")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var devcontainerCmd = &cobra.Command{
+	Use:   "devcontainer",
+	Short: "Print devcontainer.json settings that share your memories with a container",
+	Long: `Print the settings to merge into .devcontainer/devcontainer.json so that
+MCP clients inside the container use the memory store on your machine.
+
+The host's data directory is bind-mounted into the container and
+MEMORYPILOT_DB points 'memorypilot mcp' at it. Keep the daemon running on
+the host; the container only serves memories. On Docker Desktop (macOS,
+Windows) the mount crosses a VM, where SQLite's locking is unreliable:
+avoid remembering from the container while the host daemon is writing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, _ := cmd.Flags().GetString("target")
+
+		mount := "source=${localEnv:HOME}${localEnv:USERPROFILE}/.memorypilot/data,target=" + target + ",type=bind"
+		dbPath := path.Join(target, "memories.db")
+
+		settings := map[string]interface{}{
+			"mounts": []string{mount},
+			"containerEnv": map[string]string{
+				"MEMORYPILOT_DB": dbPath,
+			},
+			"postCreateCommand": "go install github.com/memorypilot/memorypilot@latest",
+		}
+		data, _ := json.MarshalIndent(settings, "", "  ")
+
+		fmt.Println("📦 Add to .devcontainer/devcontainer.json:")
+		fmt.Println()
+		fmt.Println(string(data))
+		fmt.Println()
+		fmt.Println("🔌 MCP server config for tools inside the container:")
+		fmt.Println()
+		mcpConfig := map[string]interface{}{
+			"mcpServers": map[string]interface{}{
+				"memorypilot": map[string]interface{}{
+					"command": "memorypilot",
+					"args":    []string{"mcp", "--db", dbPath},
+				},
+			},
+		}
+		data, _ = json.MarshalIndent(mcpConfig, "", "  ")
+		fmt.Println(string(data))
+
+		if env := config.Container(); env != "" {
+			fmt.Println()
+			fmt.Printf("⚠️  Already inside a container (%s): rebuild it after adding these settings.\n", env)
+		}
+		return nil
+	},
+}
+
+func init() {
+	devcontainerCmd.Flags().String("target", "/memorypilot", "Where the data directory is mounted in the container")
+}
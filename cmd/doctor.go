@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/analysis"
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the memory database for problems",
+	Long: `Check the memory database for corruption and for dangling references,
+such as memories or events pointing at projects that no longer exist.
+Databases created by older versions, which didn't enforce foreign keys,
+may contain them.
+
+With --fix, a corrupt database is rebuilt from its readable rows plus the
+newest backup (the damaged file is kept), and dangling references are
+repaired.
+
+With --suggest-ignores, directories whose file changes have never led to a
+memory are listed, and on confirmation added to watchers.file.ignore in
+the config file.
+
+Examples:
+  memorypilot doctor
+  memorypilot doctor --fix
+  memorypilot doctor --suggest-ignores`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath := getDataDir() + "/memories.db"
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			fmt.Println("❌ MemoryPilot not initialized")
+			fmt.Println("   Run 'memorypilot init' to get started")
+			return nil
+		}
+
+		fix, _ := cmd.Flags().GetBool("fix")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if suggest, _ := cmd.Flags().GetBool("suggest-ignores"); suggest {
+			return suggestIgnores(cmd, jsonOutput)
+		}
+
+		result := struct {
+			Integrity string                `json:"integrity"`
+			Recovery  *store.RecoveryReport `json:"recovery,omitempty"`
+			Orphans   *store.Orphans        `json:"orphans,omitempty"`
+			Backups   []string              `json:"backups"`
+			Fixed     bool                  `json:"fixed"`
+		}{Integrity: "ok", Fixed: fix}
+		result.Backups, _ = store.ListBackups(dbPath)
+
+		var s *store.Store
+		var err error
+		if fix {
+			s, result.Recovery, err = store.OpenOrRecover(dbPath)
+			if result.Recovery != nil {
+				result.Integrity = "recovered"
+			}
+		} else {
+			s, err = store.New(dbPath)
+			if err == nil {
+				if err = s.CheckIntegrity(); err != nil {
+					s.Close()
+				}
+			}
+		}
+		if err != nil && !store.IsCorrupt(err) {
+			return err
+		}
+		if err != nil {
+			result.Integrity = err.Error()
+		} else {
+			defer s.Close()
+			if fix {
+				result.Orphans, err = s.FixOrphans()
+			} else {
+				result.Orphans, err = s.FindOrphans()
+			}
+			if err != nil {
+				return fmt.Errorf("reference check failed: %w", err)
+			}
+		}
+
+		if jsonOutput {
+			data, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Println("🩺 MemoryPilot Doctor")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+
+		switch {
+		case result.Recovery != nil:
+			fmt.Println("🛠️  Integrity: database was corrupt and has been rebuilt")
+			fmt.Printf("   %s\n", indent(strings.TrimSpace(result.Recovery.Summary()), "   "))
+			fmt.Printf("   Report: %s\n", result.Recovery.ReportPath)
+		case result.Integrity != "ok":
+			fmt.Printf("❌ Integrity: %s\n", result.Integrity)
+			fmt.Println("   Run 'memorypilot doctor --fix' to rebuild it (the damaged file is kept)")
+			return nil
+		default:
+			fmt.Println("✅ Integrity: ok")
+		}
+
+		if len(result.Backups) == 0 {
+			fmt.Println("💾 Backups: none yet (the daemon takes one daily)")
+		} else {
+			fmt.Printf("💾 Backups: %d, newest %s\n", len(result.Backups), filepath.Base(result.Backups[0]))
+		}
+
+		orphans := result.Orphans
+		if orphans.Total() == 0 {
+			fmt.Println("✅ References: no orphaned rows")
+			return nil
+		}
+
+		verb := "found"
+		if fix {
+			verb = "repaired"
+		}
+		fmt.Printf("⚠️  References: %s %d orphaned rows\n", verb, orphans.Total())
+		printOrphans("memories linked to a missing project", orphans.Memories)
+		printOrphans("events linked to a missing project", orphans.Events)
+		printOrphans("facts of a missing project", orphans.ProjectFacts)
+		printOrphans("annotations of a missing memory", orphans.Annotations)
+		if !fix {
+			fmt.Println("\n   Run 'memorypilot doctor --fix' to repair them")
+		}
+		return nil
+	},
+}
+
+// suggestIgnores lists unproductive directory names and offers to add
+// them to the file watcher's ignore list
+func suggestIgnores(cmd *cobra.Command, jsonOutput bool) error {
+	s, err := openStore()
+	if err != nil || s == nil {
+		return err
+	}
+	defer s.Close()
+
+	settings, err := loadSettings()
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	yields, err := s.DirYields()
+	if err != nil {
+		return fmt.Errorf("failed to load directory stats: %w", err)
+	}
+	projects, err := s.ListProjects()
+	if err != nil {
+		return fmt.Errorf("failed to list projects: %w", err)
+	}
+	roots := make([]string, len(projects))
+	for i, p := range projects {
+		roots[i] = p.Path
+	}
+	minEvents, _ := cmd.Flags().GetInt("min-events")
+	suggestions := analysis.SuggestIgnores(yields, roots, settings.FileIgnore, minEvents)
+
+	if jsonOutput {
+		if suggestions == nil {
+			suggestions = []analysis.IgnoreSuggestion{}
+		}
+		data, _ := json.MarshalIndent(suggestions, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Println("✅ No directories to ignore: every busy directory has led to memories")
+		return nil
+	}
+
+	fmt.Printf("🔇 %d directories produce file events that never lead to memories:\n\n", len(suggestions))
+	names := make([]string, len(suggestions))
+	for i, sg := range suggestions {
+		names[i] = sg.Name
+		fmt.Printf("   %-24s %5d events, e.g. %s\n", sg.Name, sg.Events, strings.Join(sg.Dirs, ", "))
+	}
+	fmt.Println()
+
+	configPath := getConfigPath()
+	if !confirm(fmt.Sprintf("Add them to watchers.file.ignore in %s? [y/N] ", configPath)) {
+		fmt.Println("   Cancelled")
+		return nil
+	}
+	added, err := config.AppendList(configPath, "watchers.file.ignore", names)
+	if err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	fmt.Printf("✅ Added %d directories to the ignore list\n", len(added))
+	fmt.Println("   Restart the daemon to apply it ('memorypilot daemon stop', then start)")
+	return nil
+}
+
+func printOrphans(label string, n int) {
+	if n > 0 {
+		fmt.Printf("   %5d %s\n", n, label)
+	}
+}
+
+func init() {
+	doctorCmd.Flags().Bool("fix", false, "Repair problems that were found")
+	doctorCmd.Flags().Bool("json", false, "Output as JSON")
+	doctorCmd.Flags().Bool("suggest-ignores", false, "Suggest directories to ignore because they never yield memories")
+	doctorCmd.Flags().Int("min-events", analysis.MinIgnoreEvents, "Events a directory needs before it is suggested")
+}
@@ -0,0 +1,449 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/memorypilot/memorypilot/internal/analysis"
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/vectors"
+	"github.com/spf13/cobra"
+)
+
+var embeddingsCmd = &cobra.Command{
+	Use:   "embeddings",
+	Short: "Manage memory embeddings",
+	Long: `Embeddings power semantic recall. Memories created while the embedding
+model was unreachable have none, and are only found by keywords until they
+are backfilled.
+
+Embeddings are cached by model and text in the store, so identical texts are
+embedded once. Entries unused for 90 days are pruned by the daemon.
+
+Embeddings from different models live in different spaces: after switching
+models, memories embedded before the switch drop out of semantic recall.
+'embeddings check' measures this drift, and 'embeddings backfill --all'
+re-embeds every memory with the current model.
+
+Each embedding is stored with the model and dimension it came from. To
+switch the Ollama embedding model, 'embeddings migrate --model' re-embeds
+every memory with the new one before making it the configured model, so
+vectors from the two are never mixed.`,
+}
+
+var embeddingsBackfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Embed memories that have no embedding",
+	Long: `Find memories without an embedding (e.g. created while Ollama was down)
+and embed them in batches with the configured embedding providers. An
+interrupted backfill picks up where it stopped when run again.
+
+With --all, every memory is re-embedded, as needed after switching
+embedding models.
+
+Summaries, topics and chunks of long content, embedded as vectors of their
+own for recall, are backfilled too; the daemon also embeds them every few
+minutes.`,
+	Example: `  memorypilot embeddings backfill
+  memorypilot embeddings backfill --batch 64 --limit 500
+  memorypilot embeddings backfill --all
+  memorypilot embeddings backfill --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		chain, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(settings.EmbeddingModel))
+		if err != nil {
+			return fmt.Errorf("invalid embedding providers: %w", err)
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+		s.SetEmbeddingModel(chain.Model())
+
+		all, _ := cmd.Flags().GetBool("all")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		batch, _ := cmd.Flags().GetInt("batch")
+		if batch <= 0 {
+			batch = 32
+		}
+		emb := embedding.Cached(chain, s)
+
+		total, err := s.CountMemoriesToEmbed(all)
+		if err != nil {
+			return fmt.Errorf("failed to count memories: %w", err)
+		}
+		if total == 0 && all {
+			fmt.Println("✅ No memories to embed")
+			return nil
+		}
+		if total == 0 {
+			fmt.Println("✅ Every memory has an embedding")
+			return backfillVectors(s, emb, batch, dryRun)
+		}
+		if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 && limit < total {
+			total = limit
+		}
+		if dryRun {
+			fmt.Printf("🧮 Would embed %d memories with %s\n", total, chain.Model())
+			return nil
+		}
+
+		fmt.Printf("🧮 Embedding %d memories with %s...\n", total, chain.Model())
+		var embedded, skipped int
+		after := ""
+		for embedded+skipped < total {
+			memories, err := s.MemoriesToEmbed(all, after, min(batch, total-embedded-skipped))
+			if err != nil {
+				return fmt.Errorf("failed to load memories: %w", err)
+			}
+			if len(memories) == 0 {
+				break
+			}
+			after = memories[len(memories)-1].ID
+
+			texts := make([]string, len(memories))
+			for i, m := range memories {
+				texts[i] = m.Content
+			}
+			vectors, err := emb.EmbedBatch(texts)
+			if err != nil {
+				return fmt.Errorf("embedding failed after %d of %d memories (run again to resume): %w", embedded, total, err)
+			}
+			for i, m := range memories {
+				if len(vectors[i]) == 0 {
+					skipped++
+					continue
+				}
+				if err := s.UpdateMemoryEmbedding(m.ID, vectors[i]); err != nil {
+					return fmt.Errorf("failed to store embedding of %s: %w", m.ID, err)
+				}
+				embedded++
+			}
+			fmt.Printf("   %d/%d (%d%%)\n", embedded+skipped, total, (embedded+skipped)*100/total)
+		}
+
+		// A complete re-embed leaves a single embedding space
+		if all && embedded+skipped == total && skipped == 0 {
+			if _, err := s.RecordEmbeddingModel(chain.Model()); err != nil {
+				return fmt.Errorf("failed to record embedding model: %w", err)
+			}
+		}
+
+		fmt.Printf("\n✅ Embedded %d memories\n", embedded)
+		if skipped > 0 {
+			fmt.Printf("⚠️  %d got no embedding: every provider fell through to null\n", skipped)
+		}
+		return backfillVectors(s, emb, batch, false)
+	},
+}
+
+// backfillVectors embeds the summaries, topics and long content chunks of
+// embedded memories that lack their vectors
+func backfillVectors(s *store.Store, emb embedding.Embedder, batch int, dryRun bool) error {
+	missing, err := s.CountMissingVectors(embedding.ModelOf(emb))
+	if err != nil {
+		return fmt.Errorf("failed to count memories: %w", err)
+	}
+	if missing == 0 {
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("🧮 Would embed the summaries, topics and chunks of %d memories\n", missing)
+		return nil
+	}
+	fmt.Printf("🧮 Embedding the summaries, topics and chunks of %d memories...\n", missing)
+	n, err := vectors.Fill(s, emb, 0, batch)
+	if err != nil {
+		return fmt.Errorf("embedding summaries, topics and chunks failed after %d vectors (run again to resume): %w", n, err)
+	}
+	fmt.Printf("✅ Embedded %d summary, topics and chunk vectors\n", n)
+	return nil
+}
+
+var embeddingsCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check embeddings for drift from the current model",
+	Long: `Sample embedded memories and re-embed them with the configured providers.
+Embeddings that no longer match came from another model, such as one used
+before switching, or a fallback provider in the chain. Semantic recall can't
+find those memories, which shows as vector search disagreeing with keyword
+search on the same queries.`,
+	Example: `  memorypilot embeddings check
+  memorypilot embeddings check --sample 200 --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		chain, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(settings.EmbeddingModel))
+		if err != nil {
+			return fmt.Errorf("invalid embedding providers: %w", err)
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		sample, _ := cmd.Flags().GetInt("sample")
+		drift, err := analysis.CheckDrift(s, embedding.Cached(chain, s), sample)
+		if err != nil {
+			return fmt.Errorf("drift check failed: %w", err)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, _ := json.MarshalIndent(struct {
+				*analysis.Drift
+				Drifted bool `json:"drifted"`
+			}{drift, drift.Drifted()}, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("🧮 Embedding model: %s\n", drift.Model)
+		if drift.RecordedModel != "" && drift.RecordedModel != drift.Model {
+			fmt.Printf("   The daemon last embedded with %s\n", drift.RecordedModel)
+		}
+		if drift.Sampled == 0 {
+			fmt.Printf("\nNo embeddings to check (%d memories embedded)\n", drift.Embedded)
+			return nil
+		}
+		fmt.Printf("   Sampled %d of %d embedded memories\n\n", drift.Sampled, drift.Embedded)
+		fmt.Printf("   From another model:  %d (%.0f%%)\n", drift.Foreign, drift.ForeignShare()*100)
+		if len(drift.Dimensions) > 1 {
+			fmt.Printf("   Dimensions:          %v (mixed)\n", drift.Dimensions)
+		}
+		if spaces, err := s.EmbeddingSpaces(); err == nil && len(spaces) > 1 {
+			fmt.Println("   Stored with:")
+			for _, sp := range spaces {
+				name := sp.Model
+				if name == "" {
+					name = "unknown model"
+				}
+				fmt.Printf("     %-38s %4d dims  %d memories\n", name, sp.Dimensions, sp.Memories)
+			}
+		}
+		fmt.Printf("   Keyword/vector agreement: %.0f%%", drift.Agreement*100)
+		if drift.Foreign > 0 && drift.Foreign < drift.Sampled {
+			fmt.Printf(" (%.0f%% for current embeddings)", drift.NativeAgreement*100)
+		}
+		fmt.Println()
+
+		if !drift.Drifted() {
+			fmt.Println("\n✅ Embeddings match the current model")
+			return nil
+		}
+		fmt.Println("\n⚠️  Embeddings from another model are invisible to semantic recall")
+		fmt.Println("   Re-embed every memory with the current model:")
+		fmt.Println("     memorypilot embeddings backfill --all")
+		return nil
+	},
+}
+
+var embeddingsMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Switch the embedding model, re-embedding every memory",
+	Long: `Re-embed every memory with another Ollama embedding model, then make it the
+configured model (embedding.model in config.yaml). Memories are re-embedded
+in batches; an interrupted migration picks up where it stopped when run
+again, and until it completes the previous model stays configured.
+
+Restart the daemon afterwards so new memories are embedded with the new
+model too.`,
+	Example: `  memorypilot embeddings migrate --model mxbai-embed-large
+  memorypilot embeddings migrate --model all-minilm --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		model, _ := cmd.Flags().GetString("model")
+		if model == "" {
+			return fmt.Errorf("--model is required")
+		}
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if !slices.Contains(settings.EmbeddingProviders, "ollama") {
+			return fmt.Errorf("the embedding model applies to the ollama provider, which isn't among the configured providers %v", settings.EmbeddingProviders)
+		}
+		chain, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(model))
+		if err != nil {
+			return fmt.Errorf("invalid embedding providers: %w", err)
+		}
+		target := chain.Model()
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		spaces, err := s.EmbeddingSpaces()
+		if err != nil {
+			return fmt.Errorf("failed to count embeddings: %w", err)
+		}
+		fmt.Println("🧮 Stored embeddings:")
+		if len(spaces) == 0 {
+			fmt.Println("   none")
+		}
+		for _, sp := range spaces {
+			name := sp.Model
+			if name == "" {
+				name = "unknown model"
+			}
+			fmt.Printf("   %-40s %4d dims  %d memories\n", name, sp.Dimensions, sp.Memories)
+		}
+
+		// Probe the model first, so an unavailable one fails before any
+		// memory is touched
+		probe, err := chain.Embed("memorypilot embedding probe")
+		if err != nil {
+			return fmt.Errorf("%s is unavailable: %w", target, err)
+		}
+		if len(probe) == 0 {
+			return fmt.Errorf("%s returned no embedding: is the model pulled in Ollama?", target)
+		}
+		dims := len(probe)
+
+		total, err := s.CountNotEmbeddedWith(target)
+		if err != nil {
+			return fmt.Errorf("failed to count memories: %w", err)
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			fmt.Printf("\n🧮 Would re-embed %d memories with %s (%d dims)\n", total, target, dims)
+			return nil
+		}
+
+		batch, _ := cmd.Flags().GetInt("batch")
+		if batch <= 0 {
+			batch = 32
+		}
+		s.SetEmbeddingModel(target)
+		emb := embedding.Cached(chain, s)
+
+		if total == 0 {
+			fmt.Printf("\n✅ Every memory is embedded with %s\n", target)
+		} else {
+			fmt.Printf("\n🧮 Re-embedding %d memories with %s (%d dims)...\n", total, target, dims)
+		}
+		done := 0
+		after := ""
+		for done < total {
+			memories, err := s.NotEmbeddedWith(target, after, batch)
+			if err != nil {
+				return fmt.Errorf("failed to load memories: %w", err)
+			}
+			if len(memories) == 0 {
+				break
+			}
+			after = memories[len(memories)-1].ID
+
+			texts := make([]string, len(memories))
+			for i, m := range memories {
+				texts[i] = m.Content
+			}
+			vectors, err := emb.EmbedBatch(texts)
+			if err != nil {
+				return fmt.Errorf("embedding failed after %d of %d memories (run again to resume): %w", done, total, err)
+			}
+			for i, m := range memories {
+				// A fallback provider answering instead would mix spaces again
+				if len(vectors[i]) != dims {
+					return fmt.Errorf("%s returned a %d-dimension embedding for %s after %d of %d memories (run again to resume)", target, len(vectors[i]), m.ID, done, total)
+				}
+				if err := s.UpdateMemoryEmbedding(m.ID, vectors[i]); err != nil {
+					return fmt.Errorf("failed to store embedding of %s: %w", m.ID, err)
+				}
+				done++
+			}
+			fmt.Printf("   %d/%d (%d%%)\n", done, total, done*100/total)
+		}
+
+		if err := backfillVectors(s, emb, batch, false); err != nil {
+			return err
+		}
+
+		configPath := getConfigPath()
+		if err := config.SetString(configPath, "embedding.model", model); err != nil {
+			return fmt.Errorf("re-embedded %d memories but failed to update %s: %w", done, configPath, err)
+		}
+		if _, err := s.RecordEmbeddingModel(target); err != nil {
+			return fmt.Errorf("failed to record embedding model: %w", err)
+		}
+
+		if done > 0 {
+			fmt.Printf("\n✅ Migrated to %s: re-embedded %d memories\n", target, done)
+		}
+		fmt.Printf("   Set embedding.model: %s in %s\n", model, configPath)
+		if env := os.Getenv("MEMORYPILOT_EMBEDDING_MODEL"); env != "" && env != model {
+			fmt.Printf("⚠️  MEMORYPILOT_EMBEDDING_MODEL=%s overrides it; update or unset it\n", env)
+		}
+		fmt.Println("   Restart the daemon to embed new memories with it ('memorypilot daemon stop', then start)")
+		return nil
+	},
+}
+
+var embeddingsInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the local embedding model",
+	Long: `Download all-MiniLM-L6-v2 (about 90 MB) for the "local" embedding provider,
+which embeds in process, so semantic recall works offline without Ollama.
+The model runs on a built-in BERT reading its safetensors weights rather
+than on ONNX Runtime, so no native library is needed.
+
+Machines without internet access can install from a directory holding the
+model's config.json, vocab.txt and model.safetensors with --from. Files are
+checked against the SHA-256 digests of the pinned model revision. The model
+is kept in models/ in the data directory, or MEMORYPILOT_LOCAL_MODEL if set.`,
+	Example: `  memorypilot embeddings install
+  memorypilot embeddings install --from ./all-MiniLM-L6-v2`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, _ := cmd.Flags().GetString("from")
+		dir := getLocalModelDir()
+
+		fmt.Printf("📦 Installing %s to %s...\n", embedding.LocalModelName, dir)
+		err := embedding.InstallLocalModel(source, dir, func(file string) {
+			fmt.Printf("   %s\n", file)
+		})
+		if err != nil {
+			return fmt.Errorf("install failed: %w", err)
+		}
+
+		fmt.Println("\n✅ Local embedding model installed")
+		fmt.Println("   Use it in config.yaml:")
+		fmt.Println("     embedding:")
+		fmt.Println("       providers: [local]")
+		fmt.Println("   then re-embed memories from the previous model:")
+		fmt.Println("     memorypilot embeddings backfill --all")
+		return nil
+	},
+}
+
+func init() {
+	embeddingsCmd.AddCommand(embeddingsBackfillCmd)
+	embeddingsCmd.AddCommand(embeddingsCheckCmd)
+	embeddingsCmd.AddCommand(embeddingsInstallCmd)
+	embeddingsCmd.AddCommand(embeddingsMigrateCmd)
+
+	embeddingsBackfillCmd.Flags().Int("batch", 32, "Memories embedded per batch")
+	embeddingsBackfillCmd.Flags().Int("limit", 0, "Embed at most this many memories (0 for all)")
+	embeddingsBackfillCmd.Flags().Bool("all", false, "Re-embed every memory, not only those without an embedding")
+	embeddingsBackfillCmd.Flags().Bool("dry-run", false, "Count memories to embed without embedding them")
+
+	embeddingsCheckCmd.Flags().Int("sample", 50, "Memories to sample")
+	embeddingsCheckCmd.Flags().Bool("json", false, "Output as JSON")
+
+	embeddingsMigrateCmd.Flags().String("model", "", "Ollama embedding model to switch to")
+	embeddingsMigrateCmd.Flags().Int("batch", 32, "Memories embedded per batch")
+	embeddingsMigrateCmd.Flags().Bool("dry-run", false, "Show stored embeddings and count memories to re-embed")
+
+	embeddingsInstallCmd.Flags().String("from", embedding.LocalModelURL, "URL or directory to install the model from")
+}
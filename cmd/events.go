@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/reprocess"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Manage captured events",
+}
+
+var eventsRetryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Re-run extraction of batches that failed",
+	Long: fmt.Sprintf(`Re-extract events whose extraction failed. The agent retries a failed
+batch itself, waiting longer after each attempt, and gives up after %d
+attempts; this retries every failed batch now, whether or not the agent
+gave up on it. Events are done with once their batch extracts.
+
+Examples:
+  memorypilot events retry --list
+  memorypilot events retry
+  memorypilot events retry --dry-run`, store.MaxExtractionAttempts),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		if list, _ := cmd.Flags().GetBool("list"); list {
+			failed, err := s.FailedEvents()
+			if err != nil {
+				return err
+			}
+			if len(failed) == 0 {
+				fmt.Println("No failed extractions.")
+				return nil
+			}
+			fmt.Printf("⚠️  %d events failed extraction:\n\n", len(failed))
+			for _, f := range failed {
+				next := "gave up"
+				if f.NextRetryAt != nil {
+					next = "next retry " + f.NextRetryAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Printf("   %s %s %s\n", f.Timestamp.Local().Format("2006-01-02 15:04"), f.Type, describeEvent(f.Event))
+				fmt.Printf("      %d attempts, %s: %s\n", f.Attempts, next, f.LastError)
+			}
+			return nil
+		}
+
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if settings.Offline {
+			return fmt.Errorf("extraction is offline (extraction.offline); retry once back online")
+		}
+		ext, err := extractor.NewChain(settings.Providers, extractor.ChainConfig{
+			OllamaModel:       settings.Model,
+			ClaudeAPIKey:      settings.ClaudeAPIKey,
+			ClaudeDailyBudget: settings.ClaudeDailyBudget,
+		})
+		if err != nil {
+			return err
+		}
+		ext.SetExamples(s)
+		ext.SetMinConfidence(settings.MinConfidence)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			ext.SetRunRecorder(s)
+		}
+		emb := configuredEmbedder()
+		s.SetEmbeddingModel(embedding.ModelOf(emb))
+
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		if batchSize <= 0 {
+			batchSize = settings.BatchSize
+		}
+		result, runErr := reprocess.Failed(s, ext, emb, reprocess.Options{
+			BatchSize:   batchSize,
+			DryRun:      dryRun,
+			ReviewBelow: settings.ReviewBelow,
+		})
+		if result == nil {
+			return fmt.Errorf("retry failed: %w", runErr)
+		}
+		if result.Events == 0 {
+			fmt.Println("No failed extractions.")
+			return nil
+		}
+
+		verb := "Added"
+		if dryRun {
+			verb = "Would add"
+		}
+		for _, m := range result.Added {
+			fmt.Printf("   %s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		}
+		fmt.Printf("\n✅ %s %d memories (%d events, %d batches, %d extracted, %d already known",
+			verb, len(result.Added), result.Events, result.Batches, result.Extracted, result.Duplicates)
+		if result.Failed > 0 {
+			fmt.Printf(", %d batches failed again", result.Failed)
+		}
+		fmt.Println(")")
+		if runErr != nil {
+			return fmt.Errorf("retry stopped early: %w", runErr)
+		}
+		return nil
+	},
+}
+
+func init() {
+	eventsRetryCmd.Flags().Bool("list", false, "List failed events without retrying them")
+	eventsRetryCmd.Flags().Bool("dry-run", false, "Show the memories without saving them")
+	eventsRetryCmd.Flags().Int("batch-size", 0, "Events per extraction batch (default: extraction.batchSize)")
+	eventsCmd.AddCommand(eventsRetryCmd)
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/analysis"
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/ical"
+	"github.com/memorypilot/memorypilot/internal/transfer"
+	"github.com/memorypilot/memorypilot/internal/vault"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export memories for use outside MemoryPilot",
+	Long: `Export memories for use outside MemoryPilot.
+
+By default every project and memory, in any review state and with its
+embedding, is written as a single JSON bundle, or with --format jsonl as
+one record per line. Use 'memorypilot import' to load it on another
+machine or into another profile.
+
+With --pca, every memory with an embedding is projected to 2D (its first
+two principal components, scaled to [-1, 1]) and written with its type,
+scope, topics, cluster and summary, ready for a scatter plot.
+
+Use 'export ical' for calendar reminders of memories that expire, and
+'export obsidian' for a vault of Markdown notes.
+
+Examples:
+  memorypilot export -o backup.json
+  memorypilot export --format jsonl | gzip > backup.jsonl.gz
+  memorypilot export --pca > points.json
+  memorypilot export --pca --format csv -o points.csv
+  memorypilot export ical --expiring 30d -o expiring.ics
+  memorypilot export obsidian ~/Notes/memories --watch`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if pca, _ := cmd.Flags().GetBool("pca"); !pca {
+			return exportAll(cmd, format)
+		}
+		if format != "json" && format != "csv" {
+			return fmt.Errorf("unknown format %q (json|csv)", format)
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		memories, err := s.EmbeddedMemories()
+		if err != nil {
+			return fmt.Errorf("failed to load embeddings: %w", err)
+		}
+		points := analysis.Project2D(memories)
+		if points == nil {
+			points = []analysis.Point{}
+		}
+
+		var out io.Writer = os.Stdout
+		if path, _ := cmd.Flags().GetString("output"); path != "" {
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+			defer f.Close()
+			out = f
+			defer fmt.Fprintf(os.Stderr, "📤 Exported %d points to %s\n", len(points), path)
+		}
+
+		if format == "csv" {
+			return writePointsCSV(out, points)
+		}
+		data, _ := json.MarshalIndent(points, "", "  ")
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	},
+}
+
+var exportICalCmd = &cobra.Command{
+	Use:   "ical",
+	Short: "Export expiring memories as calendar reminders (.ics)",
+	Long: `Export memories that expire soon (temporary decisions, "revisit after
+launch", session context) as iCalendar events at their expiry, each with a
+reminder ahead of time. Import the file into any calendar, or serve it and
+subscribe; event UIDs are stable so re-imports update events in place.
+
+Examples:
+  memorypilot export ical -o expiring.ics
+  memorypilot export ical --expiring 90d --alarm 72h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		expiring, _ := cmd.Flags().GetString("expiring")
+		horizon, err := parseSpan(expiring)
+		if err != nil {
+			return fmt.Errorf("invalid --expiring %q (e.g. 30d, 48h)", expiring)
+		}
+		opts := ical.DefaultOptions
+		opts.Alarm, _ = cmd.Flags().GetDuration("alarm")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		now := time.Now()
+		memories, err := s.ExpiringMemories(now, now.Add(horizon))
+		if err != nil {
+			return fmt.Errorf("failed to load expiring memories: %w", err)
+		}
+
+		var out io.Writer = os.Stdout
+		if path, _ := cmd.Flags().GetString("output"); path != "" {
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+			defer f.Close()
+			out = f
+			defer fmt.Fprintf(os.Stderr, "📅 Exported %d expiring memories to %s\n", len(memories), path)
+		}
+		return ical.Write(out, memories, now, opts)
+	},
+}
+
+var exportObsidianCmd = &cobra.Command{
+	Use:   "obsidian <dir>",
+	Short: "Export memories as Markdown notes for an Obsidian vault",
+	Long: `Write each approved memory as a Markdown note with YAML frontmatter (type,
+scope, project, topics as tags, confidence, source, created and expiry) and
+[[links]] to its related memories. Notes go in <dir>/<project>/<type>/,
+with memories outside any project under Personal/.
+
+Re-running only rewrites notes that changed and removes notes of memories
+that were forgotten or archived. Notes you add yourself are never touched.
+With --watch the vault is kept in sync until interrupted.
+
+Examples:
+  memorypilot export obsidian ~/Notes/memories
+  memorypilot export obsidian ./vault --watch --interval 1m`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := config.ExpandHome(args[0])
+		watch, _ := cmd.Flags().GetBool("watch")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if watch && interval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		res, err := vault.Sync(s, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📝 Vault %s: %d written, %d updated, %d removed, %d unchanged\n", dir, res.Written, res.Updated, res.Removed, res.Unchanged)
+		if !watch {
+			return nil
+		}
+
+		fmt.Printf("👀 Watching for changes every %s (Ctrl+C to stop)\n", interval)
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sigChan:
+				return nil
+			case <-ticker.C:
+				res, err := vault.Sync(s, dir)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: vault sync failed: %v\n", err)
+					continue
+				}
+				if res.Changed() {
+					fmt.Printf("📝 %s: %d written, %d updated, %d removed\n", time.Now().Format("15:04:05"), res.Written, res.Updated, res.Removed)
+				}
+			}
+		}
+	},
+}
+
+// exportAll writes every project and memory as a transfer bundle
+func exportAll(cmd *cobra.Command, format string) error {
+	if format != transfer.FormatJSON && format != transfer.FormatJSONL {
+		return fmt.Errorf("unknown format %q (json|jsonl)", format)
+	}
+
+	s, err := openStore()
+	if err != nil || s == nil {
+		return err
+	}
+	defer s.Close()
+
+	bundle, err := transfer.Export(s, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path, _ := cmd.Flags().GetString("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		defer f.Close()
+		out = f
+		defer fmt.Fprintf(os.Stderr, "📤 Exported %d projects and %d memories to %s\n", len(bundle.Projects), len(bundle.Memories), path)
+	}
+	return transfer.Write(out, bundle, format)
+}
+
+// writePointsCSV writes one row per point; topics are joined with ";"
+func writePointsCSV(out io.Writer, points []analysis.Point) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"id", "x", "y", "cluster", "type", "scope", "project_id", "created_at", "topics", "summary"})
+	for _, p := range points {
+		w.Write([]string{
+			p.ID,
+			strconv.FormatFloat(p.X, 'f', 6, 64),
+			strconv.FormatFloat(p.Y, 'f', 6, 64),
+			strconv.Itoa(p.Cluster),
+			string(p.Type),
+			string(p.Scope),
+			p.ProjectID,
+			p.CreatedAt.Format(time.RFC3339),
+			strings.Join(p.Topics, ";"),
+			p.Summary,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func init() {
+	exportCmd.Flags().Bool("pca", false, "Export 2D coordinates from a PCA projection of the embeddings")
+	exportCmd.Flags().String("format", "json", "Output format: json or jsonl, or json or csv with --pca")
+	exportCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+
+	exportCmd.AddCommand(exportICalCmd)
+	exportICalCmd.Flags().String("expiring", "30d", "Include memories expiring within this span (e.g. 30d, 48h)")
+	exportICalCmd.Flags().Duration("alarm", ical.DefaultOptions.Alarm, "Remind this long before each expiry (0 for no alarm)")
+	exportICalCmd.Flags().StringP("output", "o", "", "Write to a file instead of stdout")
+
+	exportCmd.AddCommand(exportObsidianCmd)
+	exportObsidianCmd.Flags().Bool("watch", false, "Keep the vault in sync until interrupted")
+	exportObsidianCmd.Flags().Duration("interval", 30*time.Second, "How often --watch checks for changes")
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// forgetPreview is how many matching memories are listed before confirming
+const forgetPreview = 10
+
+var forgetCmd = &cobra.Command{
+	Use:   "forget [memory-id...]",
+	Short: "Delete memories by ID or filter",
+	Long: `Delete memories permanently, by ID or by filter. Filters combine: a memory
+is deleted only if it matches all of them. Matching memories are listed and
+you are asked to confirm unless --yes is given.
+
+Examples:
+  memorypilot forget 01HX...
+  memorypilot forget --query "old staging cluster" --dry-run
+  memorypilot forget --before 2024-01-01 --type context
+  memorypilot forget --before 90d --project api --topic spike`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		filter, err := forgetFilter(cmd, s)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 && !filter.IsEmpty() {
+			return fmt.Errorf("give memory IDs or filters, not both")
+		}
+		if len(args) == 0 && filter.IsEmpty() {
+			return fmt.Errorf("give memory IDs or at least one of --query, --before, --type, --project, --topic")
+		}
+
+		var matched []models.Memory
+		if len(args) > 0 {
+			for _, id := range args {
+				m, err := s.GetMemory(id)
+				if err != nil {
+					return fmt.Errorf("failed to look up %s: %w", id, err)
+				}
+				if m == nil {
+					return fmt.Errorf("memory %s not found", id)
+				}
+				matched = append(matched, *m)
+			}
+		} else if matched, err = s.MatchMemories(filter); err != nil {
+			return fmt.Errorf("failed to find memories: %w", err)
+		}
+
+		if len(matched) == 0 {
+			fmt.Println("🔍 No memories match")
+			return nil
+		}
+
+		fmt.Printf("🗑️  %d memories match:\n\n", len(matched))
+		for i, m := range matched {
+			if i == forgetPreview {
+				fmt.Printf("   ... and %d more\n", len(matched)-forgetPreview)
+				break
+			}
+			fmt.Printf("%s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+			fmt.Printf("   🆔 %s | 📅 %s\n", m.ID, m.CreatedAt.Format("2006-01-02"))
+		}
+		fmt.Println()
+
+		if dryRun {
+			fmt.Println("   Dry run: nothing was deleted")
+			return nil
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d memories permanently? [y/N] ", len(matched))) {
+			fmt.Println("   Cancelled")
+			return nil
+		}
+
+		var deleted int64
+		if len(args) > 0 {
+			for _, id := range args {
+				if err := s.DeleteMemory(id); errors.Is(err, store.ErrHeld) {
+					continue
+				} else if err != nil {
+					return err
+				}
+				deleted++
+			}
+		} else if deleted, err = s.DeleteByFilter(filter); err != nil {
+			return fmt.Errorf("failed to delete memories: %w", err)
+		}
+
+		fmt.Printf("✅ Forgot %d memories\n", deleted)
+		if held := int64(len(matched)) - deleted; held > 0 {
+			fmt.Printf("⚖️  Kept %d under legal hold (see 'memorypilot hold list')\n", held)
+		}
+		return nil
+	},
+}
+
+// forgetFilter builds the delete filter from forget's flags
+func forgetFilter(cmd *cobra.Command, s *store.Store) (store.DeleteFilter, error) {
+	var f store.DeleteFilter
+	f.Query, _ = cmd.Flags().GetString("query")
+	f.Topic, _ = cmd.Flags().GetString("topic")
+
+	types, _ := cmd.Flags().GetStringSlice("type")
+	for _, t := range types {
+		f.Types = append(f.Types, models.MemoryType(t))
+	}
+
+	if before, _ := cmd.Flags().GetString("before"); before != "" {
+		if t, err := time.ParseInLocation("2006-01-02", before, time.Local); err == nil {
+			f.Before = t
+		} else if t, err := parseSince(before); err == nil {
+			f.Before = t
+		} else {
+			return f, fmt.Errorf("invalid --before %q (a date like 2024-01-01 or an age like 90d)", before)
+		}
+	}
+
+	if name, _ := cmd.Flags().GetString("project"); name != "" {
+		project, err := findProject(s, name)
+		if err != nil {
+			return f, err
+		}
+		f.ProjectID = &project.ID
+	}
+	return f, nil
+}
+
+// findProject looks up a project by name, or by path
+func findProject(s *store.Store, name string) (*models.Project, error) {
+	project, err := s.GetProjectByName(name)
+	if err == nil && project == nil {
+		if abs, absErr := filepath.Abs(name); absErr == nil {
+			project, err = s.GetProjectByPath(abs)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up project: %w", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("unknown project %q", name)
+	}
+	return project, nil
+}
+
+// confirm asks a yes/no question on the terminal, defaulting to no
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	forgetCmd.Flags().String("query", "", "Memories whose content, summary or topics contain this text")
+	forgetCmd.Flags().String("before", "", "Memories created before a date (2024-01-01) or older than an age (90d)")
+	forgetCmd.Flags().StringSlice("type", nil, "Memories of these types")
+	forgetCmd.Flags().String("project", "", "Memories of this project (name or path)")
+	forgetCmd.Flags().String("topic", "", "Memories with this topic")
+	forgetCmd.Flags().Bool("dry-run", false, "List matching memories without deleting them")
+	forgetCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/memorypilot/memorypilot/internal/guard"
+	"github.com/spf13/cobra"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Warn when staged changes resemble a known mistake",
+	Long: `Compare the staged diff against "mistake" memories and warn when the
+change looks like a mistake you've made before.
+
+By default guard only warns; with --strict it exits non-zero so the commit is
+blocked. If Ollama is unreachable the check is skipped rather than blocking.
+
+To run it on every commit, add to .git/hooks/pre-commit:
+  memorypilot guard --staged`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		staged, _ := cmd.Flags().GetBool("staged")
+		if !staged {
+			return fmt.Errorf("nothing to check: pass --staged")
+		}
+
+		dir, _ := os.Getwd()
+		chunks, err := guard.StagedDiff(dir)
+		if err != nil {
+			return err
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		threshold, _ := cmd.Flags().GetFloat32("threshold")
+		warnings, err := guard.Check(s, configuredEmbedder(), chunks, threshold)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  MemoryPilot guard skipped: %v\n", err)
+			return nil
+		}
+		if len(warnings) == 0 {
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "⚠️  This change resembles %d known mistake(s):\n\n", len(warnings))
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "❌ %s\n", w.Mistake.Content)
+			fmt.Fprintf(os.Stderr, "   📄 %s | 🎯 %.0f%% similar | 📅 %s\n\n", w.File, w.Similarity*100, w.Mistake.CreatedAt.Format("2006-01-02"))
+		}
+
+		strict, _ := cmd.Flags().GetBool("strict")
+		if strict {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("commit blocked by MemoryPilot guard (use --no-verify to bypass)")
+		}
+		return nil
+	},
+}
+
+func init() {
+	guardCmd.Flags().Bool("staged", false, "Check the staged diff")
+	guardCmd.Flags().Bool("strict", false, "Exit non-zero when a known mistake is matched")
+	guardCmd.Flags().Float32("threshold", guard.DefaultThreshold, "Minimum similarity to flag (0-1)")
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var holdCmd = &cobra.Command{
+	Use:   "hold",
+	Short: "Place legal holds that freeze memories",
+	Long: `A legal hold freezes the memories of a project, the memories with a
+topic, or a single memory, for compliance or disputes. Held memories can't
+be deleted (by forget, retention policies or the API), edited, reviewed or
+decayed, and neither can their annotations or the project's captured
+events. Holds are enforced by the database itself, so every command and
+the daemon respect them.`,
+}
+
+var holdAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Place a legal hold on a project, topic or memory",
+	Example: `  memorypilot hold add --project acme-api --reason "Case 2024-17"
+  memorypilot hold add --topic client-acme
+  memorypilot hold add --memory 01HX...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetString("project")
+		topic, _ := cmd.Flags().GetString("topic")
+		memoryID, _ := cmd.Flags().GetString("memory")
+		reason, _ := cmd.Flags().GetString("reason")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		var kind, value string
+		switch {
+		case project != "":
+			p, err := findProject(s, project)
+			if err != nil {
+				return err
+			}
+			kind, value = store.HoldProject, p.ID
+		case topic != "":
+			kind, value = store.HoldTopic, topic
+		case memoryID != "":
+			m, err := s.GetMemory(memoryID)
+			if err != nil {
+				return fmt.Errorf("failed to look up memory: %w", err)
+			}
+			if m == nil {
+				return fmt.Errorf("memory %s not found", memoryID)
+			}
+			kind, value = store.HoldMemory, m.ID
+		}
+
+		hold, err := s.AddHold(kind, value, reason)
+		if err != nil {
+			return fmt.Errorf("failed to place hold: %w", err)
+		}
+		n, err := s.HeldCount(*hold)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("⚖️  Hold %d placed on %s %s (%d memories frozen)\n", hold.ID, kind, describeHold(s, *hold), n)
+		return nil
+	},
+}
+
+var holdListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List legal holds",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		holds, err := s.ListHolds()
+		if err != nil {
+			return fmt.Errorf("failed to list holds: %w", err)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if holds == nil {
+				holds = []store.Hold{}
+			}
+			data, _ := json.MarshalIndent(holds, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(holds) == 0 {
+			fmt.Println("📭 No legal holds")
+			return nil
+		}
+		fmt.Printf("⚖️  Legal Holds (%d)\n", len(holds))
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+		for _, h := range holds {
+			n, err := s.HeldCount(h)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n%d. %s %s: %d memories\n", h.ID, h.Kind, describeHold(s, h), n)
+			fmt.Printf("   📅 Since %s", h.CreatedAt.Local().Format("2006-01-02"))
+			if h.Reason != "" {
+				fmt.Printf(" | 📝 %s", h.Reason)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+var holdReleaseCmd = &cobra.Command{
+	Use:   "release <hold-id>",
+	Short: "Release a legal hold",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid hold ID %q", args[0])
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		yes, _ := cmd.Flags().GetBool("yes")
+		if !yes && !confirm(fmt.Sprintf("Release hold %d? Its memories can then be changed and deleted. [y/N] ", id)) {
+			fmt.Println("   Cancelled")
+			return nil
+		}
+		if err := s.ReleaseHold(id); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Released hold %d\n", id)
+		return nil
+	},
+}
+
+// describeHold names what a hold covers, showing project names rather than
+// IDs
+func describeHold(s *store.Store, h store.Hold) string {
+	if h.Kind == store.HoldProject {
+		if projects, err := s.ListProjects(); err == nil {
+			for _, p := range projects {
+				if p.ID == h.Value {
+					return p.Name
+				}
+			}
+		}
+	}
+	return h.Value
+}
+
+func init() {
+	holdCmd.AddCommand(holdAddCmd)
+	holdCmd.AddCommand(holdListCmd)
+	holdCmd.AddCommand(holdReleaseCmd)
+
+	holdAddCmd.Flags().String("project", "", "Hold every memory of this project (name or path)")
+	holdAddCmd.Flags().String("topic", "", "Hold every memory with this topic")
+	holdAddCmd.Flags().String("memory", "", "Hold a single memory")
+	holdAddCmd.Flags().String("reason", "", "Why the hold was placed, e.g. a case number")
+	holdAddCmd.MarkFlagsMutuallyExclusive("project", "topic", "memory")
+	holdAddCmd.MarkFlagsOneRequired("project", "topic", "memory")
+	holdListCmd.Flags().Bool("json", false, "Output as JSON")
+	holdReleaseCmd.Flags().Bool("yes", false, "Don't ask for confirmation")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/memorypilot/memorypilot/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import memories from a MemoryPilot export",
+	Long: `Import projects and memories written by 'memorypilot export', as a JSON
+bundle or JSON Lines ("-" reads stdin). Projects at a path already known
+here are matched to the local project; memories keep their IDs and
+embeddings.
+
+When a memory's ID already exists, --conflict decides what happens:
+  skip       keep the local memory (default)
+  overwrite  replace it with the imported one
+  merge      keep the most recent edit of each field and the union of topics
+
+Memories under legal hold are never changed. New memories whose content is
+already stored under another ID are counted as duplicates and not added.
+
+Examples:
+  memorypilot import backup.json
+  memorypilot import shared.jsonl --conflict merge --dry-run
+  gunzip -c backup.jsonl.gz | memorypilot import -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, _ := cmd.Flags().GetString("conflict")
+		conflict, err := transfer.ParseConflict(mode)
+		if err != nil {
+			return err
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", args[0], err)
+			}
+			defer f.Close()
+			in = f
+		}
+		bundle, err := transfer.Read(in)
+		if err != nil {
+			return err
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		res, err := transfer.Import(s, bundle, conflict, dryRun)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("📥 Read %d projects and %d memories\n\n", len(bundle.Projects), len(bundle.Memories))
+		fmt.Printf("   Projects added:   %d\n", res.Projects)
+		fmt.Printf("   Memories added:   %d\n", res.Added)
+		if res.Duplicates > 0 {
+			fmt.Printf("   Duplicates:       %d\n", res.Duplicates)
+		}
+		if res.Skipped > 0 {
+			fmt.Printf("   Skipped:          %d\n", res.Skipped)
+		}
+		if res.Overwritten > 0 {
+			fmt.Printf("   Overwritten:      %d\n", res.Overwritten)
+		}
+		if res.Merged > 0 {
+			fmt.Printf("   Merged:           %d\n", res.Merged)
+		}
+		if res.Held > 0 {
+			fmt.Printf("⚖️  Kept %d under legal hold (see 'memorypilot hold list')\n", res.Held)
+		}
+		if dryRun {
+			fmt.Println("\n   Dry run: nothing was written")
+		}
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().String("conflict", string(transfer.ConflictSkip), "When a memory ID exists: skip, overwrite or merge")
+	importCmd.Flags().Bool("dry-run", false, "Count what would be imported without writing anything")
+}
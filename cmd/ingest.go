@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/ingest"
+	"github.com/spf13/cobra"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Feed documents into memory",
+}
+
+var ingestDocCmd = &cobra.Command{
+	Use:   "doc <file|url>",
+	Short: "Extract memories from a design doc, postmortem or other document",
+	Long: `Read a PDF, HTML, Markdown or text document, split it into sections at
+its headings, and extract memories from each with a prompt for documents:
+decisions and their reasons, constraints, conventions, root causes and
+lessons. Memories name the document as their source, and are attached to
+--project, or to the project in the current directory if it's known.
+
+PDFs are read without external tools; scanned and encrypted PDFs have no
+text to read. URLs are downloaded unless privacy.localOnly is set.
+Documents need a model: the rules provider is skipped.
+
+Examples:
+  memorypilot ingest doc docs/design/sync.md
+  memorypilot ingest doc postmortem-2024-03.pdf --project api
+  memorypilot ingest doc https://wiki.internal/adr/0012.html --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if settings.Offline {
+			return fmt.Errorf("extraction is offline (extraction.offline); ingest documents once back online")
+		}
+		providers := slices.DeleteFunc(slices.Clone(settings.Providers), func(p string) bool {
+			return p == extractor.ProviderRules
+		})
+		if len(providers) == 0 {
+			return fmt.Errorf("documents need a model, but the only extraction provider is %q", extractor.ProviderRules)
+		}
+		ext, err := extractor.NewChain(providers, extractor.ChainConfig{
+			OllamaModel:       settings.Model,
+			ClaudeAPIKey:      settings.ClaudeAPIKey,
+			ClaudeDailyBudget: settings.ClaudeDailyBudget,
+		})
+		if err != nil {
+			return err
+		}
+		ext.SetExamples(s)
+		ext.SetMinConfidence(settings.MinConfidence)
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			ext.SetRunRecorder(s)
+		}
+		emb := configuredEmbedder()
+		s.SetEmbeddingModel(embedding.ModelOf(emb))
+
+		opts := ingest.Options{Similarity: settings.DedupSimilarity, ReviewBelow: settings.ReviewBelow, DryRun: dryRun}
+		opts.SectionSize, _ = cmd.Flags().GetInt("section-size")
+		if name, _ := cmd.Flags().GetString("project"); name != "" {
+			project, err := findProject(s, name)
+			if err != nil {
+				return err
+			}
+			opts.ProjectID = &project.ID
+		} else if cwd, err := os.Getwd(); err == nil {
+			if project, err := s.GetProjectByPath(cwd); err == nil && project != nil {
+				opts.ProjectID = &project.ID
+			}
+		}
+
+		doc, err := ingest.Load(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📄 %s (%s, %d characters)\n", doc.Title, doc.Format, len(doc.Text))
+		opts.Progress = func(i, n int, sec ingest.Section) {
+			heading := sec.Heading
+			if heading == "" {
+				heading = fmt.Sprintf("%d characters", len(sec.Text))
+			}
+			fmt.Printf("   [%d/%d] %s\n", i+1, n, heading)
+		}
+
+		result, runErr := ingest.Extract(s, ext, emb, doc, opts)
+		verb := "Added"
+		if dryRun {
+			verb = "Would add"
+		}
+		if len(result.Added) > 0 {
+			fmt.Println()
+		}
+		for _, m := range result.Added {
+			fmt.Printf("   %s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		}
+		fmt.Printf("\n✅ %s %d memories (%d sections, %d extracted, %d already known",
+			verb, len(result.Added), result.Sections, result.Extracted, result.Duplicates)
+		if result.Failed > 0 {
+			fmt.Printf(", %d sections unusable", result.Failed)
+		}
+		fmt.Println(")")
+		if runErr != nil {
+			return fmt.Errorf("ingestion stopped early: %w", runErr)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ingestDocCmd.Flags().StringP("project", "p", "", "Project name or path (default: current directory)")
+	ingestDocCmd.Flags().Int("section-size", ingest.DefaultSectionSize, "Most characters extracted at once")
+	ingestDocCmd.Flags().Bool("dry-run", false, "Show the memories without saving them")
+	ingestCmd.AddCommand(ingestDocCmd)
+}
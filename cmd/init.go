@@ -16,14 +16,17 @@ var initCmd = &cobra.Command{
 This creates:
   ~/.memorypilot/config.yaml    - Configuration file
   ~/.memorypilot/data/          - Database and embeddings
-  ~/.memorypilot/logs/          - Log files`,
+  ~/.memorypilot/logs/          - Log files
+
+MEMORYPILOT_HOME moves ~/.memorypilot elsewhere. The data directory can
+be set with --data-dir, and follows XDG_DATA_HOME on new installs.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configDir := getConfigDir()
 		dataDir := getDataDir()
 		logsDir := configDir + "/logs"
-		
+
 		fmt.Println("🧠 Initializing MemoryPilot...")
-		
+
 		// Create directories
 		dirs := []string{configDir, dataDir, logsDir}
 		for _, dir := range dirs {
@@ -32,7 +35,7 @@ This creates:
 			}
 		}
 		fmt.Println("   ✓ Created directories")
-		
+
 		// Create config file if it doesn't exist
 		configPath := configDir + "/config.yaml"
 		if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -43,7 +46,7 @@ This creates:
 		} else {
 			fmt.Println("   ✓ Config exists")
 		}
-		
+
 		// Initialize database
 		dbPath := dataDir + "/memories.db"
 		s, err := store.New(dbPath)
@@ -52,7 +55,7 @@ This creates:
 		}
 		s.Close()
 		fmt.Println("   ✓ Initialized database")
-		
+
 		fmt.Println()
 		fmt.Println("✅ MemoryPilot initialized!")
 		fmt.Println()
@@ -71,7 +74,7 @@ This creates:
 		fmt.Println(`      }`)
 		fmt.Println(`    }`)
 		fmt.Println(`  }`)
-		
+
 		return nil
 	},
 }
@@ -80,19 +83,75 @@ const defaultConfig = `# MemoryPilot Configuration
 
 # LLM settings for memory extraction
 extraction:
-  provider: ollama  # ollama | claude
-  model: llama3.2   # For ollama
-  # apiKey: ""      # For claude (or set ANTHROPIC_API_KEY)
+  # Providers are tried in order; when one is unreachable or over budget the
+  # next is used. "rules" derives basic memories (dependencies adopted, bugs
+  # fixed) without a model, flagged for 'memorypilot reprocess --flagged';
+  # "null" skips extraction; "fake" extracts with fixed keyword rules, for
+  # demos and tests without a model. (env: MEMORYPILOT_PROVIDERS)
+  providers: [ollama, rules]   # e.g. [ollama, claude, rules]
+  model: llama3.2       # For ollama (env: MEMORYPILOT_MODEL)
+  # apiKey: ""          # For claude (or set ANTHROPIC_API_KEY)
+  # claudeDailyBudget: 200   # Max claude requests per day
+  # offline: false     # Capture only; extract when back online (env: MEMORYPILOT_OFFLINE)
+  # Tuning below is reloaded by a running daemon when this file changes
+  minConfidence: 0.6    # Drop extracted memories below this (0-1)
+  # reviewBelow: 0.75   # Hold memories below this for 'memorypilot review' instead of recalling them (0 = off)
+  dedupSimilarity: 0.92 # Merge memories this similar to one already stored (0 = off)
+  minFileSignificance: 0.1  # Skip file saves scoring lower (0-1; unchanged and whitespace-only saves score 0)
+  batchSize: 10         # Events per extraction batch (1-500)
+  batchWait: 5s         # Flush a partial batch after this long
+
+# Embedding providers, tried in order (env: MEMORYPILOT_EMBEDDING_PROVIDERS)
+embedding:
+  # "local" runs all-MiniLM-L6-v2 in process, without Ollama, once
+  # installed with 'memorypilot embeddings install'
+  providers: [ollama]   # e.g. [ollama, null] or [local]; "fake" hashes words, for tests
+  # Ollama embedding model (env: MEMORYPILOT_EMBEDDING_MODEL); switch with
+  # 'memorypilot embeddings migrate --model', which re-embeds every memory
+  model: nomic-embed-text
+
+# Recall settings
+recall:
+  # When a personal memory contradicts a project, team or org one, the
+  # narrower scope wins. annotate marks the overridden memory, strict hides
+  # it, off disables the check.
+  scopeConflicts: annotate
+  # Hybrid search ranks results by a weighted sum of keyword relevance
+  # (BM25), semantic similarity, importance and recency, which halves every
+  # recencyHalfLife. 'recall --json' shows each result's breakdown.
+  weights:
+    keyword: 0.3
+    semantic: 0.5
+    importance: 0.15
+    recency: 0.05
+  recencyHalfLife: 2160h  # 90 days
+  # Summaries and topics get embeddings of their own, so a memory whose
+  # summary matches the query is found even if its details read otherwise;
+  # so do chunks of long content, shown as the matched excerpt. Semantic
+  # similarity is the best of a memory's vectors, summary and topics scaled
+  # by their weight (0 ignores them).
+  vectorWeights:
+    summary: 1.0
+    topics: 0.8
 
 # Watcher settings
 watchers:
+  # Directories read per second while discovering repos and setting up file
+  # watches; lower it if the first minutes after startup feel slow
+  scanBudget: 200
   git:
     enabled: true
-    interval: 30s
+    interval: 30s       # Polling interval (1s-1h), reloaded at runtime
+    # Only commits by you are captured (your git config user.email/user.name).
+    # List extra identities here, or enable teamCapture to capture everyone.
+    authors: []
+    teamCapture: false
+    # Tags and releases are always captured; stashes are opt-in
+    stashes: false
   file:
     enabled: true
     debounce: 500ms
-    ignore:
+    ignore:             # Added to the built-in list
       - node_modules
       - .git
       - dist
@@ -106,10 +165,44 @@ watchers:
       - ~/.zsh_history
       - ~/.bash_history
 
-# API settings
+# API settings (env: MEMORYPILOT_API_HOST, MEMORYPILOT_API_PORT, MEMORYPILOT_API_TOKEN)
 api:
   port: 7832
   enabled: true
+  # host: 127.0.0.1    # Set a token before listening on other interfaces
+  # token: ""          # Bearer token required by the API
+
+# Anonymized org insights (see 'memorypilot insights'), regenerated daily by
+# the daemon: topic, type and mistake-category counts, never memories
+insights:
+  enabled: false
+  windowDays: 30
+  minContributors: 3    # Topics with fewer distinct contributors are hidden
+  epsilon: 1.0          # Noise added to counts; smaller is more private
+
+# Retention policies (see 'memorypilot policy list'), applied hourly by the
+# daemon: matching memories expire, then are deleted
+# retention:
+#   policies:
+#     client-acme:
+#       topic: client-acme   # also: project, source, types
+#       until: 2026-12-31    # contract end
+#     terminal-facts:
+#       source: terminal
+#       types: [fact]
+#       maxAge: 90d
+
+# Expired memories (remember --ttl, retention policies) drop out of recall
+# right away; the daemon then archives or deletes them hourly
+expiry:
+  action: archive       # archive (keep, out of recall) or delete
+
+# Privacy settings
+privacy:
+  # Refuse to start anything that would send content off this machine:
+  # claude extraction, non-localhost ollama, team sync, webhooks and
+  # publishing. (env: MEMORYPILOT_LOCAL_ONLY)
+  localOnly: false
 
 # Sync settings (Phase 2)
 sync:
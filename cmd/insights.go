@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/insights"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var insightsCmd = &cobra.Command{
+	Use:   "insights",
+	Short: "Show anonymized org-level patterns instead of raw memories",
+	Long: `Show which topics, memory types and mistake categories come up across
+everyone's memories, without showing any memory or who wrote it.
+
+Topics are only reported once enough different contributors
+(insights.minContributors) have memories about them, and every count is
+noised (insights.epsilon), so the report can be shared across an
+organization without surveilling individuals. The daemon regenerates it
+daily when insights.enabled is set; --refresh generates it now.
+
+With MEMORYPILOT_REMOTE_URL set, the team server's report is shown.
+
+Examples:
+  memorypilot insights
+  memorypilot insights --refresh --days 90
+  memorypilot insights --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		refresh, _ := cmd.Flags().GetBool("refresh")
+
+		var report *insights.Report
+		client, err := remoteClient()
+		if err != nil {
+			return err
+		}
+		if client != nil {
+			if refresh {
+				return fmt.Errorf("--refresh generates a local report; unset MEMORYPILOT_REMOTE_URL")
+			}
+			if report, err = client.Insights(); err != nil {
+				return err
+			}
+		} else {
+			s, err := openStore()
+			if err != nil || s == nil {
+				return err
+			}
+			defer s.Close()
+
+			if refresh {
+				settings, err := loadSettings()
+				if err != nil {
+					return fmt.Errorf("invalid config: %w", err)
+				}
+				opts := insightsOptions(settings)
+				if cmd.Flags().Changed("days") {
+					days, _ := cmd.Flags().GetInt("days")
+					opts.Window = time.Duration(days) * 24 * time.Hour
+				}
+				if cmd.Flags().Changed("min-contributors") {
+					opts.MinContributors, _ = cmd.Flags().GetInt("min-contributors")
+				}
+				r, err := insights.Generate(s, opts, time.Now())
+				if err != nil {
+					return err
+				}
+				report = &r
+			} else if report, err = insights.Latest(s); err != nil {
+				return err
+			}
+		}
+
+		if report == nil {
+			fmt.Println("📭 No insights report yet")
+			fmt.Println("   Run 'memorypilot insights --refresh', or set insights.enabled for the daemon")
+			return nil
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+		printInsights(report)
+		return nil
+	},
+}
+
+// insightsOptions maps the insights.* settings to aggregation options
+func insightsOptions(s config.Settings) insights.Options {
+	return insights.Options{
+		Window:          time.Duration(s.InsightsWindowDays) * 24 * time.Hour,
+		MinContributors: s.InsightsMinContributors,
+		Epsilon:         s.InsightsEpsilon,
+	}
+}
+
+func printInsights(r *insights.Report) {
+	fmt.Printf("🏢 Org Insights (%s to %s)\n", r.Since.Local().Format("2006-01-02"), r.GeneratedAt.Local().Format("2006-01-02"))
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("   ~%d memories from ~%d contributors\n", r.Memories, r.Contributors)
+	fmt.Printf("   🔒 Topics need %d+ contributors; counts noised (ε=%g)\n", r.MinContributors, r.Epsilon)
+
+	sections := []struct {
+		title  string
+		counts []insights.Count
+		types  bool
+	}{
+		{"📊 By Type", r.ByType, true},
+		{"🏷️  Top Topics", r.Topics, false},
+		{"❌ Common Mistake Categories", r.Mistakes, false},
+	}
+	for _, sec := range sections {
+		fmt.Println()
+		fmt.Println(sec.title)
+		if len(sec.counts) == 0 {
+			fmt.Println("   (nothing shared by enough contributors)")
+			continue
+		}
+		peak := sec.counts[0].Count
+		for _, c := range sec.counts {
+			name := c.Name
+			if sec.types {
+				name = getTypeEmoji(models.MemoryType(c.Name)) + " " + name
+			}
+			fmt.Printf("   %-28s %4d %s\n", name, c.Count, countBar(c.Count, peak))
+		}
+	}
+}
+
+// countBar draws a count relative to the largest one
+func countBar(n, peak int) string {
+	if peak == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, n*20/peak))
+}
+
+func init() {
+	insightsCmd.Flags().Bool("refresh", false, "Generate a new report from the local store now")
+	insightsCmd.Flags().Int("days", 30, "With --refresh, aggregate memories from this many days, overriding insights.windowDays")
+	insightsCmd.Flags().Int("min-contributors", 3, "With --refresh, contributors a topic needs to be reported, overriding insights.minContributors")
+	insightsCmd.Flags().Bool("json", false, "Output as JSON")
+}
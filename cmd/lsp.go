@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/memorypilot/memorypilot/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Start the language server",
+	Long: `Start a minimal Language Server Protocol server over stdio.
+
+Memories that reference the open file (by source or by mentioning its path)
+appear as hints on the first line, on hover, and as code actions, so they
+show up in any LSP-capable editor without a dedicated plugin. Hovering a
+word shows memories tagged with it.
+
+Configure your editor to run "memorypilot lsp" for any file type.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		server, err := lsp.NewServer(getDataDir() + "/memories.db")
+		if err != nil {
+			return fmt.Errorf("failed to create LSP server: %w", err)
+		}
+
+		// Run the server (blocks until the client exits)
+		return server.Run()
+	},
+}
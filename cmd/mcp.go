@@ -1,8 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
 	"github.com/memorypilot/memorypilot/internal/mcp"
 	"github.com/spf13/cobra"
 )
@@ -13,17 +23,133 @@ var mcpCmd = &cobra.Command{
 	Long: `Start the Model Context Protocol server for AI tool integration.
 
 This is typically spawned by AI tools like Claude Code or OpenClaw.
-The server communicates over stdio using the MCP protocol.`,
+The server communicates over stdio using the MCP protocol.
+
+With --http the server runs once for any number of web-based or remote
+clients instead: the streamable HTTP transport at /mcp, and the older
+HTTP+SSE transport at /sse. With --token (or MEMORYPILOT_MCP_TOKEN) every
+request needs "Authorization: Bearer <token>"; without one, browser
+requests from other origins are refused. Set a token before listening on
+another interface.
+
+While the daemon runs, the server relays to it over a unix socket in the
+data directory, so any number of AI tools can run 'memorypilot mcp' side by
+side against one store. Without the daemon (or with --direct) it opens the
+database itself.
+
+Inside a devcontainer, mount the host's data directory and point --db
+(or MEMORYPILOT_DB) at the mounted memories.db; see 'memorypilot devcontainer'.
+
+Examples:
+  memorypilot mcp
+  memorypilot mcp --http 127.0.0.1:7833
+  memorypilot mcp --http :7833 --token "$(openssl rand -hex 32)"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		dataDir := getDataDir()
-		dbPath := dataDir + "/memories.db"
-		
+		dbPath, _ := cmd.Flags().GetString("db")
+		if dbPath == "" {
+			dbPath = os.Getenv("MEMORYPILOT_DB")
+		}
+		if dbPath == "" {
+			dataDir := getDataDir()
+			dbPath = dataDir + "/memories.db"
+		}
+
+		// In a container a missing store usually means the host's wasn't
+		// mounted; an empty one would silently answer nothing
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			if env := config.Container(); env != "" {
+				return fmt.Errorf("no memory store at %s (running in %s): mount the host's ~/.memorypilot/data and pass --db; see 'memorypilot devcontainer'", dbPath, env)
+			}
+		}
+
+		// Share the daemon's store when it's running, rather than open the
+		// database alongside every other client
+		addr, _ := cmd.Flags().GetString("http")
+		if direct, _ := cmd.Flags().GetBool("direct"); !direct && addr == "" {
+			if relayed, err := mcp.Relay(mcp.SocketPath(filepath.Dir(dbPath))); relayed {
+				return err
+			}
+		}
+
 		server, err := mcp.NewServer(dbPath)
 		if err != nil {
 			return fmt.Errorf("failed to create MCP server: %w", err)
 		}
-		
+		settings, err := loadSettings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid config, using defaults: %v\n", err)
+			settings = config.DefaultSettings()
+		}
+		server.SetSearchWeights(settings.Search)
+		if emb, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(settings.EmbeddingModel)); err == nil {
+			server.SetEmbedder(emb)
+		}
+
+		// Session memories graduate through the configured extractors
+		if !settings.Offline {
+			ext, err := extractor.NewChain(settings.Providers, extractor.ChainConfig{
+				OllamaModel:       settings.Model,
+				ClaudeAPIKey:      settings.ClaudeAPIKey,
+				ClaudeDailyBudget: settings.ClaudeDailyBudget,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: session memories can't graduate: %v\n", err)
+			} else {
+				ext.SetMinConfidence(settings.MinConfidence)
+				server.SetExtractor(ext)
+			}
+		}
+
+		if addr != "" {
+			token, _ := cmd.Flags().GetString("token")
+			if token == "" {
+				token = os.Getenv("MEMORYPILOT_MCP_TOKEN")
+			}
+			return serveMCPHTTP(server, addr, token)
+		}
+
 		// Run the server (blocks until stdin closes)
 		return server.Run()
 	},
 }
+
+// serveMCPHTTP serves MCP over HTTP until interrupted
+func serveMCPHTTP(server *mcp.Server, addr, token string) error {
+	srv := server.NewHTTPServer(addr, token)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+
+	fmt.Printf("🌐 Serving MCP on http://%s/mcp (HTTP+SSE clients: /sse)\n", displayAddr(srv.Addr()))
+	if token == "" {
+		fmt.Println("   ⚠️  No token set: anyone who can reach this address can read and edit memories")
+	}
+	fmt.Println("   Press Ctrl+C to stop")
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-errc:
+		return err
+	case <-sigChan:
+	}
+
+	fmt.Println("\n🛑 Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// displayAddr makes ":7833" printable as a URL host
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "localhost" + addr
+	}
+	return addr
+}
+
+func init() {
+	mcpCmd.Flags().String("http", "", "Serve over HTTP on this address (e.g. :7833) instead of stdio")
+	mcpCmd.Flags().String("token", "", "Bearer token required over HTTP (default MEMORYPILOT_MCP_TOKEN)")
+	mcpCmd.Flags().Bool("direct", false, "Open the database even if the daemon is running")
+	mcpCmd.Flags().String("db", "", "Memory database to serve (default ~/.memorypilot/data/memories.db, also MEMORYPILOT_DB)")
+}
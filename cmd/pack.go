@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/memorypilot/memorypilot/internal/pack"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Export a context file for tools that don't speak MCP",
+	Long: `Produce a curated, deduplicated context file that fits within a token
+budget, for pasting into a chat or passing to tools like aider.
+
+Decisions, patterns and preferences are packed first; lower-priority
+memories are dropped once the budget is spent.
+
+Examples:
+  memorypilot pack --out context.md
+  memorypilot pack --project api --budget 4000tokens --out context.md
+  memorypilot pack --budget 8k | pbcopy`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		budgetFlag, _ := cmd.Flags().GetString("budget")
+		budget, err := pack.ParseBudget(budgetFlag)
+		if err != nil {
+			return err
+		}
+
+		// --project accepts a project name or path; default to the current directory
+		projectFlag, _ := cmd.Flags().GetString("project")
+		title := projectFlag
+		var project *models.Project
+		if projectFlag == "" {
+			cwd, _ := os.Getwd()
+			title = filepath.Base(cwd)
+			project, err = s.GetProjectByPath(cwd)
+		} else {
+			project, err = s.GetProjectByName(projectFlag)
+			if err == nil && project == nil {
+				if abs, absErr := filepath.Abs(projectFlag); absErr == nil {
+					project, err = s.GetProjectByPath(abs)
+				}
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up project: %w", err)
+		}
+
+		req := models.RecallRequest{Limit: 500}
+		if project != nil {
+			title = project.Name
+			req.ProjectID = &project.ID
+		} else if projectFlag != "" {
+			return fmt.Errorf("unknown project %q", projectFlag)
+		}
+		req.Query, _ = cmd.Flags().GetString("query")
+
+		memories, err := s.Recall(req)
+		if err != nil {
+			return fmt.Errorf("failed to load memories: %w", err)
+		}
+
+		p := pack.Build(title, memories, budget)
+
+		out, _ := cmd.Flags().GetString("out")
+		if out == "" || out == "-" {
+			fmt.Print(p.Markdown())
+			return nil
+		}
+
+		if err := os.WriteFile(out, []byte(p.Markdown()), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out, err)
+		}
+
+		fmt.Printf("📦 Packed %d memories into %s (~%d/%d tokens)\n", len(p.Memories), out, p.Tokens, p.Budget)
+		if p.Dropped > 0 {
+			fmt.Printf("   %d lower-priority memories didn't fit the budget\n", p.Dropped)
+		}
+		return nil
+	},
+}
+
+func init() {
+	packCmd.Flags().StringP("project", "p", "", "Project name or path (default: current directory)")
+	packCmd.Flags().StringP("budget", "b", "4000tokens", "Token budget, e.g. 4000tokens or 8k")
+	packCmd.Flags().StringP("out", "o", "", "Output file (default: stdout)")
+	packCmd.Flags().StringP("query", "q", "", "Only include memories matching this text")
+}
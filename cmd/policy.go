@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/retention"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "List and apply retention policies",
+	Long: `Retention policies in config.yaml expire memories by topic, project,
+source or type, e.g. "memories tagged client-acme expire when the contract
+ends" or "terminal-derived facts live 90 days". The daemon applies them
+hourly and deletes memories once their policy expires them.
+
+  retention:
+    policies:
+      client-acme:
+        topic: client-acme
+        until: 2026-12-31
+      terminal-facts:
+        source: terminal
+        types: [fact]
+        maxAge: 90d`,
+}
+
+var policyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List retention policies and the memories they select",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policies, err := loadRetentionPolicies()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if len(policies) == 0 {
+			fmt.Println("📭 No retention policies")
+			fmt.Println("   Add them under retention.policies in config.yaml (see 'memorypilot policy --help')")
+			return nil
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		results, _, err := retention.Apply(s, policies, time.Now(), true)
+		if err != nil {
+			return err
+		}
+		governed, err := s.RetentionCounts()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🗓️  Retention Policies (%d)\n", len(policies))
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+		for _, res := range results {
+			p := res.Policy
+			fmt.Printf("\n📜 %s: %s → %s\n", p.Name, p.Selector(), p.Rule())
+			if res.Warning != "" {
+				fmt.Printf("   ⚠️  %s\n", res.Warning)
+				continue
+			}
+			fmt.Printf("   Matches %d memories; %d expiring under this policy\n", res.Matched, governed[p.Name])
+			if res.Scheduled > 0 {
+				fmt.Printf("   ⏳ %d not yet scheduled (%d already past due)\n", res.Scheduled, res.Due)
+			}
+		}
+		return nil
+	},
+}
+
+var policyApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply retention policies now, deleting expired memories",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policies, err := loadRetentionPolicies()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		if len(policies) == 0 {
+			fmt.Println("📭 No retention policies")
+			return nil
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		results, purged, err := retention.Apply(s, policies, time.Now(), dryRun)
+		if err != nil {
+			return err
+		}
+
+		for _, res := range results {
+			if res.Warning != "" {
+				fmt.Printf("⚠️  %s: %s\n", res.Policy.Name, res.Warning)
+				continue
+			}
+			fmt.Printf("📜 %s: %d memories scheduled to expire\n", res.Policy.Name, res.Scheduled)
+		}
+		if dryRun {
+			fmt.Printf("🔍 Dry run: %d memories would be deleted\n", purged)
+			return nil
+		}
+		fmt.Printf("🗑️  Deleted %d expired memories\n", purged)
+		return nil
+	},
+}
+
+func init() {
+	policyCmd.AddCommand(policyListCmd)
+	policyCmd.AddCommand(policyApplyCmd)
+
+	policyApplyCmd.Flags().Bool("dry-run", false, "Show what would change without changing anything")
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/prime"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var primeCmd = &cobra.Command{
+	Use:   "prime",
+	Short: "Write top memories into CLAUDE.md, .cursorrules or AGENTS.md",
+	Long: `Select a project's most relevant memories (preferences and decisions
+first, then by importance and confidence) and write them into the rules
+files coding tools read at startup, for tools without MCP support:
+
+  claude   CLAUDE.md
+  cursor   .cursorrules
+  agents   AGENTS.md
+
+Memories outside any project (personal preferences) are included too. They
+go in a block between <!-- memorypilot:begin --> and <!-- memorypilot:end -->
+markers; the rest of the file is left alone and re-running replaces only
+the block.
+
+Examples:
+  memorypilot prime
+  memorypilot prime --project ~/code/api --format claude,agents
+  memorypilot prime --format all -n 40
+  memorypilot prime --format cursor --stdout`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		formats, _ := cmd.Flags().GetStringSlice("format")
+		if len(formats) == 1 && formats[0] == "all" {
+			formats = prime.Formats
+		}
+		for _, f := range formats {
+			if prime.FileName(f) == "" {
+				return fmt.Errorf("unknown format %q (claude|cursor|agents|all)", f)
+			}
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+		toStdout, _ := cmd.Flags().GetBool("stdout")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		// --project accepts a path or a project name; default to the current directory
+		dir, _ := os.Getwd()
+		var project *models.Project
+		if name, _ := cmd.Flags().GetString("project"); name != "" {
+			if info, statErr := os.Stat(config.ExpandHome(name)); statErr == nil && info.IsDir() {
+				dir, _ = filepath.Abs(config.ExpandHome(name))
+			} else {
+				if project, err = findProject(s, name); err != nil {
+					return err
+				}
+				dir = project.Path
+			}
+		}
+		if project == nil {
+			if project, err = s.GetProjectByPath(dir); err != nil {
+				return fmt.Errorf("failed to look up project: %w", err)
+			}
+		}
+		title := filepath.Base(dir)
+		if project != nil {
+			title = project.Name
+		}
+
+		memories, err := prime.Load(s, project)
+		if err != nil {
+			return fmt.Errorf("failed to load memories: %w", err)
+		}
+		selected := prime.Select(memories, limit, time.Now())
+		if len(selected) == 0 {
+			fmt.Fprintln(os.Stderr, "🔍 No memories for", title)
+			return nil
+		}
+
+		for _, format := range formats {
+			block := prime.Render(format, title, selected)
+			if toStdout {
+				fmt.Print(block)
+				continue
+			}
+			path := filepath.Join(dir, prime.FileName(format))
+			changed, err := prime.Write(path, block)
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			if changed {
+				fmt.Printf("✅ Wrote %d memories to %s\n", len(selected), path)
+			} else {
+				fmt.Printf("✅ %s already up to date\n", path)
+			}
+		}
+		if project == nil && !toStdout {
+			fmt.Println("   (not a tracked project: only memories outside any project were included)")
+		}
+		return nil
+	},
+}
+
+func init() {
+	primeCmd.Flags().StringP("project", "p", "", "Project path or name (default: current directory)")
+	primeCmd.Flags().StringSlice("format", []string{prime.FormatClaude}, "Rules files to write: claude, cursor, agents, or all")
+	primeCmd.Flags().IntP("limit", "n", prime.DefaultLimit, "How many memories to include")
+	primeCmd.Flags().Bool("stdout", false, "Print the blocks instead of writing files")
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/scopes"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// profile is a named memory database
+type profile struct {
+	Name   string
+	DBPath string
+}
+
+// teamCacheProfile is the profile name of the local team cache
+const teamCacheProfile = "team-cache"
+
+// profiledMemory is a recall result labelled with the profile it came from
+type profiledMemory struct {
+	Profile  string `json:"profile"`
+	Pinned   bool   `json:"pinned,omitempty"`
+	Stale    bool   `json:"stale,omitempty"`
+	Feedback string `json:"feedback,omitempty"`
+	models.Memory
+	OverriddenBy string   `json:"overriddenBy,omitempty"`
+	Overrides    []string `json:"overrides,omitempty"`
+
+	conflicts scopes.Resolution
+}
+
+// getProfilesDir returns the directory holding additional profile databases
+func getProfilesDir() string {
+	return getConfigDir() + "/profiles"
+}
+
+// listProfiles returns the default profile, the team cache if present, and
+// every profile found under ~/.memorypilot/profiles/<name>/memories.db
+func listProfiles() []profile {
+	var profiles []profile
+
+	defaultDB := getDataDir() + "/memories.db"
+	if _, err := os.Stat(defaultDB); err == nil {
+		profiles = append(profiles, profile{Name: "default", DBPath: defaultDB})
+	}
+	if _, err := os.Stat(getTeamCachePath()); err == nil {
+		profiles = append(profiles, profile{Name: teamCacheProfile, DBPath: getTeamCachePath()})
+	}
+
+	entries, err := os.ReadDir(getProfilesDir())
+	if err != nil {
+		return profiles
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dbPath := filepath.Join(getProfilesDir(), entry.Name(), "memories.db")
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		profiles = append(profiles, profile{Name: entry.Name(), DBPath: dbPath})
+	}
+
+	return profiles
+}
+
+// recallAllProfiles runs the recall query against every profile database and
+// merges the ranked results
+func recallAllProfiles(cmd *cobra.Command, query string) error {
+	profiles := listProfiles()
+	if len(profiles) == 0 {
+		fmt.Println("❌ MemoryPilot not initialized")
+		fmt.Println("   Run 'memorypilot init' to get started")
+		return nil
+	}
+
+	mode, err := scopeConflictMode(cmd)
+	if err != nil {
+		return err
+	}
+	queryEmb := embedQuery(cmd, query)
+
+	var ranked [][]profiledMemory
+	embeddings := make(map[string][]float32)
+	for _, p := range profiles {
+		s, err := store.New(p.DBPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping profile %s: %v\n", p.Name, err)
+			continue
+		}
+
+		memories, err := searchMemories(cmd, s, query, queryEmb)
+		if err != nil {
+			s.Close()
+			fmt.Fprintf(os.Stderr, "Warning: skipping profile %s: %v\n", p.Name, err)
+			continue
+		}
+
+		// Team cache results carry local annotations and a staleness flag
+		var annotations map[string]store.Annotation
+		stale := false
+		if p.Name == teamCacheProfile {
+			annotations, _ = s.GetAnnotations()
+			stale = teamsync.IsStale(s)
+		}
+		if mode != scopes.ModeOff {
+			found, err := s.MemoryEmbeddings(memoryIDs(memories))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: no embeddings for profile %s: %v\n", p.Name, err)
+			}
+			for id, e := range found {
+				embeddings[id] = e
+			}
+		}
+		s.Close()
+
+		results := make([]profiledMemory, len(memories))
+		for i, m := range memories {
+			a := annotations[m.ID]
+			results[i] = profiledMemory{Profile: p.Name, Pinned: a.Pinned, Stale: stale, Feedback: a.Feedback, Memory: m}
+		}
+		ranked = append(ranked, results)
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	merged := mergeRanked(ranked, limit)
+
+	// Scope conflicts are resolved across profiles, so a personal memory
+	// overrides a team cache one
+	memories := make([]models.Memory, len(merged))
+	for i, m := range merged {
+		memories[i] = m.Memory
+	}
+	resolved := resolveScopes(memories, embeddings, mode)
+	if mode == scopes.ModeStrict {
+		// Resolutions keep their order, leaving out overridden memories
+		kept := merged[:0]
+		for _, m := range merged {
+			if len(kept) < len(resolved) && resolved[len(kept)].Memory.ID == m.ID {
+				kept = append(kept, m)
+			}
+		}
+		merged = kept
+	}
+	for i, r := range resolved {
+		merged[i].conflicts = r
+		merged[i].Overrides = memoryIDs(r.Overrides)
+		if r.OverriddenBy != nil {
+			merged[i].OverriddenBy = r.OverriddenBy.ID
+		}
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		data, _ := json.MarshalIndent(merged, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(merged) == 0 {
+		fmt.Printf("🔍 No memories found in %d profiles for: %q\n", len(profiles), query)
+		return nil
+	}
+
+	fmt.Printf("🧠 Found %d memories across %d profiles for: %q\n\n", len(merged), len(profiles), query)
+
+	for i, m := range merged {
+		fmt.Printf("%s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		fmt.Printf("   %s\n", m.Content)
+		fmt.Printf("   📂 %s%s | 📅 %s | 🎯 %.0f%% confidence\n", m.Profile, profileBadges(m), m.CreatedAt.Format("2006-01-02"), m.Confidence*100)
+		if m.Feedback != "" {
+			fmt.Printf("   💬 %s\n", m.Feedback)
+		}
+		if len(m.Topics) > 0 {
+			fmt.Printf("   🏷️  %s\n", strings.Join(m.Topics, ", "))
+		}
+		printOwnership(m.Memory)
+		printScopeConflicts(m.conflicts.OverriddenBy, m.conflicts.Overrides)
+		if i < len(merged)-1 {
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// profileBadges renders pin and staleness markers for a result
+func profileBadges(m profiledMemory) string {
+	var badges string
+	if m.Pinned {
+		badges += " 📍 pinned"
+	}
+	if m.Stale {
+		badges += " ⚠️ stale"
+	}
+	return badges
+}
+
+// mergeRanked combines per-profile result lists using reciprocal rank fusion.
+// Scores from different databases aren't comparable, so each result is scored
+// by its position in its own list; pinned results score as if ranked first
+// and ties fall back to importance.
+func mergeRanked(lists [][]profiledMemory, limit int) []profiledMemory {
+	const k = 60.0
+
+	type scored struct {
+		memory profiledMemory
+		score  float64
+	}
+
+	var all []scored
+	for _, list := range lists {
+		for rank, m := range list {
+			if m.Pinned {
+				rank = 0
+			}
+			all = append(all, scored{memory: m, score: 1.0 / (k + float64(rank+1))})
+		}
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].score != all[j].score {
+			return all[i].score > all[j].score
+		}
+		return all[i].memory.Importance > all[j].memory.Importance
+	})
+
+	var merged []profiledMemory
+	for i := 0; i < len(all) && (limit <= 0 || i < limit); i++ {
+		merged = append(merged, all[i].memory)
+	}
+	return merged
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/publish"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+	"github.com/spf13/cobra"
+)
+
+var publishCmd = &cobra.Command{
+	Use:   "publish",
+	Short: "Keep a Notion database or Confluence page in sync with memories",
+	Long: `Publish a selection of memories to a team knowledge base. The daemon
+republishes each target on its schedule (hourly by default).
+
+Notion: each memory becomes a page in a database, updated when the memory
+changes and archived when it is no longer selected. Share the database with
+your integration and set NOTION_TOKEN. Missing properties (Type, Scope,
+Topics, Content, Memory ID, Created) are added to the database.
+
+Confluence: the page body is replaced with the selected memories grouped by
+type. Set CONFLUENCE_URL (e.g. https://acme.atlassian.net/wiki),
+CONFLUENCE_EMAIL and CONFLUENCE_API_TOKEN (or only CONFLUENCE_API_TOKEN for a
+Data Center personal access token).
+
+The daemon needs the same variables in its environment.
+
+Examples:
+  memorypilot publish add notion 1a2b3c... --type decision --scope team
+  memorypilot publish add confluence 123456 --topic deploy --every 30m
+  memorypilot publish run --dry-run`,
+}
+
+var publishAddCmd = &cobra.Command{
+	Use:   "add [notion|confluence] [database-or-page-id]",
+	Short: "Add a Notion database or Confluence page to publish to",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind, destination := args[0], args[1]
+		if kind != publish.KindNotion && kind != publish.KindConfluence {
+			return fmt.Errorf("unknown target %q (use notion or confluence)", kind)
+		}
+		every, _ := cmd.Flags().GetDuration("every")
+		if every < time.Minute {
+			return fmt.Errorf("--every must be at least 1m")
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		var filter publish.Filter
+		types, _ := cmd.Flags().GetStringSlice("type")
+		for _, t := range types {
+			filter.Types = append(filter.Types, models.MemoryType(t))
+		}
+		scopes, _ := cmd.Flags().GetStringSlice("scope")
+		for _, sc := range scopes {
+			filter.Scopes = append(filter.Scopes, models.MemoryScope(sc))
+		}
+		filter.Topics, _ = cmd.Flags().GetStringSlice("topic")
+		if name, _ := cmd.Flags().GetString("project"); name != "" {
+			project, err := s.GetProjectByName(name)
+			if err == nil && project == nil {
+				if abs, absErr := filepath.Abs(name); absErr == nil {
+					project, err = s.GetProjectByPath(abs)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to look up project: %w", err)
+			}
+			if project == nil {
+				return fmt.Errorf("unknown project %q", name)
+			}
+			filter.ProjectID = &project.ID
+		}
+
+		t := &store.PublishTarget{
+			ID:          ulid.Make().String(),
+			Kind:        kind,
+			Destination: destination,
+			Filter:      filter.Encode(),
+			Interval:    every,
+			CreatedAt:   time.Now(),
+		}
+		if err := s.AddPublishTarget(t); err != nil {
+			return fmt.Errorf("failed to add publish target: %w", err)
+		}
+
+		fmt.Printf("✅ Publishing to %s %s\n", kind, destination)
+		fmt.Printf("   🆔 %s\n", t.ID)
+		fmt.Printf("   📋 %s, every %s\n", filter.Describe(), every)
+		if _, err := publish.New(kind, publish.CredentialsFromEnv()); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+		}
+		fmt.Println("   Run 'memorypilot publish run' to publish now")
+		return nil
+	},
+}
+
+var publishListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List publish targets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		targets, err := s.ListPublishTargets()
+		if err != nil {
+			return fmt.Errorf("failed to list publish targets: %w", err)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, _ := json.MarshalIndent(targets, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(targets) == 0 {
+			fmt.Println("📤 No publish targets")
+			fmt.Println("   Add one with 'memorypilot publish add notion <database-id>'")
+			return nil
+		}
+
+		for i, t := range targets {
+			filter, _ := publish.ParseFilter(t.Filter)
+			fmt.Printf("📤 %s %s\n", t.Kind, t.Destination)
+			fmt.Printf("   🆔 %s | 📋 %s | 🔁 every %s\n", t.ID, filter.Describe(), t.Interval)
+			switch {
+			case t.LastRunAt == nil:
+				fmt.Println("   ⏳ Not published yet")
+			case t.LastError != "":
+				fmt.Printf("   ⚠️  Last run %s failed: %s\n", t.LastRunAt.Format("2006-01-02 15:04"), t.LastError)
+			default:
+				fmt.Printf("   ✓ Last published %s\n", t.LastRunAt.Format("2006-01-02 15:04"))
+			}
+			if i < len(targets)-1 {
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
+
+var publishRemoveCmd = &cobra.Command{
+	Use:   "remove [target-id]",
+	Short: "Stop publishing to a target (published pages are kept)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		if err := s.RemovePublishTarget(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed publish target %s\n", args[0])
+		return nil
+	},
+}
+
+var publishRunCmd = &cobra.Command{
+	Use:   "run [target-id...]",
+	Short: "Publish now (all targets, or the given ones)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		targets, err := s.ListPublishTargets()
+		if err != nil {
+			return fmt.Errorf("failed to list publish targets: %w", err)
+		}
+		if len(args) > 0 {
+			wanted := make(map[string]bool)
+			for _, id := range args {
+				wanted[id] = true
+			}
+			var selected []store.PublishTarget
+			for _, t := range targets {
+				if wanted[t.ID] {
+					selected = append(selected, t)
+					delete(wanted, t.ID)
+				}
+			}
+			for id := range wanted {
+				return fmt.Errorf("publish target %s not found", id)
+			}
+			targets = selected
+		}
+		if len(targets) == 0 {
+			fmt.Println("📤 No publish targets")
+			return nil
+		}
+
+		creds := publish.CredentialsFromEnv()
+		failed := 0
+		for _, t := range targets {
+			res, err := publish.Run(context.Background(), s, t, creds, dryRun)
+			if err != nil {
+				failed++
+				fmt.Printf("❌ %s %s: %v\n", t.Kind, t.Destination, err)
+				continue
+			}
+			verb := "Published"
+			if dryRun {
+				verb = "Would publish"
+			}
+			fmt.Printf("✅ %s to %s %s: %d new, %d updated, %d archived, %d unchanged\n",
+				verb, t.Kind, t.Destination, res.Published, res.Updated, res.Archived, res.Unchanged)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d targets failed", failed, len(targets))
+		}
+		return nil
+	},
+}
+
+func init() {
+	publishCmd.AddCommand(publishAddCmd)
+	publishCmd.AddCommand(publishListCmd)
+	publishCmd.AddCommand(publishRemoveCmd)
+	publishCmd.AddCommand(publishRunCmd)
+
+	publishAddCmd.Flags().StringSlice("type", nil, "Only memories of these types (e.g. decision)")
+	publishAddCmd.Flags().StringSlice("scope", nil, "Only memories with these scopes (e.g. team)")
+	publishAddCmd.Flags().StringSlice("topic", nil, "Only memories with any of these topics")
+	publishAddCmd.Flags().String("project", "", "Only memories of this project (name or path) and global ones")
+	publishAddCmd.Flags().Duration("every", publish.DefaultInterval, "How often the daemon republishes")
+	publishListCmd.Flags().Bool("json", false, "Output as JSON")
+	publishRunCmd.Flags().Bool("dry-run", false, "Show what would change without publishing")
+}
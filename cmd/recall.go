@@ -6,7 +6,7 @@ import (
 	"os"
 	"strings"
 
-	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/scopes"
 	"github.com/memorypilot/memorypilot/internal/store"
 	"github.com/memorypilot/memorypilot/pkg/models"
 	"github.com/spf13/cobra"
@@ -20,108 +20,325 @@ var recallCmd = &cobra.Command{
 Examples:
   memorypilot recall "authentication patterns"
   memorypilot recall "how did we handle rate limiting"
-  memorypilot recall --type decision "database choice"`,
+  memorypilot recall --type decision "database choice"
+  memorypilot recall --project api --scope project "rate limiting"
+  memorypilot recall --all-profiles "deploy checklist"
+  memorypilot recall "auth -oauth -saml"
+  memorypilot recall --exclude-topic oauth --exclude-type mistake auth
+
+Words starting with "-" exclude memories that mention them. Quote the query
+(or put it after "--") so they aren't read as flags. --exclude-topic drops
+memories tagged with a topic and --exclude-type drops a memory type; both
+can be repeated.
+
+--type, --scope and --project narrow semantic and keyword search alike:
+results are drawn only from matching memories, before ranking. --project
+keeps memories of that project (by name or path) and ones tied to none.
+
+Profiles are extra databases stored under ~/.memorypilot/profiles/<name>/.
+With --all-profiles every profile is searched and results are labelled
+with the profile they came from.
+
+When a memory contradicts one of the same type from a wider scope, the
+narrower scope wins: personal overrides project, team and org memories.
+Both are shown with a note on which overrides which; --scope-conflicts
+strict shows only the winner and off disables the check. The default comes
+from recall.scopeConflicts in config.yaml.
+
+When MEMORYPILOT_REMOTE_URL is set, recall runs against that team server
+(authenticating with MEMORYPILOT_REMOTE_TOKEN) and no local database is
+needed.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := strings.Join(args, " ")
-		
-		dataDir := getDataDir()
-		dbPath := dataDir + "/memories.db"
-		
-		// Check if database exists
-		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-			fmt.Println("❌ MemoryPilot not initialized")
-			fmt.Println("   Run 'memorypilot init' to get started")
-			return nil
+
+		// Fan out across every profile database if requested
+		allProfiles, _ := cmd.Flags().GetBool("all-profiles")
+		if allProfiles {
+			return recallAllProfiles(cmd, query)
 		}
-		
-		// Open store
-		s, err := store.New(dbPath)
+
+		mode, err := scopeConflictMode(cmd)
+		if err != nil {
+			return err
+		}
+
+		client, err := remoteClient()
 		if err != nil {
-			return fmt.Errorf("failed to open store: %w", err)
+			return err
 		}
-		defer s.Close()
-		
-		// Build recall request
-		limit, _ := cmd.Flags().GetInt("limit")
-		typeFilter, _ := cmd.Flags().GetString("type")
-		scopeFilter, _ := cmd.Flags().GetStringSlice("scope")
-		semantic, _ := cmd.Flags().GetBool("semantic")
-		
+
 		var memories []models.Memory
-		
-		if semantic {
-			// Try semantic search with embeddings
-			embedder := embedding.NewOllamaEmbedder("", "nomic-embed-text")
-			queryEmb, err := embedder.Embed(query)
+		var embeddings map[string][]float32
+		if client != nil {
+			// Remote team server: no local database needed
+			if name, _ := cmd.Flags().GetString("project"); name != "" {
+				return fmt.Errorf("--project can't be looked up on a remote server")
+			}
+			req := recallRequest(cmd, query)
+			memories, err = client.Recall(req)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Semantic search unavailable (%v), falling back to keyword search\n", err)
-				semantic = false
-			} else {
-				memories, err = s.HybridSearch(query, queryEmb, limit)
-				if err != nil {
-					return fmt.Errorf("hybrid search failed: %w", err)
-				}
+				return fmt.Errorf("remote recall failed: %w", err)
 			}
-		}
-		
-		if !semantic {
-			// Keyword search
-			req := models.RecallRequest{
-				Query: query,
-				Limit: limit,
+
+			// Older servers ignore exclusions, so apply them here as well
+			memories = excludeMemories(req, memories)
+		} else {
+			dataDir := getDataDir()
+			dbPath := dataDir + "/memories.db"
+
+			// Check if database exists
+			if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+				fmt.Println("❌ MemoryPilot not initialized")
+				fmt.Println("   Run 'memorypilot init' to get started")
+				return nil
+			}
+
+			// Open store
+			s, err := store.New(dbPath)
+			if err != nil {
+				return fmt.Errorf("failed to open store: %w", err)
 			}
-			
-			if typeFilter != "" {
-				req.Types = []models.MemoryType{models.MemoryType(typeFilter)}
+			defer s.Close()
+
+			memories, err = searchMemories(cmd, s, query, embedQuery(cmd, query))
+			if err != nil {
+				return err
 			}
-			
-			if len(scopeFilter) > 0 {
-				for _, sc := range scopeFilter {
-					req.Scope = append(req.Scope, models.MemoryScope(sc))
+			if mode != scopes.ModeOff {
+				if embeddings, err = s.MemoryEmbeddings(memoryIDs(memories)); err != nil {
+					return fmt.Errorf("failed to load embeddings: %w", err)
 				}
 			}
-			
-			var err error
-			memories, err = s.Recall(req)
-			if err != nil {
-				return fmt.Errorf("recall failed: %w", err)
+		}
+
+		// Narrower scopes override wider ones they contradict
+		resolved := resolveScopes(memories, embeddings, mode)
+
+		// GitHub Actions annotations for CI logs
+		github, _ := cmd.Flags().GetBool("github")
+		if github {
+			for _, r := range resolved {
+				fmt.Println(githubAnnotation("", r.Memory))
 			}
+			return nil
 		}
-		
+
 		// Check if JSON output requested
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 		if jsonOutput {
-			data, _ := json.MarshalIndent(memories, "", "  ")
+			results := make([]recalledMemory, len(resolved))
+			for i, r := range resolved {
+				results[i] = recalledMemory{Memory: r.Memory, Overrides: memoryIDs(r.Overrides)}
+				if r.OverriddenBy != nil {
+					results[i].OverriddenBy = r.OverriddenBy.ID
+				}
+			}
+			data, _ := json.MarshalIndent(results, "", "  ")
 			fmt.Println(string(data))
 			return nil
 		}
-		
+
 		// Pretty print
-		if len(memories) == 0 {
+		if len(resolved) == 0 {
 			fmt.Printf("🔍 No memories found for: %q\n", query)
 			return nil
 		}
-		
-		fmt.Printf("🧠 Found %d memories for: %q\n\n", len(memories), query)
-		
-		for i, m := range memories {
+
+		fmt.Printf("🧠 Found %d memories for: %q\n\n", len(resolved), query)
+
+		for i, r := range resolved {
+			m := r.Memory
 			typeEmoji := getTypeEmoji(m.Type)
 			fmt.Printf("%s [%s] %s\n", typeEmoji, m.Type, m.Summary)
-			fmt.Printf("   %s\n", m.Content)
+			if m.Score != nil && m.Score.Excerpt != "" {
+				// Long content: the passage that matched
+				fmt.Printf("   %s\n", models.Highlight(m.Score.Excerpt, query))
+				fmt.Printf("   (excerpt of %d characters; 'memorypilot show %s' for all)\n", len(m.Content), m.ID)
+			} else {
+				fmt.Printf("   %s\n", m.Content)
+			}
 			fmt.Printf("   📅 %s | 🎯 %.0f%% confidence\n", m.CreatedAt.Format("2006-01-02"), m.Confidence*100)
 			if len(m.Topics) > 0 {
 				fmt.Printf("   🏷️  %s\n", strings.Join(m.Topics, ", "))
 			}
-			if i < len(memories)-1 {
+			printOwnership(m)
+			printScopeConflicts(r.OverriddenBy, r.Overrides)
+			if i < len(resolved)-1 {
 				fmt.Println()
 			}
 		}
-		
+
 		return nil
 	},
 }
 
+// recalledMemory is a recall result with the scope conflicts it is part of
+type recalledMemory struct {
+	models.Memory
+	OverriddenBy string   `json:"overriddenBy,omitempty"`
+	Overrides    []string `json:"overrides,omitempty"`
+}
+
+// scopeConflictMode returns the --scope-conflicts mode, falling back to
+// recall.scopeConflicts in the config file
+func scopeConflictMode(cmd *cobra.Command) (scopes.Mode, error) {
+	name, _ := cmd.Flags().GetString("scope-conflicts")
+	if name == "" {
+		tuning, err := loadTuning()
+		if err != nil {
+			return "", fmt.Errorf("failed to load config: %w", err)
+		}
+		name = tuning.ScopeConflicts
+	}
+	return scopes.ParseMode(name)
+}
+
+// resolveScopes finds scope conflicts among recall results; embeddings may
+// be missing, in which case topics are compared instead
+func resolveScopes(memories []models.Memory, embeddings map[string][]float32, mode scopes.Mode) []scopes.Resolution {
+	candidates := make([]scopes.Candidate, len(memories))
+	for i, m := range memories {
+		candidates[i] = scopes.Candidate{Memory: m, Embedding: embeddings[m.ID]}
+	}
+	return scopes.Resolve(candidates, mode)
+}
+
+// printOwnership says whose a shared memory is; personal memories are
+// your own
+func printOwnership(m models.Memory) {
+	if m.Scope == models.MemoryScopePersonal || m.Author == "" {
+		return
+	}
+	owner := "👤 " + m.Author
+	if m.Maintainer != "" && m.Maintainer != m.Author {
+		owner += " | 🛠️  maintained by " + m.Maintainer
+	}
+	fmt.Printf("   %s\n", owner)
+}
+
+// printScopeConflicts notes which memories a result overrides or is
+// overridden by
+func printScopeConflicts(overriddenBy *models.Memory, overrides []models.Memory) {
+	if overriddenBy != nil {
+		fmt.Printf("   ↪️  Overridden by %s memory: %s (%s)\n", overriddenBy.Scope, overriddenBy.Summary, overriddenBy.ID)
+	}
+	for _, o := range overrides {
+		fmt.Printf("   🔀 Overrides %s memory: %s (%s)\n", o.Scope, o.Summary, o.ID)
+	}
+}
+
+func memoryIDs(memories []models.Memory) []string {
+	ids := make([]string, len(memories))
+	for i, m := range memories {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// embedQuery returns the query embedding for semantic search, or nil when
+// semantic search is disabled or the embedder is unavailable
+func embedQuery(cmd *cobra.Command, query string) []float32 {
+	semantic, _ := cmd.Flags().GetBool("semantic")
+	if !semantic {
+		return nil
+	}
+
+	// Exclusion words shouldn't pull the query vector towards them
+	text, _ := models.ParseQuery(query)
+	if text == "" {
+		return nil
+	}
+
+	embedder := configuredEmbedder()
+	queryEmb, err := embedder.Embed(text)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Semantic search unavailable (%v), falling back to keyword search\n", err)
+		return nil
+	}
+	return queryEmb
+}
+
+// searchMemories runs the recall request described by the command flags
+// against a single store
+func searchMemories(cmd *cobra.Command, s *store.Store, query string, queryEmb []float32) ([]models.Memory, error) {
+	req := recallRequest(cmd, query)
+
+	// Project IDs differ between stores, so --project is looked up in each
+	if name, _ := cmd.Flags().GetString("project"); name != "" {
+		project, err := findProject(s, name)
+		if err != nil {
+			return nil, err
+		}
+		req.ProjectID = &project.ID
+	}
+
+	if len(queryEmb) > 0 {
+		tuning, err := loadTuning()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		s.SetSearchWeights(tuning.Search)
+		memories, err := s.HybridSearch(req, queryEmb)
+		if err != nil {
+			return nil, fmt.Errorf("hybrid search failed: %w", err)
+		}
+		return memories, nil
+	}
+
+	// Keyword search
+	memories, err := s.Recall(req)
+	if err != nil {
+		return nil, fmt.Errorf("recall failed: %w", err)
+	}
+	return memories, nil
+}
+
+// recallRequest builds a keyword recall request from the command flags
+func recallRequest(cmd *cobra.Command, query string) models.RecallRequest {
+	limit, _ := cmd.Flags().GetInt("limit")
+	typeFilter, _ := cmd.Flags().GetString("type")
+	scopeFilter, _ := cmd.Flags().GetStringSlice("scope")
+
+	req := models.RecallRequest{
+		Query: query,
+		Limit: limit,
+	}
+
+	if typeFilter != "" {
+		req.Types = []models.MemoryType{models.MemoryType(typeFilter)}
+	}
+
+	if len(scopeFilter) > 0 {
+		for _, sc := range scopeFilter {
+			req.Scope = append(req.Scope, models.MemoryScope(sc))
+		}
+	}
+
+	req.ExcludeTopics, _ = cmd.Flags().GetStringSlice("exclude-topic")
+	excludeTypes, _ := cmd.Flags().GetStringSlice("exclude-type")
+	for _, t := range excludeTypes {
+		req.ExcludeTypes = append(req.ExcludeTypes, models.MemoryType(t))
+	}
+
+	return req
+}
+
+// excludeMemories drops results ruled out by the request's exclusions,
+// including "-term" words in its query
+func excludeMemories(req models.RecallRequest, memories []models.Memory) []models.Memory {
+	_, terms := models.ParseQuery(req.Query)
+	req.ExcludeTerms = append(terms, req.ExcludeTerms...)
+
+	kept := memories[:0]
+	for _, m := range memories {
+		if !req.Excludes(m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
 func getTypeEmoji(t models.MemoryType) string {
 	switch t {
 	case models.MemoryTypeDecision:
@@ -136,6 +353,8 @@ func getTypeEmoji(t models.MemoryType) string {
 		return "⚠️"
 	case models.MemoryTypeLearning:
 		return "💡"
+	case models.MemoryTypeContext:
+		return "📸"
 	default:
 		return "📝"
 	}
@@ -145,6 +364,12 @@ func init() {
 	recallCmd.Flags().IntP("limit", "l", 5, "Maximum number of results")
 	recallCmd.Flags().StringP("type", "t", "", "Filter by memory type (decision|pattern|fact|preference|mistake|learning)")
 	recallCmd.Flags().StringSliceP("scope", "s", []string{}, "Filter by scope (personal|project|team)")
+	recallCmd.Flags().String("project", "", "Only memories of this project (name or path) and global ones")
+	recallCmd.Flags().StringSlice("exclude-topic", []string{}, "Leave out memories tagged with this topic")
+	recallCmd.Flags().StringSlice("exclude-type", []string{}, "Leave out memories of this type")
 	recallCmd.Flags().Bool("json", false, "Output as JSON")
+	recallCmd.Flags().Bool("github", false, "Output as GitHub Actions annotations")
 	recallCmd.Flags().BoolP("semantic", "S", true, "Use semantic search (requires Ollama)")
+	recallCmd.Flags().Bool("all-profiles", false, "Search every profile database and merge the results")
+	recallCmd.Flags().String("scope-conflicts", "", "How narrower scopes override wider ones: annotate|strict|off (default from config, annotate)")
 }
@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/identity"
 	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/memorypilot/memorypilot/internal/textutil"
 	"github.com/memorypilot/memorypilot/pkg/models"
 	"github.com/oklog/ulid/v2"
 	"github.com/spf13/cobra"
@@ -20,40 +25,44 @@ var rememberCmd = &cobra.Command{
 Examples:
   memorypilot remember "Always validate JWT tokens server-side"
   memorypilot remember --type decision "Chose PostgreSQL for ACID compliance"
-  memorypilot remember --type mistake "Don't use float for currency"`,
+  memorypilot remember --type mistake "Don't use float for currency"
+  memorypilot remember --scope team --sign "Always run migrations in a transaction"
+  memorypilot remember --ttl 14d "Staging is frozen for the release"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		content := strings.Join(args, " ")
-		
+
 		dataDir := getDataDir()
 		dbPath := dataDir + "/memories.db"
-		
+
 		// Check if database exists
 		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 			fmt.Println("❌ MemoryPilot not initialized")
 			fmt.Println("   Run 'memorypilot init' to get started")
 			return nil
 		}
-		
+
 		// Open store
 		s, err := store.New(dbPath)
 		if err != nil {
 			return fmt.Errorf("failed to open store: %w", err)
 		}
 		defer s.Close()
-		
+
 		// Get flags
 		memoryType, _ := cmd.Flags().GetString("type")
 		topics, _ := cmd.Flags().GetStringSlice("topics")
-		
+		scope, _ := cmd.Flags().GetString("scope")
+		sign, _ := cmd.Flags().GetBool("sign")
+
 		// Create memory
 		now := time.Now()
 		memory := models.Memory{
 			ID:      ulid.Make().String(),
 			Type:    models.MemoryType(memoryType),
 			Content: content,
-			Summary: truncate(content, 100),
-			Scope:   models.MemoryScopePersonal,
+			Summary: textutil.Truncate(content, 100),
+			Scope:   models.MemoryScope(scope),
 			Source: models.Source{
 				Type:      models.SourceTypeManual,
 				Reference: "cli",
@@ -66,28 +75,55 @@ Examples:
 			LastAccessedAt: now,
 			AccessCount:    0,
 		}
-		
+
+		if ttl, _ := cmd.Flags().GetString("ttl"); ttl != "" {
+			d, err := parseSpan(ttl)
+			if err != nil {
+				return fmt.Errorf("invalid --ttl %q (e.g. 30d, 12h)", ttl)
+			}
+			expires := now.Add(d)
+			memory.ExpiresAt = &expires
+		}
+
+		memory.Maintainer, _ = cmd.Flags().GetString("maintainer")
+		identity.Attribute(&memory)
+
+		// Sign so teammates can verify authorship after sync
+		if sign {
+			keyPath, _ := cmd.Flags().GetString("key")
+			signer, err := teamsync.LoadSigner(config.ExpandHome(keyPath))
+			if err != nil {
+				return fmt.Errorf("failed to load signing key: %w", err)
+			}
+			signer.Sign(&memory)
+		}
+
 		// Save
 		if err := s.CreateMemory(&memory); err != nil {
+			if errors.Is(err, store.ErrDuplicate) {
+				fmt.Printf("🔁 Already remembered: %s\n", memory.ID)
+				return nil
+			}
 			return fmt.Errorf("failed to save memory: %w", err)
 		}
-		
+
 		fmt.Printf("✅ Memory created: %s\n", memory.ID)
 		fmt.Printf("   Type: %s\n", memory.Type)
 		fmt.Printf("   %s\n", memory.Content)
-		
+		if memory.ExpiresAt != nil {
+			fmt.Printf("   ⏳ Expires %s\n", memory.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+
 		return nil
 	},
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
-
 func init() {
+	rememberCmd.Flags().String("maintainer", "", "Who keeps this memory up to date (default: you, from git config)")
 	rememberCmd.Flags().StringP("type", "t", "fact", "Memory type (decision|pattern|fact|preference|mistake|learning)")
 	rememberCmd.Flags().StringSliceP("topics", "T", []string{}, "Topics/tags for this memory")
+	rememberCmd.Flags().String("scope", "personal", "Memory scope (personal|project|team|org)")
+	rememberCmd.Flags().Bool("sign", false, "Sign the memory with your SSH key (for team/org scope)")
+	rememberCmd.Flags().String("key", "~/.ssh/id_ed25519", "ed25519 SSH private key used by --sign")
+	rememberCmd.Flags().String("ttl", "", "Forget this memory after a while (e.g. 30d, 12h)")
 }
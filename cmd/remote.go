@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// remoteClient returns a client for the team server named by
+// MEMORYPILOT_REMOTE_URL, or nil when commands should use local databases
+func remoteClient() (*teamsync.Client, error) {
+	endpoint := strings.TrimRight(os.Getenv("MEMORYPILOT_REMOTE_URL"), "/")
+	if endpoint == "" {
+		return nil, nil
+	}
+	return teamsync.NewClient(endpoint, os.Getenv("MEMORYPILOT_REMOTE_TOKEN"))
+}
+
+// githubAnnotation formats a memory as a GitHub Actions workflow command,
+// attached to file when it is non-empty
+func githubAnnotation(file string, m models.Memory) string {
+	level := "notice"
+	if m.Type == models.MemoryTypeMistake {
+		level = "warning"
+	}
+
+	props := []string{"title=" + escapeGitHubProperty(fmt.Sprintf("MemoryPilot %s", m.Type))}
+	if file != "" {
+		props = append([]string{"file=" + escapeGitHubProperty(file)}, props...)
+	}
+
+	return fmt.Sprintf("::%s %s::%s", level, strings.Join(props, ","), escapeGitHubData(m.Content))
+}
+
+// escapeGitHubData escapes a workflow command message
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a workflow command property value
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
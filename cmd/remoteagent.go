@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/watcher"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var remoteAgentCmd = &cobra.Command{
+	Use:   "remote-agent",
+	Short: "Capture git and terminal events on a dev server for a local daemon",
+	Long: `Run the git and terminal watchers on this machine and stream their events
+as JSON lines on stdout. It is meant to be started by the daemon on your
+own machine over ssh, which authenticates the connection:
+
+  memorypilot daemon start --remote devbox
+
+Nothing is stored or extracted here, and no port is opened. Install the
+shell hook (memorypilot shell-hook) on the server to record directories.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("git-interval")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		// stdout carries events; logs would only pile up on the other end
+		if !verbose {
+			log.SetOutput(io.Discard)
+		}
+
+		events := make(chan models.Event, 100)
+
+		gitWatcher := watcher.NewGitWatcher(interval, nil, events)
+		termWatcher := watcher.NewTerminalWatcher(events)
+		termWatcher.UseCwdLog(filepath.Join(getDataDir(), watcher.CwdLogName))
+		watchers := []watcher.Watcher{gitWatcher, termWatcher}
+		for _, w := range watchers {
+			if err := w.Start(); err != nil {
+				return err
+			}
+			defer w.Stop()
+		}
+
+		// ssh sends SIGHUP when the connection closes
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+		enc := json.NewEncoder(os.Stdout)
+		for {
+			select {
+			case <-sigChan:
+				return nil
+			case e := <-events:
+				if err := enc.Encode(e); err != nil {
+					return fmt.Errorf("connection closed: %w", err)
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	remoteAgentCmd.Flags().Duration("git-interval", config.DefaultTuning().GitInterval, "How often repositories are checked for commits")
+	remoteAgentCmd.Flags().Bool("verbose", false, "Log watcher activity to stderr")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/memorypilot/memorypilot/internal/projects"
+	"github.com/memorypilot/memorypilot/internal/watcher"
+	"github.com/spf13/cobra"
+)
+
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Manage the registry of watched git repositories",
+	Long: `Manage the registry of watched git repositories.
+
+The daemon only polls registered repos for new commits and re-walks your
+code directories once an hour. Run 'memorypilot repos scan' to pick up a
+freshly cloned repo immediately.`,
+}
+
+var reposListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered repositories",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		repos, err := s.ListRepos()
+		if err != nil {
+			return fmt.Errorf("failed to list repos: %w", err)
+		}
+
+		if len(repos) == 0 {
+			fmt.Println("📁 No repositories registered yet")
+			fmt.Println("   Run 'memorypilot repos scan' to discover them")
+			return nil
+		}
+
+		fmt.Printf("📁 %d registered repositories\n\n", len(repos))
+		for _, repo := range repos {
+			fmt.Printf("   %s\n", repo)
+		}
+		return nil
+	},
+}
+
+var reposScanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Discover repositories now and update the registry",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		repos := watcher.DiscoverRepos(watcher.DefaultCodeDirs())
+		if err := s.SaveRepos(repos); err != nil {
+			return fmt.Errorf("failed to save repos: %w", err)
+		}
+
+		// Detect each project's languages and frameworks
+		for _, repo := range repos {
+			if _, err := projects.RefreshStack(s, repo); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to detect stack for %s: %v\n", repo, err)
+			}
+		}
+
+		fmt.Printf("✅ Registered %d repositories\n", len(repos))
+		return nil
+	},
+}
+
+func init() {
+	reposCmd.AddCommand(reposListCmd)
+	reposCmd.AddCommand(reposScanCmd)
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/reprocess"
+	"github.com/spf13/cobra"
+)
+
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Re-run extraction over past events with the current model and prompts",
+	Long: `Re-extract memories from retained events, e.g. after upgrading the
+extraction model or prompts. Results are diffed against existing memories
+and only genuinely new ones are added.
+
+When the extraction prompts change, the agent flags low-confidence memories
+extracted with older prompts. --flagged re-extracts just their events and
+archives each flagged memory the new prompts replace.
+
+Examples:
+  memorypilot reprocess --since 30d --model llama3.3
+  memorypilot reprocess --since 7d --dry-run
+  memorypilot reprocess --flagged`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		since, err := parseSince(sinceFlag)
+		if err != nil {
+			return err
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		model, _ := cmd.Flags().GetString("model")
+		providers := []string{extractor.ProviderOllama}
+		if chain := os.Getenv("MEMORYPILOT_PROVIDERS"); chain != "" {
+			providers = strings.Split(chain, ",")
+		}
+		ext, err := extractor.NewChain(providers, extractor.ChainConfig{
+			OllamaModel:  model,
+			ClaudeAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
+		})
+		if err != nil {
+			return err
+		}
+		ext.SetExamples(s)
+		tuning, err := loadTuning()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		ext.SetMinConfidence(tuning.MinConfidence)
+
+		var emb embedding.Embedder
+		if noSemantic, _ := cmd.Flags().GetBool("no-semantic"); !noSemantic {
+			emb = configuredEmbedder()
+			s.SetEmbeddingModel(embedding.ModelOf(emb))
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if !dryRun {
+			ext.SetRunRecorder(s)
+		}
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		if batchSize <= 0 {
+			batchSize = tuning.BatchSize
+		}
+
+		opts := reprocess.Options{
+			Since:       since,
+			BatchSize:   batchSize,
+			DryRun:      dryRun,
+			ReviewBelow: tuning.ReviewBelow,
+		}
+		var result *reprocess.Result
+		var runErr error
+		if flagged, _ := cmd.Flags().GetBool("flagged"); flagged {
+			fmt.Printf("🔁 Reprocessing memories flagged from older prompts with %s...\n", model)
+			result, runErr = reprocess.Flagged(s, ext, emb, opts)
+		} else {
+			fmt.Printf("🔁 Reprocessing events since %s with %s...\n", since.Format("2006-01-02 15:04"), model)
+			result, runErr = reprocess.Run(s, ext, emb, opts)
+		}
+		if result == nil {
+			return fmt.Errorf("reprocess failed: %w", runErr)
+		}
+
+		verb := "Added"
+		if dryRun {
+			verb = "Would add"
+		}
+		for _, m := range result.Added {
+			fmt.Printf("   %s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		}
+		fmt.Printf("\n✅ %s %d new memories (%d events, %d batches, %d extracted, %d already known",
+			verb, len(result.Added), result.Events, result.Batches, result.Extracted, result.Duplicates)
+		if result.Failed > 0 {
+			fmt.Printf(", %d batches unusable", result.Failed)
+		}
+		fmt.Println(")")
+		if len(result.Superseded) > 0 {
+			verb = "Archived"
+			if dryRun {
+				verb = "Would archive"
+			}
+			fmt.Printf("🗄️  %s %d memories replaced by the new extraction:\n", verb, len(result.Superseded))
+			for _, m := range result.Superseded {
+				fmt.Printf("   %s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+			}
+		}
+		if result.Missing > 0 {
+			fmt.Printf("⚠️  %d flagged memories were left alone: their events are no longer stored\n", result.Missing)
+		}
+
+		if runErr != nil {
+			return fmt.Errorf("reprocess stopped early: %w", runErr)
+		}
+		return nil
+	},
+}
+
+// parseSince converts a lookback like "30d", "12h" or "90m" into a start time
+func parseSince(value string) (time.Time, error) {
+	d, err := parseSpan(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q (e.g. 30d, 12h)", value)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// parseSpan parses a positive span like "30d", "12h" or "90m"
+func parseSpan(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid span %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid span %q", value)
+	}
+	return d, nil
+}
+
+func init() {
+	reprocessCmd.Flags().String("since", "30d", "How far back to reprocess (e.g. 30d, 12h)")
+	reprocessCmd.Flags().String("model", "llama3.2", "Ollama model used for extraction")
+	reprocessCmd.Flags().Int("batch-size", 0, "Events per extraction batch (default extraction.batchSize)")
+	reprocessCmd.Flags().Bool("flagged", false, "Reprocess memories flagged after a prompt change instead of --since")
+	reprocessCmd.Flags().Bool("dry-run", false, "Show new memories without saving them")
+	reprocessCmd.Flags().Bool("no-semantic", false, "Only treat verbatim matches as duplicates")
+}
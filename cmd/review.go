@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/mining"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Approve, edit or reject memories awaiting review",
+	Long: `Go through proposed memories awaiting review, approving, editing or
+rejecting each. Proposed memories (such as workflows mined from terminal
+history, and extracted memories less confident than extraction.reviewBelow)
+are not recalled until approved.
+
+Edits open $VISUAL or $EDITOR with the summary on the first line and the
+content below it. Without a terminal, or with --list, the queue is listed.
+
+Examples:
+  memorypilot review
+  memorypilot review --list
+  memorypilot review approve 01HX...
+  memorypilot review reject 01HX...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		pending, err := s.ListPending(0)
+		if err != nil {
+			return fmt.Errorf("failed to list pending memories: %w", err)
+		}
+
+		if len(pending) == 0 {
+			fmt.Println("✅ Nothing awaiting review")
+			return nil
+		}
+
+		if list, _ := cmd.Flags().GetBool("list"); list || !stdinIsTerminal() {
+			fmt.Printf("📥 %d memories awaiting review\n\n", len(pending))
+			for i, m := range pending {
+				printPending(m)
+				if i < len(pending)-1 {
+					fmt.Println()
+				}
+			}
+			return nil
+		}
+		return reviewInteractively(s, pending)
+	},
+}
+
+// printPending shows a memory awaiting review
+func printPending(m models.Memory) {
+	fmt.Printf("%s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+	fmt.Printf("   %s\n", indent(m.Content, "   "))
+	fmt.Printf("   🆔 %s | 📅 %s | 🎯 %.0f%% confidence\n", m.ID, m.CreatedAt.Format("2006-01-02"), m.Confidence*100)
+}
+
+// reviewInteractively asks for a decision on each pending memory
+func reviewInteractively(s *store.Store, pending []models.Memory) error {
+	in := bufio.NewReader(os.Stdin)
+	approved, rejected, edited := 0, 0, 0
+	fmt.Printf("📥 %d memories awaiting review\n", len(pending))
+
+	for i := 0; i < len(pending); i++ {
+		m := &pending[i]
+		fmt.Printf("\n(%d/%d) ", i+1, len(pending))
+		printPending(*m)
+		fmt.Print("   [a]pprove  [e]dit  [r]eject  [s]kip  [q]uit: ")
+		answer, err := in.ReadString('\n')
+		if err != nil && answer == "" {
+			break
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "a", "approve":
+			if err := s.SetStatus(m.ID, models.MemoryStatusApproved); err != nil {
+				return err
+			}
+			approved++
+		case "r", "reject":
+			if err := s.SetStatus(m.ID, models.MemoryStatusRejected); err != nil {
+				return err
+			}
+			rejected++
+		case "e", "edit":
+			if changed, err := editPending(s, m, in); err != nil {
+				fmt.Printf("   ⚠️  %v\n", err)
+			} else if changed {
+				edited++
+			}
+			i-- // decide on the edited memory
+		case "s", "skip", "":
+		case "q", "quit":
+			i = len(pending)
+		default:
+			fmt.Println("   Answer a, e, r, s or q")
+			i--
+		}
+	}
+
+	fmt.Printf("\n✅ Approved %d, rejected %d, edited %d", approved, rejected, edited)
+	if left := len(pending) - approved - rejected; left > 0 {
+		fmt.Printf("; %d still awaiting review", left)
+	}
+	fmt.Println()
+	return nil
+}
+
+// editPending changes a memory's summary and content, in $VISUAL or
+// $EDITOR if set, else by reading new content from the terminal. It
+// reports whether the memory changed.
+func editPending(s *store.Store, m *models.Memory, in *bufio.Reader) (bool, error) {
+	summary, content := m.Summary, m.Content
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor != "" {
+		f, err := os.CreateTemp("", "memorypilot-review-*.md")
+		if err != nil {
+			return false, err
+		}
+		defer os.Remove(f.Name())
+		fmt.Fprintf(f, "%s\n\n%s\n", m.Summary, m.Content)
+		f.Close()
+
+		args := append(strings.Fields(editor), f.Name())
+		c := exec.Command(args[0], args[1:]...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := c.Run(); err != nil {
+			return false, fmt.Errorf("editor failed: %w", err)
+		}
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			return false, err
+		}
+		first, rest, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+		summary, content = strings.TrimSpace(first), strings.TrimSpace(rest)
+		if content == "" {
+			content = summary
+		}
+	} else {
+		fmt.Print("   New content (empty keeps it): ")
+		line, _ := in.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			content = line
+		}
+		fmt.Print("   New summary (empty keeps it): ")
+		line, _ = in.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			summary = line
+		}
+	}
+	if summary == "" {
+		return false, fmt.Errorf("summary can't be empty; memory left as it was")
+	}
+	if summary == m.Summary && content == m.Content {
+		fmt.Println("   Unchanged")
+		return false, nil
+	}
+
+	// Edited content is re-embedded; otherwise the stored embedding stays
+	updated := *m
+	updated.Summary, updated.Content = summary, content
+	if content != m.Content {
+		emb := configuredEmbedder()
+		s.SetEmbeddingModel(embedding.ModelOf(emb))
+		updated.Embedding, _ = emb.Embed(content)
+	} else {
+		embeddings, err := s.MemoryEmbeddings([]string{m.ID})
+		if err != nil {
+			return false, err
+		}
+		updated.Embedding = embeddings[m.ID]
+	}
+	if err := s.UpdateMemory(&updated); err != nil {
+		switch {
+		case errors.Is(err, store.ErrDuplicate):
+			return false, fmt.Errorf("an identical memory already exists")
+		case errors.Is(err, store.ErrHeld):
+			return false, fmt.Errorf("memory %s is under legal hold and can't be changed", m.ID)
+		}
+		return false, err
+	}
+	*m = updated
+	return true, nil
+}
+
+// stdinIsTerminal reports whether input comes from a terminal rather than
+// a pipe or file
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var reviewApproveCmd = &cobra.Command{
+	Use:   "approve [memory-id...]",
+	Short: "Approve proposed memories so they are recalled",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setReviewStatus(args, models.MemoryStatusApproved, "✅ Approved")
+	},
+}
+
+var reviewRejectCmd = &cobra.Command{
+	Use:   "reject [memory-id...]",
+	Short: "Reject proposed memories",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setReviewStatus(args, models.MemoryStatusRejected, "🗑️  Rejected")
+	},
+}
+
+var mineCmd = &cobra.Command{
+	Use:   "mine",
+	Short: "Mine terminal history for recurring workflows",
+	Long: `Find command sequences you run repeatedly and propose them as pattern
+memories in the review queue. The daemon does this daily.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		days, _ := cmd.Flags().GetInt("days")
+		noLLM, _ := cmd.Flags().GetBool("no-llm")
+
+		var completer extractor.Completer
+		if !noLLM {
+			model, _ := cmd.Flags().GetString("model")
+			completer = extractor.NewOllamaExtractor("", model)
+		}
+
+		proposed, err := mining.Run(s, completer, time.Now().AddDate(0, 0, -days))
+		if err != nil {
+			return fmt.Errorf("mining failed: %w", err)
+		}
+
+		if len(proposed) == 0 {
+			fmt.Println("🔍 No new recurring workflows found")
+			return nil
+		}
+
+		fmt.Printf("⛏️  Proposed %d workflows for review:\n\n", len(proposed))
+		for _, m := range proposed {
+			fmt.Printf("🔄 %s\n   🆔 %s\n", m.Summary, m.ID)
+		}
+		fmt.Println("\n   Run 'memorypilot review' to approve or reject them")
+		return nil
+	},
+}
+
+// indent continues multi-line text under a list item
+func indent(text, prefix string) string {
+	return strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
+
+func setReviewStatus(ids []string, status models.MemoryStatus, label string) error {
+	s, err := openStore()
+	if err != nil || s == nil {
+		return err
+	}
+	defer s.Close()
+
+	for _, id := range ids {
+		if err := s.SetStatus(id, status); err != nil {
+			return err
+		}
+		fmt.Printf("%s %s\n", label, id)
+	}
+	return nil
+}
+
+func init() {
+	reviewCmd.Flags().Bool("list", false, "List the memories awaiting review without prompting")
+	reviewCmd.AddCommand(reviewApproveCmd)
+	reviewCmd.AddCommand(reviewRejectCmd)
+
+	mineCmd.Flags().Int("days", 30, "How many days of history to mine")
+	mineCmd.Flags().Bool("no-llm", false, "Describe workflows without the LLM")
+	mineCmd.Flags().String("model", "llama3.2", "Ollama model used to describe workflows")
+}
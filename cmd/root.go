@@ -3,13 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/privacy"
+	"github.com/memorypilot/memorypilot/internal/retention"
+	"github.com/memorypilot/memorypilot/internal/store"
 	"github.com/spf13/cobra"
 )
 
 var (
 	version = "0.1.0"
 	cfgFile string
+	dataDir string
 )
 
 var rootCmd = &cobra.Command{
@@ -22,6 +29,9 @@ terminal commands) and makes it available to any AI tool through MCP or REST API
 
 Your AI tools will finally remember you.`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return enforcePrivacy()
+	},
 }
 
 func Execute() error {
@@ -30,7 +40,8 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ~/.memorypilot/config.yaml)")
-	
+	rootCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "", "data directory (default ~/.memorypilot/data)")
+
 	// Add subcommands
 	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(statusCmd)
@@ -38,10 +49,50 @@ func init() {
 	rootCmd.AddCommand(rememberCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(mcpCmd)
+	rootCmd.AddCommand(teamCmd)
+	rootCmd.AddCommand(reposCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	rootCmd.AddCommand(standupCmd)
+	rootCmd.AddCommand(askCmd)
+	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(primeCmd)
+	rootCmd.AddCommand(adaptersCmd)
+	rootCmd.AddCommand(lspCmd)
+	rootCmd.AddCommand(guardCmd)
+	rootCmd.AddCommand(ciContextCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(mineCmd)
+	rootCmd.AddCommand(reprocessCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(similarCmd)
+	rootCmd.AddCommand(clustersCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(shellHookCmd)
+	rootCmd.AddCommand(remoteAgentCmd)
+	rootCmd.AddCommand(devcontainerCmd)
+	rootCmd.AddCommand(demoCmd)
+	rootCmd.AddCommand(webhookCmd)
+	rootCmd.AddCommand(publishCmd)
+	rootCmd.AddCommand(forgetCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(showCmd)
+	rootCmd.AddCommand(whyCmd)
+	rootCmd.AddCommand(insightsCmd)
+	rootCmd.AddCommand(policyCmd)
+	rootCmd.AddCommand(holdCmd)
+	rootCmd.AddCommand(embeddingsCmd)
+	rootCmd.AddCommand(ingestCmd)
+	rootCmd.AddCommand(eventsCmd)
 }
 
-// getConfigDir returns the MemoryPilot config directory
+// getConfigDir returns the MemoryPilot config directory: MEMORYPILOT_HOME
+// if set, otherwise ~/.memorypilot
 func getConfigDir() string {
+	if dir := os.Getenv("MEMORYPILOT_HOME"); dir != "" {
+		return config.ExpandHome(dir)
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
@@ -50,7 +101,119 @@ func getConfigDir() string {
 	return home + "/.memorypilot"
 }
 
-// getDataDir returns the MemoryPilot data directory
+// getConfigPath returns the config file path, honoring --config
+func getConfigPath() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	return getConfigDir() + "/config.yaml"
+}
+
+// loadTuning reads the extraction tuning from the config file
+func loadTuning() (config.Tuning, error) {
+	f, err := config.Load(getConfigPath())
+	if err != nil {
+		return config.Tuning{}, err
+	}
+	return f.Tuning()
+}
+
+// loadSettings reads the daemon settings from the config file, with
+// environment overrides
+func loadSettings() (config.Settings, error) {
+	f, err := config.Load(getConfigPath())
+	if err != nil {
+		return config.Settings{}, err
+	}
+	return f.Settings()
+}
+
+// loadRetentionPolicies reads the retention policies from the config file
+func loadRetentionPolicies() ([]retention.Policy, error) {
+	f, err := config.Load(getConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	return retention.Load(f)
+}
+
+// enforcePrivacy turns on local-only mode when the config asks for it, so
+// every provider constructed afterwards is checked
+func enforcePrivacy() error {
+	f, err := config.Load(getConfigPath())
+	if err != nil {
+		return err
+	}
+	localOnly, err := f.LocalOnly()
+	if err != nil {
+		return err
+	}
+	privacy.Enforce(localOnly)
+	return nil
+}
+
+// configuredEmbedder returns the embedding providers and model in the
+// config file, so queries are embedded the way the daemon embeds memories;
+// with an invalid config, those in the environment
+func configuredEmbedder() embedding.Embedder {
+	settings, err := loadSettings()
+	if err == nil {
+		if c, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(settings.EmbeddingModel)); err == nil {
+			return c
+		}
+	}
+	return embedding.FromEnv(getLocalModelDir())
+}
+
+// embeddingChainConfig configures the embedding providers to use model
+// with Ollama and the local model in getLocalModelDir
+func embeddingChainConfig(model string) embedding.ChainConfig {
+	return embedding.ChainConfig{OllamaModel: model, LocalModelDir: getLocalModelDir()}
+}
+
+// getLocalModelDir returns where the local embedding model is kept:
+// MEMORYPILOT_LOCAL_MODEL if set, else models/all-MiniLM-L6-v2 in the
+// data directory
+func getLocalModelDir() string {
+	if dir := os.Getenv("MEMORYPILOT_LOCAL_MODEL"); dir != "" {
+		return config.ExpandHome(dir)
+	}
+	return embedding.LocalModelDir(getDataDir())
+}
+
+// getDataDir returns the MemoryPilot data directory: --data-dir, else
+// $MEMORYPILOT_HOME/data, else $XDG_DATA_HOME/memorypilot unless an
+// existing ~/.memorypilot/data is in use, else ~/.memorypilot/data
 func getDataDir() string {
-	return getConfigDir() + "/data"
+	if dataDir != "" {
+		return config.ExpandHome(dataDir)
+	}
+	legacy := getConfigDir() + "/data"
+	if os.Getenv("MEMORYPILOT_HOME") != "" {
+		return legacy
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		if _, err := os.Stat(legacy); os.IsNotExist(err) {
+			return filepath.Join(xdg, "memorypilot")
+		}
+	}
+	return legacy
+}
+
+// openStore opens the default memory database, printing a hint and
+// returning nil if MemoryPilot hasn't been initialized
+func openStore() (*store.Store, error) {
+	dbPath := getDataDir() + "/memories.db"
+
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		fmt.Println("❌ MemoryPilot not initialized")
+		fmt.Println("   Run 'memorypilot init' to get started")
+		return nil, nil
+	}
+
+	s, err := store.New(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+	return s, nil
 }
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/api"
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the memory store over HTTP",
+	Long: `Serve the REST API without the rest of the daemon. 'memorypilot daemon
+start' serves the same API unless api.enabled is false in config.yaml.
+
+Routes (JSON):
+  GET    /health
+  GET    /v1/memories?scope=&type=&project=&since=&limit=
+  POST   /v1/memories
+  GET    /v1/memories/{id}
+  PATCH  /v1/memories/{id}
+  DELETE /v1/memories/{id}
+  POST   /v1/recall
+  POST   /v1/recall/batch   {"queries": [{"query": ...}, ...], "budget": 4000}
+  GET    /v1/stats
+  GET    /v1/projects
+  GET    /v1/projects/{id-or-name}
+  GET    /v1/events?since=&until=&type=&project=&limit=
+  POST   /v1/events
+
+PATCH needs the version the edit is based on, as If-Match with the ETag
+GET returned or a "version" field, and answers 409 Conflict if the memory
+has changed since, so concurrent clients can't overwrite each other.
+
+The address defaults to api.host and api.port from config.yaml. With
+--token (or api.token, or MEMORYPILOT_API_TOKEN) every /v1 route requires
+"Authorization: Bearer <token>". The API listens on localhost by default;
+set a token before binding it to another interface.
+
+Examples:
+  memorypilot serve
+  memorypilot serve --addr 0.0.0.0:7832 --token "$(openssl rand -hex 32)"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings, err := loadSettings()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		addr := fmt.Sprintf("%s:%d", settings.APIHost, settings.APIPort)
+		if cmd.Flags().Changed("addr") {
+			addr, _ = cmd.Flags().GetString("addr")
+		}
+		token := settings.APIToken
+		if cmd.Flags().Changed("token") {
+			token, _ = cmd.Flags().GetString("token")
+		}
+
+		embedder, err := embedding.NewChain(settings.EmbeddingProviders, embeddingChainConfig(settings.EmbeddingModel))
+		if err != nil {
+			return fmt.Errorf("invalid embedding providers: %w", err)
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+		s.SetSearchWeights(settings.Search)
+		s.SetEmbeddingModel(embedder.Model())
+
+		srv := api.New(s, api.Options{
+			Addr:     addr,
+			Token:    token,
+			Embedder: embedding.Cached(embedder, s),
+		})
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.ListenAndServe() }()
+
+		fmt.Printf("🌐 Serving MemoryPilot API on http://%s\n", srv.Addr())
+		if token == "" {
+			fmt.Println("   ⚠️  No token set: anyone who can reach this address can read and edit memories")
+		}
+		fmt.Println("   Press Ctrl+C to stop")
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case err := <-errc:
+			return err
+		case <-sigChan:
+		}
+
+		fmt.Println("\n🛑 Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("addr", api.DefaultAddr, "Address to listen on (default from api.host and api.port)")
+	serveCmd.Flags().String("token", "", "Bearer token required on /v1 routes (default from api.token or MEMORYPILOT_API_TOKEN)")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/internal/watcher"
+	"github.com/spf13/cobra"
+)
+
+var shellHookCmd = &cobra.Command{
+	Use:   "shell-hook [zsh|bash]",
+	Short: "Print a shell hook that records where commands run",
+	Long: `Print a snippet for your shell's rc file that logs the working directory
+of each command. The daemon matches these with your shell history so
+terminal commands are attributed to the project they ran in.
+
+Add to ~/.zshrc:
+  eval "$(memorypilot shell-hook zsh)"
+
+Add to ~/.bashrc:
+  eval "$(memorypilot shell-hook bash)"
+
+bash logs after each command finishes, so a command that changes
+directory (cd foo && make) is attributed to where it ended up.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"zsh", "bash"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logPath := textutil.ShellQuote(filepath.Join(getDataDir(), watcher.CwdLogName))
+		switch args[0] {
+		case "zsh":
+			fmt.Printf(zshHook, logPath)
+		case "bash":
+			fmt.Printf(bashHook, logPath)
+		default:
+			return fmt.Errorf("unsupported shell %q (zsh|bash)", args[0])
+		}
+		return nil
+	},
+}
+
+// zshHook logs each command with its directory before it runs
+const zshHook = `# MemoryPilot: record where commands run
+zmodload zsh/datetime 2>/dev/null
+__memorypilot_preexec() {
+  local entry=${1//\\/\\\\}
+  print -r -- "${EPOCHSECONDS}"$'\t'"${PWD}"$'\t'"${entry//$'\n'/\\n}" >> %s 2>/dev/null
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __memorypilot_preexec
+`
+
+// bashHook logs the last history entry with the directory after each
+// command, skipping prompts where no new command ran
+const bashHook = `# MemoryPilot: record where commands run
+__memorypilot_log() {
+  local entry now
+  entry=$(HISTTIMEFORMAT= builtin history 1)
+  [[ "$entry" == "$__memorypilot_last" ]] && return
+  __memorypilot_last=$entry
+  entry=$(printf '%%s' "$entry" | sed -E '1s/^ *[0-9]+\*? *//')
+  entry=${entry//\\/\\\\}
+  printf -v now '%%(%%s)T' -1
+  printf '%%s\t%%s\t%%s\n' "$now" "$PWD" "${entry//$'\n'/\\n}" >> %s 2>/dev/null
+}
+__memorypilot_last=$(HISTTIMEFORMAT= builtin history 1)
+PROMPT_COMMAND="__memorypilot_log${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`
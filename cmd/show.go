@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <memory-id>",
+	Short: "Show a memory in full",
+	Long: `Show everything stored about a memory: its content, scope, where it came
+from, who wrote it and who maintains it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		m, err := s.GetMemory(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to look up memory: %w", err)
+		}
+		if m == nil {
+			return fmt.Errorf("memory %s not found", args[0])
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, _ := json.MarshalIndent(m, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("%s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		fmt.Printf("   %s\n\n", m.Content)
+		fmt.Printf("   🆔 %s\n", m.ID)
+		fmt.Printf("   🔭 %s scope", m.Scope)
+		if m.ProjectID != nil {
+			fmt.Printf(" | 📁 project %s", *m.ProjectID)
+		}
+		fmt.Println()
+		fmt.Printf("   ✍️  Author: %s\n", orUnknown(m.Author))
+		fmt.Printf("   🛠️  Maintainer: %s\n", orUnknown(m.Maintainer))
+		if m.Signer != "" {
+			fmt.Printf("   🔏 Signed by %s\n", m.Signer)
+		}
+		fmt.Printf("   📥 From %s (%s)", m.Source.Type, m.Source.Reference)
+		if m.Provider != "" {
+			fmt.Printf(" via %s", m.Provider)
+		}
+		fmt.Println()
+		fmt.Printf("   📅 %s | 🎯 %.0f%% confidence | ⭐ %.0f%% importance | 👀 %d recalls\n",
+			m.CreatedAt.Format("2006-01-02 15:04"), m.Confidence*100, m.Importance*100, m.AccessCount)
+		if m.ExpiresAt != nil {
+			fmt.Printf("   ⏳ Expires %s\n", m.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+		if len(m.Topics) > 0 {
+			fmt.Printf("   🏷️  %s\n", strings.Join(m.Topics, ", "))
+		}
+		if m.Status != "" && m.Status != "approved" {
+			fmt.Printf("   📋 %s\n", m.Status)
+		}
+		if held, err := s.IsHeld(m.ID); err == nil && held {
+			fmt.Println("   ⚖️  Under legal hold: can't be changed or deleted")
+		}
+		if flagged, err := s.IsFlaggedForReprocess(m.ID); err == nil && flagged {
+			fmt.Printf("   🔄 Extracted with prompt version %d: flagged for 'memorypilot reprocess --flagged'\n", m.PromptVersion)
+		}
+		return nil
+	},
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func init() {
+	showCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var similarCmd = &cobra.Command{
+	Use:   "similar [memory-id]",
+	Short: "Find the memories nearest to a memory",
+	Long: `List the memories whose embeddings are closest to an existing memory,
+with their similarity scores. Useful for spotting near-duplicates and
+exploring related memories.
+
+Examples:
+  memorypilot similar 01HX...
+  memorypilot similar --min 0.85 01HX...`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		limit, _ := cmd.Flags().GetInt("limit")
+		minSimilarity, _ := cmd.Flags().GetFloat32("min")
+
+		m, err := s.GetMemory(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get memory: %w", err)
+		}
+		if m == nil {
+			return fmt.Errorf("memory %s not found", args[0])
+		}
+
+		similar, err := s.SimilarTo(m.ID, minSimilarity, limit)
+		if errors.Is(err, store.ErrNoEmbedding) {
+			return fmt.Errorf("memory %s has no embedding yet (the daemon computes them with Ollama)", m.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("similarity search failed: %w", err)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			if similar == nil {
+				similar = []store.ScoredMemory{}
+			}
+			data, _ := json.MarshalIndent(similar, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("%s [%s] %s\n\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		if len(similar) == 0 {
+			fmt.Println("🔍 No similar memories found")
+			return nil
+		}
+
+		fmt.Printf("🧭 %d similar memories:\n\n", len(similar))
+		for i, sm := range similar {
+			fmt.Printf("%3.0f%% %s [%s] %s\n", sm.Similarity*100, getTypeEmoji(sm.Type), sm.Type, sm.Summary)
+			fmt.Printf("     🆔 %s | 📅 %s\n", sm.ID, sm.CreatedAt.Format("2006-01-02"))
+			if i < len(similar)-1 {
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	similarCmd.Flags().IntP("limit", "l", 10, "Maximum number of results")
+	similarCmd.Flags().Float32("min", 0.5, "Minimum similarity (0-1)")
+	similarCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record your current working state for tomorrow's AI session",
+	Long: `Record the current working state of a repository as a short-lived
+context memory: branch, uncommitted files, TODOs touched today, failing
+tests and an optional note. The memory expires after --ttl.
+
+Examples:
+  memorypilot snapshot
+  memorypilot snapshot --note "halfway through the retry refactor"
+  memorypilot snapshot --failing TestRetryBackoff --ttl 72h`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		dir, _ := cmd.Flags().GetString("path")
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+
+		snap, err := snapshot.Capture(dir)
+		if err != nil {
+			return err
+		}
+		snap.Note, _ = cmd.Flags().GetString("note")
+		snap.FailingTests, _ = cmd.Flags().GetStringSlice("failing")
+
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		memory := snap.Memory(ttl)
+		if project, err := s.GetProjectByPath(snap.Repo); err == nil && project != nil {
+			memory.ProjectID = &project.ID
+		}
+
+		identity.Attribute(&memory)
+		if err := s.CreateMemory(&memory); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+
+		fmt.Printf("📸 Snapshot saved: %s\n", memory.ID)
+		fmt.Printf("   %s\n", memory.Summary)
+		fmt.Printf("   Expires: %s\n", memory.ExpiresAt.Format("2006-01-02 15:04"))
+		return nil
+	},
+}
+
+func init() {
+	snapshotCmd.Flags().String("path", "", "Repository to snapshot (default: current directory)")
+	snapshotCmd.Flags().StringP("note", "n", "", "Free-form note about where you left off")
+	snapshotCmd.Flags().StringSlice("failing", []string{}, "Tests that are currently failing")
+	snapshotCmd.Flags().Duration("ttl", snapshot.DefaultTTL, "How long the snapshot is kept")
+}
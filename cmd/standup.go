@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/standup"
+	"github.com/spf13/cobra"
+)
+
+var standupCmd = &cobra.Command{
+	Use:   "standup",
+	Short: "Summarize yesterday's work into a standup report",
+	Long: `Summarize the previous workday's captured activity and new memories,
+per project, into a "what I did / what I learned / what's blocked" report.
+
+Examples:
+  memorypilot standup
+  memorypilot standup --format slack
+  memorypilot standup --since 72h --no-llm`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		since, until := standup.PreviousWorkday(time.Now())
+		if d, _ := cmd.Flags().GetDuration("since"); d > 0 {
+			since, until = time.Now().Add(-d), time.Now()
+		}
+
+		activity, err := standup.Collect(s, since, until)
+		if err != nil {
+			return err
+		}
+
+		var report *standup.Report
+		noLLM, _ := cmd.Flags().GetBool("no-llm")
+		if !noLLM && !activity.Empty() {
+			model, _ := cmd.Flags().GetString("model")
+			report, err = standup.Summarize(extractor.NewOllamaExtractor("", model), activity)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: LLM summary unavailable (%v), listing raw activity\n", err)
+			}
+		}
+		if report == nil {
+			report = standup.Fallback(activity)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		switch format {
+		case "slack":
+			fmt.Print(report.Slack())
+		case "json":
+			data, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(data))
+		case "markdown", "md":
+			fmt.Print(report.Markdown())
+		default:
+			return fmt.Errorf("unknown format %q (markdown|slack|json)", format)
+		}
+		return nil
+	},
+}
+
+func init() {
+	standupCmd.Flags().StringP("format", "f", "markdown", "Output format (markdown|slack|json)")
+	standupCmd.Flags().Duration("since", 0, "Cover this much time instead of the previous workday (e.g. 72h)")
+	standupCmd.Flags().Bool("no-llm", false, "Skip the LLM and list raw activity")
+	standupCmd.Flags().String("model", "llama3.2", "Ollama model used to write the summary")
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/analysis"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how extracted memories are distributed across types",
+	Long: `Show the type distribution of automatically extracted memories. With
+--analyze, flag skew (e.g. almost only facts, no mistakes at all) and
+suggest how to tune extraction. With --heatmap, show per-project events
+and memories by day, revealing which projects MemoryPilot is learning from.
+
+Examples:
+  memorypilot stats
+  memorypilot stats --analyze
+  memorypilot stats --heatmap --days 90`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		stats, err := s.ExtractedTypeStats()
+		if err != nil {
+			return fmt.Errorf("failed to get stats: %w", err)
+		}
+		tuning, err := loadTuning()
+		if err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+		report := analysis.AnalyzeTypes(stats, tuning.MinConfidence)
+
+		analyze, _ := cmd.Flags().GetBool("analyze")
+		if !analyze {
+			report.Findings = nil
+		}
+
+		var heatmap *analysis.Heatmap
+		if showHeatmap, _ := cmd.Flags().GetBool("heatmap"); showHeatmap {
+			days, _ := cmd.Flags().GetInt("days")
+			now := time.Now().UTC()
+			since := now.Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+			activity, err := s.DailyActivity(since)
+			if err != nil {
+				return fmt.Errorf("failed to get activity: %w", err)
+			}
+			heatmap = analysis.BuildHeatmap(activity, days, now)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, _ := json.MarshalIndent(struct {
+				*analysis.TypeReport
+				Activity *analysis.Heatmap `json:"activity,omitempty"`
+			}{report, heatmap}, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("📊 Extracted memories by type (%d total)\n", report.Total)
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		for _, t := range report.Types {
+			bar := strings.Repeat("█", int(t.Share*20+0.5))
+			fmt.Printf("   %-11s %5d  %3.0f%%  %-20s  conf %.2f", t.Type, t.Total, t.Share*100, bar, t.AvgConfidence)
+			if t.Rejected > 0 {
+				fmt.Printf("  (%d rejected)", t.Rejected)
+			}
+			fmt.Println()
+		}
+
+		if heatmap != nil {
+			printHeatmap(heatmap)
+		}
+
+		if !analyze {
+			return nil
+		}
+
+		fmt.Println()
+		if len(report.Findings) == 0 {
+			fmt.Println("✅ No anomalies: extraction looks balanced")
+			return nil
+		}
+		fmt.Println("🔎 Analysis")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+		for _, f := range report.Findings {
+			icon := "💡"
+			if f.Severity == analysis.SeverityWarning {
+				icon = "⚠️ "
+			}
+			fmt.Printf("%s %s\n   → %s\n", icon, f.Message, f.Suggestion)
+		}
+		return nil
+	},
+}
+
+// heatCells shades heatmap levels from nothing to busiest
+var heatCells = []string{"·", "░", "▒", "▓", "█"}
+
+// printHeatmap draws one contribution-graph row per project
+func printHeatmap(h *analysis.Heatmap) {
+	fmt.Printf("\n🔥 Activity by project (%s to %s)\n", h.From, h.To)
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	if len(h.Projects) == 0 {
+		fmt.Println("   No events or memories in this period")
+		return
+	}
+	for _, p := range h.Projects {
+		var row strings.Builder
+		for _, d := range p.Days {
+			row.WriteString(heatCells[d.Level])
+		}
+		name := p.Name
+		if len(name) > 16 {
+			name = name[:15] + "…"
+		}
+		fmt.Printf("   %-16s %s  %d events, %d memories", name, row.String(), p.Events, p.Memories)
+		if p.Sampled > 0 {
+			fmt.Printf(" (%d noisy events sampled out)", p.Sampled)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("   %-16s less %s more\n", "", strings.Join(heatCells, ""))
+}
+
+func init() {
+	statsCmd.Flags().Bool("analyze", false, "Flag type distribution anomalies and suggest tuning")
+	statsCmd.Flags().Bool("heatmap", false, "Show events and memories per project by day")
+	statsCmd.Flags().Int("days", 60, "Days covered by --heatmap")
+	statsCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/daemon"
+	"github.com/memorypilot/memorypilot/internal/privacy"
 	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/watcher"
 	"github.com/spf13/cobra"
 )
 
@@ -15,40 +19,57 @@ var statusCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dataDir := getDataDir()
 		dbPath := dataDir + "/memories.db"
-		
+
 		// Check if database exists
 		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
 			fmt.Println("❌ MemoryPilot not initialized")
 			fmt.Println("   Run 'memorypilot init' to get started")
 			return nil
 		}
-		
+
 		// Open store
 		s, err := store.New(dbPath)
 		if err != nil {
 			return fmt.Errorf("failed to open store: %w", err)
 		}
 		defer s.Close()
-		
+
 		// Get stats
 		stats, err := s.GetStats()
 		if err != nil {
 			return fmt.Errorf("failed to get stats: %w", err)
 		}
-		
+
+		_, stats.DaemonRunning = daemon.Running(getPIDPath())
+
+		scans, err := scanProgress(s)
+		if err != nil {
+			return fmt.Errorf("failed to get scan progress: %w", err)
+		}
+
 		// Check if JSON output requested
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 		if jsonOutput {
-			data, _ := json.MarshalIndent(stats, "", "  ")
+			data, _ := json.MarshalIndent(struct {
+				*store.Stats
+				LocalOnly bool                   `json:"localOnly"`
+				Scans     []watcher.ScanProgress `json:"scans,omitempty"`
+			}{stats, privacy.Enabled(), scans}, "", "  ")
 			fmt.Println(string(data))
 			return nil
 		}
-		
+
 		// Pretty print
 		fmt.Println("🧠 MemoryPilot Status")
 		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
 		fmt.Printf("   Version:    %s\n", version)
 		fmt.Printf("   Status:     %s\n", getStatusEmoji(stats.DaemonRunning))
+		if privacy.Enabled() {
+			fmt.Println("   Privacy:    🔒 Local-only (nothing leaves this machine)")
+		}
+		if env := config.Container(); env != "" {
+			fmt.Printf("   Container:  %s (see 'memorypilot devcontainer')\n", env)
+		}
 		fmt.Println()
 		fmt.Println("📊 Memory Statistics")
 		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
@@ -59,15 +80,79 @@ var statusCmd = &cobra.Command{
 		fmt.Printf("   Preferences:%d\n", stats.ByType["preference"])
 		fmt.Printf("   Mistakes:   %d\n", stats.ByType["mistake"])
 		fmt.Printf("   Learnings:  %d\n", stats.ByType["learning"])
+		if stats.DeferredEvents > 0 {
+			fmt.Printf("   Deferred:   %d events awaiting extraction\n", stats.DeferredEvents)
+		}
+		if stats.RetryEvents > 0 {
+			fmt.Printf("   Retrying:   %d events whose extraction failed\n", stats.RetryEvents)
+		}
+		if stats.FailedEvents > 0 {
+			fmt.Printf("   Failed:     %d events (run 'memorypilot events retry')\n", stats.FailedEvents)
+		}
+		if stats.MergedCount > 0 {
+			fmt.Printf("   Merged:     %d duplicates folded into existing memories\n", stats.MergedCount)
+		}
+		if stats.ArchivedCount > 0 {
+			fmt.Printf("   Archived:   %d expired memories\n", stats.ArchivedCount)
+		}
+		if stats.ReprocessCount > 0 {
+			fmt.Printf("   Stale:      %d from older prompts (run 'memorypilot reprocess --flagged')\n", stats.ReprocessCount)
+		}
+		if stats.RepeatedEvents > 0 {
+			fmt.Printf("   Repeats:    %d identical events folded at capture\n", stats.RepeatedEvents)
+		}
+		if stats.PendingCount > 0 {
+			fmt.Printf("   Pending:    %d (run 'memorypilot review')\n", stats.PendingCount)
+		}
+		if len(stats.CaptureAlerts) > 0 {
+			fmt.Println()
+			fmt.Println("⚠️  Capture Alerts")
+			fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+			for _, alert := range stats.CaptureAlerts {
+				fmt.Printf("   %s watcher silent for %s", alert.Watcher, alert.QuietFor)
+				if !alert.LastEvent.IsZero() {
+					fmt.Printf(" (last event %s)", alert.LastEvent.Local().Format("2006-01-02 15:04"))
+				}
+				fmt.Printf("\n   → %s\n", alert.Hint)
+			}
+		}
 		fmt.Println()
 		fmt.Println("📁 Projects")
 		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
 		fmt.Printf("   Tracked:    %d\n", stats.ProjectCount)
-		
+		for _, p := range scans {
+			label := "File tree:"
+			found := ""
+			if p.Name == watcher.ScanRepos {
+				label = "Discovery:"
+				found = fmt.Sprintf(", %d repos", p.Found)
+			}
+			if p.Finished.IsZero() {
+				fmt.Printf("   %-11s scanning, %d directories read, %d queued%s\n", label, p.Dirs, p.Queued, found)
+			} else {
+				fmt.Printf("   %-11s %d directories%s (finished %s)\n", label, p.Dirs, found, p.Finished.Local().Format("2006-01-02 15:04"))
+			}
+		}
+
 		return nil
 	},
 }
 
+// scanProgress summarizes the daemon's saved repo and file tree scans
+func scanProgress(s *store.Store) ([]watcher.ScanProgress, error) {
+	var progress []watcher.ScanProgress
+	for _, name := range []string{watcher.ScanRepos, watcher.ScanFileTree} {
+		data, err := s.LoadScan(name)
+		if err != nil {
+			return nil, err
+		}
+		if scan := watcher.ParseScan(data); scan != nil {
+			progress = append(progress, scan.Progress(name))
+		}
+	}
+	return progress, nil
+}
+
 func getStatusEmoji(running bool) string {
 	if running {
 		return "🟢 Running"
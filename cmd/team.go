@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/spf13/cobra"
+)
+
+var teamCmd = &cobra.Command{
+	Use:   "team",
+	Short: "Manage the local cache of team memories",
+	Long: `Manage the local read-only cache of team and org memories.
+
+The cache lets recall work offline. The daemon refreshes it in the
+background when MEMORYPILOT_SYNC_ENDPOINT is set. Pins and feedback are
+stored locally and survive refreshes.
+
+If ~/.memorypilot/allowed_signers exists (one "ssh-ed25519 AAAA..." key per
+line), only team memories signed by one of those keys are accepted.`,
+}
+
+var teamRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the team cache from the sync endpoint",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpoint := os.Getenv("MEMORYPILOT_SYNC_ENDPOINT")
+		if endpoint == "" {
+			return fmt.Errorf("MEMORYPILOT_SYNC_ENDPOINT is not set")
+		}
+
+		client, err := teamsync.NewClient(endpoint, os.Getenv("MEMORYPILOT_SYNC_TOKEN"))
+		if err != nil {
+			return err
+		}
+		cache, err := teamsync.OpenCache(getTeamCachePath(), client)
+		if err != nil {
+			return err
+		}
+		defer cache.Close()
+
+		if path := getAllowedSignersPath(); path != "" {
+			trusted, err := teamsync.LoadAllowedSigners(path)
+			if err != nil {
+				return fmt.Errorf("failed to load allowed signers: %w", err)
+			}
+			cache.RequireSignatures(trusted)
+		}
+
+		n, err := cache.Refresh()
+		if err != nil {
+			return fmt.Errorf("refresh failed: %w", err)
+		}
+
+		fmt.Printf("✅ Team cache refreshed: %d memories updated\n", n)
+		return nil
+	},
+}
+
+var teamStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show team cache freshness",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := openTeamCache()
+		if err != nil || cache == nil {
+			return err
+		}
+		defer cache.Close()
+
+		stats, err := cache.Store().GetStats()
+		if err != nil {
+			return fmt.Errorf("failed to get stats: %w", err)
+		}
+
+		fmt.Println("👥 Team Cache")
+		fmt.Println("━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Printf("   Memories:   %d\n", stats.TotalMemories)
+		fmt.Printf("   Refreshed:  %s\n", describeTeamCacheAge(cache))
+		return nil
+	},
+}
+
+var teamPinCmd = &cobra.Command{
+	Use:   "pin [memory-id]",
+	Short: "Pin a cached team memory locally",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTeamPin(args[0], true)
+	},
+}
+
+var teamUnpinCmd = &cobra.Command{
+	Use:   "unpin [memory-id]",
+	Short: "Remove a local pin from a cached team memory",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTeamPin(args[0], false)
+	},
+}
+
+var teamFeedbackCmd = &cobra.Command{
+	Use:   "feedback [memory-id] [text]",
+	Short: "Attach local feedback to a cached team memory",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cache, err := openTeamCache()
+		if err != nil || cache == nil {
+			return err
+		}
+		defer cache.Close()
+
+		if err := cache.Store().SetFeedback(args[0], strings.Join(args[1:], " ")); err != nil {
+			return fmt.Errorf("failed to save feedback: %w", err)
+		}
+
+		fmt.Printf("✅ Feedback saved for %s\n", args[0])
+		return nil
+	},
+}
+
+// getTeamCachePath returns the path of the team cache database
+func getTeamCachePath() string {
+	return getDataDir() + "/" + teamsync.CacheFile
+}
+
+// getAllowedSignersPath returns the trusted signers file, or "" if signature
+// verification isn't configured
+func getAllowedSignersPath() string {
+	path := os.Getenv("MEMORYPILOT_ALLOWED_SIGNERS")
+	if path == "" {
+		path = getConfigDir() + "/allowed_signers"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// openTeamCache opens the team cache for reading, printing a hint and
+// returning nil if it hasn't been created yet
+func openTeamCache() (*teamsync.Cache, error) {
+	if _, err := os.Stat(getTeamCachePath()); os.IsNotExist(err) {
+		fmt.Println("❌ No team cache yet")
+		fmt.Println("   Set MEMORYPILOT_SYNC_ENDPOINT and run 'memorypilot team refresh'")
+		return nil, nil
+	}
+	return teamsync.OpenCache(getTeamCachePath(), nil)
+}
+
+// describeTeamCacheAge renders the last refresh time with a staleness marker
+func describeTeamCacheAge(cache *teamsync.Cache) string {
+	last, ok, err := cache.LastRefresh()
+	if err != nil || !ok {
+		return "never"
+	}
+	age := time.Since(last).Round(time.Minute)
+	if cache.IsStale() {
+		return fmt.Sprintf("%s ago ⚠️ stale", age)
+	}
+	return fmt.Sprintf("%s ago", age)
+}
+
+func setTeamPin(memoryID string, pinned bool) error {
+	cache, err := openTeamCache()
+	if err != nil || cache == nil {
+		return err
+	}
+	defer cache.Close()
+
+	m, err := cache.Store().GetMemory(memoryID)
+	if err != nil {
+		return fmt.Errorf("failed to look up memory: %w", err)
+	}
+	if m == nil {
+		return fmt.Errorf("memory %s is not in the team cache", memoryID)
+	}
+
+	if err := cache.Store().SetPinned(memoryID, pinned); err != nil {
+		return fmt.Errorf("failed to update pin: %w", err)
+	}
+
+	if pinned {
+		fmt.Printf("📍 Pinned: %s\n", m.Summary)
+	} else {
+		fmt.Printf("✅ Unpinned: %s\n", m.Summary)
+	}
+	return nil
+}
+
+func init() {
+	teamCmd.AddCommand(teamRefreshCmd)
+	teamCmd.AddCommand(teamStatusCmd)
+	teamCmd.AddCommand(teamPinCmd)
+	teamCmd.AddCommand(teamUnpinCmd)
+	teamCmd.AddCommand(teamFeedbackCmd)
+}
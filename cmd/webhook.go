@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/webhook"
+	"github.com/oklog/ulid/v2"
+	"github.com/spf13/cobra"
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Notify automation platforms when memories change",
+	Long: `Send memory lifecycle events to URLs such as Zapier, n8n or Make hooks.
+
+Events:
+  memory.created    a memory was stored (check memory.status for "pending")
+  memory.updated    its type, content, summary, topics or project changed
+  memory.promoted   it was approved in review, or its scope widened
+  memory.deleted    it was deleted (the payload is its last state)
+
+Each event is POSTed as JSON and signed with the webhook's secret:
+
+  X-MemoryPilot-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+
+The daemon delivers events, retrying failures with exponential backoff for
+about an hour before giving up.
+
+Examples:
+  memorypilot webhook add https://hooks.zapier.com/hooks/catch/123/abc
+  memorypilot webhook add https://n8n.example.com/webhook/mp --event memory.promoted
+  memorypilot webhook test 01HX...`,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add [url]",
+	Short: "Register a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		u, err := url.Parse(args[0])
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q", args[0])
+		}
+		events, _ := cmd.Flags().GetStringSlice("event")
+		for _, e := range events {
+			if !isWebhookEvent(e) {
+				return fmt.Errorf("unknown event %q (choose from %s)", e, strings.Join(store.WebhookEvents, ", "))
+			}
+		}
+		secret, _ := cmd.Flags().GetString("secret")
+		if secret == "" {
+			secret = webhook.NewSecret()
+		}
+
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		w := &store.Webhook{
+			ID:        ulid.Make().String(),
+			URL:       u.String(),
+			Secret:    secret,
+			Events:    events,
+			CreatedAt: time.Now(),
+		}
+		if err := s.AddWebhook(w); err != nil {
+			return fmt.Errorf("failed to add webhook: %w", err)
+		}
+
+		fmt.Printf("✅ Webhook added: %s\n", w.ID)
+		fmt.Printf("   URL:    %s\n", w.URL)
+		fmt.Printf("   Events: %s\n", describeWebhookEvents(w.Events))
+		fmt.Printf("   Secret: %s\n", w.Secret)
+		fmt.Println("   Verify the X-MemoryPilot-Signature header with this secret")
+		return nil
+	},
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhooks and their delivery status",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		hooks, err := s.ListWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to list webhooks: %w", err)
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, _ := json.MarshalIndent(hooks, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(hooks) == 0 {
+			fmt.Println("🔔 No webhooks registered")
+			fmt.Println("   Add one with 'memorypilot webhook add <url>'")
+			return nil
+		}
+
+		for i, w := range hooks {
+			fmt.Printf("🔔 %s\n", w.URL)
+			fmt.Printf("   🆔 %s | 📡 %s\n", w.ID, describeWebhookEvents(w.Events))
+			fmt.Printf("   ✓ %d delivered | ⏳ %d pending | ✗ %d failed\n", w.Delivered, w.Pending, w.Failed)
+			if lastErr, _ := s.LastWebhookError(w.ID); lastErr != "" && (w.Pending > 0 || w.Failed > 0) {
+				fmt.Printf("   ⚠️  Last error: %s\n", lastErr)
+			}
+			if i < len(hooks)-1 {
+				fmt.Println()
+			}
+		}
+		return nil
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove [webhook-id]",
+	Short: "Unregister a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		if err := s.RemoveWebhook(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️  Removed webhook %s\n", args[0])
+		return nil
+	},
+}
+
+var webhookTestCmd = &cobra.Command{
+	Use:   "test [webhook-id]",
+	Short: "Send a signed ping to a webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		w, err := s.GetWebhook(args[0])
+		if err != nil {
+			return err
+		}
+
+		id := "test-" + ulid.Make().String()
+		body, _ := json.Marshal(webhook.Payload{
+			ID:        id,
+			Event:     "ping",
+			CreatedAt: time.Now().UTC(),
+			Memory:    json.RawMessage("null"),
+		})
+		if err := webhook.NewDispatcher(s).Send(context.Background(), w.URL, w.Secret, "ping", id, body); err != nil {
+			return fmt.Errorf("ping failed: %w", err)
+		}
+		fmt.Printf("✅ %s accepted the ping\n", w.URL)
+		return nil
+	},
+}
+
+var webhookDeliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Deliver queued events now instead of waiting for the daemon",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		if retry, _ := cmd.Flags().GetBool("retry-failed"); retry {
+			hooks, err := s.ListWebhooks()
+			if err != nil {
+				return err
+			}
+			for _, w := range hooks {
+				if n, err := s.RetryFailedWebhookDeliveries(w.ID); err != nil {
+					return err
+				} else if n > 0 {
+					fmt.Printf("🔁 Requeued %d failed deliveries to %s\n", n, w.URL)
+				}
+			}
+		}
+
+		sent, failed, err := webhook.NewDispatcher(s).Flush(context.Background())
+		if err != nil {
+			return fmt.Errorf("delivery failed: %w", err)
+		}
+		fmt.Printf("📤 %d delivered, %d failed (failures are retried with backoff)\n", sent, failed)
+		return nil
+	},
+}
+
+func isWebhookEvent(e string) bool {
+	for _, known := range store.WebhookEvents {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+func describeWebhookEvents(events []string) string {
+	if len(events) == 0 {
+		return "all events"
+	}
+	return strings.Join(events, ", ")
+}
+
+func init() {
+	webhookCmd.AddCommand(webhookAddCmd)
+	webhookCmd.AddCommand(webhookListCmd)
+	webhookCmd.AddCommand(webhookRemoveCmd)
+	webhookCmd.AddCommand(webhookTestCmd)
+	webhookCmd.AddCommand(webhookDeliverCmd)
+
+	webhookAddCmd.Flags().StringSlice("event", nil, "Events to send (default all): "+strings.Join(store.WebhookEvents, ", "))
+	webhookAddCmd.Flags().String("secret", "", "Signing secret (default: generated)")
+	webhookListCmd.Flags().Bool("json", false, "Output as JSON")
+	webhookDeliverCmd.Flags().Bool("retry-failed", false, "Also retry deliveries that were given up on")
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+var whyCmd = &cobra.Command{
+	Use:   "why <memory-id>",
+	Short: "Trace a memory back to the events it came from",
+	Long: `Print a memory's provenance end to end: the events it was extracted
+from, the extraction that produced it (provider, model, prompt hash and
+confidence), every later merge, edit and review decision, and its state now.
+
+Events pruned by retention are reported as missing. Memories created before
+provenance was recorded only show where they came from.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := openStore()
+		if err != nil || s == nil {
+			return err
+		}
+		defer s.Close()
+
+		m, err := s.GetMemory(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to look up memory: %w", err)
+		}
+		if m == nil {
+			return fmt.Errorf("memory %s not found", args[0])
+		}
+		lineage, err := s.Lineage(m.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load lineage: %w", err)
+		}
+
+		// The extraction runs behind it, and every event any extraction or
+		// merge drew on. A run lists the events its prompt saw, which may be
+		// fewer than the batch's.
+		runs := make(map[string]*models.ExtractionRun)
+		var ids []string
+		for i, e := range lineage {
+			if e.RunID == "" {
+				ids = append(ids, e.EventIDs...)
+				continue
+			}
+			run, err := s.GetExtractionRun(e.RunID)
+			if err != nil {
+				return fmt.Errorf("failed to load extraction run: %w", err)
+			}
+			if run != nil {
+				runs[run.ID] = run
+				lineage[i].EventIDs = run.EventIDs
+			}
+			ids = append(ids, lineage[i].EventIDs...)
+		}
+		events, err := s.GetEvents(ids)
+		if err != nil {
+			return fmt.Errorf("failed to load events: %w", err)
+		}
+		byID := make(map[string]models.Event, len(events))
+		for _, e := range events {
+			byID[e.ID] = e
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, _ := json.MarshalIndent(map[string]interface{}{
+				"memory":  m,
+				"lineage": lineage,
+				"runs":    runs,
+				"events":  events,
+			}, "", "  ")
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Printf("%s [%s] %s\n", getTypeEmoji(m.Type), m.Type, m.Summary)
+		fmt.Printf("   %s\n\n", m.Content)
+
+		if len(lineage) == 0 || lineage[0].Action != store.LineageExtracted {
+			fmt.Printf("📥 Created %s from %s (%s)", m.CreatedAt.Format("2006-01-02 15:04"), m.Source.Type, m.Source.Reference)
+			if m.Provider != "" {
+				fmt.Printf(" via %s", m.Provider)
+			}
+			fmt.Println()
+		}
+		for _, e := range lineage {
+			printLineageEntry(e, runs[e.RunID], byID)
+		}
+
+		fmt.Printf("\n📍 Now: %.0f%% confidence | ⭐ %.0f%% importance | 👀 %d recalls",
+			m.Confidence*100, m.Importance*100, m.AccessCount)
+		if m.Status != "" && m.Status != models.MemoryStatusApproved {
+			fmt.Printf(" | 📋 %s", m.Status)
+		}
+		if m.ExpiresAt != nil {
+			fmt.Printf(" | ⏳ expires %s", m.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+// printLineageEntry prints one step of a memory's history, with the
+// extraction run and events it drew on
+func printLineageEntry(e store.LineageEntry, run *models.ExtractionRun, events map[string]models.Event) {
+	at := e.At.Local().Format("2006-01-02 15:04")
+	switch e.Action {
+	case store.LineageExtracted:
+		fmt.Printf("🤖 Extracted %s from %d events%s\n", at, len(e.EventIDs), describeExtraction(e))
+	case store.LineageMerged:
+		fmt.Printf("🔁 Merged a repeat %s from %d events%s\n", at, len(e.EventIDs), describeExtraction(e))
+	case store.LineageEdited:
+		fmt.Printf("✏️  Edited %s: %s\n", at, e.Note)
+		return
+	case store.LineageStatus:
+		fmt.Printf("📋 Marked %s %s\n", e.Note, at)
+		return
+	default:
+		fmt.Printf("•  %s %s %s\n", e.Action, at, e.Note)
+		return
+	}
+	if e.Note != "" {
+		fmt.Printf("   (%s)\n", e.Note)
+	}
+	if run != nil {
+		fmt.Printf("   Run %s: %s, response %s, %d accepted, %d below the confidence threshold\n",
+			run.ID, run.Latency.Round(time.Millisecond), run.ResponseHash, len(run.Accepted), len(run.Rejected))
+	}
+	for _, id := range e.EventIDs {
+		ev, ok := events[id]
+		if !ok {
+			fmt.Printf("   - %s (no longer stored)\n", id)
+			continue
+		}
+		fmt.Printf("   - %s %s %s\n", ev.Timestamp.Local().Format("2006-01-02 15:04"), ev.Type, describeEvent(ev))
+	}
+}
+
+// describeExtraction renders who extracted a memory and how sure it was
+func describeExtraction(e store.LineageEntry) string {
+	var parts []string
+	if e.Provider != "" {
+		by := e.Provider
+		if e.Model != "" {
+			by += " (" + e.Model + ")"
+		}
+		parts = append(parts, "by "+by)
+	}
+	if e.PromptHash != "" {
+		parts = append(parts, "prompt "+e.PromptHash)
+	}
+	if e.Confidence > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f%% confidence", e.Confidence*100))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(parts, ", ")
+}
+
+// describeEvent picks the most telling field of an event's data
+func describeEvent(e models.Event) string {
+	for _, key := range []string{"message", "tag", "command", "path", "section", "source"} {
+		if v, ok := e.Data[key].(string); ok && v != "" {
+			line, _, _ := strings.Cut(v, "\n")
+			if len(line) > 80 {
+				line = line[:77] + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+func init() {
+	whyCmd.Flags().Bool("json", false, "Output as JSON")
+}
@@ -4,6 +4,7 @@ go 1.25.6
 
 require (
 	github.com/fsnotify/fsnotify v1.9.0
+	github.com/klauspost/compress v1.18.0
 	github.com/mattn/go-sqlite3 v1.14.34
 	github.com/oklog/ulid/v2 v2.1.1
 	github.com/spf13/cobra v1.10.2
@@ -0,0 +1,176 @@
+package adapters
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/pack"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultBudget is the token budget for context written into tool files.
+// It is smaller than a hand-made pack since it is loaded into every session.
+const DefaultBudget = 2000
+
+// Adapter writes MemoryPilot context where a coding tool will pick it up
+type Adapter interface {
+	// Name is the tool's display name
+	Name() string
+	// Detect reports whether the tool is configured in the project
+	Detect(projectDir string) bool
+	// Write installs the context file, returning the paths it changed
+	Write(projectDir, content string) ([]string, error)
+}
+
+// All returns every supported adapter
+func All() []Adapter {
+	return []Adapter{aider{}, continueDev{}}
+}
+
+// Detected returns the adapters whose tool is configured in the project
+func Detected(projectDir string) []Adapter {
+	var found []Adapter
+	for _, a := range All() {
+		if a.Detect(projectDir) {
+			found = append(found, a)
+		}
+	}
+	return found
+}
+
+// Sync packs the project's memories and writes them through every detected
+// adapter. Files are only rewritten when their content changes.
+func Sync(s *store.Store, projectDir string, budget int) ([]string, error) {
+	detected := Detected(projectDir)
+	if len(detected) == 0 {
+		return nil, nil
+	}
+
+	req := models.RecallRequest{Limit: 500}
+	title := filepath.Base(projectDir)
+	project, err := s.GetProjectByPath(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if project != nil {
+		title = project.Name
+		req.ProjectID = &project.ID
+	}
+
+	memories, err := s.Recall(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(memories) == 0 {
+		return nil, nil
+	}
+	content := pack.Build(title, memories, budget).Markdown()
+
+	var written []string
+	for _, a := range detected {
+		paths, err := a.Write(projectDir, content)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", a.Name(), err)
+		}
+		written = append(written, paths...)
+	}
+	return written, nil
+}
+
+// aider reads extra context from files listed under "read:" in .aider.conf.yml
+type aider struct{}
+
+const (
+	aiderConfig  = ".aider.conf.yml"
+	aiderContext = ".aider.memorypilot.md"
+)
+
+func (aider) Name() string { return "aider" }
+
+func (aider) Detect(projectDir string) bool {
+	return exists(filepath.Join(projectDir, aiderConfig)) ||
+		exists(filepath.Join(projectDir, ".aider.chat.history.md"))
+}
+
+func (aider) Write(projectDir, content string) ([]string, error) {
+	var changed []string
+
+	path := filepath.Join(projectDir, aiderContext)
+	ok, err := writeIfChanged(path, []byte(content))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		changed = append(changed, path)
+	}
+
+	// Register the file as read-only context. An existing read: key is left
+	// alone rather than risk rewriting the user's YAML.
+	configPath := filepath.Join(projectDir, aiderConfig)
+	config, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return changed, err
+	}
+	if bytes.Contains(config, []byte(aiderContext)) || hasYAMLKey(string(config), "read") {
+		return changed, nil
+	}
+	if len(config) > 0 && !bytes.HasSuffix(config, []byte("\n")) {
+		config = append(config, '\n')
+	}
+	config = append(config, []byte("# Added by MemoryPilot\nread: "+aiderContext+"\n")...)
+	if err := os.WriteFile(configPath, config, 0644); err != nil {
+		return changed, err
+	}
+	return append(changed, configPath), nil
+}
+
+// continueDev loads every markdown rule under .continue/rules into context
+type continueDev struct{}
+
+func (continueDev) Name() string { return "continue" }
+
+func (continueDev) Detect(projectDir string) bool {
+	return exists(filepath.Join(projectDir, ".continue"))
+}
+
+func (continueDev) Write(projectDir, content string) ([]string, error) {
+	dir := filepath.Join(projectDir, ".continue", "rules")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	rule := "---\nname: MemoryPilot project context\nalwaysApply: true\n---\n\n" + content
+	path := filepath.Join(dir, "memorypilot.md")
+	ok, err := writeIfChanged(path, []byte(rule))
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []string{path}, nil
+}
+
+// writeIfChanged avoids touching files (and waking file watchers) when the
+// content is already up to date
+func writeIfChanged(path string, data []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	return true, os.WriteFile(path, data, 0644)
+}
+
+func hasYAMLKey(doc, key string) bool {
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(line, key+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -2,15 +2,32 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/memorypilot/memorypilot/internal/adapters"
+	"github.com/memorypilot/memorypilot/internal/api"
+	"github.com/memorypilot/memorypilot/internal/config"
 	"github.com/memorypilot/memorypilot/internal/embedding"
 	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/insights"
+	"github.com/memorypilot/memorypilot/internal/journal"
+	"github.com/memorypilot/memorypilot/internal/mining"
+	"github.com/memorypilot/memorypilot/internal/projects"
+	"github.com/memorypilot/memorypilot/internal/publish"
+	"github.com/memorypilot/memorypilot/internal/reprocess"
+	"github.com/memorypilot/memorypilot/internal/retention"
 	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/teamsync"
 	"github.com/memorypilot/memorypilot/internal/watcher"
+	"github.com/memorypilot/memorypilot/internal/webhook"
 	"github.com/memorypilot/memorypilot/pkg/models"
 	"github.com/oklog/ulid/v2"
 )
@@ -19,20 +36,115 @@ import (
 type Config struct {
 	DataDir         string
 	GitInterval     time.Duration
+	GitAuthors      []string // extra identities whose commits are captured
+	GitTeamCapture  bool     // capture commits from every author
+	GitStashes      bool     // capture git stash pushes
 	FileDebounce    time.Duration
+	FileIgnore      []string // directory names skipped besides the built-in ones
+	HistoryFiles    []string // shell history files; nil for the defaults
+	ScanBudget      int      // directories read per second by repo and file discovery
 	BatchSize       int
 	BatchWait       time.Duration
 	ExtractionModel string
+	MinConfidence   float64
+	ReviewBelow     float64 // memories less confident than this await review; 0 disables
+	DedupSimilarity float64 // merge memories this similar to a stored one; 0 disables
+	MinSignificance float64 // file changes scoring lower aren't extracted from
+	SearchWeights   models.SearchWeights
+
+	// ConfigPath is watched for changes; tuning (confidence threshold,
+	// batching, git interval, search weights) is reloaded from it without a
+	// restart
+	ConfigPath string
+
+	// Watchers that are turned off
+	DisableGit      bool
+	DisableFile     bool
+	DisableTerminal bool
+
+	// Offline keeps capturing events but defers extraction until the agent
+	// runs online again; keyword recall is unaffected
+	Offline bool
+
+	// Ordered provider chains; later providers are used when earlier ones
+	// are unreachable or over budget
+	Providers          []string // ollama | claude | null
+	EmbeddingProviders []string // ollama | local | null
+	EmbeddingModel     string   // Ollama's embedding model
+	LocalModelDir      string   // the local embedder's model; empty for the one in DataDir
+	ClaudeAPIKey       string
+	ClaudeModel        string
+	ClaudeDailyBudget  int // requests per day, 0 = unlimited
+
+	// Team sync (disabled when SyncEndpoint is empty)
+	SyncEndpoint string
+	SyncToken    string
+	SyncInterval time.Duration
+
+	// AllowedSigners, if set, is a file of trusted public keys; synced team
+	// memories not signed by one of them are rejected
+	AllowedSigners string
+
+	// CaptureQuietAfter is how long a normally active watcher may go
+	// without events before a capture alert is raised (0 disables the
+	// check); DesktopNotify also shows the alert as a notification
+	CaptureQuietAfter time.Duration
+	DesktopNotify     bool
+
+	// TmuxSessions are tmux sessions whose panes are captured (REPLs and
+	// ssh sessions that never reach shell history)
+	TmuxSessions []string
+
+	// RemoteHosts are ssh destinations running 'memorypilot remote-agent',
+	// whose git and terminal events are captured too
+	RemoteHosts []string
+
+	// RemindExpiring, if set, sends one reminder (desktop notification and
+	// ReminderCmd) per memory this long before it expires
+	RemindExpiring time.Duration
+	ReminderCmd    string
+
+	// APIAddr is where the HTTP API listens (empty disables it); APIToken,
+	// if set, is required as a bearer token
+	APIAddr  string
+	APIToken string
+
+	// AggregateInsights, if set, generates an anonymized insights report
+	// daily (see package insights)
+	AggregateInsights bool
+	Insights          insights.Options
+
+	// RetentionPolicies expire and then delete the memories they select
+	RetentionPolicies []retention.Policy
+
+	// ExpiryAction is what happens to expired memories: archived (kept,
+	// out of recall) or deleted
+	ExpiryAction string
 }
 
 // DefaultConfig returns the default agent configuration
 func DefaultConfig() *Config {
+	tuning := config.DefaultTuning()
 	return &Config{
-		GitInterval:     30 * time.Second,
-		FileDebounce:    500 * time.Millisecond,
-		BatchSize:       10,
-		BatchWait:       5 * time.Second,
-		ExtractionModel: "llama3.2",
+		GitInterval:        tuning.GitInterval,
+		FileDebounce:       500 * time.Millisecond,
+		ScanBudget:         watcher.DefaultScanBudget,
+		BatchSize:          tuning.BatchSize,
+		BatchWait:          tuning.BatchWait,
+		MinConfidence:      tuning.MinConfidence,
+		ReviewBelow:        tuning.ReviewBelow,
+		DedupSimilarity:    tuning.DedupSimilarity,
+		MinSignificance:    tuning.MinSignificance,
+		SearchWeights:      tuning.Search,
+		ExtractionModel:    "llama3.2",
+		Providers:          []string{extractor.ProviderOllama, extractor.ProviderRules},
+		EmbeddingProviders: []string{"ollama"},
+		EmbeddingModel:     "nomic-embed-text",
+		SyncInterval:       15 * time.Minute,
+		CaptureQuietAfter:  6 * time.Hour,
+		APIAddr:            api.DefaultAddr,
+		Insights:           insights.DefaultOptions(),
+		ExpiryAction:       config.ExpiryArchive,
 	}
 }
 
@@ -42,27 +154,88 @@ type Agent struct {
 	store      *store.Store
 	extractor  extractor.Extractor
 	embedder   embedding.Embedder
+	syncClient *teamsync.Client
 	eventQueue chan models.Event
+	journal    *journal.Journal
+	stored     chan models.Event // journaled events, once in the store
 	watchers   []watcher.Watcher
+	gitWatcher *watcher.GitWatcher
+	correlator *correlator
+	throttle   *throttle
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
+	started    time.Time
+
+	mu     sync.RWMutex
+	tuning config.Tuning
 }
 
 // New creates a new agent instance
 func New(cfg *Config) (*Agent, error) {
-	// Open store
+	// Open store, rebuilding it if it's corrupt rather than crash-looping
 	dbPath := cfg.DataDir + "/memories.db"
-	s, err := store.New(dbPath)
+	s, recovery, err := store.OpenOrRecover(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open store: %w", err)
 	}
+	if recovery != nil {
+		log.Print(recovery.Summary())
+		log.Printf("Recovery report written to %s", recovery.ReportPath)
+	}
 
-	// Initialize extractor (Ollama)
-	ext := extractor.NewOllamaExtractor("", cfg.ExtractionModel)
+	// Initialize extractor chain
+	ext, err := extractor.NewChain(cfg.Providers, extractor.ChainConfig{
+		OllamaModel:       cfg.ExtractionModel,
+		ClaudeAPIKey:      cfg.ClaudeAPIKey,
+		ClaudeModel:       cfg.ClaudeModel,
+		ClaudeDailyBudget: cfg.ClaudeDailyBudget,
+	})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	ext.SetExamples(s)
+	ext.SetRunRecorder(s)
+
+	// Initialize embedder chain
+	localModelDir := cfg.LocalModelDir
+	if localModelDir == "" {
+		localModelDir = embedding.LocalModelDir(cfg.DataDir)
+	}
+	emb, err := embedding.NewChain(cfg.EmbeddingProviders, embedding.ChainConfig{
+		OllamaModel:   cfg.EmbeddingModel,
+		LocalModelDir: localModelDir,
+	})
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.SetEmbeddingModel(emb.Model())
+
+	// Embeddings from a new model don't compare with those already stored
+	if previous, err := s.RecordEmbeddingModel(emb.Model()); err != nil {
+		log.Printf("Failed to record embedding model: %v", err)
+	} else if previous != "" {
+		log.Printf("Embedding model changed from %s to %s: semantic recall misses memories embedded before. Check with 'memorypilot embeddings check', re-embed with 'memorypilot embeddings backfill --all'", previous, emb.Model())
+	}
+
+	// Team sync is refused up front in local-only mode
+	var syncClient *teamsync.Client
+	if cfg.SyncEndpoint != "" {
+		syncClient, err = teamsync.NewClient(cfg.SyncEndpoint, cfg.SyncToken)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
 
-	// Initialize embedder (Ollama)
-	emb := embedding.NewOllamaEmbedder("", "nomic-embed-text")
+	// Captured events are journaled before they reach the store
+	j, err := journal.Open(cfg.DataDir + "/journal")
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to open event journal: %w", err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -70,11 +243,27 @@ func New(cfg *Config) (*Agent, error) {
 		config:     cfg,
 		store:      s,
 		extractor:  ext,
-		embedder:   emb,
+		embedder:   embedding.Cached(emb, s),
+		syncClient: syncClient,
 		eventQueue: make(chan models.Event, 10000),
+		journal:    j,
+		stored:     make(chan models.Event),
+		correlator: newCorrelator(),
+		throttle:   newThrottle(),
 		ctx:        ctx,
 		cancel:     cancel,
+		tuning:     config.DefaultTuning(),
 	}
+	a.tuning.MinConfidence = cfg.MinConfidence
+	a.tuning.ReviewBelow = cfg.ReviewBelow
+	a.tuning.DedupSimilarity = cfg.DedupSimilarity
+	a.tuning.MinSignificance = cfg.MinSignificance
+	a.tuning.BatchSize = cfg.BatchSize
+	a.tuning.BatchWait = cfg.BatchWait
+	a.tuning.GitInterval = cfg.GitInterval
+	a.tuning.Search = cfg.SearchWeights
+	ext.SetMinConfidence(cfg.MinConfidence)
+	s.SetSearchWeights(cfg.SearchWeights)
 
 	return a, nil
 }
@@ -82,20 +271,126 @@ func New(cfg *Config) (*Agent, error) {
 // Start begins the agent's background processing
 func (a *Agent) Start() error {
 	log.Println("Starting MemoryPilot agent...")
+	a.started = time.Now()
+
+	// Events left unprocessed by a previous run are caught up below
+	if n, err := a.store.DeferUnprocessedEvents(); err != nil {
+		log.Printf("Failed to defer leftover events: %v", err)
+	} else if n > 0 {
+		log.Printf("Deferred %d events left over from a previous run", n)
+	}
+	if n, err := reprocess.FlagStale(a.store); err != nil {
+		log.Printf("Failed to flag memories from older prompts: %v", err)
+	} else if n > 0 {
+		log.Printf("Extraction prompts changed: flagged %d low-confidence memories for 'memorypilot reprocess --flagged'", n)
+	}
+	if a.config.Offline {
+		log.Println("Offline mode: extraction is deferred until the agent runs online")
+	}
+
+	// Journal captured events, then ingest them into the store
+	a.wg.Add(1)
+	go a.journalLoop()
+	a.wg.Add(1)
+	go a.ingestLoop()
 
 	// Start event processor
 	a.wg.Add(1)
 	go a.processEvents()
 
+	// Extract deferred events once a provider is reachable
+	a.wg.Add(1)
+	go a.catchUpLoop()
+
 	// Start watchers
 	if err := a.startWatchers(); err != nil {
 		return fmt.Errorf("failed to start watchers: %w", err)
 	}
 
+	// Warn when a normally active watcher stops producing events
+	if a.config.CaptureQuietAfter > 0 {
+		a.wg.Add(1)
+		go a.healthLoop()
+	}
+
 	// Start importance decay (daily)
 	a.wg.Add(1)
 	go a.decayLoop()
 
+	// Back up the database (daily) for corruption recovery
+	a.wg.Add(1)
+	go a.backupLoop()
+
+	// Keep aider/continue.dev context files up to date
+	a.wg.Add(1)
+	go a.adapterLoop()
+
+	// Mine terminal history for recurring workflows (daily)
+	a.wg.Add(1)
+	go a.miningLoop()
+
+	// Deliver memory lifecycle webhooks
+	a.wg.Add(1)
+	go a.webhookLoop()
+
+	// Keep Notion/Confluence publish targets in sync
+	a.wg.Add(1)
+	go a.publishLoop()
+
+	// Remind about memories that are about to expire
+	if a.config.RemindExpiring > 0 {
+		a.wg.Add(1)
+		go a.reminderLoop()
+	}
+
+	// Archive or delete expired memories
+	a.wg.Add(1)
+	go a.expiryLoop()
+
+	// Embed summaries, topics and chunks of long content for recall
+	a.wg.Add(1)
+	go a.vectorLoop()
+
+	// Promote durable session memories before they expire
+	if !a.config.Offline {
+		a.wg.Add(1)
+		go a.graduateLoop()
+	}
+
+	// Enforce retention policies
+	if len(a.config.RetentionPolicies) > 0 {
+		a.wg.Add(1)
+		go a.retentionLoop()
+	}
+
+	// Aggregate anonymized org insights
+	if a.config.AggregateInsights {
+		a.wg.Add(1)
+		go a.insightsLoop()
+	}
+
+	// Serve the HTTP API
+	if a.config.APIAddr != "" {
+		a.wg.Add(1)
+		go a.apiLoop()
+	}
+
+	// Share the store with MCP clients
+	a.wg.Add(1)
+	go a.mcpLoop()
+
+	// Pick up tuning changes in config.yaml
+	if a.config.ConfigPath != "" {
+		a.wg.Add(1)
+		go a.configLoop()
+	}
+
+	// Start team cache refresh
+	if a.syncClient != nil {
+		a.wg.Add(1)
+		go a.syncLoop()
+	}
+
 	log.Println("MemoryPilot agent started")
 	return nil
 }
@@ -104,18 +399,19 @@ func (a *Agent) Start() error {
 func (a *Agent) Stop() {
 	log.Println("Stopping MemoryPilot agent...")
 
-	// Signal shutdown
-	a.cancel()
-
-	// Stop watchers
+	// Stop watchers first so everything they captured gets journaled
 	for _, w := range a.watchers {
 		w.Stop()
 	}
 
+	// Signal shutdown
+	a.cancel()
+
 	// Wait for goroutines
 	a.wg.Wait()
 
-	// Close store
+	// Close journal and store
+	a.journal.Close()
 	a.store.Close()
 
 	log.Println("MemoryPilot agent stopped")
@@ -124,38 +420,164 @@ func (a *Agent) Stop() {
 // startWatchers initializes and starts all watchers
 func (a *Agent) startWatchers() error {
 	// Git watcher
-	gitWatcher := watcher.NewGitWatcher(a.config.GitInterval, a.eventQueue)
-	if err := gitWatcher.Start(); err != nil {
-		log.Printf("Warning: Git watcher failed to start: %v", err)
-	} else {
-		a.watchers = append(a.watchers, gitWatcher)
+	if !a.config.DisableGit {
+		gitWatcher := watcher.NewGitWatcher(a.config.GitInterval, a.store, a.eventQueue)
+		gitWatcher.FilterAuthors(a.config.GitAuthors, a.config.GitTeamCapture)
+		gitWatcher.CaptureStashes(a.config.GitStashes)
+		gitWatcher.SetScanBudget(a.config.ScanBudget)
+		gitWatcher.UseScanStore(a.store)
+		if err := gitWatcher.Start(); err != nil {
+			log.Printf("Warning: Git watcher failed to start: %v", err)
+		} else {
+			a.watchers = append(a.watchers, gitWatcher)
+			a.gitWatcher = gitWatcher
+		}
 	}
 
 	// File watcher
-	fileWatcher := watcher.NewFileWatcher(a.config.FileDebounce, a.eventQueue)
-	if err := fileWatcher.Start(); err != nil {
-		log.Printf("Warning: File watcher failed to start: %v", err)
-	} else {
-		a.watchers = append(a.watchers, fileWatcher)
+	if !a.config.DisableFile {
+		fileWatcher := watcher.NewFileWatcher(a.config.FileDebounce, a.eventQueue)
+		fileWatcher.Ignore(a.config.FileIgnore)
+		fileWatcher.SetScanBudget(a.config.ScanBudget)
+		fileWatcher.UseScanStore(a.store)
+		if err := fileWatcher.Start(); err != nil {
+			log.Printf("Warning: File watcher failed to start: %v", err)
+		} else {
+			a.watchers = append(a.watchers, fileWatcher)
+		}
 	}
 
-	// Terminal watcher
-	termWatcher := watcher.NewTerminalWatcher(a.eventQueue)
-	if err := termWatcher.Start(); err != nil {
-		log.Printf("Warning: Terminal watcher failed to start: %v", err)
-	} else {
-		a.watchers = append(a.watchers, termWatcher)
+	// Terminal watcher; the shell hook's log ties commands to directories
+	if !a.config.DisableTerminal {
+		termWatcher := watcher.NewTerminalWatcher(a.eventQueue)
+		if a.config.HistoryFiles != nil {
+			termWatcher.SetHistoryFiles(a.config.HistoryFiles)
+		}
+		termWatcher.UseCwdLog(filepath.Join(a.config.DataDir, watcher.CwdLogName))
+		if err := termWatcher.Start(); err != nil {
+			log.Printf("Warning: Terminal watcher failed to start: %v", err)
+		} else {
+			a.watchers = append(a.watchers, termWatcher)
+		}
+	}
+
+	// Remote agents on dev servers, reached over ssh
+	if len(a.config.RemoteHosts) > 0 {
+		remoteWatcher := watcher.NewRemoteWatcher(a.config.RemoteHosts, a.eventQueue)
+		if err := remoteWatcher.Start(); err != nil {
+			log.Printf("Warning: Remote watcher failed to start: %v", err)
+		} else {
+			a.watchers = append(a.watchers, remoteWatcher)
+		}
+	}
+
+	// Tmux watcher, for designated sessions only
+	if len(a.config.TmuxSessions) > 0 {
+		tmuxWatcher := watcher.NewTmuxWatcher(a.config.TmuxSessions, filepath.Join(a.config.DataDir, "tmux"), a.eventQueue)
+		if err := tmuxWatcher.Start(); err != nil {
+			log.Printf("Warning: Tmux watcher failed to start: %v", err)
+		} else {
+			a.watchers = append(a.watchers, tmuxWatcher)
+		}
 	}
 
 	return nil
 }
 
-// processEvents handles the event queue
+// journalLoop durably appends captured events to the journal. On shutdown
+// it drains the queue so nothing captured is lost.
+func (a *Agent) journalLoop() {
+	defer a.wg.Done()
+
+	appendEvent := func(e models.Event) {
+		a.attributeEvent(&e)
+		if err := a.journal.Append(e); err != nil {
+			log.Printf("Failed to journal event, storing directly: %v", err)
+			if err := a.store.CreateEvent(&e); err != nil {
+				log.Printf("Failed to store event: %v", err)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			for {
+				select {
+				case e := <-a.eventQueue:
+					appendEvent(e)
+				default:
+					return
+				}
+			}
+		case e := <-a.eventQueue:
+			appendEvent(e)
+		}
+	}
+}
+
+// ingestLoop moves journaled events into the store in order, retrying with
+// backoff while the store is unavailable (locked, full, ...), and hands
+// them on for extraction
+func (a *Agent) ingestLoop() {
+	defer a.wg.Done()
+
+	const maxBackoff = 30 * time.Second
+	backoff := time.Second
+
+	for {
+		e, pos, ok, err := a.journal.Next()
+		if err != nil {
+			log.Printf("Failed to read event journal: %v", err)
+		}
+		if err != nil || !ok {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-a.journal.Notify():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+
+		for {
+			inserted, err := a.store.IngestEvent(&e)
+			if err == nil {
+				backoff = time.Second
+				if err := a.journal.Commit(pos); err != nil {
+					log.Printf("Failed to advance event journal: %v", err)
+				}
+				if !inserted {
+					break // replayed after a crash, or a repeat of a recent event
+				}
+				select {
+				case a.stored <- e:
+				case <-a.ctx.Done():
+					// Stored but not batched; deferred on next start
+					return
+				}
+				break
+			}
+
+			log.Printf("Failed to store event, retrying in %s: %v", backoff, err)
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}
+}
+
+// processEvents batches stored events for extraction
 func (a *Agent) processEvents() {
 	defer a.wg.Done()
 
-	batch := make([]models.Event, 0, a.config.BatchSize)
-	timer := time.NewTimer(a.config.BatchWait)
+	batch := make([]models.Event, 0, a.currentTuning().BatchSize)
+	timer := time.NewTimer(a.currentTuning().BatchWait)
 
 	for {
 		select {
@@ -166,18 +588,39 @@ func (a *Agent) processEvents() {
 			}
 			return
 
-		case event := <-a.eventQueue:
-			// Store event
-			if err := a.store.CreateEvent(&event); err != nil {
-				log.Printf("Failed to store event: %v", err)
-				continue
+		case event := <-a.stored:
+			// Keep the project's detected stack current
+			a.refreshStackOnManifest(event)
+
+			// Link build failures to the commit that fixed them
+			if fix := a.correlator.Observe(event); fix != nil {
+				if err := a.store.CreateEvent(fix); err != nil {
+					log.Printf("Failed to store event: %v", err)
+				} else {
+					batch = append(batch, *fix)
+				}
 			}
 
-			batch = append(batch, event)
-			if len(batch) >= a.config.BatchSize {
+			// Trivial saves, and events a noisy project sends beyond its
+			// share, stay recorded but out of extraction
+			switch {
+			case insignificant(event, a.currentTuning().MinSignificance):
+				if err := a.store.MarkEventProcessed(event.ID); err != nil {
+					log.Printf("Failed to mark event processed: %v", err)
+				}
+				a.recordYield([]models.Event{event}, false)
+			case !a.throttle.Keep(event):
+				if err := a.store.MarkEventSampled(event.ID); err != nil {
+					log.Printf("Failed to mark event sampled: %v", err)
+				}
+			default:
+				batch = append(batch, event)
+			}
+
+			if len(batch) >= a.currentTuning().BatchSize {
 				a.processBatch(batch)
 				batch = batch[:0]
-				timer.Reset(a.config.BatchWait)
+				timer.Reset(a.currentTuning().BatchWait)
 			}
 
 		case <-timer.C:
@@ -185,68 +628,207 @@ func (a *Agent) processEvents() {
 				a.processBatch(batch)
 				batch = batch[:0]
 			}
-			timer.Reset(a.config.BatchWait)
+			timer.Reset(a.currentTuning().BatchWait)
+		}
+	}
+}
+
+// recordYield counts file events per directory, and whether they led to
+// memories, for 'memorypilot doctor --suggest-ignores'
+func (a *Agent) recordYield(events []models.Event, yielded bool) {
+	dirs := make(map[string]int)
+	for _, e := range events {
+		if e.Type != "file_change" {
+			continue
+		}
+		if path, ok := e.Data["path"].(string); ok && path != "" {
+			dirs[filepath.Dir(path)] += 1 + e.Repeats
 		}
 	}
+	if err := a.store.RecordDirYield(dirs, yielded); err != nil {
+		log.Printf("Failed to record directory yield: %v", err)
+	}
 }
 
-// processBatch extracts memories from a batch of events
+// insignificant reports whether an event is a file change scored below
+// the threshold by the file watcher
+func insignificant(e models.Event, threshold float64) bool {
+	if e.Type != "file_change" {
+		return false
+	}
+	score, ok := e.Data["significance"].(float64)
+	return ok && score < threshold
+}
+
+// processBatch extracts memories from a batch of events. While offline, or
+// when no provider is reachable, the batch is deferred for catchUpLoop;
+// when extraction fails, it's retried later with backoff.
 func (a *Agent) processBatch(events []models.Event) {
+	if a.config.Offline {
+		a.deferBatch(events)
+		return
+	}
+
 	log.Printf("Processing batch of %d events...", len(events))
 
+	if err := a.extractBatch(events); err != nil {
+		if errors.Is(err, extractor.ErrUnavailable) {
+			log.Printf("Extraction unavailable, deferring batch: %v", err)
+			a.deferBatch(events)
+			return
+		}
+		a.failBatch(events, err)
+	}
+}
+
+// failBatch schedules a batch whose extraction failed for a retry with
+// backoff, rather than dropping its events
+func (a *Agent) failBatch(events []models.Event, err error) {
+	log.Printf("Extraction failed: %v", err)
+	exhausted, ferr := a.store.FailEvents(events, err.Error())
+	if ferr != nil {
+		log.Printf("Failed to record extraction failure: %v", ferr)
+		return
+	}
+	if exhausted > 0 {
+		log.Printf("Gave up on %d events after %d attempts; run 'memorypilot events retry' to try again",
+			exhausted, store.MaxExtractionAttempts)
+	}
+	if retrying := len(events) - exhausted; retrying > 0 {
+		log.Printf("Will retry extraction of %d events", retrying)
+	}
+}
+
+// deferBatch marks events as awaiting extraction
+func (a *Agent) deferBatch(events []models.Event) {
+	if err := a.store.DeferEvents(events); err != nil {
+		log.Printf("Failed to defer events: %v", err)
+		return
+	}
+	log.Printf("Deferred %d events for later extraction", len(events))
+}
+
+// extractBatch extracts and stores memories from events, marking them
+// processed on success. The events are left untouched on error.
+func (a *Agent) extractBatch(events []models.Event) error {
 	// Extract memories using LLM
 	extracted, err := a.extractor.Extract(events)
 	if err != nil {
-		log.Printf("Extraction failed: %v", err)
-		// Still mark events as processed to avoid reprocessing
-		for _, e := range events {
-			a.store.MarkEventProcessed(e.ID)
-		}
-		return
+		return err
 	}
 
 	log.Printf("Extracted %d memories from batch", len(extracted))
 
-	// Create memories in store
+	projectID := a.batchProject(events)
+	author := identity.OfEvents(events)
+	eventIDs := make([]string, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.ID
+	}
+
+	// Create memories in store; merged duplicates count as yield too
+	yielded := false
 	for _, ext := range extracted {
 		now := time.Now()
 		memory := models.Memory{
-			ID:      ulid.Make().String(),
-			Type:    models.MemoryType(ext.Type),
-			Content: ext.Content,
-			Summary: ext.Summary,
-			Scope:   models.MemoryScopePersonal,
+			ID:        ulid.Make().String(),
+			Type:      models.MemoryType(ext.Type),
+			Content:   ext.Content,
+			Summary:   ext.Summary,
+			Scope:     models.MemoryScopePersonal,
+			ProjectID: projectID,
 			Source: models.Source{
-				Type:      models.SourceTypeGit, // Default, could be smarter
+				Type:      extractor.BatchSourceType(events),
 				Reference: "batch",
 				Timestamp: now,
 			},
 			Confidence:     ext.Confidence,
+			Provider:       ext.Provider,
+			PromptVersion:  ext.PromptVersion,
 			Importance:     1.0,
 			Topics:         ext.Topics,
 			CreatedAt:      now,
 			LastAccessedAt: now,
 			AccessCount:    0,
+			Author:         author,
+		}
+		if memory.Confidence < a.currentTuning().ReviewBelow {
+			memory.Status = models.MemoryStatusPending
+		}
+		identity.Attribute(&memory)
+
+		emb, err := a.embedder.Embed(memory.Content)
+		if err != nil {
+			log.Printf("Failed to generate embedding: %v", err)
+		}
+		lineage := store.LineageEntry{
+			Action:     store.LineageExtracted,
+			RunID:      ext.RunID,
+			EventIDs:   eventIDs,
+			Provider:   ext.Provider,
+			Model:      ext.Model,
+			PromptHash: ext.PromptHash,
+			Confidence: ext.Confidence,
+		}
+
+		// Repeated events yield near-identical memories; fold them into
+		// the one already stored
+		if threshold := a.currentTuning().DedupSimilarity; threshold > 0 {
+			dup, err := a.store.NearDuplicate(&memory, emb, float32(threshold))
+			if err != nil {
+				log.Printf("Failed to check for duplicates: %v", err)
+			} else if dup != nil {
+				if err := a.store.MergeNearDuplicate(dup.ID, memory.Topics); err != nil {
+					log.Printf("Failed to merge duplicate: %v", err)
+				} else {
+					yielded = true
+					lineage.Action = store.LineageMerged
+					lineage.Note = fmt.Sprintf("%.0f%% similar: %s", dup.Similarity*100, memory.Summary)
+					a.recordLineage(dup.ID, lineage)
+					log.Printf("Merged near duplicate (%.0f%% similar) into %s: [%s] %s",
+						dup.Similarity*100, dup.ID, memory.Type, memory.Summary)
+				}
+				continue
+			}
 		}
 
 		// Save memory
 		if err := a.store.CreateMemory(&memory); err != nil {
-			log.Printf("Failed to save memory: %v", err)
+			if errors.Is(err, store.ErrDuplicate) {
+				yielded = true
+				lineage.Action = store.LineageMerged
+				lineage.Note = "identical content"
+				a.recordLineage(memory.ID, lineage)
+				log.Printf("Merged duplicate into %s: [%s] %s", memory.ID, memory.Type, memory.Summary)
+			} else {
+				log.Printf("Failed to save memory: %v", err)
+			}
 			continue
 		}
 
-		// Generate and store embedding
-		emb, err := a.embedder.Embed(memory.Content)
-		if err != nil {
-			log.Printf("Failed to generate embedding: %v", err)
-		} else if emb != nil {
+		if emb != nil {
 			if err := a.store.UpdateMemoryEmbedding(memory.ID, emb); err != nil {
 				log.Printf("Failed to store embedding: %v", err)
 			}
 		}
 
-		log.Printf("Created memory: [%s] %s", memory.Type, memory.Summary)
+		// Rule-derived memories stand in until a model can redo them
+		// ('memorypilot reprocess --flagged')
+		if ext.Provider == extractor.ProviderRules {
+			if err := a.store.FlagForReprocess(memory.ID); err != nil {
+				log.Printf("Failed to flag memory for reprocessing: %v", err)
+			}
+		}
+
+		a.recordLineage(memory.ID, lineage)
+		yielded = true
+		if memory.Status == models.MemoryStatusPending {
+			log.Printf("Created memory awaiting review (%.0f%% confidence): [%s] %s", memory.Confidence*100, memory.Type, memory.Summary)
+		} else {
+			log.Printf("Created memory: [%s] %s", memory.Type, memory.Summary)
+		}
 	}
+	a.recordYield(events, yielded)
 
 	// Mark events as processed
 	for _, e := range events {
@@ -256,9 +838,187 @@ func (a *Agent) processBatch(events []models.Event) {
 	}
 
 	log.Printf("Batch processed")
+	return nil
+}
+
+// recordLineage notes where a memory came from, for 'memorypilot why'
+func (a *Agent) recordLineage(memoryID string, e store.LineageEntry) {
+	if err := a.store.RecordLineage(memoryID, e); err != nil {
+		log.Printf("Failed to record lineage of %s: %v", memoryID, err)
+	}
+}
+
+// catchUpLoop periodically extracts deferred events, oldest first, and
+// failed batches whose retry is due, stopping while no provider is
+// available until the next tick
+func (a *Agent) catchUpLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if a.config.Offline {
+				continue
+			}
+			a.catchUp()
+			a.retryFailed()
+		}
+	}
+}
+
+// catchUp drains the deferred events in batches
+func (a *Agent) catchUp() {
+	for a.ctx.Err() == nil {
+		events, err := a.store.GetDeferredEvents(a.currentTuning().BatchSize)
+		if err != nil {
+			log.Printf("Failed to load deferred events: %v", err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		log.Printf("Catching up on %d deferred events...", len(events))
+		if err := a.extractBatch(events); err != nil {
+			if errors.Is(err, extractor.ErrUnavailable) {
+				log.Printf("Provider still unavailable, retrying later: %v", err)
+				return
+			}
+			a.failBatch(events, err)
+		}
+	}
 }
 
-// decayLoop periodically decays memory importance
+// retryFailed re-extracts batches whose retry is due, stopping while no
+// provider is available
+func (a *Agent) retryFailed() {
+	for a.ctx.Err() == nil {
+		events, err := a.store.DueRetries(a.currentTuning().BatchSize)
+		if err != nil {
+			log.Printf("Failed to load events to retry: %v", err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		log.Printf("Retrying extraction of %d events...", len(events))
+		if err := a.extractBatch(events); err != nil {
+			if errors.Is(err, extractor.ErrUnavailable) {
+				log.Printf("Provider unavailable, retrying later: %v", err)
+				return
+			}
+			a.failBatch(events, err)
+		}
+	}
+}
+
+// batchProject attributes a batch to the repository most of its events came
+// from, registering the project on first sight. Returns nil when the events
+// can't be tied to a known repository.
+func (a *Agent) batchProject(events []models.Event) *string {
+	repos, err := a.store.ListRepos()
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	best := ""
+	for _, e := range events {
+		repo, _ := e.Data["repo"].(string)
+		for _, key := range []string{"path", "cwd"} {
+			if path, ok := e.Data[key].(string); ok && repo == "" {
+				repo = enclosingRepo(repos, path)
+			}
+		}
+		if repo == "" {
+			continue
+		}
+		counts[repo]++
+		if counts[repo] > counts[best] {
+			best = repo
+		}
+	}
+	if best == "" {
+		return nil
+	}
+
+	project, err := a.store.EnsureProject(best)
+	if err != nil {
+		log.Printf("Failed to register project %s: %v", best, err)
+		return nil
+	}
+	return &project.ID
+}
+
+// attributeEvent assigns an event that ran in a known repository (a
+// terminal command with its cwd) to that repository's project
+func (a *Agent) attributeEvent(e *models.Event) {
+	cwd, _ := e.Data["cwd"].(string)
+	if _, remote := e.Data["host"]; remote {
+		return // a directory on another machine
+	}
+	if e.ProjectID != nil || cwd == "" {
+		return
+	}
+	repos, err := a.store.ListRepos()
+	if err != nil {
+		return
+	}
+	if repo := enclosingRepo(repos, cwd); repo != "" {
+		if project, err := a.store.EnsureProject(repo); err == nil {
+			e.ProjectID = &project.ID
+		}
+	}
+}
+
+// refreshStackOnManifest re-detects a project's languages and frameworks
+// when one of its manifests (go.mod, package.json, ...) changes
+func (a *Agent) refreshStackOnManifest(event models.Event) {
+	if event.Type != "file_change" {
+		return
+	}
+	path, _ := event.Data["path"].(string)
+	if !projects.IsManifest(filepath.Base(path)) {
+		return
+	}
+
+	// Only manifests at a repository root describe the project
+	repos, err := a.store.ListRepos()
+	if err != nil {
+		return
+	}
+	dir := filepath.Dir(path)
+	if enclosingRepo(repos, path) != dir {
+		return
+	}
+
+	stack, err := projects.RefreshStack(a.store, dir)
+	if err != nil {
+		log.Printf("Failed to refresh stack for %s: %v", dir, err)
+		return
+	}
+	log.Printf("Detected stack for %s: %s", filepath.Base(dir), stack)
+}
+
+// enclosingRepo returns the deepest repository containing path
+func enclosingRepo(repos []string, path string) string {
+	match := ""
+	for _, repo := range repos {
+		if (path == repo || strings.HasPrefix(path, repo+string(filepath.Separator))) && len(repo) > len(match) {
+			match = repo
+		}
+	}
+	return match
+}
+
+// decayLoop periodically decays memory importance and drops expired
+// idempotency keys
 func (a *Agent) decayLoop() {
 	defer a.wg.Done()
 
@@ -273,6 +1033,280 @@ func (a *Agent) decayLoop() {
 			if err := a.store.DecayImportance(); err != nil {
 				log.Printf("Failed to decay importance: %v", err)
 			}
+			if err := a.store.PruneIdempotencyKeys(time.Now().Add(-store.IdempotencyTTL)); err != nil {
+				log.Printf("Failed to prune idempotency keys: %v", err)
+			}
+			if _, err := a.store.PruneEmbeddingCache(time.Now().Add(-store.EmbeddingCacheTTL)); err != nil {
+				log.Printf("Failed to prune embedding cache: %v", err)
+			}
+		}
+	}
+}
+
+// backupLoop keeps a daily backup of the database, checking hourly so a
+// daemon that is often restarted still backs up once a day
+func (a *Agent) backupLoop() {
+	defer a.wg.Done()
+
+	backup := func() {
+		if time.Since(a.store.LastBackup()) < 24*time.Hour {
+			return
+		}
+		path, err := a.store.Backup()
+		if err != nil {
+			log.Printf("Backup failed: %v", err)
+			return
+		}
+		log.Printf("Backed up database to %s", path)
+	}
+
+	backup()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			backup()
+		}
+	}
+}
+
+// miningLoop periodically proposes recurring command sequences as pattern
+// memories for review
+func (a *Agent) miningLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	completer, _ := a.extractor.(extractor.Completer)
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			proposed, err := mining.Run(a.store, completer, time.Now().AddDate(0, 0, -30))
+			if err != nil {
+				log.Printf("Sequence mining failed: %v", err)
+				continue
+			}
+			for _, m := range proposed {
+				log.Printf("Proposed pattern for review: %s", m.Summary)
+			}
+		}
+	}
+}
+
+// webhookLoop delivers queued webhook events, including those queued by
+// other processes, and prunes old successful deliveries
+func (a *Agent) webhookLoop() {
+	defer a.wg.Done()
+
+	dispatcher := webhook.NewDispatcher(a.store)
+	lastPrune := time.Now()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := dispatcher.Flush(a.ctx); err != nil {
+				log.Printf("Webhook delivery failed: %v", err)
+			}
+			if time.Since(lastPrune) > 24*time.Hour {
+				if err := a.store.PruneWebhookDeliveries(time.Now().AddDate(0, 0, -7)); err != nil {
+					log.Printf("Failed to prune webhook deliveries: %v", err)
+				}
+				lastPrune = time.Now()
+			}
+		}
+	}
+}
+
+// publishLoop republishes each publish target when its interval is up
+func (a *Agent) publishLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			targets, err := a.store.ListPublishTargets()
+			if err != nil {
+				log.Printf("Failed to list publish targets: %v", err)
+				continue
+			}
+			creds := publish.CredentialsFromEnv()
+			for _, t := range targets {
+				if !t.Due(time.Now()) {
+					continue
+				}
+				res, err := publish.Run(a.ctx, a.store, t, creds, false)
+				if err != nil {
+					log.Printf("Publishing to %s %s failed: %v", t.Kind, t.Destination, err)
+				} else if res.Changed() {
+					log.Printf("Published to %s %s: %d new, %d updated, %d archived",
+						t.Kind, t.Destination, res.Published, res.Updated, res.Archived)
+				}
+			}
+		}
+	}
+}
+
+// adapterLoop periodically writes project context into the config files of
+// coding tools that don't speak MCP
+func (a *Agent) adapterLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			repos, err := a.store.ListRepos()
+			if err != nil {
+				log.Printf("Failed to list repos for adapters: %v", err)
+				continue
+			}
+			for _, repo := range repos {
+				written, err := adapters.Sync(a.store, repo, adapters.DefaultBudget)
+				if err != nil {
+					log.Printf("Failed to update tool context in %s: %v", repo, err)
+					continue
+				}
+				for _, path := range written {
+					log.Printf("Updated tool context: %s", path)
+				}
+			}
+		}
+	}
+}
+
+// currentTuning returns the tuning in effect
+func (a *Agent) currentTuning() config.Tuning {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tuning
+}
+
+// applyTuning switches to new tuning values while running
+func (a *Agent) applyTuning(t config.Tuning) {
+	a.mu.Lock()
+	a.tuning = t
+	a.mu.Unlock()
+
+	if ext, ok := a.extractor.(interface{ SetMinConfidence(float64) }); ok {
+		ext.SetMinConfidence(t.MinConfidence)
+	}
+	if a.gitWatcher != nil {
+		a.gitWatcher.SetInterval(t.GitInterval)
+	}
+	a.store.SetSearchWeights(t.Search)
+}
+
+// configLoop reloads tuning when the config file changes. Invalid configs
+// are reported and the running values kept.
+func (a *Agent) configLoop() {
+	defer a.wg.Done()
+
+	modTime := func() time.Time {
+		info, err := os.Stat(a.config.ConfigPath)
+		if err != nil {
+			return time.Time{}
+		}
+		return info.ModTime()
+	}
+	last := modTime()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			current := modTime()
+			if current.Equal(last) {
+				continue
+			}
+			last = current
+
+			f, err := config.Load(a.config.ConfigPath)
+			if err != nil {
+				log.Printf("Config reload failed, keeping current settings: %v", err)
+				continue
+			}
+			tuning, err := f.Tuning()
+			if err != nil {
+				log.Printf("Invalid config, keeping current settings: %v", err)
+				continue
+			}
+			if tuning == a.currentTuning() {
+				continue
+			}
+			a.applyTuning(tuning)
+			log.Printf("Reloaded tuning: minConfidence=%.2f reviewBelow=%.2f dedupSimilarity=%.2f minFileSignificance=%.2f batchSize=%d batchWait=%s gitInterval=%s",
+				tuning.MinConfidence, tuning.ReviewBelow, tuning.DedupSimilarity, tuning.MinSignificance, tuning.BatchSize, tuning.BatchWait, tuning.GitInterval)
+		}
+	}
+}
+
+// syncLoop keeps the local team cache fresh
+func (a *Agent) syncLoop() {
+	defer a.wg.Done()
+
+	cache, err := teamsync.OpenCache(a.config.DataDir+"/"+teamsync.CacheFile, a.syncClient)
+	if err != nil {
+		log.Printf("Team sync disabled: %v", err)
+		return
+	}
+	defer cache.Close()
+
+	if a.config.AllowedSigners != "" {
+		trusted, err := teamsync.LoadAllowedSigners(a.config.AllowedSigners)
+		if err != nil {
+			log.Printf("Team sync disabled: failed to load allowed signers: %v", err)
+			return
+		}
+		cache.RequireSignatures(trusted)
+	}
+
+	refresh := func() {
+		n, err := cache.Refresh()
+		if err != nil {
+			log.Printf("Team cache refresh failed (serving cached copy): %v", err)
+			return
+		}
+		log.Printf("Team cache refreshed: %d memories updated", n)
+	}
+
+	refresh()
+
+	ticker := time.NewTicker(a.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
 		}
 	}
 }
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/api"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// apiLoop serves the HTTP API until the agent stops. Posted events join
+// the capture pipeline like any watcher's.
+func (a *Agent) apiLoop() {
+	defer a.wg.Done()
+
+	srv := api.New(a.store, api.Options{
+		Addr:     a.config.APIAddr,
+		Token:    a.config.APIToken,
+		Embedder: a.embedder,
+		Capture: func(e models.Event) bool {
+			select {
+			case a.eventQueue <- e:
+				return true
+			default:
+				return false
+			}
+		},
+	})
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Printf("API listening on http://%s", srv.Addr())
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			log.Printf("API server failed: %v", err)
+		}
+	case <-a.ctx.Done():
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("API shutdown: %v", err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// correlationWindow is how far back a commit looks for the failure it fixed
+const correlationWindow = 2 * time.Hour
+
+// buildCommands are command prefixes that build or test a project
+var buildCommands = []string{
+	"go build", "go test", "go vet", "go run",
+	"npm test", "npm run build", "npm run test", "npm run lint",
+	"yarn test", "yarn build", "pnpm test", "pnpm build",
+	"cargo build", "cargo test", "cargo check",
+	"make", "pytest", "python -m pytest", "mvn ", "gradle ",
+	"tsc", "docker build",
+}
+
+// buildRun is a build/test command observed in the terminal
+type buildRun struct {
+	event   models.Event
+	command string
+	failed  bool // known failure (exit code) or inferred from a re-run
+}
+
+// correlator links build failures to the file changes and commit that fixed
+// them. Watchers emit these as unrelated events that land in different
+// batches; the correlator keeps a sliding window across batches and, when a
+// commit follows a failed build, synthesizes a build_fix event carrying both
+// sides of the story.
+type correlator struct {
+	runs  []buildRun
+	files []models.Event
+}
+
+func newCorrelator() *correlator {
+	return &correlator{}
+}
+
+// Observe records an event and returns a synthesized build_fix event if the
+// event completes a failure -> fix sequence
+func (c *correlator) Observe(e models.Event) *models.Event {
+	c.prune(e.Timestamp)
+
+	switch e.Type {
+	case "terminal_cmd":
+		cmd, _ := e.Data["command"].(string)
+		if !isBuildCommand(cmd) {
+			return nil
+		}
+		run := buildRun{event: e, command: cmd}
+		if code, ok := exitCode(e); ok {
+			run.failed = code != 0
+		}
+		// Re-running the same build after editing files means the last run
+		// most likely failed
+		for i := len(c.runs) - 1; i >= 0; i-- {
+			if c.runs[i].command == cmd {
+				if _, known := exitCode(c.runs[i].event); !known && c.filesChangedSince(c.runs[i].event.Timestamp) {
+					c.runs[i].failed = true
+				}
+				break
+			}
+		}
+		c.runs = append(c.runs, run)
+
+	case "file_change":
+		c.files = append(c.files, e)
+
+	case "git_commit":
+		return c.resolve(e)
+	}
+
+	return nil
+}
+
+// resolve pairs a commit with the earliest failed build still pending
+func (c *correlator) resolve(commit models.Event) *models.Event {
+	var failed []buildRun
+	for _, r := range c.runs {
+		if r.failed {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	first := failed[0]
+	var commands []string
+	seen := make(map[string]bool)
+	for _, r := range failed {
+		if !seen[r.command] {
+			seen[r.command] = true
+			commands = append(commands, r.command)
+		}
+	}
+
+	var changed []string
+	seenFiles := make(map[string]bool)
+	for _, f := range c.files {
+		if f.Timestamp.Before(first.event.Timestamp) {
+			continue
+		}
+		if path, ok := f.Data["path"].(string); ok && !seenFiles[path] {
+			seenFiles[path] = true
+			changed = append(changed, path)
+		}
+	}
+
+	data := map[string]interface{}{
+		"failedCommands": commands,
+		"failedAt":       first.event.Timestamp,
+		"changedFiles":   changed,
+		"commitMessage":  commit.Data["message"],
+		"commitHash":     commit.Data["hash"],
+		"repo":           commit.Data["repo"],
+		"fixDuration":    commit.Timestamp.Sub(first.event.Timestamp).Round(time.Minute).String(),
+	}
+	if output, ok := first.event.Data["output"].(string); ok {
+		data["failureOutput"] = output
+	}
+
+	// The story is told; start fresh for the next failure
+	c.runs = nil
+	c.files = nil
+
+	return &models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "build_fix",
+		Timestamp: commit.Timestamp,
+		Data:      data,
+		ProjectID: commit.ProjectID,
+	}
+}
+
+// filesChangedSince reports whether any file changed after t
+func (c *correlator) filesChangedSince(t time.Time) bool {
+	for _, f := range c.files {
+		if f.Timestamp.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops observations older than the correlation window
+func (c *correlator) prune(now time.Time) {
+	cutoff := now.Add(-correlationWindow)
+
+	runs := c.runs[:0]
+	for _, r := range c.runs {
+		if r.event.Timestamp.After(cutoff) {
+			runs = append(runs, r)
+		}
+	}
+	c.runs = runs
+
+	files := c.files[:0]
+	for _, f := range c.files {
+		if f.Timestamp.After(cutoff) {
+			files = append(files, f)
+		}
+	}
+	c.files = files
+}
+
+func isBuildCommand(cmd string) bool {
+	for _, prefix := range buildCommands {
+		if cmd == strings.TrimSpace(prefix) || strings.HasPrefix(cmd, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCode returns the command's exit code when the capture source knows it
+func exitCode(e models.Event) (int, bool) {
+	switch v := e.Data["exitCode"].(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
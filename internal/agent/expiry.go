@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+)
+
+// expiryInterval is how often expired memories are cleaned up
+const expiryInterval = time.Hour
+
+// expiryLoop archives or deletes memories once they expire. Recall already
+// skips them; this keeps the store from accumulating them.
+func (a *Agent) expiryLoop() {
+	defer a.wg.Done()
+
+	a.cleanExpired()
+
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.cleanExpired()
+		}
+	}
+}
+
+func (a *Agent) cleanExpired() {
+	if a.config.ExpiryAction == config.ExpiryDelete {
+		n, err := a.store.DeleteExpired(time.Now())
+		if err != nil {
+			log.Printf("Failed to delete expired memories: %v", err)
+		} else if n > 0 {
+			log.Printf("Deleted %d expired memories", n)
+		}
+		return
+	}
+	n, err := a.store.ArchiveExpired(time.Now())
+	if err != nil {
+		log.Printf("Failed to archive expired memories: %v", err)
+	} else if n > 0 {
+		log.Printf("Archived %d expired memories", n)
+	}
+}
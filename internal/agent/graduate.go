@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/graduate"
+)
+
+// graduateInterval is how often session memories nearing expiry are
+// reviewed; well under graduate.Window, so none expire unreviewed
+const graduateInterval = 15 * time.Minute
+
+// graduateLoop promotes session memories that prove durable to the main
+// store before they expire
+func (a *Agent) graduateLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(graduateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.graduateSessions()
+		}
+	}
+}
+
+func (a *Agent) graduateSessions() {
+	result, err := graduate.Run(a.store, a.extractor, a.embedder, graduate.Options{
+		Within:    graduate.Window,
+		BatchSize: a.currentTuning().BatchSize,
+	})
+	if err != nil {
+		log.Printf("Failed to graduate session memories: %v", err)
+	}
+	if result != nil && len(result.Graduated) > 0 {
+		log.Printf("Graduated %d of %d session memories", len(result.Graduated), result.Reviewed)
+	}
+}
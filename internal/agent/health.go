@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+const (
+	// healthInterval is how often capture is checked
+	healthInterval = 15 * time.Minute
+
+	// A watcher counts as normally active when it produced events in the
+	// same time-of-day window on at least activeDays of the last
+	// baselineDays days, so quiet nights and weekends don't raise alerts
+	baselineDays = 7
+	activeDays   = 3
+)
+
+// capturedBy lists the watchers whose silence is checked
+var capturedBy = []string{"git", "file", "terminal"}
+
+// captureHints suggests the usual cause when a watcher goes quiet
+var captureHints = map[string]string{
+	"git":      "repositories may have moved or git polling is failing; check the daemon log",
+	"file":     "the inotify watch limit may be exhausted; raise fs.inotify.max_user_watches",
+	"terminal": "the shell history file may have been rotated or moved, or HISTFILE changed",
+}
+
+// healthLoop periodically checks that watchers which are normally active
+// are still producing events, rather than letting capture fail silently
+func (a *Agent) healthLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(healthInterval)
+	defer ticker.Stop()
+
+	alerts := make(map[string]store.CaptureAlert)
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkCapture(alerts, time.Now())
+		}
+	}
+}
+
+// checkCapture raises an alert for each watcher that has been silent for
+// CaptureQuietAfter when it would usually have produced events, and clears
+// alerts once events resume. alerts carries state between checks.
+func (a *Agent) checkCapture(alerts map[string]store.CaptureAlert, now time.Time) {
+	window := a.config.CaptureQuietAfter
+	if now.Sub(a.started) < window {
+		return // not running long enough to tell
+	}
+
+	recent, err := a.store.CaptureCounts(now.Add(-window), now)
+	if err != nil {
+		log.Printf("Capture health check failed: %v", err)
+		return
+	}
+
+	activeOn := make(map[string]int)
+	for d := 1; d <= baselineDays; d++ {
+		end := now.AddDate(0, 0, -d)
+		counts, err := a.store.CaptureCounts(end.Add(-window), end)
+		if err != nil {
+			log.Printf("Capture health check failed: %v", err)
+			return
+		}
+		for w, n := range counts {
+			if n > 0 {
+				activeOn[w]++
+			}
+		}
+	}
+
+	for _, w := range capturedBy {
+		if a.watcherDisabled(w) {
+			continue // silent on purpose
+		}
+		_, raised := alerts[w]
+		if recent[w] > 0 {
+			if raised {
+				log.Printf("✅ Capture resumed: %s watcher is producing events again", w)
+				delete(alerts, w)
+			}
+			continue
+		}
+		if raised || activeOn[w] < activeDays {
+			continue
+		}
+
+		last, _ := a.store.LastCaptured(w)
+		alert := store.CaptureAlert{
+			Watcher:   w,
+			QuietFor:  window.String(),
+			LastEvent: last,
+			Hint:      captureHints[w],
+			RaisedAt:  now,
+		}
+		alerts[w] = alert
+
+		message := fmt.Sprintf("No %s events for %s, though it is usually active at this time; %s", w, window, alert.Hint)
+		log.Printf("⚠️  Capture stopped: %s", message)
+		if a.config.DesktopNotify {
+			if err := desktopNotify("MemoryPilot capture stopped", message); err != nil {
+				log.Printf("Desktop notification failed: %v", err)
+			}
+		}
+	}
+
+	current := make([]store.CaptureAlert, 0, len(alerts))
+	for _, w := range capturedBy {
+		if alert, ok := alerts[w]; ok {
+			current = append(current, alert)
+		}
+	}
+	if err := a.store.SetCaptureAlerts(current); err != nil {
+		log.Printf("Failed to save capture alerts: %v", err)
+	}
+}
+
+// watcherDisabled reports whether a watcher was turned off in the config
+func (a *Agent) watcherDisabled(w string) bool {
+	switch w {
+	case "git":
+		return a.config.DisableGit
+	case "file":
+		return a.config.DisableFile
+	case "terminal":
+		return a.config.DisableTerminal
+	}
+	return false
+}
+
+// desktopNotify shows a desktop notification with the platform's own tool
+func desktopNotify(title, message string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "linux":
+		return exec.Command("notify-send", title, message).Run()
+	default:
+		return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/insights"
+)
+
+// insightsInterval is how often the insights report is regenerated
+const insightsInterval = 24 * time.Hour
+
+// insightsLoop regenerates the anonymized insights report daily
+func (a *Agent) insightsLoop() {
+	defer a.wg.Done()
+
+	a.aggregateInsights()
+
+	ticker := time.NewTicker(insightsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.aggregateInsights()
+		}
+	}
+}
+
+func (a *Agent) aggregateInsights() {
+	r, err := insights.Generate(a.store, a.config.Insights, time.Now())
+	if err != nil {
+		log.Printf("Failed to aggregate insights: %v", err)
+		return
+	}
+	log.Printf("Aggregated insights: %d topics, %d mistake categories", len(r.Topics), len(r.Mistakes))
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"log"
+
+	"github.com/memorypilot/memorypilot/internal/mcp"
+)
+
+// mcpLoop serves 'memorypilot mcp' processes on a unix socket, so AI tools
+// running side by side share the daemon's store instead of each opening
+// the database
+func (a *Agent) mcpLoop() {
+	defer a.wg.Done()
+
+	path := mcp.SocketPath(a.config.DataDir)
+	ln, err := mcp.ListenSocket(path)
+	if err != nil {
+		log.Printf("MCP socket unavailable, clients will open the database directly: %v", err)
+		return
+	}
+
+	srv := mcp.NewServerFor(a.store, a.embedder)
+	if !a.config.Offline {
+		srv.SetExtractor(a.extractor)
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	log.Printf("MCP clients served on %s", path)
+
+	select {
+	case err := <-errc:
+		if err != nil {
+			log.Printf("MCP socket failed: %v", err)
+		}
+	case <-a.ctx.Done():
+		ln.Close()
+	}
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// reminderInterval is how often expiring memories are checked
+const reminderInterval = 15 * time.Minute
+
+// reminderLoop reminds once about each memory that will expire within
+// RemindExpiring, with a desktop notification and ReminderCmd
+func (a *Agent) reminderLoop() {
+	defer a.wg.Done()
+
+	a.remindExpiring()
+
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.remindExpiring()
+		}
+	}
+}
+
+func (a *Agent) remindExpiring() {
+	now := time.Now()
+	memories, err := a.store.UnremindedExpiring(now, now.Add(a.config.RemindExpiring))
+	if err != nil {
+		log.Printf("Failed to check expiring memories: %v", err)
+		return
+	}
+
+	for _, m := range memories {
+		message := fmt.Sprintf("%s (expires %s)", m.Summary, m.ExpiresAt.Local().Format("Mon Jan 2 15:04"))
+		log.Printf("⏰ Expiring memory %s: %s", m.ID, message)
+		if err := desktopNotify("MemoryPilot: revisit before it expires", message); err != nil {
+			log.Printf("Desktop notification failed: %v", err)
+		}
+		if a.config.ReminderCmd != "" {
+			if err := runReminderCmd(a.config.ReminderCmd, m); err != nil {
+				log.Printf("Reminder command failed for %s: %v", m.ID, err)
+			}
+		}
+		if err := a.store.MarkReminded(m.ID, now); err != nil {
+			log.Printf("Failed to record reminder for %s: %v", m.ID, err)
+		}
+	}
+}
+
+// runReminderCmd runs the reminder hook through the shell with the memory
+// described in its environment
+func runReminderCmd(command string, m models.Memory) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"MEMORYPILOT_MEMORY_ID="+m.ID,
+		"MEMORYPILOT_MEMORY_TYPE="+string(m.Type),
+		"MEMORYPILOT_MEMORY_SUMMARY="+m.Summary,
+		"MEMORYPILOT_MEMORY_CONTENT="+m.Content,
+		"MEMORYPILOT_MEMORY_EXPIRES="+m.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/retention"
+)
+
+// retentionInterval is how often retention policies are applied
+const retentionInterval = time.Hour
+
+// retentionLoop applies the retention policies hourly, so memories are
+// deleted soon after their policy expires them
+func (a *Agent) retentionLoop() {
+	defer a.wg.Done()
+
+	a.applyRetention()
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.applyRetention()
+		}
+	}
+}
+
+func (a *Agent) applyRetention() {
+	results, purged, err := retention.Apply(a.store, a.config.RetentionPolicies, time.Now(), false)
+	if err != nil {
+		log.Printf("Failed to apply retention policies: %v", err)
+		return
+	}
+	for _, res := range results {
+		if res.Warning != "" {
+			log.Printf("Retention policy %s: %s", res.Policy.Name, res.Warning)
+		}
+		if res.Scheduled > 0 {
+			log.Printf("Retention policy %s: set expiry on %d memories", res.Policy.Name, res.Scheduled)
+		}
+	}
+	if purged > 0 {
+		log.Printf("Retention: deleted %d expired memories", purged)
+	}
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// throttleWindow is how far back event volume is measured
+	throttleWindow = time.Hour
+
+	// throttleMinEvents is the volume below which a project is never
+	// sampled, however lopsided the traffic
+	throttleMinEvents = 100
+
+	// throttleMaxShare is the share of the window's events a project may
+	// send to extraction before it is sampled down to that share
+	throttleMaxShare = 0.5
+)
+
+// throttle samples projects that produce a disproportionate share of
+// events (generated code churn, vendored updates), so one noisy repo
+// doesn't crowd every other project out of extraction. Commits and build
+// fixes are always kept; the sampled events are the per-file and
+// per-command ones that churn produces.
+type throttle struct {
+	seen    []projectEvent
+	counts  map[string]int     // events per project in the window
+	credit  map[string]float64 // fractional events owed to each sampled project
+	noisy   map[string]bool    // projects currently sampled, for logging
+	touched time.Time
+}
+
+type projectEvent struct {
+	project string
+	at      time.Time
+}
+
+func newThrottle() *throttle {
+	return &throttle{
+		counts: make(map[string]int),
+		credit: make(map[string]float64),
+		noisy:  make(map[string]bool),
+	}
+}
+
+// Keep records an event and reports whether it should be extracted
+func (t *throttle) Keep(e models.Event) bool {
+	project := ""
+	if e.ProjectID != nil {
+		project = *e.ProjectID
+	}
+	now := e.Timestamp
+	if now.Before(t.touched) {
+		now = t.touched // replayed or backdated events don't rewind the window
+	}
+	t.touched = now
+	t.prune(now)
+	t.seen = append(t.seen, projectEvent{project, now})
+	t.counts[project]++
+
+	rate := t.rate(project)
+	if rate >= 1 {
+		if t.noisy[project] {
+			delete(t.noisy, project)
+			delete(t.credit, project)
+			log.Printf("Project %s is no longer sampled", orNone(project))
+		}
+		return true
+	}
+	if !t.noisy[project] {
+		t.noisy[project] = true
+		log.Printf("Project %s sent %d of the last hour's %d events; sampling %.0f%% of them for extraction",
+			orNone(project), t.counts[project], len(t.seen), rate*100)
+	}
+	if !sampleable(e.Type) {
+		return true
+	}
+
+	// Keep a steady fraction rather than a random one, so sampled
+	// projects still contribute evenly
+	t.credit[project] += rate
+	if t.credit[project] >= 1 {
+		t.credit[project]--
+		return true
+	}
+	return false
+}
+
+// rate is the fraction of a project's events to keep
+func (t *throttle) rate(project string) float64 {
+	n := t.counts[project]
+	if n < throttleMinEvents || n == len(t.seen) {
+		// Quiet, or nothing else to starve
+		return 1
+	}
+	allowed := throttleMaxShare * float64(len(t.seen))
+	if float64(n) <= allowed {
+		return 1
+	}
+	return allowed / float64(n)
+}
+
+func (t *throttle) prune(now time.Time) {
+	cutoff := now.Add(-throttleWindow)
+	i := 0
+	for i < len(t.seen) && t.seen[i].at.Before(cutoff) {
+		t.counts[t.seen[i].project]--
+		if t.counts[t.seen[i].project] <= 0 {
+			delete(t.counts, t.seen[i].project)
+		}
+		i++
+	}
+	t.seen = t.seen[i:]
+}
+
+// sampleable reports whether events of a type may be sampled out
+func sampleable(eventType string) bool {
+	return !strings.HasPrefix(eventType, "git_") && eventType != "build_fix"
+}
+
+func orNone(project string) string {
+	if project == "" {
+		return "(none)"
+	}
+	return project
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/vectors"
+)
+
+const (
+	// vectorInterval is how often memories' summaries, topics and chunks are
+	// embedded, catching memories from every writer: extraction, MCP, the
+	// API and imports
+	vectorInterval = 5 * time.Minute
+
+	// vectorLimit bounds the memories embedded per run, so a large backlog
+	// is worked through without holding up other embedding
+	vectorLimit = 200
+)
+
+// vectorLoop embeds the summary, topics and chunk vectors hybrid search
+// matches queries against alongside content embeddings
+func (a *Agent) vectorLoop() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(vectorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := vectors.Fill(a.store, a.embedder, vectorLimit, vectors.DefaultBatchSize)
+			if err != nil {
+				log.Printf("Failed to embed summaries, topics and chunks: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Embedded %d summary, topics and chunk vectors", n)
+			}
+		}
+	}
+}
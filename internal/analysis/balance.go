@@ -0,0 +1,155 @@
+// Package analysis inspects the memory store for signs that extraction is
+// miscalibrated.
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// MinSample is the number of extracted memories needed before the
+	// distribution says anything about extraction
+	MinSample = 20
+
+	// dominantShare flags a type that crowds out the others
+	dominantShare = 0.6
+
+	// highRejection flags a type the user mostly rejects in review, once
+	// at least minReviewed of its memories have been reviewed
+	highRejection = 0.5
+	minReviewed   = 5
+
+	// confidenceMargin flags types whose average confidence sits just
+	// above the threshold
+	confidenceMargin = 0.05
+)
+
+// Severity levels for findings
+const (
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// expectedTypes are the types a healthy store accumulates over time
+var expectedTypes = []models.MemoryType{
+	models.MemoryTypeDecision,
+	models.MemoryTypePattern,
+	models.MemoryTypeFact,
+	models.MemoryTypePreference,
+	models.MemoryTypeMistake,
+	models.MemoryTypeLearning,
+}
+
+// typeAdvice explains how each type usually gets extracted, used when it is
+// missing or underrepresented
+var typeAdvice = map[models.MemoryType]string{
+	models.MemoryTypeDecision:   "decisions come from commit messages that say why; try writing \"chose X because Y\" in commits",
+	models.MemoryTypePattern:    "patterns come from repeated workflows; run 'memorypilot mine' and review its proposals",
+	models.MemoryTypeFact:       "facts come from commits and file changes; check that the git and file watchers are running",
+	models.MemoryTypePreference: "preferences come from editor, linter and dotfile changes; check that those files are watched",
+	models.MemoryTypeMistake:    "mistakes come from failed commands followed by fixing commits; make sure terminal capture is enabled",
+	models.MemoryTypeLearning:   "learnings need context the events rarely carry; consider a stronger extraction model",
+}
+
+// TypeShare is one type's slice of the extracted memories
+type TypeShare struct {
+	store.TypeStat
+	Total int     `json:"total"`
+	Share float64 `json:"share"`
+}
+
+// Finding is a detected anomaly with a suggested fix
+type Finding struct {
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// TypeReport describes how extracted memories are distributed over types
+type TypeReport struct {
+	Total    int         `json:"total"`
+	Types    []TypeShare `json:"types"`
+	Findings []Finding   `json:"findings"`
+}
+
+// AnalyzeTypes flags skew in the type distribution of extracted memories.
+// minConfidence is the extraction threshold currently in effect.
+func AnalyzeTypes(stats []store.TypeStat, minConfidence float64) *TypeReport {
+	report := &TypeReport{}
+	byType := make(map[models.MemoryType]store.TypeStat)
+	for _, t := range stats {
+		byType[models.MemoryType(t.Type)] = t
+		report.Total += t.Approved + t.Pending + t.Rejected
+	}
+
+	for _, t := range stats {
+		total := t.Approved + t.Pending + t.Rejected
+		share := TypeShare{TypeStat: t, Total: total}
+		if report.Total > 0 {
+			share.Share = float64(total) / float64(report.Total)
+		}
+		report.Types = append(report.Types, share)
+	}
+	sort.Slice(report.Types, func(i, j int) bool {
+		return report.Types[i].Total > report.Types[j].Total
+	})
+
+	if report.Total < MinSample {
+		report.Findings = append(report.Findings, Finding{
+			Severity:   SeverityInfo,
+			Message:    fmt.Sprintf("Only %d extracted memories so far; the distribution isn't meaningful yet", report.Total),
+			Suggestion: fmt.Sprintf("check back after %d or more", MinSample),
+		})
+		return report
+	}
+
+	for _, share := range report.Types {
+		memType := models.MemoryType(share.Type)
+
+		if share.Share >= dominantShare {
+			suggestion := typeAdvice[memType]
+			if memType == models.MemoryTypeFact || memType == models.MemoryTypeContext {
+				suggestion = fmt.Sprintf("routine changes are probably being recorded; raise extraction.minConfidence (now %.2f) or use a stronger model", minConfidence)
+			}
+			report.Findings = append(report.Findings, Finding{
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("%.0f%% of extracted memories are %ss", share.Share*100, share.Type),
+				Suggestion: suggestion,
+			})
+		}
+
+		reviewed := share.Approved + share.Rejected
+		if reviewed >= minReviewed && float64(share.Rejected)/float64(reviewed) >= highRejection {
+			report.Findings = append(report.Findings, Finding{
+				Severity:   SeverityWarning,
+				Message:    fmt.Sprintf("You rejected %d of %d reviewed %ss", share.Rejected, reviewed, share.Type),
+				Suggestion: fmt.Sprintf("raise extraction.minConfidence (now %.2f) so fewer weak %ss are proposed", minConfidence, share.Type),
+			})
+		}
+
+		if share.AvgConfidence < minConfidence+confidenceMargin {
+			report.Findings = append(report.Findings, Finding{
+				Severity:   SeverityInfo,
+				Message:    fmt.Sprintf("%ss average confidence %.2f, barely above the %.2f threshold", share.Type, share.AvgConfidence, minConfidence),
+				Suggestion: "the model is unsure about these; a stronger model or accepted examples of this type will help",
+			})
+		}
+	}
+
+	for _, memType := range expectedTypes {
+		if byType[memType].Approved+byType[memType].Pending > 0 {
+			continue
+		}
+		report.Findings = append(report.Findings, Finding{
+			Severity:   SeverityInfo,
+			Message:    fmt.Sprintf("No %ss have been extracted", memType),
+			Suggestion: typeAdvice[memType],
+		})
+	}
+
+	return report
+}
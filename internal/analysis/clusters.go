@@ -0,0 +1,297 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// maxClusters caps the automatic choice of k
+	maxClusters = 20
+
+	// kmeansIterations bounds k-means when assignments keep changing
+	kmeansIterations = 50
+
+	// labelTopics is the number of top topics used as a cluster's label
+	labelTopics = 3
+
+	// labelSamples is the number of summaries shown to the LLM per cluster
+	labelSamples = 8
+)
+
+// Cluster is a group of memories that sit close together in embedding space
+type Cluster struct {
+	Label    string          `json:"label"`
+	Topics   []string        `json:"topics"`
+	Types    map[string]int  `json:"types"`
+	Size     int             `json:"size"`
+	Members  []models.Memory `json:"members"`
+	Cohesion float64         `json:"cohesion"` // mean similarity to the centroid
+}
+
+// ClusterMemories groups memories with spherical k-means (cosine distance),
+// largest cluster first. With k <= 0 a k is chosen from the number of
+// memories. Memories whose embedding dimension differs from the majority,
+// e.g. from an older embedding model, are left out.
+func ClusterMemories(memories []store.EmbeddedMemory, k int) []Cluster {
+	vectors, members := normalizedVectors(memories)
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	if k <= 0 {
+		k = int(math.Round(math.Sqrt(float64(len(vectors)) / 2)))
+		if k > maxClusters {
+			k = maxClusters
+		}
+	}
+	if k < 1 {
+		k = 1
+	}
+	if k > len(vectors) {
+		k = len(vectors)
+	}
+
+	centroids := seedCentroids(vectors, k)
+	assign := make([]int, len(vectors))
+	for i := range assign {
+		assign[i] = -1
+	}
+
+	for iter := 0; iter < kmeansIterations; iter++ {
+		changed := false
+		for i, v := range vectors {
+			best := nearest(centroids, v)
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		centroids = recomputeCentroids(vectors, assign, centroids)
+	}
+
+	clusters := make([]Cluster, k)
+	for i := range clusters {
+		clusters[i].Types = make(map[string]int)
+	}
+	for i, c := range assign {
+		m := members[i]
+		clusters[c].Members = append(clusters[c].Members, m)
+		clusters[c].Types[string(m.Type)]++
+		clusters[c].Cohesion += dot(vectors[i], centroids[c])
+	}
+
+	var result []Cluster
+	for _, c := range clusters {
+		if len(c.Members) == 0 {
+			continue
+		}
+		c.Size = len(c.Members)
+		c.Cohesion /= float64(c.Size)
+		c.Topics = topTopics(c.Members, labelTopics)
+		c.Label = topicLabel(c)
+		result = append(result, c)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Size > result[j].Size })
+	return result
+}
+
+// LabelClusters replaces topic-based labels with a short name written by
+// the LLM from a sample of each cluster's summaries. Clusters the model
+// fails on keep their topic label.
+func LabelClusters(c extractor.Completer, clusters []Cluster) error {
+	for i := range clusters {
+		var sb strings.Builder
+		for j, m := range clusters[i].Members {
+			if j == labelSamples {
+				break
+			}
+			fmt.Fprintf(&sb, "- %s\n", m.Summary)
+		}
+
+		response, err := c.Complete(fmt.Sprintf(labelPrompt, sb.String()))
+		if err != nil {
+			return err
+		}
+		label := strings.Trim(strings.TrimSpace(response), `"'.`)
+		if label != "" && !strings.Contains(label, "\n") {
+			clusters[i].Label = label
+		}
+	}
+	return nil
+}
+
+const labelPrompt = `These notes from a developer's memory belong together:
+
+%s
+Name the common theme in 2 to 5 words. Reply with the name only.`
+
+// normalizedVectors returns unit-length embeddings of the majority
+// dimension, with the memories they belong to
+func normalizedVectors(memories []store.EmbeddedMemory) ([][]float64, []models.Memory) {
+	dims := make(map[int]int)
+	for _, m := range memories {
+		dims[len(m.Embedding)]++
+	}
+	dim, count := 0, 0
+	for d, n := range dims {
+		if n > count || (n == count && d > dim) {
+			dim, count = d, n
+		}
+	}
+
+	var vectors [][]float64
+	var members []models.Memory
+	for _, m := range memories {
+		if len(m.Embedding) != dim || dim == 0 {
+			continue
+		}
+		v := make([]float64, dim)
+		for i, x := range m.Embedding {
+			v[i] = float64(x)
+		}
+		if normalize(v) {
+			vectors = append(vectors, v)
+			members = append(members, m.Memory)
+		}
+	}
+	return vectors, members
+}
+
+// seedCentroids picks initial centroids with k-means++. The seed is fixed
+// so the same memories always give the same clusters.
+func seedCentroids(vectors [][]float64, k int) [][]float64 {
+	rng := rand.New(rand.NewSource(1))
+	centroids := [][]float64{clone(vectors[rng.Intn(len(vectors))])}
+
+	dist := make([]float64, len(vectors))
+	for len(centroids) < k {
+		total := 0.0
+		for i, v := range vectors {
+			d := 1 - dot(v, centroids[nearest(centroids, v)])
+			dist[i] = d * d
+			total += dist[i]
+		}
+		if total == 0 {
+			break // fewer distinct points than k
+		}
+		target := rng.Float64() * total
+		next := len(vectors) - 1
+		for i, d := range dist {
+			target -= d
+			if target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, clone(vectors[next]))
+	}
+	return centroids
+}
+
+// recomputeCentroids moves each centroid to the normalized mean of its
+// members; a centroid left without members stays where it was
+func recomputeCentroids(vectors [][]float64, assign []int, old [][]float64) [][]float64 {
+	sums := make([][]float64, len(old))
+	for i := range sums {
+		sums[i] = make([]float64, len(vectors[0]))
+	}
+	for i, c := range assign {
+		for j, x := range vectors[i] {
+			sums[c][j] += x
+		}
+	}
+	for i := range sums {
+		if !normalize(sums[i]) {
+			sums[i] = old[i]
+		}
+	}
+	return sums
+}
+
+func nearest(centroids [][]float64, v []float64) int {
+	best, bestSim := 0, math.Inf(-1)
+	for i, c := range centroids {
+		if sim := dot(c, v); sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+	return best
+}
+
+// topTopics returns the most frequent topics among the members
+func topTopics(members []models.Memory, n int) []string {
+	counts := make(map[string]int)
+	display := make(map[string]string)
+	for _, m := range members {
+		for _, t := range m.Topics {
+			key := strings.ToLower(t)
+			if _, ok := display[key]; !ok {
+				display[key] = t
+			}
+			counts[key]++
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+
+	topics := make([]string, len(keys))
+	for i, k := range keys {
+		topics[i] = display[k]
+	}
+	return topics
+}
+
+// topicLabel names a cluster after its top topics, or after its first
+// memory when none of them have topics
+func topicLabel(c Cluster) string {
+	if len(c.Topics) > 0 {
+		return strings.Join(c.Topics, ", ")
+	}
+	return c.Members[0].Summary
+}
+
+func normalize(v []float64) bool {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return false
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return true
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func clone(v []float64) []float64 {
+	return append([]float64(nil), v...)
+}
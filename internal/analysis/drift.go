@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"math/rand"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+const (
+	// DriftSimilarity is the similarity to a fresh embedding of the same
+	// text below which a stored embedding is taken to come from another
+	// model: one model embeds a text to (nearly) the same vector each time
+	DriftSimilarity = 0.98
+
+	// driftSample is how many memories a drift check samples by default
+	driftSample = 50
+
+	// driftK is how many keyword and vector matches are compared per query
+	driftK = 5
+)
+
+// Drift reports how far the stored embeddings have drifted from the
+// current embedding model. Embeddings from another model live in another
+// space, so vector search against them is noise and recall quietly falls
+// back on keywords.
+type Drift struct {
+	Model         string `json:"model"`                   // the current embedding model
+	RecordedModel string `json:"recordedModel,omitempty"` // the model the daemon last embedded with
+	Embedded      int    `json:"embedded"`                // memories with an embedding
+	Sampled       int    `json:"sampled"`
+	Foreign       int    `json:"foreign"` // sampled embeddings from another model
+
+	// Dimensions counts sampled embeddings by length; more than one means
+	// spaces are mixed whatever their similarity
+	Dimensions map[int]int `json:"dimensions"`
+
+	// Agreement is the share of keyword matches for the sampled memories'
+	// summaries that vector search finds too, and NativeAgreement the same
+	// over the summaries of sampled memories whose embedding is current. A
+	// low agreement, or a gap between the two, is recall lost to drift.
+	Agreement       float64 `json:"agreement"`
+	NativeAgreement float64 `json:"nativeAgreement"`
+}
+
+// Drifted reports whether any sampled embedding came from another model
+func (d *Drift) Drifted() bool {
+	return d.Foreign > 0 || len(d.Dimensions) > 1
+}
+
+// ForeignShare estimates the share of embeddings from another model
+func (d *Drift) ForeignShare() float64 {
+	if d.Sampled == 0 {
+		return 0
+	}
+	return float64(d.Foreign) / float64(d.Sampled)
+}
+
+// CheckDrift samples up to sample embedded memories (0 for the default)
+// and re-embeds them with emb to find embeddings from other models, then
+// measures how well vector search agrees with keyword search on their
+// summaries
+func CheckDrift(s *store.Store, emb embedding.Embedder, sample int) (*Drift, error) {
+	if sample <= 0 {
+		sample = driftSample
+	}
+	d := &Drift{Dimensions: make(map[int]int)}
+	if m, ok := emb.(embedding.Modeler); ok {
+		d.Model = m.Model()
+	}
+	var err error
+	if d.RecordedModel, _, err = s.EmbeddingModel(); err != nil {
+		return nil, err
+	}
+
+	memories, err := s.EmbeddedMemories()
+	if err != nil {
+		return nil, err
+	}
+	d.Embedded = len(memories)
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(memories), func(i, j int) { memories[i], memories[j] = memories[j], memories[i] })
+	if len(memories) > sample {
+		memories = memories[:sample]
+	}
+	if len(memories) == 0 {
+		return d, nil
+	}
+
+	texts := make([]string, len(memories))
+	for i, m := range memories {
+		texts[i] = m.Content
+	}
+	fresh, err := emb.EmbedBatch(texts)
+	if err != nil {
+		return nil, err
+	}
+
+	var agreed, compared, nativeAgreed, nativeCompared int
+	for i, m := range memories {
+		if len(fresh[i]) == 0 {
+			// Nothing to compare against, such as with the null embedder
+			continue
+		}
+		d.Sampled++
+		d.Dimensions[len(m.Embedding)]++
+		native := embedding.CosineSimilarity(m.Embedding, fresh[i]) >= DriftSimilarity
+		if !native {
+			d.Foreign++
+		}
+
+		query := m.Summary
+		if query == "" {
+			query = m.Content
+		}
+		keyword, err := s.KeywordSearch(query, driftK)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyword) == 0 {
+			continue
+		}
+		queryEmb, err := emb.Embed(query)
+		if err != nil || len(queryEmb) == 0 {
+			continue
+		}
+		similar, err := s.SimilarMemories(queryEmb, nil, -1, driftK)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]bool, len(similar))
+		for _, sm := range similar {
+			found[sm.ID] = true
+		}
+		for _, id := range keyword {
+			compared++
+			if native {
+				nativeCompared++
+			}
+			if found[id] {
+				agreed++
+				if native {
+					nativeAgreed++
+				}
+			}
+		}
+	}
+	if compared > 0 {
+		d.Agreement = float64(agreed) / float64(compared)
+	}
+	if nativeCompared > 0 {
+		d.NativeAgreement = float64(nativeAgreed) / float64(nativeCompared)
+	}
+	return d, nil
+}
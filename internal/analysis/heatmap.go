@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+// HeatLevels is the number of intensity levels above zero, as in a
+// contribution graph
+const HeatLevels = 4
+
+// HeatDay is one cell of a project's heatmap
+type HeatDay struct {
+	Day      string `json:"day"`
+	Events   int    `json:"events"`
+	Memories int    `json:"memories"`
+	Level    int    `json:"level"` // 0 (nothing) to HeatLevels
+}
+
+// ProjectHeat is one project's row of the heatmap, with a cell for every
+// day in the range
+type ProjectHeat struct {
+	ProjectID string    `json:"projectId"`
+	Name      string    `json:"name"`
+	Events    int       `json:"events"`
+	Sampled   int       `json:"sampled"` // events left out of extraction as too noisy
+	Memories  int       `json:"memories"`
+	Days      []HeatDay `json:"days"`
+}
+
+// Heatmap shows which projects MemoryPilot has been capturing events and
+// learning memories from, day by day
+type Heatmap struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Projects []ProjectHeat `json:"projects"`
+}
+
+// BuildHeatmap lays daily activity out as a dense grid of the given number
+// of days ending today, busiest project first. Levels scale with events
+// plus memories relative to the busiest day of any project.
+func BuildHeatmap(activity []store.DayActivity, days int, now time.Time) *Heatmap {
+	if days < 1 {
+		days = 1
+	}
+	first := now.AddDate(0, 0, -(days - 1))
+	index := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		index[first.AddDate(0, 0, i).Format("2006-01-02")] = i
+	}
+
+	byProject := make(map[string]*ProjectHeat)
+	var order []*ProjectHeat
+	peak := 0
+	for _, a := range activity {
+		i, ok := index[a.Day]
+		if !ok {
+			continue
+		}
+		p := byProject[a.ProjectID]
+		if p == nil {
+			p = &ProjectHeat{ProjectID: a.ProjectID, Name: projectLabel(a), Days: make([]HeatDay, days)}
+			for d := range p.Days {
+				p.Days[d].Day = first.AddDate(0, 0, d).Format("2006-01-02")
+			}
+			byProject[a.ProjectID] = p
+			order = append(order, p)
+		}
+		p.Days[i].Events += a.Events
+		p.Days[i].Memories += a.Memories
+		p.Events += a.Events
+		p.Sampled += a.Sampled
+		p.Memories += a.Memories
+		if n := p.Days[i].Events + p.Days[i].Memories; n > peak {
+			peak = n
+		}
+	}
+
+	heatmap := &Heatmap{
+		From:     first.Format("2006-01-02"),
+		To:       now.Format("2006-01-02"),
+		Projects: []ProjectHeat{},
+	}
+	for _, p := range order {
+		for d := range p.Days {
+			p.Days[d].Level = heatLevel(p.Days[d].Events+p.Days[d].Memories, peak)
+		}
+		heatmap.Projects = append(heatmap.Projects, *p)
+	}
+	sort.SliceStable(heatmap.Projects, func(i, j int) bool {
+		a, b := heatmap.Projects[i], heatmap.Projects[j]
+		return a.Events+a.Memories > b.Events+b.Memories
+	})
+	return heatmap
+}
+
+// heatLevel buckets a day's count into 0..HeatLevels; any activity at all
+// is at least level 1
+func heatLevel(count, peak int) int {
+	if count == 0 || peak == 0 {
+		return 0
+	}
+	level := (count*HeatLevels + peak - 1) / peak
+	if level > HeatLevels {
+		level = HeatLevels
+	}
+	return level
+}
+
+func projectLabel(a store.DayActivity) string {
+	switch {
+	case a.ProjectName != "":
+		return a.ProjectName
+	case a.ProjectID != "":
+		return a.ProjectID
+	default:
+		return "(no project)"
+	}
+}
@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+// MinIgnoreEvents is how many file events a directory name needs, none of
+// them leading to a memory, before it is suggested for the ignore list
+const MinIgnoreEvents = 25
+
+// maxExampleDirs caps the directories listed per suggestion
+const maxExampleDirs = 3
+
+// IgnoreSuggestion is a directory name whose file events never yield
+// memories, worth adding to watchers.file.ignore
+type IgnoreSuggestion struct {
+	Name   string   `json:"name"`
+	Events int      `json:"events"`
+	Dirs   []string `json:"dirs"` // where it was seen, busiest first
+}
+
+// SuggestIgnores finds directory names inside projects that produced at
+// least minEvents file events without any of them leading to a memory.
+// The ignore list matches names at any depth, so a name qualifies only if
+// it is unproductive everywhere it appears, and only names below a project
+// root are considered, never the project or its parents. Of nested
+// candidates only the outermost is suggested, and directories already
+// under an ignored name are left out. Nothing is suggested before
+// extraction has produced memories at all, since then every directory
+// looks unproductive.
+func SuggestIgnores(yields []store.DirYield, projectRoots, ignored []string, minEvents int) []IgnoreSuggestion {
+	if minEvents <= 0 {
+		minEvents = MinIgnoreEvents
+	}
+	skip := make(map[string]bool, len(ignored))
+	for _, name := range ignored {
+		skip[name] = true
+	}
+
+	// The names of each directory below its project root, if it is in a
+	// project and not already ignored
+	relative := make(map[string][]string, len(yields))
+	for _, y := range yields {
+		root := projectRoot(y.Dir, projectRoots)
+		if root == "" {
+			continue
+		}
+		names := components(strings.TrimPrefix(y.Dir, root))
+		ignoredAlready := false
+		for _, name := range names {
+			ignoredAlready = ignoredAlready || skip[name]
+		}
+		if len(names) > 0 && !ignoredAlready {
+			relative[y.Dir] = names
+		}
+	}
+
+	type stats struct {
+		events, yielded int
+		dirs            map[string]int // path up to the name -> events
+	}
+	byName := make(map[string]*stats)
+	productive := false
+	for _, y := range yields {
+		if y.Yielded > 0 {
+			productive = true
+		}
+		names, ok := relative[y.Dir]
+		if !ok {
+			continue
+		}
+		seen := make(map[string]bool)
+		prefix := projectRoot(y.Dir, projectRoots)
+		for _, name := range names {
+			prefix = filepath.Join(prefix, name)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			st := byName[name]
+			if st == nil {
+				st = &stats{dirs: make(map[string]int)}
+				byName[name] = st
+			}
+			st.events += y.Events
+			st.yielded += y.Yielded
+			st.dirs[prefix] += y.Events
+		}
+	}
+	if !productive {
+		return nil
+	}
+
+	candidate := func(name string) bool {
+		st := byName[name]
+		return st.yielded == 0 && st.events >= minEvents
+	}
+
+	// Keep candidates that are the outermost candidate of some directory
+	outermost := make(map[string]bool)
+	for _, names := range relative {
+		for _, name := range names {
+			if candidate(name) {
+				outermost[name] = true
+				break
+			}
+		}
+	}
+
+	var suggestions []IgnoreSuggestion
+	for name := range outermost {
+		st := byName[name]
+		dirs := make([]string, 0, len(st.dirs))
+		for dir := range st.dirs {
+			dirs = append(dirs, dir)
+		}
+		sort.Slice(dirs, func(i, j int) bool {
+			if st.dirs[dirs[i]] != st.dirs[dirs[j]] {
+				return st.dirs[dirs[i]] > st.dirs[dirs[j]]
+			}
+			return dirs[i] < dirs[j]
+		})
+		if len(dirs) > maxExampleDirs {
+			dirs = dirs[:maxExampleDirs]
+		}
+		suggestions = append(suggestions, IgnoreSuggestion{Name: name, Events: st.events, Dirs: dirs})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Events != suggestions[j].Events {
+			return suggestions[i].Events > suggestions[j].Events
+		}
+		return suggestions[i].Name < suggestions[j].Name
+	})
+	return suggestions
+}
+
+// projectRoot returns the deepest root containing dir, or ""
+func projectRoot(dir string, roots []string) string {
+	best := ""
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if (dir == root || strings.HasPrefix(dir, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	return best
+}
+
+// components splits a directory path into its names
+func components(dir string) []string {
+	var names []string
+	for _, name := range strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/") {
+		if name != "" && name != "." {
+			names = append(names, name)
+		}
+	}
+	return names
+}
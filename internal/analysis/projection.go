@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"math"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// powerIterations bounds the search for each principal component
+const powerIterations = 100
+
+// Point is a memory placed in 2D for a scatter plot
+type Point struct {
+	ID        string             `json:"id"`
+	X         float64            `json:"x"`
+	Y         float64            `json:"y"`
+	Cluster   int                `json:"cluster"`
+	Type      models.MemoryType  `json:"type"`
+	Scope     models.MemoryScope `json:"scope"`
+	Summary   string             `json:"summary"`
+	Topics    []string           `json:"topics"`
+	ProjectID string             `json:"projectId,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// Project2D projects memory embeddings onto their first two principal
+// components, scaled to [-1, 1], and tags each point with its cluster
+// (the index into ClusterMemories' result) so plots can color by it
+func Project2D(memories []store.EmbeddedMemory) []Point {
+	vectors, members := normalizedVectors(memories)
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	// Center the data so the components describe variance, not the mean
+	mean := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		for i, x := range v {
+			mean[i] += x / float64(len(vectors))
+		}
+	}
+	centered := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		centered[i] = make([]float64, len(v))
+		for j, x := range v {
+			centered[i][j] = x - mean[j]
+		}
+	}
+
+	pc1 := principalComponent(centered, nil)
+	pc2 := principalComponent(centered, pc1)
+
+	clusterOf := make(map[string]int)
+	for i, c := range ClusterMemories(memories, 0) {
+		for _, m := range c.Members {
+			clusterOf[m.ID] = i
+		}
+	}
+
+	points := make([]Point, len(centered))
+	var maxAbs float64
+	for i, v := range centered {
+		m := members[i]
+		p := Point{
+			ID:        m.ID,
+			X:         dot(v, pc1),
+			Y:         dot(v, pc2),
+			Cluster:   clusterOf[m.ID],
+			Type:      m.Type,
+			Scope:     m.Scope,
+			Summary:   m.Summary,
+			Topics:    m.Topics,
+			CreatedAt: m.CreatedAt,
+		}
+		if m.ProjectID != nil {
+			p.ProjectID = *m.ProjectID
+		}
+		maxAbs = math.Max(maxAbs, math.Max(math.Abs(p.X), math.Abs(p.Y)))
+		points[i] = p
+	}
+
+	if maxAbs > 0 {
+		for i := range points {
+			points[i].X /= maxAbs
+			points[i].Y /= maxAbs
+		}
+	}
+	return points
+}
+
+// principalComponent finds the direction of greatest variance by power
+// iteration on XᵀX, orthogonal to exclude when it is set. The covariance
+// matrix is never built, so this stays cheap for wide embeddings.
+func principalComponent(x [][]float64, exclude []float64) []float64 {
+	dim := len(x[0])
+	v := make([]float64, dim)
+	for i := range v {
+		// Deterministic, and unlikely to be orthogonal to the answer
+		v[i] = 1 / float64(i+1)
+	}
+	orthogonalize(v, exclude)
+	if !normalize(v) {
+		return v
+	}
+
+	projected := make([]float64, len(x))
+	for iter := 0; iter < powerIterations; iter++ {
+		for i, row := range x {
+			projected[i] = dot(row, v)
+		}
+		next := make([]float64, dim)
+		for i, row := range x {
+			for j, val := range row {
+				next[j] += val * projected[i]
+			}
+		}
+		orthogonalize(next, exclude)
+		if !normalize(next) {
+			return v // no variance left in this direction
+		}
+
+		converged := math.Abs(dot(next, v)) > 1-1e-9
+		v = next
+		if converged {
+			break
+		}
+	}
+	return v
+}
+
+// orthogonalize removes the component of v along the unit vector u
+func orthogonalize(v, u []float64) {
+	if u == nil {
+		return
+	}
+	d := dot(v, u)
+	for i := range v {
+		v[i] -= d * u[i]
+	}
+}
@@ -0,0 +1,587 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/insights"
+	"github.com/memorypilot/memorypilot/internal/recall"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	defaultRecallLimit = 5
+	defaultEventLimit  = 100
+)
+
+// memoryInput is the body of POST and PATCH /v1/memories. Fields left out
+// of a PATCH keep their value.
+type memoryInput struct {
+	Type      *models.MemoryType  `json:"type"`
+	Content   *string             `json:"content"`
+	Summary   *string             `json:"summary"`
+	Scope     *models.MemoryScope `json:"scope"`
+	ProjectID *string             `json:"projectId"`
+	Topics    *[]string           `json:"topics"`
+	ExpiresAt *time.Time          `json:"expiresAt"`
+
+	// Author can only be given when creating a memory
+	Author     *string `json:"author"`
+	Maintainer *string `json:"maintainer"`
+
+	// Version is the version a PATCH is based on, unless If-Match gives it
+	Version *int `json:"version"`
+}
+
+// apply copies the given fields onto m, validating them
+func (in memoryInput) apply(m *models.Memory) error {
+	if in.Type != nil {
+		if !in.Type.Valid() {
+			return fmt.Errorf("unknown memory type %q", *in.Type)
+		}
+		m.Type = *in.Type
+	}
+	if in.Scope != nil {
+		if !validScope(*in.Scope) {
+			return fmt.Errorf("unknown scope %q", *in.Scope)
+		}
+		m.Scope = *in.Scope
+	}
+	if in.Content != nil {
+		if strings.TrimSpace(*in.Content) == "" {
+			return errors.New("content must not be empty")
+		}
+		m.Content = *in.Content
+	}
+	if in.Summary != nil {
+		m.Summary = *in.Summary
+	}
+	if in.ProjectID != nil {
+		if *in.ProjectID == "" {
+			m.ProjectID = nil
+		} else {
+			m.ProjectID = in.ProjectID
+		}
+	}
+	if in.Topics != nil {
+		m.Topics = *in.Topics
+	}
+	if in.Author != nil {
+		if m.Author != "" && *in.Author != m.Author {
+			return errors.New("the author of a memory can't be changed; set its maintainer instead")
+		}
+		m.Author = *in.Author
+	}
+	if in.Maintainer != nil {
+		m.Maintainer = *in.Maintainer
+	}
+	if in.ExpiresAt != nil {
+		if in.ExpiresAt.IsZero() {
+			m.ExpiresAt = nil
+		} else {
+			m.ExpiresAt = in.ExpiresAt
+		}
+	}
+	return nil
+}
+
+func validScope(s models.MemoryScope) bool {
+	switch s {
+	case models.MemoryScopePersonal, models.MemoryScopeProject, models.MemoryScopeTeam, models.MemoryScopeOrg:
+		return true
+	}
+	return false
+}
+
+// embed returns the embedding of a memory's content, or nil when no
+// embedder is configured or it is unavailable
+func (srv *Server) embed(text string) []float32 {
+	if srv.opts.Embedder == nil {
+		return nil
+	}
+	emb, err := srv.opts.Embedder.Embed(text)
+	if err != nil {
+		log.Printf("api: embedding unavailable: %v", err)
+		return nil
+	}
+	return emb
+}
+
+// listMemories returns approved memories, newest first, filtered by the
+// scope, type, project and since parameters
+func (srv *Server) listMemories(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var req models.RecallRequest
+	for _, sc := range splitList(q["scope"]) {
+		req.Scope = append(req.Scope, models.MemoryScope(sc))
+	}
+	for _, t := range splitList(q["type"]) {
+		req.Types = append(req.Types, models.MemoryType(t))
+	}
+	if project := q.Get("project"); project != "" {
+		req.ProjectID = &project
+	}
+
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "since must be an RFC 3339 time")
+			return
+		}
+		since = t
+	}
+	limit, ok := intParam(w, r, "limit", 0)
+	if !ok {
+		return
+	}
+
+	memories, err := srv.store.ListMemories(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	kept := []models.Memory{}
+	for _, m := range memories {
+		if limit > 0 && len(kept) == limit {
+			break
+		}
+		if m.CreatedAt.Before(since) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"memories": kept})
+}
+
+func (srv *Server) createMemory(w http.ResponseWriter, r *http.Request) {
+	var in memoryInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+	if in.Content == nil {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	// A retry carrying the same Idempotency-Key gets the memory the first
+	// attempt created, without touching the store again
+	key := r.Header.Get("Idempotency-Key")
+	var requestHash string
+	if key != "" {
+		requestHash = store.RequestHash(in)
+		id, err := srv.store.IdempotentMemory(key, requestHash)
+		if err != nil {
+			if errors.Is(err, store.ErrIdempotencyMismatch) {
+				writeError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if id != "" {
+			m, err := srv.store.GetMemory(id)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			// A memory deleted since is created again
+			if m != nil {
+				m.Embedding = nil
+				w.Header().Set("Idempotent-Replayed", "true")
+				writeJSON(w, http.StatusOK, m)
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	m := models.Memory{
+		ID:    ulid.Make().String(),
+		Type:  models.MemoryTypeFact,
+		Scope: models.MemoryScopePersonal,
+		Source: models.Source{
+			Type:      models.SourceTypeManual,
+			Reference: "api",
+			Timestamp: now,
+		},
+		Confidence:     1.0,
+		Importance:     1.0,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+	}
+	if err := in.apply(&m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if m.Summary == "" {
+		m.Summary = models.Summarize(m.Content)
+	}
+	identity.Attribute(&m)
+	m.Embedding = srv.embed(m.Content)
+
+	err := srv.store.CreateMemory(&m)
+	if err != nil && !errors.Is(err, store.ErrDuplicate) {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// A duplicate is keyed too: m.ID now names the memory it repeats
+	if key != "" {
+		if err := srv.store.RecordIdempotencyKey(key, requestHash, m.ID); err != nil {
+			log.Printf("Failed to record idempotency key: %v", err)
+		}
+	}
+	if err != nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "duplicate memory", "id": m.ID})
+		return
+	}
+	m.Embedding = nil
+	w.Header().Set("ETag", etag(m.Version))
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// lookupMemory loads the memory named in the path, writing a 404 if there
+// is none
+func (srv *Server) lookupMemory(w http.ResponseWriter, r *http.Request) (*models.Memory, bool) {
+	m, err := srv.store.GetMemory(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if m == nil {
+		writeError(w, http.StatusNotFound, "memory not found")
+		return nil, false
+	}
+	return m, true
+}
+
+func (srv *Server) getMemory(w http.ResponseWriter, r *http.Request) {
+	if m, ok := srv.lookupMemory(w, r); ok {
+		w.Header().Set("ETag", etag(m.Version))
+		writeJSON(w, http.StatusOK, m)
+	}
+}
+
+// updateMemory requires the version the edit is based on, from If-Match or
+// the body, so two clients editing the same memory can't silently
+// overwrite each other
+func (srv *Server) updateMemory(w http.ResponseWriter, r *http.Request) {
+	m, ok := srv.lookupMemory(w, r)
+	if !ok {
+		return
+	}
+	var in memoryInput
+	if !readJSON(w, r, &in) {
+		return
+	}
+	version, ok := expectedVersion(r, in)
+	if !ok {
+		writeError(w, http.StatusPreconditionRequired, "the memory's version is required: send If-Match with its ETag, or a version field")
+		return
+	}
+	if version != m.Version {
+		writeConflict(w, m.Version)
+		return
+	}
+
+	content := m.Content
+	if err := in.apply(m); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if in.Content != nil && in.Summary == nil {
+		m.Summary = models.Summarize(m.Content)
+	}
+
+	// Edited content is re-embedded; otherwise the stored embedding stays
+	if m.Content != content {
+		m.Embedding = srv.embed(m.Content)
+	} else {
+		embeddings, err := srv.store.MemoryEmbeddings([]string{m.ID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		m.Embedding = embeddings[m.ID]
+	}
+
+	if err := srv.store.UpdateMemory(m); err != nil {
+		var conflict *store.ConflictError
+		if errors.As(err, &conflict) {
+			writeConflict(w, conflict.Current)
+			return
+		}
+		if errors.Is(err, store.ErrDuplicate) {
+			writeError(w, http.StatusConflict, "an identical memory already exists")
+			return
+		}
+		if errors.Is(err, store.ErrHeld) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	m.Embedding = nil
+	w.Header().Set("ETag", etag(m.Version))
+	writeJSON(w, http.StatusOK, m)
+}
+
+// expectedVersion reads the version an update is based on from If-Match,
+// falling back to the body
+func expectedVersion(r *http.Request, in memoryInput) (int, bool) {
+	if match := r.Header.Get("If-Match"); match != "" {
+		v, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(match, "W/"), `"`))
+		return v, err == nil
+	}
+	if in.Version != nil {
+		return *in.Version, true
+	}
+	return 0, false
+}
+
+// etag renders a memory version as an entity tag
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// writeConflict reports an update based on an outdated version
+func writeConflict(w http.ResponseWriter, current int) {
+	w.Header().Set("ETag", etag(current))
+	writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error":   "memory was changed since that version; fetch it and reapply the edit",
+		"version": current,
+	})
+}
+
+func (srv *Server) deleteMemory(w http.ResponseWriter, r *http.Request) {
+	m, ok := srv.lookupMemory(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.store.DeleteMemory(m.ID); err != nil {
+		if errors.Is(err, store.ErrHeld) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recall searches memories, using hybrid search when the query can be
+// embedded and keyword search otherwise
+func (srv *Server) recall(w http.ResponseWriter, r *http.Request) {
+	var req models.RecallRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultRecallLimit
+	}
+
+	memories, err := srv.search(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if memories == nil {
+		memories = []models.Memory{}
+	}
+	writeJSON(w, http.StatusOK, models.RecallResponse{Memories: memories, Total: len(memories), Query: req.Query})
+}
+
+// recallBatch answers several queries within one token budget
+func (srv *Server) recallBatch(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchRecallRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	resp, err := recall.Batch(req, srv.search)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, recall.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// search runs a recall query, semantically when the query can be embedded
+func (srv *Server) search(req models.RecallRequest) ([]models.Memory, error) {
+	text, _ := models.ParseQuery(req.Query)
+	if emb := srv.embedQuery(text); len(emb) > 0 {
+		return srv.store.HybridSearch(req, emb)
+	}
+	return srv.store.Recall(req)
+}
+
+func (srv *Server) embedQuery(text string) []float32 {
+	if text == "" {
+		return nil
+	}
+	return srv.embed(text)
+}
+
+func (srv *Server) stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := srv.store.GetStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// insights serves the latest anonymized insights report; it never exposes
+// memories, so org members can read it without seeing individuals' work
+func (srv *Server) insights(w http.ResponseWriter, r *http.Request) {
+	report, err := insights.Latest(srv.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if report == nil {
+		writeError(w, http.StatusNotFound, "no insights report has been generated")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (srv *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := srv.store.ListProjects()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if projects == nil {
+		projects = []models.Project{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"projects": projects})
+}
+
+// getProject returns a project, looked up by ID or name, with its facts
+func (srv *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	projects, err := srv.store.ListProjects()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	id := r.PathValue("id")
+	for _, p := range projects {
+		if p.ID != id && p.Name != id {
+			continue
+		}
+		facts, err := srv.store.GetProjectFacts(p.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if facts == nil {
+			facts = []store.ProjectFact{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"project": p, "facts": facts})
+		return
+	}
+	writeError(w, http.StatusNotFound, "project not found")
+}
+
+// listEvents returns captured events in [since, until), oldest first,
+// keeping the most recent limit of them. since defaults to a day ago.
+func (srv *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	until := time.Now()
+	since := until.Add(-24 * time.Hour)
+	for name, dst := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, name+" must be an RFC 3339 time")
+				return
+			}
+			*dst = t
+		}
+	}
+	limit, ok := intParam(w, r, "limit", defaultEventLimit)
+	if !ok {
+		return
+	}
+	types := splitList(q["type"])
+	project := q.Get("project")
+
+	events, err := srv.store.GetEventsBetween(since, until)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	kept := []models.Event{}
+	for _, e := range events {
+		if len(types) > 0 && !slices.Contains(types, e.Type) {
+			continue
+		}
+		if project != "" && (e.ProjectID == nil || *e.ProjectID != project) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"events": kept})
+}
+
+// createEvent captures an event from another tool
+func (srv *Server) createEvent(w http.ResponseWriter, r *http.Request) {
+	var e models.Event
+	if !readJSON(w, r, &e) {
+		return
+	}
+	if e.Type == "" {
+		writeError(w, http.StatusBadRequest, "type is required")
+		return
+	}
+	if e.ID == "" {
+		e.ID = ulid.Make().String()
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+
+	if srv.opts.Capture == nil || !srv.opts.Capture(e) {
+		// No pipeline to take it: store it for the daemon to catch up on
+		if _, err := srv.store.IngestEvent(&e); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := srv.store.DeferEvents([]models.Event{e}); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": e.ID})
+}
+
+// intParam reads a non-negative integer query parameter
+func intParam(w http.ResponseWriter, r *http.Request, name string, def int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, name+" must be a non-negative integer")
+		return 0, false
+	}
+	return n, true
+}
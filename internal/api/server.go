@@ -0,0 +1,167 @@
+// Package api serves the memory store over HTTP: memory CRUD, recall,
+// stats, insights, projects and events. Routes live under /v1, the same paths
+// teamsync.Client uses, so a machine serving its store can act as a team
+// server.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultAddr is where the API listens unless configured otherwise
+const DefaultAddr = "127.0.0.1:7832"
+
+// maxBody caps request bodies
+const maxBody = 1 << 20
+
+// Options configure the server
+type Options struct {
+	// Addr is the host:port to listen on
+	Addr string
+
+	// Token, if set, must be sent as "Authorization: Bearer <token>" on
+	// every /v1 route
+	Token string
+
+	// Embedder embeds recall queries and new or edited memories; nil means
+	// keyword recall only
+	Embedder embedding.Embedder
+
+	// Capture hands posted events to a running pipeline, reporting whether
+	// it accepted them. Without it events are stored and deferred, so the
+	// daemon extracts them when it catches up.
+	Capture func(models.Event) bool
+}
+
+// Server is the HTTP API
+type Server struct {
+	store *store.Store
+	opts  Options
+	http  *http.Server
+}
+
+// New creates a server for a store
+func New(s *store.Store, opts Options) *Server {
+	if opts.Addr == "" {
+		opts.Addr = DefaultAddr
+	}
+	srv := &Server{store: s, opts: opts}
+	srv.http = &http.Server{
+		Addr:              opts.Addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv
+}
+
+// Addr returns the address the server listens on
+func (srv *Server) Addr() string {
+	return srv.opts.Addr
+}
+
+// Handler returns the API's routes
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	v1 := http.NewServeMux()
+	v1.HandleFunc("GET /v1/memories", srv.listMemories)
+	v1.HandleFunc("POST /v1/memories", srv.createMemory)
+	v1.HandleFunc("GET /v1/memories/{id}", srv.getMemory)
+	v1.HandleFunc("PATCH /v1/memories/{id}", srv.updateMemory)
+	v1.HandleFunc("DELETE /v1/memories/{id}", srv.deleteMemory)
+	v1.HandleFunc("POST /v1/recall", srv.recall)
+	v1.HandleFunc("POST /v1/recall/batch", srv.recallBatch)
+	v1.HandleFunc("GET /v1/stats", srv.stats)
+	v1.HandleFunc("GET /v1/insights", srv.insights)
+	v1.HandleFunc("GET /v1/projects", srv.listProjects)
+	v1.HandleFunc("GET /v1/projects/{id}", srv.getProject)
+	v1.HandleFunc("GET /v1/events", srv.listEvents)
+	v1.HandleFunc("POST /v1/events", srv.createEvent)
+	mux.Handle("/v1/", srv.authenticate(v1))
+
+	return mux
+}
+
+// ListenAndServe serves until Shutdown is called
+func (srv *Server) ListenAndServe() error {
+	err := srv.http.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops the server, waiting for requests in flight
+func (srv *Server) Shutdown(ctx context.Context) error {
+	return srv.http.Shutdown(ctx)
+}
+
+// authenticate rejects requests without the bearer token, if one is set
+func (srv *Server) authenticate(next http.Handler) http.Handler {
+	if srv.opts.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + srv.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="memorypilot"`)
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// readJSON decodes a request body, rejecting unknown fields so typos in
+// field names aren't silently ignored
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma-separated query parameter, which may also be
+// repeated
+func splitList(values []string) []string {
+	var items []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
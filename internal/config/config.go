@@ -0,0 +1,180 @@
+// Package config reads ~/.memorypilot/config.yaml.
+//
+// Only the subset of YAML the generated config uses is supported: nested
+// maps, scalars, block lists ("- item") and inline lists ("[a, b]").
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// File is a parsed config file, addressed by dotted key paths such as
+// "watchers.git.interval"
+type File struct {
+	values map[string]string
+	lists  map[string][]string
+}
+
+// Load reads and parses a config file. A missing file yields an empty File,
+// so callers fall back to defaults.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Parse(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse parses config file contents
+func Parse(data []byte) (*File, error) {
+	f := &File{
+		values: make(map[string]string),
+		lists:  make(map[string][]string),
+	}
+
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+	path := func(key string) string {
+		parts := make([]string, 0, len(stack)+1)
+		for _, l := range stack {
+			parts = append(parts, l.key)
+		}
+		if key != "" {
+			parts = append(parts, key)
+		}
+		return strings.Join(parts, ".")
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := stripComment(scanner.Text())
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", lineNo)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok || trimmed == "-" {
+			// List items belong to the innermost open key
+			for len(stack) > 0 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: list item outside of a key", lineNo)
+			}
+			key := path("")
+			f.lists[key] = append(f.lists[key], unquote(strings.TrimSpace(item)))
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case value == "":
+			stack = append(stack, level{indent: indent, key: key})
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			items := []string{}
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, unquote(item))
+				}
+			}
+			f.lists[path(key)] = items
+		default:
+			f.values[path(key)] = unquote(value)
+		}
+	}
+	return f, scanner.Err()
+}
+
+// String returns the scalar at key
+func (f *File) String(key string) (string, bool) {
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// List returns the list at key
+func (f *File) List(key string) ([]string, bool) {
+	v, ok := f.lists[key]
+	return v, ok
+}
+
+// Children returns the names of the keys directly under prefix, sorted,
+// e.g. the policy names under "retention.policies"
+func (f *File) Children(prefix string) []string {
+	seen := map[string]bool{}
+	add := func(key string) {
+		if rest, ok := strings.CutPrefix(key, prefix+"."); ok {
+			name, _, _ := strings.Cut(rest, ".")
+			seen[name] = true
+		}
+	}
+	for key := range f.values {
+		add(key)
+	}
+	for key := range f.lists {
+		add(key)
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// stripComment removes a trailing "# comment" outside of quotes
+func stripComment(line string) string {
+	inSingle, inDouble := false, false
+	for i, r := range line {
+		switch r {
+		case '\'':
+			if !inDouble {
+				inSingle = !inSingle
+			}
+		case '"':
+			if !inSingle {
+				inDouble = !inDouble
+			}
+		case '#':
+			if !inSingle && !inDouble && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
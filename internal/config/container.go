@@ -0,0 +1,32 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Container identifies the containerized dev environment MemoryPilot runs
+// in: "codespaces", "devcontainer", "podman" or "docker", or "" on a
+// regular machine. Inside one, the host's memory store is only visible if
+// it was mounted in.
+func Container() string {
+	switch {
+	case os.Getenv("CODESPACES") == "true":
+		return "codespaces"
+	case os.Getenv("REMOTE_CONTAINERS") == "true" || os.Getenv("DEVCONTAINER") != "":
+		return "devcontainer"
+	}
+	if _, err := os.Stat("/run/.containerenv"); err == nil {
+		return "podman"
+	}
+	if _, err := os.Stat("/.dockerenv"); err == nil {
+		return "docker"
+	}
+	if data, err := os.ReadFile("/proc/1/cgroup"); err == nil {
+		s := string(data)
+		if strings.Contains(s, "/docker/") || strings.Contains(s, "/kubepods") {
+			return "docker"
+		}
+	}
+	return ""
+}
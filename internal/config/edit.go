@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// AppendList adds items to the list at a dotted key in the config file at
+// path, leaving the rest of the file, comments included, as it is. Items
+// already listed are skipped; a missing key is appended to the end of the
+// file. It returns the items added.
+func AppendList(path, key string, items []string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	existing, _ := f.List(key)
+	listed := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		listed[item] = true
+	}
+	var added []string
+	for _, item := range items {
+		if !listed[item] {
+			listed[item] = true
+			added = append(added, item)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	lines = appendItems(lines, key, existing, added)
+	return added, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// appendItems inserts items into the list at key, following the same
+// indentation rules as Parse
+func appendItems(lines []string, key string, existing, items []string) []string {
+	i, indent, name, value := findKey(lines, key)
+	if i < 0 {
+		// Missing key: add it at the end. Parse merges repeated parent
+		// keys by path, so this works whether or not the parents exist.
+		lines = appendKey(lines, key, "")
+		for _, item := range items {
+			lines = append(lines, strings.Repeat("  ", len(strings.Split(key, ".")))+"- "+item)
+		}
+		return lines
+	}
+	line := lines[i]
+
+	// Inline list: rewrite it whole
+	if strings.HasPrefix(value, "[") {
+		all := append(append([]string{}, existing...), items...)
+		lines[i] = withComment(strings.Repeat(" ", indent)+name+": ["+strings.Join(all, ", ")+"]", line)
+		return lines
+	}
+
+	// Block list: add after its last item
+	itemIndent := indent + 2
+	last := i
+	for j := i + 1; j < len(lines); j++ {
+		t := strings.TrimSpace(stripComment(lines[j]))
+		if t == "" {
+			continue
+		}
+		in := len(lines[j]) - len(strings.TrimLeft(lines[j], " "))
+		if !strings.HasPrefix(t, "- ") && t != "-" || in < indent {
+			break
+		}
+		if last == i {
+			itemIndent = in
+		}
+		last = j
+	}
+	var added []string
+	for _, item := range items {
+		added = append(added, strings.Repeat(" ", itemIndent)+"- "+item)
+	}
+	out := append(append([]string{}, lines[:last+1]...), added...)
+	return append(out, lines[last+1:]...)
+}
+
+// SetString sets the scalar at a dotted key in the config file at path,
+// leaving the rest of the file, comments included, as it is. A missing key
+// is added under its closest existing parent, or to the end of the file.
+func SetString(path, key, value string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, err := Parse(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	if i, indent, name, _ := findKey(lines, key); i >= 0 {
+		lines[i] = withComment(strings.Repeat(" ", indent)+name+": "+value, lines[i])
+	} else {
+		lines = insertKey(lines, key, value)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// findKey returns the line a dotted key is set on, with its indentation,
+// name and value, following the same indentation rules as Parse; the line
+// is -1 if the key is missing
+func findKey(lines []string, key string) (int, int, string, string) {
+	type level struct {
+		indent int
+		key    string
+	}
+	var stack []level
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" || strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+
+		path := make([]string, 0, len(stack)+1)
+		for _, l := range stack {
+			path = append(path, l.key)
+		}
+		path = append(path, name)
+		if strings.Join(path, ".") == key {
+			return i, indent, name, value
+		}
+		if value == "" {
+			stack = append(stack, level{indent: indent, key: name})
+		}
+	}
+	return -1, 0, "", ""
+}
+
+// appendKey adds a dotted key with its parents to the end of lines
+func appendKey(lines []string, key, value string) []string {
+	if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+		lines = append(lines, "")
+	}
+	parts := strings.Split(key, ".")
+	for depth, part := range parts {
+		lines = append(lines, strings.Repeat("  ", depth)+part+":")
+	}
+	if value != "" {
+		lines[len(lines)-1] += " " + value
+	}
+	return lines
+}
+
+// insertKey adds a missing dotted key after the last line of its closest
+// existing parent's block, or with all its parents to the end of lines
+func insertKey(lines []string, key, value string) []string {
+	parts := strings.Split(key, ".")
+	for n := len(parts) - 1; n > 0; n-- {
+		i, indent, _, parentValue := findKey(lines, strings.Join(parts[:n], "."))
+		if i < 0 {
+			continue
+		}
+		if parentValue != "" {
+			break // a scalar or inline value can't take keys
+		}
+		childIndent := indent + 2
+		last := i
+		for j := i + 1; j < len(lines); j++ {
+			t := strings.TrimSpace(stripComment(lines[j]))
+			if t == "" {
+				continue
+			}
+			in := len(lines[j]) - len(strings.TrimLeft(lines[j], " "))
+			if in <= indent {
+				break
+			}
+			if last == i {
+				childIndent = in
+			}
+			last = j
+		}
+		var added []string
+		for depth, part := range parts[n:] {
+			added = append(added, strings.Repeat(" ", childIndent+2*depth)+part+":")
+		}
+		added[len(added)-1] += " " + value
+		out := append(append([]string{}, lines[:last+1]...), added...)
+		return append(out, lines[last+1:]...)
+	}
+	return appendKey(lines, key, value)
+}
+
+// withComment keeps the trailing comment of the line it replaces
+func withComment(line, replaced string) string {
+	if comment := strings.TrimSpace(strings.TrimPrefix(replaced, stripComment(replaced))); comment != "" {
+		return line + " " + comment
+	}
+	return line
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// LocalOnly reports whether privacy.localOnly is set, with
+// MEMORYPILOT_LOCAL_ONLY overriding the file. An unparsable value is an
+// error rather than false, so a typo never silently turns the guarantee off.
+func (f *File) LocalOnly() (bool, error) {
+	if v := os.Getenv("MEMORYPILOT_LOCAL_ONLY"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, fmt.Errorf("invalid MEMORYPILOT_LOCAL_ONLY: %w", err)
+		}
+		return b, nil
+	}
+	v, ok := f.String("privacy.localOnly")
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("privacy.localOnly: %q is not true or false", v)
+	}
+	return b, nil
+}
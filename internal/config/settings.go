@@ -0,0 +1,271 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings are everything the daemon reads from config.yaml, with
+// environment variables overriding the file where noted
+type Settings struct {
+	Tuning
+
+	Providers          []string // extraction.providers (MEMORYPILOT_PROVIDERS)
+	Model              string   // extraction.model (MEMORYPILOT_MODEL)
+	ClaudeAPIKey       string   // extraction.apiKey (ANTHROPIC_API_KEY)
+	ClaudeDailyBudget  int      // extraction.claudeDailyBudget (MEMORYPILOT_CLAUDE_DAILY_BUDGET)
+	Offline            bool     // extraction.offline (MEMORYPILOT_OFFLINE)
+	EmbeddingProviders []string // embedding.providers (MEMORYPILOT_EMBEDDING_PROVIDERS)
+	EmbeddingModel     string   // embedding.model: Ollama's (MEMORYPILOT_EMBEDDING_MODEL)
+
+	GitEnabled     bool          // watchers.git.enabled
+	GitAuthors     []string      // watchers.git.authors
+	GitTeamCapture bool          // watchers.git.teamCapture
+	GitStashes     bool          // watchers.git.stashes
+	FileEnabled    bool          // watchers.file.enabled
+	FileDebounce   time.Duration // watchers.file.debounce
+	FileIgnore     []string      // watchers.file.ignore, added to the built-in list
+	TermEnabled    bool          // watchers.terminal.enabled
+	HistoryFiles   []string      // watchers.terminal.historyFiles
+	ScanBudget     int           // watchers.scanBudget: directories read per second during discovery
+
+	APIEnabled bool   // api.enabled (MEMORYPILOT_API_ENABLED)
+	APIHost    string // api.host (MEMORYPILOT_API_HOST)
+	APIPort    int    // api.port (MEMORYPILOT_API_PORT)
+	APIToken   string // api.token (MEMORYPILOT_API_TOKEN)
+
+	InsightsEnabled         bool    // insights.enabled
+	InsightsWindowDays      int     // insights.windowDays
+	InsightsMinContributors int     // insights.minContributors
+	InsightsEpsilon         float64 // insights.epsilon
+
+	ExpiryAction string // expiry.action: archive or delete expired memories
+}
+
+// What the daemon does with expired memories
+const (
+	ExpiryArchive = "archive" // keep them, out of recall
+	ExpiryDelete  = "delete"
+)
+
+// DefaultSettings returns the built-in settings
+func DefaultSettings() Settings {
+	return Settings{
+		Tuning:             DefaultTuning(),
+		Providers:          []string{"ollama", "rules"},
+		Model:              "llama3.2",
+		EmbeddingProviders: []string{"ollama"},
+		EmbeddingModel:     "nomic-embed-text",
+		GitEnabled:         true,
+		FileEnabled:        true,
+		FileDebounce:       500 * time.Millisecond,
+		TermEnabled:        true,
+		HistoryFiles:       []string{"~/.zsh_history", "~/.bash_history"},
+		ScanBudget:         200,
+		APIEnabled:         true,
+		APIHost:            "127.0.0.1",
+		APIPort:            7832,
+
+		InsightsWindowDays:      30,
+		InsightsMinContributors: 3,
+		InsightsEpsilon:         1.0,
+
+		ExpiryAction: ExpiryArchive,
+	}
+}
+
+// APIAddr is the address the API listens on, or "" if it is disabled
+func (s Settings) APIAddr() string {
+	if !s.APIEnabled {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", s.APIHost, s.APIPort)
+}
+
+// Settings returns the file's settings with environment overrides applied
+// and defaults for unset keys
+func (f *File) Settings() (Settings, error) {
+	s := DefaultSettings()
+
+	tuning, err := f.Tuning()
+	if err != nil {
+		return s, err
+	}
+	s.Tuning = tuning
+
+	strs := map[string]*string{
+		"extraction.model":  &s.Model,
+		"extraction.apiKey": &s.ClaudeAPIKey,
+		"embedding.model":   &s.EmbeddingModel,
+		"api.host":          &s.APIHost,
+		"api.token":         &s.APIToken,
+		"expiry.action":     &s.ExpiryAction,
+	}
+	for key, dst := range strs {
+		if v, ok := f.String(key); ok {
+			*dst = v
+		}
+	}
+
+	lists := map[string]*[]string{
+		"extraction.providers":           &s.Providers,
+		"embedding.providers":            &s.EmbeddingProviders,
+		"watchers.git.authors":           &s.GitAuthors,
+		"watchers.file.ignore":           &s.FileIgnore,
+		"watchers.terminal.historyFiles": &s.HistoryFiles,
+	}
+	for key, dst := range lists {
+		if v, ok := f.List(key); ok {
+			*dst = v
+		}
+	}
+
+	bools := map[string]*bool{
+		"extraction.offline":        &s.Offline,
+		"watchers.git.enabled":      &s.GitEnabled,
+		"watchers.git.teamCapture":  &s.GitTeamCapture,
+		"watchers.git.stashes":      &s.GitStashes,
+		"watchers.file.enabled":     &s.FileEnabled,
+		"watchers.terminal.enabled": &s.TermEnabled,
+		"api.enabled":               &s.APIEnabled,
+	}
+	for key, dst := range bools {
+		if v, ok := f.String(key); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return s, fmt.Errorf("%s: %q is not true or false", key, v)
+			}
+			*dst = b
+		}
+	}
+
+	ints := map[string]*int{
+		"extraction.claudeDailyBudget": &s.ClaudeDailyBudget,
+		"api.port":                     &s.APIPort,
+		"watchers.scanBudget":          &s.ScanBudget,
+		"insights.windowDays":          &s.InsightsWindowDays,
+		"insights.minContributors":     &s.InsightsMinContributors,
+	}
+	for key, dst := range ints {
+		if v, ok := f.String(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return s, fmt.Errorf("%s: %q is not an integer", key, v)
+			}
+			*dst = n
+		}
+	}
+
+	if v, ok := f.String("insights.epsilon"); ok {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return s, fmt.Errorf("insights.epsilon: %q is not a number", v)
+		}
+		s.InsightsEpsilon = n
+	}
+
+	if v, ok := f.String("watchers.file.debounce"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return s, fmt.Errorf("watchers.file.debounce: %q is not a duration (e.g. 500ms)", v)
+		}
+		s.FileDebounce = d
+	}
+
+	if err := s.applyEnv(); err != nil {
+		return s, err
+	}
+	for i, path := range s.HistoryFiles {
+		s.HistoryFiles[i] = ExpandHome(path)
+	}
+	return s, s.Validate()
+}
+
+// applyEnv applies environment variable overrides
+func (s *Settings) applyEnv() error {
+	if v := os.Getenv("MEMORYPILOT_PROVIDERS"); v != "" {
+		s.Providers = strings.Split(v, ",")
+	}
+	if v := os.Getenv("MEMORYPILOT_EMBEDDING_PROVIDERS"); v != "" {
+		s.EmbeddingProviders = strings.Split(v, ",")
+	}
+	if v := os.Getenv("MEMORYPILOT_MODEL"); v != "" {
+		s.Model = v
+	}
+	if v := os.Getenv("MEMORYPILOT_EMBEDDING_MODEL"); v != "" {
+		s.EmbeddingModel = v
+	}
+	if v := os.Getenv("ANTHROPIC_API_KEY"); v != "" {
+		s.ClaudeAPIKey = v
+	}
+	if v := os.Getenv("MEMORYPILOT_CLAUDE_DAILY_BUDGET"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid MEMORYPILOT_CLAUDE_DAILY_BUDGET: %w", err)
+		}
+		s.ClaudeDailyBudget = n
+	}
+	if os.Getenv("MEMORYPILOT_OFFLINE") != "" {
+		s.Offline = true
+	}
+	if v := os.Getenv("MEMORYPILOT_API_ENABLED"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid MEMORYPILOT_API_ENABLED: %w", err)
+		}
+		s.APIEnabled = b
+	}
+	if v := os.Getenv("MEMORYPILOT_API_HOST"); v != "" {
+		s.APIHost = v
+	}
+	if v := os.Getenv("MEMORYPILOT_API_PORT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid MEMORYPILOT_API_PORT: %w", err)
+		}
+		s.APIPort = n
+	}
+	if v := os.Getenv("MEMORYPILOT_API_TOKEN"); v != "" {
+		s.APIToken = v
+	}
+	return nil
+}
+
+// Validate rejects settings the daemon can't run with
+func (s Settings) Validate() error {
+	switch {
+	case len(s.Providers) == 0:
+		return fmt.Errorf("extraction.providers must list at least one provider")
+	case s.ClaudeDailyBudget < 0:
+		return fmt.Errorf("extraction.claudeDailyBudget must not be negative, got %d", s.ClaudeDailyBudget)
+	case s.FileDebounce < 0:
+		return fmt.Errorf("watchers.file.debounce must not be negative, got %s", s.FileDebounce)
+	case s.ScanBudget < 1:
+		return fmt.Errorf("watchers.scanBudget must be at least 1, got %d", s.ScanBudget)
+	case s.APIPort < 1 || s.APIPort > 65535:
+		return fmt.Errorf("api.port must be between 1 and 65535, got %d", s.APIPort)
+	case s.InsightsWindowDays < 1:
+		return fmt.Errorf("insights.windowDays must be at least 1, got %d", s.InsightsWindowDays)
+	case s.InsightsMinContributors < 2:
+		return fmt.Errorf("insights.minContributors must be at least 2, got %d", s.InsightsMinContributors)
+	case s.InsightsEpsilon < 0:
+		return fmt.Errorf("insights.epsilon must not be negative, got %g", s.InsightsEpsilon)
+	case s.ExpiryAction != ExpiryArchive && s.ExpiryAction != ExpiryDelete:
+		return fmt.Errorf("expiry.action must be archive or delete, got %q", s.ExpiryAction)
+	}
+	return s.Tuning.Validate()
+}
+
+// ExpandHome expands a leading ~ to the home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Tuning holds the extraction and recall knobs power users adjust most.
+// The daemon re-reads them when config.yaml changes.
+type Tuning struct {
+	MinConfidence   float64       // extraction.minConfidence
+	ReviewBelow     float64       // extraction.reviewBelow; 0 disables
+	DedupSimilarity float64       // extraction.dedupSimilarity; 0 disables
+	MinSignificance float64       // extraction.minFileSignificance
+	BatchSize       int           // extraction.batchSize
+	BatchWait       time.Duration // extraction.batchWait
+	GitInterval     time.Duration // watchers.git.interval
+	ScopeConflicts  string        // recall.scopeConflicts: annotate, strict or off
+
+	// Search ranks hybrid recall results (recall.weights.keyword,
+	// .semantic, .importance, .recency, recall.recencyHalfLife and
+	// recall.vectorWeights.summary and .topics)
+	Search models.SearchWeights
+}
+
+// DefaultTuning returns the built-in tuning
+func DefaultTuning() Tuning {
+	return Tuning{
+		MinConfidence:   0.6,
+		DedupSimilarity: 0.92,
+		MinSignificance: 0.1,
+		BatchSize:       10,
+		BatchWait:       5 * time.Second,
+		GitInterval:     30 * time.Second,
+		ScopeConflicts:  "annotate",
+		Search:          models.DefaultSearchWeights(),
+	}
+}
+
+// Tuning returns the file's tuning, with defaults for unset keys
+func (f *File) Tuning() (Tuning, error) {
+	t := DefaultTuning()
+
+	if v, ok := f.String("extraction.minConfidence"); ok {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return t, fmt.Errorf("extraction.minConfidence: %q is not a number", v)
+		}
+		t.MinConfidence = n
+	}
+	if v, ok := f.String("extraction.reviewBelow"); ok {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return t, fmt.Errorf("extraction.reviewBelow: %q is not a number", v)
+		}
+		t.ReviewBelow = n
+	}
+	if v, ok := f.String("extraction.dedupSimilarity"); ok {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return t, fmt.Errorf("extraction.dedupSimilarity: %q is not a number", v)
+		}
+		t.DedupSimilarity = n
+	}
+	if v, ok := f.String("extraction.minFileSignificance"); ok {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return t, fmt.Errorf("extraction.minFileSignificance: %q is not a number", v)
+		}
+		t.MinSignificance = n
+	}
+	if v, ok := f.String("extraction.batchSize"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return t, fmt.Errorf("extraction.batchSize: %q is not an integer", v)
+		}
+		t.BatchSize = n
+	}
+	for key, dst := range map[string]*time.Duration{
+		"extraction.batchWait":  &t.BatchWait,
+		"watchers.git.interval": &t.GitInterval,
+	} {
+		if v, ok := f.String(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return t, fmt.Errorf("%s: %q is not a duration (e.g. 5s, 1m)", key, v)
+			}
+			*dst = d
+		}
+	}
+
+	if v, ok := f.String("recall.scopeConflicts"); ok {
+		t.ScopeConflicts = v
+	}
+	for key, dst := range map[string]*float64{
+		"recall.weights.keyword":       &t.Search.Keyword,
+		"recall.weights.semantic":      &t.Search.Semantic,
+		"recall.weights.importance":    &t.Search.Importance,
+		"recall.weights.recency":       &t.Search.Recency,
+		"recall.vectorWeights.summary": &t.Search.SummaryVector,
+		"recall.vectorWeights.topics":  &t.Search.TopicsVector,
+	} {
+		if v, ok := f.String(key); ok {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return t, fmt.Errorf("%s: %q is not a number", key, v)
+			}
+			*dst = n
+		}
+	}
+	if v, ok := f.String("recall.recencyHalfLife"); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return t, fmt.Errorf("recall.recencyHalfLife: %q is not a duration (e.g. 720h)", v)
+		}
+		t.Search.RecencyHalfLife = d
+	}
+
+	return t, t.Validate()
+}
+
+// Validate rejects values that would stall or flood the pipeline
+func (t Tuning) Validate() error {
+	switch {
+	case t.MinConfidence < 0 || t.MinConfidence > 1:
+		return fmt.Errorf("extraction.minConfidence must be between 0 and 1, got %v", t.MinConfidence)
+	case t.ReviewBelow < 0 || t.ReviewBelow > 1:
+		return fmt.Errorf("extraction.reviewBelow must be between 0 (off) and 1, got %v", t.ReviewBelow)
+	case t.DedupSimilarity != 0 && (t.DedupSimilarity < 0.5 || t.DedupSimilarity > 1):
+		return fmt.Errorf("extraction.dedupSimilarity must be 0 (off) or between 0.5 and 1, got %v", t.DedupSimilarity)
+	case t.MinSignificance < 0 || t.MinSignificance > 1:
+		return fmt.Errorf("extraction.minFileSignificance must be between 0 and 1, got %v", t.MinSignificance)
+	case t.BatchSize < 1 || t.BatchSize > 500:
+		return fmt.Errorf("extraction.batchSize must be between 1 and 500, got %d", t.BatchSize)
+	case t.BatchWait < 100*time.Millisecond || t.BatchWait > 10*time.Minute:
+		return fmt.Errorf("extraction.batchWait must be between 100ms and 10m, got %s", t.BatchWait)
+	case t.GitInterval < time.Second || t.GitInterval > time.Hour:
+		return fmt.Errorf("watchers.git.interval must be between 1s and 1h, got %s", t.GitInterval)
+	case t.ScopeConflicts != "annotate" && t.ScopeConflicts != "strict" && t.ScopeConflicts != "off":
+		return fmt.Errorf("recall.scopeConflicts must be annotate, strict or off, got %q", t.ScopeConflicts)
+	case t.Search.RecencyHalfLife < time.Hour:
+		return fmt.Errorf("recall.recencyHalfLife must be at least 1h, got %s", t.Search.RecencyHalfLife)
+	}
+	return validateWeights(t.Search)
+}
+
+// validateWeights rejects negative weights and a ranking that weighs nothing
+func validateWeights(w models.SearchWeights) error {
+	for _, c := range []struct {
+		key    string
+		weight float64
+	}{
+		{"keyword", w.Keyword},
+		{"semantic", w.Semantic},
+		{"importance", w.Importance},
+		{"recency", w.Recency},
+	} {
+		if c.weight < 0 || c.weight > 10 {
+			return fmt.Errorf("recall.weights.%s must be between 0 and 10, got %v", c.key, c.weight)
+		}
+	}
+	if w.SummaryVector < 0 || w.SummaryVector > 1 {
+		return fmt.Errorf("recall.vectorWeights.summary must be between 0 and 1, got %v", w.SummaryVector)
+	}
+	if w.TopicsVector < 0 || w.TopicsVector > 1 {
+		return fmt.Errorf("recall.vectorWeights.topics must be between 0 and 1, got %v", w.TopicsVector)
+	}
+	if w.Keyword+w.Semantic+w.Importance+w.Recency == 0 {
+		return fmt.Errorf("recall.weights can't all be 0")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package daemon manages the background daemon process: its PID file,
+// starting it detached from the terminal, and stopping it.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default file names under the config directory
+const (
+	PIDFileName = "daemon.pid"
+	LogFileName = "daemon.log"
+)
+
+// Info describes a running daemon, as recorded in its PID file
+type Info struct {
+	PID     int
+	Started time.Time
+}
+
+// Uptime is how long the daemon has been running
+func (i Info) Uptime() time.Duration {
+	return time.Since(i.Started).Round(time.Second)
+}
+
+// WritePID records the current process in the PID file. The file holds the
+// PID on the first line and the start time (RFC 3339) on the second.
+func WritePID(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data := fmt.Sprintf("%d\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	return os.WriteFile(path, []byte(data), 0644)
+}
+
+// RemovePID deletes the PID file if it still names the current process, so
+// a daemon exiting late doesn't remove its successor's file
+func RemovePID(path string) error {
+	info, err := ReadPID(path)
+	if err != nil || info.PID != os.Getpid() {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// ReadPID parses the PID file
+func ReadPID(path string) (Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Info{}, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || pid <= 0 {
+		return Info{}, fmt.Errorf("%s: invalid PID %q", path, lines[0])
+	}
+	info := Info{PID: pid}
+	if len(lines) > 1 {
+		info.Started, _ = time.Parse(time.RFC3339, strings.TrimSpace(lines[1]))
+	}
+	if info.Started.IsZero() {
+		if st, err := os.Stat(path); err == nil {
+			info.Started = st.ModTime()
+		}
+	}
+	return info, nil
+}
+
+// Running returns the daemon named by the PID file if its process is
+// alive. A stale file, left by a daemon that crashed, is removed.
+func Running(path string) (Info, bool) {
+	info, err := ReadPID(path)
+	if err != nil {
+		return Info{}, false
+	}
+	if !alive(info.PID) {
+		os.Remove(path)
+		return Info{}, false
+	}
+	return info, true
+}
+
+// Detach starts this executable again with args, detached from the
+// terminal and with its output appended to logPath. It returns the child's
+// PID without waiting for it.
+func Detach(args []string, logPath string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return 0, err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer logFile.Close()
+
+	child := exec.Command(exe, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachAttr()
+	if err := child.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := child.Process.Pid
+	// The child outlives us; release it rather than wait
+	child.Process.Release()
+	return pid, nil
+}
+
+// ErrNotRunning is returned by Stop when no daemon is running
+var ErrNotRunning = errors.New("daemon is not running")
+
+// Stop asks the daemon in the PID file to shut down and waits up to
+// timeout for it to exit
+func Stop(path string, timeout time.Duration) (Info, error) {
+	info, ok := Running(path)
+	if !ok {
+		return Info{}, ErrNotRunning
+	}
+	if err := terminate(info.PID); err != nil {
+		return info, fmt.Errorf("failed to signal PID %d: %w", info.PID, err)
+	}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !alive(info.PID) {
+			os.Remove(path)
+			return info, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return info, fmt.Errorf("daemon (PID %d) did not exit within %s", info.PID, timeout)
+}
@@ -0,0 +1,290 @@
+// Package demo generates a realistic synthetic memory store, so recall and
+// MCP integration can be evaluated before any real work is captured.
+package demo
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// Days is how far back the generated activity reaches
+const Days = 30
+
+// Summary counts what Seed generated
+type Summary struct {
+	Projects int
+	Memories int
+	Events   int
+	Embedded int // memories with embeddings; 0 if the embedder was unavailable
+}
+
+type demoProject struct {
+	name, path string
+}
+
+var projects = []demoProject{
+	{"acme-api", "/home/demo/code/acme-api"},
+	{"acme-web", "/home/demo/code/acme-web"},
+	{"infra", "/home/demo/code/infra"},
+}
+
+// demoMemory is a memory and, for git sources, the commit it came from
+type demoMemory struct {
+	project int // index into projects, -1 for personal
+	memType models.MemoryType
+	source  models.SourceType
+	summary string
+	content string
+	topics  []string
+	daysAgo int
+}
+
+var memories = []demoMemory{
+	{0, models.MemoryTypeDecision, models.SourceTypeGit, "Use pgx instead of lib/pq",
+		"Switched the Postgres driver from lib/pq to pgx: lib/pq is in maintenance mode and pgx supports COPY and batch queries, which the import job needs.",
+		[]string{"postgres", "database", "go"}, 28},
+	{0, models.MemoryTypeMistake, models.SourceTypeGit, "Close rows before issuing another query",
+		"Connection pool exhaustion in /orders was caused by iterating rows without closing them on early return; always defer rows.Close() right after Query.",
+		[]string{"postgres", "connection-pool", "bug"}, 25},
+	{0, models.MemoryTypePattern, models.SourceTypeGit, "Handlers return errors, middleware writes responses",
+		"HTTP handlers have the signature func(w, r) error; the errorMiddleware maps domain errors (ErrNotFound, ErrConflict) to status codes so handlers never write error responses themselves.",
+		[]string{"http", "errors", "architecture"}, 22},
+	{0, models.MemoryTypeDecision, models.SourceTypeGit, "Migrations run with goose at startup",
+		"Database migrations use goose and run at service startup behind an advisory lock, so rolling deploys can't apply the same migration twice.",
+		[]string{"migrations", "postgres", "deploy"}, 20},
+	{0, models.MemoryTypeFact, models.SourceTypeFile, "Integration tests need TEST_DATABASE_URL",
+		"Integration tests are skipped unless TEST_DATABASE_URL is set; make test-db starts a disposable Postgres in Docker and exports it.",
+		[]string{"testing", "postgres", "docker"}, 18},
+	{0, models.MemoryTypeLearning, models.SourceTypeTerminal, "Race detector catches the cache bug",
+		"The flaky TestPriceCache failure was a data race on the map in priceCache; go test -race reproduces it reliably, so CI now runs with -race.",
+		[]string{"testing", "concurrency", "ci"}, 12},
+	{0, models.MemoryTypeDecision, models.SourceTypeGit, "Idempotency keys on payment endpoints",
+		"POST /payments requires an Idempotency-Key header; responses are cached for 24h in the idempotency_keys table so client retries never double-charge.",
+		[]string{"payments", "api", "reliability"}, 7},
+	{0, models.MemoryTypeMistake, models.SourceTypeGit, "Time zones in report queries",
+		"Daily revenue reports were off by one day for US customers because date_trunc ran in UTC; reports now truncate in the account's time zone.",
+		[]string{"postgres", "timezones", "reports"}, 3},
+
+	{1, models.MemoryTypeDecision, models.SourceTypeGit, "Server components fetch data, client components stay dumb",
+		"In acme-web, data fetching happens in server components; client components receive props only, which keeps API tokens off the client bundle.",
+		[]string{"nextjs", "react", "architecture"}, 27},
+	{1, models.MemoryTypePattern, models.SourceTypeGit, "Forms use react-hook-form with zod schemas",
+		"All forms use react-hook-form with a zod schema shared with the API client, so validation messages match server-side errors.",
+		[]string{"forms", "zod", "react"}, 21},
+	{1, models.MemoryTypeMistake, models.SourceTypeGit, "Hydration mismatch from Date.now()",
+		"A hydration mismatch on the dashboard came from rendering Date.now() during SSR; relative times are now rendered in a client-only component.",
+		[]string{"nextjs", "ssr", "bug"}, 15},
+	{1, models.MemoryTypeFact, models.SourceTypeFile, "Design tokens live in tailwind.config.ts",
+		"Colors, spacing and font sizes come from design tokens in tailwind.config.ts; arbitrary values like text-[13px] are rejected by the lint rule.",
+		[]string{"tailwind", "design-system", "css"}, 14},
+	{1, models.MemoryTypeDecision, models.SourceTypeGit, "Playwright over Cypress for e2e",
+		"End-to-end tests moved from Cypress to Playwright for parallel runs and WebKit coverage; CI time for e2e dropped from 14 to 5 minutes.",
+		[]string{"testing", "e2e", "playwright"}, 9},
+	{1, models.MemoryTypeLearning, models.SourceTypeTerminal, "pnpm needs shamefully-hoist for the storybook build",
+		"Storybook fails to resolve peer dependencies under pnpm's strict layout; .npmrc sets public-hoist-pattern for @storybook/* rather than shamefully-hoist.",
+		[]string{"pnpm", "storybook", "build"}, 4},
+
+	{2, models.MemoryTypeDecision, models.SourceTypeGit, "One Terraform state per environment",
+		"Terraform state is split per environment (dev, staging, prod) with separate S3 backends, so a plan in dev can never touch prod resources.",
+		[]string{"terraform", "aws", "environments"}, 29},
+	{2, models.MemoryTypeFact, models.SourceTypeGit, "Deploys go through Argo CD",
+		"Kubernetes deploys are driven by Argo CD watching the deploy/ directory; kubectl apply against prod is reserved for incidents.",
+		[]string{"kubernetes", "argocd", "deploy"}, 24},
+	{2, models.MemoryTypeMistake, models.SourceTypeTerminal, "Pods OOMKilled after the Go 1.22 upgrade",
+		"acme-api pods were OOMKilled after upgrading Go because GOMEMLIMIT wasn't set; the deployment now sets GOMEMLIMIT to 90% of the memory limit.",
+		[]string{"kubernetes", "go", "memory"}, 16},
+	{2, models.MemoryTypePattern, models.SourceTypeGit, "Secrets come from External Secrets, never from values files",
+		"Helm values never contain secrets; ExternalSecret resources pull them from AWS Secrets Manager, and a CI check rejects base64 blobs in values files.",
+		[]string{"secrets", "kubernetes", "security"}, 10},
+	{2, models.MemoryTypeLearning, models.SourceTypeTerminal, "terraform plan -refresh=false for quick checks",
+		"terraform plan -refresh=false cuts plan time from minutes to seconds when only checking a variable change; run a full plan before apply.",
+		[]string{"terraform", "productivity"}, 2},
+
+	{-1, models.MemoryTypePreference, models.SourceTypeFile, "Prefers table-driven tests",
+		"Prefers table-driven tests with t.Run subtests and descriptive case names over separate test functions per case.",
+		[]string{"testing", "go", "style"}, 26},
+	{-1, models.MemoryTypePreference, models.SourceTypeFile, "Small commits with conventional prefixes",
+		"Writes small commits with conventional-commit prefixes (feat:, fix:, refactor:) and explains why in the body.",
+		[]string{"git", "workflow"}, 19},
+	{-1, models.MemoryTypePreference, models.SourceTypeChat, "Explanations before code",
+		"Likes a short explanation of the approach before code in AI answers, and no more than one alternative unless asked.",
+		[]string{"ai", "communication"}, 11},
+	{-1, models.MemoryTypePreference, models.SourceTypeFile, "Neovim with gopls and format on save",
+		"Edits in Neovim with gopls and ts_ls; format on save with gofumpt and prettier is expected in every project.",
+		[]string{"editor", "neovim", "formatting"}, 6},
+}
+
+// routineCommits and commands fill the activity between memorable events
+var routineCommits = []string{
+	"chore: bump dependencies", "docs: update README", "test: cover edge cases",
+	"refactor: rename variables for clarity", "fix: typo in log message", "feat: add pagination",
+	"chore: tidy imports", "feat: expose metrics endpoint",
+}
+
+var commands = map[int][]string{
+	0: {"go test ./...", "go test -race ./...", "make test-db", "go build ./...", "goose status"},
+	1: {"pnpm dev", "pnpm test", "pnpm playwright test", "pnpm lint", "pnpm build"},
+	2: {"terraform plan", "terraform apply", "kubectl get pods -n acme", "helm diff upgrade acme ./chart", "argocd app sync acme-api"},
+}
+
+// Seed fills s with the demo projects, memories and events, dated in the
+// Days before now. Memories are embedded with emb if it is reachable; they
+// still work with keyword recall if not.
+func Seed(s *store.Store, emb embedding.Embedder, now time.Time) (Summary, error) {
+	var sum Summary
+	rng := rand.New(rand.NewSource(1))
+
+	ids := make([]string, len(projects))
+	for i, p := range projects {
+		project := models.Project{
+			ID:        ulid.Make().String(),
+			Name:      p.name,
+			Path:      p.path,
+			CreatedAt: now.AddDate(0, 0, -Days),
+			LastSeen:  now,
+		}
+		if err := s.CreateProject(&project); err != nil {
+			return sum, fmt.Errorf("project %s: %w", p.name, err)
+		}
+		ids[i] = project.ID
+		sum.Projects++
+	}
+
+	embedOK := emb != nil
+	for _, dm := range memories {
+		at := now.AddDate(0, 0, -dm.daysAgo).Add(-time.Duration(rng.Intn(8*60)) * time.Minute)
+		m := models.Memory{
+			ID:             ulid.Make().String(),
+			Type:           dm.memType,
+			Content:        dm.content,
+			Summary:        dm.summary,
+			Scope:          models.MemoryScopePersonal,
+			Source:         models.Source{Type: dm.source, Timestamp: at},
+			Confidence:     0.75 + float64(rng.Intn(20))/100,
+			Importance:     0.4 + float64(rng.Intn(50))/100,
+			Topics:         dm.topics,
+			CreatedAt:      at,
+			LastAccessedAt: at,
+			Provider:       "demo",
+		}
+		if dm.project >= 0 {
+			m.Scope = models.MemoryScopeProject
+			m.ProjectID = &ids[dm.project]
+		}
+
+		// Memories from commits point at a matching commit event
+		if dm.source == models.SourceTypeGit {
+			hash := fakeHash(dm.content)
+			m.Source.Reference = hash
+			event := commitEvent(ids[dm.project], projects[dm.project].path, hash, commitMessage(dm), at.Add(-5*time.Minute))
+			if err := addEvent(s, event); err != nil {
+				return sum, err
+			}
+			sum.Events++
+		}
+
+		if embedOK {
+			vec, err := emb.Embed(dm.content)
+			if err != nil || len(vec) == 0 {
+				embedOK = false
+			} else {
+				m.Embedding = vec
+				sum.Embedded++
+			}
+		}
+
+		if err := s.CreateMemory(&m); err != nil {
+			return sum, fmt.Errorf("memory %q: %w", dm.summary, err)
+		}
+		sum.Memories++
+	}
+
+	// Background activity: a few commits and commands per project per day
+	for day := Days; day >= 0; day-- {
+		for i, p := range projects {
+			if rng.Intn(3) == 0 {
+				continue // not every project is touched every day
+			}
+			base := now.AddDate(0, 0, -day).Add(-time.Duration(rng.Intn(10*60)) * time.Minute)
+			if base.After(now) {
+				base = now
+			}
+			for n := rng.Intn(4); n >= 0; n-- {
+				at := base.Add(time.Duration(n*7) * time.Minute)
+				cmd := commands[i][rng.Intn(len(commands[i]))]
+				event := models.Event{
+					ID:        ulid.Make().String(),
+					Type:      "terminal_cmd",
+					Timestamp: at,
+					Data:      map[string]interface{}{"command": cmd, "cwd": p.path},
+					ProjectID: &ids[i],
+				}
+				if err := addEvent(s, event); err != nil {
+					return sum, err
+				}
+				sum.Events++
+			}
+			if rng.Intn(2) == 0 {
+				msg := routineCommits[rng.Intn(len(routineCommits))]
+				event := commitEvent(ids[i], p.path, fakeHash(fmt.Sprint(day, p.name, msg)), msg, base.Add(40*time.Minute))
+				if err := addEvent(s, event); err != nil {
+					return sum, err
+				}
+				sum.Events++
+			}
+		}
+	}
+
+	return sum, nil
+}
+
+// addEvent stores an event as already processed, so a daemon pointed at
+// the demo store doesn't extract it again
+func addEvent(s *store.Store, e models.Event) error {
+	if err := s.CreateEvent(&e); err != nil {
+		return fmt.Errorf("event: %w", err)
+	}
+	return s.MarkEventProcessed(e.ID)
+}
+
+func commitEvent(projectID, repo, hash, message string, at time.Time) models.Event {
+	return models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "git_commit",
+		Timestamp: at,
+		Data: map[string]interface{}{
+			"hash":    hash,
+			"message": message,
+			"repo":    repo,
+			"author":  "Demo User",
+			"email":   "demo@example.com",
+		},
+		ProjectID: &projectID,
+	}
+}
+
+// commitMessage phrases a memory's summary as the commit that produced it
+func commitMessage(dm demoMemory) string {
+	prefix := "feat: "
+	switch dm.memType {
+	case models.MemoryTypeMistake:
+		prefix = "fix: "
+	case models.MemoryTypePattern:
+		prefix = "refactor: "
+	}
+	return prefix + dm.summary
+}
+
+// fakeHash returns a stable commit-like hash for s
+func fakeHash(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
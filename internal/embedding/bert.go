@@ -0,0 +1,364 @@
+package embedding
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// bertConfig is the part of a Hugging Face config.json the encoder needs
+type bertConfig struct {
+	Hidden       int     `json:"hidden_size"`
+	Layers       int     `json:"num_hidden_layers"`
+	Heads        int     `json:"num_attention_heads"`
+	Intermediate int     `json:"intermediate_size"`
+	MaxPositions int     `json:"max_position_embeddings"`
+	VocabSize    int     `json:"vocab_size"`
+	LayerNormEps float64 `json:"layer_norm_eps"`
+}
+
+// bert is a BERT encoder whose mean-pooled, normalized output is a
+// sentence embedding, as sentence-transformers computes it
+type bert struct {
+	cfg       bertConfig
+	word      []float32 // vocab × hidden
+	position  []float32 // positions × hidden
+	tokenType []float32 // only the first type's row is used
+	embNorm   layerNorm
+	layers    []bertLayer
+}
+
+type bertLayer struct {
+	query, key, value, attnOut linear
+	attnNorm                   layerNorm
+	up, down                   linear
+	outNorm                    layerNorm
+}
+
+// linear is a dense layer; w is out × in, as PyTorch stores it
+type linear struct {
+	w, b    []float32
+	in, out int
+}
+
+type layerNorm struct {
+	gamma, beta []float32
+	eps         float32
+}
+
+// loadBERT reads config.json and model.safetensors from dir
+func loadBERT(dir string) (*bert, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	m := &bert{}
+	if err := json.Unmarshal(data, &m.cfg); err != nil {
+		return nil, fmt.Errorf("config.json: %w", err)
+	}
+	cfg := &m.cfg
+	if cfg.Hidden <= 0 || cfg.Layers <= 0 || cfg.Heads <= 0 || cfg.Hidden%cfg.Heads != 0 || cfg.Intermediate <= 0 {
+		return nil, fmt.Errorf("config.json doesn't describe a BERT model")
+	}
+	if cfg.LayerNormEps == 0 {
+		cfg.LayerNormEps = 1e-12
+	}
+
+	t, err := openSafetensors(filepath.Join(dir, "model.safetensors"))
+	if err != nil {
+		return nil, err
+	}
+	h, eps := cfg.Hidden, float32(cfg.LayerNormEps)
+	if m.word, err = t.tensor("embeddings.word_embeddings.weight", -1, h); err != nil {
+		return nil, err
+	}
+	if m.position, err = t.tensor("embeddings.position_embeddings.weight", -1, h); err != nil {
+		return nil, err
+	}
+	if m.tokenType, err = t.tensor("embeddings.token_type_embeddings.weight", -1, h); err != nil {
+		return nil, err
+	}
+	if m.embNorm, err = t.layerNorm("embeddings.LayerNorm", h, eps); err != nil {
+		return nil, err
+	}
+	cfg.VocabSize = len(m.word) / h
+	cfg.MaxPositions = len(m.position) / h
+
+	for i := 0; i < cfg.Layers; i++ {
+		p := fmt.Sprintf("encoder.layer.%d.", i)
+		var l bertLayer
+		for _, ln := range []struct {
+			l       *linear
+			name    string
+			out, in int
+		}{
+			{&l.query, "attention.self.query", h, h},
+			{&l.key, "attention.self.key", h, h},
+			{&l.value, "attention.self.value", h, h},
+			{&l.attnOut, "attention.output.dense", h, h},
+			{&l.up, "intermediate.dense", cfg.Intermediate, h},
+			{&l.down, "output.dense", h, cfg.Intermediate},
+		} {
+			if *ln.l, err = t.linear(p+ln.name, ln.out, ln.in); err != nil {
+				return nil, err
+			}
+		}
+		if l.attnNorm, err = t.layerNorm(p+"attention.output.LayerNorm", h, eps); err != nil {
+			return nil, err
+		}
+		if l.outNorm, err = t.layerNorm(p+"output.LayerNorm", h, eps); err != nil {
+			return nil, err
+		}
+		m.layers = append(m.layers, l)
+	}
+	return m, nil
+}
+
+// embed encodes token IDs into a unit-length sentence embedding
+func (m *bert) embed(ids []int) []float32 {
+	n, h := len(ids), m.cfg.Hidden
+	x := make([]float32, n*h)
+	for t, id := range ids {
+		if id >= m.cfg.VocabSize {
+			id = 0
+		}
+		row := x[t*h : (t+1)*h]
+		word := m.word[id*h : (id+1)*h]
+		pos := m.position[t*h : (t+1)*h]
+		for i := range row {
+			row[i] = word[i] + pos[i] + m.tokenType[i]
+		}
+	}
+	m.embNorm.apply(x, n)
+	for i := range m.layers {
+		x = m.layers[i].forward(x, n, m.cfg.Heads)
+	}
+
+	// Mean pooling over the tokens, then L2 normalization
+	out := make([]float32, h)
+	for t := 0; t < n; t++ {
+		for i, v := range x[t*h : (t+1)*h] {
+			out[i] += v
+		}
+	}
+	var norm float64
+	for i := range out {
+		out[i] /= float32(n)
+		norm += float64(out[i]) * float64(out[i])
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range out {
+			out[i] *= scale
+		}
+	}
+	return out
+}
+
+// forward runs one encoder layer over n tokens; a single unpadded text
+// needs no attention mask
+func (l *bertLayer) forward(x []float32, n, heads int) []float32 {
+	h := l.query.out
+	d := h / heads
+	q, k, v := l.query.apply(x, n), l.key.apply(x, n), l.value.apply(x, n)
+
+	ctx := make([]float32, n*h)
+	scores := make([]float32, n)
+	scale := float32(1 / math.Sqrt(float64(d)))
+	for head := 0; head < heads; head++ {
+		off := head * d
+		for i := 0; i < n; i++ {
+			qi := q[i*h+off : i*h+off+d]
+			best := float32(math.Inf(-1))
+			for j := 0; j < n; j++ {
+				kj := k[j*h+off : j*h+off+d]
+				var s float32
+				for t := range qi {
+					s += qi[t] * kj[t]
+				}
+				scores[j] = s * scale
+				best = max(best, scores[j])
+			}
+			var sum float32
+			for j := range scores {
+				scores[j] = float32(math.Exp(float64(scores[j] - best)))
+				sum += scores[j]
+			}
+			ci := ctx[i*h+off : i*h+off+d]
+			for j := 0; j < n; j++ {
+				w := scores[j] / sum
+				vj := v[j*h+off : j*h+off+d]
+				for t := range ci {
+					ci[t] += w * vj[t]
+				}
+			}
+		}
+	}
+
+	a := l.attnOut.apply(ctx, n)
+	for i := range a {
+		a[i] += x[i]
+	}
+	l.attnNorm.apply(a, n)
+
+	u := l.up.apply(a, n)
+	for i, v := range u {
+		u[i] = float32(0.5 * float64(v) * (1 + math.Erf(float64(v)/math.Sqrt2)))
+	}
+	o := l.down.apply(u, n)
+	for i := range o {
+		o[i] += a[i]
+	}
+	l.outNorm.apply(o, n)
+	return o
+}
+
+// apply multiplies rows of x by the layer's weights and adds its bias
+func (l *linear) apply(x []float32, rows int) []float32 {
+	y := make([]float32, rows*l.out)
+	for r := 0; r < rows; r++ {
+		xr := x[r*l.in : (r+1)*l.in]
+		yr := y[r*l.out : (r+1)*l.out]
+		for o := range yr {
+			w := l.w[o*l.in : (o+1)*l.in]
+			var sum float32
+			for i, v := range xr {
+				sum += v * w[i]
+			}
+			yr[o] = sum + l.b[o]
+		}
+	}
+	return y
+}
+
+// apply normalizes each row of x in place
+func (ln *layerNorm) apply(x []float32, rows int) {
+	h := len(ln.gamma)
+	for r := 0; r < rows; r++ {
+		row := x[r*h : (r+1)*h]
+		var mean, variance float32
+		for _, v := range row {
+			mean += v
+		}
+		mean /= float32(h)
+		for _, v := range row {
+			variance += (v - mean) * (v - mean)
+		}
+		variance /= float32(h)
+		inv := float32(1 / math.Sqrt(float64(variance+ln.eps)))
+		for i, v := range row {
+			row[i] = (v-mean)*inv*ln.gamma[i] + ln.beta[i]
+		}
+	}
+}
+
+// safetensors is a loaded .safetensors file: a JSON header describing the
+// tensors, followed by their raw little-endian data
+type safetensors struct {
+	header map[string]tensorInfo
+	data   []byte
+}
+
+type tensorInfo struct {
+	DType   string `json:"dtype"`
+	Shape   []int  `json:"shape"`
+	Offsets [2]int `json:"data_offsets"`
+}
+
+func openSafetensors(path string) (*safetensors, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < 8 {
+		return nil, fmt.Errorf("%s is not a safetensors file", path)
+	}
+	n := binary.LittleEndian.Uint64(raw)
+	if n > uint64(len(raw)-8) {
+		return nil, fmt.Errorf("%s is truncated", path)
+	}
+
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(raw[8:8+n], &header); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	st := &safetensors{header: make(map[string]tensorInfo, len(header)), data: raw[8+n:]}
+	for name, entry := range header {
+		if name == "__metadata__" {
+			continue
+		}
+		var info tensorInfo
+		if err := json.Unmarshal(entry, &info); err != nil {
+			return nil, fmt.Errorf("%s: tensor %s: %w", path, name, err)
+		}
+		st.header[name] = info
+	}
+	return st, nil
+}
+
+// tensor returns a float32 tensor by name, allowing the "bert." prefix
+// some checkpoints carry, and checks its shape (-1 matches any size)
+func (st *safetensors) tensor(name string, shape ...int) ([]float32, error) {
+	info, ok := st.header[name]
+	if !ok {
+		if info, ok = st.header["bert."+name]; !ok {
+			return nil, fmt.Errorf("model has no tensor %s", name)
+		}
+	}
+	if info.DType != "F32" {
+		return nil, fmt.Errorf("tensor %s is %s; only F32 models are supported", name, info.DType)
+	}
+	if len(info.Shape) != len(shape) {
+		return nil, fmt.Errorf("tensor %s has shape %v", name, info.Shape)
+	}
+	size := 1
+	for i, dim := range info.Shape {
+		if shape[i] >= 0 && shape[i] != dim {
+			return nil, fmt.Errorf("tensor %s has shape %v", name, info.Shape)
+		}
+		size *= dim
+	}
+	begin, end := info.Offsets[0], info.Offsets[1]
+	if begin < 0 || end > len(st.data) || end-begin != size*4 {
+		return nil, fmt.Errorf("tensor %s has bad data offsets", name)
+	}
+
+	values := make([]float32, size)
+	for i := range values {
+		values[i] = math.Float32frombits(binary.LittleEndian.Uint32(st.data[begin+i*4:]))
+	}
+	return values, nil
+}
+
+func (st *safetensors) linear(name string, out, in int) (linear, error) {
+	w, err := st.tensor(name+".weight", out, in)
+	if err != nil {
+		return linear{}, err
+	}
+	b, err := st.tensor(name+".bias", out)
+	if err != nil {
+		return linear{}, err
+	}
+	return linear{w: w, b: b, in: in, out: out}, nil
+}
+
+// layerNorm loads a layer norm's weight and bias, named gamma and beta in
+// older checkpoints
+func (st *safetensors) layerNorm(name string, size int, eps float32) (layerNorm, error) {
+	gamma, err := st.tensor(name+".weight", size)
+	if err != nil {
+		if gamma, err = st.tensor(name+".gamma", size); err != nil {
+			return layerNorm{}, err
+		}
+	}
+	beta, err := st.tensor(name+".bias", size)
+	if err != nil {
+		if beta, err = st.tensor(name+".beta", size); err != nil {
+			return layerNorm{}, err
+		}
+	}
+	return layerNorm{gamma: gamma, beta: beta, eps: eps}, nil
+}
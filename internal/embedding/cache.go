@@ -0,0 +1,130 @@
+package embedding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Cache stores embeddings by key; the store implements it
+type Cache interface {
+	// CachedEmbedding returns the embedding stored under key, or nil
+	CachedEmbedding(key string) ([]float32, error)
+	CacheEmbedding(key string, embedding []float32) error
+}
+
+// Modeler is implemented by embedders that can name the model their
+// vectors come from; only their embeddings can be cached
+type Modeler interface {
+	Model() string
+}
+
+// ModelOf returns the model an embedder names, or "" if it can't
+func ModelOf(e Embedder) string {
+	if m, ok := e.(Modeler); ok {
+		return m.Model()
+	}
+	return ""
+}
+
+// Model names the Ollama model
+func (e *OllamaEmbedder) Model() string {
+	return "ollama/" + e.model
+}
+
+// Model names the fake embedder, whose vectors only depend on the text
+func (e *FakeEmbedder) Model() string {
+	return "fake"
+}
+
+// Model names the null embedder
+func (e *NullEmbedder) Model() string {
+	return "null"
+}
+
+// Model names the chain's models in order
+func (c *Chain) Model() string {
+	models := make([]string, 0, len(c.embedders))
+	for i, e := range c.embedders {
+		if m, ok := e.(Modeler); ok {
+			models = append(models, m.Model())
+		} else {
+			models = append(models, c.names[i])
+		}
+	}
+	return strings.Join(models, ",")
+}
+
+// CachedEmbedder looks texts up in a cache before embedding them, so
+// identical texts (repeated content, re-extracted memories, common
+// queries) are embedded once per model
+type CachedEmbedder struct {
+	embedder Embedder
+	cache    Cache
+	model    string
+}
+
+// Cached wraps an embedder with a cache. Embedders that can't name their
+// model are returned as they are, since their vectors could change.
+func Cached(e Embedder, cache Cache) Embedder {
+	m, ok := e.(Modeler)
+	if !ok || cache == nil {
+		return e
+	}
+	return &CachedEmbedder{embedder: e, cache: cache, model: m.Model()}
+}
+
+// Model names the wrapped embedder's model
+func (c *CachedEmbedder) Model() string {
+	return c.model
+}
+
+// key returns the cache key for a text: a hash of the model and the text
+func (c *CachedEmbedder) key(text string) string {
+	sum := sha256.Sum256([]byte(c.model + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Embed returns the cached embedding of text, embedding it on a miss.
+// Empty results (such as the null embedder's) aren't cached.
+func (c *CachedEmbedder) Embed(text string) ([]float32, error) {
+	key := c.key(text)
+	if emb, err := c.cache.CachedEmbedding(key); err == nil && len(emb) > 0 {
+		return emb, nil
+	}
+	emb, err := c.embedder.Embed(text)
+	if err == nil && len(emb) > 0 {
+		c.cache.CacheEmbedding(key, emb)
+	}
+	return emb, err
+}
+
+// EmbedBatch embeds the texts missing from the cache in one batch
+func (c *CachedEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	var missing []string
+	var missingAt []int
+	for i, text := range texts {
+		if emb, err := c.cache.CachedEmbedding(c.key(text)); err == nil && len(emb) > 0 {
+			embeddings[i] = emb
+			continue
+		}
+		missing = append(missing, text)
+		missingAt = append(missingAt, i)
+	}
+	if len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	embedded, err := c.embedder.EmbedBatch(missing)
+	if err != nil {
+		return nil, err
+	}
+	for j, emb := range embedded {
+		embeddings[missingAt[j]] = emb
+		if len(emb) > 0 {
+			c.cache.CacheEmbedding(c.key(missing[j]), emb)
+		}
+	}
+	return embeddings, nil
+}
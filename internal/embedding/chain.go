@@ -0,0 +1,96 @@
+package embedding
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+)
+
+// Chain tries embedders in order, falling through when one is unreachable.
+//
+// Vectors from different models aren't comparable, so a chain should hold
+// at most one real model followed by "null"; falling through to null skips
+// embedding and leaves the memory to keyword recall.
+type Chain struct {
+	names     []string
+	embedders []Embedder
+}
+
+// ChainConfig configures the providers in a chain
+type ChainConfig struct {
+	OllamaModel   string
+	LocalModelDir string // where the "local" provider's model is installed
+}
+
+// NewChain builds a chain from provider names, e.g. ["ollama", "null"]
+func NewChain(names []string, cfg ChainConfig) (*Chain, error) {
+	c := &Chain{}
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ToLower(name))
+		switch name {
+		case "ollama":
+			ollama := NewOllamaEmbedder("", cfg.OllamaModel)
+			if err := privacy.CheckURL("ollama embedder", ollama.endpoint); err != nil {
+				return nil, err
+			}
+			c.embedders = append(c.embedders, ollama)
+		case "local":
+			c.embedders = append(c.embedders, NewLocalEmbedder(cfg.LocalModelDir))
+		case "null":
+			c.embedders = append(c.embedders, &NullEmbedder{})
+		case "fake":
+			c.embedders = append(c.embedders, &FakeEmbedder{})
+		default:
+			return nil, fmt.Errorf("unknown embedding provider %q (expected ollama, local, null or fake)", name)
+		}
+		c.names = append(c.names, name)
+	}
+	if len(c.embedders) == 0 {
+		return nil, fmt.Errorf("embedding provider chain is empty")
+	}
+	return c, nil
+}
+
+// Embed returns the first successful embedding
+func (c *Chain) Embed(text string) ([]float32, error) {
+	var errs []string
+	for i, e := range c.embedders {
+		emb, err := e.Embed(text)
+		if err == nil {
+			return emb, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", c.names[i], err))
+	}
+	return nil, fmt.Errorf("all embedders failed: %s", strings.Join(errs, "; "))
+}
+
+// EmbedBatch embeds each text, falling through per text
+func (c *Chain) EmbedBatch(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		emb, err := c.Embed(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text %d: %w", i, err)
+		}
+		embeddings[i] = emb
+	}
+	return embeddings, nil
+}
+
+// FromEnv returns the chain named by MEMORYPILOT_EMBEDDING_PROVIDERS, or
+// Ollama when it is unset or invalid, with the Ollama model in
+// MEMORYPILOT_EMBEDDING_MODEL, so queries are embedded the same way the
+// daemon embedded memories. The "local" provider uses the model in
+// localModelDir.
+func FromEnv(localModelDir string) Embedder {
+	model := os.Getenv("MEMORYPILOT_EMBEDDING_MODEL")
+	if names := os.Getenv("MEMORYPILOT_EMBEDDING_PROVIDERS"); names != "" {
+		cfg := ChainConfig{OllamaModel: model, LocalModelDir: localModelDir}
+		if c, err := NewChain(strings.Split(names, ","), cfg); err == nil {
+			return c
+		}
+	}
+	return NewOllamaEmbedder("", model)
+}
@@ -0,0 +1,64 @@
+package embedding
+
+import (
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// FakeDimensions is the size of vectors from FakeEmbedder
+const FakeDimensions = 384
+
+// FakeEmbedder derives vectors from the words of a text by feature hashing,
+// without any model. Texts sharing words get similar vectors, which is
+// enough for integration tests and demos; it does not understand meaning.
+type FakeEmbedder struct{}
+
+// Embed hashes each word (and adjacent word pair) into a bucket and
+// returns the normalized counts
+func (e *FakeEmbedder) Embed(text string) ([]float32, error) {
+	vec := make([]float32, FakeDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		addFeature(vec, w, 1)
+		if i > 0 {
+			addFeature(vec, words[i-1]+" "+w, 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v * v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec, nil
+}
+
+// EmbedBatch embeds each text
+func (e *FakeEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		out[i], _ = e.Embed(t)
+	}
+	return out, nil
+}
+
+// addFeature adds weight to the feature's bucket, with a sign from another
+// hash bit so collisions tend to cancel out
+func addFeature(vec []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vec[sum%uint64(len(vec))] += weight
+}
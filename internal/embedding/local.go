@@ -0,0 +1,207 @@
+package embedding
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+)
+
+const (
+	// LocalModelName is the sentence-transformer the local embedder runs
+	LocalModelName = "all-MiniLM-L6-v2"
+
+	// LocalModelRevision is the Hugging Face commit the model is pinned to,
+	// so the files match localModelFiles' digests
+	LocalModelRevision = "c9745ed1d9f207416be6d2e6f8de32d1f16199bf"
+
+	// LocalModelURL is where InstallLocalModel downloads it from
+	LocalModelURL = "https://huggingface.co/sentence-transformers/all-MiniLM-L6-v2/resolve/" + LocalModelRevision
+
+	// localMaxTokens is the model's maximum sequence length; longer texts
+	// are truncated, as sentence-transformers does
+	localMaxTokens = 256
+)
+
+// localModelFiles are the files a local model directory holds, with the
+// SHA-256 of each at LocalModelRevision
+var localModelFiles = []struct {
+	name, sha256 string
+}{
+	{"config.json", "953f9c0d463486b10a6871cc2fd59f223b2c70184f49815e7efbcab5d8908b41"},
+	{"vocab.txt", "07eced375cec144d27c900241f3e339478dec958f92fddbc551f295c992038a3"},
+	{"model.safetensors", "53aa51172d142c89d9012cce15ae4d6cc0ca6895895114379cacb4fab128d9db"},
+}
+
+// ErrNoLocalModel is returned by the local embedder until its model is
+// installed
+var ErrNoLocalModel = errors.New("local embedding model not installed (run 'memorypilot embeddings install')")
+
+// LocalModelDir returns where the local model is kept in a data directory
+func LocalModelDir(dataDir string) string {
+	return filepath.Join(dataDir, "models", LocalModelName)
+}
+
+// LocalEmbedder runs a BERT sentence-transformer in process, so embedding
+// needs no server and works offline. The model is read from its
+// safetensors weights on first use; no ONNX runtime or other native
+// library is involved.
+type LocalEmbedder struct {
+	dir string
+
+	mu    sync.Mutex
+	model *bert
+	vocab *wordPiece
+	err   error // a model that failed to load, other than a missing one
+}
+
+// NewLocalEmbedder creates an embedder for the model in dir
+func NewLocalEmbedder(dir string) *LocalEmbedder {
+	return &LocalEmbedder{dir: dir}
+}
+
+// Model names the local model by its directory
+func (e *LocalEmbedder) Model() string {
+	return "local/" + filepath.Base(e.dir)
+}
+
+// load reads the model once it's installed. A missing model is looked for
+// again on every call, so installing it takes effect without a restart.
+func (e *LocalEmbedder) load() (*bert, *wordPiece, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.model != nil || e.err != nil {
+		return e.model, e.vocab, e.err
+	}
+	for _, f := range localModelFiles {
+		if _, err := os.Stat(filepath.Join(e.dir, f.name)); err != nil {
+			return nil, nil, ErrNoLocalModel
+		}
+	}
+
+	vocab, err := loadVocab(filepath.Join(e.dir, "vocab.txt"))
+	if err == nil {
+		e.model, err = loadBERT(e.dir)
+	}
+	if err != nil {
+		e.err = fmt.Errorf("failed to load local embedding model from %s: %w", e.dir, err)
+		return nil, nil, e.err
+	}
+	e.vocab = vocab
+	return e.model, e.vocab, nil
+}
+
+// Embed generates an embedding for a single text
+func (e *LocalEmbedder) Embed(text string) ([]float32, error) {
+	model, vocab, err := e.load()
+	if err != nil {
+		return nil, err
+	}
+	return model.embed(vocab.encode(text, min(localMaxTokens, model.cfg.MaxPositions))), nil
+}
+
+// EmbedBatch generates embeddings for multiple texts
+func (e *LocalEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		emb, err := e.Embed(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text %d: %w", i, err)
+		}
+		embeddings[i] = emb
+	}
+	return embeddings, nil
+}
+
+// InstallLocalModel copies the local model's files into dir from source,
+// a URL to download them from (such as LocalModelURL) or a directory
+// holding a copy. progress, if set, is called before each file. A file
+// whose SHA-256 doesn't match the pinned model's isn't installed.
+func InstallLocalModel(source, dir string, progress func(file string)) error {
+	remote := strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+	if remote {
+		if err := privacy.CheckURL("local model download", source); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range localModelFiles {
+		if progress != nil {
+			progress(f.name)
+		}
+		var err error
+		if remote {
+			err = downloadFile(strings.TrimSuffix(source, "/")+"/"+f.name, filepath.Join(dir, f.name), f.sha256)
+		} else {
+			err = copyFile(filepath.Join(source, f.name), filepath.Join(dir, f.name), f.sha256)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+
+	// Check the installed model loads before anything embeds with it
+	_, _, err := NewLocalEmbedder(dir).load()
+	return err
+}
+
+func downloadFile(url, path, sum string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+	return writeFile(path, resp.Body, sum)
+}
+
+func copyFile(src, path, sum string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFile(path, f, sum)
+}
+
+// writeFile writes r to path through a temporary file, so an interrupted
+// install never leaves a partial file in place. The file is only put in
+// place if its SHA-256 is sum.
+func writeFile(path string, r io.Reader, sum string) error {
+	if sum == "" {
+		return errors.New("no pinned checksum")
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		os.Remove(tmp)
+		return fmt.Errorf("checksum mismatch: got sha256 %s, want %s", got, sum)
+	}
+	return os.Rename(tmp, path)
+}
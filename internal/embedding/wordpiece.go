@@ -0,0 +1,129 @@
+package embedding
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// maxWordRunes is the length beyond which a word is one unknown token
+const maxWordRunes = 100
+
+// wordPiece tokenizes text the way BERT's uncased tokenizer does: split on
+// whitespace and punctuation, then into the longest pieces in the
+// vocabulary. Accents aren't stripped, so accented words may split
+// differently than in Python.
+type wordPiece struct {
+	vocab         map[string]int
+	unk, cls, sep int
+}
+
+// loadVocab reads a vocab.txt, one token per line in ID order
+func loadVocab(path string) (*wordPiece, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	w := &wordPiece{vocab: make(map[string]int)}
+	scanner := bufio.NewScanner(f)
+	for id := 0; scanner.Scan(); id++ {
+		w.vocab[strings.TrimRight(scanner.Text(), "\r")] = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for token, id := range map[string]*int{"[UNK]": &w.unk, "[CLS]": &w.cls, "[SEP]": &w.sep} {
+		n, ok := w.vocab[token]
+		if !ok {
+			return nil, fmt.Errorf("%s has no %s token", path, token)
+		}
+		*id = n
+	}
+	return w, nil
+}
+
+// encode returns the token IDs of text between [CLS] and [SEP], truncated
+// to maxTokens in all
+func (w *wordPiece) encode(text string, maxTokens int) []int {
+	ids := []int{w.cls}
+	for _, word := range basicTokens(text) {
+		for _, id := range w.pieces(word) {
+			if len(ids) == maxTokens-1 {
+				return append(ids, w.sep)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return append(ids, w.sep)
+}
+
+// pieces splits a word into the longest vocabulary pieces from the left,
+// continuations prefixed with ##, or returns [UNK] if it can't
+func (w *wordPiece) pieces(word string) []int {
+	runes := []rune(word)
+	if len(runes) > maxWordRunes {
+		return []int{w.unk}
+	}
+
+	var ids []int
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		id, found := 0, false
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = "##" + piece
+			}
+			if id, found = w.vocab[piece]; found {
+				break
+			}
+		}
+		if !found {
+			return []int{w.unk}
+		}
+		ids = append(ids, id)
+		start = end
+	}
+	return ids
+}
+
+// basicTokens lowercases text and splits it into words, with each
+// punctuation mark and CJK character a word of its own
+func basicTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r == 0 || r == unicode.ReplacementChar || unicode.IsControl(r):
+		case isPunctuation(r) || unicode.Is(unicode.Han, r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isPunctuation counts every non-alphanumeric ASCII symbol as punctuation,
+// as BERT does, besides Unicode punctuation
+func isPunctuation(r rune) bool {
+	if (r >= 33 && r <= 47) || (r >= 58 && r <= 64) || (r >= 91 && r <= 96) || (r >= 123 && r <= 126) {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
@@ -0,0 +1,171 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Provider names accepted in a provider chain
+const (
+	ProviderOllama = "ollama"
+	ProviderClaude = "claude"
+	ProviderRules  = "rules" // no model; a fallback behind the others
+	ProviderNull   = "null"
+	ProviderFake   = "fake" // rule-based, for tests and demos
+)
+
+// ChainConfig configures the providers a chain can be built from
+type ChainConfig struct {
+	OllamaModel       string
+	ClaudeAPIKey      string
+	ClaudeModel       string
+	ClaudeDailyBudget int
+}
+
+// Provider is a named extraction backend
+type Provider struct {
+	Name      string
+	Extractor Extractor
+}
+
+// Chain tries providers in order, falling through to the next when one is
+// unreachable, errors, or is over budget
+type Chain struct {
+	providers []Provider
+}
+
+// NewChain builds a chain from provider names, e.g. ["ollama", "claude", "rules"]
+func NewChain(names []string, cfg ChainConfig) (*Chain, error) {
+	c := &Chain{}
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ToLower(name))
+		var ext Extractor
+		switch name {
+		case ProviderOllama:
+			ollama := NewOllamaExtractor("", cfg.OllamaModel)
+			if err := privacy.CheckURL("ollama extractor", ollama.endpoint); err != nil {
+				return nil, err
+			}
+			ext = ollama
+		case ProviderClaude:
+			if err := privacy.CheckRemote("claude extractor"); err != nil {
+				return nil, err
+			}
+			claude := NewClaudeExtractor(cfg.ClaudeAPIKey, cfg.ClaudeModel)
+			claude.DailyBudget = cfg.ClaudeDailyBudget
+			ext = claude
+		case ProviderRules:
+			ext = &RuleExtractor{}
+		case ProviderNull:
+			ext = &NullExtractor{}
+		case ProviderFake:
+			ext = &FakeExtractor{}
+		default:
+			return nil, fmt.Errorf("unknown provider %q (expected ollama, claude, rules, null or fake)", name)
+		}
+		c.providers = append(c.providers, Provider{Name: name, Extractor: ext})
+	}
+	if len(c.providers) == 0 {
+		return nil, fmt.Errorf("provider chain is empty")
+	}
+	return c, nil
+}
+
+// Providers returns the chain's providers in order
+func (c *Chain) Providers() []Provider {
+	return c.providers
+}
+
+// SetExamples enables few-shot examples for every provider that prompts a
+// model
+func (c *Chain) SetExamples(src ExampleSource) {
+	for _, p := range c.providers {
+		if e, ok := p.Extractor.(interface{ SetExamples(ExampleSource) }); ok {
+			e.SetExamples(src)
+		}
+	}
+}
+
+// SetMinConfidence sets the confidence threshold for every provider that
+// prompts a model
+func (c *Chain) SetMinConfidence(min float64) {
+	for _, p := range c.providers {
+		if e, ok := p.Extractor.(interface{ SetMinConfidence(float64) }); ok {
+			e.SetMinConfidence(min)
+		}
+	}
+}
+
+// SetRunRecorder records the runs of every provider that prompts a model,
+// tagged with the provider's name
+func (c *Chain) SetRunRecorder(r RunRecorder) {
+	for _, p := range c.providers {
+		if e, ok := p.Extractor.(interface{ SetRunRecorder(RunRecorder) }); ok {
+			e.SetRunRecorder(providerRuns{name: p.Name, runs: r})
+		}
+	}
+}
+
+// providerRuns tags runs with the provider that made them
+type providerRuns struct {
+	name string
+	runs RunRecorder
+}
+
+func (p providerRuns) RecordExtractionRun(run *models.ExtractionRun) error {
+	run.Provider = p.name
+	return p.runs.RecordExtractionRun(run)
+}
+
+// Extract runs the first provider that succeeds and tags each memory with
+// the provider that produced it
+func (c *Chain) Extract(events []models.Event) ([]ExtractedMemory, error) {
+	var errs []error
+	for _, p := range c.providers {
+		memories, err := p.Extractor.Extract(events)
+		if err != nil {
+			log.Printf("Provider %s failed, falling through: %v", p.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		for i := range memories {
+			memories[i].Provider = p.Name
+		}
+		return memories, nil
+	}
+	return nil, fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
+
+// Complete runs the prompt on the first completing provider
+func (c *Chain) Complete(prompt string) (string, error) {
+	return c.complete(func(cp Completer) (string, error) { return cp.Complete(prompt) })
+}
+
+// CompleteJSON runs the prompt on the first completing provider
+func (c *Chain) CompleteJSON(prompt string) (string, error) {
+	return c.complete(func(cp Completer) (string, error) { return cp.CompleteJSON(prompt) })
+}
+
+func (c *Chain) complete(call func(Completer) (string, error)) (string, error) {
+	var errs []error
+	for _, p := range c.providers {
+		cp, ok := p.Extractor.(Completer)
+		if !ok {
+			continue
+		}
+		response, err := call(cp)
+		if err == nil {
+			return response, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("no provider in the chain can generate text")
+	}
+	return "", fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,158 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ErrOverBudget is returned when a provider's daily request budget is spent
+var ErrOverBudget = fmt.Errorf("%w: daily budget exhausted", ErrUnavailable)
+
+// ClaudeExtractor uses the Anthropic Messages API for memory extraction
+type ClaudeExtractor struct {
+	apiKey string
+	model  string
+	client *http.Client
+
+	// DailyBudget caps requests per day (0 = unlimited)
+	DailyBudget int
+
+	settings
+
+	mu       sync.Mutex
+	day      string
+	requests int
+}
+
+// NewClaudeExtractor creates a new Claude-based extractor
+func NewClaudeExtractor(apiKey, model string) *ClaudeExtractor {
+	if model == "" {
+		model = "claude-3-5-haiku-latest"
+	}
+	return &ClaudeExtractor{
+		apiKey: apiKey,
+		model:  model,
+		client: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+type claudeRequest struct {
+	Model     string          `json:"model"`
+	MaxTokens int             `json:"max_tokens"`
+	Messages  []claudeMessage `json:"messages"`
+}
+
+type claudeMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type claudeResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// Extract analyzes events and extracts memories
+func (e *ClaudeExtractor) Extract(events []models.Event) ([]ExtractedMemory, error) {
+	return extractWith(e, &e.settings, events)
+}
+
+// Model returns the name of the Claude model
+func (e *ClaudeExtractor) Model() string {
+	return e.model
+}
+
+// Complete sends a free-form prompt to the model and returns its response
+func (e *ClaudeExtractor) Complete(prompt string) (string, error) {
+	return e.post(prompt)
+}
+
+// CompleteJSON sends a prompt that asks for JSON output and returns the raw
+// response. Claude has no JSON mode, so the response is prefilled with "{".
+func (e *ClaudeExtractor) CompleteJSON(prompt string) (string, error) {
+	response, err := e.post(prompt, claudeMessage{Role: "assistant", Content: "{"})
+	if err != nil {
+		return "", err
+	}
+	return "{" + response, nil
+}
+
+// post runs a single message request, optionally with a prefilled reply
+func (e *ClaudeExtractor) post(prompt string, prefill ...claudeMessage) (string, error) {
+	if e.apiKey == "" {
+		return "", fmt.Errorf("%w: claude: no API key (set ANTHROPIC_API_KEY)", ErrUnavailable)
+	}
+	if err := e.spend(); err != nil {
+		return "", err
+	}
+
+	req := claudeRequest{
+		Model:     e.model,
+		MaxTokens: 2048,
+		Messages:  append([]claudeMessage{{Role: "user", Content: prompt}}, prefill...),
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", e.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("%w: claude request failed: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("%w: claude error (%d): %s", ErrUnavailable, resp.StatusCode, string(body))
+	}
+
+	var result claudeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String(), nil
+}
+
+// spend counts a request against the daily budget
+func (e *ClaudeExtractor) spend() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	today := time.Now().Format("2006-01-02")
+	if e.day != today {
+		e.day, e.requests = today, 0
+	}
+	if e.DailyBudget > 0 && e.requests >= e.DailyBudget {
+		return ErrOverBudget
+	}
+	e.requests++
+	return nil
+}
@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// maxExamples caps the few-shot examples added to a prompt
+const maxExamples = 6
+
+// ExampleSource supplies memories the user has accepted, shown to the model
+// as few-shot examples so extraction picks up their style and typing
+type ExampleSource interface {
+	FewShotExamples(source models.SourceType, types []models.MemoryType, limit int) ([]models.Memory, error)
+}
+
+// SourceTypeFor maps an event type to the source its memories are filed under
+func SourceTypeFor(eventType string) models.SourceType {
+	switch {
+	case strings.HasPrefix(eventType, "git_"), eventType == "build_fix":
+		return models.SourceTypeGit
+	case eventType == "file_change", eventType == "config_change":
+		return models.SourceTypeFile
+	case eventType == "terminal_cmd":
+		return models.SourceTypeTerminal
+	case eventType == "session_note":
+		return models.SourceTypeChat
+	case eventType == "document":
+		return models.SourceTypeDocument
+	default:
+		return models.SourceTypeGit
+	}
+}
+
+// BatchSourceType returns the source most of the events map to
+func BatchSourceType(events []models.Event) models.SourceType {
+	counts := make(map[models.SourceType]int)
+	best := models.SourceTypeGit
+	for _, e := range events {
+		t := SourceTypeFor(e.Type)
+		counts[t]++
+		if counts[t] > counts[best] {
+			best = t
+		}
+	}
+	return best
+}
+
+// formatExamples renders accepted memories as a prompt section, or "" when
+// there are none
+func formatExamples(src ExampleSource, source models.SourceType, types ...models.MemoryType) string {
+	if src == nil {
+		return ""
+	}
+	memories, err := src.FewShotExamples(source, types, maxExamples)
+	if err != nil || len(memories) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Examples of memories this developer accepted before; match their style, detail and choice of type:\n")
+	for _, m := range memories {
+		example, _ := json.Marshal(ExtractedMemory{
+			Type:       string(m.Type),
+			Content:    m.Content,
+			Summary:    m.Summary,
+			Confidence: m.Confidence,
+			Topics:     m.Topics,
+		})
+		sb.Write(example)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
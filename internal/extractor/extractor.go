@@ -2,9 +2,13 @@ package extractor
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
@@ -17,6 +21,18 @@ type Extractor interface {
 	Extract(events []models.Event) ([]ExtractedMemory, error)
 }
 
+// ErrUnavailable marks errors caused by the provider being unreachable,
+// erroring or over budget, as opposed to returning unusable output.
+// Extraction that fails this way can be retried later.
+var ErrUnavailable = errors.New("provider unavailable")
+
+// Completer generates free-form text with the extraction model, for features
+// that summarize or answer rather than extract (standup, ask, ...)
+type Completer interface {
+	Complete(prompt string) (string, error)
+	CompleteJSON(prompt string) (string, error)
+}
+
 // ExtractedMemory represents a memory extracted by the LLM
 type ExtractedMemory struct {
 	Type       string   `json:"type"`
@@ -24,6 +40,40 @@ type ExtractedMemory struct {
 	Summary    string   `json:"summary"`
 	Confidence float64  `json:"confidence"`
 	Topics     []string `json:"topics"`
+
+	// Provider is the name of the provider that produced the memory
+	Provider string `json:"-"`
+
+	// Model and PromptHash identify the model and prompt template behind
+	// the memory, when it came from a model
+	Model         string `json:"-"`
+	PromptHash    string `json:"-"`
+	PromptVersion int    `json:"-"`
+
+	// RunID is the extraction run it came from, if runs are recorded
+	RunID string `json:"-"`
+}
+
+// RunRecorder stores extraction runs, assigning their IDs
+type RunRecorder interface {
+	RecordExtractionRun(run *models.ExtractionRun) error
+}
+
+// modeler is implemented by extractors backed by a named model
+type modeler interface {
+	Model() string
+}
+
+// PromptVersion is the generation of the extraction prompts. Bump it when
+// the prompts change enough that memories extracted with earlier versions
+// are worth re-extracting; wording tweaks only change their PromptHash.
+const PromptVersion = 1
+
+// PromptHash identifies a prompt template, so memories can be traced to
+// the prompt wording that produced them
+func PromptHash(template string) string {
+	sum := sha256.Sum256([]byte(template))
+	return hex.EncodeToString(sum[:6])
 }
 
 // OllamaExtractor uses Ollama for memory extraction
@@ -31,6 +81,7 @@ type OllamaExtractor struct {
 	endpoint string
 	model    string
 	client   *http.Client
+	settings
 }
 
 // NewOllamaExtractor creates a new Ollama-based extractor
@@ -67,7 +118,87 @@ Rules:
 - Be specific: include WHY decisions were made if evident
 - A batch of events might produce 0-3 memories (don't force it)
 
-Events to analyze:
+%sEvents to analyze:
+%s
+
+Respond ONLY with valid JSON in this exact format (no markdown, no explanation):
+{"memories": [{"type": "decision", "content": "...", "summary": "...", "confidence": 0.85, "topics": ["topic1", "topic2"]}]}
+
+If no memories worth extracting, respond: {"memories": []}`
+
+const tagExtractionPrompt = `You are a memory extraction system for a software developer.
+The following events are git tags and releases. Releases mark milestones and
+usually follow decisions worth remembering.
+
+For each tag, extract at most one memory recording what shipped, in the form
+"Version X shipped with Y" where Y is the most notable change (taken from the
+annotation or changelog). Use type "fact", or "decision" if the annotation
+explains why something was done.
+
+For each memory, provide:
+- type: One of: decision, pattern, fact, preference, mistake, learning
+- content: The full memory (1-3 sentences, be specific)
+- summary: Short version (under 80 characters)
+- confidence: 0.0-1.0 how confident this is worth remembering
+- topics: Array of relevant topics (2-5 keywords), including the version
+
+Skip tags that look like throwaway markers (e.g. "tmp", "backup").
+
+%sEvents to analyze:
+%s
+
+Respond ONLY with valid JSON in this exact format (no markdown, no explanation):
+{"memories": [{"type": "fact", "content": "...", "summary": "...", "confidence": 0.85, "topics": ["release", "v1.2.0"]}]}
+
+If no memories worth extracting, respond: {"memories": []}`
+
+const preferenceExtractionPrompt = `You are a memory extraction system for a software developer.
+The following events are changes to editor, formatter, linter, dotfile or AI
+tool settings. Settings like these encode the developer's personal
+preferences.
+
+Extract the preferences they express, e.g. "Prefers tabs over spaces (tab
+width 4)", "Uses single quotes and no semicolons in JavaScript", "Prefers
+ripgrep over grep". State each as a durable preference, not as a file edit.
+Ignore machine-specific values (paths, usernames, tokens, colors, fonts).
+
+For each memory, provide:
+- type: Always "preference"
+- content: The preference (1-2 sentences, be specific)
+- summary: Short version (under 80 characters)
+- confidence: 0.0-1.0 how confident this reflects a deliberate preference
+- topics: Array of relevant topics (2-5 keywords), including the language or tool
+
+%sEvents to analyze:
+%s
+
+Respond ONLY with valid JSON in this exact format (no markdown, no explanation):
+{"memories": [{"type": "preference", "content": "...", "summary": "...", "confidence": 0.85, "topics": ["formatting", "go"]}]}
+
+If no memories worth extracting, respond: {"memories": []}`
+
+const documentExtractionPrompt = `You are a memory extraction system for a software developer.
+The following is a section of a document the developer chose to remember,
+such as a design doc, postmortem, RFC or runbook.
+
+Extract the durable knowledge it records: decisions and the reasons for
+them, constraints, conventions, incident root causes and their lessons, and
+facts someone working on this code would need. Each memory must stand on
+its own without the document, so name the system or component it concerns.
+Skip background, meeting logistics, open questions and options the document
+rejects, unless rejecting one is itself a decision worth remembering.
+
+For each memory, provide:
+- type: One of: decision, pattern, fact, preference, mistake, learning
+  (a root cause is a "mistake", what was learned from it a "learning")
+- content: The full memory (1-3 sentences, be specific)
+- summary: Short version (under 80 characters)
+- confidence: 0.0-1.0 how confident this is worth remembering
+- topics: Array of relevant topics (2-5 keywords)
+
+A section might produce 0-5 memories (don't force it).
+
+%sDocument section to analyze:
 %s
 
 Respond ONLY with valid JSON in this exact format (no markdown, no explanation):
@@ -79,7 +210,7 @@ type ollamaGenerateRequest struct {
 	Model  string `json:"model"`
 	Prompt string `json:"prompt"`
 	Stream bool   `json:"stream"`
-	Format string `json:"format"`
+	Format string `json:"format,omitempty"`
 }
 
 type ollamaGenerateResponse struct {
@@ -89,67 +220,237 @@ type ollamaGenerateResponse struct {
 
 // Extract analyzes events and extracts memories
 func (e *OllamaExtractor) Extract(events []models.Event) ([]ExtractedMemory, error) {
+	return extractWith(e, &e.settings, events)
+}
+
+// extractWith runs the extraction prompts through any completer. Tag events
+// are extracted separately with a release-focused prompt, config changes
+// with a preference-focused one, and ingested documents with their own. Each prompt gets few-shot examples
+// matching its events, if an example source is set.
+func extractWith(c Completer, s *settings, events []models.Event) ([]ExtractedMemory, error) {
 	if len(events) == 0 {
 		return nil, nil
 	}
 
+	var tags, configs, docs, others []models.Event
+	for _, ev := range events {
+		switch ev.Type {
+		case "git_tag":
+			tags = append(tags, ev)
+		case "config_change":
+			configs = append(configs, ev)
+		case "document":
+			docs = append(docs, ev)
+		default:
+			others = append(others, ev)
+		}
+	}
+
+	src, minConfidence := s.current()
+	runs := s.recorder()
+
+	var memories []ExtractedMemory
+	if len(others) > 0 {
+		examples := formatExamples(src, BatchSourceType(others))
+		extracted, err := generate(c, extractionPrompt, examples, others, minConfidence, runs)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, extracted...)
+	}
+	if len(tags) > 0 {
+		examples := formatExamples(src, models.SourceTypeGit, models.MemoryTypeFact, models.MemoryTypeDecision)
+		extracted, err := generate(c, tagExtractionPrompt, examples, tags, minConfidence, runs)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, extracted...)
+	}
+	if len(configs) > 0 {
+		examples := formatExamples(src, models.SourceTypeFile, models.MemoryTypePreference)
+		extracted, err := generate(c, preferenceExtractionPrompt, examples, configs, minConfidence, runs)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range extracted {
+			m.Type = string(models.MemoryTypePreference)
+			memories = append(memories, m)
+		}
+	}
+	if len(docs) > 0 {
+		examples := formatExamples(src, models.SourceTypeDocument)
+		extracted, err := generate(c, documentExtractionPrompt, examples, docs, minConfidence, runs)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, extracted...)
+	}
+
+	return memories, nil
+}
+
+// generate runs one extraction prompt over the events, keeping memories at
+// or above minConfidence. The run is recorded to runs, if set, whether or
+// not it succeeds.
+func generate(c Completer, promptTemplate, examples string, events []models.Event, minConfidence float64, runs RunRecorder) ([]ExtractedMemory, error) {
+	run := &models.ExtractionRun{
+		StartedAt:     time.Now(),
+		PromptHash:    PromptHash(promptTemplate),
+		PromptVersion: PromptVersion,
+	}
+	if mc, ok := c.(modeler); ok {
+		run.Model = mc.Model()
+	}
+	for _, e := range events {
+		run.EventIDs = append(run.EventIDs, e.ID)
+	}
+
 	// Format events for the prompt
 	eventsText := formatEvents(events)
-	prompt := fmt.Sprintf(extractionPrompt, eventsText)
+	prompt := fmt.Sprintf(promptTemplate, examples, eventsText)
+
+	filtered, err := complete(c, prompt, minConfidence, run)
+	if err != nil {
+		run.Error = err.Error()
+	}
 
+	if runs != nil {
+		if recErr := runs.RecordExtractionRun(run); recErr != nil {
+			log.Printf("Failed to record extraction run: %v", recErr)
+		}
+		for i := range filtered {
+			filtered[i].RunID = run.ID
+		}
+	}
+	return filtered, err
+}
+
+// complete sends an extraction prompt and parses the memories at or above
+// minConfidence, noting the timing, response and outcome in run
+func complete(c Completer, prompt string, minConfidence float64, run *models.ExtractionRun) ([]ExtractedMemory, error) {
+	response, err := c.CompleteJSON(prompt)
+	run.Latency = time.Since(run.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(response))
+	run.ResponseHash = hex.EncodeToString(sum[:8])
+
+	// Parse the JSON response
+	var extracted struct {
+		Memories []ExtractedMemory `json:"memories"`
+	}
+	if err := ParseJSON(response, &extracted); err != nil {
+		return nil, err
+	}
+
+	// Filter by confidence
+	var filtered []ExtractedMemory
+	for _, m := range extracted.Memories {
+		proposed := models.RunMemory{Type: m.Type, Summary: m.Summary, Confidence: m.Confidence}
+		if m.Confidence < minConfidence {
+			run.Rejected = append(run.Rejected, proposed)
+			continue
+		}
+		run.Accepted = append(run.Accepted, proposed)
+		m.Model, m.PromptHash, m.PromptVersion = run.Model, run.PromptHash, run.PromptVersion
+		filtered = append(filtered, m)
+	}
+	return filtered, nil
+}
+
+// Model returns the name of the Ollama model
+func (e *OllamaExtractor) Model() string {
+	return e.model
+}
+
+// Complete sends a free-form prompt to the model and returns its response
+func (e *OllamaExtractor) Complete(prompt string) (string, error) {
+	return e.post(prompt, "")
+}
+
+// CompleteJSON sends a prompt that asks for JSON output and returns the raw
+// response, constrained to valid JSON by Ollama
+func (e *OllamaExtractor) CompleteJSON(prompt string) (string, error) {
+	return e.post(prompt, "json")
+}
+
+// post runs a single non-streaming generation
+func (e *OllamaExtractor) post(prompt, format string) (string, error) {
 	req := ollamaGenerateRequest{
 		Model:  e.model,
 		Prompt: prompt,
 		Stream: false,
-		Format: "json",
+		Format: format,
 	}
 
 	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	resp, err := e.client.Post(e.endpoint+"/api/generate", "application/json", bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("ollama request failed: %w", err)
+		return "", fmt.Errorf("%w: ollama request failed: %v", ErrUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama error: %s", string(body))
+		return "", fmt.Errorf("%w: ollama error: %s", ErrUnavailable, string(body))
 	}
 
 	var result ollamaGenerateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Parse the JSON response
-	var extracted struct {
-		Memories []ExtractedMemory `json:"memories"`
-	}
+	return result.Response, nil
+}
 
+// ParseJSON decodes an LLM response into v, tolerating markdown code fences
+func ParseJSON(response string, v interface{}) error {
 	// Clean up response (sometimes LLM adds markdown)
-	response := strings.TrimSpace(result.Response)
+	response = strings.TrimSpace(response)
 	response = strings.TrimPrefix(response, "```json")
 	response = strings.TrimPrefix(response, "```")
 	response = strings.TrimSuffix(response, "```")
 	response = strings.TrimSpace(response)
 
-	if err := json.Unmarshal([]byte(response), &extracted); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response: %w (response: %s)", err, response)
+	if err := json.Unmarshal([]byte(response), v); err != nil {
+		return fmt.Errorf("failed to parse LLM response: %w (response: %s)", err, response)
 	}
+	return nil
+}
 
-	// Filter by confidence
-	var filtered []ExtractedMemory
-	for _, m := range extracted.Memories {
-		if m.Confidence >= 0.6 {
-			filtered = append(filtered, m)
+// stringList reads a list of strings from event data, whether as captured
+// or as decoded from JSON by the journal or the store
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var out []string
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
 		}
+		return out
 	}
+	return nil
+}
 
-	return filtered, nil
+// intValue reads a number from event data, whether as captured (int) or
+// as decoded from JSON (float64)
+func intValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
 }
 
 func formatEvents(events []models.Event) string {
@@ -157,13 +458,16 @@ func formatEvents(events []models.Event) string {
 
 	for i, e := range events {
 		sb.WriteString(fmt.Sprintf("Event %d [%s] at %s:\n", i+1, e.Type, e.Timestamp.Format("2006-01-02 15:04")))
+		if host, ok := e.Data["host"].(string); ok {
+			sb.WriteString(fmt.Sprintf("  On remote host: %s\n", host))
+		}
 
 		switch e.Type {
 		case "git_commit":
 			if msg, ok := e.Data["message"].(string); ok {
 				sb.WriteString(fmt.Sprintf("  Commit: %s\n", msg))
 			}
-			if files, ok := e.Data["files"].([]string); ok && len(files) > 0 {
+			if files := stringList(e.Data["files"]); len(files) > 0 {
 				sb.WriteString(fmt.Sprintf("  Files: %s\n", strings.Join(files[:min(5, len(files))], ", ")))
 			}
 			if diff, ok := e.Data["diff"].(string); ok && len(diff) > 0 {
@@ -174,11 +478,79 @@ func formatEvents(events []models.Event) string {
 				sb.WriteString(fmt.Sprintf("  Diff summary: %s\n", diff))
 			}
 
+		case "git_commit_range":
+			if count, ok := intValue(e.Data["count"]); ok {
+				sb.WriteString(fmt.Sprintf("  %d commits made while MemoryPilot wasn't running\n", count))
+			}
+			if messages := stringList(e.Data["messages"]); len(messages) > 0 {
+				sb.WriteString(fmt.Sprintf("  Commits: %s\n", strings.Join(messages, "; ")))
+			}
+			if diff, ok := e.Data["diff"].(string); ok && len(diff) > 0 {
+				sb.WriteString(fmt.Sprintf("  Diff summary: %s\n", diff))
+			}
+
+		case "git_tag":
+			if tag, ok := e.Data["tag"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Tag: %s\n", tag))
+			}
+			if release, ok := e.Data["release"].(bool); ok && release {
+				sb.WriteString("  Looks like a release\n")
+			}
+			if annotation, ok := e.Data["annotation"].(string); ok && len(annotation) > 0 {
+				sb.WriteString(fmt.Sprintf("  Annotation: %s\n", annotation))
+			}
+			if changes := stringList(e.Data["changes"]); len(changes) > 0 {
+				if prev, ok := e.Data["previousTag"].(string); ok && prev != "" {
+					sb.WriteString(fmt.Sprintf("  Changes since %s: %s\n", prev, strings.Join(changes, "; ")))
+				} else {
+					sb.WriteString(fmt.Sprintf("  Changes: %s\n", strings.Join(changes, "; ")))
+				}
+			}
+
+		case "config_change":
+			if path, ok := e.Data["path"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Settings file: %s\n", path))
+			}
+			if content, ok := e.Data["content"].(string); ok && len(content) > 0 {
+				if len(content) > 1500 {
+					content = content[:1500] + "..."
+				}
+				sb.WriteString(fmt.Sprintf("  Contents:\n%s\n", content))
+			}
+
+		case "git_stash":
+			if msg, ok := e.Data["message"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Stashed work: %s\n", msg))
+			}
+
+		case "build_fix":
+			if cmds := stringList(e.Data["failedCommands"]); len(cmds) > 0 {
+				sb.WriteString(fmt.Sprintf("  Failing build/test: %s\n", strings.Join(cmds, ", ")))
+			}
+			if output, ok := e.Data["failureOutput"].(string); ok && len(output) > 0 {
+				if len(output) > 300 {
+					output = output[:300] + "..."
+				}
+				sb.WriteString(fmt.Sprintf("  Failure output: %s\n", output))
+			}
+			if files := stringList(e.Data["changedFiles"]); len(files) > 0 {
+				sb.WriteString(fmt.Sprintf("  Then changed: %s\n", strings.Join(files[:min(5, len(files))], ", ")))
+			}
+			if msg, ok := e.Data["commitMessage"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Fixed by commit: %s\n", msg))
+			}
+			sb.WriteString("  (A mistake and its fix: extract a \"mistake\" memory describing what broke and how it was fixed)\n")
+
 		case "file_change":
 			if path, ok := e.Data["path"].(string); ok {
 				sb.WriteString(fmt.Sprintf("  File: %s\n", path))
 			}
-			if content, ok := e.Data["content"].(string); ok && len(content) > 0 {
+			if diff, ok := e.Data["diff"].(string); ok && len(diff) > 0 {
+				if len(diff) > 1500 {
+					diff = diff[:1500] + "..."
+				}
+				sb.WriteString(fmt.Sprintf("  Changes:\n%s\n", diff))
+			} else if content, ok := e.Data["content"].(string); ok && len(content) > 0 {
 				// Truncate content
 				if len(content) > 300 {
 					content = content[:300] + "..."
@@ -190,6 +562,39 @@ func formatEvents(events []models.Event) string {
 			if cmd, ok := e.Data["command"].(string); ok {
 				sb.WriteString(fmt.Sprintf("  Command: %s\n", cmd))
 			}
+			if cwd, ok := e.Data["cwd"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Directory: %s\n", cwd))
+			}
+
+		case "session_note":
+			if content, ok := e.Data["content"].(string); ok {
+				noteType, _ := e.Data["type"].(string)
+				sb.WriteString(fmt.Sprintf("  Noted by an AI assistant during a conversation (%s): %s\n", noteType, content))
+			}
+			if topics := stringList(e.Data["topics"]); len(topics) > 0 {
+				sb.WriteString(fmt.Sprintf("  Topics: %s\n", strings.Join(topics, ", ")))
+			}
+			sb.WriteString("  (Scratch context from one conversation: extract a memory only if it will still matter in later ones)\n")
+
+		case "document":
+			title, _ := e.Data["title"].(string)
+			source, _ := e.Data["source"].(string)
+			sb.WriteString(fmt.Sprintf("  Document: %s (%s)\n", title, source))
+			if section, ok := e.Data["section"].(string); ok && section != "" {
+				sb.WriteString(fmt.Sprintf("  Section: %s\n", section))
+			}
+			if text, ok := e.Data["text"].(string); ok {
+				sb.WriteString(fmt.Sprintf("  Text:\n%s\n", text))
+			}
+
+		case "tmux_cmd":
+			if cmd, ok := e.Data["command"].(string); ok {
+				repl, _ := e.Data["repl"].(string)
+				sb.WriteString(fmt.Sprintf("  Command (%s): %s\n", repl, cmd))
+			}
+		}
+		if e.Repeats > 0 {
+			sb.WriteString(fmt.Sprintf("  Repeated %d more times\n", e.Repeats))
 		}
 
 		sb.WriteString("\n")
@@ -0,0 +1,165 @@
+package extractor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// fakeConfidence is the confidence of every memory FakeExtractor produces
+const fakeConfidence = 0.8
+
+// fakeCommitRules map keywords in a commit message to the memory type it
+// suggests, checked in order; commits matching none are routine
+var fakeCommitRules = []struct {
+	memType  models.MemoryType
+	keywords []string
+}{
+	{models.MemoryTypeMistake, []string{"fix", "bug", "revert", "hotfix"}},
+	{models.MemoryTypeDecision, []string{"switch", "migrate", "replace", "instead", "adopt", "move to", "drop"}},
+	{models.MemoryTypePattern, []string{"refactor", "extract", "convention", "pattern"}},
+	{models.MemoryTypeLearning, []string{"learned", "turns out", "workaround", "gotcha"}},
+}
+
+// fakeStopWords are left out of derived topics
+var fakeStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true,
+	"that": true, "this": true, "when": true, "instead": true, "because": true,
+}
+
+// FakeExtractor extracts memories with fixed keyword rules instead of a
+// model, so the pipeline can run end to end in tests and demos. The same
+// events always produce the same memories.
+type FakeExtractor struct{}
+
+// Extract turns notable commits, tags, settings changes, build fixes,
+// session notes and document sections into memories
+func (e *FakeExtractor) Extract(events []models.Event) ([]ExtractedMemory, error) {
+	var memories []ExtractedMemory
+	for _, ev := range events {
+		if m, ok := fakeExtract(ev); ok {
+			memories = append(memories, m)
+		}
+	}
+	return memories, nil
+}
+
+func fakeExtract(ev models.Event) (ExtractedMemory, bool) {
+	str := func(key string) string {
+		s, _ := ev.Data[key].(string)
+		return strings.TrimSpace(s)
+	}
+
+	switch ev.Type {
+	case "git_commit":
+		msg := str("message")
+		if memType, ok := fakeCommitType(msg); ok {
+			return fakeMemory(memType, msg), true
+		}
+
+	case "session_note":
+		// Notes typed as more than context are durable, as are context
+		// notes that read like a notable commit
+		content := str("content")
+		if memType := models.MemoryType(str("type")); content != "" && memType != models.MemoryTypeContext {
+			return fakeMemory(memType, content), true
+		}
+		if memType, ok := fakeCommitType(content); ok {
+			return fakeMemory(memType, content), true
+		}
+
+	case "git_tag":
+		if tag := str("tag"); tag != "" {
+			content := fmt.Sprintf("Version %s shipped", tag)
+			if note := str("annotation"); note != "" {
+				content += " with " + firstLine(note)
+			}
+			m := fakeMemory(models.MemoryTypeFact, content)
+			m.Topics = append([]string{"release", tag}, m.Topics...)
+			return m, true
+		}
+
+	case "config_change":
+		if path := str("path"); path != "" {
+			return fakeMemory(models.MemoryTypePreference, fmt.Sprintf("Customizes %s settings", filepath.Base(path))), true
+		}
+
+	case "document":
+		// The first sentence of the section that reads like a notable commit
+		for _, sentence := range strings.FieldsFunc(str("text"), func(r rune) bool {
+			return r == '.' || r == '\n' || r == '!' || r == '?'
+		}) {
+			sentence = strings.TrimSpace(strings.TrimLeft(sentence, "#-* "))
+			if memType, ok := fakeCommitType(sentence); ok && len(sentence) > 20 {
+				return fakeMemory(memType, sentence), true
+			}
+		}
+
+	case "build_fix":
+		cmds := stringList(ev.Data["failedCommands"])
+		content := "A failing build was fixed"
+		if len(cmds) > 0 {
+			content = fmt.Sprintf("%s failed and was fixed", strings.Join(cmds, ", "))
+		}
+		if msg := str("commitMessage"); msg != "" {
+			content += " by: " + msg
+		}
+		return fakeMemory(models.MemoryTypeMistake, content), true
+	}
+	return ExtractedMemory{}, false
+}
+
+// fakeCommitType returns the memory type the first matching commit rule
+// suggests for a text
+func fakeCommitType(text string) (models.MemoryType, bool) {
+	lower := strings.ToLower(text)
+	for _, rule := range fakeCommitRules {
+		for _, kw := range rule.keywords {
+			if strings.Contains(lower, kw) {
+				return rule.memType, true
+			}
+		}
+	}
+	return "", false
+}
+
+func fakeMemory(memType models.MemoryType, content string) ExtractedMemory {
+	summary := firstLine(content)
+	if len(summary) > 80 {
+		summary = summary[:77] + "..."
+	}
+	return ExtractedMemory{
+		Type:       string(memType),
+		Content:    content,
+		Summary:    summary,
+		Confidence: fakeConfidence,
+		Topics:     fakeTopics(content),
+	}
+}
+
+// fakeTopics picks up to three distinctive words of the text
+func fakeTopics(text string) []string {
+	var topics []string
+	seen := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) < 4 || fakeStopWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		topics = append(topics, w)
+		if len(topics) == 3 {
+			break
+		}
+	}
+	return topics
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,383 @@
+package extractor
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ruleConfidence is the confidence of memories RuleExtractor derives;
+// they say what changed but rarely why
+const ruleConfidence = 0.6
+
+// ruleMaxEach is how many adopted or dropped dependencies of a manifest
+// get a memory each; more are listed in one memory
+const ruleMaxEach = 3
+
+// RuleExtractor derives basic memories from events without a model:
+// dependencies adopted or dropped in manifest diffs, and bugs fixed or
+// commits reverted from commit messages. It's meant to follow the model
+// providers in a chain, so something is remembered while none is
+// reachable.
+type RuleExtractor struct{}
+
+// Extract derives memories from manifest changes and fix or revert commits
+func (e *RuleExtractor) Extract(events []models.Event) ([]ExtractedMemory, error) {
+	var memories []ExtractedMemory
+	seen := make(map[string]bool)
+	add := func(m ExtractedMemory) {
+		if !seen[m.Content] {
+			seen[m.Content] = true
+			memories = append(memories, m)
+		}
+	}
+
+	for _, ev := range events {
+		str := func(key string) string {
+			s, _ := ev.Data[key].(string)
+			return strings.TrimSpace(s)
+		}
+
+		switch ev.Type {
+		case "file_change", "config_change":
+			if diff := str("diff"); diff != "" {
+				for _, m := range dependencyMemories(diff) {
+					add(m)
+				}
+			}
+
+		case "git_commit":
+			if m, ok := fixMemory(str("message"), stringList(ev.Data["files"])); ok {
+				add(m)
+			}
+			if diff := str("manifestDiff"); diff != "" {
+				for _, m := range dependencyMemories(diff) {
+					add(m)
+				}
+			}
+		}
+	}
+	return memories, nil
+}
+
+// Commit messages reporting a fix, and the conventional-commit prefix
+// ("fix(parser): ...") some carry
+var (
+	fixKeywords   = regexp.MustCompile(`(?i)\b(fix(es|ed)?|bug ?fix(es)?|hotfix(es)?)\b`)
+	revertSubject = regexp.MustCompile(`^(?i)revert(ed|s)?\b:?\s*"?(.*?)"?$`)
+	conventional  = regexp.MustCompile(`^(\w+)(?:\(([^)]+)\))?!?:\s*(.+)$`)
+)
+
+// fixMemory turns a commit fixing a bug, or reverting one, into a mistake
+// memory naming the area it touched: the commit's conventional scope, or
+// else the directory its files share
+func fixMemory(message string, files []string) (ExtractedMemory, bool) {
+	subject := firstLine(message)
+	if subject == "" {
+		return ExtractedMemory{}, false
+	}
+	body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), subject))
+	if len(body) > 300 {
+		body = body[:297] + "..."
+	}
+
+	var content, area string
+	if m := revertSubject.FindStringSubmatch(subject); m != nil && m[2] != "" {
+		content = fmt.Sprintf("Reverted %q", m[2])
+	} else {
+		if m := conventional.FindStringSubmatch(subject); m != nil {
+			switch strings.ToLower(m[1]) {
+			case "docs", "style", "test", "tests":
+				return ExtractedMemory{}, false
+			case "fix", "bugfix", "hotfix":
+				area, subject = m[2], m[3]
+			default:
+				if !fixKeywords.MatchString(m[3]) {
+					return ExtractedMemory{}, false
+				}
+				area, subject = m[2], m[3]
+			}
+		} else if !fixKeywords.MatchString(subject) {
+			return ExtractedMemory{}, false
+		}
+		if area == "" {
+			area = commonDir(files)
+		}
+
+		content = "Fixed a bug"
+		if area != "" {
+			content += " in " + area
+		}
+		content += ": " + subject
+	}
+	summary := content
+	if len(summary) > 80 {
+		summary = summary[:77] + "..."
+	}
+	if body != "" {
+		content += "\n\n" + body
+	}
+
+	topics := []string{"bugfix"}
+	if area != "" {
+		topics = append(topics, strings.ToLower(path.Base(area)))
+	}
+	for _, t := range fakeTopics(subject) {
+		if !slices.Contains(topics, t) {
+			topics = append(topics, t)
+		}
+	}
+	return ExtractedMemory{
+		Type:       string(models.MemoryTypeMistake),
+		Content:    content,
+		Summary:    summary,
+		Confidence: ruleConfidence,
+		Topics:     topics,
+	}, true
+}
+
+// commonDir returns the deepest directory all files are in, the file
+// itself when there's one, or "" when they share none
+func commonDir(files []string) string {
+	var paths []string
+	for _, f := range files {
+		if f = strings.TrimSpace(f); f != "" {
+			paths = append(paths, f)
+		}
+	}
+	switch len(paths) {
+	case 0:
+		return ""
+	case 1:
+		return paths[0]
+	}
+
+	common := strings.Split(path.Dir(paths[0]), "/")
+	for _, p := range paths[1:] {
+		parts := strings.Split(path.Dir(p), "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	dir := strings.Join(common, "/")
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// dependency is a dependency line of a manifest
+type dependency struct {
+	name, version string
+}
+
+// manifestEcosystems are the manifests whose dependency lines are read,
+// by file name
+var manifestEcosystems = map[string]string{
+	"go.mod":           "go",
+	"package.json":     "npm",
+	"requirements.txt": "python",
+	"Cargo.toml":       "rust",
+	"Gemfile":          "ruby",
+}
+
+// dependencyMemories reads a unified diff, of one file or several, and
+// returns decision memories for the dependencies its manifests gained or
+// lost. A dependency both removed and added changed version, which isn't
+// remembered.
+func dependencyMemories(diff string) []ExtractedMemory {
+	type change struct {
+		added, removed map[string]string
+	}
+	changes := make(map[string]*change)
+	var order []string
+	file := ""
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			name := strings.TrimSpace(line[4:])
+			if name == "/dev/null" {
+				continue
+			}
+			name = strings.TrimPrefix(strings.TrimPrefix(name, "a/"), "b/")
+			file = name
+			continue
+		case strings.HasPrefix(line, "diff --git "):
+			file = ""
+			continue
+		}
+		if file == "" || line == "" || (line[0] != '+' && line[0] != '-') {
+			continue
+		}
+		dep, ok := parseDependency(path.Base(file), line[1:])
+		if !ok {
+			continue
+		}
+		c := changes[file]
+		if c == nil {
+			c = &change{added: make(map[string]string), removed: make(map[string]string)}
+			changes[file] = c
+			order = append(order, file)
+		}
+		if line[0] == '+' {
+			c.added[dep.name] = dep.version
+		} else {
+			c.removed[dep.name] = dep.version
+		}
+	}
+
+	var memories []ExtractedMemory
+	for _, file := range order {
+		c := changes[file]
+		var adopted, dropped []dependency
+		for name, version := range c.added {
+			if _, ok := c.removed[name]; !ok {
+				adopted = append(adopted, dependency{name, version})
+			}
+		}
+		for name, version := range c.removed {
+			if _, ok := c.added[name]; !ok {
+				dropped = append(dropped, dependency{name, version})
+			}
+		}
+		ecosystem := manifestEcosystems[path.Base(file)]
+		memories = append(memories, dependencyChange("Adopted", "in", file, ecosystem, adopted)...)
+		memories = append(memories, dependencyChange("Dropped", "from", file, ecosystem, dropped)...)
+	}
+	return memories
+}
+
+// dependencyChange describes dependencies adopted or dropped in a manifest,
+// one memory each, or one for all when there are many
+func dependencyChange(verb, prep, file, ecosystem string, deps []dependency) []ExtractedMemory {
+	if len(deps) == 0 {
+		return nil
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+
+	memory := func(content, summary string, topics ...string) ExtractedMemory {
+		if len(summary) > 80 {
+			summary = summary[:77] + "..."
+		}
+		return ExtractedMemory{
+			Type:       string(models.MemoryTypeDecision),
+			Content:    content,
+			Summary:    summary,
+			Confidence: ruleConfidence,
+			Topics:     append([]string{"dependencies", ecosystem}, topics...),
+		}
+	}
+
+	if len(deps) <= ruleMaxEach {
+		var memories []ExtractedMemory
+		for _, d := range deps {
+			content := fmt.Sprintf("%s dependency %s", verb, d.name)
+			if d.version != "" {
+				content += " " + d.version
+			}
+			content += fmt.Sprintf(" %s %s", prep, file)
+			summary := fmt.Sprintf("%s dependency %s", verb, d.name)
+			memories = append(memories, memory(content, summary, dependencyTopic(d.name)))
+		}
+		return memories
+	}
+
+	var names []string
+	for _, d := range deps {
+		names = append(names, d.name)
+	}
+	listed := names
+	if len(listed) > 10 {
+		listed = append(listed[:10:10], fmt.Sprintf("%d more", len(names)-10))
+	}
+	content := fmt.Sprintf("%s %d dependencies %s %s: %s", verb, len(deps), prep, file, strings.Join(listed, ", "))
+	return []ExtractedMemory{memory(content, fmt.Sprintf("%s %d dependencies %s %s", verb, len(deps), prep, file))}
+}
+
+// dependencyTopic shortens a dependency name to a topic, e.g.
+// "github.com/jackc/pgx/v5" to "pgx" and "@types/node" to "node"
+func dependencyTopic(name string) string {
+	parts := strings.Split(strings.ToLower(name), "/")
+	last := parts[len(parts)-1]
+	if len(parts) > 1 && len(last) > 1 && last[0] == 'v' && strings.Trim(last[1:], "0123456789") == "" {
+		last = parts[len(parts)-2]
+	}
+	return last
+}
+
+// Dependency lines of each manifest format
+var (
+	goRequire      = regexp.MustCompile(`^(?:require\s+)?([^\s()]+\.[^\s()]+)\s+(v\S+)\s*(//.*)?$`)
+	npmDependency  = regexp.MustCompile(`^"(@?[^"\s]+)"\s*:\s*"([^"]*)",?$`)
+	npmVersion     = regexp.MustCompile(`^([\^~=v]|[<>]=?)?\s*\d|^(\*|latest|next)$|^(workspace|npm|file|link|github|git\+\w+):`)
+	pipRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_.\-]*)(\[[^\]]*\])?\s*(.*)$`)
+	cargoEntry     = regexp.MustCompile(`^([A-Za-z0-9_\-]+)\s*=\s*("([^"]*)"|\{.*\})\s*$`)
+	cargoVersion   = regexp.MustCompile(`version\s*=\s*"([^"]*)"`)
+	gemEntry       = regexp.MustCompile(`^gem\s+['"]([^'"]+)['"](?:\s*,\s*['"]([^'"]+)['"])?`)
+)
+
+// nonDependencyKeys are keys of package.json and Cargo.toml that look like
+// dependencies but aren't
+var nonDependencyKeys = map[string]bool{
+	"name": true, "version": true, "node": true, "npm": true, "yarn": true, "pnpm": true,
+	"edition": true, "rust-version": true, "resolver": true, "license": true,
+	"description": true, "repository": true, "homepage": true, "readme": true,
+	"documentation": true, "build": true, "publish": true, "default": true,
+}
+
+// parseDependency reads a dependency from a line of the named manifest
+func parseDependency(manifest, line string) (dependency, bool) {
+	line = strings.TrimSpace(line)
+	switch manifest {
+	case "go.mod":
+		m := goRequire.FindStringSubmatch(line)
+		if m == nil || strings.Contains(m[3], "indirect") {
+			return dependency{}, false
+		}
+		return dependency{m[1], m[2]}, true
+
+	case "package.json":
+		m := npmDependency.FindStringSubmatch(line)
+		if m == nil || nonDependencyKeys[m[1]] || !npmVersion.MatchString(m[2]) {
+			return dependency{}, false
+		}
+		return dependency{m[1], m[2]}, true
+
+	case "requirements.txt":
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			return dependency{}, false
+		}
+		m := pipRequirement.FindStringSubmatch(line)
+		if m == nil {
+			return dependency{}, false
+		}
+		version, _, _ := strings.Cut(m[3], "#")
+		return dependency{strings.ToLower(m[1]), strings.TrimSpace(version)}, true
+
+	case "Cargo.toml":
+		m := cargoEntry.FindStringSubmatch(line)
+		if m == nil || nonDependencyKeys[m[1]] {
+			return dependency{}, false
+		}
+		version := m[3]
+		if strings.HasPrefix(m[2], "{") {
+			if v := cargoVersion.FindStringSubmatch(m[2]); v != nil {
+				version = v[1]
+			}
+		}
+		return dependency{m[1], version}, true
+
+	case "Gemfile":
+		if m := gemEntry.FindStringSubmatch(line); m != nil {
+			return dependency{m[1], m[2]}, true
+		}
+	}
+	return dependency{}, false
+}
@@ -0,0 +1,54 @@
+package extractor
+
+import "sync"
+
+// DefaultMinConfidence is the confidence below which extracted memories are
+// dropped, unless configured otherwise
+const DefaultMinConfidence = 0.6
+
+// settings are the tunables shared by model-backed extractors. They can be
+// changed while extraction runs, e.g. on config reload.
+type settings struct {
+	mu            sync.RWMutex
+	examples      ExampleSource
+	minConfidence float64
+	hasMin        bool
+	runs          RunRecorder
+}
+
+// SetExamples enables few-shot examples drawn from src
+func (s *settings) SetExamples(src ExampleSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.examples = src
+}
+
+// SetMinConfidence sets the confidence threshold for extracted memories
+func (s *settings) SetMinConfidence(min float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minConfidence = min
+	s.hasMin = true
+}
+
+// SetRunRecorder records every extraction run to r
+func (s *settings) SetRunRecorder(r RunRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = r
+}
+
+func (s *settings) recorder() RunRecorder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.runs
+}
+
+func (s *settings) current() (ExampleSource, float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.hasMin {
+		return s.examples, DefaultMinConfidence
+	}
+	return s.examples, s.minConfidence
+}
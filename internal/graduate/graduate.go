@@ -0,0 +1,185 @@
+// Package graduate promotes session memories that prove durable into the
+// main store before they expire, so what an AI tool learns in one
+// conversation outlives it.
+package graduate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// Window is how close to expiry the daemon reviews a session memory;
+	// until then the conversation may still revise or outgrow it
+	Window = time.Hour
+
+	// EventType marks session memories handed to the extractor
+	EventType = "session_note"
+
+	// DefaultBatchSize matches the agent's extraction batch size
+	DefaultBatchSize = 10
+
+	// DuplicateSimilarity is the embedding similarity above which a
+	// graduating memory is considered already known
+	DuplicateSimilarity = 0.9
+)
+
+// Options controls a graduation run
+type Options struct {
+	SessionID string        // "" for every session
+	Within    time.Duration // review memories expiring within this; 0 for all
+	BatchSize int
+	DryRun    bool // report memories that would graduate without saving them
+}
+
+// Result summarizes a graduation run
+type Result struct {
+	Reviewed   int
+	Failed     int // batches whose extraction produced unusable output
+	Duplicates int
+	Graduated  []models.Memory
+}
+
+// Run reviews session memories with the extractor, which keeps only what
+// stays useful beyond the conversation, and saves what it keeps as durable
+// memories. Reviewed session memories aren't reviewed again, but stay in
+// their session until they expire. emb may be nil, in which case only
+// exact duplicates are detected. Runs stop early when the provider becomes
+// unavailable, returning what graduated so far.
+func Run(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, opts Options) (*Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	var before time.Time
+	if opts.Within > 0 {
+		before = time.Now().Add(opts.Within)
+	}
+
+	notes, err := s.SessionMemoriesToReview(opts.SessionID, before)
+	if err != nil {
+		return nil, err
+	}
+
+	// Batches don't mix sessions, so each graduate names its session
+	result := &Result{}
+	for start := 0; start < len(notes); {
+		end := start + 1
+		for end < len(notes) && end-start < opts.BatchSize && notes[end].SessionID == notes[start].SessionID {
+			end++
+		}
+		if err := review(s, ext, emb, opts, notes[start:end], result); err != nil {
+			return result, err
+		}
+		start = end
+	}
+	return result, nil
+}
+
+// review runs one session's batch of notes through the extractor
+func review(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, opts Options, notes []models.SessionMemory, result *Result) error {
+	events := make([]models.Event, len(notes))
+	ids := make([]string, len(notes))
+	for i, n := range notes {
+		events[i] = models.Event{
+			ID:        n.ID,
+			Type:      EventType,
+			Timestamp: n.CreatedAt,
+			Data: map[string]interface{}{
+				"content": n.Content,
+				"type":    string(n.Type),
+				"topics":  n.Topics,
+			},
+		}
+		ids[i] = n.ID
+	}
+
+	extracted, err := ext.Extract(events)
+	if err != nil {
+		if errors.Is(err, extractor.ErrUnavailable) {
+			return fmt.Errorf("extraction unavailable after reviewing %d session memories: %w", result.Reviewed, err)
+		}
+		// Left unreviewed, so the next run tries again
+		result.Failed++
+		return nil
+	}
+	result.Reviewed += len(notes)
+
+	for _, e := range extracted {
+		var vec []float32
+		if emb != nil {
+			vec, _ = emb.Embed(e.Content)
+		}
+		dup, err := s.IsDuplicate(e.Content, vec, DuplicateSimilarity)
+		if err != nil {
+			return err
+		}
+		if dup {
+			result.Duplicates++
+			continue
+		}
+
+		m := newMemory(e, notes[0].SessionID)
+		identity.Attribute(&m)
+		if !opts.DryRun {
+			if err := s.CreateMemory(&m); err != nil {
+				if errors.Is(err, store.ErrDuplicate) {
+					result.Duplicates++
+					continue
+				}
+				return fmt.Errorf("failed to save memory: %w", err)
+			}
+			if len(vec) > 0 {
+				s.UpdateMemoryEmbedding(m.ID, vec)
+			}
+			s.RecordLineage(m.ID, store.LineageEntry{
+				Action:     store.LineageExtracted,
+				RunID:      e.RunID,
+				EventIDs:   ids,
+				Provider:   e.Provider,
+				Model:      e.Model,
+				PromptHash: e.PromptHash,
+				Confidence: e.Confidence,
+				Note:       "graduated from session " + notes[0].SessionID,
+			})
+		}
+		result.Graduated = append(result.Graduated, m)
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	return s.MarkSessionMemoriesReviewed(ids)
+}
+
+// newMemory builds a personal memory from an extraction result, sourced
+// from the conversation it was noted in
+func newMemory(e extractor.ExtractedMemory, sessionID string) models.Memory {
+	now := time.Now()
+	return models.Memory{
+		ID:      ulid.Make().String(),
+		Type:    models.MemoryType(e.Type),
+		Content: e.Content,
+		Summary: e.Summary,
+		Scope:   models.MemoryScopePersonal,
+		Source: models.Source{
+			Type:      models.SourceTypeChat,
+			Reference: "session:" + sessionID,
+			Timestamp: now,
+		},
+		Confidence:     e.Confidence,
+		Provider:       e.Provider,
+		PromptVersion:  e.PromptVersion,
+		Importance:     1.0,
+		Topics:         e.Topics,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+	}
+}
@@ -0,0 +1,116 @@
+package guard
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultThreshold is the minimum similarity for a mistake to be flagged
+const DefaultThreshold = 0.75
+
+const (
+	maxFiles     = 20   // files embedded per check
+	maxChunkSize = 4000 // characters embedded per file
+)
+
+// Chunk is the staged change to a single file
+type Chunk struct {
+	File string
+	Diff string
+}
+
+// Warning is a known mistake the staged change resembles
+type Warning struct {
+	File       string
+	Mistake    models.Memory
+	Similarity float32
+}
+
+// StagedDiff returns the staged changes of the repository at dir, split by file
+func StagedDiff(dir string) ([]Chunk, error) {
+	cmd := exec.Command("git", "diff", "--cached", "--no-color", "--unified=1")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed: %w", err)
+	}
+	return SplitDiff(string(out)), nil
+}
+
+// SplitDiff splits a unified diff into per-file chunks containing the file
+// name and its added lines. Removed lines are dropped: a mistake is
+// repeated by what gets written, not by what gets deleted.
+func SplitDiff(diff string) []Chunk {
+	var chunks []Chunk
+	var current *Chunk
+	var sb strings.Builder
+
+	flush := func() {
+		if current != nil && sb.Len() > 0 {
+			current.Diff = textutil.Truncate(sb.String(), maxChunkSize)
+			chunks = append(chunks, *current)
+		}
+		sb.Reset()
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &Chunk{}
+		case strings.HasPrefix(line, "+++ "):
+			if current != nil {
+				current.File = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+				sb.WriteString("File: " + current.File + "\n")
+			}
+		case strings.HasPrefix(line, "+"):
+			sb.WriteString(line[1:] + "\n")
+		}
+	}
+	flush()
+
+	if len(chunks) > maxFiles {
+		chunks = chunks[:maxFiles]
+	}
+	return chunks
+}
+
+// Check compares each chunk against mistake memories, returning the best
+// match per known mistake
+func Check(s *store.Store, emb embedding.Embedder, chunks []Chunk, threshold float32) ([]Warning, error) {
+	best := make(map[string]Warning)
+	var order []string
+
+	for _, chunk := range chunks {
+		vec, err := emb.Embed(chunk.Diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %s: %w", chunk.File, err)
+		}
+
+		matches, err := s.SimilarMemories(vec, []models.MemoryType{models.MemoryTypeMistake}, threshold, 3)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			prev, seen := best[m.ID]
+			if !seen {
+				order = append(order, m.ID)
+			}
+			if !seen || m.Similarity > prev.Similarity {
+				best[m.ID] = Warning{File: chunk.File, Mistake: m.Memory, Similarity: m.Similarity}
+			}
+		}
+	}
+
+	warnings := make([]Warning, 0, len(order))
+	for _, id := range order {
+		warnings = append(warnings, best[id])
+	}
+	return warnings, nil
+}
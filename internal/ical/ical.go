@@ -0,0 +1,122 @@
+// Package ical writes memories that expire as iCalendar (RFC 5545) events,
+// so calendars remind you to revisit them.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	stampFormat = "20060102T150405Z"
+
+	// lineLimit is the longest content line in octets, before folding
+	lineLimit = 75
+)
+
+// Options shape the exported events
+type Options struct {
+	// Alarm is how long before the expiry a reminder fires (0 for none)
+	Alarm time.Duration
+
+	// Duration of each event
+	Duration time.Duration
+}
+
+// DefaultOptions remind a day ahead with a half-hour event
+var DefaultOptions = Options{Alarm: 24 * time.Hour, Duration: 30 * time.Minute}
+
+// Write writes a calendar with an event at the expiry of each memory;
+// memories without an expiry are skipped
+func Write(w io.Writer, memories []models.Memory, now time.Time, opts Options) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//MemoryPilot//Expiring memories//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "MemoryPilot: expiring memories")
+
+	for _, m := range memories {
+		if m.ExpiresAt == nil {
+			continue
+		}
+		start := m.ExpiresAt.UTC()
+
+		description := m.Content
+		if len(m.Topics) > 0 {
+			description += "\n\nTopics: " + strings.Join(m.Topics, ", ")
+		}
+		description += fmt.Sprintf("\n\nThis %s memory expires now and will no longer be recalled.\nMemory ID: %s", m.Type, m.ID)
+
+		line("BEGIN", "VEVENT")
+		line("UID", m.ID+"@memorypilot")
+		line("DTSTAMP", now.UTC().Format(stampFormat))
+		line("DTSTART", start.Format(stampFormat))
+		line("DTEND", start.Add(opts.Duration).Format(stampFormat))
+		line("SUMMARY", escape("Revisit: "+m.Summary))
+		line("DESCRIPTION", escape(description))
+		line("CATEGORIES", escape(string(m.Type)))
+		if opts.Alarm > 0 {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escape("Revisit: "+m.Summary))
+			line("TRIGGER", "-"+duration(opts.Alarm))
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// duration formats a positive duration as an RFC 5545 DURATION
+func duration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	out := "P"
+	if days > 0 {
+		out += fmt.Sprintf("%dD", days)
+	}
+	if d > 0 || days == 0 {
+		out += "T"
+		if h := d / time.Hour; h > 0 {
+			out += fmt.Sprintf("%dH", h)
+		}
+		out += fmt.Sprintf("%dM", (d%time.Hour)/time.Minute)
+	}
+	return out
+}
+
+// writeFolded writes a content line, folding it at lineLimit octets
+// without splitting UTF-8 sequences
+func writeFolded(w *bufio.Writer, s string) {
+	limit := lineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = lineLimit - 1 // continuation lines start with a space
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,99 @@
+// Package identity names the person using this machine, for attributing
+// the memories they create.
+package identity
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+	"sync"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+var (
+	once    sync.Once
+	current string
+)
+
+// Current returns "Name <email>" from the global git config, falling back
+// to user@host when git has no identity configured
+func Current() string {
+	once.Do(func() {
+		current = fromGit()
+		if current == "" {
+			current = fromDevice()
+		}
+	})
+	return current
+}
+
+// Format joins a name and email the way git does, using whichever is set
+func Format(name, email string) string {
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	switch {
+	case name != "" && email != "":
+		return name + " <" + email + ">"
+	case email != "":
+		return "<" + email + ">"
+	}
+	return name
+}
+
+func fromGit() string {
+	get := func(key string) string {
+		out, err := exec.Command("git", "config", "--global", key).Output()
+		if err != nil {
+			return ""
+		}
+		return string(out)
+	}
+	return Format(get("user.name"), get("user.email"))
+}
+
+func fromDevice() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	switch {
+	case name != "" && host != "":
+		return name + "@" + host
+	case name != "":
+		return name
+	}
+	return host
+}
+
+// Attribute fills in a memory's author, if unset, with the current
+// identity, and its maintainer with the author
+func Attribute(m *models.Memory) {
+	if m.Author == "" {
+		m.Author = Current()
+	}
+	if m.Maintainer == "" {
+		m.Maintainer = m.Author
+	}
+}
+
+// OfEvents returns the commit author when every event is a git commit by
+// the same person, as with a teammate's commits under team capture, and ""
+// otherwise
+func OfEvents(events []models.Event) string {
+	author := ""
+	for _, e := range events {
+		if e.Type != "git_commit" {
+			return ""
+		}
+		name, _ := e.Data["author"].(string)
+		email, _ := e.Data["email"].(string)
+		a := Format(name, email)
+		if a == "" || author != "" && a != author {
+			return ""
+		}
+		author = a
+	}
+	return author
+}
@@ -0,0 +1,293 @@
+// Package ingest turns documents, such as design docs and postmortems, into
+// memories: their text is extracted, split into sections and run through
+// memory extraction with a document-focused prompt.
+package ingest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+)
+
+// Formats of document Load reads
+const (
+	FormatPDF      = "pdf"
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// maxDocumentSize is the largest document Load reads
+const maxDocumentSize = 50 << 20
+
+// Document is the text of a document and where it came from
+type Document struct {
+	Source string // absolute file path or URL
+	Title  string
+	Format string
+	Text   string // Markdown-style headings mark its sections
+}
+
+// Load reads a document from a file or an http(s) URL
+func Load(source string) (*Document, error) {
+	var data []byte
+	contentType := ""
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		if err := privacy.CheckURL("document download", source); err != nil {
+			return nil, err
+		}
+		client := &http.Client{Timeout: 60 * time.Second}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download %s: %s", source, resp.Status)
+		}
+		if data, err = readLimited(resp.Body); err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", source, err)
+		}
+		contentType = resp.Header.Get("Content-Type")
+	} else {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(abs)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if data, err = readLimited(f); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		source = abs
+	}
+	return Parse(source, data, contentType)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("document is larger than %d MB", maxDocumentSize>>20)
+	}
+	return data, nil
+}
+
+// Parse extracts the text of a document, telling its format from its
+// content, its content type (if known) and the name in source
+func Parse(source string, data []byte, contentType string) (*Document, error) {
+	doc := &Document{Source: source, Format: detectFormat(source, data, contentType)}
+	switch doc.Format {
+	case FormatPDF:
+		title, text, err := pdfText(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF: %w", err)
+		}
+		doc.Title, doc.Text = title, text
+	case FormatHTML:
+		doc.Title, doc.Text = htmlText(string(data))
+	case FormatMarkdown, FormatText:
+		doc.Title, doc.Text = markdownText(string(data))
+	default:
+		return nil, fmt.Errorf("unsupported document format (expected PDF, HTML, Markdown or text)")
+	}
+
+	doc.Text = tidy(doc.Text)
+	if doc.Text == "" {
+		return nil, fmt.Errorf("no text found in %s", source)
+	}
+	if doc.Title == "" {
+		doc.Title = firstHeading(doc.Text)
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(path.Base(source), path.Ext(source))
+	}
+	return doc, nil
+}
+
+func detectFormat(source string, data []byte, contentType string) string {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return FormatPDF
+	}
+	contentType = strings.ToLower(contentType)
+	ext := strings.ToLower(path.Ext(strings.SplitN(source, "?", 2)[0]))
+	head := strings.ToLower(string(bytes.TrimSpace(data[:min(len(data), 512)])))
+	switch {
+	case strings.Contains(contentType, "pdf"):
+		return FormatPDF
+	case strings.Contains(contentType, "html"), ext == ".html", ext == ".htm", ext == ".xhtml",
+		strings.HasPrefix(head, "<!doctype html"), strings.HasPrefix(head, "<html"):
+		return FormatHTML
+	case ext == ".md", ext == ".markdown", ext == ".mdx", strings.Contains(contentType, "markdown"):
+		return FormatMarkdown
+	case utf8.Valid(data):
+		return FormatText
+	}
+	return ""
+}
+
+var frontMatterTitle = regexp.MustCompile(`(?m)^title:\s*["']?(.*?)["']?\s*$`)
+
+// markdownText returns Markdown without its front matter, and the title
+// the front matter gives
+func markdownText(text string) (title, body string) {
+	text = strings.TrimPrefix(strings.ReplaceAll(text, "\r\n", "\n"), "\ufeff")
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		if front, body, ok := strings.Cut(rest, "\n---\n"); ok {
+			if m := frontMatterTitle.FindStringSubmatch(front); m != nil {
+				title = m[1]
+			}
+			return title, body
+		}
+	}
+	return "", text
+}
+
+var heading = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// headings calls fn for each line of text with its heading level and
+// text, or 0 for lines that aren't headings; lines in fenced code blocks
+// never are
+func headings(text string, fn func(line string, level int, title string)) {
+	fenced := false
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		if m := heading.FindStringSubmatch(line); m != nil && !fenced {
+			fn(line, len(m[1]), m[2])
+		} else {
+			fn(line, 0, "")
+		}
+	}
+}
+
+// firstHeading returns the text of the first top-level heading, or of the
+// first heading if there's none
+func firstHeading(text string) string {
+	top, first := "", ""
+	headings(text, func(_ string, level int, title string) {
+		if level == 1 && top == "" {
+			top = title
+		}
+		if level > 0 && first == "" {
+			first = title
+		}
+	})
+	if top != "" {
+		return top
+	}
+	return first
+}
+
+// tidy trims trailing space from lines and collapses runs of blank lines
+func tidy(text string) string {
+	var out []string
+	blank := false
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t\r\u00a0")
+		if line == "" {
+			blank = len(out) > 0
+			continue
+		}
+		if blank {
+			out = append(out, "")
+			blank = false
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// DefaultSectionSize is the most characters of a document extracted at once
+const DefaultSectionSize = 6000
+
+// Section is a part of a document extracted at once: one or more of its
+// sections by heading, or part of one too long for that
+type Section struct {
+	Heading string // the heading it starts under, if any
+	Text    string
+}
+
+// Sections splits the document at its headings into parts of at most size
+// bytes, joining short sections and splitting long ones between paragraphs
+func (d *Document) Sections(size int) []Section {
+	if size <= 0 {
+		size = DefaultSectionSize
+	}
+
+	// Sections by heading
+	var byHeading []Section
+	current := Section{}
+	var lines []string
+	flush := func() {
+		current.Text = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Text != "" {
+			byHeading = append(byHeading, current)
+		}
+		lines = nil
+	}
+	headings(d.Text, func(line string, level int, title string) {
+		if level > 0 {
+			flush()
+			current = Section{Heading: title}
+		}
+		lines = append(lines, line)
+	})
+	flush()
+
+	// Joined up to size, or split to fit it
+	var sections []Section
+	for _, s := range byHeading {
+		if n := len(sections); n > 0 && len(sections[n-1].Text)+2+len(s.Text) <= size {
+			sections[n-1].Text += "\n\n" + s.Text
+			continue
+		}
+		for _, part := range split(s.Text, size) {
+			sections = append(sections, Section{Heading: s.Heading, Text: part})
+		}
+	}
+	return sections
+}
+
+// split cuts text into parts of at most size bytes, between paragraphs
+// where it can, else between lines or words
+func split(text string, size int) []string {
+	var parts []string
+	for len(text) > size {
+		cut := -1
+		for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+			if i := strings.LastIndex(text[:size], sep); i > size/2 {
+				cut = i + len(sep)
+				break
+			}
+		}
+		if cut < 0 {
+			cut = size
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		parts = append(parts, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return parts
+}
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// htmlSkipped are elements whose content isn't text of the document
+var htmlSkipped = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "nav": true, "footer": true, "head": true, "iframe": true,
+}
+
+// htmlBlocks are elements that start a new line
+var htmlBlocks = map[string]bool{
+	"div": true, "section": true, "article": true, "main": true, "header": true,
+	"aside": true, "tr": true, "ul": true, "ol": true, "dl": true, "dt": true,
+	"dd": true, "figure": true, "figcaption": true, "hr": true, "br": true,
+	"form": true, "details": true, "summary": true,
+}
+
+// htmlText converts HTML to plain text, keeping headings as Markdown
+// headings, list items as "- " lines and preformatted text as is. It
+// returns the text of the title element too.
+func htmlText(src string) (title, text string) {
+	var out []byte
+	skip := "" // the skipped element being read through
+	pre := 0
+	inTitle := false
+
+	write := func(s string) {
+		if pre == 0 && (len(out) == 0 || out[len(out)-1] == '\n') {
+			s = strings.TrimLeft(s, " ")
+		}
+		out = append(out, s...)
+	}
+	// breakLines ends the text so far with at least n line breaks
+	breakLines := func(n int) {
+		out = bytes.TrimRight(out, " ")
+		have := len(out) - len(bytes.TrimRight(out, "\n"))
+		for ; have < n && len(out) > 0; have++ {
+			out = append(out, '\n')
+		}
+	}
+
+	for len(src) > 0 {
+		lt := strings.IndexByte(src, '<')
+		if lt < 0 {
+			lt = len(src)
+		}
+		if chunk := src[:lt]; chunk != "" {
+			switch {
+			case inTitle:
+				title += chunk
+			case skip != "":
+			case pre > 0:
+				write(html.UnescapeString(chunk))
+			default:
+				// Runs of whitespace are one space, as browsers show them
+				words := strings.Fields(html.UnescapeString(chunk))
+				if isSpace(chunk[0]) {
+					write(" ")
+				}
+				write(strings.Join(words, " "))
+				if len(words) > 0 && isSpace(chunk[len(chunk)-1]) {
+					write(" ")
+				}
+			}
+		}
+		src = src[lt:]
+		if src == "" {
+			break
+		}
+
+		if strings.HasPrefix(src, "<!--") {
+			end := strings.Index(src, "-->")
+			if end < 0 {
+				break
+			}
+			src = src[end+3:]
+			continue
+		}
+		gt := strings.IndexByte(src, '>')
+		if gt < 0 {
+			break
+		}
+		tag := src[1:gt]
+		src = src[gt+1:]
+		if strings.HasPrefix(tag, "!") || strings.HasPrefix(tag, "?") {
+			continue // doctype, CDATA or processing instruction
+		}
+
+		closing := strings.HasPrefix(tag, "/")
+		name := strings.ToLower(strings.TrimLeft(tag, "/"))
+		if i := strings.IndexAny(name, " \t\n\r/"); i >= 0 {
+			name = name[:i]
+		}
+		selfClosing := strings.HasSuffix(tag, "/")
+
+		if name == "title" && (skip == "" || skip == "head") {
+			inTitle = !closing
+			continue
+		}
+		if skip != "" {
+			if closing && name == skip {
+				skip = ""
+			}
+			continue
+		}
+		if htmlSkipped[name] && !closing && !selfClosing {
+			skip = name
+			continue
+		}
+
+		switch {
+		case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+			breakLines(2)
+			if !closing {
+				write(strings.Repeat("#", int(name[1]-'0')) + " ")
+			}
+		case name == "li":
+			if !closing {
+				breakLines(1)
+				write("- ")
+			}
+		case name == "pre":
+			breakLines(1)
+			if closing {
+				pre = max(0, pre-1)
+			} else {
+				pre++
+			}
+		case name == "td" || name == "th":
+			if !closing {
+				write(" | ")
+			}
+		case name == "p" || name == "blockquote" || name == "table":
+			breakLines(2)
+		case htmlBlocks[name]:
+			breakLines(1)
+		}
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(title)), " "), string(out)
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
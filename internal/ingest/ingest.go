@@ -0,0 +1,177 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// EventType is the type of the events a document's sections are stored as
+const EventType = "document"
+
+// Options controls an ingestion
+type Options struct {
+	ProjectID   *string
+	SectionSize int     // bytes per section (DefaultSectionSize if 0)
+	Similarity  float64 // embedding similarity of a near duplicate (0 = exact only)
+	ReviewBelow float64 // memories less confident than this await review
+	DryRun      bool    // report memories without saving them
+
+	// Progress, if set, is called before each section is extracted
+	Progress func(i, n int, s Section)
+}
+
+// Result summarizes an ingestion
+type Result struct {
+	Sections   int
+	Failed     int // sections whose extraction produced unusable output
+	Extracted  int
+	Duplicates int
+	Added      []models.Memory
+}
+
+// Extract runs memory extraction over each section of a document and saves
+// the memories that aren't already known, tagged with the document as
+// their source. Sections are stored as events, so 'memorypilot why' traces
+// memories to them and reprocessing can extract them again. emb may be
+// nil. It stops early when the provider becomes unavailable, returning
+// what was added so far.
+func Extract(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, doc *Document, opts Options) (*Result, error) {
+	sections := doc.Sections(opts.SectionSize)
+	result := &Result{Sections: len(sections)}
+	seen := make(map[string]bool)
+	now := time.Now()
+
+	for i, sec := range sections {
+		if opts.Progress != nil {
+			opts.Progress(i, len(sections), sec)
+		}
+		ev := models.Event{
+			ID:        ulid.Make().String(),
+			Type:      EventType,
+			Timestamp: now,
+			ProjectID: opts.ProjectID,
+			Data: map[string]interface{}{
+				"source":  doc.Source,
+				"title":   doc.Title,
+				"format":  doc.Format,
+				"section": sec.Heading,
+				"part":    i + 1,
+				"parts":   len(sections),
+				"text":    sec.Text,
+			},
+		}
+		if !opts.DryRun {
+			if err := s.CreateEvent(&ev); err != nil {
+				return result, fmt.Errorf("failed to store section: %w", err)
+			}
+			if err := s.MarkEventProcessed(ev.ID); err != nil {
+				return result, err
+			}
+		}
+
+		extracted, err := ext.Extract([]models.Event{ev})
+		if err != nil {
+			if errors.Is(err, extractor.ErrUnavailable) {
+				return result, fmt.Errorf("extraction unavailable after %d of %d sections: %w", i, len(sections), err)
+			}
+			result.Failed++
+			continue
+		}
+
+		for _, e := range extracted {
+			result.Extracted++
+			key := strings.ToLower(strings.Join(strings.Fields(e.Content), " "))
+			if key == "" || seen[key] {
+				result.Duplicates++
+				continue
+			}
+			seen[key] = true
+
+			m := models.Memory{
+				ID:        ulid.Make().String(),
+				Type:      models.MemoryType(e.Type),
+				Content:   e.Content,
+				Summary:   e.Summary,
+				Scope:     models.MemoryScopePersonal,
+				ProjectID: opts.ProjectID,
+				Source: models.Source{
+					Type:      models.SourceTypeDocument,
+					Reference: doc.Source,
+					Timestamp: now,
+				},
+				Confidence:     e.Confidence,
+				Provider:       e.Provider,
+				PromptVersion:  e.PromptVersion,
+				Importance:     1.0,
+				Topics:         e.Topics,
+				CreatedAt:      now,
+				LastAccessedAt: now,
+			}
+			if m.Confidence < opts.ReviewBelow {
+				m.Status = models.MemoryStatusPending
+			}
+			identity.Attribute(&m)
+
+			var vec []float32
+			if emb != nil {
+				vec, _ = emb.Embed(m.Content)
+			}
+			dup, err := isDuplicate(s, &m, vec, opts.Similarity)
+			if err != nil {
+				return result, err
+			}
+			if dup {
+				result.Duplicates++
+				continue
+			}
+
+			if !opts.DryRun {
+				if err := s.CreateMemory(&m); err != nil {
+					if errors.Is(err, store.ErrDuplicate) {
+						result.Duplicates++
+						continue
+					}
+					return result, fmt.Errorf("failed to save memory: %w", err)
+				}
+				if vec != nil {
+					s.UpdateMemoryEmbedding(m.ID, vec)
+				}
+				s.RecordLineage(m.ID, store.LineageEntry{
+					Action:     store.LineageExtracted,
+					RunID:      e.RunID,
+					EventIDs:   []string{ev.ID},
+					Provider:   e.Provider,
+					Model:      e.Model,
+					PromptHash: e.PromptHash,
+					Confidence: e.Confidence,
+					Note:       "ingested from " + doc.Title,
+				})
+			}
+			result.Added = append(result.Added, m)
+		}
+	}
+	return result, nil
+}
+
+// isDuplicate reports whether a memory is already stored, verbatim or as
+// a near duplicate
+func isDuplicate(s *store.Store, m *models.Memory, vec []float32, similarity float64) (bool, error) {
+	exists, err := s.HasMemoryContent(m.Content)
+	if err != nil || exists {
+		return exists, err
+	}
+	if similarity <= 0 {
+		vec = nil
+	}
+	dup, err := s.NearDuplicate(m, vec, float32(similarity))
+	return dup != nil, err
+}
@@ -0,0 +1,846 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The PDF reader pulls the text out of a PDF's pages in order. It reads
+// objects wherever they are (plain or in object streams) rather than
+// trusting the cross-reference table, inflates Flate streams, and decodes
+// text through fonts' ToUnicode maps, or as Latin-1 for simple fonts
+// without one. Layout is approximated: text moving down starts a new line.
+// Scanned PDFs, encrypted ones and fonts with neither a ToUnicode map nor a
+// single-byte encoding yield no text.
+
+// pdfName is a PDF name, without its slash
+type pdfName string
+
+// pdfRef is an indirect reference to an object
+type pdfRef struct{ num, gen int }
+
+// pdfKeyword is a bare keyword: an operator, true, false or null
+type pdfKeyword string
+
+// pdfObject is an indirect object: its value, and its stream's raw data if
+// it has one
+type pdfObject struct {
+	value  interface{}
+	stream []byte
+}
+
+type pdfFile struct {
+	objects map[int]*pdfObject
+	fonts   map[pdfRef]*pdfFont
+}
+
+// pdfMaxDepth bounds how deeply page trees and form XObjects nest
+const pdfMaxDepth = 16
+
+// pdfMaxNesting bounds how deeply arrays and dictionaries nest, so a
+// hostile file can't exhaust the stack
+const pdfMaxNesting = 64
+
+var pdfObjectStart = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// pdfText returns a PDF's title, from its document information, and the
+// text of its pages, separated by blank lines
+func pdfText(data []byte) (title, text string, err error) {
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", "", errors.New("encrypted PDFs aren't supported")
+	}
+	f := &pdfFile{objects: make(map[int]*pdfObject), fonts: make(map[pdfRef]*pdfFont)}
+
+	// Later definitions of an object (incremental updates) replace earlier
+	// ones
+	var trailers []map[pdfName]interface{}
+	for _, loc := range pdfObjectStart.FindAllSubmatchIndex(data, -1) {
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		lex := &pdfLexer{b: data, pos: loc[1]}
+		value := lex.value()
+		obj := &pdfObject{value: value}
+		if lex.keyword() == "stream" {
+			obj.stream = lex.stream()
+		}
+		f.objects[num] = obj
+		if d, ok := value.(map[pdfName]interface{}); ok && d["Type"] == pdfName("XRef") {
+			trailers = append(trailers, d)
+		}
+	}
+	for i := 0; ; {
+		j := bytes.Index(data[i:], []byte("trailer"))
+		if j < 0 {
+			break
+		}
+		lex := &pdfLexer{b: data, pos: i + j + len("trailer")}
+		if d, ok := lex.value().(map[pdfName]interface{}); ok {
+			trailers = append(trailers, d)
+		}
+		i += j + len("trailer")
+	}
+	f.unpackObjectStreams()
+
+	// Pages in order, from the catalog's page tree; failing that, every
+	// page by object number
+	var pages []map[pdfName]interface{}
+	for _, t := range trailers {
+		if root, ok := f.resolve(t["Root"]).(map[pdfName]interface{}); ok {
+			f.walkPages(f.resolve(root["Pages"]), nil, &pages, 0)
+			break
+		}
+	}
+	if len(pages) == 0 {
+		var nums []int
+		for num, obj := range f.objects {
+			if d, ok := obj.value.(map[pdfName]interface{}); ok && d["Type"] == pdfName("Page") {
+				nums = append(nums, num)
+			}
+		}
+		sort.Ints(nums)
+		for _, num := range nums {
+			pages = append(pages, f.objects[num].value.(map[pdfName]interface{}))
+		}
+	}
+	if len(pages) == 0 {
+		return "", "", errors.New("no pages found")
+	}
+
+	var texts []string
+	for _, page := range pages {
+		var content []byte
+		switch c := f.resolve(page["Contents"]).(type) {
+		case []interface{}:
+			for _, ref := range c {
+				content = append(content, f.streamOf(ref)...)
+				content = append(content, '\n')
+			}
+		default:
+			content = f.streamOf(page["Contents"])
+		}
+		resources, _ := f.resolve(page["Resources"]).(map[pdfName]interface{})
+		var sb strings.Builder
+		f.showText(&sb, content, resources, 0)
+		if t := strings.TrimSpace(sb.String()); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	text = strings.Join(texts, "\n\n")
+	if text == "" {
+		return "", "", errors.New("no text found (scanned, or drawn with fonts that can't be decoded)")
+	}
+
+	for _, t := range trailers {
+		if info, ok := f.resolve(t["Info"]).(map[pdfName]interface{}); ok {
+			if s, ok := f.resolve(info["Title"]).([]byte); ok {
+				title = strings.TrimSpace(pdfTextString(s))
+				break
+			}
+		}
+	}
+	return title, text, nil
+}
+
+// unpackObjectStreams adds the objects compressed into object streams,
+// unless defined plainly
+func (f *pdfFile) unpackObjectStreams() {
+	var streams []*pdfObject
+	for _, obj := range f.objects {
+		if d, ok := obj.value.(map[pdfName]interface{}); ok && d["Type"] == pdfName("ObjStm") {
+			streams = append(streams, obj)
+		}
+	}
+	for _, obj := range streams {
+		d := obj.value.(map[pdfName]interface{})
+		data, err := f.decode(d, obj.stream)
+		if err != nil {
+			continue
+		}
+		n, _ := f.resolve(d["N"]).(float64)
+		first, _ := f.resolve(d["First"]).(float64)
+		lex := &pdfLexer{b: data}
+		type entry struct{ num, offset int }
+		var entries []entry
+		for i := 0; i < int(n); i++ {
+			num, ok1 := lex.value().(float64)
+			off, ok2 := lex.value().(float64)
+			if !ok1 || !ok2 {
+				break
+			}
+			entries = append(entries, entry{int(num), int(off)})
+		}
+		for _, e := range entries {
+			if _, ok := f.objects[e.num]; ok {
+				continue
+			}
+			pos := int(first) + e.offset
+			if pos < 0 || pos >= len(data) {
+				continue
+			}
+			f.objects[e.num] = &pdfObject{value: (&pdfLexer{b: data, pos: pos}).value()}
+		}
+	}
+}
+
+// walkPages collects the pages of a page tree in order, giving each the
+// resources it inherits
+func (f *pdfFile) walkPages(node interface{}, inherited interface{}, pages *[]map[pdfName]interface{}, depth int) {
+	d, ok := node.(map[pdfName]interface{})
+	if !ok || depth > pdfMaxDepth {
+		return
+	}
+	if _, ok := d["Resources"]; !ok && inherited != nil {
+		d["Resources"] = inherited
+	}
+	if d["Type"] == pdfName("Page") {
+		*pages = append(*pages, d)
+		return
+	}
+	kids, _ := f.resolve(d["Kids"]).([]interface{})
+	for _, kid := range kids {
+		f.walkPages(f.resolve(kid), d["Resources"], pages, depth+1)
+	}
+}
+
+// resolve follows a reference to the object it names
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for i := 0; i < pdfMaxDepth; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj, ok := f.objects[ref.num]
+		if !ok {
+			return nil
+		}
+		v = obj.value
+	}
+	return nil
+}
+
+// streamOf returns the decoded stream of a referenced object
+func (f *pdfFile) streamOf(v interface{}) []byte {
+	ref, ok := v.(pdfRef)
+	if !ok {
+		return nil
+	}
+	obj, ok := f.objects[ref.num]
+	if !ok || obj.stream == nil {
+		return nil
+	}
+	d, _ := obj.value.(map[pdfName]interface{})
+	data, err := f.decode(d, obj.stream)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// decode applies a stream's filters; only Flate is supported
+func (f *pdfFile) decode(d map[pdfName]interface{}, raw []byte) ([]byte, error) {
+	var filters []interface{}
+	switch v := f.resolve(d["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{v}
+	case []interface{}:
+		filters = v
+	}
+	data := raw
+	for _, filter := range filters {
+		if filter != pdfName("FlateDecode") {
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		// Keep what inflates before any corruption
+		inflated, err := io.ReadAll(r)
+		if err != nil && len(inflated) == 0 {
+			return nil, err
+		}
+		data = inflated
+	}
+	return data, nil
+}
+
+// showText writes the text a content stream draws
+func (f *pdfFile) showText(sb *strings.Builder, content []byte, resources map[pdfName]interface{}, depth int) {
+	if depth > pdfMaxDepth {
+		return
+	}
+	fonts, _ := f.resolve(resources["Font"]).(map[pdfName]interface{})
+	xobjects, _ := f.resolve(resources["XObject"]).(map[pdfName]interface{})
+	var font *pdfFont
+	var lineY float64
+
+	newline := func() {
+		if s := sb.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	space := func() {
+		if s := sb.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+			sb.WriteByte(' ')
+		}
+	}
+	show := func(v interface{}) {
+		if s, ok := v.([]byte); ok {
+			sb.WriteString(font.decode(s))
+		}
+	}
+	num := func(v interface{}) float64 {
+		n, _ := v.(float64)
+		return n
+	}
+
+	lex := &pdfLexer{b: content}
+	var operands []interface{}
+	for {
+		v := lex.value()
+		if v == nil && lex.pos >= len(lex.b) {
+			return
+		}
+		op, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		args := operands
+		operands = nil
+		switch op {
+		case "Tf":
+			if len(args) >= 1 {
+				name, _ := args[0].(pdfName)
+				font = f.font(fonts[name])
+			}
+		case "Tj":
+			if len(args) >= 1 {
+				show(args[0])
+			}
+		case "'":
+			newline()
+			if len(args) >= 1 {
+				show(args[0])
+			}
+		case "\"":
+			newline()
+			if len(args) >= 3 {
+				show(args[2])
+			}
+		case "TJ":
+			if len(args) >= 1 {
+				items, _ := args[0].([]interface{})
+				for _, item := range items {
+					// A large negative adjustment is a gap between words
+					if n, ok := item.(float64); ok && n < -200 {
+						space()
+					}
+					show(item)
+				}
+			}
+		case "Td", "TD":
+			if len(args) >= 2 {
+				if num(args[1]) != 0 {
+					newline()
+				} else if num(args[0]) > 0 {
+					space()
+				}
+			}
+		case "T*":
+			newline()
+		case "Tm":
+			if len(args) >= 6 {
+				if y := num(args[5]); y != lineY {
+					newline()
+					lineY = y
+				} else {
+					space()
+				}
+			}
+		case "ET":
+			space()
+		case "BI":
+			lex.skipInlineImage()
+		case "Do":
+			if len(args) >= 1 {
+				name, _ := args[0].(pdfName)
+				ref, ok := xobjects[name].(pdfRef)
+				if !ok {
+					continue
+				}
+				obj := f.objects[ref.num]
+				if obj == nil {
+					continue
+				}
+				d, _ := obj.value.(map[pdfName]interface{})
+				if d["Subtype"] != pdfName("Form") {
+					continue
+				}
+				inner, _ := f.resolve(d["Resources"]).(map[pdfName]interface{})
+				if inner == nil {
+					inner = resources
+				}
+				newline()
+				f.showText(sb, f.streamOf(ref), inner, depth+1)
+				newline()
+			}
+		}
+	}
+}
+
+// pdfFont decodes the strings a font draws
+type pdfFont struct {
+	codeLen int               // bytes per character code
+	unicode map[uint32]string // from the ToUnicode map, if any
+}
+
+// font returns the decoder of a font resource; nil decodes as Latin-1
+func (f *pdfFile) font(v interface{}) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if font, ok := f.fonts[ref]; ok && isRef {
+		return font
+	}
+	d, ok := f.resolve(v).(map[pdfName]interface{})
+	if !ok {
+		return nil
+	}
+	font := &pdfFont{codeLen: 1}
+	if d["Subtype"] == pdfName("Type0") {
+		font.codeLen = 2
+	}
+	if cmap := f.streamOf(d["ToUnicode"]); cmap != nil {
+		font.unicode, font.codeLen = parseToUnicode(cmap, font.codeLen)
+	}
+	if isRef {
+		f.fonts[ref] = font
+	}
+	return font
+}
+
+func (font *pdfFont) decode(s []byte) string {
+	if font == nil || (font.unicode == nil && font.codeLen == 1) {
+		return latin1(s)
+	}
+	if font.unicode == nil {
+		return "" // composite font without a map: codes are glyph IDs
+	}
+	var sb strings.Builder
+	for i := 0; i+font.codeLen <= len(s); i += font.codeLen {
+		var code uint32
+		for _, b := range s[i : i+font.codeLen] {
+			code = code<<8 | uint32(b)
+		}
+		if u, ok := font.unicode[code]; ok {
+			sb.WriteString(u)
+		} else if font.codeLen == 1 {
+			sb.WriteString(latin1(s[i : i+1]))
+		}
+	}
+	return sb.String()
+}
+
+// parseToUnicode reads a ToUnicode CMap's character mappings, and the code
+// length of its code space
+func parseToUnicode(data []byte, codeLen int) (map[uint32]string, int) {
+	m := make(map[uint32]string)
+	code := func(b []byte) uint32 {
+		var c uint32
+		for _, x := range b {
+			c = c<<8 | uint32(x)
+		}
+		return c
+	}
+
+	lex := &pdfLexer{b: data}
+	var operands []interface{}
+	for {
+		v := lex.value()
+		if v == nil && lex.pos >= len(lex.b) {
+			break
+		}
+		op, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		args := operands
+		operands = nil
+		switch op {
+		case "endcodespacerange":
+			if len(args) >= 1 {
+				if lo, ok := args[0].([]byte); ok && len(lo) > 0 {
+					codeLen = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(args); i += 2 {
+				src, ok1 := args[i].([]byte)
+				dst, ok2 := args[i+1].([]byte)
+				if ok1 && ok2 {
+					m[code(src)] = utf16BE(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(args); i += 3 {
+				lo, ok1 := args[i].([]byte)
+				hi, ok2 := args[i+1].([]byte)
+				if !ok1 || !ok2 || code(hi) < code(lo) || code(hi)-code(lo) > 0xffff {
+					continue
+				}
+				switch dst := args[i+2].(type) {
+				case []byte:
+					// Consecutive codes map to consecutive characters
+					base := []rune(utf16BE(dst))
+					if len(base) == 0 {
+						continue
+					}
+					// Counted by offset: a range can end at the largest code
+					for off := uint32(0); off <= code(hi)-code(lo); off++ {
+						r := append([]rune(nil), base...)
+						r[len(r)-1] += rune(off)
+						m[code(lo)+off] = string(r)
+					}
+				case []interface{}:
+					for j, item := range dst {
+						if b, ok := item.([]byte); ok {
+							m[code(lo)+uint32(j)] = utf16BE(b)
+						}
+					}
+				}
+			}
+		}
+	}
+	return m, codeLen
+}
+
+func utf16BE(b []byte) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(u))
+}
+
+// pdfTextString decodes a text string outside content, such as a title:
+// UTF-16 with a byte order mark, or else PDFDocEncoding, close to Latin-1
+func pdfTextString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		return utf16BE(b[2:])
+	}
+	return latin1(b)
+}
+
+// winAnsi are the characters Windows-1252 puts where Latin-1 has control
+// codes, common in simple fonts
+var winAnsi = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”',
+	0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™',
+}
+
+func latin1(b []byte) string {
+	r := make([]rune, 0, len(b))
+	for _, c := range b {
+		switch {
+		case winAnsi[c] != 0:
+			r = append(r, winAnsi[c])
+		case c >= 0x20 && c != 0x7f && (c < 0x80 || c >= 0xa0):
+			r = append(r, rune(c))
+		case c == '\t' || c == '\n':
+			r = append(r, ' ')
+		}
+	}
+	return string(r)
+}
+
+// pdfLexer reads PDF values: numbers, strings (as []byte), names, arrays,
+// dictionaries, references and keywords. It returns nil at the end.
+type pdfLexer struct {
+	b     []byte
+	pos   int
+	depth int // arrays and dictionaries being read
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.b) {
+		switch c := l.b[l.pos]; {
+		case c == '%':
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0:
+			l.pos++
+		default:
+			return
+		}
+	}
+}
+
+func pdfDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/% \n\r\t\f\x00", c) >= 0
+}
+
+// keyword reads the next token if it's a keyword, leaving the position
+// alone otherwise
+func (l *pdfLexer) keyword() pdfKeyword {
+	save := l.pos
+	if k, ok := l.value().(pdfKeyword); ok {
+		return k
+	}
+	l.pos = save
+	return ""
+}
+
+// stream reads stream data after the stream keyword, up to endstream
+func (l *pdfLexer) stream() []byte {
+	if l.pos < len(l.b) && l.b[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+		l.pos++
+	}
+	end := bytes.Index(l.b[l.pos:], []byte("endstream"))
+	if end < 0 {
+		return nil
+	}
+	data := bytes.TrimRight(l.b[l.pos:l.pos+end], "\r\n")
+	l.pos += end + len("endstream")
+	return data
+}
+
+// skipInlineImage skips an inline image's data, up to its EI operator
+func (l *pdfLexer) skipInlineImage() {
+	id := bytes.Index(l.b[l.pos:], []byte("ID"))
+	if id < 0 {
+		l.pos = len(l.b)
+		return
+	}
+	l.pos += id + 2
+	for l.pos < len(l.b) {
+		ei := bytes.Index(l.b[l.pos:], []byte("EI"))
+		if ei < 0 {
+			l.pos = len(l.b)
+			return
+		}
+		at := l.pos + ei
+		l.pos = at + 2
+		if at > 0 && pdfDelimiter(l.b[at-1]) && (l.pos == len(l.b) || pdfDelimiter(l.b[l.pos])) {
+			return
+		}
+	}
+}
+
+func (l *pdfLexer) value() interface{} {
+	l.skipSpace()
+	for l.pos < len(l.b) && strings.IndexByte("])>{}", l.b[l.pos]) >= 0 {
+		l.pos++ // stray delimiter
+		l.skipSpace()
+	}
+	if l.pos >= len(l.b) {
+		return nil
+	}
+	switch c := l.b[l.pos]; {
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.b) && !pdfDelimiter(l.b[l.pos]) {
+			l.pos++
+		}
+		return pdfName(unescapeName(string(l.b[start:l.pos])))
+
+	case c == '(':
+		return l.literalString()
+
+	case c == '<' && l.pos+1 < len(l.b) && l.b[l.pos+1] == '<':
+		l.pos += 2
+		if l.depth >= pdfMaxNesting {
+			return nil
+		}
+		l.depth++
+		defer func() { l.depth-- }()
+		d := make(map[pdfName]interface{})
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.b) {
+				return d
+			}
+			if l.b[l.pos] == '>' {
+				l.pos += 2
+				return d
+			}
+			key, ok := l.value().(pdfName)
+			if !ok {
+				continue // malformed; skip the token
+			}
+			d[key] = l.value()
+		}
+
+	case c == '<':
+		l.pos++
+		end := bytes.IndexByte(l.b[l.pos:], '>')
+		if end < 0 {
+			l.pos = len(l.b)
+			return []byte(nil)
+		}
+		hex := make([]byte, 0, end)
+		for _, h := range l.b[l.pos : l.pos+end] {
+			if (h >= '0' && h <= '9') || (h >= 'a' && h <= 'f') || (h >= 'A' && h <= 'F') {
+				hex = append(hex, h)
+			}
+		}
+		l.pos += end + 1
+		if len(hex)%2 == 1 {
+			hex = append(hex, '0')
+		}
+		out := make([]byte, len(hex)/2)
+		for i := range out {
+			n, _ := strconv.ParseUint(string(hex[2*i:2*i+2]), 16, 8)
+			out[i] = byte(n)
+		}
+		return out
+
+	case c == '[':
+		l.pos++
+		if l.depth >= pdfMaxNesting {
+			return nil
+		}
+		l.depth++
+		defer func() { l.depth-- }()
+		var items []interface{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.b) {
+				return items
+			}
+			if l.b[l.pos] == ']' {
+				l.pos++
+				return items
+			}
+			items = append(items, l.value())
+		}
+
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.b) && !pdfDelimiter(l.b[l.pos]) {
+			l.pos++
+		}
+		n, err := strconv.ParseFloat(string(l.b[start:l.pos]), 64)
+		if err != nil {
+			return pdfKeyword(l.b[start:l.pos])
+		}
+
+		// "num gen R" is a reference
+		save := l.pos
+		if n == float64(int(n)) {
+			l.skipSpace()
+			gstart := l.pos
+			for l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '9' {
+				l.pos++
+			}
+			if l.pos > gstart {
+				gen, _ := strconv.Atoi(string(l.b[gstart:l.pos]))
+				l.skipSpace()
+				if l.pos < len(l.b) && l.b[l.pos] == 'R' && (l.pos+1 == len(l.b) || pdfDelimiter(l.b[l.pos+1])) {
+					l.pos++
+					return pdfRef{int(n), gen}
+				}
+			}
+		}
+		l.pos = save
+		return n
+
+	default:
+		start := l.pos
+		for l.pos < len(l.b) && !pdfDelimiter(l.b[l.pos]) {
+			l.pos++
+		}
+		if l.pos == start {
+			l.pos++
+		}
+		return pdfKeyword(l.b[start:l.pos])
+	}
+}
+
+// literalString reads a (string) with its escapes and balanced parentheses
+func (l *pdfLexer) literalString() []byte {
+	l.pos++
+	var out []byte
+	depth := 1
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.b) {
+				return out
+			}
+			e := l.b[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.b) && l.b[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					n := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '7'; i++ {
+						n = n*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(n)
+				} else {
+					c = e
+				}
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// unescapeName decodes #xx escapes in a name
+func unescapeName(s string) string {
+	if !strings.Contains(s, "#") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i+2 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(n))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,374 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF lays out objects 1..n with a cross-reference table and a
+// trailer, as a writer would
+func buildPDF(trailer string, objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	if trailer != "" {
+		fmt.Fprintf(&buf, "trailer\n<< /Size %d %s >>\n", len(objects)+1, trailer)
+	}
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+func stream(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(data string) string {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(data))
+	w.Close()
+	return stream("/Filter /FlateDecode", buf.String())
+}
+
+// objectStream packs objects, numbered from first, into an object stream
+func objectStream(first int, objects ...string) string {
+	var header, body strings.Builder
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", first+i, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	data := header.String() + body.String()
+	return stream(fmt.Sprintf("/Type /ObjStm /N %d /First %d", len(objects), header.Len()), data)
+}
+
+const (
+	pdfCatalog   = "<< /Type /Catalog /Pages 2 0 R >>"
+	pdfHelvetica = "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+)
+
+// pdfFixtures are small, well-formed documents covering the features the
+// reader handles
+var pdfFixtures = []struct {
+	name        string
+	data        []byte
+	title, text string
+}{
+	{
+		name: "simple",
+		data: buildPDF("/Root 1 0 R /Info 6 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			stream("", "BT /F1 12 Tf 72 700 Td (Hello, world) Tj ET"),
+			pdfHelvetica,
+			"<< /Title (Quarterly Report) >>",
+		),
+		title: "Quarterly Report",
+		text:  "Hello, world",
+	},
+	{
+		name: "flate streams and page tree order",
+		data: buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			// Inherited resources, and pages listed out of object order
+			"<< /Type /Pages /Kids [4 0 R 3 0 R] /Count 2 /Resources << /Font << /F1 7 0 R >> >> >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 6 0 R >>",
+			flate("BT /F1 12 Tf 72 700 Td (Second page) Tj ET"),
+			flate("BT /F1 12 Tf 72 700 Td (First page) Tj 0 -14 Td (next line) Tj ET"),
+			pdfHelvetica,
+		),
+		text: "First page\nnext line\n\nSecond page",
+	},
+	{
+		name: "text operators",
+		data: buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			stream("", strings.Join([]string{
+				"BT /F1 12 Tf",
+				"1 0 0 1 72 700 Tm [(Kern)-20(ed)-250(words)] TJ",
+				"1 0 0 1 72 686 Tm (Escapes: \\(a\\) \\101\\102 caf\\351 \\222quoted\\222) Tj",
+				"T* <48657820737472696e67> Tj",
+				"(Quote op) '",
+				"2 0 (Double quote op) \"",
+				"ET",
+				"BI /W 2 /H 1 /BPC 8 /CS /G ID \x00\xffEI Q EI",
+				"BT /F1 12 Tf 0 -14 Td (After image) Tj ET",
+			}, "\n")),
+			pdfHelvetica,
+		),
+		text: "Kerned words\nEscapes: (a) AB café ’quoted’\nHex string\nQuote op\nDouble quote op\nAfter image",
+	},
+	{
+		name: "object streams and xref stream",
+		data: buildPDF("",
+			"<< /Type /Catalog /Pages 10 0 R >>",
+			objectStream(10,
+				"<< /Type /Pages /Kids [11 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 10 0 R /Resources << /Font << /F1 13 0 R >> >> /Contents 3 0 R >>",
+				"<< /Title <feff005000610063006b0065006400204e2d6587> >>",
+				pdfHelvetica,
+			),
+			flate("BT /F1 12 Tf (Packed objects) Tj ET"),
+			stream("/Type /XRef /Root 1 0 R /Info 12 0 R /Size 14 /W [1 2 1]", ""),
+		),
+		title: "Packed 中文",
+		text:  "Packed objects",
+	},
+	{
+		name: "ToUnicode CMap",
+		data: buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			// Glyph IDs with no relation to the characters they draw
+			stream("", "BT /F1 12 Tf <0001000200040003> Tj <0010001100120005> Tj ET"),
+			"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 6 0 R >>",
+			flate(strings.Join([]string{
+				"/CIDInit /ProcSet findresource begin",
+				"begincmap",
+				"1 begincodespacerange <0000> <ffff> endcodespacerange",
+				"2 beginbfchar <0001> <0047> <0002> <006f> endbfchar",
+				"2 beginbfrange <0003> <0004> <0020> <0010> <0012> [<00e9> <0074> <00e9>] endbfrange",
+				"1 beginbfchar <0005> <d83dde00> endbfchar",
+				"endcmap",
+			}, "\n")),
+		),
+		text: "Go! été😀",
+	},
+	{
+		name: "form XObject",
+		data: buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 6 0 R >> /XObject << /Fm1 5 0 R >> >> /Contents 4 0 R >>",
+			stream("", "BT /F1 12 Tf (Before) Tj ET /Fm1 Do BT /F1 12 Tf (After) Tj ET"),
+			stream("/Type /XObject /Subtype /Form", "BT /F1 12 Tf (Inside the form) Tj ET"),
+			pdfHelvetica,
+		),
+		text: "Before\nInside the form\nAfter",
+	},
+	{
+		name: "incremental update",
+		data: append(
+			buildPDF("/Root 1 0 R",
+				pdfCatalog,
+				"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+				"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+				stream("", "BT /F1 12 Tf (Draft) Tj ET"),
+				pdfHelvetica,
+			),
+			"4 0 obj\n"+stream("", "BT /F1 12 Tf (Final) Tj ET")+"\nendobj\n"...,
+		),
+		text: "Final",
+	},
+	{
+		name: "no trailer",
+		data: buildPDF("",
+			"<< /Type /Page /Resources << /Font << /F1 3 0 R >> >> /Contents 2 0 R >>",
+			stream("", "BT /F1 12 Tf (Found by object number) Tj ET"),
+			pdfHelvetica,
+		),
+		text: "Found by object number",
+	},
+}
+
+func TestPDFText(t *testing.T) {
+	for _, tt := range pdfFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			title, text, err := pdfText(tt.data)
+			if err != nil {
+				t.Fatalf("pdfText: %v", err)
+			}
+			if title != tt.title {
+				t.Errorf("title = %q, want %q", title, tt.title)
+			}
+			// Parse tidies trailing spaces away, so they don't matter here
+			if text = tidy(text); text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+		})
+	}
+}
+
+func TestPDFTextErrors(t *testing.T) {
+	page := func(content string) []byte {
+		return buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			content,
+			pdfHelvetica,
+		)
+	}
+	// Deep enough to overflow the stack if nesting weren't bounded
+	deep := func(token string) []byte {
+		return append([]byte("1 0 obj\n"), bytes.Repeat([]byte(token), (4<<20)/len(token))...)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "no pages"},
+		{"not a PDF", []byte("<html><body>hello</body></html>"), "no pages"},
+		{"encrypted", buildPDF("/Root 1 0 R /Encrypt 2 0 R", pdfCatalog, "<< /Filter /Standard >>"), "encrypted"},
+		{"image only", page(stream("", "q 100 0 0 100 0 0 cm /Im1 Do Q")), "no text"},
+		{"unsupported filter", page(stream("/Filter /LZWDecode", "BT (hidden) Tj ET")), "no text"},
+		{"corrupt flate", page(stream("/Filter /FlateDecode", "BT (not deflated) Tj ET")), "no text"},
+		{"composite font without ToUnicode", buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+			stream("", "BT /F1 12 Tf <00010002> Tj ET"),
+			"<< /Type /Font /Subtype /Type0 /BaseFont /Custom >>",
+		), "no text"},
+		{"cyclic page tree", buildPDF("/Root 1 0 R",
+			pdfCatalog,
+			"<< /Type /Pages /Kids [2 0 R 2 0 R] /Count 1 >>",
+		), "no pages"},
+		{"deeply nested arrays", deep("["), "no pages"},
+		{"deeply nested dictionaries", deep("<< /A "), "no pages"},
+		{"stray delimiters", deep(")"), "no pages"},
+		{"cyclic references", buildPDF("/Root 1 0 R", "<< /Pages 2 0 R >>", "3 0 R", "2 0 R"), "no pages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := pdfText(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("pdfText = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Damaged files must give an error or partial text, never a panic or a
+// hang
+func TestPDFTextDamaged(t *testing.T) {
+	for _, tt := range pdfFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			for n := range tt.data {
+				pdfText(tt.data[:n])
+			}
+			damaged := make([]byte, len(tt.data))
+			for i := range tt.data {
+				for _, c := range []byte{0, '(', ')', '<', '>', '[', ']', '\\', '/', '9', 0xff} {
+					copy(damaged, tt.data)
+					damaged[i] = c
+					pdfText(damaged)
+				}
+			}
+		})
+	}
+}
+
+func TestParseToUnicode(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmap    string
+		codeLen int
+		want    map[uint32]string
+	}{
+		{
+			name:    "code space sets the code length",
+			cmap:    "1 begincodespacerange <00> <ff> endcodespacerange 1 beginbfchar <41> <0042> endbfchar",
+			codeLen: 1,
+			want:    map[uint32]string{0x41: "B"},
+		},
+		{
+			name:    "range with a base",
+			cmap:    "1 beginbfrange <0001> <0003> <0061> endbfrange",
+			codeLen: 2,
+			want:    map[uint32]string{1: "a", 2: "b", 3: "c"},
+		},
+		{
+			name:    "range with an array",
+			cmap:    "1 beginbfrange <0001> <0002> [<0078> <0079>] endbfrange",
+			codeLen: 2,
+			want:    map[uint32]string{1: "x", 2: "y"},
+		},
+		{
+			name:    "range at the top of the code space",
+			cmap:    "1 begincodespacerange <00000000> <ffffffff> endcodespacerange 1 beginbfrange <fffffffe> <ffffffff> <0061> endbfrange",
+			codeLen: 4,
+			want:    map[uint32]string{0xfffffffe: "a", 0xffffffff: "b"},
+		},
+		{
+			name:    "reversed and oversized ranges are ignored",
+			cmap:    "2 beginbfrange <0005> <0001> <0061> <00000000> <00ffffff> <0061> endbfrange",
+			codeLen: 2,
+			want:    map[uint32]string{},
+		},
+		{
+			name:    "operands of the wrong type are ignored",
+			cmap:    "2 beginbfchar /A <0041> <0001> 5 endbfchar 1 beginbfrange <01> /x <0061> endbfrange",
+			codeLen: 2,
+			want:    map[uint32]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, codeLen := parseToUnicode([]byte(tt.cmap), 2)
+			if codeLen != tt.codeLen {
+				t.Errorf("code length = %d, want %d", codeLen, tt.codeLen)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("mappings = %q, want %q", got, tt.want)
+			}
+			for code, s := range tt.want {
+				if got[code] != s {
+					t.Errorf("code %#x = %q, want %q", code, got[code], s)
+				}
+			}
+		})
+	}
+}
+
+func TestPDFLexer(t *testing.T) {
+	tests := []struct {
+		in   string
+		want interface{}
+	}{
+		{"42", float64(42)},
+		{"-3.5", float64(-3.5)},
+		{"12 0 R", pdfRef{12, 0}},
+		{"12 0 Rx", float64(12)},
+		{"/Name#20With#2fEscapes", pdfName("Name With/Escapes")},
+		{"(a (nested) string\\\nwrapped)", []byte("a (nested) stringwrapped")},
+		{"(unterminated", []byte("unterminated")},
+		{"<48 65 6c 6C 6>", []byte("Hell`")},
+		{"<4865", []byte(nil)},
+		{"true", pdfKeyword("true")},
+		{"1.2.3", pdfKeyword("1.2.3")},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := (&pdfLexer{b: []byte(tt.in)}).value()
+		if fmt.Sprintf("%#v", got) != fmt.Sprintf("%#v", tt.want) {
+			t.Errorf("value(%q) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+
+	// Unterminated containers end at the end of the input
+	d, ok := (&pdfLexer{b: []byte("<< /A [1 2 << /B (x")}).value().(map[pdfName]interface{})
+	if !ok {
+		t.Fatal("unterminated dictionary didn't parse as one")
+	}
+	if a, _ := d["A"].([]interface{}); len(a) != 3 {
+		t.Fatalf("A = %#v, want three items", d["A"])
+	}
+}
@@ -0,0 +1,219 @@
+// Package insights aggregates memories into org-level patterns that can be
+// shared without sharing the memories themselves: how often topics, memory
+// types and mistake categories come up, never what anyone wrote.
+//
+// Two protections keep individuals out of the report. A topic is only
+// reported once at least MinContributors different people (or projects,
+// for memories without an author) have memories about it, and every count
+// has Laplace noise of scale 1/Epsilon added, so no single memory can be
+// inferred from the numbers.
+package insights
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// maxTopics caps the topic and mistake lists
+const maxTopics = 25
+
+// Options control what a report covers and how it is anonymized
+type Options struct {
+	// Window is how far back memories are aggregated
+	Window time.Duration
+
+	// MinContributors is how many distinct contributors a topic needs
+	// before it is reported
+	MinContributors int
+
+	// Epsilon is the privacy budget: noise of scale 1/Epsilon is added to
+	// every count. Zero disables noise.
+	Epsilon float64
+}
+
+// DefaultOptions aggregate the last 30 days, reporting topics shared by at
+// least three contributors
+func DefaultOptions() Options {
+	return Options{
+		Window:          30 * 24 * time.Hour,
+		MinContributors: 3,
+		Epsilon:         1.0,
+	}
+}
+
+// Validate rejects options that would leak individuals
+func (o Options) Validate() error {
+	switch {
+	case o.Window <= 0:
+		return fmt.Errorf("insights window must be positive, got %s", o.Window)
+	case o.MinContributors < 2:
+		return fmt.Errorf("insights minContributors must be at least 2, got %d", o.MinContributors)
+	case o.Epsilon < 0:
+		return fmt.Errorf("insights epsilon must not be negative, got %g", o.Epsilon)
+	}
+	return nil
+}
+
+// Count is a noisy count of memories in one category
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Report is an anonymized aggregate of a window of memories
+type Report struct {
+	GeneratedAt     time.Time `json:"generatedAt"`
+	Since           time.Time `json:"since"`
+	MinContributors int       `json:"minContributors"`
+	Epsilon         float64   `json:"epsilon"`
+
+	Memories     int     `json:"memories"`
+	Contributors int     `json:"contributors"`
+	ByType       []Count `json:"byType"`
+	Topics       []Count `json:"topics"`
+	Mistakes     []Count `json:"mistakes"` // topics of mistake memories
+}
+
+// Build aggregates memories into a report
+func Build(memories []models.Memory, opts Options, now time.Time, rng *rand.Rand) Report {
+	r := Report{
+		GeneratedAt:     now,
+		Since:           now.Add(-opts.Window),
+		MinContributors: opts.MinContributors,
+		Epsilon:         opts.Epsilon,
+	}
+	noisy := func(n int) int {
+		if opts.Epsilon == 0 {
+			return n
+		}
+		// The difference of two exponentials is Laplace distributed
+		noise := (rng.ExpFloat64() - rng.ExpFloat64()) / opts.Epsilon
+		return max(0, int(math.Round(float64(n)+noise)))
+	}
+
+	contributors := map[string]bool{}
+	types := newTally()
+	topics := newTally()
+	mistakes := newTally()
+	for _, m := range memories {
+		who := contributor(m)
+		contributors[who] = true
+		types.add(string(m.Type), who)
+		seen := map[string]bool{}
+		for _, topic := range m.Topics {
+			topic = strings.ToLower(strings.TrimSpace(topic))
+			if topic == "" || seen[topic] {
+				continue
+			}
+			seen[topic] = true
+			topics.add(topic, who)
+			if m.Type == models.MemoryTypeMistake {
+				mistakes.add(topic, who)
+			}
+		}
+	}
+
+	r.Memories = noisy(len(memories))
+	r.Contributors = noisy(len(contributors))
+	r.ByType = types.counts(opts.MinContributors, noisy, 0)
+	r.Topics = topics.counts(opts.MinContributors, noisy, maxTopics)
+	r.Mistakes = mistakes.counts(opts.MinContributors, noisy, maxTopics)
+	return r
+}
+
+// Generate aggregates the store's recent memories and saves the report
+func Generate(s *store.Store, opts Options, now time.Time) (Report, error) {
+	if err := opts.Validate(); err != nil {
+		return Report{}, err
+	}
+	memories, err := s.GetMemoriesCreatedBetween(now.Add(-opts.Window), now)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to load memories: %w", err)
+	}
+	r := Build(memories, opts, now, rand.New(rand.NewSource(now.UnixNano())))
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return r, err
+	}
+	if err := s.SaveInsights(now, data); err != nil {
+		return r, fmt.Errorf("failed to save insights: %w", err)
+	}
+	return r, nil
+}
+
+// Latest returns the most recently generated report, or nil if there is
+// none
+func Latest(s *store.Store) (*Report, error) {
+	data, err := s.LatestInsights()
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("corrupt insights report: %w", err)
+	}
+	return &r, nil
+}
+
+// contributor identifies who a memory came from: its author, else its
+// project
+func contributor(m models.Memory) string {
+	if m.Author != "" {
+		return "author:" + m.Author
+	}
+	if m.ProjectID != nil && *m.ProjectID != "" {
+		return "project:" + *m.ProjectID
+	}
+	return "unknown"
+}
+
+// tally counts memories and distinct contributors per category
+type tally struct {
+	memories     map[string]int
+	contributors map[string]map[string]bool
+}
+
+func newTally() *tally {
+	return &tally{memories: map[string]int{}, contributors: map[string]map[string]bool{}}
+}
+
+func (t *tally) add(name, who string) {
+	t.memories[name]++
+	if t.contributors[name] == nil {
+		t.contributors[name] = map[string]bool{}
+	}
+	t.contributors[name][who] = true
+}
+
+// counts returns noisy counts for categories with enough contributors,
+// largest first, keeping at most limit (0 keeps all)
+func (t *tally) counts(minContributors int, noisy func(int) int, limit int) []Count {
+	counts := []Count{}
+	for name, n := range t.memories {
+		if len(t.contributors[name]) < minContributors {
+			continue
+		}
+		if c := noisy(n); c > 0 {
+			counts = append(counts, Count{Name: name, Count: c})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	if limit > 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
@@ -0,0 +1,282 @@
+// Package journal is a write-ahead log for captured events. Events are
+// appended to rotating segment files before they reach SQLite, so capture
+// never depends on the store being available; the agent ingests them in
+// order and deletes segments once they are fully stored.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// MaxSegmentSize is the size at which the journal starts a new segment
+const MaxSegmentSize = 4 << 20
+
+const (
+	segmentPrefix = "events-"
+	segmentSuffix = ".jsonl"
+	cursorFile    = "cursor"
+)
+
+// Position identifies the end of an entry in the journal
+type Position struct {
+	Segment int
+	Offset  int64
+}
+
+// Journal appends events to segment files and reads them back in order
+type Journal struct {
+	dir    string
+	notify chan struct{}
+
+	mu   sync.Mutex
+	w    *os.File
+	seg  int   // segment being written
+	size int64 // bytes in the segment being written
+
+	// Reader state, used by a single consumer
+	r      *os.File
+	br     *bufio.Reader
+	cursor Position // next entry to read
+}
+
+// Open opens or creates a journal in dir, resuming after the last
+// committed entry
+func Open(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{dir: dir, notify: make(chan struct{}, 1)}
+
+	segments, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 {
+		j.seg = segments[len(segments)-1]
+		j.cursor.Segment = segments[0]
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, cursorFile)); err == nil {
+		var c Position
+		if _, err := fmt.Sscanf(string(data), "%d %d", &c.Segment, &c.Offset); err == nil && c.Segment >= j.cursor.Segment {
+			j.cursor = c
+		}
+	}
+	if j.cursor.Segment > j.seg {
+		j.seg = j.cursor.Segment
+	}
+	if _, err := os.Stat(j.segmentPath(j.cursor.Segment)); os.IsNotExist(err) {
+		j.cursor.Offset = 0
+	}
+
+	// Don't append after a write torn by a crash; the reader drops the
+	// partial entry when it moves past the segment
+	if tornTail(j.segmentPath(j.seg)) {
+		j.seg++
+	}
+
+	if err := j.openWriter(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// tornTail reports whether a segment ends in a partial entry
+func tornTail(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false
+	}
+	return last[0] != '\n'
+}
+
+// Append durably writes an event to the journal
+func (j *Journal) Append(e models.Event) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.size > 0 && j.size+int64(len(line)) > MaxSegmentSize {
+		j.w.Close()
+		j.seg++
+		if err := j.openWriter(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.w.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if err := j.w.Sync(); err != nil {
+		return err
+	}
+
+	select {
+	case j.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Notify is signalled after appends
+func (j *Journal) Notify() <-chan struct{} {
+	return j.notify
+}
+
+// Next returns the next uncommitted event and the position to commit once
+// it is stored. ok is false when the journal has been read to the end.
+// Unreadable entries are skipped.
+func (j *Journal) Next() (e models.Event, pos Position, ok bool, err error) {
+	for {
+		j.mu.Lock()
+		writing := j.seg
+		j.mu.Unlock()
+
+		if j.r == nil {
+			f, err := os.Open(j.segmentPath(j.cursor.Segment))
+			if os.IsNotExist(err) && j.cursor.Segment < writing {
+				j.cursor = Position{Segment: j.cursor.Segment + 1}
+				continue
+			}
+			if err != nil {
+				return e, pos, false, err
+			}
+			if _, err := f.Seek(j.cursor.Offset, io.SeekStart); err != nil {
+				f.Close()
+				return e, pos, false, err
+			}
+			j.r, j.br = f, bufio.NewReader(f)
+		}
+
+		line, readErr := j.br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return e, pos, false, readErr
+		}
+
+		if readErr == io.EOF {
+			// A finished segment was read to the end (or ends in a torn
+			// write from a crash); move on. The segment being written may
+			// still grow, so wait for more.
+			if j.cursor.Segment < writing {
+				j.closeReader()
+				j.cursor = Position{Segment: j.cursor.Segment + 1}
+				continue
+			}
+			j.closeReader()
+			return e, pos, false, nil
+		}
+
+		j.cursor.Offset += int64(len(line))
+		if err := json.Unmarshal(line, &e); err != nil || e.ID == "" {
+			continue
+		}
+		return e, j.cursor, true, nil
+	}
+}
+
+// Commit records that everything up to pos is stored, deleting segments
+// that are no longer needed
+func (j *Journal) Commit(pos Position) error {
+	tmp := filepath.Join(j.dir, cursorFile+".tmp")
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d\n", pos.Segment, pos.Offset)), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(j.dir, cursorFile)); err != nil {
+		return err
+	}
+
+	segments, err := j.segments()
+	if err != nil {
+		return err
+	}
+	for _, seg := range segments {
+		if seg < pos.Segment {
+			os.Remove(j.segmentPath(seg))
+		}
+	}
+	return nil
+}
+
+// Close closes the journal's files
+func (j *Journal) Close() error {
+	j.closeReader()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.w.Close()
+}
+
+func (j *Journal) openWriter() error {
+	f, err := os.OpenFile(j.segmentPath(j.seg), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	j.w, j.size = f, info.Size()
+	return nil
+}
+
+func (j *Journal) closeReader() {
+	if j.r != nil {
+		j.r.Close()
+		j.r, j.br = nil, nil
+	}
+}
+
+func (j *Journal) segmentPath(seg int) string {
+	return filepath.Join(j.dir, fmt.Sprintf("%s%08d%s", segmentPrefix, seg, segmentSuffix))
+}
+
+// segments lists existing segment numbers in ascending order
+func (j *Journal) segments() ([]int, error) {
+	entries, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, n)
+	}
+	sort.Ints(segments)
+	return segments, nil
+}
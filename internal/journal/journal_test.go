@@ -0,0 +1,193 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// bigEvent is large enough that a few of them fill a segment
+func bigEvent(i int) models.Event {
+	return models.Event{
+		ID:        fmt.Sprintf("ev-%02d", i),
+		Type:      "file_change",
+		Timestamp: time.Date(2025, 1, 1, 9, 0, i, 0, time.UTC),
+		Data:      map[string]interface{}{"diff": strings.Repeat("x", MaxSegmentSize/3)},
+	}
+}
+
+func open(t *testing.T, dir string) *Journal {
+	t.Helper()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	return j
+}
+
+// next reads the next event, failing if there is none
+func next(t *testing.T, j *Journal) (models.Event, Position) {
+	t.Helper()
+	e, pos, ok, err := j.Next()
+	if err != nil || !ok {
+		t.Fatalf("next: ok = %v, err = %v", ok, err)
+	}
+	return e, pos
+}
+
+func segmentsOf(t *testing.T, j *Journal) []int {
+	t.Helper()
+	segments, err := j.segments()
+	if err != nil {
+		t.Fatalf("segments: %v", err)
+	}
+	return segments
+}
+
+func TestRotationReopenAndCommit(t *testing.T) {
+	dir := t.TempDir()
+	const total = 8
+
+	j := open(t, dir)
+	for i := 0; i < total; i++ {
+		if err := j.Append(bigEvent(i)); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+	written := segmentsOf(t, j)
+	if len(written) < 3 {
+		t.Fatalf("appends across %d bytes wrote segments %v, want a rotation", total*MaxSegmentSize/3, written)
+	}
+
+	// Store the first few, commit, then "crash" before storing the rest
+	const stored = 5
+	var pos Position
+	for i := 0; i < stored; i++ {
+		var e models.Event
+		e, pos = next(t, j)
+		if want := bigEvent(i).ID; e.ID != want {
+			t.Fatalf("event %d = %s, want %s", i, e.ID, want)
+		}
+	}
+	if err := j.Commit(pos); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	j.Close()
+
+	kept := segmentsOf(t, j)
+	for _, seg := range written {
+		if want := seg >= pos.Segment; slices.Contains(kept, seg) != want {
+			t.Errorf("segment %d kept = %v, want %v (committed up to segment %d)", seg, !want, want, pos.Segment)
+		}
+	}
+
+	// Reopening replays exactly the uncommitted events, in order
+	j = open(t, dir)
+	defer j.Close()
+	for i := stored; i < total; i++ {
+		e, _ := next(t, j)
+		if want := bigEvent(i).ID; e.ID != want {
+			t.Fatalf("replayed %s, want %s", e.ID, want)
+		}
+	}
+	if e, _, ok, err := j.Next(); ok || err != nil {
+		t.Fatalf("after replay: got %s, ok = %v, err = %v; want the end", e.ID, ok, err)
+	}
+
+	// Appends after reopening follow the replayed events
+	if err := j.Append(bigEvent(total)); err != nil {
+		t.Fatalf("append after reopen: %v", err)
+	}
+	if e, _ := next(t, j); e.ID != bigEvent(total).ID {
+		t.Fatalf("read %s after reopen, want %s", e.ID, bigEvent(total).ID)
+	}
+}
+
+func TestCommitAtEndRemovesFinishedSegments(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	defer j.Close()
+
+	for i := 0; i < 6; i++ {
+		if err := j.Append(bigEvent(i)); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	var pos Position
+	for i := 0; i < 6; i++ {
+		_, pos = next(t, j)
+	}
+	if err := j.Commit(pos); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if got := segmentsOf(t, j); !slices.Equal(got, []int{pos.Segment}) {
+		t.Fatalf("segments after committing everything = %v, want only the one being written (%d)", got, pos.Segment)
+	}
+}
+
+func TestTornWriteIsSkipped(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	first := models.Event{ID: "first", Type: "terminal_cmd", Data: map[string]interface{}{"command": "go test"}}
+	if err := j.Append(first); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	seg := j.seg
+	j.Close()
+
+	// A crash mid-write leaves a partial entry
+	f, err := os.OpenFile(j.segmentPath(seg), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"torn","type":"termi`)
+	f.Close()
+
+	j = open(t, dir)
+	defer j.Close()
+	if j.seg == seg {
+		t.Fatalf("reopened journal appends to segment %d, which ends in a torn write", seg)
+	}
+	second := models.Event{ID: "second", Type: "terminal_cmd"}
+	if err := j.Append(second); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+
+	var ids []string
+	for {
+		e, _, ok, err := j.Next()
+		if err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		if !ok {
+			break
+		}
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"first", "second"}; !slices.Equal(ids, want) {
+		t.Fatalf("read %v, want %v", ids, want)
+	}
+}
+
+func TestCorruptCursorIsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	j := open(t, dir)
+	if err := j.Append(models.Event{ID: "kept", Type: "terminal_cmd"}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	j.Close()
+	if err := os.WriteFile(dir+"/"+cursorFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	j = open(t, dir)
+	defer j.Close()
+	if e, _ := next(t, j); e.ID != "kept" {
+		t.Fatalf("read %s, want kept", e.ID)
+	}
+}
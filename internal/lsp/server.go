@@ -0,0 +1,381 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// maxPerFile caps how many memories are surfaced for a single file
+const maxPerFile = 5
+
+// showMemoryCommand is the code action command that displays a memory
+const showMemoryCommand = "memorypilot.showMemory"
+
+// Server implements a minimal Language Server Protocol server over stdio.
+// Memories that reference an open file are published as hint diagnostics on
+// its first line, shown on hover, and offered as code actions.
+type Server struct {
+	store  *store.Store
+	reader *bufio.Reader
+	writer io.Writer
+	root   string
+	docs   map[string]string // uri -> text
+}
+
+// NewServer creates a new LSP server
+func NewServer(dbPath string) (*Server, error) {
+	s, err := store.New(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open store: %w", err)
+	}
+
+	return &Server{
+		store:  s,
+		reader: bufio.NewReader(os.Stdin),
+		writer: os.Stdout,
+		docs:   make(map[string]string),
+	}, nil
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type textDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	Position       position `json:"position"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// Run serves requests until the client sends exit or closes stdin
+func (s *Server) Run() error {
+	log.SetOutput(os.Stderr)
+	defer s.store.Close()
+
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read error: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			log.Printf("lsp: malformed message: %v", err)
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+		s.handle(&req)
+	}
+}
+
+func (s *Server) handle(req *request) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			RootURI  string `json:"rootUri"`
+			RootPath string `json:"rootPath"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.root = params.RootPath
+		if params.RootURI != "" {
+			s.root = uriToPath(params.RootURI)
+		}
+
+		s.reply(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   1, // full
+				"hoverProvider":      true,
+				"codeActionProvider": true,
+				"executeCommandProvider": map[string]interface{}{
+					"commands": []string{showMemoryCommand},
+				},
+			},
+			"serverInfo": map[string]string{
+				"name":    "memorypilot",
+				"version": "0.1.0",
+			},
+		})
+
+	case "shutdown":
+		s.reply(req.ID, nil)
+
+	case "textDocument/didOpen", "textDocument/didSave":
+		var params textDocumentParams
+		json.Unmarshal(req.Params, &params)
+		if params.TextDocument.Text != "" {
+			s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		}
+		s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params textDocumentParams
+		json.Unmarshal(req.Params, &params)
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+
+	case "textDocument/didClose":
+		var params textDocumentParams
+		json.Unmarshal(req.Params, &params)
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []interface{}{},
+		})
+
+	case "textDocument/hover":
+		var params textDocumentParams
+		json.Unmarshal(req.Params, &params)
+		s.reply(req.ID, s.hover(params.TextDocument.URI, params.Position))
+
+	case "textDocument/codeAction":
+		var params textDocumentParams
+		json.Unmarshal(req.Params, &params)
+		s.reply(req.ID, s.codeActions(params.TextDocument.URI))
+
+	case "workspace/executeCommand":
+		var params struct {
+			Command   string            `json:"command"`
+			Arguments []json.RawMessage `json:"arguments"`
+		}
+		json.Unmarshal(req.Params, &params)
+		s.executeCommand(params.Command, params.Arguments)
+		s.reply(req.ID, nil)
+
+	default:
+		// Requests we don't support get an error; notifications are ignored
+		if len(req.ID) > 0 {
+			s.replyError(req.ID, -32601, "Method not found")
+		}
+	}
+}
+
+// fileMemories returns memories referencing the document
+func (s *Server) fileMemories(uri string) []models.Memory {
+	path := uriToPath(uri)
+	rel := filepath.Base(path)
+	if s.root != "" {
+		if r, err := filepath.Rel(s.root, path); err == nil && !strings.HasPrefix(r, "..") {
+			rel = r
+		}
+	}
+
+	memories, err := s.store.GetMemoriesReferencingFile(path, filepath.ToSlash(rel), maxPerFile)
+	if err != nil {
+		log.Printf("lsp: lookup failed for %s: %v", path, err)
+		return nil
+	}
+	return memories
+}
+
+func (s *Server) publishDiagnostics(uri string) {
+	diagnostics := []map[string]interface{}{}
+	for _, m := range s.fileMemories(uri) {
+		diagnostics = append(diagnostics, map[string]interface{}{
+			"range":    lspRange{},
+			"severity": 4, // hint
+			"source":   "memorypilot",
+			"code":     m.ID,
+			"message":  fmt.Sprintf("[%s] %s", m.Type, m.Summary),
+		})
+	}
+
+	s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// hover shows file memories on the first line, and memories tagged with the
+// word under the cursor elsewhere
+func (s *Server) hover(uri string, pos position) interface{} {
+	var memories []models.Memory
+	if pos.Line == 0 {
+		memories = s.fileMemories(uri)
+	} else if word := wordAt(s.docs[uri], pos); len(word) >= 3 {
+		candidates, err := s.store.Recall(models.RecallRequest{Query: word, Limit: 20})
+		if err != nil {
+			log.Printf("lsp: recall failed: %v", err)
+		}
+		for _, m := range candidates {
+			if hasTopic(m, word) && len(memories) < maxPerFile {
+				memories = append(memories, m)
+			}
+		}
+	}
+	if len(memories) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**MemoryPilot**\n\n")
+	for _, m := range memories {
+		sb.WriteString(fmt.Sprintf("- **%s** %s _(%s)_\n", m.Type, m.Content, m.CreatedAt.Format("2006-01-02")))
+	}
+
+	return map[string]interface{}{
+		"contents": map[string]string{"kind": "markdown", "value": sb.String()},
+	}
+}
+
+func (s *Server) codeActions(uri string) []map[string]interface{} {
+	actions := []map[string]interface{}{}
+	for _, m := range s.fileMemories(uri) {
+		actions = append(actions, map[string]interface{}{
+			"title": "MemoryPilot: " + textutil.Truncate(m.Summary, 60),
+			"kind":  "quickfix",
+			"command": map[string]interface{}{
+				"title":     "Show memory",
+				"command":   showMemoryCommand,
+				"arguments": []string{m.ID},
+			},
+		})
+	}
+	return actions
+}
+
+func (s *Server) executeCommand(command string, args []json.RawMessage) {
+	if command != showMemoryCommand || len(args) == 0 {
+		return
+	}
+	var id string
+	json.Unmarshal(args[0], &id)
+
+	m, err := s.store.GetMemory(id)
+	if err != nil || m == nil {
+		return
+	}
+	s.notify("window/showMessage", map[string]interface{}{
+		"type":    3, // info
+		"message": fmt.Sprintf("[%s] %s", m.Type, m.Content),
+	})
+}
+
+// readMessage reads one Content-Length framed message
+func (s *Server) readMessage() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if value, ok := strings.CutPrefix(line, "Content-Length:"); ok {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("bad Content-Length: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	_, err := io.ReadFull(s.reader, body)
+	return body, err
+}
+
+func (s *Server) write(msg interface{}) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("lsp: failed to encode message: %v", err)
+		return
+	}
+	fmt.Fprintf(s.writer, "Content-Length: %d\r\n\r\n%s", len(data), data)
+}
+
+func (s *Server) reply(id json.RawMessage, result interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+}
+
+func (s *Server) replyError(id json.RawMessage, code int, message string) {
+	s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// wordAt returns the identifier under the cursor
+func wordAt(text string, pos position) string {
+	lines := strings.Split(text, "\n")
+	if pos.Line >= len(lines) {
+		return ""
+	}
+	line := []rune(lines[pos.Line])
+	if pos.Character > len(line) {
+		return ""
+	}
+
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' }
+	start, end := pos.Character, pos.Character
+	for start > 0 && isWord(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWord(line[end]) {
+		end++
+	}
+	return string(line[start:end])
+}
+
+func hasTopic(m models.Memory, word string) bool {
+	for _, t := range m.Topics {
+		if strings.EqualFold(t, word) {
+			return true
+		}
+	}
+	return false
+}
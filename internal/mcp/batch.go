@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/recall"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+func (s *Server) handleRecallBatch(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Queries []string `json:"queries"`
+		Budget  int      `json:"budget"`
+		Limit   int      `json:"limit"`
+	}
+	json.Unmarshal(args, &params)
+
+	batch := models.BatchRecallRequest{Budget: params.Budget}
+	for _, q := range params.Queries {
+		batch.Queries = append(batch.Queries, models.RecallRequest{Query: q, Limit: params.Limit})
+	}
+	resp, err := recall.Batch(batch, s.search)
+	if err != nil {
+		code := -32000
+		if errors.Is(err, recall.ErrInvalid) {
+			code = -32602
+		}
+		s.sendError(req.ID, code, err.Error())
+		return
+	}
+
+	var sb strings.Builder
+	for _, r := range resp.Results {
+		sb.WriteString(fmt.Sprintf("## %s\n\n", r.Query))
+		if len(r.Memories) == 0 {
+			sb.WriteString("No new memories.\n\n")
+			continue
+		}
+		for i, m := range r.Memories {
+			sb.WriteString(fmt.Sprintf("%d. [%s] %s\n   %s\n   Topics: %v\n\n", i+1, m.Type, m.Summary, m.Content, m.Topics))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("~%d of %d tokens used", resp.Tokens, resp.Budget))
+	if resp.Duplicates > 0 {
+		sb.WriteString(fmt.Sprintf("; %d repeat matches shown under another question", resp.Duplicates))
+	}
+	if resp.Dropped > 0 {
+		sb.WriteString(fmt.Sprintf("; %d matches left out to fit the budget", resp.Dropped))
+	}
+	s.sendText(req.ID, sb.String())
+}
@@ -0,0 +1,344 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// MCP over HTTP serves many clients from one process. The streamable HTTP
+// transport (protocol 2025-03-26) takes JSON-RPC messages POSTed to /mcp
+// and answers in the HTTP response. The older HTTP+SSE transport
+// (2024-11-05) is served too: GET /sse opens an event stream whose first
+// event names the URL to POST messages to, and answers arrive on the stream.
+
+const (
+	// sessionHeader carries the session assigned at initialization
+	sessionHeader = "Mcp-Session-Id"
+
+	// maxMessage bounds a POSTed message or batch
+	maxMessage = 4 << 20
+
+	// sessionIdle is how long a streamable session lasts without requests;
+	// clients aren't required to end them
+	sessionIdle = time.Hour
+
+	// keepAlive is how often an idle event stream gets a comment, so
+	// proxies don't close it
+	keepAlive = 30 * time.Second
+)
+
+// HTTPServer serves MCP over HTTP until Shutdown is called
+type HTTPServer struct {
+	http      *http.Server
+	transport *httpTransport
+}
+
+// httpTransport tracks sessions across requests. Messages are handled
+// statelessly; sessions exist for clients that expect one and to route
+// answers to SSE streams.
+type httpTransport struct {
+	server  *Server
+	token   string
+	mu      sync.Mutex
+	session map[string]*session
+	closing chan struct{}
+}
+
+type session struct {
+	lastSeen time.Time
+	events   chan []byte   // answers for an SSE stream; nil for streamable sessions
+	done     chan struct{} // closed when the SSE stream ends
+}
+
+// NewHTTPServer serves s on addr. With a token, every request must carry
+// "Authorization: Bearer <token>"; without one, browsers on other origins
+// are refused so web pages can't reach a local server.
+func (s *Server) NewHTTPServer(addr, token string) *HTTPServer {
+	t := &httpTransport{
+		server:  s,
+		token:   token,
+		session: make(map[string]*session),
+		closing: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /mcp", t.post)
+	mux.HandleFunc("DELETE /mcp", t.endSession)
+	mux.HandleFunc("GET /mcp", func(w http.ResponseWriter, r *http.Request) {
+		// Nothing is ever sent unprompted, so there's no stream to open
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "server-initiated messages are not supported", http.StatusMethodNotAllowed)
+	})
+	mux.HandleFunc("GET /sse", t.openStream)
+	mux.HandleFunc("POST /messages", t.postMessage)
+
+	return &HTTPServer{
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           t.guard(mux),
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		transport: t,
+	}
+}
+
+// Addr returns the address the server listens on
+func (h *HTTPServer) Addr() string {
+	return h.http.Addr
+}
+
+// ListenAndServe serves until Shutdown is called
+func (h *HTTPServer) ListenAndServe() error {
+	err := h.http.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown ends open event streams, then waits for requests in flight
+func (h *HTTPServer) Shutdown(ctx context.Context) error {
+	close(h.transport.closing)
+	return h.http.Shutdown(ctx)
+}
+
+// guard checks the bearer token, or the origin when there is none
+func (t *httpTransport) guard(next http.Handler) http.Handler {
+	want := []byte("Bearer " + t.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if t.token != "" {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="memorypilot"`)
+				http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+				return
+			}
+		} else if origin := r.Header.Get("Origin"); origin != "" && !loopbackOrigin(origin) {
+			http.Error(w, "cross-origin requests need a token", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loopbackOrigin reports whether a browser origin is this machine
+func loopbackOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// post handles the streamable transport: a message or batch in, the
+// answers out. Notifications and responses alone get 202 Accepted.
+func (t *httpTransport) post(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessage))
+	if err != nil {
+		http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	id := r.Header.Get(sessionHeader)
+	if id != "" && !t.touch(id) {
+		http.Error(w, "unknown or expired session", http.StatusNotFound)
+		return
+	}
+
+	messages, batch := splitBatch(body)
+	var answers [][]byte
+	for _, msg := range messages {
+		if id == "" && isInitialize(msg) {
+			id = t.open(nil)
+			w.Header().Set(sessionHeader, id)
+		}
+		if answer := t.server.handle(msg, id); answer != nil {
+			answers = append(answers, answer)
+		}
+	}
+
+	if len(answers) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		w.Write([]byte("["))
+		w.Write(bytes.Join(answers, []byte(",")))
+		w.Write([]byte("]"))
+		return
+	}
+	w.Write(answers[0])
+}
+
+// endSession lets a streamable client end its session
+func (t *httpTransport) endSession(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(sessionHeader)
+	if id == "" || !t.touch(id) {
+		http.Error(w, "unknown or expired session", http.StatusNotFound)
+		return
+	}
+	t.close(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// openStream serves the HTTP+SSE transport's event stream, which lasts as
+// long as the client's session
+func (t *httpTransport) openStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	events := make(chan []byte, 16)
+	id := t.open(events)
+	defer t.close(id)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "event: endpoint\ndata: /messages?sessionId=%s\n\n", id)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.closing:
+			return
+		case msg := <-events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// postMessage takes a message for an SSE session; its answer goes out on
+// the session's stream
+func (t *httpTransport) postMessage(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("sessionId")
+	t.mu.Lock()
+	sess := t.session[id]
+	t.mu.Unlock()
+	if sess == nil || sess.events == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessage))
+	if err != nil {
+		http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	messages, _ := splitBatch(body)
+	for _, msg := range messages {
+		answer := t.server.handle(msg, id)
+		if answer == nil {
+			continue
+		}
+		select {
+		case sess.events <- answer:
+		case <-sess.done:
+			http.Error(w, "session ended", http.StatusNotFound)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// open starts a session, dropping streamable sessions left idle
+func (t *httpTransport) open(events chan []byte) string {
+	id := ulid.Make().String()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for old, sess := range t.session {
+		if sess.events == nil && time.Since(sess.lastSeen) > sessionIdle {
+			delete(t.session, old)
+		}
+	}
+	t.session[id] = &session{lastSeen: time.Now(), events: events, done: make(chan struct{})}
+	return id
+}
+
+// touch marks a session as used, reporting whether it exists
+func (t *httpTransport) touch(id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sess, ok := t.session[id]
+	if !ok || (sess.events == nil && time.Since(sess.lastSeen) > sessionIdle) {
+		return false
+	}
+	sess.lastSeen = time.Now()
+	return true
+}
+
+func (t *httpTransport) close(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sess, ok := t.session[id]; ok {
+		close(sess.done)
+		delete(t.session, id)
+	}
+}
+
+// handle runs one JSON-RPC message from a session ("" if the client has
+// none) and returns its answer, or nil for a notification or a client's
+// response
+func (s *Server) handle(msg json.RawMessage, session string) []byte {
+	var out bytes.Buffer
+	conn := *s
+	conn.writer = &out
+	conn.session = session
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		conn.sendError(nil, -32700, "Parse error")
+		return bytes.TrimSpace(out.Bytes())
+	}
+	if req.ID == nil || req.Method == "" {
+		return nil
+	}
+	conn.handleRequest(&req)
+	return bytes.TrimSpace(out.Bytes())
+}
+
+// splitBatch splits a body into its messages, reporting whether it was a
+// batch. A malformed batch is passed on whole to be answered with a parse
+// error.
+func splitBatch(body []byte) ([]json.RawMessage, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return []json.RawMessage{trimmed}, false
+	}
+	var messages []json.RawMessage
+	if err := json.Unmarshal(trimmed, &messages); err != nil {
+		return []json.RawMessage{trimmed}, false
+	}
+	return messages, true
+}
+
+func isInitialize(msg json.RawMessage) bool {
+	var req struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(msg, &req)
+	return req.Method == "initialize"
+}
@@ -0,0 +1,376 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// Timeline defaults
+	timelineHours = 24
+	timelineLimit = 50
+
+	// pendingLimit is how many memories awaiting review are listed
+	pendingLimit = 20
+)
+
+func (s *Server) handleForget(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(args, &params)
+	if params.ID == "" {
+		s.sendError(req.ID, -32602, "id is required")
+		return
+	}
+
+	m, err := s.store.GetMemory(params.ID)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if m == nil {
+		s.sendError(req.ID, -32602, fmt.Sprintf("memory %s not found", params.ID))
+		return
+	}
+	if err := s.store.DeleteMemory(m.ID); err != nil {
+		if errors.Is(err, store.ErrHeld) {
+			s.sendError(req.ID, -32000, fmt.Sprintf("memory %s is under legal hold and can't be deleted", m.ID))
+			return
+		}
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+
+	s.sendText(req.ID, fmt.Sprintf("Forgot %s: [%s] %s", m.ID, m.Type, m.Summary))
+}
+
+func (s *Server) handleUpdate(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		ID      string    `json:"id"`
+		Content *string   `json:"content"`
+		Summary *string   `json:"summary"`
+		Type    *string   `json:"type"`
+		Topics  *[]string `json:"topics"`
+		Version *int      `json:"version"`
+	}
+	json.Unmarshal(args, &params)
+	if params.ID == "" {
+		s.sendError(req.ID, -32602, "id is required")
+		return
+	}
+
+	m, err := s.store.GetMemory(params.ID)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if m == nil {
+		s.sendError(req.ID, -32602, fmt.Sprintf("memory %s not found", params.ID))
+		return
+	}
+	if params.Version != nil && *params.Version != m.Version {
+		s.sendError(req.ID, -32000, fmt.Sprintf("memory %s was changed since version %d (now %d); recall it again and reapply the edit",
+			m.ID, *params.Version, m.Version))
+		return
+	}
+
+	content := m.Content
+	if params.Content != nil {
+		if strings.TrimSpace(*params.Content) == "" {
+			s.sendError(req.ID, -32602, "content can't be empty")
+			return
+		}
+		m.Content = *params.Content
+		if params.Summary == nil {
+			m.Summary = models.Summarize(m.Content)
+		}
+	}
+	if params.Summary != nil {
+		m.Summary = *params.Summary
+	}
+	if params.Type != nil {
+		if !models.MemoryType(*params.Type).Valid() {
+			s.sendError(req.ID, -32602, fmt.Sprintf("unknown memory type %q", *params.Type))
+			return
+		}
+		m.Type = models.MemoryType(*params.Type)
+	}
+	if params.Topics != nil {
+		m.Topics = *params.Topics
+	}
+
+	// Edited content is re-embedded; otherwise the stored embedding stays
+	if m.Content != content {
+		if emb, err := s.embedder.Embed(m.Content); err == nil {
+			m.Embedding = emb
+		}
+	} else {
+		embeddings, err := s.store.MemoryEmbeddings([]string{m.ID})
+		if err != nil {
+			s.sendError(req.ID, -32000, err.Error())
+			return
+		}
+		m.Embedding = embeddings[m.ID]
+	}
+
+	if err := s.store.UpdateMemory(m); err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			s.sendError(req.ID, -32000, fmt.Sprintf("memory %s was changed while updating it; recall it again and reapply the edit", m.ID))
+		case errors.Is(err, store.ErrDuplicate):
+			s.sendError(req.ID, -32000, "an identical memory already exists")
+		case errors.Is(err, store.ErrHeld):
+			s.sendError(req.ID, -32000, fmt.Sprintf("memory %s is under legal hold and can't be changed", m.ID))
+		default:
+			s.sendError(req.ID, -32000, err.Error())
+		}
+		return
+	}
+
+	s.sendText(req.ID, fmt.Sprintf("Updated %s to version %d: [%s] %s\n%s\nTopics: %v", m.ID, m.Version, m.Type, m.Summary, m.Content, m.Topics))
+}
+
+func (s *Server) handlePending(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Limit int `json:"limit"`
+	}
+	json.Unmarshal(args, &params)
+	if params.Limit <= 0 {
+		params.Limit = pendingLimit
+	}
+
+	pending, err := s.store.ListPending(params.Limit)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if len(pending) == 0 {
+		s.sendText(req.ID, "Nothing awaiting review.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d memories awaiting review:\n\n", len(pending)))
+	for _, m := range pending {
+		sb.WriteString(fmt.Sprintf("- %s [%s] %s\n  %s\n  %.0f%% confidence, created %s\n",
+			m.ID, m.Type, m.Summary, strings.ReplaceAll(m.Content, "\n", "\n  "), m.Confidence*100, m.CreatedAt.Format("2006-01-02")))
+	}
+	s.sendText(req.ID, sb.String())
+}
+
+func (s *Server) handleReview(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		IDs      []string `json:"ids"`
+		Decision string   `json:"decision"`
+	}
+	json.Unmarshal(args, &params)
+	if len(params.IDs) == 0 {
+		s.sendError(req.ID, -32602, "ids is required")
+		return
+	}
+	var status models.MemoryStatus
+	var verb string
+	switch params.Decision {
+	case "approve":
+		status, verb = models.MemoryStatusApproved, "Approved"
+	case "reject":
+		status, verb = models.MemoryStatusRejected, "Rejected"
+	default:
+		s.sendError(req.ID, -32602, "decision must be approve or reject")
+		return
+	}
+
+	// Only the review queue is decided on: approved memories stay put
+	var lines []string
+	for _, id := range params.IDs {
+		m, err := s.store.GetMemory(id)
+		if err != nil {
+			s.sendError(req.ID, -32000, err.Error())
+			return
+		}
+		switch {
+		case m == nil:
+			lines = append(lines, fmt.Sprintf("%s: not found", id))
+		case m.Status != models.MemoryStatusPending:
+			lines = append(lines, fmt.Sprintf("%s: not awaiting review (%s)", id, m.Status))
+		default:
+			if err := s.store.SetStatus(m.ID, status); err != nil {
+				lines = append(lines, fmt.Sprintf("%s: %v", id, err))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s %s: [%s] %s", verb, m.ID, m.Type, m.Summary))
+		}
+	}
+	s.sendText(req.ID, strings.Join(lines, "\n"))
+}
+
+func (s *Server) handleListProjects(req *JSONRPCRequest) {
+	list, err := s.store.ListProjects()
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if len(list) == 0 {
+		s.sendText(req.ID, "No tracked projects yet.")
+		return
+	}
+
+	// One pass over the memories to count them per project
+	memories, err := s.store.ListMemories(models.RecallRequest{})
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	counts := make(map[string]int)
+	for _, m := range memories {
+		if m.ProjectID != nil {
+			counts[*m.ProjectID]++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d tracked projects:\n\n", len(list)))
+	for _, p := range list {
+		sb.WriteString(fmt.Sprintf("- %s (%s)\n  id %s, %d memories, last seen %s\n",
+			p.Name, p.Path, p.ID, counts[p.ID], p.LastSeen.Format("2006-01-02 15:04")))
+	}
+	s.sendText(req.ID, sb.String())
+}
+
+func (s *Server) handleTimeline(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Hours   float64 `json:"hours"`
+		Project string  `json:"project"`
+		Limit   int     `json:"limit"`
+	}
+	json.Unmarshal(args, &params)
+	if params.Hours <= 0 {
+		params.Hours = timelineHours
+	}
+	if params.Limit <= 0 {
+		params.Limit = timelineLimit
+	}
+
+	var project *models.Project
+	if params.Project != "" {
+		var err error
+		if project, err = s.lookupProject(params.Project); err != nil {
+			s.sendError(req.ID, -32000, err.Error())
+			return
+		}
+		if project == nil {
+			s.sendError(req.ID, -32602, fmt.Sprintf("no project %q", params.Project))
+			return
+		}
+	}
+	inProject := func(id *string) bool {
+		return project == nil || (id != nil && *id == project.ID)
+	}
+
+	until := time.Now()
+	since := until.Add(-time.Duration(params.Hours * float64(time.Hour)))
+	events, err := s.store.GetEventsBetween(since, until)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	memories, err := s.store.GetMemoriesCreatedBetween(since, until)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+
+	// File changes are too many to list; they're counted instead
+	type entry struct {
+		at   time.Time
+		text string
+	}
+	var entries []entry
+	files := make(map[string]bool)
+	fileChanges := 0
+	for _, e := range events {
+		if !inProject(e.ProjectID) {
+			continue
+		}
+		if e.Type == "file_change" {
+			fileChanges += 1 + e.Repeats
+			if path, ok := e.Data["path"].(string); ok {
+				files[path] = true
+			}
+			continue
+		}
+		entries = append(entries, entry{e.Timestamp, fmt.Sprintf("%s %s", e.Type, eventSummary(e))})
+	}
+	for _, m := range memories {
+		if inProject(m.ProjectID) {
+			entries = append(entries, entry{m.CreatedAt, fmt.Sprintf("memory [%s] %s (%s)", m.Type, m.Summary, m.ID)})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].at.Before(entries[j].at) })
+
+	var sb strings.Builder
+	title := "all projects"
+	if project != nil {
+		title = project.Name
+	}
+	sb.WriteString(fmt.Sprintf("Activity in %s over the last %gh:\n\n", title, params.Hours))
+	if len(entries) == 0 && fileChanges == 0 {
+		sb.WriteString("Nothing recorded.\n")
+	}
+	if len(entries) > params.Limit {
+		sb.WriteString(fmt.Sprintf("(showing the latest %d of %d entries)\n", params.Limit, len(entries)))
+		entries = entries[len(entries)-params.Limit:]
+	}
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("- %s %s\n", e.at.Local().Format("01-02 15:04"), e.text))
+	}
+	if fileChanges > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d file changes across %d files\n", fileChanges, len(files)))
+	}
+	s.sendText(req.ID, sb.String())
+}
+
+// lookupProject finds a project by path or name, returning nil if there is
+// none
+func (s *Server) lookupProject(nameOrPath string) (*models.Project, error) {
+	if info, err := os.Stat(nameOrPath); err == nil && info.IsDir() {
+		abs, _ := filepath.Abs(nameOrPath)
+		if p, err := s.store.GetProjectByPath(abs); err != nil || p != nil {
+			return p, err
+		}
+	}
+	return s.store.GetProjectByName(nameOrPath)
+}
+
+// eventSummary picks the most telling field of an event's data
+func eventSummary(e models.Event) string {
+	for _, key := range []string{"message", "tag", "command", "path", "section", "source"} {
+		if v, ok := e.Data[key].(string); ok && v != "" {
+			line, _, _ := strings.Cut(v, "\n")
+			if len(line) > 100 {
+				line = line[:97] + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// sendText sends a tool result made of one text block
+func (s *Server) sendText(id interface{}, text string) {
+	s.sendResult(id, map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": text},
+		},
+	})
+}
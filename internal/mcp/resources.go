@@ -0,0 +1,230 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/prime"
+	"github.com/memorypilot/memorypilot/internal/projects"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Resource URIs. Projects are addressed by ID, which stays stable when a
+// project is renamed.
+const (
+	resourceScheme   = "memorypilot://"
+	recentURI        = resourceScheme + "memories/recent"
+	personalURI      = resourceScheme + "memories/personal"
+	projectDigestFmt = resourceScheme + "projects/%s/digest"
+	projectRecentFmt = resourceScheme + "projects/%s/recent"
+)
+
+// Resource sizes
+const (
+	digestLimit = 15
+	recentLimit = 20
+)
+
+func (s *Server) handleResourcesList(req *JSONRPCRequest) {
+	resources := []map[string]interface{}{
+		{
+			"uri":         recentURI,
+			"name":        "Recent memories",
+			"description": "The most recently created memories across all projects",
+			"mimeType":    "text/markdown",
+		},
+		{
+			"uri":         personalURI,
+			"name":        "Personal memories",
+			"description": "Top memories outside any project, such as general preferences",
+			"mimeType":    "text/markdown",
+		},
+	}
+
+	list, err := s.store.ListProjects()
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	for _, p := range list {
+		resources = append(resources,
+			map[string]interface{}{
+				"uri":         fmt.Sprintf(projectDigestFmt, p.ID),
+				"name":        p.Name + " digest",
+				"description": fmt.Sprintf("Stack and top memories for %s (%s)", p.Name, p.Path),
+				"mimeType":    "text/markdown",
+			},
+			map[string]interface{}{
+				"uri":         fmt.Sprintf(projectRecentFmt, p.ID),
+				"name":        p.Name + " recent",
+				"description": fmt.Sprintf("Recent memories for %s", p.Name),
+				"mimeType":    "text/markdown",
+			},
+		)
+	}
+
+	s.sendResult(req.ID, map[string]interface{}{"resources": resources})
+}
+
+func (s *Server) handleResourceTemplatesList(req *JSONRPCRequest) {
+	templates := []map[string]interface{}{
+		{
+			"uriTemplate": fmt.Sprintf(projectDigestFmt, "{projectId}"),
+			"name":        "Project digest",
+			"description": "Stack and top memories for a project",
+			"mimeType":    "text/markdown",
+		},
+		{
+			"uriTemplate": fmt.Sprintf(projectRecentFmt, "{projectId}"),
+			"name":        "Project recent memories",
+			"description": "Recent memories for a project",
+			"mimeType":    "text/markdown",
+		},
+	}
+	s.sendResult(req.ID, map[string]interface{}{"resourceTemplates": templates})
+}
+
+func (s *Server) handleResourcesRead(req *JSONRPCRequest) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		s.sendError(req.ID, -32602, "Invalid params")
+		return
+	}
+
+	text, err := s.readResource(params.URI)
+	if err != nil {
+		s.sendError(req.ID, -32002, err.Error())
+		return
+	}
+
+	s.sendResult(req.ID, map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"uri": params.URI, "mimeType": "text/markdown", "text": text},
+		},
+	})
+}
+
+// readResource renders the resource at uri
+func (s *Server) readResource(uri string) (string, error) {
+	switch uri {
+	case recentURI:
+		memories, err := s.store.ListMemories(models.RecallRequest{})
+		if err != nil {
+			return "", err
+		}
+		return formatRecent("Recent memories", memories), nil
+	case personalURI:
+		memories, err := prime.Load(s.store, nil)
+		if err != nil {
+			return "", err
+		}
+		return formatDigest("Personal memories", "", memories), nil
+	}
+
+	rest := strings.TrimPrefix(uri, resourceScheme+"projects/")
+	id, kind, ok := strings.Cut(rest, "/")
+	if rest == uri || !ok {
+		return "", fmt.Errorf("resource not found: %s", uri)
+	}
+	project, err := s.projectByID(id)
+	if err != nil {
+		return "", err
+	}
+	if project == nil {
+		return "", fmt.Errorf("resource not found: %s", uri)
+	}
+
+	memories, err := s.store.ListMemories(models.RecallRequest{ProjectID: &project.ID})
+	if err != nil {
+		return "", err
+	}
+	switch kind {
+	case "digest":
+		var stack string
+		if facts, err := s.store.GetProjectFacts(project.ID); err == nil && len(facts) > 0 {
+			stack = formatStack(projects.StackFromFacts(facts))
+		} else {
+			stack = formatStack(projects.DetectStack(project.Path))
+		}
+		return formatDigest(fmt.Sprintf("%s (%s)", project.Name, project.Path), stack, memories), nil
+	case "recent":
+		var own []models.Memory
+		for _, m := range memories {
+			if m.ProjectID != nil && *m.ProjectID == project.ID {
+				own = append(own, m)
+			}
+		}
+		return formatRecent("Recent memories for "+project.Name, own), nil
+	}
+	return "", fmt.Errorf("resource not found: %s", uri)
+}
+
+// projectByID looks a project up by ID, returning nil if there is none
+func (s *Server) projectByID(id string) (*models.Project, error) {
+	list, err := s.store.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == id {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// formatDigest renders a stack and the top memories, ranked the way prime
+// ranks them for rules files
+func formatDigest(title, stack string, memories []models.Memory) string {
+	var sb strings.Builder
+	sb.WriteString("# " + title + "\n\n")
+	if stack != "" {
+		sb.WriteString(stack + "\n")
+	}
+
+	counts := make(map[models.MemoryType]int)
+	for _, m := range memories {
+		counts[m.Type]++
+	}
+	if len(memories) == 0 {
+		sb.WriteString("No memories yet.\n")
+		return sb.String()
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%d %s", counts[models.MemoryType(t)], t)
+	}
+	sb.WriteString(fmt.Sprintf("%d memories: %s\n\n", len(memories), strings.Join(parts, ", ")))
+
+	for _, m := range prime.Select(memories, digestLimit, time.Now()) {
+		sb.WriteString(fmt.Sprintf("- [%s] %s\n", m.Type, strings.Join(strings.Fields(m.Content), " ")))
+	}
+	return sb.String()
+}
+
+// formatRecent renders the newest memories, which arrive newest first
+func formatRecent(title string, memories []models.Memory) string {
+	var sb strings.Builder
+	sb.WriteString("# " + title + "\n\n")
+	if len(memories) == 0 {
+		sb.WriteString("No memories yet.\n")
+		return sb.String()
+	}
+	if len(memories) > recentLimit {
+		memories = memories[:recentLimit]
+	}
+	for _, m := range memories {
+		sb.WriteString(fmt.Sprintf("- %s [%s] %s\n", m.CreatedAt.Format("2006-01-02"), m.Type, m.Summary))
+	}
+	return sb.String()
+}
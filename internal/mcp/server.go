@@ -3,20 +3,35 @@ package mcp
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
-
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/projects"
+	"github.com/memorypilot/memorypilot/internal/rag"
+	"github.com/memorypilot/memorypilot/internal/snapshot"
 	"github.com/memorypilot/memorypilot/internal/store"
 	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
 )
 
-// Server implements the MCP protocol over stdio
+// Server implements the MCP protocol over stdio, or over HTTP for many
+// clients at once
 type Server struct {
-	store  *store.Store
-	reader *bufio.Reader
-	writer io.Writer
+	store     *store.Store
+	embedder  embedding.Embedder
+	extractor extractor.Extractor // graduates session memories; nil if extraction is off
+	reader    *bufio.Reader
+	writer    io.Writer
+	session   string // the client's session, which session memories belong to
 }
 
 // NewServer creates a new MCP server
@@ -26,16 +41,57 @@ func NewServer(dbPath string) (*Server, error) {
 		return nil, fmt.Errorf("failed to open store: %w", err)
 	}
 
+	server := NewServerFor(s, nil)
+	server.SetEmbedder(embedding.FromEnv(embedding.LocalModelDir(filepath.Dir(dbPath))))
+	return server, nil
+}
+
+// NewServerFor serves an already open store, such as the daemon's
+func NewServerFor(s *store.Store, embedder embedding.Embedder) *Server {
 	return &Server{
-		store:  s,
-		reader: bufio.NewReader(os.Stdin),
-		writer: os.Stdout,
-	}, nil
+		store:    s,
+		embedder: embedder,
+		reader:   bufio.NewReader(os.Stdin),
+		writer:   os.Stdout,
+	}
+}
+
+// SetEmbedder changes the embedder memories and queries are embedded with,
+// such as to the configured model
+func (s *Server) SetEmbedder(e embedding.Embedder) {
+	s.embedder = embedding.Cached(e, s.store)
+	s.store.SetEmbeddingModel(embedding.ModelOf(e))
+}
+
+// SetSearchWeights changes how recall results are ranked
+func (s *Server) SetSearchWeights(w models.SearchWeights) {
+	s.store.SetSearchWeights(w)
+}
+
+// SetExtractor sets the extractor that decides which session memories
+// graduate to the durable store
+func (s *Server) SetExtractor(ext extractor.Extractor) {
+	s.extractor = ext
 }
 
-// Run starts the MCP server (blocks until stdin closes)
+// Run serves MCP over stdio (blocks until stdin closes)
 func (s *Server) Run() error {
 	log.SetOutput(os.Stderr) // Log to stderr, not stdout
+	return s.serve()
+}
+
+// ServeConn serves MCP over a connection the way Run does over stdio,
+// until the client closes it
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	c := *s
+	c.reader = bufio.NewReader(conn)
+	c.writer = conn
+	return c.serve()
+}
+
+func (s *Server) serve() error {
+	// Each stdio process or socket connection is one session
+	s.session = ulid.Make().String()
 
 	// Send server info
 	s.sendServerInfo()
@@ -89,7 +145,8 @@ func (s *Server) sendServerInfo() {
 			"version": "0.1.0",
 		},
 		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
 		},
 	}
 	s.sendResult(nil, info)
@@ -103,6 +160,12 @@ func (s *Server) handleRequest(req *JSONRPCRequest) {
 		s.handleToolsList(req)
 	case "tools/call":
 		s.handleToolsCall(req)
+	case "resources/list":
+		s.handleResourcesList(req)
+	case "resources/templates/list":
+		s.handleResourceTemplatesList(req)
+	case "resources/read":
+		s.handleResourcesRead(req)
 	default:
 		s.sendError(req.ID, -32601, "Method not found")
 	}
@@ -116,7 +179,8 @@ func (s *Server) handleInitialize(req *JSONRPCRequest) {
 			"version": "0.1.0",
 		},
 		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
 		},
 	}
 	s.sendResult(req.ID, result)
@@ -132,17 +196,75 @@ func (s *Server) handleToolsList(req *JSONRPCRequest) {
 				"properties": map[string]interface{}{
 					"query": map[string]interface{}{
 						"type":        "string",
-						"description": "What to search for",
+						"description": "What to search for; prefix a word with - to exclude memories mentioning it",
 					},
 					"limit": map[string]interface{}{
 						"type":        "number",
 						"description": "Maximum results",
 						"default":     5,
 					},
+					"types": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": []string{"decision", "pattern", "fact", "preference", "mistake", "learning", "context"}},
+						"description": "Only memories of these types",
+					},
+					"scopes": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string", "enum": []string{"personal", "project", "team", "org"}},
+						"description": "Only memories with these scopes",
+					},
+					"project": map[string]interface{}{
+						"type":        "string",
+						"description": "Only memories of this project (path or name) and global ones",
+					},
 				},
 				"required": []string{"query"},
 			},
 		},
+		{
+			"name":        "memorypilot_recall_batch",
+			"description": "Search memory for several sub-questions at once. Results share one token budget and each memory is returned only once",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"queries": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "The questions to search for",
+					},
+					"budget": map[string]interface{}{
+						"type":        "number",
+						"description": "Token budget across all results",
+						"default":     4000,
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Maximum results per question",
+						"default":     5,
+					},
+				},
+				"required": []string{"queries"},
+			},
+		},
+		{
+			"name":        "memorypilot_ask",
+			"description": "Answer a question from memory, citing the memory IDs used. Use this when you want an answer rather than a list of memories",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"question": map[string]interface{}{
+						"type":        "string",
+						"description": "The question to answer",
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Number of memories to use as context",
+						"default":     8,
+					},
+				},
+				"required": []string{"question"},
+			},
+		},
 		{
 			"name":        "memorypilot_remember",
 			"description": "Explicitly remember something important",
@@ -159,10 +281,64 @@ func (s *Server) handleToolsList(req *JSONRPCRequest) {
 						"enum":        []string{"decision", "pattern", "fact", "preference", "mistake", "learning"},
 						"default":     "fact",
 					},
+					"topics": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Topics to file it under",
+					},
+					"idempotencyKey": map[string]interface{}{
+						"type":        "string",
+						"description": "Unique per memory; a retry with the same key returns the first call's memory instead of a duplicate",
+					},
 				},
 				"required": []string{"content"},
 			},
 		},
+		{
+			"name":        "memorypilot_snapshot",
+			"description": "Record the current working state (branch, uncommitted files, TODOs touched today, failing tests) as a short-lived memory so the next session can resume where this one left off",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Repository path (defaults to the server's working directory)",
+					},
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "Where you left off and what's next",
+					},
+					"failingTests": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Tests that are currently failing",
+					},
+					"ttlHours": map[string]interface{}{
+						"type":        "number",
+						"description": "How long to keep the snapshot",
+						"default":     48,
+					},
+				},
+			},
+		},
+		{
+			"name":        "memorypilot_project_context",
+			"description": "Get context for a project: its own memories plus relevant memories from similar past projects, each labelled with the project it came from. Useful when starting work in a new project",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type":        "string",
+						"description": "Project path (defaults to the server's working directory)",
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Maximum memories per section",
+						"default":     5,
+					},
+				},
+			},
+		},
 		{
 			"name":        "memorypilot_status",
 			"description": "Get memory statistics",
@@ -171,6 +347,194 @@ func (s *Server) handleToolsList(req *JSONRPCRequest) {
 				"properties": map[string]interface{}{},
 			},
 		},
+		{
+			"name":        "memorypilot_forget",
+			"description": "Delete a memory by ID, e.g. one that is wrong or out of date",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the memory to delete",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+		{
+			"name":        "memorypilot_update",
+			"description": "Correct a memory's content, summary, type or topics. Fields left out are kept; edited content gets a new summary unless one is given",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        "string",
+						"description": "ID of the memory to update",
+					},
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "New content",
+					},
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "New one-line summary",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "New memory type",
+						"enum":        []string{"decision", "pattern", "fact", "preference", "mistake", "learning", "context"},
+					},
+					"topics": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Replacement topics",
+					},
+					"version": map[string]interface{}{
+						"type":        "number",
+						"description": "The version the edit is based on; refused if the memory has changed since",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+		{
+			"name":        "memorypilot_pending",
+			"description": "List memories awaiting review, such as low-confidence extractions and proposed workflows. They aren't recalled until approved",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Maximum memories to list",
+						"default":     pendingLimit,
+					},
+				},
+			},
+		},
+		{
+			"name":        "memorypilot_review",
+			"description": "Approve or reject memories awaiting review. To correct one first, edit it with memorypilot_update",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"ids": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "IDs of the memories awaiting review",
+					},
+					"decision": map[string]interface{}{
+						"type":        "string",
+						"description": "approve to have them recalled, reject to discard them",
+						"enum":        []string{"approve", "reject"},
+					},
+				},
+				"required": []string{"ids", "decision"},
+			},
+		},
+		{
+			"name":        "memorypilot_list_projects",
+			"description": "List tracked projects with their paths, IDs and memory counts",
+			"inputSchema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			"name":        "memorypilot_timeline",
+			"description": "Recent activity in time order: commits, tags, terminal commands and new memories, with file changes counted",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"hours": map[string]interface{}{
+						"type":        "number",
+						"description": "How far back to look",
+						"default":     timelineHours,
+					},
+					"project": map[string]interface{}{
+						"type":        "string",
+						"description": "Only this project, by path or name",
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Maximum entries, latest kept",
+						"default":     timelineLimit,
+					},
+				},
+			},
+		},
+		{
+			"name":        "memorypilot_session_remember",
+			"description": "Keep scratch context for this conversation only. Session memories expire within hours, are recalled only with memorypilot_session_recall in the same session, and reach the durable store only if they graduate (see memorypilot_graduate)",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "What to keep",
+					},
+					"type": map[string]interface{}{
+						"type":        "string",
+						"description": "Memory type",
+						"enum":        []string{"decision", "pattern", "fact", "preference", "mistake", "learning", "context"},
+						"default":     "context",
+					},
+					"topics": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Topics to file it under",
+					},
+					"ttl": map[string]interface{}{
+						"type":        "string",
+						"description": "How long to keep it, e.g. 30m or 2h (at most 24h)",
+						"default":     "4h",
+					},
+					"session": map[string]interface{}{
+						"type":        "string",
+						"description": "Session to write to, e.g. a conversation ID; defaults to this connection's session",
+					},
+				},
+				"required": []string{"content"},
+			},
+		},
+		{
+			"name":        "memorypilot_session_recall",
+			"description": "Recall scratch context kept with memorypilot_session_remember in this session, best matches first, or the newest without a query",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "What to look for",
+					},
+					"limit": map[string]interface{}{
+						"type":        "number",
+						"description": "Maximum results",
+						"default":     10,
+					},
+					"session": map[string]interface{}{
+						"type":        "string",
+						"description": "Session to read from; defaults to this connection's session",
+					},
+				},
+			},
+		},
+		{
+			"name":        "memorypilot_graduate",
+			"description": "Promote this session's memories that stay useful beyond the conversation into the durable store. Each session memory is reviewed once; the daemon also reviews them as they near expiry",
+			"inputSchema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"session": map[string]interface{}{
+						"type":        "string",
+						"description": "Session to graduate; defaults to this connection's session",
+					},
+					"dryRun": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Report what would graduate without saving it or marking anything reviewed",
+					},
+				},
+			},
+		},
 	}
 
 	s.sendResult(req.ID, map[string]interface{}{"tools": tools})
@@ -190,10 +554,36 @@ func (s *Server) handleToolsCall(req *JSONRPCRequest) {
 	switch params.Name {
 	case "memorypilot_recall":
 		s.handleRecall(req, params.Arguments)
+	case "memorypilot_recall_batch":
+		s.handleRecallBatch(req, params.Arguments)
+	case "memorypilot_ask":
+		s.handleAsk(req, params.Arguments)
 	case "memorypilot_remember":
 		s.handleRemember(req, params.Arguments)
+	case "memorypilot_snapshot":
+		s.handleSnapshot(req, params.Arguments)
+	case "memorypilot_project_context":
+		s.handleProjectContext(req, params.Arguments)
 	case "memorypilot_status":
 		s.handleStatus(req)
+	case "memorypilot_forget":
+		s.handleForget(req, params.Arguments)
+	case "memorypilot_update":
+		s.handleUpdate(req, params.Arguments)
+	case "memorypilot_pending":
+		s.handlePending(req, params.Arguments)
+	case "memorypilot_review":
+		s.handleReview(req, params.Arguments)
+	case "memorypilot_list_projects":
+		s.handleListProjects(req)
+	case "memorypilot_timeline":
+		s.handleTimeline(req, params.Arguments)
+	case "memorypilot_session_remember":
+		s.handleSessionRemember(req, params.Arguments)
+	case "memorypilot_session_recall":
+		s.handleSessionRecall(req, params.Arguments)
+	case "memorypilot_graduate":
+		s.handleGraduate(req, params.Arguments)
 	default:
 		s.sendError(req.ID, -32602, "Unknown tool")
 	}
@@ -201,8 +591,11 @@ func (s *Server) handleToolsCall(req *JSONRPCRequest) {
 
 func (s *Server) handleRecall(req *JSONRPCRequest, args json.RawMessage) {
 	var params struct {
-		Query string `json:"query"`
-		Limit int    `json:"limit"`
+		Query   string   `json:"query"`
+		Limit   int      `json:"limit"`
+		Types   []string `json:"types"`
+		Scopes  []string `json:"scopes"`
+		Project string   `json:"project"`
 	}
 	json.Unmarshal(args, &params)
 
@@ -210,10 +603,34 @@ func (s *Server) handleRecall(req *JSONRPCRequest, args json.RawMessage) {
 		params.Limit = 5
 	}
 
-	memories, err := s.store.Recall(models.RecallRequest{
+	recallReq := models.RecallRequest{
 		Query: params.Query,
 		Limit: params.Limit,
-	})
+	}
+	for _, t := range params.Types {
+		if !models.MemoryType(t).Valid() {
+			s.sendError(req.ID, -32602, fmt.Sprintf("unknown memory type %q", t))
+			return
+		}
+		recallReq.Types = append(recallReq.Types, models.MemoryType(t))
+	}
+	for _, scope := range params.Scopes {
+		recallReq.Scope = append(recallReq.Scope, models.MemoryScope(scope))
+	}
+	if params.Project != "" {
+		project, err := s.lookupProject(params.Project)
+		if err != nil {
+			s.sendError(req.ID, -32000, err.Error())
+			return
+		}
+		if project == nil {
+			s.sendError(req.ID, -32602, fmt.Sprintf("unknown project %q", params.Project))
+			return
+		}
+		recallReq.ProjectID = &project.ID
+	}
+
+	memories, err := s.search(recallReq)
 	if err != nil {
 		s.sendError(req.ID, -32000, err.Error())
 		return
@@ -226,8 +643,14 @@ func (s *Server) handleRecall(req *JSONRPCRequest, args json.RawMessage) {
 	} else {
 		text = fmt.Sprintf("Found %d memories:\n\n", len(memories))
 		for i, m := range memories {
+			content := m.Content
+			if m.Score != nil && m.Score.Excerpt != "" {
+				// Long content: the passage that matched, not all of it
+				content = fmt.Sprintf("Matched excerpt (memorypilot_get %s for all %d characters):\n   %s",
+					m.ID, len(m.Content), models.Highlight(m.Score.Excerpt, params.Query))
+			}
 			text += fmt.Sprintf("%d. [%s] %s\n   %s\n   Topics: %v\n\n",
-				i+1, m.Type, m.Summary, m.Content, m.Topics)
+				i+1, m.Type, m.Summary, content, m.Topics)
 		}
 	}
 
@@ -238,19 +661,167 @@ func (s *Server) handleRecall(req *JSONRPCRequest, args json.RawMessage) {
 	})
 }
 
+func (s *Server) handleAsk(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Question string `json:"question"`
+		Limit    int    `json:"limit"`
+	}
+	json.Unmarshal(args, &params)
+
+	if params.Limit == 0 {
+		params.Limit = 8
+	}
+
+	memories, err := s.search(models.RecallRequest{Query: params.Question, Limit: params.Limit})
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+
+	answer, err := rag.Ask(extractor.NewOllamaExtractor("", ""), params.Question, memories)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+
+	text := answer.Text
+	if len(answer.Citations) > 0 {
+		text += "\n\nSources:\n"
+		for _, m := range answer.Citations {
+			text += fmt.Sprintf("- %s [%s] %s\n", m.ID, m.Type, m.Summary)
+		}
+	}
+
+	s.sendResult(req.ID, map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": text},
+		},
+	})
+}
+
+// search retrieves memories for a request, using hybrid search when the
+// embedder is reachable and keyword search otherwise
+func (s *Server) search(req models.RecallRequest) ([]models.Memory, error) {
+	text, _ := models.ParseQuery(req.Query)
+	emb, err := s.embedder.Embed(text)
+	if err == nil && len(emb) > 0 {
+		return s.store.HybridSearch(req, emb)
+	}
+	return s.store.Recall(req)
+}
+
 func (s *Server) handleRemember(req *JSONRPCRequest, args json.RawMessage) {
 	var params struct {
-		Content string `json:"content"`
-		Type    string `json:"type"`
+		Content        string   `json:"content"`
+		Type           string   `json:"type"`
+		Topics         []string `json:"topics"`
+		IdempotencyKey string   `json:"idempotencyKey"`
 	}
 	json.Unmarshal(args, &params)
 
+	if strings.TrimSpace(params.Content) == "" {
+		s.sendError(req.ID, -32602, "content is required")
+		return
+	}
 	if params.Type == "" {
 		params.Type = "fact"
 	}
+	if !models.MemoryType(params.Type).Valid() {
+		s.sendError(req.ID, -32602, fmt.Sprintf("unknown memory type %q", params.Type))
+		return
+	}
 
-	// TODO: Create memory
-	text := fmt.Sprintf("Remembered: %s (type: %s)", params.Content, params.Type)
+	// A retry with the same key gets the memory the first call created
+	var requestHash string
+	if params.IdempotencyKey != "" {
+		requestHash = store.RequestHash(params)
+		id, err := s.store.IdempotentMemory(params.IdempotencyKey, requestHash)
+		if err != nil {
+			s.sendError(req.ID, -32602, err.Error())
+			return
+		}
+		if id != "" {
+			if m, err := s.store.GetMemory(id); err == nil && m != nil {
+				s.sendText(req.ID, fmt.Sprintf("Remembered %s: [%s] %s", m.ID, m.Type, m.Summary))
+				return
+			}
+		}
+	}
+
+	now := time.Now()
+	m := models.Memory{
+		ID:      ulid.Make().String(),
+		Type:    models.MemoryType(params.Type),
+		Content: params.Content,
+		Summary: models.Summarize(params.Content),
+		Scope:   models.MemoryScopePersonal,
+		Source: models.Source{
+			Type:      models.SourceTypeManual,
+			Reference: "mcp",
+			Timestamp: now,
+		},
+		Confidence:     1.0,
+		Importance:     1.0,
+		Topics:         params.Topics,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+	}
+	identity.Attribute(&m)
+	if emb, err := s.embedder.Embed(m.Content); err == nil {
+		m.Embedding = emb
+	}
+
+	err := s.store.CreateMemory(&m)
+	if err != nil && !errors.Is(err, store.ErrDuplicate) {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if params.IdempotencyKey != "" {
+		if err := s.store.RecordIdempotencyKey(params.IdempotencyKey, requestHash, m.ID); err != nil {
+			log.Printf("Failed to record idempotency key: %v", err)
+		}
+	}
+	if err != nil {
+		s.sendText(req.ID, fmt.Sprintf("Already remembered as %s", m.ID))
+		return
+	}
+	s.sendText(req.ID, fmt.Sprintf("Remembered %s: [%s] %s", m.ID, m.Type, m.Summary))
+}
+
+func (s *Server) handleSnapshot(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Path         string   `json:"path"`
+		Note         string   `json:"note"`
+		FailingTests []string `json:"failingTests"`
+		TTLHours     float64  `json:"ttlHours"`
+	}
+	json.Unmarshal(args, &params)
+
+	if params.Path == "" {
+		params.Path, _ = os.Getwd()
+	}
+
+	snap, err := snapshot.Capture(params.Path)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	snap.Note = params.Note
+	snap.FailingTests = params.FailingTests
+
+	memory := snap.Memory(time.Duration(params.TTLHours * float64(time.Hour)))
+	if project, err := s.store.GetProjectByPath(snap.Repo); err == nil && project != nil {
+		memory.ProjectID = &project.ID
+	}
+
+	identity.Attribute(&memory)
+	if err := s.store.CreateMemory(&memory); err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+
+	text := fmt.Sprintf("Snapshot saved (%s), expires %s:\n\n%s",
+		memory.ID, memory.ExpiresAt.Format("2006-01-02 15:04"), memory.Content)
 
 	s.sendResult(req.ID, map[string]interface{}{
 		"content": []map[string]interface{}{
@@ -259,6 +830,94 @@ func (s *Server) handleRemember(req *JSONRPCRequest, args json.RawMessage) {
 	})
 }
 
+func (s *Server) handleProjectContext(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Path  string `json:"path"`
+		Limit int    `json:"limit"`
+	}
+	json.Unmarshal(args, &params)
+
+	if params.Limit == 0 {
+		params.Limit = 5
+	}
+	if params.Path == "" {
+		params.Path, _ = os.Getwd()
+	}
+	if abs, err := filepath.Abs(params.Path); err == nil {
+		params.Path = abs
+	}
+
+	var sb strings.Builder
+
+	project, err := s.store.GetProjectByPath(params.Path)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if project != nil {
+		own, err := s.store.Recall(models.RecallRequest{ProjectID: &project.ID, Limit: params.Limit})
+		if err != nil {
+			s.sendError(req.ID, -32000, err.Error())
+			return
+		}
+		sb.WriteString(fmt.Sprintf("Project %s (%s)\n", project.Name, project.Path))
+		if facts, err := s.store.GetProjectFacts(project.ID); err == nil && len(facts) > 0 {
+			sb.WriteString(formatStack(projects.StackFromFacts(facts)))
+		} else {
+			sb.WriteString(formatStack(projects.DetectStack(params.Path)))
+		}
+		sb.WriteString("\n")
+		for _, m := range own {
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n", m.Type, m.Content))
+		}
+	} else {
+		sb.WriteString(fmt.Sprintf("No memories yet for %s.\n", params.Path))
+		sb.WriteString(formatStack(projects.DetectStack(params.Path)))
+	}
+
+	similar, suggestions, err := projects.Suggest(s.store, s.embedder, params.Path, params.Limit)
+	if err != nil {
+		log.Printf("Cross-project suggestions unavailable: %v", err)
+	}
+	if len(suggestions) > 0 {
+		names := make([]string, len(similar))
+		for i, p := range similar {
+			names[i] = fmt.Sprintf("%s (%.0f%% similar)", p.Project.Name, p.Similarity*100)
+		}
+		sb.WriteString("\nFrom similar projects: " + strings.Join(names, ", ") + "\n\n")
+		for _, sg := range suggestions {
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n  (from project %s at %s, %s, memory %s)\n",
+				sg.Memory.Type, sg.Memory.Content, sg.Project.Name, sg.Project.Path,
+				sg.Memory.CreatedAt.Format("2006-01-02"), sg.Memory.ID))
+		}
+	}
+
+	s.sendResult(req.ID, map[string]interface{}{
+		"content": []map[string]interface{}{
+			{"type": "text", "text": sb.String()},
+		},
+	})
+}
+
+// formatStack renders a detected stack for tool output
+func formatStack(stack projects.Stack) string {
+	if len(stack.Languages) == 0 {
+		return ""
+	}
+	out := "Languages: " + strings.Join(stack.Languages, ", ") + "\n"
+	if len(stack.Frameworks) > 0 {
+		out += "Frameworks: " + strings.Join(stack.Frameworks, ", ") + "\n"
+	}
+	if len(stack.Dependencies) > 0 {
+		deps := stack.Dependencies
+		if len(deps) > 15 {
+			deps = append(deps[:15:15], fmt.Sprintf("and %d more", len(stack.Dependencies)-15))
+		}
+		out += "Dependencies: " + strings.Join(deps, ", ") + "\n"
+	}
+	return out
+}
+
 func (s *Server) handleStatus(req *JSONRPCRequest) {
 	stats, err := s.store.GetStats()
 	if err != nil {
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/graduate"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Session memories are scratch context for one conversation: a stdio
+// process, a socket connection or an HTTP session, unless the client names
+// its own session (such as a conversation ID) to carry it across
+// reconnects.
+
+func (s *Server) handleSessionRemember(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Content string   `json:"content"`
+		Type    string   `json:"type"`
+		Topics  []string `json:"topics"`
+		TTL     string   `json:"ttl"`
+		Session string   `json:"session"`
+	}
+	json.Unmarshal(args, &params)
+
+	if strings.TrimSpace(params.Content) == "" {
+		s.sendError(req.ID, -32602, "content is required")
+		return
+	}
+	session := s.sessionFor(params.Session)
+	if session == "" {
+		s.sendError(req.ID, -32602, "no session: initialize first or pass a session")
+		return
+	}
+	if params.Type == "" {
+		params.Type = string(models.MemoryTypeContext)
+	}
+	if !models.MemoryType(params.Type).Valid() {
+		s.sendError(req.ID, -32602, fmt.Sprintf("unknown memory type %q", params.Type))
+		return
+	}
+	ttl := store.SessionTTL
+	if params.TTL != "" {
+		d, err := time.ParseDuration(params.TTL)
+		if err != nil || d <= 0 {
+			s.sendError(req.ID, -32602, fmt.Sprintf("ttl %q is not a duration (e.g. 30m, 2h)", params.TTL))
+			return
+		}
+		ttl = min(d, store.MaxSessionTTL)
+	}
+
+	now := time.Now()
+	m := models.SessionMemory{
+		SessionID: session,
+		Type:      models.MemoryType(params.Type),
+		Content:   params.Content,
+		Topics:    params.Topics,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	if emb, err := s.embedder.Embed(m.Content); err == nil {
+		m.Embedding = emb
+	}
+	if err := s.store.CreateSessionMemory(&m); err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	s.sendText(req.ID, fmt.Sprintf("Kept %s in session %s until %s", m.ID, session, m.ExpiresAt.Format("15:04")))
+}
+
+func (s *Server) handleSessionRecall(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Query   string `json:"query"`
+		Limit   int    `json:"limit"`
+		Session string `json:"session"`
+	}
+	json.Unmarshal(args, &params)
+
+	session := s.sessionFor(params.Session)
+	if session == "" {
+		s.sendError(req.ID, -32602, "no session: initialize first or pass a session")
+		return
+	}
+	var queryEmb []float32
+	if strings.TrimSpace(params.Query) != "" {
+		queryEmb, _ = s.embedder.Embed(params.Query)
+	}
+	memories, err := s.store.RecallSession(session, params.Query, queryEmb, params.Limit)
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if len(memories) == 0 {
+		s.sendText(req.ID, "No session memories found.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Session %s:\n\n", session))
+	for i, m := range memories {
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, m.Type, m.Content))
+		if len(m.Topics) > 0 {
+			sb.WriteString(fmt.Sprintf("   Topics: %v\n", m.Topics))
+		}
+		sb.WriteString(fmt.Sprintf("   Expires %s\n\n", m.ExpiresAt.Format("15:04")))
+	}
+	s.sendText(req.ID, sb.String())
+}
+
+func (s *Server) handleGraduate(req *JSONRPCRequest, args json.RawMessage) {
+	var params struct {
+		Session string `json:"session"`
+		DryRun  bool   `json:"dryRun"`
+	}
+	json.Unmarshal(args, &params)
+
+	session := s.sessionFor(params.Session)
+	if session == "" {
+		s.sendError(req.ID, -32602, "no session: initialize first or pass a session")
+		return
+	}
+	if s.extractor == nil {
+		s.sendError(req.ID, -32000, "graduation needs extraction, which is off for this server")
+		return
+	}
+	result, err := graduate.Run(s.store, s.extractor, s.embedder, graduate.Options{
+		SessionID: session,
+		DryRun:    params.DryRun,
+	})
+	if err != nil {
+		s.sendError(req.ID, -32000, err.Error())
+		return
+	}
+	if result.Reviewed == 0 && result.Failed == 0 {
+		s.sendText(req.ID, "No session memories left to review.")
+		return
+	}
+
+	var sb strings.Builder
+	verb := "Graduated"
+	if params.DryRun {
+		verb = "Would graduate"
+	}
+	sb.WriteString(fmt.Sprintf("Reviewed %d session memories. %s %d:\n\n", result.Reviewed, verb, len(result.Graduated)))
+	for _, m := range result.Graduated {
+		sb.WriteString(fmt.Sprintf("- [%s] %s (%s)\n", m.Type, m.Content, m.ID))
+	}
+	if result.Duplicates > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d already known.\n", result.Duplicates))
+	}
+	if result.Failed > 0 {
+		sb.WriteString(fmt.Sprintf("\n%d batches couldn't be reviewed and will be retried.\n", result.Failed))
+	}
+	s.sendText(req.ID, sb.String())
+}
+
+// sessionFor returns the session a tool call names, or the client's own
+func (s *Server) sessionFor(named string) string {
+	if named != "" {
+		return named
+	}
+	return s.session
+}
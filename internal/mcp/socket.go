@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Each 'memorypilot mcp' process an AI tool spawns would otherwise open the
+// database itself, and several writing at once can collide. While the
+// daemon runs it owns the store and listens on a unix socket in the data
+// directory; mcp processes relay their stdio to it, so every client shares
+// one store.
+
+// dialTimeout bounds connecting to the daemon's socket
+const dialTimeout = time.Second
+
+// SocketPath is where the daemon listens for MCP clients
+func SocketPath(dataDir string) string {
+	return filepath.Join(dataDir, "mcp.sock")
+}
+
+// ListenSocket listens on path, replacing a socket file left behind by a
+// daemon that didn't shut down cleanly. Fails if another daemon is
+// listening.
+func ListenSocket(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, dialTimeout); err == nil {
+		conn.Close()
+		return nil, errors.New("another process is serving " + path)
+	}
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	// Only this user may talk to their memories
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Serve answers MCP clients connecting to ln until it is closed
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+// Relay connects stdin and stdout to the daemon's socket, returning false
+// without error if the daemon isn't listening. It returns once the client
+// closes stdin and the daemon has answered, or the daemon goes away.
+func Relay(path string) (bool, error) {
+	return relay(path, os.Stdin, os.Stdout)
+}
+
+// relay connects in and out to the daemon's socket, the way Relay does
+// stdin and stdout
+func relay(path string, in io.Reader, out io.Writer) (bool, error) {
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return false, nil
+	}
+	defer conn.Close()
+
+	go func() {
+		io.Copy(conn, in)
+		// Let the daemon see EOF and finish answering
+		if c, ok := conn.(*net.UnixConn); ok {
+			c.CloseWrite()
+		}
+	}()
+	_, err = io.Copy(out, conn)
+	return true, err
+}
@@ -0,0 +1,84 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/pkg/fixtures"
+)
+
+func TestListenSocketRefusesSecondDaemon(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.sock")
+	ln, err := ListenSocket(path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	if second, err := ListenSocket(path); err == nil {
+		second.Close()
+		t.Fatal("second ListenSocket succeeded while the first is serving")
+	}
+}
+
+// Each client relays its own session to the one daemon; their remembers
+// and recalls must not interleave or be lost
+func TestRelayConcurrentClients(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.sock")
+	ln, err := ListenSocket(path)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := NewServerFor(fixtures.NewTempStore(t), &embedding.FakeEmbedder{})
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ln) }()
+	defer func() {
+		ln.Close()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	}()
+
+	const clients = 8
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			word := fmt.Sprintf("gadget%d", i)
+			in := strings.NewReader(toolCall(1, "memorypilot_remember",
+				fmt.Sprintf(`{"content":"Client %d configures the %s service","type":"fact"}`, i, word)) +
+				toolCall(2, "memorypilot_recall", fmt.Sprintf(`{"query":%q}`, word)))
+			var out bytes.Buffer
+			ok, err := relay(path, in, &out)
+			if err != nil || !ok {
+				t.Errorf("client %d: relay = %v, %v", i, ok, err)
+				return
+			}
+			got := out.String()
+			if !strings.Contains(got, "Remembered ") {
+				t.Errorf("client %d: no remember result in %q", i, got)
+			}
+			if !strings.Contains(got, fmt.Sprintf(`1. [fact] Client %d configures`, i)) {
+				t.Errorf("client %d: recall missed its memory in %q", i, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestRelayWithoutDaemon(t *testing.T) {
+	ok, err := relay(filepath.Join(t.TempDir(), "mcp.sock"), strings.NewReader(""), &bytes.Buffer{})
+	if ok || err != nil {
+		t.Fatalf("relay = %v, %v; want false, nil", ok, err)
+	}
+}
+
+func toolCall(id int, name, args string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, id, name, args) + "\n"
+}
@@ -0,0 +1,274 @@
+package mining
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// MinSupport is how often a sequence must recur to be proposed
+	MinSupport = 3
+
+	minLength = 3
+	maxLength = 6
+
+	// sessionGap splits the command stream into work sessions
+	sessionGap = 30 * time.Minute
+
+	// maxProposals caps proposals per run so the review queue stays readable
+	maxProposals = 5
+
+	// SourcePrefix marks memories proposed by sequence mining
+	SourcePrefix = "sequence:"
+)
+
+// Sequence is a recurring run of terminal commands
+type Sequence struct {
+	Commands []string  `json:"commands"`
+	Count    int       `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// Ref is a stable identifier for the sequence, used to avoid re-proposing it
+func (s Sequence) Ref() string {
+	sum := sha1.Sum([]byte(strings.Join(s.Commands, "\n")))
+	return SourcePrefix + hex.EncodeToString(sum[:])[:16]
+}
+
+var quoted = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+
+// normalize makes commands that differ only in free-text arguments (commit
+// messages, search strings) compare equal
+func normalize(cmd string) string {
+	return strings.Join(strings.Fields(quoted.ReplaceAllString(cmd, `"…"`)), " ")
+}
+
+// Mine finds command sequences that recur at least minSupport times in the
+// given terminal events. Longer sequences are preferred; a sequence contained
+// in an already-selected longer one is dropped.
+func Mine(events []models.Event, minSupport int) []Sequence {
+	sort.Slice(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+
+	// Split into sessions of normalized commands
+	type entry struct {
+		cmd string
+		at  time.Time
+	}
+	var sessions [][]entry
+	var current []entry
+	var last time.Time
+	for _, e := range events {
+		if e.Type != "terminal_cmd" {
+			continue
+		}
+		cmd, _ := e.Data["command"].(string)
+		if cmd == "" {
+			continue
+		}
+		if !last.IsZero() && e.Timestamp.Sub(last) > sessionGap && len(current) > 0 {
+			sessions = append(sessions, current)
+			current = nil
+		}
+		last = e.Timestamp
+		cmd = normalize(cmd)
+		if n := len(current); n > 0 && current[n-1].cmd == cmd {
+			continue // repeated runs of the same command are one step
+		}
+		current = append(current, entry{cmd, e.Timestamp})
+	}
+	if len(current) > 0 {
+		sessions = append(sessions, current)
+	}
+
+	// Count non-overlapping occurrences of every n-gram
+	counts := make(map[string]*Sequence)
+	for _, session := range sessions {
+		for n := minLength; n <= maxLength; n++ {
+			nextFree := make(map[string]int)
+			for i := 0; i+n <= len(session); i++ {
+				cmds := make([]string, n)
+				distinct := make(map[string]bool)
+				for j := range cmds {
+					cmds[j] = session[i+j].cmd
+					distinct[cmds[j]] = true
+				}
+				if len(distinct) < n {
+					continue // loops like edit/test/edit/test aren't workflows
+				}
+				key := strings.Join(cmds, "\n")
+				if i < nextFree[key] {
+					continue
+				}
+				nextFree[key] = i + n
+
+				seq, ok := counts[key]
+				if !ok {
+					seq = &Sequence{Commands: cmds}
+					counts[key] = seq
+				}
+				seq.Count++
+				if at := session[i+n-1].at; at.After(seq.LastSeen) {
+					seq.LastSeen = at
+				}
+			}
+		}
+	}
+
+	var candidates []Sequence
+	for _, seq := range counts {
+		if seq.Count >= minSupport {
+			candidates = append(candidates, *seq)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if len(candidates[i].Commands) != len(candidates[j].Commands) {
+			return len(candidates[i].Commands) > len(candidates[j].Commands)
+		}
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return strings.Join(candidates[i].Commands, "\n") < strings.Join(candidates[j].Commands, "\n")
+	})
+
+	var selected []Sequence
+	for _, c := range candidates {
+		key := strings.Join(c.Commands, "\n")
+		covered := false
+		for _, s := range selected {
+			if strings.Contains(strings.Join(s.Commands, "\n"), key) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+const describePrompt = `A developer repeatedly runs these terminal commands in this order:
+
+%s
+Write ONE short sentence (max 15 words) describing the workflow they perform,
+starting with a verb, e.g. "Deploys the API to staging after running tests".
+Respond with only the sentence.`
+
+// Describe generates a one-line description of what the sequence does.
+// With a nil completer a plain listing is returned.
+func Describe(c extractor.Completer, seq Sequence) string {
+	fallback := "Runs " + strings.Join(quoteAll(seq.Commands), ", then ")
+	if c == nil {
+		return fallback
+	}
+
+	var sb strings.Builder
+	for i, cmd := range seq.Commands {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, cmd))
+	}
+	response, err := c.Complete(fmt.Sprintf(describePrompt, sb.String()))
+	if err != nil {
+		return fallback
+	}
+	description := strings.Trim(strings.TrimSpace(response), `"`)
+	if description == "" || strings.Contains(description, "\n") {
+		return fallback
+	}
+	return description
+}
+
+// Proposal builds a pending pattern memory for a mined sequence
+func Proposal(seq Sequence, description string) models.Memory {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s. Recurring workflow (seen %d times):\n", strings.TrimSuffix(description, "."), seq.Count))
+	for i, cmd := range seq.Commands {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, cmd))
+	}
+
+	topics := []string{"workflow"}
+	seen := map[string]bool{"workflow": true}
+	for _, cmd := range seq.Commands {
+		if tool := strings.Fields(cmd)[0]; !seen[tool] {
+			seen[tool] = true
+			topics = append(topics, tool)
+		}
+	}
+
+	now := time.Now()
+	confidence := 0.5 + 0.1*float64(seq.Count)
+	if confidence > 0.9 {
+		confidence = 0.9
+	}
+	return models.Memory{
+		ID:      ulid.Make().String(),
+		Type:    models.MemoryTypePattern,
+		Content: strings.TrimSpace(sb.String()),
+		Summary: description,
+		Scope:   models.MemoryScopePersonal,
+		Source: models.Source{
+			Type:      models.SourceTypeTerminal,
+			Reference: seq.Ref(),
+			Timestamp: seq.LastSeen,
+		},
+		Confidence:     confidence,
+		Importance:     1.0,
+		Topics:         topics,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+		Status:         models.MemoryStatusPending,
+	}
+}
+
+// Run mines terminal events since the given time and queues new sequences
+// for review. Sequences proposed before (even if rejected) are skipped.
+func Run(s *store.Store, c extractor.Completer, since time.Time) ([]models.Memory, error) {
+	events, err := s.GetEventsBetween(since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var proposed []models.Memory
+	for _, seq := range Mine(events, MinSupport) {
+		if len(proposed) >= maxProposals {
+			break
+		}
+		exists, err := s.HasSourceReference(seq.Ref())
+		if err != nil {
+			return proposed, err
+		}
+		if exists {
+			continue
+		}
+
+		m := Proposal(seq, Describe(c, seq))
+		identity.Attribute(&m)
+		if err := s.CreateMemory(&m); err != nil {
+			if errors.Is(err, store.ErrDuplicate) {
+				continue
+			}
+			return proposed, err
+		}
+		proposed = append(proposed, m)
+	}
+	return proposed, nil
+}
+
+func quoteAll(cmds []string) []string {
+	quoted := make([]string, len(cmds))
+	for i, c := range cmds {
+		quoted[i] = "`" + c + "`"
+	}
+	return quoted
+}
@@ -0,0 +1,182 @@
+package pack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultBudget is the token budget used when none is given
+const DefaultBudget = 4000
+
+// charsPerToken is a rough estimate that holds well enough for English prose
+// and code across common tokenizers
+const charsPerToken = 4
+
+// sectionOrder controls which memory types are packed first when the budget
+// runs short, and the order sections appear in the file
+var sectionOrder = []struct {
+	Type  models.MemoryType
+	Title string
+}{
+	{models.MemoryTypeDecision, "Decisions"},
+	{models.MemoryTypePattern, "Patterns"},
+	{models.MemoryTypePreference, "Preferences"},
+	{models.MemoryTypeMistake, "Known Pitfalls"},
+	{models.MemoryTypeFact, "Facts"},
+	{models.MemoryTypeLearning, "Learnings"},
+	{models.MemoryTypeContext, "Work in Progress"},
+}
+
+// Pack is a curated, budget-aware selection of memories
+type Pack struct {
+	Title    string
+	Memories []models.Memory
+	Tokens   int
+	Budget   int
+	Dropped  int
+}
+
+// Build selects memories for a pack. Expired and duplicate memories are
+// removed, then memories are taken in section order, most important first,
+// until the token budget is spent.
+func Build(title string, memories []models.Memory, budget int) *Pack {
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+	p := &Pack{Title: title, Budget: budget}
+
+	// Order deterministically so unchanged memories produce an unchanged pack
+	memories = append([]models.Memory{}, memories...)
+	sort.SliceStable(memories, func(i, j int) bool {
+		if memories[i].Importance != memories[j].Importance {
+			return memories[i].Importance > memories[j].Importance
+		}
+		return memories[i].CreatedAt.Before(memories[j].CreatedAt)
+	})
+	memories = dedupe(unexpired(memories))
+
+	// Reserve room for the header and section titles
+	p.Tokens = EstimateTokens(header(title)) + len(sectionOrder)*4
+
+	byType := make(map[models.MemoryType][]models.Memory)
+	for _, m := range memories {
+		byType[m.Type] = append(byType[m.Type], m)
+	}
+
+	for _, section := range sectionOrder {
+		for _, m := range byType[section.Type] {
+			cost := EstimateTokens(entry(m))
+			if p.Tokens+cost > budget {
+				p.Dropped++
+				continue
+			}
+			p.Tokens += cost
+			p.Memories = append(p.Memories, m)
+		}
+	}
+
+	return p
+}
+
+// Markdown renders the pack as a file suitable for pasting into a chat or
+// passing to a tool as extra context
+func (p *Pack) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(header(p.Title))
+
+	byType := make(map[models.MemoryType][]models.Memory)
+	for _, m := range p.Memories {
+		byType[m.Type] = append(byType[m.Type], m)
+	}
+
+	for _, section := range sectionOrder {
+		entries := byType[section.Type]
+		if len(entries) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", section.Title))
+		for _, m := range entries {
+			sb.WriteString(entry(m))
+		}
+	}
+
+	return sb.String()
+}
+
+// EstimateTokens approximates the token count of text
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+var budgetPattern = regexp.MustCompile(`^(\d+)(k)?(?:\s*tokens?)?$`)
+
+// ParseBudget parses budgets like "4000", "4000tokens" or "8k"
+func ParseBudget(s string) (int, error) {
+	match := budgetPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid budget %q (expected e.g. 4000tokens or 8k)", s)
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, err
+	}
+	if match[2] == "k" {
+		n *= 1000
+	}
+	return n, nil
+}
+
+func header(title string) string {
+	return fmt.Sprintf("# Project Context: %s\n\n_Generated by MemoryPilot on %s. Decisions, conventions and pitfalls worth knowing before making changes._\n",
+		title, time.Now().Format("2006-01-02"))
+}
+
+func entry(m models.Memory) string {
+	line := "- " + strings.Join(strings.Fields(m.Content), " ")
+	if len(m.Topics) > 0 {
+		line += fmt.Sprintf(" _(%s)_", strings.Join(m.Topics, ", "))
+	}
+	return line + "\n"
+}
+
+func unexpired(memories []models.Memory) []models.Memory {
+	now := time.Now()
+	var kept []models.Memory
+	for _, m := range memories {
+		if m.ExpiresAt != nil && m.ExpiresAt.Before(now) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// dedupe drops memories whose content or summary repeats an earlier one.
+// Input order is preserved, so callers should pass the preferred copy first.
+func dedupe(memories []models.Memory) []models.Memory {
+	seen := make(map[string]bool)
+	var kept []models.Memory
+	for _, m := range memories {
+		content := normalize(m.Content)
+		summary := normalize(m.Summary)
+		if seen[content] || (summary != "" && seen[summary]) {
+			continue
+		}
+		seen[content] = true
+		if summary != "" {
+			seen[summary] = true
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
@@ -0,0 +1,210 @@
+// Package prime writes a project's most relevant memories into the rules
+// files coding tools load on their own (CLAUDE.md, .cursorrules,
+// AGENTS.md), for tools without MCP support.
+//
+// The memories go in a block between marker comments, so the rest of the
+// file stays the user's: a later run replaces only the block.
+package prime
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultLimit is how many memories are written when no limit is given
+const DefaultLimit = 25
+
+// Rules file formats
+const (
+	FormatClaude = "claude" // CLAUDE.md, read by Claude Code
+	FormatCursor = "cursor" // .cursorrules, read by Cursor
+	FormatAgents = "agents" // AGENTS.md, read by Codex and others
+)
+
+// Formats lists every format, in the order they are written
+var Formats = []string{FormatClaude, FormatCursor, FormatAgents}
+
+// FileName returns the file a format is written to, relative to the
+// project, or "" for an unknown format
+func FileName(format string) string {
+	switch format {
+	case FormatClaude:
+		return "CLAUDE.md"
+	case FormatCursor:
+		return ".cursorrules"
+	case FormatAgents:
+		return "AGENTS.md"
+	}
+	return ""
+}
+
+// Markers delimit the generated block
+const (
+	beginMarker = "<!-- memorypilot:begin -->"
+	endMarker   = "<!-- memorypilot:end -->"
+)
+
+// typeWeights rank memory types by how much they should steer a tool:
+// stated preferences and decisions first, background facts last
+var typeWeights = map[models.MemoryType]float64{
+	models.MemoryTypePreference: 1.0,
+	models.MemoryTypeDecision:   1.0,
+	models.MemoryTypeMistake:    0.95,
+	models.MemoryTypePattern:    0.9,
+	models.MemoryTypeFact:       0.7,
+	models.MemoryTypeLearning:   0.6,
+	models.MemoryTypeContext:    0.5,
+}
+
+// sections are the headings memories are grouped under, in order
+var sections = []struct {
+	Type  models.MemoryType
+	Title string
+}{
+	{models.MemoryTypePreference, "Preferences"},
+	{models.MemoryTypeDecision, "Decisions"},
+	{models.MemoryTypePattern, "Conventions"},
+	{models.MemoryTypeMistake, "Pitfalls to avoid"},
+	{models.MemoryTypeFact, "Facts"},
+	{models.MemoryTypeLearning, "Learnings"},
+	{models.MemoryTypeContext, "Current work"},
+}
+
+// Load returns the memories that apply to a project: its own and those
+// outside any project. A nil project yields only the latter.
+func Load(s *store.Store, project *models.Project) ([]models.Memory, error) {
+	req := models.RecallRequest{}
+	if project != nil {
+		req.ProjectID = &project.ID
+	}
+	memories, err := s.ListMemories(req)
+	if err != nil || project != nil {
+		return memories, err
+	}
+	var personal []models.Memory
+	for _, m := range memories {
+		if m.ProjectID == nil {
+			personal = append(personal, m)
+		}
+	}
+	return personal, nil
+}
+
+// Select returns the top n memories by type weight, importance and
+// confidence, dropping expired ones
+func Select(memories []models.Memory, n int, now time.Time) []models.Memory {
+	if n <= 0 {
+		n = DefaultLimit
+	}
+	var live []models.Memory
+	for _, m := range memories {
+		if m.ExpiresAt != nil && m.ExpiresAt.Before(now) {
+			continue
+		}
+		live = append(live, m)
+	}
+	sort.SliceStable(live, func(i, j int) bool {
+		si, sj := score(live[i]), score(live[j])
+		if si != sj {
+			return si > sj
+		}
+		return live[i].ID < live[j].ID
+	})
+	if len(live) > n {
+		live = live[:n]
+	}
+	return live
+}
+
+func score(m models.Memory) float64 {
+	weight, ok := typeWeights[m.Type]
+	if !ok {
+		weight = 0.5
+	}
+	return weight * m.Importance * m.Confidence
+}
+
+// Render writes the block for a rules file, markers included
+func Render(format, title string, memories []models.Memory) string {
+	var b strings.Builder
+	b.WriteString(beginMarker + "\n")
+	if format == FormatCursor {
+		// .cursorrules is read as plain instructions
+		fmt.Fprintf(&b, "Remembered context for %s (from MemoryPilot). Follow these unless told otherwise.\n", title)
+	} else {
+		fmt.Fprintf(&b, "## Remembered context: %s\n\n", title)
+		b.WriteString("_Generated by MemoryPilot from past work. Edits inside this block are overwritten; edit outside it._\n")
+	}
+
+	byType := make(map[models.MemoryType][]models.Memory)
+	for _, m := range memories {
+		byType[m.Type] = append(byType[m.Type], m)
+	}
+	for _, section := range sections {
+		entries := byType[section.Type]
+		if len(entries) == 0 {
+			continue
+		}
+		if format == FormatCursor {
+			fmt.Fprintf(&b, "\n%s:\n", section.Title)
+		} else {
+			fmt.Fprintf(&b, "\n### %s\n\n", section.Title)
+		}
+		for _, m := range entries {
+			b.WriteString("- " + strings.Join(strings.Fields(m.Content), " ") + "\n")
+		}
+	}
+	b.WriteString(endMarker + "\n")
+	return b.String()
+}
+
+// Write puts block into the file at path, replacing an earlier block or
+// appending it, and reports whether the file changed
+func Write(path, block string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	updated := splice(existing, []byte(block))
+	if bytes.Equal(existing, updated) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, updated, 0644)
+}
+
+// splice replaces the marked block in doc, or appends block after a blank
+// line if there is none
+func splice(doc, block []byte) []byte {
+	begin := bytes.Index(doc, []byte(beginMarker))
+	if begin >= 0 {
+		if end := bytes.Index(doc[begin:], []byte(endMarker)); end >= 0 {
+			end += begin + len(endMarker)
+			if end < len(doc) && doc[end] == '\n' {
+				end++
+			}
+			out := append([]byte{}, doc[:begin]...)
+			out = append(out, block...)
+			return append(out, doc[end:]...)
+		}
+	}
+
+	out := append([]byte{}, doc...)
+	if len(out) > 0 {
+		if !bytes.HasSuffix(out, []byte("\n")) {
+			out = append(out, '\n')
+		}
+		out = append(out, '\n')
+	}
+	return append(out, block...)
+}
@@ -0,0 +1,62 @@
+// Package privacy enforces local-only mode: when it is on, nothing that
+// would send memory or event content off this machine may be constructed.
+package privacy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrNotLocal is returned when local-only mode rejects an endpoint
+var ErrNotLocal = errors.New("local-only mode forbids non-localhost endpoints")
+
+var localOnly atomic.Bool
+
+// Enforce turns local-only mode on or off for the process
+func Enforce(on bool) {
+	localOnly.Store(on)
+}
+
+// Enabled reports whether local-only mode is on
+func Enabled() bool {
+	return localOnly.Load()
+}
+
+// IsLocalURL reports whether a URL points at this machine: localhost, a
+// loopback address or a unix socket
+func IsLocalURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "unix" {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// CheckURL rejects a non-local endpoint when local-only mode is on. what
+// names the component for the error, e.g. "team sync".
+func CheckURL(what, raw string) error {
+	if !Enabled() || IsLocalURL(raw) {
+		return nil
+	}
+	return fmt.Errorf("%s endpoint %s: %w", what, raw, ErrNotLocal)
+}
+
+// CheckRemote rejects a component that always talks to a remote service
+func CheckRemote(what string) error {
+	if !Enabled() {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", what, ErrNotLocal)
+}
@@ -0,0 +1,149 @@
+package projects
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// MinProjectSimilarity is how close another project's centroid must be
+	// before its memories are suggested
+	MinProjectSimilarity = 0.5
+
+	// maxSimilarProjects caps how many projects suggestions are drawn from
+	maxSimilarProjects = 3
+
+	// minCentroidMemories is how many memories a project needs before its own
+	// centroid describes it better than its README does
+	minCentroidMemories = 3
+)
+
+// manifests are files whose presence and first lines say what a project is
+var manifests = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "requirements.txt", "Gemfile", "pom.xml", "build.gradle"}
+
+// Suggestion is a memory from a similar past project
+type Suggestion struct {
+	Project           models.Project `json:"project"`
+	ProjectSimilarity float32        `json:"projectSimilarity"`
+	Memory            models.Memory  `json:"memory"`
+	Similarity        float32        `json:"similarity"`
+}
+
+// SimilarProject is another project ranked by centroid similarity
+type SimilarProject struct {
+	Project    models.Project `json:"project"`
+	Similarity float32        `json:"similarity"`
+	Memories   int            `json:"memories"`
+}
+
+// Suggest finds projects similar to the one at dir and their memories most
+// relevant to it. The current project is described by its memory centroid,
+// or by its README and manifests when it is too new to have memories.
+func Suggest(s *store.Store, emb embedding.Embedder, dir string, limit int) ([]SimilarProject, []Suggestion, error) {
+	current, err := s.GetProjectByPath(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	centroids, err := s.ProjectCentroids()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var query []float32
+	for _, c := range centroids {
+		if current != nil && c.Project.ID == current.ID && c.Memories >= minCentroidMemories {
+			query = c.Centroid
+		}
+	}
+	if query == nil {
+		query, err = emb.Embed(Describe(dir))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to embed project description: %w", err)
+		}
+	}
+
+	var similar []SimilarProject
+	for _, c := range centroids {
+		if current != nil && c.Project.ID == current.ID {
+			continue
+		}
+		sim := embedding.CosineSimilarity(query, c.Centroid)
+		if sim >= MinProjectSimilarity {
+			similar = append(similar, SimilarProject{Project: c.Project, Similarity: sim, Memories: c.Memories})
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool { return similar[i].Similarity > similar[j].Similarity })
+	if len(similar) > maxSimilarProjects {
+		similar = similar[:maxSimilarProjects]
+	}
+
+	var suggestions []Suggestion
+	for _, p := range similar {
+		memories, err := s.SimilarProjectMemories(query, p.Project.ID, 0, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range memories {
+			if m.Type == models.MemoryTypeContext {
+				continue // another project's work in progress isn't transferable
+			}
+			suggestions = append(suggestions, Suggestion{
+				Project:           p.Project,
+				ProjectSimilarity: p.Similarity,
+				Memory:            m.Memory,
+				Similarity:        m.Similarity,
+			})
+		}
+	}
+
+	// Weight each memory by how alike the projects are
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Similarity*suggestions[i].ProjectSimilarity >
+			suggestions[j].Similarity*suggestions[j].ProjectSimilarity
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return similar, suggestions, nil
+}
+
+// Describe builds a short text description of a project from its name,
+// README and manifest files
+func Describe(dir string) string {
+	var sb strings.Builder
+	sb.WriteString("Project: " + filepath.Base(dir) + "\n")
+
+	for _, name := range manifests {
+		if head := readHead(filepath.Join(dir, name), 500); head != "" {
+			sb.WriteString(name + ":\n" + head + "\n")
+		}
+	}
+	for _, name := range []string{"README.md", "README", "readme.md"} {
+		if head := readHead(filepath.Join(dir, name), 1500); head != "" {
+			sb.WriteString(head + "\n")
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+func readHead(path string, n int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if len(data) > n {
+		data = data[:n]
+	}
+	return string(data)
+}
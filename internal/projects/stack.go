@@ -0,0 +1,387 @@
+package projects
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+// Stack is the languages, frameworks and dependencies a project uses, as
+// declared by its manifests
+type Stack struct {
+	Languages    []string `json:"languages"`
+	Frameworks   []string `json:"frameworks"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// frameworks maps dependency names (or module path prefixes) to the
+// framework they indicate
+var frameworks = map[string]string{
+	// Go
+	"github.com/gin-gonic/gin":           "Gin",
+	"github.com/labstack/echo":           "Echo",
+	"github.com/gofiber/fiber":           "Fiber",
+	"github.com/go-chi/chi":              "chi",
+	"github.com/spf13/cobra":             "Cobra",
+	"google.golang.org/grpc":             "gRPC",
+	"gorm.io/gorm":                       "GORM",
+	"github.com/mattn/go-sqlite3":        "SQLite",
+	"github.com/jackc/pgx":               "PostgreSQL",
+	"k8s.io/client-go":                   "Kubernetes client",
+	"github.com/stretchr/testify":        "testify",
+	"github.com/charmbracelet/bubbletea": "Bubble Tea",
+	// JavaScript / TypeScript
+	"react":         "React",
+	"next":          "Next.js",
+	"vue":           "Vue",
+	"nuxt":          "Nuxt",
+	"svelte":        "Svelte",
+	"@angular/core": "Angular",
+	"express":       "Express",
+	"fastify":       "Fastify",
+	"@nestjs/core":  "NestJS",
+	"electron":      "Electron",
+	"tailwindcss":   "Tailwind CSS",
+	"prisma":        "Prisma",
+	"jest":          "Jest",
+	"vitest":        "Vitest",
+	"react-native":  "React Native",
+	// Python
+	"django":     "Django",
+	"flask":      "Flask",
+	"fastapi":    "FastAPI",
+	"sqlalchemy": "SQLAlchemy",
+	"pytest":     "pytest",
+	"pandas":     "pandas",
+	"torch":      "PyTorch",
+	"tensorflow": "TensorFlow",
+	// Rust
+	"tokio":     "Tokio",
+	"actix-web": "Actix Web",
+	"axum":      "Axum",
+	"serde":     "Serde",
+	"clap":      "clap",
+	// Ruby / JVM
+	"rails":                    "Rails",
+	"sinatra":                  "Sinatra",
+	"spring-boot-starter":      "Spring Boot",
+	"spring-boot-starter-web":  "Spring Boot",
+	"org.springframework.boot": "Spring Boot",
+}
+
+// DetectStack reads the manifests in dir and reports the project's stack
+func DetectStack(dir string) Stack {
+	languages := make(map[string]bool)
+	var deps []string
+
+	if lines := readLines(filepath.Join(dir, "go.mod")); lines != nil {
+		languages["Go"] = true
+		deps = append(deps, goModDeps(lines)...)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		languages["JavaScript"] = true
+		pkgDeps := packageJSONDeps(data)
+		if slices.Contains(pkgDeps, "typescript") || exists(filepath.Join(dir, "tsconfig.json")) {
+			languages["TypeScript"] = true
+		}
+		deps = append(deps, pkgDeps...)
+	}
+	if lines := readLines(filepath.Join(dir, "Cargo.toml")); lines != nil {
+		languages["Rust"] = true
+		deps = append(deps, tomlSectionKeys(lines, "dependencies")...)
+	}
+	if lines := readLines(filepath.Join(dir, "pyproject.toml")); lines != nil {
+		languages["Python"] = true
+		deps = append(deps, pyprojectDeps(lines)...)
+	}
+	if lines := readLines(filepath.Join(dir, "requirements.txt")); lines != nil {
+		languages["Python"] = true
+		deps = append(deps, requirementsDeps(lines)...)
+	}
+	if lines := readLines(filepath.Join(dir, "Gemfile")); lines != nil {
+		languages["Ruby"] = true
+		deps = append(deps, gemfileDeps(lines)...)
+	}
+	if lines := readLines(filepath.Join(dir, "pom.xml")); lines != nil {
+		languages["Java"] = true
+		deps = append(deps, xmlValues(lines, "artifactId")...)
+	}
+	if lines := readLines(filepath.Join(dir, "build.gradle")); lines != nil {
+		languages["Java"] = true
+		deps = append(deps, gradleDeps(lines)...)
+	}
+
+	stack := Stack{Languages: sortedKeys(languages)}
+	found := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, dep := range deps {
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		stack.Dependencies = append(stack.Dependencies, dep)
+		if fw := frameworkFor(dep); fw != "" {
+			found[fw] = true
+		}
+	}
+	stack.Frameworks = sortedKeys(found)
+	sort.Strings(stack.Dependencies)
+	return stack
+}
+
+// Facts converts the stack to structured project facts
+func (s Stack) Facts() []store.ProjectFact {
+	var facts []store.ProjectFact
+	for _, v := range s.Languages {
+		facts = append(facts, store.ProjectFact{Kind: store.FactLanguage, Value: v})
+	}
+	for _, v := range s.Frameworks {
+		facts = append(facts, store.ProjectFact{Kind: store.FactFramework, Value: v})
+	}
+	for _, v := range s.Dependencies {
+		facts = append(facts, store.ProjectFact{Kind: store.FactDependency, Value: v})
+	}
+	return facts
+}
+
+// StackFromFacts rebuilds a stack from stored project facts
+func StackFromFacts(facts []store.ProjectFact) Stack {
+	var s Stack
+	for _, f := range facts {
+		switch f.Kind {
+		case store.FactLanguage:
+			s.Languages = append(s.Languages, f.Value)
+		case store.FactFramework:
+			s.Frameworks = append(s.Frameworks, f.Value)
+		case store.FactDependency:
+			s.Dependencies = append(s.Dependencies, f.Value)
+		}
+	}
+	return s
+}
+
+// String renders the stack in one line, e.g. "Go; frameworks: Cobra, SQLite"
+func (s Stack) String() string {
+	if len(s.Languages) == 0 {
+		return ""
+	}
+	out := strings.Join(s.Languages, ", ")
+	if len(s.Frameworks) > 0 {
+		out += "; frameworks: " + strings.Join(s.Frameworks, ", ")
+	}
+	return out
+}
+
+// IsManifest reports whether a file name is a manifest DetectStack reads
+func IsManifest(name string) bool {
+	for _, m := range manifests {
+		if name == m {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshStack detects the stack of the project at dir and stores it as
+// the project's facts
+func RefreshStack(s *store.Store, dir string) (Stack, error) {
+	project, err := s.EnsureProject(dir)
+	if err != nil {
+		return Stack{}, err
+	}
+	stack := DetectStack(dir)
+	return stack, s.SetProjectFacts(project.ID, stack.Facts())
+}
+
+func frameworkFor(dep string) string {
+	if fw, ok := frameworks[dep]; ok {
+		return fw
+	}
+	// Go modules may carry a major version suffix (github.com/labstack/echo/v4)
+	for prefix, fw := range frameworks {
+		if strings.Contains(prefix, "/") && strings.HasPrefix(dep, prefix+"/") {
+			return fw
+		}
+	}
+	return ""
+}
+
+func goModDeps(lines []string) []string {
+	var deps []string
+	inBlock := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "require ("):
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "" && !strings.HasPrefix(line, "//"):
+			if !strings.Contains(line, "// indirect") {
+				deps = append(deps, strings.Fields(line)[0])
+			}
+		case strings.HasPrefix(line, "require "):
+			if fields := strings.Fields(line); len(fields) >= 2 && !strings.Contains(line, "// indirect") {
+				deps = append(deps, fields[1])
+			}
+		}
+	}
+	return deps
+}
+
+func packageJSONDeps(data []byte) []string {
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return nil
+	}
+	var deps []string
+	for name := range pkg.Dependencies {
+		deps = append(deps, name)
+	}
+	for name := range pkg.DevDependencies {
+		deps = append(deps, name)
+	}
+	return deps
+}
+
+var requirementName = regexp.MustCompile(`^([A-Za-z0-9_.\-]+)`)
+
+func requirementsDeps(lines []string) []string {
+	var deps []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if m := requirementName.FindString(line); m != "" {
+			deps = append(deps, strings.ToLower(m))
+		}
+	}
+	return deps
+}
+
+// pyprojectDeps reads PEP 621 dependencies and Poetry dependency tables
+func pyprojectDeps(lines []string) []string {
+	deps := tomlSectionKeys(lines, "tool.poetry.dependencies")
+	inList := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "dependencies = [") {
+			inList = true
+			line = strings.TrimPrefix(line, "dependencies = [")
+		}
+		if !inList {
+			continue
+		}
+		for _, item := range strings.Split(line, ",") {
+			item = strings.Trim(strings.TrimSpace(item), `"'[]`)
+			if m := requirementName.FindString(item); m != "" {
+				deps = append(deps, strings.ToLower(m))
+			}
+		}
+		if strings.Contains(line, "]") {
+			inList = false
+		}
+	}
+	var filtered []string
+	for _, d := range deps {
+		if d != "python" {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// tomlSectionKeys returns the keys of a [section] table
+func tomlSectionKeys(lines []string, section string) []string {
+	var keys []string
+	in := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			in = line == "["+section+"]"
+			continue
+		}
+		if in && line != "" && !strings.HasPrefix(line, "#") {
+			if key, _, ok := strings.Cut(line, "="); ok {
+				keys = append(keys, strings.Trim(strings.TrimSpace(key), `"`))
+			}
+		}
+	}
+	return keys
+}
+
+var gemName = regexp.MustCompile(`^gem\s+['"]([^'"]+)['"]`)
+
+func gemfileDeps(lines []string) []string {
+	var deps []string
+	for _, line := range lines {
+		if m := gemName.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			deps = append(deps, m[1])
+		}
+	}
+	return deps
+}
+
+var gradleDep = regexp.MustCompile(`['"]([\w.\-]+):([\w.\-]+)(:[^'"]*)?['"]`)
+
+func gradleDeps(lines []string) []string {
+	var deps []string
+	for _, line := range lines {
+		if m := gradleDep.FindStringSubmatch(line); m != nil {
+			deps = append(deps, m[2])
+		}
+	}
+	return deps
+}
+
+func xmlValues(lines []string, tag string) []string {
+	open, end := "<"+tag+">", "</"+tag+">"
+	var values []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, open) && strings.HasSuffix(line, end) {
+			values = append(values, strings.TrimSuffix(strings.TrimPrefix(line, open), end))
+		}
+	}
+	return values
+}
+
+func readLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,159 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// confluencePublisher replaces the body of a Confluence page with the
+// selected memories, grouped by type
+type confluencePublisher struct {
+	base   string // including the /wiki context path on Confluence Cloud
+	email  string
+	token  string
+	client *http.Client
+}
+
+func newConfluence(creds Credentials) *confluencePublisher {
+	return &confluencePublisher{
+		base:   creds.ConfluenceURL,
+		email:  creds.ConfluenceEmail,
+		token:  creds.ConfluenceToken,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish rewrites the page unless its content would be unchanged
+func (p *confluencePublisher) Publish(ctx context.Context, s *store.Store, t store.PublishTarget, memories []models.Memory, dryRun bool) (Result, error) {
+	body := confluencePage(memories)
+	res := Result{Hash: hashOf(body)}
+	if res.Hash == t.LastHash {
+		res.Unchanged = len(memories)
+		return res, nil
+	}
+	res.Published = len(memories)
+	if dryRun {
+		return res, nil
+	}
+
+	var page struct {
+		Title   string `json:"title"`
+		Version struct {
+			Number int `json:"number"`
+		} `json:"version"`
+	}
+	path := "/rest/api/content/" + t.Destination
+	if err := p.request(ctx, http.MethodGet, path+"?expand=version", nil, &page); err != nil {
+		return Result{}, fmt.Errorf("failed to read Confluence page: %w", err)
+	}
+
+	update := map[string]interface{}{
+		"id":      t.Destination,
+		"type":    "page",
+		"title":   page.Title,
+		"version": map[string]interface{}{"number": page.Version.Number + 1, "message": "Synced by MemoryPilot"},
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+	}
+	if err := p.request(ctx, http.MethodPut, path, update, nil); err != nil {
+		return Result{}, fmt.Errorf("failed to update Confluence page: %w", err)
+	}
+	return res, nil
+}
+
+// confluencePage renders memories in Confluence storage format
+func confluencePage(memories []models.Memory) string {
+	byType := make(map[models.MemoryType][]models.Memory)
+	for _, m := range memories {
+		byType[m.Type] = append(byType[m.Type], m)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>`)
+	b.WriteString("This page is maintained by MemoryPilot; edits here are overwritten on the next sync.")
+	b.WriteString(`</p></ac:rich-text-body></ac:structured-macro>`)
+	if len(memories) == 0 {
+		b.WriteString("<p><em>No memories selected yet.</em></p>")
+	}
+
+	for _, t := range typeOrder {
+		list := byType[t]
+		if len(list) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "<h2>%s</h2>", html.EscapeString(pluralTitle(t)))
+		for _, m := range list {
+			fmt.Fprintf(&b, "<h3>%s</h3>", html.EscapeString(m.Summary))
+			fmt.Fprintf(&b, "<p>%s</p>", strings.ReplaceAll(html.EscapeString(m.Content), "\n", "<br/>"))
+			meta := m.CreatedAt.Format("2006-01-02") + " · " + string(m.Scope)
+			if len(m.Topics) > 0 {
+				meta += " · " + strings.Join(m.Topics, ", ")
+			}
+			fmt.Fprintf(&b, "<p><sub>%s</sub></p>", html.EscapeString(meta))
+		}
+	}
+	return b.String()
+}
+
+// pluralTitle names a section of memories of one type
+func pluralTitle(t models.MemoryType) string {
+	switch t {
+	case models.MemoryTypeContext:
+		return "Context"
+	default:
+		s := string(t)
+		return strings.ToUpper(s[:1]) + s[1:] + "s"
+	}
+}
+
+// request calls the Confluence REST API
+func (p *confluencePublisher) request(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.base+path, payload)
+	if err != nil {
+		return err
+	}
+	if p.email != "" {
+		req.SetBasicAuth(p.email, p.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("Confluence API %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	notionVersion = "2022-06-28"
+
+	// notionTextLimit is the longest text object Notion accepts
+	notionTextLimit = 2000
+
+	// notionInterval keeps requests under Notion's 3 per second limit
+	notionInterval = 350 * time.Millisecond
+)
+
+// notionProperties are the database properties memories are written to,
+// besides the title, with their Notion types. Missing ones are added.
+var notionProperties = map[string]string{
+	"Type":      "select",
+	"Scope":     "select",
+	"Topics":    "multi_select",
+	"Content":   "rich_text",
+	"Memory ID": "rich_text",
+	"Created":   "date",
+}
+
+// notionPublisher publishes each memory as a page in a Notion database
+type notionPublisher struct {
+	token  string
+	api    string
+	client *http.Client
+	last   time.Time
+}
+
+func newNotion(creds Credentials) *notionPublisher {
+	return &notionPublisher{
+		token:  creds.NotionToken,
+		api:    creds.NotionAPI,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Publish creates a page per new memory, updates pages of changed ones and
+// archives pages of memories no longer selected
+func (p *notionPublisher) Publish(ctx context.Context, s *store.Store, t store.PublishTarget, memories []models.Memory, dryRun bool) (Result, error) {
+	var res Result
+	published, err := s.PublishedMemories(t.ID)
+	if err != nil {
+		return res, err
+	}
+
+	var title string
+	if !dryRun {
+		if title, err = p.ensureSchema(ctx, t.Destination); err != nil {
+			return res, err
+		}
+	}
+
+	selected := make(map[string]bool, len(memories))
+	for _, m := range memories {
+		selected[m.ID] = true
+		hash := memoryHash(m)
+		prev, ok := published[m.ID]
+		if ok && prev.Hash == hash {
+			res.Unchanged++
+			continue
+		}
+		if dryRun {
+			if ok {
+				res.Updated++
+			} else {
+				res.Published++
+			}
+			continue
+		}
+
+		pageID := prev.RemoteID
+		if ok {
+			err = p.request(ctx, http.MethodPatch, "/v1/pages/"+pageID, map[string]interface{}{
+				"properties": notionPage(title, m),
+			}, nil)
+			if isNotFound(err) {
+				ok = false // deleted in Notion; publish it again
+			} else if err != nil {
+				return res, fmt.Errorf("failed to update page for %s: %w", m.ID, err)
+			} else {
+				res.Updated++
+			}
+		}
+		if !ok {
+			var page struct {
+				ID string `json:"id"`
+			}
+			err = p.request(ctx, http.MethodPost, "/v1/pages", map[string]interface{}{
+				"parent":     map[string]string{"database_id": t.Destination},
+				"properties": notionPage(title, m),
+			}, &page)
+			if err != nil {
+				return res, fmt.Errorf("failed to create page for %s: %w", m.ID, err)
+			}
+			pageID = page.ID
+			res.Published++
+		}
+		if err := s.SetPublishedMemory(t.ID, m.ID, store.PublishedMemory{RemoteID: pageID, Hash: hash}); err != nil {
+			return res, err
+		}
+	}
+
+	for memoryID, prev := range published {
+		if selected[memoryID] {
+			continue
+		}
+		res.Archived++
+		if dryRun {
+			continue
+		}
+		err := p.request(ctx, http.MethodPatch, "/v1/pages/"+prev.RemoteID, map[string]interface{}{"archived": true}, nil)
+		if err != nil && !isNotFound(err) {
+			return res, fmt.Errorf("failed to archive page for %s: %w", memoryID, err)
+		}
+		if err := s.DeletePublishedMemory(t.ID, memoryID); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// ensureSchema adds missing properties to the database, returning the name
+// of its title property
+func (p *notionPublisher) ensureSchema(ctx context.Context, databaseID string) (string, error) {
+	var db struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := p.request(ctx, http.MethodGet, "/v1/databases/"+databaseID, nil, &db); err != nil {
+		return "", fmt.Errorf("failed to read Notion database (is it shared with the integration?): %w", err)
+	}
+
+	var title string
+	missing := map[string]interface{}{}
+	for name, prop := range db.Properties {
+		if prop.Type == "title" {
+			title = name
+		}
+	}
+	for name, kind := range notionProperties {
+		prop, ok := db.Properties[name]
+		if !ok {
+			missing[name] = map[string]interface{}{kind: map[string]interface{}{}}
+			continue
+		}
+		if prop.Type != kind {
+			return "", fmt.Errorf("Notion property %q is %s, expected %s", name, prop.Type, kind)
+		}
+	}
+	if title == "" {
+		return "", fmt.Errorf("Notion database has no title property")
+	}
+
+	if len(missing) > 0 {
+		if err := p.request(ctx, http.MethodPatch, "/v1/databases/"+databaseID, map[string]interface{}{"properties": missing}, nil); err != nil {
+			return "", fmt.Errorf("failed to add properties to Notion database: %w", err)
+		}
+	}
+	return title, nil
+}
+
+// notionPage is the page properties describing a memory
+func notionPage(title string, m models.Memory) map[string]interface{} {
+	topics := []map[string]string{}
+	for _, t := range m.Topics {
+		// Select options can't contain commas
+		if name := strings.TrimSpace(strings.ReplaceAll(t, ",", " ")); name != "" {
+			topics = append(topics, map[string]string{"name": textutil.Truncate(name, 100)})
+		}
+	}
+	return map[string]interface{}{
+		title:       map[string]interface{}{"title": notionText(m.Summary)},
+		"Type":      map[string]interface{}{"select": map[string]string{"name": string(m.Type)}},
+		"Scope":     map[string]interface{}{"select": map[string]string{"name": string(m.Scope)}},
+		"Topics":    map[string]interface{}{"multi_select": topics},
+		"Content":   map[string]interface{}{"rich_text": notionText(m.Content)},
+		"Memory ID": map[string]interface{}{"rich_text": notionText(m.ID)},
+		"Created":   map[string]interface{}{"date": map[string]string{"start": m.CreatedAt.UTC().Format(time.RFC3339)}},
+	}
+}
+
+// notionText splits text into rich text objects under Notion's size limit
+func notionText(text string) []map[string]interface{} {
+	var out []map[string]interface{}
+	runes := []rune(text)
+	for len(runes) > 0 && len(out) < 100 {
+		n := min(len(runes), notionTextLimit)
+		out = append(out, map[string]interface{}{
+			"type": "text",
+			"text": map[string]string{"content": string(runes[:n])},
+		})
+		runes = runes[n:]
+	}
+	return out
+}
+
+// notionError is an error response from the Notion API
+type notionError struct {
+	Status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *notionError) Error() string {
+	return fmt.Sprintf("Notion API %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+func isNotFound(err error) bool {
+	ne, ok := err.(*notionError)
+	return ok && ne.Status == http.StatusNotFound
+}
+
+// request calls the Notion API, pacing requests and retrying when rate
+// limited
+func (p *notionPublisher) request(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if wait := notionInterval - time.Since(p.last); wait > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+		p.last = time.Now()
+
+		req, err := http.NewRequestWithContext(ctx, method, p.api+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+p.token)
+		req.Header.Set("Notion-Version", notionVersion)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < 3 {
+			retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			p.last = time.Now().Add(time.Duration(max(retry, 1)) * time.Second)
+			continue
+		}
+		if resp.StatusCode/100 != 2 {
+			ne := &notionError{Status: resp.StatusCode}
+			if json.Unmarshal(data, ne) != nil || ne.Message == "" {
+				ne.Message = strings.TrimSpace(string(data))
+			}
+			return ne
+		}
+		if out != nil {
+			return json.Unmarshal(data, out)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,235 @@
+// Package publish keeps pages in external knowledge bases (a Notion
+// database, a Confluence page) in sync with a selection of memories.
+package publish
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Publish target kinds
+const (
+	KindNotion     = "notion"
+	KindConfluence = "confluence"
+)
+
+// DefaultInterval is how often the daemon republishes a target
+const DefaultInterval = time.Hour
+
+// Filter selects the memories published to a target. Topics act as
+// collections: a memory is selected if it has any of them.
+type Filter struct {
+	Types     []models.MemoryType  `json:"types,omitempty"`
+	Scopes    []models.MemoryScope `json:"scopes,omitempty"`
+	ProjectID *string              `json:"projectId,omitempty"`
+	Topics    []string             `json:"topics,omitempty"`
+}
+
+// ParseFilter decodes a filter saved with a target
+func ParseFilter(data string) (Filter, error) {
+	var f Filter
+	if err := json.Unmarshal([]byte(data), &f); err != nil {
+		return f, fmt.Errorf("invalid publish filter: %w", err)
+	}
+	return f, nil
+}
+
+// Encode serializes the filter for saving with a target
+func (f Filter) Encode() string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+// Describe summarizes the filter for display
+func (f Filter) Describe() string {
+	var parts []string
+	if len(f.Types) > 0 {
+		types := make([]string, len(f.Types))
+		for i, t := range f.Types {
+			types[i] = string(t)
+		}
+		parts = append(parts, "types "+strings.Join(types, ", "))
+	}
+	if len(f.Scopes) > 0 {
+		scopes := make([]string, len(f.Scopes))
+		for i, sc := range f.Scopes {
+			scopes[i] = string(sc)
+		}
+		parts = append(parts, "scopes "+strings.Join(scopes, ", "))
+	}
+	if f.ProjectID != nil {
+		parts = append(parts, "project "+*f.ProjectID)
+	}
+	if len(f.Topics) > 0 {
+		parts = append(parts, "topics "+strings.Join(f.Topics, ", "))
+	}
+	if len(parts) == 0 {
+		return "all memories"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Select returns the memories the filter selects, newest first
+func Select(s *store.Store, f Filter) ([]models.Memory, error) {
+	memories, err := s.ListMemories(models.RecallRequest{Types: f.Types, Scope: f.Scopes, ProjectID: f.ProjectID})
+	if err != nil || len(f.Topics) == 0 {
+		return memories, err
+	}
+
+	var selected []models.Memory
+	for _, m := range memories {
+		if hasAnyTopic(m, f.Topics) {
+			selected = append(selected, m)
+		}
+	}
+	return selected, nil
+}
+
+func hasAnyTopic(m models.Memory, topics []string) bool {
+	for _, want := range topics {
+		for _, t := range m.Topics {
+			if strings.EqualFold(t, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Credentials for the external services, read from the environment
+type Credentials struct {
+	NotionToken string
+	NotionAPI   string // default https://api.notion.com
+
+	ConfluenceURL   string // e.g. https://acme.atlassian.net/wiki
+	ConfluenceEmail string // with an API token; without, the token is a bearer PAT
+	ConfluenceToken string
+}
+
+// CredentialsFromEnv reads NOTION_TOKEN, CONFLUENCE_URL, CONFLUENCE_EMAIL
+// and CONFLUENCE_API_TOKEN
+func CredentialsFromEnv() Credentials {
+	api := os.Getenv("NOTION_API_URL")
+	if api == "" {
+		api = "https://api.notion.com"
+	}
+	return Credentials{
+		NotionToken:     os.Getenv("NOTION_TOKEN"),
+		NotionAPI:       strings.TrimSuffix(api, "/"),
+		ConfluenceURL:   strings.TrimSuffix(os.Getenv("CONFLUENCE_URL"), "/"),
+		ConfluenceEmail: os.Getenv("CONFLUENCE_EMAIL"),
+		ConfluenceToken: os.Getenv("CONFLUENCE_API_TOKEN"),
+	}
+}
+
+// Result counts what a publish run changed
+type Result struct {
+	Published int // newly published (or, for whole pages, memories on the page)
+	Updated   int
+	Archived  int // no longer selected, removed from the destination
+	Unchanged int
+
+	// Hash of a whole published page, saved to skip unchanged pages
+	Hash string
+}
+
+// Changed reports whether the run changed the destination
+func (r Result) Changed() bool {
+	return r.Published+r.Updated+r.Archived > 0
+}
+
+// Publisher writes memories to one kind of destination
+type Publisher interface {
+	// Publish brings the target's destination in line with memories. With
+	// dryRun, it only reports what would change.
+	Publish(ctx context.Context, s *store.Store, t store.PublishTarget, memories []models.Memory, dryRun bool) (Result, error)
+}
+
+// New returns the publisher for a kind of target
+func New(kind string, creds Credentials) (Publisher, error) {
+	switch kind {
+	case KindNotion:
+		if creds.NotionToken == "" {
+			return nil, fmt.Errorf("NOTION_TOKEN is not set")
+		}
+		if err := privacy.CheckURL("notion", creds.NotionAPI); err != nil {
+			return nil, err
+		}
+		return newNotion(creds), nil
+	case KindConfluence:
+		if creds.ConfluenceURL == "" || creds.ConfluenceToken == "" {
+			return nil, fmt.Errorf("CONFLUENCE_URL and CONFLUENCE_API_TOKEN must be set")
+		}
+		if err := privacy.CheckURL("confluence", creds.ConfluenceURL); err != nil {
+			return nil, err
+		}
+		return newConfluence(creds), nil
+	default:
+		return nil, fmt.Errorf("unknown publish target kind %q", kind)
+	}
+}
+
+// Run publishes the memories a target selects and records the outcome
+func Run(ctx context.Context, s *store.Store, t store.PublishTarget, creds Credentials, dryRun bool) (Result, error) {
+	res, err := run(ctx, s, t, creds, dryRun)
+	if !dryRun {
+		if recErr := s.RecordPublishRun(t.ID, time.Now(), err, res.Hash); recErr != nil && err == nil {
+			err = recErr
+		}
+	}
+	return res, err
+}
+
+func run(ctx context.Context, s *store.Store, t store.PublishTarget, creds Credentials, dryRun bool) (Result, error) {
+	filter, err := ParseFilter(t.Filter)
+	if err != nil {
+		return Result{}, err
+	}
+	pub, err := New(t.Kind, creds)
+	if err != nil {
+		return Result{}, err
+	}
+	memories, err := Select(s, filter)
+	if err != nil {
+		return Result{}, err
+	}
+	return pub.Publish(ctx, s, t, memories, dryRun)
+}
+
+// memoryHash fingerprints the published fields of a memory
+func memoryHash(m models.Memory) string {
+	topics := append([]string{}, m.Topics...)
+	sort.Strings(topics)
+	return hashOf(string(m.Type), string(m.Scope), m.Summary, m.Content, strings.Join(topics, ","))
+}
+
+func hashOf(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// typeOrder is the order memory types are listed on whole pages
+var typeOrder = []models.MemoryType{
+	models.MemoryTypeDecision,
+	models.MemoryTypePattern,
+	models.MemoryTypeMistake,
+	models.MemoryTypeLearning,
+	models.MemoryTypePreference,
+	models.MemoryTypeFact,
+	models.MemoryTypeContext,
+}
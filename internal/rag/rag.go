@@ -0,0 +1,76 @@
+package rag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Answer is a synthesized answer with the memories it cites
+type Answer struct {
+	Question  string          `json:"question"`
+	Text      string          `json:"answer"`
+	Citations []models.Memory `json:"citations"`
+}
+
+const askPrompt = `You answer a software developer's question using ONLY their remembered
+context below. Each memory is numbered.
+
+Rules:
+- Cite every claim with the memory number in square brackets, e.g. [2]
+- If the memories don't answer the question, say so plainly; don't guess
+- Be concise: 1-4 sentences
+
+Memories:
+%s
+Question: %s
+
+Answer:`
+
+var citationRef = regexp.MustCompile(`\[(\d+)\]`)
+
+// Ask synthesizes an answer to the question from the retrieved memories
+func Ask(c extractor.Completer, question string, memories []models.Memory) (*Answer, error) {
+	answer := &Answer{Question: question}
+	if len(memories) == 0 {
+		answer.Text = "I don't have any memories related to that."
+		return answer, nil
+	}
+
+	var sb strings.Builder
+	for i, m := range memories {
+		sb.WriteString(fmt.Sprintf("[%d] (%s, %s) %s\n", i+1, m.Type, m.CreatedAt.Format("2006-01-02"), m.Content))
+	}
+
+	response, err := c.Complete(fmt.Sprintf(askPrompt, sb.String(), question))
+	if err != nil {
+		return nil, err
+	}
+	answer.Text = strings.TrimSpace(response)
+
+	// Keep only the memories the answer actually cites, in citation order
+	cited := make(map[int]bool)
+	for _, match := range citationRef.FindAllStringSubmatch(answer.Text, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n > len(memories) || cited[n] {
+			continue
+		}
+		cited[n] = true
+		answer.Citations = append(answer.Citations, memories[n-1])
+	}
+
+	// Replace [n] with the memory ID so citations survive outside this answer
+	answer.Text = citationRef.ReplaceAllStringFunc(answer.Text, func(ref string) string {
+		n, _ := strconv.Atoi(ref[1 : len(ref)-1])
+		if n < 1 || n > len(memories) {
+			return ref
+		}
+		return "[" + memories[n-1].ID + "]"
+	})
+
+	return answer, nil
+}
@@ -0,0 +1,98 @@
+// Package recall answers several queries in one call, for agents that
+// assemble context for a handful of sub-questions at once
+package recall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/memorypilot/memorypilot/internal/pack"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// MaxQueries bounds the queries in one batch
+	MaxQueries = 20
+
+	// DefaultLimit is the most memories returned per query when a query
+	// doesn't say
+	DefaultLimit = 5
+)
+
+// ErrInvalid is returned for a batch with no queries or too many
+var ErrInvalid = errors.New("invalid batch")
+
+// SearchFunc runs one query, best matches first
+type SearchFunc func(models.RecallRequest) ([]models.Memory, error)
+
+// Batch runs every query and fits the results into one token budget. A
+// memory matching several queries is returned for the one it ranks highest
+// in. The budget is spent round-robin by rank, so each query gets its best
+// match before any query gets its second.
+func Batch(req models.BatchRecallRequest, search SearchFunc) (*models.BatchRecallResponse, error) {
+	if len(req.Queries) == 0 {
+		return nil, fmt.Errorf("%w: no queries given", ErrInvalid)
+	}
+	if len(req.Queries) > MaxQueries {
+		return nil, fmt.Errorf("%w: %d queries (at most %d)", ErrInvalid, len(req.Queries), MaxQueries)
+	}
+	budget := req.Budget
+	if budget <= 0 {
+		budget = pack.DefaultBudget
+	}
+
+	// Each memory goes to the query it ranks highest in, the earlier query
+	// on ties
+	type placement struct{ query, rank int }
+	ranked := make([][]models.Memory, len(req.Queries))
+	best := make(map[string]placement)
+	for i, q := range req.Queries {
+		if q.Limit <= 0 {
+			q.Limit = DefaultLimit
+		}
+		memories, err := search(q)
+		if err != nil {
+			return nil, fmt.Errorf("query %q: %w", q.Query, err)
+		}
+		ranked[i] = memories
+		for rank, m := range memories {
+			if p, ok := best[m.ID]; !ok || rank < p.rank {
+				best[m.ID] = placement{i, rank}
+			}
+		}
+	}
+
+	resp := &models.BatchRecallResponse{
+		Results: make([]models.RecallResponse, len(req.Queries)),
+		Budget:  budget,
+	}
+	for i, q := range req.Queries {
+		resp.Results[i] = models.RecallResponse{Query: q.Query, Memories: []models.Memory{}}
+	}
+	for rank := 0; ; rank++ {
+		more := false
+		for i, memories := range ranked {
+			if rank >= len(memories) {
+				continue
+			}
+			more = true
+			m := memories[rank]
+			if p := best[m.ID]; p.query != i {
+				resp.Duplicates++
+				continue
+			}
+			cost := pack.EstimateTokens(m.Summary + "\n" + m.Content)
+			if resp.Tokens+cost > budget {
+				resp.Dropped++
+				continue
+			}
+			resp.Tokens += cost
+			resp.Results[i].Memories = append(resp.Results[i].Memories, m)
+			resp.Results[i].Total++
+		}
+		if !more {
+			break
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,375 @@
+// Package reprocess re-runs extraction over retained events so prompt and
+// model improvements apply to past activity.
+package reprocess
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/identity"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// DefaultBatchSize matches the agent's extraction batch size
+	DefaultBatchSize = 10
+
+	// DuplicateSimilarity is the embedding similarity above which an
+	// extracted memory is considered already known
+	DuplicateSimilarity = 0.9
+
+	// SourceReference marks memories created by reprocessing
+	SourceReference = "reprocess"
+
+	// StaleConfidence is the confidence below which memories extracted
+	// with older prompts are flagged for reprocessing
+	StaleConfidence = 0.75
+
+	// promptVersionKey records the prompt version last seen, in sync_state
+	promptVersionKey = "extractor:promptVersion"
+)
+
+// Options controls a reprocessing run
+type Options struct {
+	Since       time.Time
+	BatchSize   int
+	DryRun      bool    // report new memories without saving them
+	ReviewBelow float64 // memories less confident than this await review
+}
+
+// Result summarizes a reprocessing run
+type Result struct {
+	Events     int
+	Batches    int
+	Failed     int // batches whose extraction produced unusable output
+	Extracted  int
+	Duplicates int
+	Added      []models.Memory
+
+	// For flagged memories: those archived because re-extraction replaced
+	// them, and how many couldn't be reprocessed as their events are gone
+	Superseded []models.Memory
+	Missing    int
+}
+
+// Run extracts memories from events captured since opts.Since and saves
+// the ones that don't duplicate an existing memory. emb may be nil, in
+// which case only exact duplicates are detected. Runs stop early when the
+// provider becomes unavailable, returning what was added so far.
+func Run(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, opts Options) (*Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	events, err := s.GetEventsBetween(opts.Since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &runner{s: s, ext: ext, emb: emb, opts: opts, result: &Result{Events: len(events)}, seen: make(map[string]bool)}
+	for start := 0; start < len(events); start += opts.BatchSize {
+		end := start + opts.BatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		if _, err := r.batch(events[start:end]); err != nil {
+			return r.result, err
+		}
+	}
+
+	return r.result, nil
+}
+
+// Flagged re-extracts the events behind memories flagged for reprocessing
+// with the current prompts. A flagged memory is archived when its events
+// yield new memories, and kept when they don't; either way it is
+// unflagged. Memories whose events are no longer stored stay flagged.
+func Flagged(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, opts Options) (*Result, error) {
+	flagged, err := s.FlaggedForReprocess()
+	if err != nil {
+		return nil, err
+	}
+
+	// Memories extracted from the same events are reprocessed together
+	var batches [][]models.Event
+	byBatch := make(map[string][]models.Memory)
+	r := &runner{s: s, ext: ext, emb: emb, opts: opts, result: &Result{}, seen: make(map[string]bool)}
+	for _, m := range flagged {
+		events, err := sourceEvents(s, m.ID)
+		if err != nil {
+			return r.result, err
+		}
+		if len(events) == 0 {
+			r.result.Missing++
+			continue
+		}
+		key := strings.Join(eventIDs(events), ",")
+		if _, ok := byBatch[key]; !ok {
+			batches = append(batches, events)
+			r.result.Events += len(events)
+		}
+		byBatch[key] = append(byBatch[key], m)
+	}
+
+	for _, batch := range batches {
+		added, err := r.batch(batch)
+		if err != nil {
+			return r.result, err
+		}
+		memories := byBatch[strings.Join(eventIDs(batch), ",")]
+		var done []string
+		for _, m := range memories {
+			done = append(done, m.ID)
+			if added == 0 {
+				continue
+			}
+			if !opts.DryRun {
+				if err := s.SetStatus(m.ID, models.MemoryStatusArchived); err != nil && !errors.Is(err, store.ErrHeld) {
+					return r.result, err
+				}
+			}
+			r.result.Superseded = append(r.result.Superseded, m)
+		}
+		if !opts.DryRun {
+			if err := s.ClearReprocessFlags(done); err != nil {
+				return r.result, err
+			}
+		}
+	}
+	return r.result, nil
+}
+
+// Failed re-extracts the events whose extraction failed, in batches, with
+// the current prompts. Events are marked processed once their batch is
+// extracted; a batch failing again counts as another attempt.
+func Failed(s *store.Store, ext extractor.Extractor, emb embedding.Embedder, opts Options) (*Result, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	failed, err := s.FailedEvents()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]models.Event, len(failed))
+	for i, f := range failed {
+		events[i] = f.Event
+	}
+
+	r := &runner{s: s, ext: ext, emb: emb, opts: opts, result: &Result{Events: len(events)}, seen: make(map[string]bool)}
+	for start := 0; start < len(events); start += opts.BatchSize {
+		batch := events[start:min(start+opts.BatchSize, len(events))]
+		r.failure = nil
+		if _, err := r.batch(batch); err != nil {
+			return r.result, err
+		}
+		if opts.DryRun {
+			continue
+		}
+		if r.failure != nil {
+			if _, err := s.FailEvents(batch, r.failure.Error()); err != nil {
+				return r.result, err
+			}
+			continue
+		}
+		for _, e := range batch {
+			if err := s.MarkEventProcessed(e.ID); err != nil {
+				return r.result, err
+			}
+		}
+	}
+	return r.result, nil
+}
+
+// FlagStale flags low-confidence memories from older extraction prompts
+// for reprocessing when the prompt version has changed since it last ran,
+// returning how many were flagged. The first run only records the version:
+// memories from before versioning aren't a different generation.
+func FlagStale(s *store.Store) (int64, error) {
+	current := strconv.Itoa(extractor.PromptVersion)
+	last, _, ok, err := s.GetSyncState(promptVersionKey)
+	if err != nil || last == current {
+		return 0, err
+	}
+	var n int64
+	if ok {
+		if n, err = s.FlagStalePrompts(extractor.PromptVersion, StaleConfidence); err != nil {
+			return 0, err
+		}
+	}
+	return n, s.SetSyncState(promptVersionKey, current)
+}
+
+// sourceEvents returns the stored events a memory was extracted from,
+// preferring those its extraction run's prompt saw
+func sourceEvents(s *store.Store, memoryID string) ([]models.Event, error) {
+	lineage, err := s.Lineage(memoryID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range lineage {
+		if e.Action != store.LineageExtracted {
+			continue
+		}
+		ids := e.EventIDs
+		if e.RunID != "" {
+			run, err := s.GetExtractionRun(e.RunID)
+			if err != nil {
+				return nil, err
+			}
+			if run != nil {
+				ids = run.EventIDs
+			}
+		}
+		return s.GetEvents(ids)
+	}
+	return nil, nil
+}
+
+// runner carries a reprocessing run's state across batches
+type runner struct {
+	s      *store.Store
+	ext    extractor.Extractor
+	emb    embedding.Embedder
+	opts   Options
+	result *Result
+	seen   map[string]bool // normalized content extracted so far
+
+	failure error // why the last failed batch failed
+}
+
+// batch extracts memories from a batch of events and saves the new ones,
+// returning how many were added. A batch with unusable output is counted
+// as failed; an unavailable provider stops the run.
+func (r *runner) batch(batch []models.Event) (int, error) {
+	s, result := r.s, r.result
+	result.Batches++
+
+	extracted, err := r.ext.Extract(batch)
+	if err != nil {
+		if errors.Is(err, extractor.ErrUnavailable) {
+			return 0, fmt.Errorf("extraction unavailable after %d batches: %w", result.Batches-1, err)
+		}
+		result.Failed++
+		r.failure = err
+		return 0, nil
+	}
+
+	added := 0
+	for _, e := range extracted {
+		result.Extracted++
+
+		key := normalize(e.Content)
+		if key == "" || r.seen[key] {
+			result.Duplicates++
+			continue
+		}
+		r.seen[key] = true
+
+		var vec []float32
+		if r.emb != nil {
+			vec, _ = r.emb.Embed(e.Content)
+		}
+		dup, err := s.IsDuplicate(e.Content, vec, DuplicateSimilarity)
+		if err != nil {
+			return added, err
+		}
+		if dup {
+			result.Duplicates++
+			continue
+		}
+
+		m := newMemory(e, extractor.BatchSourceType(batch), batchProject(batch))
+		m.Author = identity.OfEvents(batch)
+		if m.Confidence < r.opts.ReviewBelow {
+			m.Status = models.MemoryStatusPending
+		}
+		identity.Attribute(&m)
+		if !r.opts.DryRun {
+			if err := s.CreateMemory(&m); err != nil {
+				if errors.Is(err, store.ErrDuplicate) {
+					result.Duplicates++
+					continue
+				}
+				return added, fmt.Errorf("failed to save memory: %w", err)
+			}
+			if vec != nil {
+				s.UpdateMemoryEmbedding(m.ID, vec)
+			}
+			s.RecordLineage(m.ID, store.LineageEntry{
+				Action:     store.LineageExtracted,
+				RunID:      e.RunID,
+				EventIDs:   eventIDs(batch),
+				Provider:   e.Provider,
+				Model:      e.Model,
+				PromptHash: e.PromptHash,
+				Confidence: e.Confidence,
+				Note:       "reprocessed",
+			})
+		}
+		result.Added = append(result.Added, m)
+		added++
+	}
+	return added, nil
+}
+
+// newMemory builds a personal memory from an extraction result
+func newMemory(e extractor.ExtractedMemory, source models.SourceType, projectID *string) models.Memory {
+	now := time.Now()
+	return models.Memory{
+		ID:        ulid.Make().String(),
+		Type:      models.MemoryType(e.Type),
+		Content:   e.Content,
+		Summary:   e.Summary,
+		Scope:     models.MemoryScopePersonal,
+		ProjectID: projectID,
+		Source: models.Source{
+			Type:      source,
+			Reference: SourceReference,
+			Timestamp: now,
+		},
+		Confidence:     e.Confidence,
+		Provider:       e.Provider,
+		PromptVersion:  e.PromptVersion,
+		Importance:     1.0,
+		Topics:         e.Topics,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+	}
+}
+
+// eventIDs lists the IDs of a batch's events
+func eventIDs(events []models.Event) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+// batchProject returns the project most of the batch's events belong to
+func batchProject(events []models.Event) *string {
+	counts := make(map[string]int)
+	var best *string
+	for _, e := range events {
+		if e.ProjectID == nil {
+			continue
+		}
+		counts[*e.ProjectID]++
+		if best == nil || counts[*e.ProjectID] > counts[*best] {
+			best = e.ProjectID
+		}
+	}
+	return best
+}
+
+func normalize(content string) string {
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
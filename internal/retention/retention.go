@@ -0,0 +1,253 @@
+// Package retention expires memories by the policies in config.yaml, e.g.
+// "memories tagged client-acme expire when the contract ends" or
+// "terminal-derived facts live 90 days":
+//
+//	retention:
+//	  policies:
+//	    client-acme:
+//	      topic: client-acme
+//	      until: 2026-12-31
+//	    terminal-facts:
+//	      source: terminal
+//	      types: [fact]
+//	      maxAge: 90d
+//
+// Applying a policy brings the expiry of each memory it selects forward to
+// the policy's date (never later than an expiry the memory already has),
+// so recall stops returning it and expiry reminders fire. Once that date
+// passes, the retention job deletes the memory.
+package retention
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/config"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Policy selects memories and says when they expire. Every set selector
+// must match.
+type Policy struct {
+	Name string
+
+	Types   []models.MemoryType
+	Project string // project name
+	Topic   string
+	Source  models.SourceType
+
+	MaxAge time.Duration // expire this long after creation
+	Until  time.Time     // or on this date
+}
+
+// Load reads the policies under retention.policies
+func Load(f *config.File) ([]Policy, error) {
+	var policies []Policy
+	for _, name := range f.Children("retention.policies") {
+		p, err := parse(f, name)
+		if err != nil {
+			return nil, fmt.Errorf("retention.policies.%s: %w", name, err)
+		}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func parse(f *config.File, name string) (Policy, error) {
+	key := "retention.policies." + name + "."
+	p := Policy{Name: name}
+
+	p.Project, _ = f.String(key + "project")
+	p.Topic, _ = f.String(key + "topic")
+	if v, ok := f.String(key + "source"); ok {
+		p.Source = models.SourceType(v)
+		if !validSource(p.Source) {
+			return p, fmt.Errorf("unknown source %q (expected git, file, terminal, chat, manual or import)", v)
+		}
+	}
+	types, _ := f.List(key + "types")
+	if v, ok := f.String(key + "types"); ok {
+		types = []string{v}
+	}
+	for _, t := range types {
+		mt := models.MemoryType(strings.TrimSpace(t))
+		if !mt.Valid() {
+			return p, fmt.Errorf("unknown memory type %q", t)
+		}
+		p.Types = append(p.Types, mt)
+	}
+
+	if v, ok := f.String(key + "maxAge"); ok {
+		d, err := parseAge(v)
+		if err != nil {
+			return p, err
+		}
+		p.MaxAge = d
+	}
+	if v, ok := f.String(key + "until"); ok {
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return p, fmt.Errorf("until: %q is not a date (YYYY-MM-DD)", v)
+		}
+		p.Until = t
+	}
+
+	switch {
+	case len(p.Types) == 0 && p.Project == "" && p.Topic == "" && p.Source == "":
+		return p, fmt.Errorf("set at least one of types, project, topic or source")
+	case p.MaxAge == 0 && p.Until.IsZero():
+		return p, fmt.Errorf("set maxAge or until")
+	case p.MaxAge != 0 && !p.Until.IsZero():
+		return p, fmt.Errorf("set maxAge or until, not both")
+	}
+	return p, nil
+}
+
+// parseAge parses a positive age like "90d", "12w" or "36h"
+func parseAge(v string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(v, suffix); ok {
+			days, err := strconv.Atoi(n)
+			if err != nil || days <= 0 {
+				return 0, fmt.Errorf("maxAge: %q is not an age (e.g. 90d, 12w)", v)
+			}
+			return time.Duration(days) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("maxAge: %q is not an age (e.g. 90d, 12w)", v)
+	}
+	return d, nil
+}
+
+// Selector describes what a policy selects, e.g. "topic client-acme, facts"
+func (p Policy) Selector() string {
+	var parts []string
+	if p.Topic != "" {
+		parts = append(parts, "topic "+p.Topic)
+	}
+	if p.Project != "" {
+		parts = append(parts, "project "+p.Project)
+	}
+	if p.Source != "" {
+		parts = append(parts, string(p.Source)+"-derived")
+	}
+	for _, t := range p.Types {
+		parts = append(parts, string(t)+"s")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Rule describes when a policy expires memories
+func (p Policy) Rule() string {
+	if !p.Until.IsZero() {
+		return "expire on " + p.Until.Format("2006-01-02")
+	}
+	if p.MaxAge%(24*time.Hour) == 0 {
+		return fmt.Sprintf("live %d days", int(p.MaxAge/(24*time.Hour)))
+	}
+	return "live " + p.MaxAge.String()
+}
+
+// ExpiryFor returns when the policy expires a memory created at created
+func (p Policy) ExpiryFor(created time.Time) time.Time {
+	if !p.Until.IsZero() {
+		return p.Until
+	}
+	return created.Add(p.MaxAge)
+}
+
+// Result is what applying one policy did, or would do
+type Result struct {
+	Policy    Policy
+	Matched   int    // memories the policy selects
+	Scheduled int    // memories whose expiry was brought forward
+	Due       int    // memories past the policy's expiry
+	Warning   string // e.g. an unknown project
+}
+
+// Apply applies the policies to the store, then deletes the memories they
+// have expired, returning how many. With dryRun nothing is changed and the
+// count is of memories that would be deleted.
+func Apply(s *store.Store, policies []Policy, now time.Time, dryRun bool) ([]Result, int64, error) {
+	var results []Result
+	var names []string
+	for _, p := range policies {
+		res, err := apply(s, p, now, dryRun)
+		if err != nil {
+			return results, 0, fmt.Errorf("policy %s: %w", p.Name, err)
+		}
+		results = append(results, res)
+		names = append(names, p.Name)
+	}
+	if dryRun {
+		due, err := s.CountRetainedDue(names, now)
+		for _, res := range results {
+			due += int64(res.Due)
+		}
+		return results, due, err
+	}
+	purged, err := s.PurgeRetained(names, now)
+	if err != nil {
+		return results, 0, fmt.Errorf("failed to delete expired memories: %w", err)
+	}
+	return results, purged, nil
+}
+
+func apply(s *store.Store, p Policy, now time.Time, dryRun bool) (Result, error) {
+	res := Result{Policy: p}
+	filter := store.DeleteFilter{Types: p.Types, Topic: p.Topic, Source: p.Source}
+	if p.Project != "" {
+		project, err := s.GetProjectByName(p.Project)
+		if err != nil {
+			return res, err
+		}
+		if project == nil {
+			res.Warning = fmt.Sprintf("no project named %q", p.Project)
+			return res, nil
+		}
+		filter.ProjectID = &project.ID
+	}
+
+	memories, err := s.MatchMemories(filter)
+	if err != nil {
+		return res, err
+	}
+	res.Matched = len(memories)
+	for _, m := range memories {
+		expiry := p.ExpiryFor(m.CreatedAt)
+		if m.ExpiresAt != nil && !m.ExpiresAt.After(expiry) {
+			// Already expires as soon or sooner, by a TTL or this policy
+			continue
+		}
+		if !expiry.After(now) {
+			res.Due++
+		}
+		if dryRun {
+			res.Scheduled++
+			continue
+		}
+		changed, err := s.ApplyRetention(m.ID, p.Name, expiry)
+		if err != nil {
+			return res, err
+		}
+		if changed {
+			res.Scheduled++
+		}
+	}
+	return res, nil
+}
+
+func validSource(t models.SourceType) bool {
+	switch t {
+	case models.SourceTypeGit, models.SourceTypeFile, models.SourceTypeTerminal,
+		models.SourceTypeChat, models.SourceTypeManual, models.SourceTypeImport,
+		models.SourceTypeDocument:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package review
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/guard"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// DefaultThreshold is the minimum similarity for a memory to be relevant.
+// It is lower than the guard's: surfacing context is cheaper than blocking.
+const DefaultThreshold = 0.6
+
+// maxFindings caps the size of a review comment
+const maxFindings = 10
+
+// reviewTypes are the memory types worth raising in code review
+var reviewTypes = []models.MemoryType{
+	models.MemoryTypeDecision,
+	models.MemoryTypePattern,
+	models.MemoryTypeMistake,
+}
+
+// Finding is a memory relevant to one or more files in a diff
+type Finding struct {
+	Memory     models.Memory `json:"memory"`
+	Files      []string      `json:"files"`
+	Similarity float32       `json:"similarity,omitempty"`
+}
+
+// Relevant finds decisions, patterns and known mistakes related to the
+// changed files. Memories mentioning a changed file always match; with an
+// embedder, memories semantically similar to a file's changes match too.
+// emb may be nil for keyword-only matching.
+func Relevant(s *store.Store, emb embedding.Embedder, chunks []guard.Chunk, threshold float32) ([]Finding, error) {
+	c := newCollector()
+
+	for _, chunk := range chunks {
+		mentions, err := s.GetMemoriesReferencingFile(chunk.File, chunk.File, maxFindings)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range mentions {
+			c.add(m, chunk.File, 1)
+		}
+
+		if emb == nil {
+			continue
+		}
+		vec, err := emb.Embed(chunk.Diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed %s: %w", chunk.File, err)
+		}
+		similar, err := s.SimilarMemories(vec, reviewTypes, threshold, 5)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range similar {
+			c.add(m.Memory, chunk.File, m.Similarity)
+		}
+	}
+
+	return c.findings(), nil
+}
+
+// RelevantRemote finds memories mentioning the changed files on a remote
+// team server. Semantic matching isn't available remotely.
+func RelevantRemote(client *teamsync.Client, chunks []guard.Chunk) ([]Finding, error) {
+	c := newCollector()
+
+	for _, chunk := range chunks {
+		mentions, err := client.Recall(models.RecallRequest{
+			Query: chunk.File,
+			Types: reviewTypes,
+			Limit: maxFindings,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range mentions {
+			c.add(m, chunk.File, 1)
+		}
+	}
+
+	return c.findings(), nil
+}
+
+// collector merges matches for the same memory across files
+type collector struct {
+	byID map[string]*Finding
+}
+
+func newCollector() *collector {
+	return &collector{byID: make(map[string]*Finding)}
+}
+
+func (c *collector) add(m models.Memory, file string, similarity float32) {
+	if !isReviewType(m.Type) {
+		return
+	}
+	f, ok := c.byID[m.ID]
+	if !ok {
+		f = &Finding{Memory: m}
+		c.byID[m.ID] = f
+	}
+	if !slices.Contains(f.Files, file) {
+		f.Files = append(f.Files, file)
+	}
+	if similarity > f.Similarity {
+		f.Similarity = similarity
+	}
+}
+
+// findings returns the strongest matches first
+func (c *collector) findings() []Finding {
+	findings := make([]Finding, 0, len(c.byID))
+	for _, f := range c.byID {
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Similarity != findings[j].Similarity {
+			return findings[i].Similarity > findings[j].Similarity
+		}
+		return findings[i].Memory.Importance > findings[j].Memory.Importance
+	})
+	if len(findings) > maxFindings {
+		findings = findings[:maxFindings]
+	}
+	return findings
+}
+
+// Comment renders findings as a pull request comment body
+func Comment(findings []Finding) string {
+	var sb strings.Builder
+	sb.WriteString("### 🧠 MemoryPilot context\n\n")
+	if len(findings) == 0 {
+		sb.WriteString("No recorded decisions or patterns relate to this change.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("Recorded team knowledge related to the files in this change:\n\n")
+	for _, f := range findings {
+		sb.WriteString(fmt.Sprintf("- **%s** %s\n", f.Memory.Type, strings.Join(strings.Fields(f.Memory.Content), " ")))
+		sb.WriteString(fmt.Sprintf("  <sub>%s · %s · `%s`</sub>\n", strings.Join(f.Files, ", "), f.Memory.CreatedAt.Format("2006-01-02"), f.Memory.ID))
+	}
+	return sb.String()
+}
+
+func isReviewType(t models.MemoryType) bool {
+	for _, rt := range reviewTypes {
+		if t == rt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+// Package scopes resolves conflicts between recalled memories of different
+// scopes. The narrower scope wins: personal overrides project, which
+// overrides team, which overrides org, so a personal preference beats the
+// team default it disagrees with.
+package scopes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Mode is how conflicts are handled
+type Mode string
+
+const (
+	ModeAnnotate Mode = "annotate" // keep both, marking the overridden memory
+	ModeStrict   Mode = "strict"   // drop overridden memories
+	ModeOff      Mode = "off"      // don't look for conflicts
+)
+
+// ParseMode validates a mode name
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeAnnotate, ModeStrict, ModeOff:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown scope conflict mode %q (annotate|strict|off)", s)
+}
+
+const (
+	// sameSubject is the embedding similarity above which two memories of
+	// the same type are taken to be about the same thing
+	sameSubject = 0.8
+
+	// sameTopics is the topic overlap (Jaccard) used instead when either
+	// memory has no embedding
+	sameTopics = 0.5
+)
+
+// Precedence ranks a scope; lower wins conflicts
+func Precedence(s models.MemoryScope) int {
+	switch s {
+	case models.MemoryScopePersonal:
+		return 0
+	case models.MemoryScopeProject:
+		return 1
+	case models.MemoryScopeTeam:
+		return 2
+	case models.MemoryScopeOrg:
+		return 3
+	}
+	return 4
+}
+
+// Candidate is a recalled memory and its embedding, if known
+type Candidate struct {
+	Memory    models.Memory
+	Embedding []float32
+}
+
+// Resolution is the outcome for one recalled memory
+type Resolution struct {
+	Memory models.Memory
+
+	// OverriddenBy is the narrower-scoped memory this one conflicts with
+	OverriddenBy *models.Memory
+
+	// Overrides lists wider-scoped memories this one conflicts with
+	Overrides []models.Memory
+}
+
+// Resolve finds conflicting memories among the candidates, keeping their
+// order. In strict mode overridden memories are left out.
+func Resolve(candidates []Candidate, mode Mode) []Resolution {
+	results := make([]Resolution, len(candidates))
+	for i, c := range candidates {
+		results[i].Memory = c.Memory
+	}
+	if mode == ModeOff {
+		return results
+	}
+
+	for i := range candidates {
+		for j := range candidates {
+			a, b := candidates[i], candidates[j]
+			if Precedence(a.Memory.Scope) >= Precedence(b.Memory.Scope) || !conflict(a, b) {
+				continue
+			}
+			// a is narrower: it overrides b, unless b already has a
+			// narrower (or earlier, equally narrow) winner
+			if w := results[j].OverriddenBy; w == nil || Precedence(a.Memory.Scope) < Precedence(w.Scope) {
+				results[j].OverriddenBy = &candidates[i].Memory
+			}
+			results[i].Overrides = append(results[i].Overrides, b.Memory)
+		}
+	}
+
+	if mode != ModeStrict {
+		return results
+	}
+	kept := results[:0]
+	for _, r := range results {
+		if r.OverriddenBy == nil {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// conflict reports whether two memories of different scopes say different
+// things about the same subject
+func conflict(a, b Candidate) bool {
+	if a.Memory.Type != b.Memory.Type || a.Memory.Scope == b.Memory.Scope {
+		return false
+	}
+	if normalize(a.Memory.Content) == normalize(b.Memory.Content) {
+		return false // the same memory shared at both scopes agrees with itself
+	}
+	if len(a.Embedding) > 0 && len(a.Embedding) == len(b.Embedding) {
+		return embedding.CosineSimilarity(a.Embedding, b.Embedding) >= sameSubject
+	}
+	return topicOverlap(a.Memory.Topics, b.Memory.Topics) >= sameTopics
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// topicOverlap is the Jaccard similarity of two topic lists
+func topicOverlap(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, t := range a {
+		set[strings.ToLower(t)] = true
+	}
+	union := len(set)
+	shared := 0
+	seen := make(map[string]bool, len(b))
+	for _, t := range b {
+		t = strings.ToLower(t)
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if set[t] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// DefaultTTL is how long a snapshot memory lives unless overridden
+const DefaultTTL = 48 * time.Hour
+
+// maxTODOs caps the TODO lines included in a snapshot
+const maxTODOs = 10
+
+// Snapshot is the working state of a repository at a point in time
+type Snapshot struct {
+	Repo         string    `json:"repo"`
+	Branch       string    `json:"branch"`
+	DirtyFiles   []string  `json:"dirtyFiles"`
+	TODOs        []string  `json:"todos"`
+	FailingTests []string  `json:"failingTests,omitempty"`
+	Note         string    `json:"note,omitempty"`
+	TakenAt      time.Time `json:"takenAt"`
+}
+
+// Capture records the working state of the git repository containing dir
+func Capture(dir string) (*Snapshot, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository", dir)
+	}
+
+	snap := &Snapshot{
+		Repo:    root,
+		TakenAt: time.Now(),
+	}
+
+	snap.Branch, _ = git(root, "rev-parse", "--abbrev-ref", "HEAD")
+
+	status, _ := git(root, "status", "--porcelain")
+	for _, line := range lines(status) {
+		if len(line) > 3 {
+			snap.DirtyFiles = append(snap.DirtyFiles, strings.TrimSpace(line[3:]))
+		}
+	}
+
+	snap.TODOs = touchedTODOs(root)
+
+	return snap, nil
+}
+
+// touchedTODOs returns TODO/FIXME lines added in uncommitted changes or in
+// today's commits
+func touchedTODOs(root string) []string {
+	var diffs []string
+	if d, err := git(root, "diff", "HEAD", "-U0"); err == nil {
+		diffs = append(diffs, d)
+	}
+	y, m, d := time.Now().Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+	if d, err := git(root, "log", "--since="+midnight, "-p", "-U0", "--format="); err == nil {
+		diffs = append(diffs, d)
+	}
+
+	var todos []string
+	seen := make(map[string]bool)
+	file := ""
+	for _, diff := range diffs {
+		for _, line := range lines(diff) {
+			if strings.HasPrefix(line, "+++ ") {
+				file = strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+				continue
+			}
+			if !strings.HasPrefix(line, "+") {
+				continue
+			}
+			text := strings.TrimSpace(line[1:])
+			if !strings.Contains(text, "TODO") && !strings.Contains(text, "FIXME") {
+				continue
+			}
+			entry := fmt.Sprintf("%s: %s", file, text)
+			if seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			todos = append(todos, entry)
+			if len(todos) >= maxTODOs {
+				return todos
+			}
+		}
+	}
+	return todos
+}
+
+// Summary renders a one-line description of the snapshot
+func (s *Snapshot) Summary() string {
+	return fmt.Sprintf("WIP on %s@%s: %d dirty files", filepath.Base(s.Repo), s.Branch, len(s.DirtyFiles))
+}
+
+// Content renders the snapshot as memory content
+func (s *Snapshot) Content() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Work in progress in %s on branch %s (as of %s).\n",
+		s.Repo, s.Branch, s.TakenAt.Format("2006-01-02 15:04")))
+	if s.Note != "" {
+		sb.WriteString(fmt.Sprintf("Note: %s\n", s.Note))
+	}
+	if len(s.DirtyFiles) > 0 {
+		sb.WriteString(fmt.Sprintf("Uncommitted files: %s\n", strings.Join(s.DirtyFiles, ", ")))
+	}
+	if len(s.FailingTests) > 0 {
+		sb.WriteString(fmt.Sprintf("Failing tests: %s\n", strings.Join(s.FailingTests, ", ")))
+	}
+	if len(s.TODOs) > 0 {
+		sb.WriteString("TODOs touched today:\n")
+		for _, todo := range s.TODOs {
+			sb.WriteString("  - " + todo + "\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Memory converts the snapshot to a context memory that expires after ttl
+func (s *Snapshot) Memory(ttl time.Duration) models.Memory {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	expires := s.TakenAt.Add(ttl)
+
+	return models.Memory{
+		ID:      ulid.Make().String(),
+		Type:    models.MemoryTypeContext,
+		Content: s.Content(),
+		Summary: s.Summary(),
+		Scope:   models.MemoryScopePersonal,
+		Source: models.Source{
+			Type:      models.SourceTypeManual,
+			Reference: s.Repo,
+			Timestamp: s.TakenAt,
+		},
+		Confidence:     1.0,
+		Importance:     1.0,
+		Topics:         []string{"snapshot", "wip", filepath.Base(s.Repo), s.Branch},
+		CreatedAt:      s.TakenAt,
+		LastAccessedAt: s.TakenAt,
+		ExpiresAt:      &expires,
+	}
+}
+
+func git(dir string, args ...string) (string, error) {
+	output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimRight(string(output), "\n"), err
+}
+
+func lines(s string) []string {
+	var result []string
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		result = append(result, scanner.Text())
+	}
+	return result
+}
@@ -0,0 +1,245 @@
+package standup
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/extractor"
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// generalProject groups activity that can't be tied to a repository
+const generalProject = "general"
+
+// Activity is the raw material for a standup, grouped by project
+type Activity struct {
+	Since    time.Time
+	Until    time.Time
+	Events   map[string][]models.Event
+	Memories map[string][]models.Memory
+}
+
+// ProjectReport is the standup section for one project
+type ProjectReport struct {
+	Name    string   `json:"name"`
+	Did     []string `json:"did"`
+	Learned []string `json:"learned"`
+	Blocked []string `json:"blocked"`
+}
+
+// Report is a complete standup
+type Report struct {
+	Since    time.Time       `json:"since"`
+	Until    time.Time       `json:"until"`
+	Projects []ProjectReport `json:"projects"`
+}
+
+// PreviousWorkday returns the start and end of the last working day before
+// now (Friday when run on a Monday)
+func PreviousWorkday(now time.Time) (time.Time, time.Time) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	start := today.AddDate(0, 0, -1)
+	for start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Collect gathers events and new memories in [since, until), by project
+func Collect(s *store.Store, since, until time.Time) (*Activity, error) {
+	events, err := s.GetEventsBetween(since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events: %w", err)
+	}
+	memories, err := s.GetMemoriesCreatedBetween(since, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+
+	a := &Activity{
+		Since:    since,
+		Until:    until,
+		Events:   make(map[string][]models.Event),
+		Memories: make(map[string][]models.Memory),
+	}
+	for _, e := range events {
+		name := eventProject(e)
+		a.Events[name] = append(a.Events[name], e)
+	}
+	for _, m := range memories {
+		if m.Type == models.MemoryTypeContext {
+			continue
+		}
+		name := generalProject
+		if m.Source.Type == models.SourceTypeGit && strings.HasPrefix(m.Source.Reference, "/") {
+			name = filepath.Base(m.Source.Reference)
+		}
+		a.Memories[name] = append(a.Memories[name], m)
+	}
+	return a, nil
+}
+
+// Empty reports whether there was no activity at all
+func (a *Activity) Empty() bool {
+	return len(a.Events) == 0 && len(a.Memories) == 0
+}
+
+// projects returns every project with activity, sorted
+func (a *Activity) projects() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range a.Events {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range a.Memories {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// eventProject attributes an event to a repository by name
+func eventProject(e models.Event) string {
+	for _, key := range []string{"repo", "cwd"} {
+		if path, ok := e.Data[key].(string); ok && path != "" {
+			return filepath.Base(path)
+		}
+	}
+	return generalProject
+}
+
+const standupPrompt = `You are writing a developer's daily standup from their activity log.
+
+For each project below, write:
+- did: what was accomplished (1-4 short bullet points, past tense, no hashes)
+- learned: notable lessons or decisions (0-3 bullets, from the memories)
+- blocked: anything that looks stuck, e.g. repeated failing builds or reverted work (0-2 bullets)
+
+Be concise and concrete. Merge related commits into one bullet. Skip projects with nothing meaningful.
+
+Activity:
+%s
+
+Respond ONLY with valid JSON in this exact format (no markdown, no explanation):
+{"projects": [{"name": "project", "did": ["..."], "learned": ["..."], "blocked": []}]}`
+
+// Summarize asks the LLM to turn the activity into a standup report
+func Summarize(c extractor.Completer, a *Activity) (*Report, error) {
+	response, err := c.CompleteJSON(fmt.Sprintf(standupPrompt, a.describe()))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Since: a.Since, Until: a.Until}
+	if err := extractor.ParseJSON(response, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Fallback builds a plain report straight from the activity, for when no LLM
+// is available: commits become "did" items and new memories "learned" items
+func Fallback(a *Activity) *Report {
+	report := &Report{Since: a.Since, Until: a.Until}
+
+	for _, name := range a.projects() {
+		p := ProjectReport{Name: name}
+		for _, e := range a.Events[name] {
+			if e.Type == "git_commit" {
+				if msg, ok := e.Data["message"].(string); ok {
+					p.Did = append(p.Did, msg)
+				}
+			}
+		}
+		for _, m := range a.Memories[name] {
+			if m.Type == models.MemoryTypeMistake {
+				p.Blocked = append(p.Blocked, m.Summary)
+			} else {
+				p.Learned = append(p.Learned, m.Summary)
+			}
+		}
+		if len(p.Did)+len(p.Learned)+len(p.Blocked) > 0 {
+			report.Projects = append(report.Projects, p)
+		}
+	}
+	return report
+}
+
+// describe renders the activity for the LLM prompt
+func (a *Activity) describe() string {
+	var sb strings.Builder
+	for _, name := range a.projects() {
+		sb.WriteString(fmt.Sprintf("## %s\n", name))
+		for _, e := range a.Events[name] {
+			switch e.Type {
+			case "git_commit":
+				sb.WriteString(fmt.Sprintf("- commit: %v\n", e.Data["message"]))
+			case "git_tag":
+				sb.WriteString(fmt.Sprintf("- tagged: %v\n", e.Data["tag"]))
+			case "terminal_cmd":
+				sb.WriteString(fmt.Sprintf("- ran: %v\n", e.Data["command"]))
+			case "build_fix":
+				sb.WriteString(fmt.Sprintf("- fixed failing %v\n", e.Data["failedCommands"]))
+			}
+		}
+		for _, m := range a.Memories[name] {
+			sb.WriteString(fmt.Sprintf("- memory [%s]: %s\n", m.Type, m.Summary))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Markdown renders the report as Markdown
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Standup — %s\n", r.Since.Format("Mon Jan 2")))
+	for _, p := range r.Projects {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.Name))
+		writeSection(&sb, "**What I did**", "- ", p.Did)
+		writeSection(&sb, "**What I learned**", "- ", p.Learned)
+		writeSection(&sb, "**Blocked**", "- ", p.Blocked)
+	}
+	if len(r.Projects) == 0 {
+		sb.WriteString("\nNo activity captured.\n")
+	}
+	return sb.String()
+}
+
+// Slack renders the report using Slack's mrkdwn flavour
+func (r *Report) Slack() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*Standup — %s*\n", r.Since.Format("Mon Jan 2")))
+	for _, p := range r.Projects {
+		sb.WriteString(fmt.Sprintf("\n*%s*\n", p.Name))
+		writeSection(&sb, "_What I did_", "• ", p.Did)
+		writeSection(&sb, "_What I learned_", "• ", p.Learned)
+		writeSection(&sb, "_Blocked_", "• ", p.Blocked)
+	}
+	if len(r.Projects) == 0 {
+		sb.WriteString("\nNo activity captured.\n")
+	}
+	return sb.String()
+}
+
+func writeSection(sb *strings.Builder, title, bullet string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(title + "\n")
+	for _, item := range items {
+		sb.WriteString(bullet + item + "\n")
+	}
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// TypeStat summarizes auto-extracted memories of one type across review
+// states
+type TypeStat struct {
+	Type          string  `json:"type"`
+	Approved      int     `json:"approved"`
+	Pending       int     `json:"pending"`
+	Rejected      int     `json:"rejected"`
+	AvgConfidence float64 `json:"avgConfidence"`
+}
+
+// ExtractedTypeStats returns per-type statistics for memories produced by
+// extraction and mining, leaving out manual entries and imports
+func (s *Store) ExtractedTypeStats() ([]TypeStat, error) {
+	rows, err := s.db.Query(`
+		SELECT type,
+			SUM(CASE WHEN status = 'approved' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END),
+			SUM(CASE WHEN status = 'rejected' THEN 1 ELSE 0 END),
+			AVG(confidence)
+		FROM memories
+		WHERE source_type NOT IN ('manual', 'import') AND team_id IS NULL
+		GROUP BY type
+		ORDER BY type
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []TypeStat
+	for rows.Next() {
+		var t TypeStat
+		if err := rows.Scan(&t.Type, &t.Approved, &t.Pending, &t.Rejected, &t.AvgConfidence); err != nil {
+			return nil, err
+		}
+		stats = append(stats, t)
+	}
+	return stats, rows.Err()
+}
+
+// EmbeddedMemory is a memory together with its embedding
+type EmbeddedMemory struct {
+	models.Memory
+	Embedding []float32 `json:"-"`
+}
+
+// EmbeddedMemories returns every approved, unexpired memory that has an
+// embedding, for analyses over the whole memory space
+func (s *Store) EmbeddedMemories() ([]EmbeddedMemory, error) {
+	rows, err := s.db.Query(`SELECT `+memoryColumns+`, embedding FROM memories
+		WHERE embedding IS NOT NULL AND `+unexpired+` AND `+approved+`
+		ORDER BY id`, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []EmbeddedMemory
+	for rows.Next() {
+		var blob []byte
+		m, err := scanMemory(extraScanner{rows, []interface{}{&blob}})
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) == 0 {
+			continue
+		}
+		memories = append(memories, EmbeddedMemory{Memory: m, Embedding: decodeEmbedding(blob)})
+	}
+	return memories, rows.Err()
+}
+
+// DayActivity counts what was captured and learned for one project on one
+// day. ProjectID is empty for activity outside any known project.
+type DayActivity struct {
+	ProjectID   string `json:"projectId"`
+	ProjectName string `json:"projectName"`
+	Day         string `json:"day"` // YYYY-MM-DD
+	Events      int    `json:"events"`
+	Sampled     int    `json:"sampled"` // events left out of extraction by sampling
+	Memories    int    `json:"memories"`
+}
+
+// DailyActivity returns per-project event and memory counts for each day
+// since the given time, ordered by project and day. Team memories are left
+// out: they were learned elsewhere.
+func (s *Store) DailyActivity(since time.Time) ([]DayActivity, error) {
+	rows, err := s.db.Query(`
+		SELECT IFNULL(a.project_id, ''), IFNULL(p.name, ''), a.day, SUM(a.events), SUM(a.sampled), SUM(a.memories)
+		FROM (
+			SELECT project_id, substr(timestamp, 1, 10) AS day, SUM(repeat_count) AS events,
+				SUM(CASE WHEN sampled THEN repeat_count ELSE 0 END) AS sampled, 0 AS memories
+			FROM events WHERE timestamp >= ?
+			GROUP BY project_id, day
+			UNION ALL
+			SELECT project_id, substr(created_at, 1, 10) AS day, 0, 0, COUNT(*)
+			FROM memories WHERE created_at >= ? AND team_id IS NULL
+			GROUP BY project_id, day
+		) a
+		LEFT JOIN projects p ON p.id = a.project_id
+		GROUP BY a.project_id, a.day
+		ORDER BY IFNULL(p.name, ''), a.day
+	`, since, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activity []DayActivity
+	for rows.Next() {
+		var d DayActivity
+		if err := rows.Scan(&d.ProjectID, &d.ProjectName, &d.Day, &d.Events, &d.Sampled, &d.Memories); err != nil {
+			return nil, err
+		}
+		activity = append(activity, d)
+	}
+	return activity, rows.Err()
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// BackupDir is where backups are kept, relative to the database
+	BackupDir = "backups"
+
+	// keepBackups is how many backups are retained
+	keepBackups = 7
+
+	backupPrefix = "memories-"
+	backupSuffix = ".db"
+)
+
+// Backup writes a consistent copy of the database to the backup directory
+// next to it, pruning all but the most recent backups
+func (s *Store) Backup() (string, error) {
+	if s.path == MemoryPath {
+		return "", errors.New("in-memory databases can't be backed up")
+	}
+	dir := filepath.Join(filepath.Dir(s.path), BackupDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, backupPrefix+time.Now().Format("20060102-150405")+backupSuffix)
+	if _, err := s.db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return "", err
+	}
+
+	backups, err := ListBackups(s.path)
+	if err != nil {
+		return path, err
+	}
+	for i := keepBackups; i < len(backups); i++ {
+		os.Remove(backups[i])
+	}
+	return path, nil
+}
+
+// LastBackup returns when the newest backup was taken, or the zero time
+func (s *Store) LastBackup() time.Time {
+	backups, err := ListBackups(s.path)
+	if err != nil || len(backups) == 0 {
+		return time.Time{}
+	}
+	info, err := os.Stat(backups[0])
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// ListBackups returns the backups of the database at dbPath, newest first
+func ListBackups(dbPath string) ([]string, error) {
+	dir := filepath.Join(filepath.Dir(dbPath), BackupDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	// Timestamped names sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
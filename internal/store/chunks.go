@@ -0,0 +1,132 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Long content, such as a memory imported from a document or transcript,
+// says more than one embedding captures, and embedding models truncate it.
+// It's split into overlapping chunks, each embedded as a vector of kind
+// "chunk:<start>-<end>" (byte offsets into the content), so a query
+// matching one passage finds the memory and the passage can be shown.
+
+const (
+	// ChunkThreshold is the content length, in bytes, above which content
+	// is chunked
+	ChunkThreshold    = 1200
+	chunkThresholdSQL = "1200" // for queries
+
+	// chunkSize is the most bytes in a chunk
+	chunkSize = 800
+
+	// chunkOverlap is how far each chunk reaches back into the previous
+	// one, so a passage spanning a boundary is whole in one of them
+	chunkOverlap = 150
+)
+
+// Chunk is a passage of a memory's content, by byte offsets
+type Chunk struct {
+	Start, End int
+}
+
+// Kind returns the vector kind the chunk is stored under
+func (c Chunk) Kind() string {
+	return fmt.Sprintf("%s:%d-%d", VectorChunk, c.Start, c.End)
+}
+
+// parseChunk returns the chunk a vector kind names, if it names one
+func parseChunk(kind string) (Chunk, bool) {
+	var c Chunk
+	rest, ok := strings.CutPrefix(kind, VectorChunk+":")
+	if !ok {
+		return c, false
+	}
+	if _, err := fmt.Sscanf(rest, "%d-%d", &c.Start, &c.End); err != nil || c.Start < 0 || c.End <= c.Start {
+		return c, false
+	}
+	return c, true
+}
+
+// Excerpt returns the chunk's passage of content on one line, marked with
+// "…" where it cuts into the text, or "" if content has changed such that
+// it no longer fits
+func (c Chunk) Excerpt(content string) string {
+	if c.End > len(content) {
+		return ""
+	}
+	excerpt := strings.Join(strings.Fields(content[c.Start:c.End]), " ")
+	if c.Start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if c.End < len(content) {
+		excerpt += "…"
+	}
+	return excerpt
+}
+
+// Chunks splits content longer than ChunkThreshold into overlapping
+// passages, preferring to break between paragraphs, then sentences, then
+// words; shorter content isn't chunked
+func Chunks(content string) []Chunk {
+	if len(content) <= ChunkThreshold {
+		return nil
+	}
+	var chunks []Chunk
+	for start := 0; start < len(content); {
+		end := start + chunkSize
+		if end >= len(content) {
+			end = len(content)
+		} else {
+			end = breakBefore(content, start+chunkSize/2, end)
+		}
+		chunks = append(chunks, Chunk{Start: start, End: end})
+		if end == len(content) {
+			break
+		}
+
+		// Step back for the overlap, to the start of a word
+		next := end - chunkOverlap
+		if next <= start {
+			next = end
+		}
+		for next < end && !isSpace(content[next-1]) {
+			next++
+		}
+		for next < end && isSpace(content[next]) {
+			next++
+		}
+		start = next
+	}
+	return chunks
+}
+
+// breakBefore returns the best place to end a chunk in content[min:max]:
+// after a paragraph, a sentence or a word, or else at a rune boundary
+func breakBefore(content string, min, max int) int {
+	window := content[min:max]
+	if i := strings.LastIndex(window, "\n\n"); i >= 0 {
+		return min + i + 2
+	}
+	best := -1
+	for _, sep := range []string{". ", "? ", "! ", ".\n", "\n"} {
+		if i := strings.LastIndex(window, sep); i >= 0 && min+i+len(sep) > best {
+			best = min + i + len(sep)
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	if i := strings.LastIndexAny(window, " \t"); i >= 0 {
+		return min + i + 1
+	}
+	for max > min && !utf8.RuneStart(content[max]) {
+		max--
+	}
+	return max
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Event payloads (file contents, diffs) dominate the database, so large
+// ones are compressed with zstd. Each row records its encoding and
+// original size; rows without an encoding are plain JSON text, as written
+// by older versions, and gzip rows from versions before zstd still decode.
+const (
+	// compressThreshold is the payload size, in bytes, above which event
+	// data is compressed
+	compressThreshold = 4096
+
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll and
+// DecodeAll calls; without options, creating them can't fail
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// encodeEventData returns the stored form of an event payload, its
+// encoding (nil for plain JSON) and its original size
+func encodeEventData(data []byte) (stored, encoding interface{}, size int) {
+	if len(data) < compressThreshold {
+		return string(data), nil, len(data)
+	}
+
+	compressed := zstdEncoder.EncodeAll(data, nil)
+	if len(compressed) >= len(data) {
+		return string(data), nil, len(data)
+	}
+	return compressed, encodingZstd, len(data)
+}
+
+// decodeEventData reverses encodeEventData
+func decodeEventData(stored []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return stored, nil
+	case encodingZstd:
+		return zstdDecoder.DecodeAll(stored, nil)
+	case encodingGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(stored))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	default:
+		return nil, fmt.Errorf("unknown event data encoding %q", encoding)
+	}
+}
@@ -0,0 +1,64 @@
+package store_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/fixtures"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Several processes (the agent, MCP servers, the CLI) open the same
+// database; each must be able to remember and recall while the others do
+func TestConcurrentRememberRecall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memories.db")
+	const handles, perHandle = 4, 10
+
+	stores := make([]*store.Store, handles)
+	for i := range stores {
+		s, err := store.New(path)
+		if err != nil {
+			t.Fatalf("open store %d: %v", i, err)
+		}
+		defer s.Close()
+		stores[i] = s
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, handles*perHandle*2)
+	for i, s := range stores {
+		wg.Add(1)
+		go func(i int, s *store.Store) {
+			defer wg.Done()
+			for j := 0; j < perHandle; j++ {
+				m := fixtures.NewMemory(fmt.Sprintf("handle %d note %d about widgets", i, j)).Build()
+				if err := s.CreateMemory(&m); err != nil {
+					errs <- fmt.Errorf("handle %d remember: %w", i, err)
+					return
+				}
+				if _, err := s.Recall(models.RecallRequest{Query: "widgets", Limit: 5}); err != nil {
+					errs <- fmt.Errorf("handle %d recall: %w", i, err)
+					return
+				}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for i, s := range stores {
+		got, err := s.Recall(models.RecallRequest{Query: "widgets", Limit: handles * perHandle * 2})
+		if err != nil {
+			t.Fatalf("handle %d final recall: %v", i, err)
+		}
+		if len(got) != handles*perHandle {
+			t.Errorf("handle %d recalled %d memories, want %d", i, len(got), handles*perHandle)
+		}
+	}
+}
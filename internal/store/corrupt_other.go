@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package store
+
+import "strings"
+
+// sqliteCorrupt reports whether err is SQLITE_CORRUPT or SQLITE_NOTADB.
+// The driver's typed errors only exist in cgo builds, so this matches
+// SQLite's messages for them.
+func sqliteCorrupt(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database disk image is malformed") ||
+		strings.Contains(msg, "file is not a database")
+}
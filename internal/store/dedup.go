@@ -0,0 +1,97 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// NearDuplicate returns the approved memory of m's project and type that m
+// most likely repeats: one with the same summary, or, when vec is set, the
+// one most similar to it by embedding with at least minSimilarity. It
+// returns nil if there is none.
+func (s *Store) NearDuplicate(m *models.Memory, vec []float32, minSimilarity float32) (*ScoredMemory, error) {
+	if summary := strings.TrimSpace(m.Summary); summary != "" {
+		var id string
+		err := s.db.QueryRow(`
+			SELECT id FROM memories
+			WHERE IFNULL(project_id, '') = IFNULL(?, '') AND type = ? AND `+approved+`
+				AND `+unexpired+`
+				AND LOWER(TRIM(summary)) = LOWER(?)
+			ORDER BY created_at ASC LIMIT 1
+		`, m.ProjectID, m.Type, time.Now(), summary).Scan(&id)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if err == nil {
+			existing, err := s.GetMemory(id)
+			if err != nil || existing == nil {
+				return nil, err
+			}
+			return &ScoredMemory{Memory: *existing, Similarity: 1}, nil
+		}
+	}
+	if vec == nil {
+		return nil, nil
+	}
+
+	similar, err := s.similarMemories(vec, " AND type = ? AND IFNULL(project_id, '') = IFNULL(?, '')",
+		[]interface{}{m.Type, m.ProjectID}, minSimilarity, 1)
+	if err != nil || len(similar) == 0 {
+		return nil, err
+	}
+	return &similar[0], nil
+}
+
+// MergeNearDuplicate folds a near-duplicate memory into the memory id
+// instead of storing it: topics it adds are merged in, the memory's
+// importance is bumped and its merge counter incremented. A held memory
+// keeps its topics.
+func (s *Store) MergeNearDuplicate(id string, topics []string) error {
+	var topicsJSON sql.NullString
+	if err := s.db.QueryRow(`SELECT topics FROM memories WHERE id = ?`, id).Scan(&topicsJSON); err != nil {
+		return err
+	}
+	var existing []string
+	if topicsJSON.Valid {
+		json.Unmarshal([]byte(topicsJSON.String), &existing)
+	}
+	merged, added := mergeTopics(existing, topics)
+	if added {
+		data, _ := json.Marshal(merged)
+		_, err := s.db.Exec(`UPDATE memories SET topics = ? WHERE id = ?`, string(data), id)
+		if err = heldErr(err); err != nil && !errors.Is(err, ErrHeld) {
+			return err
+		}
+	}
+	_, err := s.db.Exec(`
+		UPDATE memories SET merged_count = merged_count + 1, importance = MIN(1.0, importance + 0.1)
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// mergeTopics appends the topics not already present, ignoring case,
+// reporting whether any were added
+func mergeTopics(existing, topics []string) ([]string, bool) {
+	seen := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		seen[strings.ToLower(t)] = true
+	}
+	merged := existing
+	added := false
+	for _, t := range topics {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		merged = append(merged, t)
+		added = true
+	}
+	return merged, added
+}
@@ -0,0 +1,76 @@
+package store
+
+// Orphans counts rows that reference rows which no longer exist
+type Orphans struct {
+	Memories     int `json:"memories"`     // project_id points at a missing project
+	Events       int `json:"events"`       // project_id points at a missing project
+	ProjectFacts int `json:"projectFacts"` // facts of a missing project
+	Annotations  int `json:"annotations"`  // annotations of a missing memory
+}
+
+// Total returns the number of orphaned rows
+func (o *Orphans) Total() int {
+	return o.Memories + o.Events + o.ProjectFacts + o.Annotations
+}
+
+// orphanChecks pairs each kind of orphan with the query that counts it and
+// the statement that repairs it. Memories and events are detached from the
+// missing project rather than deleted; memories under legal hold are left
+// as they are.
+var orphanChecks = []struct {
+	count, fix string
+	field      func(*Orphans) *int
+}{
+	{
+		`SELECT COUNT(*) FROM memories WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM projects) AND ` + notHeld,
+		`UPDATE memories SET project_id = NULL WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM projects) AND ` + notHeld,
+		func(o *Orphans) *int { return &o.Memories },
+	},
+	{
+		`SELECT COUNT(*) FROM events WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM projects)`,
+		`UPDATE events SET project_id = NULL WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM projects)`,
+		func(o *Orphans) *int { return &o.Events },
+	},
+	{
+		`SELECT COUNT(*) FROM project_facts WHERE project_id NOT IN (SELECT id FROM projects)`,
+		`DELETE FROM project_facts WHERE project_id NOT IN (SELECT id FROM projects)`,
+		func(o *Orphans) *int { return &o.ProjectFacts },
+	},
+	{
+		`SELECT COUNT(*) FROM annotations WHERE memory_id NOT IN (SELECT id FROM memories)`,
+		`DELETE FROM annotations WHERE memory_id NOT IN (SELECT id FROM memories)`,
+		func(o *Orphans) *int { return &o.Annotations },
+	},
+}
+
+// FindOrphans counts dangling references left by older versions, which
+// didn't enforce foreign keys
+func (s *Store) FindOrphans() (*Orphans, error) {
+	o := &Orphans{}
+	for _, check := range orphanChecks {
+		if err := s.db.QueryRow(check.count).Scan(check.field(o)); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// FixOrphans repairs dangling references, returning what was repaired
+func (s *Store) FixOrphans() (*Orphans, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	o := &Orphans{}
+	for _, check := range orphanChecks {
+		result, err := tx.Exec(check.fix)
+		if err != nil {
+			return nil, err
+		}
+		n, _ := result.RowsAffected()
+		*check.field(o) = int(n)
+	}
+	return o, tx.Commit()
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// EmbeddingCacheTTL is how long a cached embedding is kept without being
+// used
+const EmbeddingCacheTTL = 90 * 24 * time.Hour
+
+var embeddingMigrations = []string{
+	// Embeddings by hash of model and text (see embedding.Cached), so
+	// identical texts are embedded once
+	`CREATE TABLE IF NOT EXISTS embedding_cache (
+		key TEXT PRIMARY KEY,
+		embedding BLOB NOT NULL,
+		used_at DATETIME NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_embedding_cache_used ON embedding_cache(used_at)`,
+
+	// Embeddings stored before they were labelled still have a known size
+	`UPDATE memories SET embedding_dim = length(embedding) / 4
+		WHERE embedding IS NOT NULL AND embedding_dim IS NULL`,
+}
+
+// SetEmbeddingModel sets the model that embeddings written from now on are
+// labelled with, such as embedding.Chain's Model()
+func (s *Store) SetEmbeddingModel(model string) {
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+	s.embeddingModel = model
+}
+
+// embeddingLabel returns the model and dimension an embedding is stored
+// with, both NULL without an embedding and the model NULL when unknown
+func (s *Store) embeddingLabel(embedding []float32) (model, dim interface{}) {
+	if len(embedding) == 0 {
+		return nil, nil
+	}
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+	return nullString(s.embeddingModel), len(embedding)
+}
+
+// EmbeddingSpace counts the stored embeddings from one model and dimension;
+// Model is "" for embeddings stored before they were labelled
+type EmbeddingSpace struct {
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+	Memories   int    `json:"memories"`
+}
+
+// EmbeddingSpaces counts the embeddings of memories other than rejected
+// ones by model and dimension, largest first
+func (s *Store) EmbeddingSpaces() ([]EmbeddingSpace, error) {
+	rows, err := s.db.Query(`SELECT IFNULL(embedding_model, ''), IFNULL(embedding_dim, 0), COUNT(*) FROM memories
+		WHERE embedding IS NOT NULL AND status != 'rejected'
+		GROUP BY 1, 2 ORDER BY 3 DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spaces []EmbeddingSpace
+	for rows.Next() {
+		var sp EmbeddingSpace
+		if err := rows.Scan(&sp.Model, &sp.Dimensions, &sp.Memories); err != nil {
+			return nil, err
+		}
+		spaces = append(spaces, sp)
+	}
+	return spaces, rows.Err()
+}
+
+// CachedEmbedding returns the embedding cached under key, or nil
+func (s *Store) CachedEmbedding(key string) ([]float32, error) {
+	var blob []byte
+	err := s.db.QueryRow(`SELECT embedding FROM embedding_cache WHERE key = ?`, key).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Marking use is a write, so it's done at most daily per entry
+	now := time.Now()
+	s.db.Exec(`UPDATE embedding_cache SET used_at = ? WHERE key = ? AND used_at < ?`, now, key, now.Add(-24*time.Hour))
+	return decodeEmbedding(blob), nil
+}
+
+// CacheEmbedding caches an embedding under key
+func (s *Store) CacheEmbedding(key string, embedding []float32) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO embedding_cache (key, embedding, used_at) VALUES (?, ?, ?)`,
+		key, encodeEmbedding(embedding), time.Now())
+	return err
+}
+
+// PruneEmbeddingCache drops cached embeddings last used before the given
+// time
+func (s *Store) PruneEmbeddingCache(before time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM embedding_cache WHERE used_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountMemoriesToEmbed returns how many memories, other than rejected
+// ones, have no embedding, or with all, how many there are
+func (s *Store) CountMemoriesToEmbed(all bool) (int, error) {
+	return s.countToEmbed(toEmbed(all))
+}
+
+// MemoriesToEmbed returns up to limit memories without an embedding, or
+// with all any memory, other than rejected ones, in ID order after the
+// given ID ("" to start)
+func (s *Store) MemoriesToEmbed(all bool, after string, limit int) ([]models.Memory, error) {
+	return s.memoriesToEmbed(toEmbed(all), after, limit)
+}
+
+// CountNotEmbeddedWith returns how many memories, other than rejected
+// ones, have no embedding or one from another model than model
+func (s *Store) CountNotEmbeddedWith(model string) (int, error) {
+	return s.countToEmbed(notEmbeddedWith, model)
+}
+
+// NotEmbeddedWith returns up to limit memories, other than rejected ones,
+// without an embedding from model, in ID order after the given ID ("" to
+// start)
+func (s *Store) NotEmbeddedWith(model, after string, limit int) ([]models.Memory, error) {
+	return s.memoriesToEmbed(notEmbeddedWith, after, limit, model)
+}
+
+const notEmbeddedWith = `(embedding IS NULL OR embedding_model IS NOT ?) AND status != 'rejected'`
+
+func (s *Store) countToEmbed(cond string, args ...interface{}) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE `+cond, args...).Scan(&n)
+	return n, err
+}
+
+func (s *Store) memoriesToEmbed(cond, after string, limit int, args ...interface{}) ([]models.Memory, error) {
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories
+		WHERE `+cond+` AND id > ? ORDER BY id LIMIT ?`, append(args, after, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+func toEmbed(all bool) string {
+	if all {
+		return `status != 'rejected'`
+	}
+	return `embedding IS NULL AND status != 'rejected'`
+}
+
+// embeddingModelKey records the embedding model last used, in sync_state
+const embeddingModelKey = "embedding:model"
+
+// EmbeddingModel returns the embedding model recorded with
+// RecordEmbeddingModel and when it was recorded, or "" if none was
+func (s *Store) EmbeddingModel() (string, time.Time, error) {
+	model, at, _, err := s.GetSyncState(embeddingModelKey)
+	return model, at, err
+}
+
+// RecordEmbeddingModel records the model new embeddings come from,
+// returning the previously recorded one if it was different ("" if it's
+// unchanged or none was recorded)
+func (s *Store) RecordEmbeddingModel(model string) (string, error) {
+	previous, _, err := s.EmbeddingModel()
+	if err != nil || previous == model {
+		return "", err
+	}
+	return previous, s.SetSyncState(embeddingModelKey, model)
+}
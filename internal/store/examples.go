@@ -0,0 +1,56 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// MinExampleConfidence is the confidence a memory needs to be shown to
+	// the extractor as a few-shot example
+	MinExampleConfidence = 0.8
+
+	// examplesPerType keeps examples varied so they teach classification
+	// rather than one dominant type
+	examplesPerType = 2
+)
+
+// FewShotExamples returns the user's accepted high-confidence memories from
+// the given source, at most two per memory type, best first. types
+// optionally restricts the memory types considered.
+func (s *Store) FewShotExamples(source models.SourceType, types []models.MemoryType, limit int) ([]models.Memory, error) {
+	filter := `status = 'approved' AND scope IN ('personal', 'project') AND source_type = ? AND confidence >= ?`
+	args := []interface{}{source, MinExampleConfidence}
+	if len(types) > 0 {
+		filter += ` AND type IN (?` + strings.Repeat(`, ?`, len(types)-1) + `)`
+		for _, t := range types {
+			args = append(args, t)
+		}
+	}
+	args = append(args, examplesPerType, limit)
+
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (
+				PARTITION BY type ORDER BY confidence * importance DESC, access_count DESC
+			) AS type_rank
+			FROM memories WHERE `+filter+`
+		)
+		WHERE type_rank <= ?
+		ORDER BY confidence * importance DESC, access_count DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, nil
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// unexpired keeps memories whose expiry, if any, is after the bound time
+const unexpired = `(expires_at IS NULL OR expires_at > ?)`
+
+// ArchiveExpired archives approved memories that expired by now, keeping
+// them for 'memorypilot show' and export but out of recall. Held memories
+// are left alone.
+func (s *Store) ArchiveExpired(now time.Time) (int64, error) {
+	result, err := s.db.Exec(`UPDATE memories SET status = ?
+		WHERE expires_at <= ? AND `+approved+` AND `+notHeld,
+		models.MemoryStatusArchived, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteExpired deletes memories that expired by now, in any review state.
+// Held memories are left alone.
+func (s *Store) DeleteExpired(now time.Time) (int64, error) {
+	return s.deleteMemories(`expires_at <= ?`, []interface{}{now})
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ExportMemories returns every memory, in any review state, with its
+// embedding, oldest first
+func (s *Store) ExportMemories() ([]models.Memory, error) {
+	rows, err := s.db.Query(`SELECT ` + memoryColumns + `, embedding FROM memories ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		var blob []byte
+		m, err := scanMemory(extraScanner{rows, []interface{}{&blob}})
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			m.Embedding = decodeEmbedding(blob)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// ImportProject adds an exported project, returning the ID its memories
+// should use here: its own, or that of the local project at the same path
+func (s *Store) ImportProject(p *models.Project) (string, error) {
+	existing, err := s.GetProjectByPath(p.Path)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return existing.ID, nil
+	}
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM projects WHERE id = ?`, p.ID).Scan(&n); err != nil {
+		return "", err
+	}
+	if n > 0 {
+		// The same project, moved on this machine
+		return p.ID, nil
+	}
+	return p.ID, s.CreateProject(p)
+}
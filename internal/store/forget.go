@@ -0,0 +1,130 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ErrEmptyFilter is returned by DeleteByFilter for a filter that would
+// match every memory
+var ErrEmptyFilter = errors.New("refusing to delete with an empty filter")
+
+// DeleteFilter selects memories to delete, in any review state. Every set
+// field must match.
+type DeleteFilter struct {
+	Types     []models.MemoryType
+	ProjectID *string
+	Topic     string            // memories with this topic (case-insensitive)
+	Source    models.SourceType // memories from this kind of source
+	Before    time.Time         // memories created before this time
+	Query     string            // memories whose content, summary or topics contain this text
+}
+
+// IsEmpty reports whether the filter sets no condition
+func (f DeleteFilter) IsEmpty() bool {
+	return len(f.Types) == 0 && f.ProjectID == nil && f.Topic == "" && f.Source == "" && f.Before.IsZero() && f.Query == ""
+}
+
+func (f DeleteFilter) where() (string, []interface{}) {
+	conds := []string{"1 = 1"}
+	var args []interface{}
+	if len(f.Types) > 0 {
+		conds = append(conds, "type IN (?"+strings.Repeat(",?", len(f.Types)-1)+")")
+		for _, t := range f.Types {
+			args = append(args, t)
+		}
+	}
+	if f.ProjectID != nil {
+		conds = append(conds, "project_id = ?")
+		args = append(args, *f.ProjectID)
+	}
+	if f.Topic != "" {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(IFNULL(memories.topics, '[]')) WHERE lower(value) = lower(?))")
+		args = append(args, f.Topic)
+	}
+	if f.Source != "" {
+		conds = append(conds, "source_type = ?")
+		args = append(args, f.Source)
+	}
+	if !f.Before.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, f.Before)
+	}
+	if f.Query != "" {
+		conds = append(conds, "(content LIKE ? OR summary LIKE ? OR topics LIKE ?)")
+		like := "%" + f.Query + "%"
+		args = append(args, like, like, like)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// MatchMemories returns the memories a delete filter selects, newest first
+func (s *Store) MatchMemories(f DeleteFilter) ([]models.Memory, error) {
+	where, args := f.where()
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories WHERE `+where+` ORDER BY created_at DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// DeleteMemory deletes a memory and its local annotations. Returns ErrHeld
+// if the memory is under legal hold.
+func (s *Store) DeleteMemory(id string) error {
+	n, err := s.deleteMemories(`id = ?`, []interface{}{id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if held, err := s.IsHeld(id); err == nil && held {
+			return ErrHeld
+		}
+		return fmt.Errorf("memory %s not found", id)
+	}
+	return nil
+}
+
+// DeleteByFilter deletes the memories a filter selects, returning how many.
+// Memories under legal hold are kept. An empty filter is rejected with
+// ErrEmptyFilter.
+func (s *Store) DeleteByFilter(f DeleteFilter) (int64, error) {
+	if f.IsEmpty() {
+		return 0, ErrEmptyFilter
+	}
+	where, args := f.where()
+	return s.deleteMemories(where, args)
+}
+
+func (s *Store) deleteMemories(where string, args []interface{}) (int64, error) {
+	where = "(" + where + ") AND " + notHeld
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Annotations aren't tied to memories by a foreign key
+	if _, err := tx.Exec(`DELETE FROM annotations WHERE memory_id IN (SELECT id FROM memories WHERE `+where+`)`, args...); err != nil {
+		return 0, err
+	}
+	result, err := tx.Exec(`DELETE FROM memories WHERE `+where, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.RowsAffected()
+	return n, tx.Commit()
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ErrDuplicate is returned by CreateMemory when a memory with the same
+// content already exists for the same project and type. The existing
+// memory's merge counter is bumped and m.ID is set to its ID.
+var ErrDuplicate = errors.New("duplicate memory")
+
+// ContentHash identifies a memory's content, ignoring case and whitespace
+func ContentHash(content string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// mergeDuplicate records an ignored duplicate insert on the memory it
+// duplicates and points m at it
+func (s *Store) mergeDuplicate(m *models.Memory, hash string) error {
+	var id string
+	err := s.db.QueryRow(`
+		SELECT id FROM memories
+		WHERE IFNULL(project_id, '') = IFNULL(?, '') AND type = ? AND content_hash = ?
+	`, m.ProjectID, m.Type, hash).Scan(&id)
+	if err == sql.ErrNoRows {
+		// The insert was ignored for another reason (an ID collision)
+		return errors.New("memory " + m.ID + " already exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(`UPDATE memories SET merged_count = merged_count + 1 WHERE id = ?`, id); err != nil {
+		return err
+	}
+	m.ID = id
+	return ErrDuplicate
+}
+
+// backfillContentHashes hashes memories stored before content hashes
+// existed. Rows that duplicate an earlier memory keep a NULL hash so the
+// unique index can be built; nothing is deleted.
+func (s *Store) backfillContentHashes() error {
+	rows, err := s.db.Query(`
+		SELECT id, IFNULL(project_id, ''), type, content FROM memories
+		WHERE content_hash IS NULL
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return err
+	}
+
+	type row struct{ id, key, hash string }
+	var pending []row
+	for rows.Next() {
+		var id, project, memType, content string
+		if err := rows.Scan(&id, &project, &memType, &content); err != nil {
+			rows.Close()
+			return err
+		}
+		hash := ContentHash(content)
+		pending = append(pending, row{id: id, key: project + "\x00" + memType + "\x00" + hash, hash: hash})
+	}
+	rows.Close()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Hashes already taken by rows written since the column was added
+	taken := make(map[string]bool)
+	existing, err := s.db.Query(`
+		SELECT IFNULL(project_id, ''), type, content_hash FROM memories WHERE content_hash IS NOT NULL
+	`)
+	if err != nil {
+		return err
+	}
+	for existing.Next() {
+		var project, memType, hash string
+		if err := existing.Scan(&project, &memType, &hash); err != nil {
+			existing.Close()
+			return err
+		}
+		taken[project+"\x00"+memType+"\x00"+hash] = true
+	}
+	existing.Close()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range pending {
+		if taken[r.key] {
+			continue
+		}
+		taken[r.key] = true
+		if _, err := tx.Exec(`UPDATE memories SET content_hash = ? WHERE id = ?`, r.hash, r.id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// EventDedupWindow is how long after an event identical ones are folded
+// into it rather than stored
+const EventDedupWindow = time.Hour
+
+// eventHash identifies an event by its type, project and payload, ignoring
+// its ID and timestamp
+func eventHash(e *models.Event, dataJSON []byte) string {
+	h := sha256.New()
+	h.Write([]byte(e.Type))
+	h.Write([]byte{0})
+	if e.ProjectID != nil {
+		h.Write([]byte(*e.ProjectID))
+	}
+	h.Write([]byte{0})
+	h.Write(dataJSON)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// foldRepeat counts e as a repeat of an identical event captured in the
+// dedup window before it, reporting whether there was one
+func (s *Store) foldRepeat(e *models.Event, hash string) (bool, error) {
+	result, err := s.db.Exec(`
+		UPDATE events SET repeat_count = repeat_count + 1,
+			last_seen = MAX(IFNULL(last_seen, timestamp), ?)
+		WHERE id = (
+			SELECT id FROM events
+			WHERE content_hash = ? AND timestamp <= ? AND timestamp > ?
+			ORDER BY timestamp DESC LIMIT 1
+		)
+	`, e.Timestamp, hash, e.Timestamp, e.Timestamp.Add(-EventDedupWindow))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// captureAlertsKey holds the daemon's current capture alerts in sync_state
+const captureAlertsKey = "capture_alerts"
+
+// captureAlertsFresh is how long saved alerts are trusted; the daemon
+// rewrites them on every health check, so older ones mean it isn't running
+const captureAlertsFresh = time.Hour
+
+// watcherOf derives the capturing watcher from an event's type, which is
+// prefixed with it (git_commit, file_change, terminal_cmd, ...)
+const watcherOf = `substr(type, 1, instr(type || '_', '_') - 1)`
+
+// CaptureAlert reports a normally active watcher that has gone quiet
+type CaptureAlert struct {
+	Watcher   string    `json:"watcher"` // git | file | terminal
+	QuietFor  string    `json:"quietFor"`
+	LastEvent time.Time `json:"lastEvent,omitempty"`
+	Hint      string    `json:"hint"`
+	RaisedAt  time.Time `json:"raisedAt"`
+}
+
+// CaptureCounts returns the number of events captured in [from, to), keyed
+// by the watcher that produced them (the event type up to its first "_").
+// Repeats folded into an event count too.
+func (s *Store) CaptureCounts(from, to time.Time) (map[string]int, error) {
+	rows, err := s.db.Query(`
+		SELECT `+watcherOf+` AS watcher, SUM(repeat_count)
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY watcher
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var watcher string
+		var n int
+		if err := rows.Scan(&watcher, &n); err != nil {
+			return nil, err
+		}
+		counts[watcher] = n
+	}
+	return counts, rows.Err()
+}
+
+// LastCaptured returns the time of the newest event from a watcher, or the
+// zero time if it has never produced one
+func (s *Store) LastCaptured(watcher string) (time.Time, error) {
+	var last time.Time
+	var repeated sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT timestamp, last_seen FROM events
+		WHERE `+watcherOf+` = ?
+		ORDER BY IFNULL(last_seen, timestamp) DESC LIMIT 1
+	`, watcher).Scan(&last, &repeated)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if repeated.Valid && repeated.Time.After(last) {
+		last = repeated.Time
+	}
+	return last, err
+}
+
+// SetCaptureAlerts saves the daemon's current capture alerts (possibly
+// none) for status to show
+func (s *Store) SetCaptureAlerts(alerts []CaptureAlert) error {
+	if alerts == nil {
+		alerts = []CaptureAlert{}
+	}
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	return s.SetSyncState(captureAlertsKey, string(data))
+}
+
+// CaptureAlerts returns the alerts last saved by a running daemon. Alerts
+// from a daemon that has stopped checking are dropped.
+func (s *Store) CaptureAlerts() ([]CaptureAlert, error) {
+	value, updatedAt, ok, err := s.GetSyncState(captureAlertsKey)
+	if err != nil || !ok || time.Since(updatedAt) > captureAlertsFresh {
+		return nil, err
+	}
+	var alerts []CaptureAlert
+	if err := json.Unmarshal([]byte(value), &alerts); err != nil {
+		return nil, nil
+	}
+	return alerts, nil
+}
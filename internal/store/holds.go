@@ -0,0 +1,161 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrHeld is returned when a change would delete or modify a memory, or its
+// evidence, that is under legal hold
+var ErrHeld = errors.New("memory is under legal hold")
+
+// Hold kinds
+const (
+	HoldProject = "project"
+	HoldTopic   = "topic"
+	HoldMemory  = "memory"
+)
+
+// heldBy returns a condition that is true when the memory row named by
+// alias (memories, OLD, NEW) is under a legal hold
+func heldBy(alias string) string {
+	return `EXISTS (SELECT 1 FROM legal_holds h WHERE
+		(h.kind = 'memory' AND h.value = ` + alias + `.id) OR
+		(h.kind = 'project' AND h.value = ` + alias + `.project_id) OR
+		(h.kind = 'topic' AND EXISTS (SELECT 1 FROM json_each(IFNULL(` + alias + `.topics, '[]')) t
+			WHERE lower(t.value) = lower(h.value))))`
+}
+
+// notHeld excludes held memories from a statement on the memories table
+var notHeld = `NOT ` + heldBy("memories")
+
+var holdMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS legal_holds (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL CHECK (kind IN ('project', 'topic', 'memory')),
+		value TEXT NOT NULL,
+		reason TEXT,
+		created_at DATETIME NOT NULL,
+		UNIQUE (kind, value)
+	)`,
+
+	// The triggers enforce holds whatever code path touches the rows;
+	// store methods also check first, to skip held rows or return ErrHeld.
+	// Importance, access counts and embeddings are left writable: recall
+	// bumps them, and they are derived rather than the record itself.
+	`CREATE TRIGGER IF NOT EXISTS legal_hold_memory_delete BEFORE DELETE ON memories
+	WHEN ` + heldBy("OLD") + `
+	BEGIN SELECT RAISE(ABORT, 'memory is under legal hold'); END`,
+
+	`CREATE TRIGGER IF NOT EXISTS legal_hold_memory_update
+	BEFORE UPDATE OF type, content, summary, scope, project_id, team_id,
+		source_type, source_reference, source_timestamp, confidence, topics,
+		related_memories, created_at, expires_at, status, author, maintainer,
+		signature, signer ON memories
+	WHEN ` + heldBy("OLD") + `
+	BEGIN SELECT RAISE(ABORT, 'memory is under legal hold'); END`,
+
+	`CREATE TRIGGER IF NOT EXISTS legal_hold_annotation_delete BEFORE DELETE ON annotations
+	WHEN EXISTS (SELECT 1 FROM memories m WHERE m.id = OLD.memory_id AND ` + heldBy("m") + `)
+	BEGIN SELECT RAISE(ABORT, 'memory is under legal hold'); END`,
+
+	`CREATE TRIGGER IF NOT EXISTS legal_hold_annotation_update BEFORE UPDATE ON annotations
+	WHEN EXISTS (SELECT 1 FROM memories m WHERE m.id = OLD.memory_id AND ` + heldBy("m") + `)
+	BEGIN SELECT RAISE(ABORT, 'memory is under legal hold'); END`,
+
+	// Events are the evidence memories were extracted from
+	`CREATE TRIGGER IF NOT EXISTS legal_hold_event_delete BEFORE DELETE ON events
+	WHEN EXISTS (SELECT 1 FROM legal_holds h WHERE h.kind = 'project' AND h.value = OLD.project_id)
+	BEGIN SELECT RAISE(ABORT, 'memory is under legal hold'); END`,
+}
+
+// heldErr maps a trigger's abort to ErrHeld
+func heldErr(err error) error {
+	if err != nil && strings.Contains(err.Error(), ErrHeld.Error()) {
+		return ErrHeld
+	}
+	return err
+}
+
+// Hold is a legal hold on a project, topic or single memory
+type Hold struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"` // project ID, topic or memory ID
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AddHold places a legal hold, returning the existing hold if the same one
+// is already in place
+func (s *Store) AddHold(kind, value, reason string) (*Hold, error) {
+	switch kind {
+	case HoldProject, HoldTopic, HoldMemory:
+	default:
+		return nil, fmt.Errorf("unknown hold kind %q (expected project, topic or memory)", kind)
+	}
+	if _, err := s.db.Exec(`INSERT INTO legal_holds (kind, value, reason, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (kind, value) DO NOTHING`, kind, value, nullString(reason), time.Now()); err != nil {
+		return nil, err
+	}
+	holds, err := s.queryHolds(`WHERE kind = ? AND value = ?`, kind, value)
+	if err != nil || len(holds) == 0 {
+		return nil, err
+	}
+	return &holds[0], nil
+}
+
+// ReleaseHold removes a legal hold
+func (s *Store) ReleaseHold(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM legal_holds WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("hold %d not found", id)
+	}
+	return nil
+}
+
+// ListHolds returns the legal holds in place, oldest first
+func (s *Store) ListHolds() ([]Hold, error) {
+	return s.queryHolds(`ORDER BY id`)
+}
+
+func (s *Store) queryHolds(clause string, args ...interface{}) ([]Hold, error) {
+	rows, err := s.db.Query(`SELECT id, kind, value, IFNULL(reason, ''), created_at FROM legal_holds `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holds []Hold
+	for rows.Next() {
+		var h Hold
+		if err := rows.Scan(&h.ID, &h.Kind, &h.Value, &h.Reason, &h.CreatedAt); err != nil {
+			return nil, err
+		}
+		holds = append(holds, h)
+	}
+	return holds, rows.Err()
+}
+
+// HeldCount returns how many memories a hold covers
+func (s *Store) HeldCount(h Hold) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE
+		(? = 'memory' AND id = ?) OR
+		(? = 'project' AND project_id = ?) OR
+		(? = 'topic' AND EXISTS (SELECT 1 FROM json_each(IFNULL(topics, '[]')) WHERE lower(value) = lower(?)))`,
+		h.Kind, h.Value, h.Kind, h.Value, h.Kind, h.Value).Scan(&n)
+	return n, err
+}
+
+// IsHeld reports whether a memory is under legal hold
+func (s *Store) IsHeld(memoryID string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE id = ? AND `+heldBy("memories"), memoryID).Scan(&n)
+	return n > 0, err
+}
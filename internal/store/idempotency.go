@@ -0,0 +1,71 @@
+package store
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// IdempotencyTTL is how long a remember request's idempotency key is
+// honoured; retries later than that create a new memory
+const IdempotencyTTL = 24 * time.Hour
+
+// ErrIdempotencyMismatch is returned when an idempotency key is reused for
+// a different request
+var ErrIdempotencyMismatch = errors.New("idempotency key was already used for a different request")
+
+var idempotencyMigrations = []string{
+	// Keys sent with remember requests over the API and MCP, so a retrying
+	// client gets the memory its first attempt created
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		key TEXT PRIMARY KEY,
+		request_hash TEXT NOT NULL,
+		memory_id TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created ON idempotency_keys(created_at)`,
+}
+
+// RequestHash fingerprints a request so a reused idempotency key can be
+// told apart from a retry
+func RequestHash(request interface{}) string {
+	data, _ := json.Marshal(request)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// IdempotentMemory returns the ID of the memory created under key within
+// IdempotencyTTL, or "" if there is none. Returns ErrIdempotencyMismatch
+// if the key came with a different request.
+func (s *Store) IdempotentMemory(key, requestHash string) (string, error) {
+	var hash, memoryID string
+	err := s.db.QueryRow(`SELECT request_hash, memory_id FROM idempotency_keys WHERE key = ? AND created_at >= ?`,
+		key, time.Now().Add(-IdempotencyTTL)).Scan(&hash, &memoryID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if hash != requestHash {
+		return "", ErrIdempotencyMismatch
+	}
+	return memoryID, nil
+}
+
+// RecordIdempotencyKey remembers the memory a keyed request created,
+// replacing an expired use of the key
+func (s *Store) RecordIdempotencyKey(key, requestHash, memoryID string) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO idempotency_keys (key, request_hash, memory_id, created_at) VALUES (?, ?, ?, ?)`,
+		key, requestHash, memoryID, time.Now())
+	return err
+}
+
+// PruneIdempotencyKeys deletes keys recorded before the given time
+func (s *Store) PruneIdempotencyKeys(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM idempotency_keys WHERE created_at < ?`, before)
+	return err
+}
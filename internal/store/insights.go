@@ -0,0 +1,51 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+var insightsMigrations = []string{
+	// Aggregated org insight reports, as JSON; only the latest is served
+	`CREATE TABLE IF NOT EXISTS org_insights (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		generated_at DATETIME NOT NULL,
+		report TEXT NOT NULL
+	)`,
+}
+
+// insightsKept is how many reports are kept, for comparing over time
+const insightsKept = 30
+
+// SaveInsights stores an aggregated insight report, pruning old ones
+func (s *Store) SaveInsights(generatedAt time.Time, report []byte) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO org_insights (generated_at, report) VALUES (?, ?)`,
+		generatedAt, string(report)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM org_insights WHERE id NOT IN
+		(SELECT id FROM org_insights ORDER BY id DESC LIMIT ?)`, insightsKept); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LatestInsights returns the most recent insight report, or nil if none
+// has been generated
+func (s *Store) LatestInsights() ([]byte, error) {
+	var report string
+	err := s.db.QueryRow(`SELECT report FROM org_insights ORDER BY id DESC LIMIT 1`).Scan(&report)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(report), nil
+}
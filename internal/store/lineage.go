@@ -0,0 +1,177 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+var lineageMigrations = []string{
+	// What happened to each memory after it was created, for 'memorypilot
+	// why'; rows go with their memory
+	`CREATE TABLE IF NOT EXISTS memory_lineage (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		memory_id TEXT NOT NULL,
+		at DATETIME NOT NULL,
+		action TEXT NOT NULL,
+		event_ids TEXT,
+		provider TEXT,
+		model TEXT,
+		prompt_hash TEXT,
+		confidence REAL,
+		note TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_memory_lineage_memory ON memory_lineage(memory_id, id)`,
+	`CREATE TRIGGER IF NOT EXISTS memory_lineage_on_delete AFTER DELETE ON memories BEGIN
+		DELETE FROM memory_lineage WHERE memory_id = OLD.id;
+	END`,
+}
+
+// Lineage actions
+const (
+	LineageExtracted = "extracted" // created from a batch of events
+	LineageMerged    = "merged"    // a repeat of it was folded in
+	LineageEdited    = "edited"    // content or metadata changed
+	LineageStatus    = "status"    // a review decision
+)
+
+// LineageEntry is one step in a memory's history
+type LineageEntry struct {
+	At         time.Time `json:"at"`
+	Action     string    `json:"action"`
+	RunID      string    `json:"runId,omitempty"` // the extraction run behind it
+	EventIDs   []string  `json:"eventIds,omitempty"`
+	Provider   string    `json:"provider,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	PromptHash string    `json:"promptHash,omitempty"`
+	Confidence float64   `json:"confidence,omitempty"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// RecordLineage appends a step to a memory's history
+func (s *Store) RecordLineage(memoryID string, e LineageEntry) error {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	var eventIDs interface{}
+	if len(e.EventIDs) > 0 {
+		data, _ := json.Marshal(e.EventIDs)
+		eventIDs = string(data)
+	}
+	var confidence interface{}
+	if e.Confidence > 0 {
+		confidence = e.Confidence
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO memory_lineage (memory_id, at, action, run_id, event_ids, provider, model, prompt_hash, confidence, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, memoryID, e.At, e.Action, nullString(e.RunID), eventIDs, nullString(e.Provider), nullString(e.Model),
+		nullString(e.PromptHash), confidence, nullString(e.Note))
+	return err
+}
+
+// Lineage returns a memory's history, oldest first
+func (s *Store) Lineage(memoryID string) ([]LineageEntry, error) {
+	rows, err := s.db.Query(`
+		SELECT at, action, run_id, event_ids, provider, model, prompt_hash, confidence, note
+		FROM memory_lineage WHERE memory_id = ? ORDER BY id
+	`, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LineageEntry
+	for rows.Next() {
+		var e LineageEntry
+		var runID, eventIDs, provider, model, promptHash, note sql.NullString
+		var confidence sql.NullFloat64
+		if err := rows.Scan(&e.At, &e.Action, &runID, &eventIDs, &provider, &model, &promptHash, &confidence, &note); err != nil {
+			return nil, err
+		}
+		if eventIDs.Valid {
+			json.Unmarshal([]byte(eventIDs.String), &e.EventIDs)
+		}
+		e.RunID, e.Provider, e.Model, e.PromptHash, e.Note = runID.String, provider.String, model.String, promptHash.String, note.String
+		e.Confidence = confidence.Float64
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetEvents returns the events with the given IDs that are still stored,
+// oldest first
+func (s *Store) GetEvents(ids []string) ([]models.Event, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT `+eventColumns+` FROM events
+		WHERE id IN (?`+strings.Repeat(",?", len(ids)-1)+`)
+		ORDER BY timestamp ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// changedFields names the fields UpdateMemory changes between two versions
+// of a memory
+func changedFields(old, m *models.Memory) []string {
+	var fields []string
+	if old.Type != m.Type {
+		fields = append(fields, "type")
+	}
+	if old.Content != m.Content {
+		fields = append(fields, "content")
+	}
+	if old.Summary != m.Summary {
+		fields = append(fields, "summary")
+	}
+	if old.Scope != m.Scope {
+		fields = append(fields, "scope")
+	}
+	if stringOf(old.ProjectID) != stringOf(m.ProjectID) {
+		fields = append(fields, "project")
+	}
+	if strings.Join(old.Topics, "\x00") != strings.Join(m.Topics, "\x00") {
+		fields = append(fields, "topics")
+	}
+	if !sameTime(old.ExpiresAt, m.ExpiresAt) {
+		fields = append(fields, "expiry")
+	}
+	if old.Maintainer != m.Maintainer {
+		fields = append(fields, "maintainer")
+	}
+	if old.Importance != m.Importance {
+		fields = append(fields, "importance")
+	}
+	if old.Confidence != m.Confidence {
+		fields = append(fields, "confidence")
+	}
+	return fields
+}
+
+func stringOf(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
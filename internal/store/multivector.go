@@ -0,0 +1,243 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Besides its content embedding, a memory can have vectors of its own for
+// its summary and its topics, so a query phrased like a memory's summary
+// finds it even when its details read differently, and for each chunk of
+// long content (see Chunks). They're kept in
+// memory_vectors and indexed in vector_index under their kind (content
+// embeddings are kind ""). Changing a memory's text or embedding drops
+// them, and they're embedded again from the new text.
+
+// Kinds of vector besides a memory's content embedding. Chunk vectors'
+// kinds also name their chunk, as "chunk:<start>-<end>".
+const (
+	VectorSummary = "summary"
+	VectorTopics  = "topics"
+	VectorChunk   = "chunk"
+)
+
+// vectorClass returns the kind of vector a stored kind is, without the
+// chunk it names
+func vectorClass(kind string) string {
+	class, _, _ := strings.Cut(kind, ":")
+	return class
+}
+
+var multiVectorMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS memory_vectors (
+		memory_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		embedding BLOB NOT NULL,
+		PRIMARY KEY (memory_id, kind)
+	)`,
+
+	// Only the changed kind leaves the index, so adding vectors doesn't
+	// force other processes to reload it
+	`CREATE TRIGGER IF NOT EXISTS memory_vectors_on_insert AFTER INSERT ON memory_vectors BEGIN
+		DELETE FROM vector_index WHERE memory_id = NEW.memory_id AND kind = NEW.kind;
+		INSERT OR IGNORE INTO vector_index_pending (memory_id) VALUES (NEW.memory_id);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS memory_vectors_on_update AFTER UPDATE ON memory_vectors BEGIN
+		DELETE FROM vector_index WHERE memory_id = NEW.memory_id AND kind = NEW.kind;
+		INSERT OR IGNORE INTO vector_index_pending (memory_id) VALUES (NEW.memory_id);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS memory_vectors_on_delete AFTER DELETE ON memory_vectors BEGIN
+		DELETE FROM vector_index WHERE memory_id = OLD.memory_id AND kind = OLD.kind;
+	END`,
+
+	// Vectors of text that changed, or from another model, are stale
+	`CREATE TRIGGER IF NOT EXISTS memory_vectors_on_memory_update AFTER UPDATE OF content, summary, topics, embedding ON memories
+		WHEN NEW.content IS NOT OLD.content OR NEW.summary IS NOT OLD.summary
+			OR NEW.topics IS NOT OLD.topics OR NEW.embedding IS NOT OLD.embedding
+	BEGIN
+		DELETE FROM memory_vectors WHERE memory_id = NEW.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS memory_vectors_on_memory_delete AFTER DELETE ON memories BEGIN
+		DELETE FROM memory_vectors WHERE memory_id = OLD.id;
+	END`,
+}
+
+// upgradeVectorIndex rebuilds a vector index from before vectors had
+// kinds. The index only holds copies of embeddings, so it's dropped,
+// recreated by vectorMigrations and refilled before the next search.
+func (s *Store) upgradeVectorIndex() error {
+	var tables, kinds int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'vector_index'`).Scan(&tables); err != nil {
+		return err
+	}
+	if tables == 0 {
+		return nil
+	}
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('vector_index') WHERE name = 'kind'`).Scan(&kinds); err != nil {
+		return err
+	}
+	if kinds > 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS vector_index`,
+		`UPDATE vector_index_meta SET deletes = deletes + 1 WHERE id = 1`,
+		`INSERT OR IGNORE INTO vector_index_pending (memory_id) SELECT id FROM memories WHERE embedding IS NOT NULL`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// VectorTexts returns the texts a memory's extra vectors embed, by kind: a
+// summary that isn't just the content, the topics, and chunks of long
+// content
+func VectorTexts(m models.Memory) map[string]string {
+	texts := make(map[string]string)
+	if m.Summary != "" && m.Summary != m.Content {
+		texts[VectorSummary] = m.Summary
+	}
+	if len(m.Topics) > 0 {
+		texts[VectorTopics] = strings.Join(m.Topics, ", ")
+	}
+	for _, c := range Chunks(m.Content) {
+		texts[c.Kind()] = m.Content[c.Start:c.End]
+	}
+	return texts
+}
+
+// VectorClass returns the kind of vector a kind from VectorTexts is:
+// VectorSummary, VectorTopics or VectorChunk
+func VectorClass(kind string) string {
+	return vectorClass(kind)
+}
+
+// Conditions on memories lacking a vector VectorTexts would give them
+const (
+	needsSummaryVector = `(summary != '' AND summary != content
+		AND NOT EXISTS (SELECT 1 FROM memory_vectors v WHERE v.memory_id = memories.id AND v.kind = 'summary'))`
+	needsTopicsVector = `(topics LIKE '["%'
+		AND NOT EXISTS (SELECT 1 FROM memory_vectors v WHERE v.memory_id = memories.id AND v.kind = 'topics'))`
+	needsChunkVectors = `(length(CAST(content AS BLOB)) > ` + chunkThresholdSQL + `
+		AND NOT EXISTS (SELECT 1 FROM memory_vectors v WHERE v.memory_id = memories.id AND v.kind LIKE 'chunk:%'))`
+	needsVectors = `embedding IS NOT NULL AND status != 'rejected' AND (` + needsSummaryVector + ` OR ` + needsTopicsVector + ` OR ` + needsChunkVectors + `)
+		AND (embedding_model IS NULL OR embedding_model = ?)`
+)
+
+// MissingVectors is a memory, the kinds of vector it lacks (VectorChunk
+// for all its chunks), and the
+// dimension of its content embedding, which they must match
+type MissingVectors struct {
+	models.Memory
+	Kinds      []string
+	Dimensions int
+}
+
+// CountMissingVectors returns how many memories, other than rejected ones,
+// lack a summary or topics vector, of those whose content embedding came
+// from model or an unrecorded one
+func (s *Store) CountMissingVectors(model string) (int, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE `+needsVectors, model).Scan(&n)
+	return n, err
+}
+
+// MemoriesMissingVectors returns up to limit of the memories
+// CountMissingVectors counts, in ID order after the given ID ("" to start)
+func (s *Store) MemoriesMissingVectors(model, after string, limit int) ([]MissingVectors, error) {
+	rows, err := s.db.Query(`SELECT `+memoryColumns+`, `+needsSummaryVector+`, `+needsTopicsVector+`, `+needsChunkVectors+`,
+		IFNULL(embedding_dim, length(embedding) / 4) FROM memories
+		WHERE `+needsVectors+` AND id > ? ORDER BY id LIMIT ?`, model, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var missing []MissingVectors
+	for rows.Next() {
+		var summary, topics, chunks bool
+		var dims int
+		m, err := scanMemory(extraScanner{rows, []interface{}{&summary, &topics, &chunks, &dims}})
+		if err != nil {
+			return nil, err
+		}
+		mv := MissingVectors{Memory: m, Dimensions: dims}
+		if summary {
+			mv.Kinds = append(mv.Kinds, VectorSummary)
+		}
+		if topics {
+			mv.Kinds = append(mv.Kinds, VectorTopics)
+		}
+		if chunks {
+			mv.Kinds = append(mv.Kinds, VectorChunk)
+		}
+		missing = append(missing, mv)
+	}
+	return missing, rows.Err()
+}
+
+// SetMemoryVector stores one of a memory's extra vectors
+func (s *Store) SetMemoryVector(memoryID, kind string, embedding []float32) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO memory_vectors (memory_id, kind, embedding) VALUES (?, ?, ?)`,
+		memoryID, kind, encodeEmbedding(embedding))
+	return err
+}
+
+// memoryVectors returns the extra vectors of the given memories by memory
+// ID and kind, leaving out kinds weighted 0 or missing from weights (by
+// their class, for chunks)
+func (s *Store) memoryVectors(ids []string, weights map[string]float32) (map[string]map[string][]float32, error) {
+	vectors := make(map[string]map[string][]float32)
+	for start := 0; start < len(ids); start += vectorFetchBatch {
+		batch := ids[start:min(start+vectorFetchBatch, len(ids))]
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+		rows, err := s.db.Query(`SELECT memory_id, kind, embedding FROM memory_vectors
+			WHERE memory_id IN (?`+strings.Repeat(",?", len(batch)-1)+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id, kind string
+			var blob []byte
+			if err := rows.Scan(&id, &kind, &blob); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if weights[vectorClass(kind)] <= 0 {
+				continue
+			}
+			if vectors[id] == nil {
+				vectors[id] = make(map[string][]float32)
+			}
+			vectors[id][kind] = decodeEmbedding(blob)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return vectors, nil
+}
+
+// vectorWeights returns how much each kind of extra vector counts in
+// hybrid search, relative to content embeddings. Chunks are content, so
+// they count in full.
+func vectorWeights(w models.SearchWeights) map[string]float32 {
+	return map[string]float32{
+		VectorSummary: float32(w.SummaryVector),
+		VectorTopics:  float32(w.TopicsVector),
+		VectorChunk:   1,
+	}
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// ProjectCentroid is the mean embedding of a project's memories
+type ProjectCentroid struct {
+	Project  models.Project
+	Centroid []float32
+	Memories int
+}
+
+// Kinds of project facts
+const (
+	FactLanguage   = "language"
+	FactFramework  = "framework"
+	FactDependency = "dependency"
+)
+
+// ProjectFact is a structured fact about a project, e.g. language=Go
+type ProjectFact struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// SetProjectFacts replaces all stored facts for a project
+func (s *Store) SetProjectFacts(projectID string, facts []ProjectFact) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM project_facts WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, f := range facts {
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO project_facts (project_id, kind, value, updated_at)
+			VALUES (?, ?, ?, ?)
+		`, projectID, f.Kind, f.Value, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetProjectFacts returns a project's facts ordered by kind and value
+func (s *Store) GetProjectFacts(projectID string) ([]ProjectFact, error) {
+	rows, err := s.db.Query(`
+		SELECT kind, value FROM project_facts WHERE project_id = ? ORDER BY kind, value
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facts []ProjectFact
+	for rows.Next() {
+		var f ProjectFact
+		if err := rows.Scan(&f.Kind, &f.Value); err != nil {
+			return nil, err
+		}
+		facts = append(facts, f)
+	}
+	return facts, nil
+}
+
+// EnsureProject returns the project at path, registering it on first sight
+// and refreshing its last-seen time otherwise
+func (s *Store) EnsureProject(path string) (*models.Project, error) {
+	p, err := s.GetProjectByPath(path)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if p != nil {
+		_, err := s.db.Exec(`UPDATE projects SET last_seen = ? WHERE id = ?`, now, p.ID)
+		p.LastSeen = now
+		return p, err
+	}
+
+	p = &models.Project{
+		ID:        ulid.Make().String(),
+		Name:      filepath.Base(path),
+		Path:      path,
+		CreatedAt: now,
+		LastSeen:  now,
+	}
+	return p, s.CreateProject(p)
+}
+
+// ListProjects returns every known project, most recently seen first
+func (s *Store) ListProjects() ([]models.Project, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, path, git_remote, created_at, last_seen
+		FROM projects ORDER BY last_seen DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		var p models.Project
+		var gitRemote sql.NullString
+		if err := rows.Scan(&p.ID, &p.Name, &p.Path, &gitRemote, &p.CreatedAt, &p.LastSeen); err != nil {
+			return nil, err
+		}
+		if gitRemote.Valid {
+			p.GitRemote = &gitRemote.String
+		}
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+// ProjectCentroids computes the centroid of every project with embedded
+// memories. Expired memories are left out so short-lived context doesn't
+// skew what a project is about.
+func (s *Store) ProjectCentroids() ([]ProjectCentroid, error) {
+	projects, err := s.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT project_id, embedding FROM memories
+		WHERE project_id IS NOT NULL AND embedding IS NOT NULL
+		AND `+unexpired+` AND `+approved+`
+	`, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[string][]float64)
+	counts := make(map[string]int)
+	for rows.Next() {
+		var projectID string
+		var blob []byte
+		if err := rows.Scan(&projectID, &blob); err != nil {
+			return nil, err
+		}
+		emb := decodeEmbedding(blob)
+		sum := sums[projectID]
+		if sum == nil {
+			sum = make([]float64, len(emb))
+			sums[projectID] = sum
+		}
+		if len(emb) != len(sum) {
+			continue // embedded with a different model
+		}
+		for i, v := range emb {
+			sum[i] += float64(v)
+		}
+		counts[projectID]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var centroids []ProjectCentroid
+	for _, p := range projects {
+		n := counts[p.ID]
+		if n == 0 {
+			continue
+		}
+		centroid := make([]float32, len(sums[p.ID]))
+		for i, v := range sums[p.ID] {
+			centroid[i] = float32(v / float64(n))
+		}
+		centroids = append(centroids, ProjectCentroid{Project: p, Centroid: centroid, Memories: n})
+	}
+	return centroids, nil
+}
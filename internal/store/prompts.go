@@ -0,0 +1,74 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// FlagStalePrompts flags approved memories extracted with prompts older
+// than version and with confidence below maxConfidence for reprocessing,
+// returning how many were newly flagged. Memories under legal hold are
+// left alone.
+func (s *Store) FlagStalePrompts(version int, maxConfidence float64) (int64, error) {
+	result, err := s.db.Exec(`
+		UPDATE memories SET reprocess_flagged = 1
+		WHERE reprocess_flagged = 0 AND provider IS NOT NULL
+			AND IFNULL(prompt_version, 0) < ? AND confidence < ?
+			AND `+approved+` AND `+notHeld,
+		version, maxConfidence)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FlagForReprocess flags a memory for reprocessing, as one extracted
+// without a model that a model should redo
+func (s *Store) FlagForReprocess(id string) error {
+	_, err := s.db.Exec(`UPDATE memories SET reprocess_flagged = 1 WHERE id = ? AND `+notHeld, id)
+	return err
+}
+
+// FlaggedForReprocess returns the approved memories flagged by
+// FlagStalePrompts or FlagForReprocess, oldest first
+func (s *Store) FlaggedForReprocess() ([]models.Memory, error) {
+	rows, err := s.db.Query(`SELECT ` + memoryColumns + ` FROM memories
+		WHERE reprocess_flagged = 1 AND ` + approved + ` ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// IsFlaggedForReprocess reports whether a memory is flagged for
+// reprocessing
+func (s *Store) IsFlaggedForReprocess(id string) (bool, error) {
+	var flagged bool
+	err := s.db.QueryRow(`SELECT reprocess_flagged FROM memories WHERE id = ?`, id).Scan(&flagged)
+	return flagged, err
+}
+
+// ClearReprocessFlags unflags memories once they have been reprocessed
+func (s *Store) ClearReprocessFlags(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	_, err := s.db.Exec(`UPDATE memories SET reprocess_flagged = 0
+		WHERE id IN (?`+strings.Repeat(",?", len(ids)-1)+`) AND `+notHeld, args...)
+	return err
+}
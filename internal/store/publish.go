@@ -0,0 +1,199 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+var publishMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS publish_targets (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,          -- notion | confluence
+		destination TEXT NOT NULL,   -- Notion database ID or Confluence page ID
+		filter TEXT NOT NULL,        -- JSON, interpreted by the publisher
+		interval_seconds INTEGER NOT NULL,
+		last_run_at DATETIME,
+		last_error TEXT,
+		last_hash TEXT,              -- of the last published page, for whole-page targets
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Remote copies of memories published one per page
+	`CREATE TABLE IF NOT EXISTS published_memories (
+		target_id TEXT NOT NULL REFERENCES publish_targets(id) ON DELETE CASCADE,
+		memory_id TEXT NOT NULL,
+		remote_id TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		published_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (target_id, memory_id)
+	)`,
+}
+
+// PublishTarget is an external page or database kept in sync with a
+// selection of memories
+type PublishTarget struct {
+	ID          string        `json:"id"`
+	Kind        string        `json:"kind"`
+	Destination string        `json:"destination"`
+	Filter      string        `json:"filter"`
+	Interval    time.Duration `json:"interval"`
+	LastRunAt   *time.Time    `json:"lastRunAt,omitempty"`
+	LastError   string        `json:"lastError,omitempty"`
+	LastHash    string        `json:"-"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+// Due reports whether the target should be published at now
+func (t PublishTarget) Due(now time.Time) bool {
+	return t.LastRunAt == nil || now.Sub(*t.LastRunAt) >= t.Interval
+}
+
+// PublishedMemory is the remote copy of a memory on a publish target
+type PublishedMemory struct {
+	RemoteID string
+	Hash     string
+}
+
+// AddPublishTarget registers a publish target
+func (s *Store) AddPublishTarget(t *PublishTarget) error {
+	_, err := s.db.Exec(`INSERT INTO publish_targets (id, kind, destination, filter, interval_seconds, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Kind, t.Destination, t.Filter, int64(t.Interval/time.Second), t.CreatedAt)
+	return err
+}
+
+// RemovePublishTarget unregisters a publish target. Published pages are
+// left in place.
+func (s *Store) RemovePublishTarget(id string) error {
+	result, err := s.db.Exec(`DELETE FROM publish_targets WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("publish target %s not found", id)
+	}
+	return nil
+}
+
+// ListPublishTargets returns all publish targets, oldest first
+func (s *Store) ListPublishTargets() ([]PublishTarget, error) {
+	rows, err := s.db.Query(`
+		SELECT id, kind, destination, filter, interval_seconds, last_run_at, last_error, last_hash, created_at
+		FROM publish_targets ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []PublishTarget
+	for rows.Next() {
+		var t PublishTarget
+		var interval int64
+		var lastRun sql.NullTime
+		var lastError, lastHash sql.NullString
+		if err := rows.Scan(&t.ID, &t.Kind, &t.Destination, &t.Filter, &interval, &lastRun, &lastError, &lastHash, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		t.Interval = time.Duration(interval) * time.Second
+		if lastRun.Valid {
+			t.LastRunAt = &lastRun.Time
+		}
+		t.LastError = lastError.String
+		t.LastHash = lastHash.String
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// RecordPublishRun records the outcome of publishing to a target. hash, if
+// non-empty, replaces the target's last published page hash.
+func (s *Store) RecordPublishRun(id string, at time.Time, runErr error, hash string) error {
+	var msg interface{}
+	if runErr != nil {
+		msg = runErr.Error()
+	}
+	_, err := s.db.Exec(`UPDATE publish_targets
+		SET last_run_at = ?, last_error = ?, last_hash = COALESCE(?, last_hash)
+		WHERE id = ?`, at, msg, nullString(hash), id)
+	return err
+}
+
+// PublishedMemories returns the memories published to a target, by memory ID
+func (s *Store) PublishedMemories(targetID string) (map[string]PublishedMemory, error) {
+	rows, err := s.db.Query(`SELECT memory_id, remote_id, hash FROM published_memories WHERE target_id = ?`, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	published := make(map[string]PublishedMemory)
+	for rows.Next() {
+		var id string
+		var p PublishedMemory
+		if err := rows.Scan(&id, &p.RemoteID, &p.Hash); err != nil {
+			return nil, err
+		}
+		published[id] = p
+	}
+	return published, rows.Err()
+}
+
+// SetPublishedMemory records the remote copy of a memory on a target
+func (s *Store) SetPublishedMemory(targetID, memoryID string, p PublishedMemory) error {
+	_, err := s.db.Exec(`INSERT INTO published_memories (target_id, memory_id, remote_id, hash, published_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (target_id, memory_id) DO UPDATE SET remote_id = excluded.remote_id, hash = excluded.hash, published_at = excluded.published_at`,
+		targetID, memoryID, p.RemoteID, p.Hash, time.Now())
+	return err
+}
+
+// DeletePublishedMemory forgets the remote copy of a memory on a target
+func (s *Store) DeletePublishedMemory(targetID, memoryID string) error {
+	_, err := s.db.Exec(`DELETE FROM published_memories WHERE target_id = ? AND memory_id = ?`, targetID, memoryID)
+	return err
+}
+
+// ListMemories returns every approved memory passing the request's type,
+// scope and project filters, newest first. Unlike Recall it has no limit
+// and doesn't count as access.
+func (s *Store) ListMemories(req models.RecallRequest) ([]models.Memory, error) {
+	query := `SELECT ` + memoryColumns + ` FROM memories WHERE ` + approved
+	var args []interface{}
+	if len(req.Types) > 0 {
+		query += " AND type IN (?" + strings.Repeat(",?", len(req.Types)-1) + ")"
+		for _, t := range req.Types {
+			args = append(args, t)
+		}
+	}
+	if len(req.Scope) > 0 {
+		query += " AND scope IN (?" + strings.Repeat(",?", len(req.Scope)-1) + ")"
+		for _, sc := range req.Scope {
+			args = append(args, sc)
+		}
+	}
+	if req.ProjectID != nil {
+		query += " AND (project_id = ? OR project_id IS NULL)"
+		args = append(args, *req.ProjectID)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
@@ -0,0 +1,494 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrCorrupt is returned when the database file is damaged
+var ErrCorrupt = errors.New("database is corrupt")
+
+// TableRecovery accounts for one table's rows after a recovery. Expected
+// and Unreadable are -1 when the damaged table couldn't even be counted.
+type TableRecovery struct {
+	Table      string `json:"table"`
+	Expected   int    `json:"expected"`   // rows in the damaged database
+	Salvaged   int    `json:"salvaged"`   // rows read back from it
+	Unreadable int    `json:"unreadable"` // Expected - Salvaged
+	FromBackup int    `json:"fromBackup"` // rows missing after salvage, restored from the backup
+	Error      string `json:"error,omitempty"`
+}
+
+// Lost is the number of unreadable rows the backup couldn't make up for.
+// Backed-up rows deleted after the backup was taken also count as
+// restored, so this is a lower bound when Unreadable is known.
+func (t TableRecovery) Lost() int {
+	if t.Unreadable < 0 || t.Unreadable <= t.FromBackup {
+		return 0
+	}
+	return t.Unreadable - t.FromBackup
+}
+
+// RecoveryReport describes what a recovery salvaged, restored and lost
+type RecoveryReport struct {
+	Time        time.Time       `json:"time"`
+	CorruptPath string          `json:"corruptPath"` // where the damaged file was moved
+	Backup      string          `json:"backup,omitempty"`
+	Tables      []TableRecovery `json:"tables"`
+	ReportPath  string          `json:"-"`
+}
+
+// Summary describes the recovery in a few lines
+func (r *RecoveryReport) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Recovered corrupt database (damaged copy kept at %s)\n", r.CorruptPath)
+	if r.Backup != "" {
+		fmt.Fprintf(&sb, "Missing rows restored from backup %s\n", r.Backup)
+	} else {
+		sb.WriteString("No usable backup was available\n")
+	}
+	for _, t := range r.Tables {
+		if t.Expected == 0 && t.Salvaged == 0 && t.FromBackup == 0 {
+			continue
+		}
+		expected := "?"
+		if t.Expected >= 0 {
+			expected = fmt.Sprint(t.Expected)
+		}
+		fmt.Fprintf(&sb, "  %-20s %s rows: %d salvaged, %d from backup, %d lost", t.Table, expected, t.Salvaged, t.FromBackup, t.Lost())
+		if t.Expected < 0 {
+			sb.WriteString(" (unknown: table unreadable)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// IsCorrupt reports whether err is SQLite reporting a damaged database
+func IsCorrupt(err error) bool {
+	if errors.Is(err, ErrCorrupt) {
+		return true
+	}
+	return sqliteCorrupt(err)
+}
+
+// CheckIntegrity runs SQLite's quick check, returning ErrCorrupt with the
+// first problems found
+func (s *Store) CheckIntegrity() error {
+	return quickCheck(s.db)
+}
+
+func quickCheck(db *sql.DB) error {
+	rows, err := db.Query(`PRAGMA quick_check(5)`)
+	if err != nil {
+		if IsCorrupt(err) {
+			return fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCorrupt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// OpenOrRecover opens the database, recovering it first if it is corrupt.
+// The report is nil when no recovery was needed.
+func OpenOrRecover(dbPath string) (*Store, *RecoveryReport, error) {
+	s, err := New(dbPath)
+	if err == nil {
+		if err = s.CheckIntegrity(); err == nil {
+			return s, nil, nil
+		}
+		s.Close()
+	}
+	if !IsCorrupt(err) {
+		return nil, nil, err
+	}
+
+	report, err := Recover(dbPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("recovery failed: %w", err)
+	}
+	s, err = New(dbPath)
+	return s, report, err
+}
+
+// Recover rebuilds a damaged database: the file is moved aside, every
+// readable row is copied into a fresh database, rows that couldn't be read
+// are restored from the newest healthy backup, and a report of what was
+// lost is written next to the database.
+func Recover(dbPath string) (*RecoveryReport, error) {
+	now := time.Now()
+	stamp := now.Format("20060102-150405")
+	report := &RecoveryReport{Time: now, CorruptPath: dbPath + ".corrupt-" + stamp}
+
+	if err := os.Rename(dbPath, report.CorruptPath); err != nil {
+		return nil, err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		os.Rename(dbPath+suffix, report.CorruptPath+suffix)
+	}
+
+	fresh, err := New(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fresh.Close()
+
+	ctx := context.Background()
+	conn, err := fresh.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Salvaged rows may reference rows that were lost; FixOrphans tidies up
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, err
+	}
+
+	// Salvaged and restored memories aren't news to webhooks. Opening the
+	// store again recreates the triggers.
+	if err := dropWebhookTriggers(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	tables, err := salvageTables(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	damaged, err := sql.Open("sqlite3", report.CorruptPath)
+	if err != nil {
+		return nil, err
+	}
+	defer damaged.Close()
+
+	for _, table := range tables {
+		t := TableRecovery{Table: table, Expected: -1, Unreadable: -1}
+		if err := damaged.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&t.Expected); err != nil {
+			t.Expected = -1
+		}
+		t.Salvaged, err = salvageTable(ctx, conn, damaged, table)
+		if err != nil {
+			t.Error = err.Error()
+		}
+		if t.Expected >= 0 {
+			t.Unreadable = t.Expected - t.Salvaged
+			if t.Unreadable < 0 {
+				t.Unreadable = 0
+			}
+		}
+		report.Tables = append(report.Tables, t)
+	}
+
+	// Fill the gaps from the newest backup that is itself healthy
+	if backup := healthyBackup(dbPath); backup != "" {
+		report.Backup = backup
+		if err := restoreFromBackup(ctx, conn, backup, report.Tables); err != nil {
+			return nil, fmt.Errorf("restore from %s failed: %w", backup, err)
+		}
+	}
+
+	conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	if _, err := fresh.FixOrphans(); err != nil {
+		return nil, err
+	}
+
+	report.ReportPath = dbPath + ".recovery-" + stamp + ".json"
+	data, _ := json.MarshalIndent(report, "", "  ")
+	if err := os.WriteFile(report.ReportPath, data, 0600); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// salvageTables lists the tables to copy, parents before the tables whose
+// foreign keys reference them and otherwise in the order they were
+// created. Full-text index tables are left out: triggers rebuild them from
+// the salvaged memories.
+func salvageTables(ctx context.Context, conn *sql.Conn) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT name, IFNULL(sql, '') FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY rowid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var names, virtual []string
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if strings.HasPrefix(strings.ToUpper(ddl), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Drop the shadow tables virtual tables keep their data in
+	var tables []string
+	for _, name := range names {
+		shadow := false
+		for _, v := range virtual {
+			shadow = shadow || strings.HasPrefix(name, v+"_")
+		}
+		if !shadow {
+			tables = append(tables, name)
+		}
+	}
+
+	parents := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		rows, err := conn.QueryContext(ctx, `SELECT DISTINCT "table" FROM pragma_foreign_key_list(?)`, table)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var parent string
+			if err := rows.Scan(&parent); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if parent != table {
+				parents[table] = append(parents[table], parent)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	ordered := make([]string, 0, len(tables))
+	visited := make(map[string]bool, len(tables))
+	var visit func(table string)
+	visit = func(table string) {
+		if visited[table] {
+			return
+		}
+		visited[table] = true
+		for _, parent := range parents[table] {
+			if slices.Contains(tables, parent) {
+				visit(parent)
+			}
+		}
+		ordered = append(ordered, table)
+	}
+	for _, table := range tables {
+		visit(table)
+	}
+	return ordered, nil
+}
+
+// dropWebhookTriggers drops the triggers that queue webhook deliveries
+func dropWebhookTriggers(ctx context.Context, conn *sql.Conn) error {
+	rows, err := conn.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'webhook_%'`)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := conn.ExecContext(ctx, `DROP TRIGGER `+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// salvageTable copies every readable row of table, in rowid order. When a
+// damaged page interrupts the scan it resumes past the damage, skipping
+// progressively further ahead.
+func salvageTable(ctx context.Context, conn *sql.Conn, damaged *sql.DB, table string) (int, error) {
+	columns, err := tableColumns(ctx, conn, table)
+	if err != nil {
+		return 0, err
+	}
+
+	salvaged := 0
+	last := int64(-1 << 62)
+	skip := int64(1)
+	var lastErr error
+
+	for attempt := 0; attempt < 5; attempt++ {
+		rows, err := damaged.QueryContext(ctx, `SELECT rowid AS "_rowid_", * FROM `+table+` WHERE rowid > ? ORDER BY rowid`, last)
+		if err != nil {
+			lastErr = err
+			last += skip
+			skip *= 100
+			continue
+		}
+
+		names, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return salvaged, err
+		}
+		var keep []string
+		var index []int
+		for i, name := range names[1:] {
+			if columns[name] {
+				keep = append(keep, name)
+				index = append(index, i+1)
+			}
+		}
+		insert := `INSERT OR IGNORE INTO ` + table + ` (` + strings.Join(keep, ", ") + `) VALUES (?` +
+			strings.Repeat(", ?", len(keep)-1) + `)`
+
+		values := make([]interface{}, len(names))
+		ptrs := make([]interface{}, len(names))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(ptrs...); err != nil {
+				break
+			}
+			if id, ok := values[0].(int64); ok {
+				last = id
+			}
+			args := make([]interface{}, len(index))
+			for i, idx := range index {
+				args[i] = values[idx]
+			}
+			// An ignored row is one triggers or migrations already wrote
+			if _, err := conn.ExecContext(ctx, insert, args...); err == nil {
+				salvaged++
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err == nil {
+			return salvaged, nil
+		}
+
+		// Resume past the damaged region
+		lastErr = err
+		last += skip
+		skip *= 100
+	}
+	return salvaged, lastErr
+}
+
+// healthyBackup returns the newest backup that passes an integrity check
+func healthyBackup(dbPath string) string {
+	backups, err := ListBackups(dbPath)
+	if err != nil {
+		return ""
+	}
+	for _, path := range backups {
+		db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+		if err != nil {
+			continue
+		}
+		err = quickCheck(db)
+		db.Close()
+		if err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// restoreFromBackup copies backup rows missing from the recovered tables
+func restoreFromBackup(ctx context.Context, conn *sql.Conn, backup string, tables []TableRecovery) error {
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup`, backup); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE backup`)
+
+	for i := range tables {
+		t := &tables[i]
+		current, err := tableColumns(ctx, conn, t.Table)
+		if err != nil {
+			return err
+		}
+		old, err := tableColumns(ctx, conn, "backup."+t.Table)
+		if err != nil || len(old) == 0 {
+			continue // table didn't exist when the backup was taken
+		}
+		var shared []string
+		for name := range current {
+			if old[name] {
+				shared = append(shared, name)
+			}
+		}
+		cols := strings.Join(shared, ", ")
+		result, err := conn.ExecContext(ctx, `INSERT OR IGNORE INTO main.`+t.Table+` (`+cols+`) SELECT `+cols+` FROM backup.`+t.Table)
+		if err != nil {
+			return err
+		}
+		n, _ := result.RowsAffected()
+		t.FromBackup = int(n)
+	}
+	return nil
+}
+
+// tableColumns returns the column names of a table, which may be
+// schema-qualified ("backup.memories")
+func tableColumns(ctx context.Context, conn *sql.Conn, table string) (map[string]bool, error) {
+	pragma := `PRAGMA table_info(` + table + `)`
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		pragma = `PRAGMA ` + schema + `.table_info(` + name + `)`
+	}
+	rows, err := conn.QueryContext(ctx, pragma)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
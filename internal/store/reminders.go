@@ -0,0 +1,57 @@
+package store
+
+import (
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+var reminderMigrations = []string{
+	// Expiring memories the daemon has already sent a reminder for
+	`CREATE TABLE IF NOT EXISTS expiry_reminders (
+		memory_id TEXT PRIMARY KEY REFERENCES memories(id) ON DELETE CASCADE,
+		expires_at DATETIME NOT NULL, -- reminders are sent again if the expiry moves
+		reminded_at DATETIME NOT NULL
+	)`,
+}
+
+// ExpiringMemories returns approved memories expiring in [from, until),
+// soonest first
+func (s *Store) ExpiringMemories(from, until time.Time) ([]models.Memory, error) {
+	return s.expiringMemories("", from, until)
+}
+
+// UnremindedExpiring returns approved memories expiring in [from, until)
+// that no reminder has been sent for at their current expiry
+func (s *Store) UnremindedExpiring(from, until time.Time) ([]models.Memory, error) {
+	return s.expiringMemories(` AND NOT EXISTS (SELECT 1 FROM expiry_reminders r
+		WHERE r.memory_id = memories.id AND r.expires_at = memories.expires_at)`, from, until)
+}
+
+func (s *Store) expiringMemories(filter string, from, until time.Time) ([]models.Memory, error) {
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories
+		WHERE expires_at >= ? AND expires_at < ? AND `+approved+filter+`
+		ORDER BY expires_at`, from, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// MarkReminded records that a reminder was sent for a memory's current
+// expiry
+func (s *Store) MarkReminded(memoryID string, at time.Time) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO expiry_reminders (memory_id, expires_at, reminded_at)
+		SELECT id, expires_at, ? FROM memories WHERE id = ? AND expires_at IS NOT NULL`, at, memoryID)
+	return err
+}
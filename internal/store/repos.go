@@ -0,0 +1,102 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ListRepos returns the paths of all registered git repositories
+func (s *Store) ListRepos() ([]string, error) {
+	rows, err := s.db.Query(`SELECT path FROM repos ORDER BY path`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// SaveRepos registers discovered repositories and forgets ones that no longer
+// exist on disk
+func (s *Store) SaveRepos(paths []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if _, err := tx.Exec(`CREATE TEMP TABLE IF NOT EXISTS discovered (path TEXT PRIMARY KEY)`); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM discovered`); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if _, err := tx.Exec(`
+			INSERT INTO repos (path, discovered_at, last_seen) VALUES (?, ?, ?)
+			ON CONFLICT(path) DO UPDATE SET last_seen = excluded.last_seen
+		`, path, now, now); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO discovered (path) VALUES (?)`, path); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM repos WHERE path NOT IN (SELECT path FROM discovered)`); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// LastCommit returns the last commit processed for a repository, or "" if
+// none has been recorded
+func (s *Store) LastCommit(path string) (string, error) {
+	var hash sql.NullString
+	err := s.db.QueryRow(`SELECT last_commit FROM repos WHERE path = ?`, path).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return hash.String, nil
+}
+
+// SetLastCommit records the last commit processed for a repository
+func (s *Store) SetLastCommit(path, hash string) error {
+	now := time.Now()
+	_, err := s.db.Exec(`
+		INSERT INTO repos (path, last_commit, discovered_at, last_seen) VALUES (?, ?, ?, ?)
+		ON CONFLICT(path) DO UPDATE SET last_commit = excluded.last_commit, last_seen = excluded.last_seen
+	`, path, hash, now, now)
+	return err
+}
+
+// scanKeyPrefix namespaces the watchers' directory scans in sync_state
+const scanKeyPrefix = "scan:"
+
+// LoadScan returns a watcher's saved directory scan, or nil if none
+func (s *Store) LoadScan(name string) ([]byte, error) {
+	value, _, ok, err := s.GetSyncState(scanKeyPrefix + name)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// SaveScan saves a watcher's directory scan
+func (s *Store) SaveScan(name string, state []byte) error {
+	return s.SetSyncState(scanKeyPrefix+name, string(state))
+}
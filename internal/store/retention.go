@@ -0,0 +1,71 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+// ApplyRetention brings a memory's expiry forward to expiresAt under a
+// retention policy. Memories that already expire sooner, or are under
+// legal hold, are left alone.
+// It reports whether the memory changed.
+func (s *Store) ApplyRetention(id, policy string, expiresAt time.Time) (bool, error) {
+	result, err := s.db.Exec(`UPDATE memories SET expires_at = ?, retention_policy = ?
+		WHERE id = ? AND (expires_at IS NULL OR expires_at > ?) AND `+notHeld, expiresAt, policy, id, expiresAt)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// PurgeRetained deletes memories that one of the named retention policies
+// expired before now. Memories expired by a plain TTL, or by a policy that
+// has since been removed, are only hidden from recall as before.
+func (s *Store) PurgeRetained(policies []string, now time.Time) (int64, error) {
+	if len(policies) == 0 {
+		return 0, nil
+	}
+	where, args := retainedDue(policies, now)
+	return s.deleteMemories(where, args)
+}
+
+// CountRetainedDue counts the memories PurgeRetained would delete
+func (s *Store) CountRetainedDue(policies []string, now time.Time) (int64, error) {
+	if len(policies) == 0 {
+		return 0, nil
+	}
+	where, args := retainedDue(policies, now)
+	var n int64
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE `+where, args...).Scan(&n)
+	return n, err
+}
+
+func retainedDue(policies []string, now time.Time) (string, []interface{}) {
+	args := []interface{}{now}
+	for _, p := range policies {
+		args = append(args, p)
+	}
+	return `expires_at <= ? AND retention_policy IN (?` + strings.Repeat(",?", len(policies)-1) + `) AND ` + notHeld, args
+}
+
+// RetentionCounts returns how many memories each retention policy governs
+func (s *Store) RetentionCounts() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT retention_policy, COUNT(*) FROM memories
+		WHERE retention_policy IS NOT NULL GROUP BY retention_policy`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var policy string
+		var n int
+		if err := rows.Scan(&policy, &n); err != nil {
+			return nil, err
+		}
+		counts[policy] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,135 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// MaxExtractionAttempts is how many times extraction of an event is
+	// tried before it's left for 'memorypilot events retry'
+	MaxExtractionAttempts = 5
+
+	retryBackoff    = 5 * time.Minute
+	maxRetryBackoff = 6 * time.Hour
+)
+
+// RetryDelay is how long to wait before retrying extraction after the
+// given number of failed attempts, doubling with each
+func RetryDelay(attempts int) time.Duration {
+	delay := retryBackoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// FailedEvent is an event whose extraction failed, with its retry state
+type FailedEvent struct {
+	models.Event
+	Attempts    int
+	NextRetryAt *time.Time // nil once MaxExtractionAttempts is reached
+	LastError   string
+}
+
+// FailEvents records a failed extraction of events, scheduling each for
+// a retry with exponential backoff. Events that reach
+// MaxExtractionAttempts aren't retried again automatically; it returns
+// how many did.
+func (s *Store) FailEvents(events []models.Event, reason string) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	exhausted := 0
+	for _, e := range events {
+		var attempts int
+		if err := tx.QueryRow(`SELECT attempts FROM events WHERE id = ?`, e.ID).Scan(&attempts); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return 0, err
+		}
+		attempts++
+		var next interface{}
+		if attempts < MaxExtractionAttempts {
+			next = now.Add(RetryDelay(attempts))
+		} else {
+			exhausted++
+		}
+		if _, err := tx.Exec(`
+			UPDATE events SET attempts = ?, next_retry_at = ?, last_error = ?, deferred_at = NULL
+			WHERE id = ?
+		`, attempts, next, reason, e.ID); err != nil {
+			return 0, err
+		}
+	}
+	return exhausted, tx.Commit()
+}
+
+// DueRetries retrieves failed events whose retry is due, in the order
+// they failed
+func (s *Store) DueRetries(limit int) ([]models.Event, error) {
+	rows, err := s.db.Query(`
+		SELECT `+eventColumns+`
+		FROM events
+		WHERE processed_at IS NULL AND next_retry_at IS NOT NULL AND next_retry_at <= ?
+		ORDER BY next_retry_at ASC, timestamp ASC
+		LIMIT ?
+	`, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// FailedEvents retrieves the unprocessed events whose extraction failed,
+// awaiting a retry or not, oldest first
+func (s *Store) FailedEvents() ([]FailedEvent, error) {
+	rows, err := s.db.Query(`
+		SELECT id, attempts, next_retry_at, IFNULL(last_error, '')
+		FROM events
+		WHERE processed_at IS NULL AND attempts > 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]FailedEvent)
+	var ids []string
+	for rows.Next() {
+		var f FailedEvent
+		var id string
+		var next sql.NullTime
+		if err := rows.Scan(&id, &f.Attempts, &next, &f.LastError); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if next.Valid {
+			f.NextRetryAt = &next.Time
+		}
+		states[id] = f
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	events, err := s.GetEvents(ids)
+	if err != nil {
+		return nil, err
+	}
+	failed := make([]FailedEvent, len(events))
+	for i, e := range events {
+		failed[i] = states[e.ID]
+		failed[i].Event = e
+	}
+	return failed, nil
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ListPending returns memories awaiting review, oldest first
+func (s *Store) ListPending(limit int) ([]models.Memory, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories
+		WHERE status = ? ORDER BY created_at ASC LIMIT ?`, models.MemoryStatusPending, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, nil
+}
+
+// SetStatus records a review decision for a memory
+func (s *Store) SetStatus(memoryID string, status models.MemoryStatus) error {
+	result, err := s.db.Exec(`UPDATE memories SET status = ? WHERE id = ?`, status, memoryID)
+	if err != nil {
+		return heldErr(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("memory %s not found", memoryID)
+	}
+	return s.RecordLineage(memoryID, LineageEntry{Action: LineageStatus, Note: string(status)})
+}
+
+// HasSourceReference reports whether any memory, in any review state, came
+// from the given source reference. Analysis jobs use it to avoid proposing
+// the same thing twice, including things the user already rejected.
+func (s *Store) HasSourceReference(ref string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM memories WHERE source_reference = ?`, ref).Scan(&n)
+	return n > 0, err
+}
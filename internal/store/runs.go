@@ -0,0 +1,109 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+var runMigrations = []string{
+	// Every prompt sent to an extraction model, for 'memorypilot why' and
+	// for comparing models and prompts
+	`CREATE TABLE IF NOT EXISTS extraction_runs (
+		id TEXT PRIMARY KEY,
+		started_at DATETIME NOT NULL,
+		latency_ms INTEGER NOT NULL,
+		provider TEXT NOT NULL,
+		model TEXT,
+		prompt_hash TEXT NOT NULL,
+		event_ids TEXT NOT NULL,
+		response_hash TEXT,
+		accepted TEXT,
+		rejected TEXT,
+		error TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_extraction_runs_started ON extraction_runs(started_at)`,
+}
+
+// runColumns lists the columns read by scanRun, in order
+const runColumns = `id, started_at, latency_ms, provider, model, prompt_hash, prompt_version,
+	event_ids, response_hash, accepted, rejected, error`
+
+// RecordExtractionRun stores an extraction run, assigning it an ID if it
+// has none
+func (s *Store) RecordExtractionRun(run *models.ExtractionRun) error {
+	if run.ID == "" {
+		run.ID = ulid.Make().String()
+	}
+	eventIDs, _ := json.Marshal(run.EventIDs)
+	_, err := s.db.Exec(`INSERT INTO extraction_runs (`+runColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.StartedAt, run.Latency.Milliseconds(), run.Provider, nullString(run.Model),
+		run.PromptHash, run.PromptVersion, string(eventIDs), nullString(run.ResponseHash),
+		runMemories(run.Accepted), runMemories(run.Rejected), nullString(run.Error))
+	return err
+}
+
+// runMemories encodes proposed memories, or NULL for none
+func runMemories(memories []models.RunMemory) interface{} {
+	if len(memories) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(memories)
+	return string(data)
+}
+
+// GetExtractionRun returns a run by ID, or nil if there is none
+func (s *Store) GetExtractionRun(id string) (*models.ExtractionRun, error) {
+	rows, err := s.db.Query(`SELECT `+runColumns+` FROM extraction_runs WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs, err := scanRuns(rows)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// ExtractionRunsSince returns the runs started at or after since, oldest
+// first
+func (s *Store) ExtractionRunsSince(since time.Time) ([]models.ExtractionRun, error) {
+	rows, err := s.db.Query(`SELECT `+runColumns+` FROM extraction_runs WHERE started_at >= ? ORDER BY started_at`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRuns(rows)
+}
+
+// scanRuns reads rows of runColumns
+func scanRuns(rows *sql.Rows) ([]models.ExtractionRun, error) {
+	var runs []models.ExtractionRun
+	for rows.Next() {
+		var r models.ExtractionRun
+		var latency int64
+		var eventIDs string
+		var model, responseHash, accepted, rejected, runErr sql.NullString
+		if err := rows.Scan(&r.ID, &r.StartedAt, &latency, &r.Provider, &model, &r.PromptHash, &r.PromptVersion,
+			&eventIDs, &responseHash, &accepted, &rejected, &runErr); err != nil {
+			return nil, err
+		}
+		r.Latency = time.Duration(latency) * time.Millisecond
+		r.Model, r.ResponseHash, r.Error = model.String, responseHash.String, runErr.String
+		json.Unmarshal([]byte(eventIDs), &r.EventIDs)
+		if accepted.Valid {
+			json.Unmarshal([]byte(accepted.String), &r.Accepted)
+		}
+		if rejected.Valid {
+			json.Unmarshal([]byte(rejected.String), &r.Rejected)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
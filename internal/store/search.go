@@ -0,0 +1,294 @@
+package store
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Hybrid search ranks the memories found by keyword and by vector
+// similarity with one formula (see models.SearchWeights). Keyword relevance
+// is BM25 over memory_fts, an FTS4 index of each memory's content, summary
+// and topics that triggers keep current. Documents are keyed by the
+// memory's rowid, which changes when a row is replaced or its table
+// rebuilt, so the memory ID is stored alongside and checked on every join.
+
+var searchMigrations = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS memory_fts USING fts4(
+		memory_id, content, summary, topics, notindexed=memory_id, tokenize=porter
+	)`,
+
+	// Index memories written before the index existed or since renumbered,
+	// and drop documents of rows that are gone
+	`INSERT OR REPLACE INTO memory_fts (docid, memory_id, content, summary, topics)
+		SELECT m.rowid, m.id, m.content, m.summary, m.topics FROM memories m
+		WHERE NOT EXISTS (SELECT 1 FROM memory_fts f WHERE f.docid = m.rowid AND f.memory_id = m.id)`,
+	`DELETE FROM memory_fts WHERE docid NOT IN (SELECT rowid FROM memories)`,
+
+	// Writes made with OR IGNORE impose it on their triggers, so documents
+	// are deleted and inserted rather than replaced
+	`CREATE TRIGGER IF NOT EXISTS memory_fts_on_insert AFTER INSERT ON memories BEGIN
+		DELETE FROM memory_fts WHERE docid = NEW.rowid;
+		INSERT INTO memory_fts (docid, memory_id, content, summary, topics)
+			VALUES (NEW.rowid, NEW.id, NEW.content, NEW.summary, NEW.topics);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS memory_fts_on_update AFTER UPDATE OF content, summary, topics ON memories BEGIN
+		DELETE FROM memory_fts WHERE docid = NEW.rowid;
+		INSERT INTO memory_fts (docid, memory_id, content, summary, topics)
+			VALUES (NEW.rowid, NEW.id, NEW.content, NEW.summary, NEW.topics);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS memory_fts_on_delete AFTER DELETE ON memories BEGIN
+		DELETE FROM memory_fts WHERE docid = OLD.rowid;
+	END`,
+}
+
+const (
+	// maxQueryTerms bounds the words of a query matched against the index
+	maxQueryTerms = 16
+
+	// BM25 term saturation and length normalization
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// ftsColumnWeights weigh matches in each memory_fts column. Summaries and
+// topics say what a memory is about, so they count for more than content;
+// memory_id isn't indexed.
+var ftsColumnWeights = []float64{0, 1, 1.5, 1.5}
+
+// SetSearchWeights changes how HybridSearch ranks results
+func (s *Store) SetSearchWeights(w models.SearchWeights) {
+	s.weightsMu.Lock()
+	defer s.weightsMu.Unlock()
+	s.weights = w
+}
+
+// SearchWeights returns the weights HybridSearch ranks with
+func (s *Store) SearchWeights() models.SearchWeights {
+	s.weightsMu.Lock()
+	defer s.weightsMu.Unlock()
+	return s.weights
+}
+
+// HybridSearch ranks the memories matching the query's words or nearest to
+// queryEmbedding (nil ranks by keywords alone) by the store's search
+// weights. The request's scope, type, project and exclusion filters select
+// the memories both kinds of match are drawn from. Each result carries its
+// score breakdown.
+func (s *Store) HybridSearch(req models.RecallRequest, queryEmbedding []float32) ([]models.Memory, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	weights := s.SearchWeights()
+	text, _ := models.ParseQuery(req.Query)
+	where, args := recallConditions(req)
+
+	// Both kinds of match contribute a pool wider than the limit, since the
+	// other components can reorder them
+	pool := limit * 5
+	if pool < vectorPool {
+		pool = vectorPool
+	}
+	vectors := vectorWeights(weights)
+	candidates := make(map[string]ScoredMemory)
+	if len(queryEmbedding) > 0 {
+		nearest, err := s.nearestMemories(queryEmbedding, where, args, -1, pool, nil, vectors)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nearest {
+			candidates[n.ID] = n
+		}
+	}
+
+	keyword, err := s.keywordScores(text, where, args)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, id := range topScores(keyword, pool) {
+		if _, ok := candidates[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	found, err := s.fetchScored(queryEmbedding, missing, where, args, vectors)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range found {
+		candidates[m.ID] = m
+	}
+
+	// Keyword relevance is relative to the best match considered
+	var best float64
+	for id := range candidates {
+		best = math.Max(best, keyword[id])
+	}
+
+	now := time.Now()
+	results := make([]models.Memory, 0, len(candidates))
+	for id, c := range candidates {
+		score := models.SearchScore{
+			Semantic:   math.Max(0, float64(c.Similarity)),
+			Importance: c.Importance,
+			Recency:    recency(c.CreatedAt, now, weights.RecencyHalfLife),
+			Vector:     c.Vector,
+			Excerpt:    c.Excerpt,
+		}
+		if best > 0 {
+			score.Keyword = keyword[id] / best
+		}
+		score.Total = weights.Keyword*score.Keyword + weights.Semantic*score.Semantic +
+			weights.Importance*score.Importance + weights.Recency*score.Recency
+
+		m := c.Memory
+		m.Score = &score
+		results = append(results, m)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score.Total != results[j].Score.Total {
+			return results[i].Score.Total > results[j].Score.Total
+		}
+		return results[i].ID > results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	for _, m := range results {
+		s.recordAccess(m.ID)
+	}
+	return results, nil
+}
+
+// recency decays from 1 for a memory created now, halving every halfLife
+func recency(created, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 {
+		halfLife = models.DefaultSearchWeights().RecencyHalfLife
+	}
+	age := now.Sub(created)
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// topScores returns the IDs of the n highest scores, best first
+func topScores(scores map[string]float64, n int) []string {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > n {
+		ids = ids[:n]
+	}
+	return ids
+}
+
+// KeywordSearch returns the IDs of up to limit approved, unexpired
+// memories best matching the query's words by BM25 alone
+func (s *Store) KeywordSearch(query string, limit int) ([]string, error) {
+	scores, err := s.keywordScores(query, approved+` AND `+unexpired, []interface{}{time.Now()})
+	if err != nil {
+		return nil, err
+	}
+	return topScores(scores, limit), nil
+}
+
+// keywordScores returns the BM25 scores of the memories passing where
+// (a condition on memories, with args) that match any word of text, by
+// memory ID
+func (s *Store) keywordScores(text, where string, args []interface{}) (map[string]float64, error) {
+	expr := matchExpression(text)
+	if expr == "" {
+		return nil, nil
+	}
+	rows, err := s.db.Query(`SELECT memory_fts.memory_id, matchinfo(memory_fts, 'pcnalx') FROM memory_fts
+		JOIN (SELECT rowid AS rid, id FROM memories WHERE `+where+`) m
+			ON m.rid = memory_fts.docid AND m.id = memory_fts.memory_id
+		WHERE memory_fts MATCH ?`, append(append([]interface{}{}, args...), expr)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[string]float64)
+	for rows.Next() {
+		var id string
+		var info []byte
+		if err := rows.Scan(&id, &info); err != nil {
+			return nil, err
+		}
+		scores[id] = bm25(info)
+	}
+	return scores, rows.Err()
+}
+
+// matchExpression turns search text into an FTS query matching any of its
+// words, or "" if it has none
+func matchExpression(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if seen[w] || len(terms) == maxQueryTerms {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, `"`+w+`"`)
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// bm25 scores a document from its matchinfo 'pcnalx' values: phrase and
+// column counts, document count, average and actual column lengths, then
+// per phrase and column the hits in this row, in all rows, and the number
+// of rows with a hit
+func bm25(info []byte) float64 {
+	v := make([]float64, len(info)/4)
+	for i := range v {
+		v[i] = float64(binary.NativeEndian.Uint32(info[i*4:]))
+	}
+	if len(v) < 3 {
+		return 0
+	}
+	phrases, columns, docs := int(v[0]), int(v[1]), v[2]
+	if len(v) < 3+2*columns+3*phrases*columns {
+		return 0
+	}
+	avgLength := v[3 : 3+columns]
+	length := v[3+columns : 3+2*columns]
+	hits := v[3+2*columns:]
+
+	var score float64
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns && c < len(ftsColumnWeights); c++ {
+			x := hits[3*(p*columns+c):]
+			tf, df := x[0], x[2]
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (docs-df+0.5)/(df+0.5))
+			norm := 1 - bm25B
+			if avgLength[c] > 0 {
+				norm += bm25B * length[c] / avgLength[c]
+			}
+			score += ftsColumnWeights[c] * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return score
+}
@@ -0,0 +1,209 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// SessionTTL is how long a session memory lasts unless its writer
+	// asks for less or more
+	SessionTTL = 4 * time.Hour
+
+	// MaxSessionTTL bounds a session memory's lifetime; anything meant to
+	// last longer belongs in the durable store
+	MaxSessionTTL = 24 * time.Hour
+)
+
+// ErrNoSession is returned when a session memory isn't tied to a session
+var ErrNoSession = errors.New("session memories need a session ID")
+
+var sessionMigrations = []string{
+	// Scratch context kept by AI tools during a conversation. It lives
+	// apart from memories so it never reaches stats, sync or exports.
+	`CREATE TABLE IF NOT EXISTS session_memories (
+		id TEXT PRIMARY KEY,
+		session_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		content TEXT NOT NULL,
+		topics TEXT,
+		embedding BLOB,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_session_memories_session ON session_memories(session_id, created_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_session_memories_expires ON session_memories(expires_at)`,
+}
+
+// CreateSessionMemory stores a session memory, assigning its ID and
+// lifetime if unset; lifetimes are capped at MaxSessionTTL. Expired
+// session memories are pruned along the way.
+func (s *Store) CreateSessionMemory(m *models.SessionMemory) error {
+	if m.SessionID == "" {
+		return ErrNoSession
+	}
+	if m.ID == "" {
+		m.ID = ulid.Make().String()
+	}
+	if m.Type == "" {
+		m.Type = models.MemoryTypeContext
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	if m.ExpiresAt.IsZero() {
+		m.ExpiresAt = m.CreatedAt.Add(SessionTTL)
+	}
+	if latest := m.CreatedAt.Add(MaxSessionTTL); m.ExpiresAt.After(latest) {
+		m.ExpiresAt = latest
+	}
+
+	if _, err := s.PruneSessionMemories(time.Now()); err != nil {
+		return err
+	}
+
+	topicsJSON, _ := json.Marshal(m.Topics)
+	var embedding []byte
+	if len(m.Embedding) > 0 {
+		embedding = encodeEmbedding(m.Embedding)
+	}
+	_, err := s.db.Exec(`INSERT INTO session_memories (id, session_id, type, content, topics, embedding, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.ID, m.SessionID, m.Type, m.Content, string(topicsJSON), embedding, m.CreatedAt, m.ExpiresAt)
+	return err
+}
+
+// SessionMemories returns a session's unexpired memories, newest first
+func (s *Store) SessionMemories(sessionID string) ([]models.SessionMemory, error) {
+	rows, err := s.db.Query(`SELECT `+sessionMemoryColumns+`
+		FROM session_memories WHERE session_id = ? AND expires_at > ? ORDER BY created_at DESC`,
+		sessionID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return scanSessionMemories(rows)
+}
+
+// SessionMemoriesToReview returns unexpired session memories not yet
+// reviewed for graduation that expire before the given time (zero for
+// any), oldest first. sessionID narrows them to one session ("" for all).
+func (s *Store) SessionMemoriesToReview(sessionID string, before time.Time) ([]models.SessionMemory, error) {
+	query := `SELECT ` + sessionMemoryColumns + ` FROM session_memories
+		WHERE reviewed_at IS NULL AND expires_at > ?`
+	args := []interface{}{time.Now()}
+	if !before.IsZero() {
+		query += " AND expires_at <= ?"
+		args = append(args, before)
+	}
+	if sessionID != "" {
+		query += " AND session_id = ?"
+		args = append(args, sessionID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY session_id, created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSessionMemories(rows)
+}
+
+// MarkSessionMemoriesReviewed records that session memories were reviewed
+// for graduation, so they aren't reviewed again
+func (s *Store) MarkSessionMemoriesReviewed(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := []interface{}{time.Now()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	_, err := s.db.Exec(`UPDATE session_memories SET reviewed_at = ? WHERE id IN (`+placeholders+`)`, args...)
+	return err
+}
+
+const sessionMemoryColumns = `id, session_id, type, content, topics, embedding, created_at, expires_at`
+
+func scanSessionMemories(rows *sql.Rows) ([]models.SessionMemory, error) {
+	defer rows.Close()
+
+	var memories []models.SessionMemory
+	for rows.Next() {
+		var m models.SessionMemory
+		var topicsJSON string
+		var embedding []byte
+		if err := rows.Scan(&m.ID, &m.SessionID, &m.Type, &m.Content, &topicsJSON, &embedding, &m.CreatedAt, &m.ExpiresAt); err != nil {
+			return nil, err
+		}
+		json.Unmarshal([]byte(topicsJSON), &m.Topics)
+		if len(embedding) > 0 {
+			m.Embedding = decodeEmbedding(embedding)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// RecallSession returns up to limit of a session's memories matching the
+// query, best first: by similarity to queryEmbedding where both have an
+// embedding, and otherwise by the share of query words they mention. An
+// empty query returns the newest.
+func (s *Store) RecallSession(sessionID, query string, queryEmbedding []float32, limit int) ([]models.SessionMemory, error) {
+	memories, err := s.SessionMemories(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) > 0 {
+		type scored struct {
+			memory models.SessionMemory
+			score  float64
+		}
+		var matches []scored
+		for _, m := range memories {
+			text := strings.ToLower(m.Content + " " + strings.Join(m.Topics, " "))
+			var hits int
+			for _, w := range words {
+				if strings.Contains(text, w) {
+					hits++
+				}
+			}
+			score := float64(hits) / float64(len(words))
+			if len(queryEmbedding) > 0 && len(m.Embedding) > 0 {
+				score = max(score, float64(cosineSimilarity(queryEmbedding, m.Embedding)))
+			}
+			if score > 0 {
+				matches = append(matches, scored{m, score})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+		memories = memories[:0]
+		for _, m := range matches {
+			memories = append(memories, m.memory)
+		}
+	}
+
+	if len(memories) > limit {
+		memories = memories[:limit]
+	}
+	return memories, nil
+}
+
+// PruneSessionMemories deletes session memories that expired before now
+func (s *Store) PruneSessionMemories(now time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM session_memories WHERE expires_at <= ?`, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
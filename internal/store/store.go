@@ -1,53 +1,161 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/memorypilot/memorypilot/pkg/models"
 )
 
+// MemoryPath opens a private in-memory database when passed to New, for
+// tests and embedding without touching the filesystem
+const MemoryPath = ":memory:"
+
+// migrateAttempts is how many times New tries to migrate a database other
+// processes keep locked
+const migrateAttempts = 3
+
 // Store handles all database operations
 type Store struct {
-	db *sql.DB
+	db      *sql.DB
+	path    string
+	tempDir string // removed on Close; set by NewTemp
+	vectors vectorIndex
+
+	weightsMu sync.Mutex
+	weights   models.SearchWeights // HybridSearch ranking
+
+	modelMu        sync.Mutex
+	embeddingModel string // labels embeddings this process writes
 }
 
 // Stats represents store statistics
 type Stats struct {
-	TotalMemories int            `json:"totalMemories"`
-	ByType        map[string]int `json:"byType"`
-	ProjectCount  int            `json:"projectCount"`
-	DaemonRunning bool           `json:"daemonRunning"`
+	TotalMemories  int            `json:"totalMemories"`
+	ByType         map[string]int `json:"byType"`
+	ProjectCount   int            `json:"projectCount"`
+	PendingCount   int            `json:"pendingCount"`
+	DeferredEvents int            `json:"deferredEvents"`
+	RetryEvents    int            `json:"retryEvents"`    // events whose failed extraction will be retried
+	FailedEvents   int            `json:"failedEvents"`   // events that failed MaxExtractionAttempts times
+	MergedCount    int            `json:"mergedCount"`    // duplicate inserts folded into existing memories
+	ArchivedCount  int            `json:"archivedCount"`  // expired memories kept out of recall
+	ReprocessCount int            `json:"reprocessCount"` // memories from older prompts flagged for reprocessing
+	RepeatedEvents int            `json:"repeatedEvents"` // identical events folded into earlier ones
+	DaemonRunning  bool           `json:"daemonRunning"`
+	CaptureAlerts  []CaptureAlert `json:"captureAlerts,omitempty"` // watchers that stopped producing events
 }
 
-// New creates a new store instance
+// New creates a new store instance. dbPath may be MemoryPath.
 func New(dbPath string) (*Store, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	// Transactions take the write lock when they begin, so processes
+	// sharing the file wait on busy_timeout instead of failing when a
+	// transaction that has read tries to write
+	dsn := dbPath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on&_txlock=immediate"
+	if dbPath == MemoryPath {
+		dsn = "file::memory:?_busy_timeout=5000&_foreign_keys=on"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	s := &Store{db: db}
-	if err := s.migrate(); err != nil {
+	// Every connection to :memory: is a separate database, so keep exactly
+	// one open for the lifetime of the store
+	if dbPath == MemoryPath {
+		db.SetMaxOpenConns(1)
+		db.SetMaxIdleConns(1)
+		db.SetConnMaxLifetime(0)
+	}
+
+	s := &Store{db: db, path: dbPath, weights: models.DefaultSearchWeights()}
+	if err := s.migrateRetrying(); err != nil {
 		db.Close()
+		if IsCorrupt(err) {
+			return nil, fmt.Errorf("%w: %v (run 'memorypilot doctor --fix' to repair)", ErrCorrupt, err)
+		}
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	return s, nil
 }
 
+// NewTemp creates a store in a new temporary directory, which is removed
+// when the store is closed
+func NewTemp() (*Store, error) {
+	dir, err := os.MkdirTemp("", "memorypilot-")
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(filepath.Join(dir, "memories.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	s.tempDir = dir
+	return s, nil
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
-	return s.db.Close()
+	err := s.db.Close()
+	if s.tempDir != "" {
+		os.RemoveAll(s.tempDir)
+	}
+	return err
+}
+
+// migrateRetrying migrates, retrying while other processes opening the
+// same database hold it locked for longer than busy_timeout
+func (s *Store) migrateRetrying() error {
+	var err error
+	for attempt := 0; attempt < migrateAttempts; attempt++ {
+		if err = s.migrate(); err == nil || !isBusy(err) {
+			return err
+		}
+		time.Sleep(time.Duration(attempt+1) * time.Second)
+	}
+	return err
+}
+
+// isBusy reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED
+func isBusy(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
 }
 
 // migrate runs database migrations
 func (s *Store) migrate() error {
+	// Widen CHECK constraints on tables created by older versions
+	checks := []struct {
+		table, old, new string
+	}{
+		{"memories", "'mistake','learning')", "'mistake','learning','context')"},
+
+		// Deleting a project detaches its memories and events and drops its facts
+		{"memories", "project_id TEXT REFERENCES projects(id),", "project_id TEXT REFERENCES projects(id) ON DELETE SET NULL,"},
+		{"events", "project_id TEXT REFERENCES projects(id),", "project_id TEXT REFERENCES projects(id) ON DELETE SET NULL,"},
+		{"project_facts", "project_id TEXT NOT NULL,", "project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,"},
+	}
+
+	for _, c := range checks {
+		if err := s.rewriteTable(c.table, c.old, c.new); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
 	migrations := []string{
 		// Projects table
 		`CREATE TABLE IF NOT EXISTS projects (
@@ -62,11 +170,11 @@ func (s *Store) migrate() error {
 		// Memories table
 		`CREATE TABLE IF NOT EXISTS memories (
 			id TEXT PRIMARY KEY,
-			type TEXT NOT NULL CHECK (type IN ('decision','pattern','fact','preference','mistake','learning')),
+			type TEXT NOT NULL CHECK (type IN ('decision','pattern','fact','preference','mistake','learning','context')),
 			content TEXT NOT NULL,
 			summary TEXT NOT NULL,
 			scope TEXT NOT NULL DEFAULT 'personal' CHECK (scope IN ('personal','project','team','org')),
-			project_id TEXT REFERENCES projects(id),
+			project_id TEXT REFERENCES projects(id) ON DELETE SET NULL,
 			team_id TEXT,
 			
 			source_type TEXT NOT NULL,
@@ -92,10 +200,41 @@ func (s *Store) migrate() error {
 			type TEXT NOT NULL,
 			timestamp DATETIME NOT NULL,
 			data TEXT,
-			project_id TEXT REFERENCES projects(id),
+			project_id TEXT REFERENCES projects(id) ON DELETE SET NULL,
 			processed_at DATETIME
 		)`,
 
+		// Registry of discovered git repositories
+		`CREATE TABLE IF NOT EXISTS repos (
+			path TEXT PRIMARY KEY,
+			discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_seen DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Sync state (key/value bookkeeping for team sync)
+		`CREATE TABLE IF NOT EXISTS sync_state (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Local annotations on memories (kept apart from synced rows)
+		`CREATE TABLE IF NOT EXISTS annotations (
+			memory_id TEXT PRIMARY KEY,
+			pinned INTEGER NOT NULL DEFAULT 0,
+			feedback TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Structured facts detected from project manifests
+		`CREATE TABLE IF NOT EXISTS project_facts (
+			project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+			kind TEXT NOT NULL,
+			value TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, kind, value)
+		)`,
+
 		// Indexes
 		`CREATE INDEX IF NOT EXISTS idx_memories_project ON memories(project_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_memories_type ON memories(type)`,
@@ -110,9 +249,179 @@ func (s *Store) migrate() error {
 		}
 	}
 
+	// Columns added after the initial schema
+	columns := []struct {
+		table, name, decl string
+	}{
+		{"memories", "clock", "TEXT"},
+		{"memories", "field_stamps", "TEXT"},
+		{"memories", "signature", "TEXT"},
+		{"memories", "signer", "TEXT"},
+		{"repos", "last_commit", "TEXT"},
+		{"memories", "status", "TEXT NOT NULL DEFAULT 'approved'"},
+		{"memories", "provider", "TEXT"},
+		{"events", "deferred_at", "DATETIME"},
+		{"memories", "content_hash", "TEXT"},
+		{"memories", "merged_count", "INTEGER NOT NULL DEFAULT 0"},
+		{"events", "data_encoding", "TEXT"},
+		{"events", "data_size", "INTEGER"},
+		{"memories", "author", "TEXT"},
+		{"memories", "maintainer", "TEXT"},
+		{"memories", "retention_policy", "TEXT"},
+		{"events", "content_hash", "TEXT"},
+		{"events", "repeat_count", "INTEGER NOT NULL DEFAULT 1"},
+		{"events", "last_seen", "DATETIME"},
+		{"events", "sampled", "INTEGER NOT NULL DEFAULT 0"},
+		{"memories", "prompt_version", "INTEGER"},
+		{"memories", "reprocess_flagged", "INTEGER NOT NULL DEFAULT 0"},
+		{"memories", "version", "INTEGER NOT NULL DEFAULT 1"},
+		{"memories", "embedding_model", "TEXT"},
+		{"memories", "embedding_dim", "INTEGER"},
+		{"events", "attempts", "INTEGER NOT NULL DEFAULT 0"},
+		{"events", "next_retry_at", "DATETIME"},
+		{"events", "last_error", "TEXT"},
+	}
+
+	for _, c := range columns {
+		if err := s.addColumn(c.table, c.name, c.decl); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
+	// Identical memories can't be stored twice for the same project and type
+	if err := s.backfillContentHashes(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if _, err := s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_memories_content_hash
+		ON memories(IFNULL(project_id, ''), type, content_hash)`); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_events_content_hash
+		ON events(content_hash, timestamp)`); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+
+	// Tables and triggers of optional features
+	if err := s.upgradeVectorIndex(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	for _, group := range [][]string{vectorMigrations, webhookMigrations, publishMigrations, reminderMigrations, insightsMigrations, holdMigrations, yieldMigrations, lineageMigrations, runMigrations, idempotencyMigrations, versionMigrations, searchMigrations, sessionMigrations, embeddingMigrations, multiVectorMigrations} {
+		for _, migration := range group {
+			if _, err := s.db.Exec(migration); err != nil {
+				return fmt.Errorf("migration failed: %w", err)
+			}
+		}
+	}
+
+	// Columns added to feature tables after they first shipped
+	for _, c := range []struct{ table, name, decl string }{
+		{"memory_lineage", "run_id", "TEXT"},
+		{"extraction_runs", "prompt_version", "INTEGER NOT NULL DEFAULT 0"},
+		{"session_memories", "reviewed_at", "DATETIME"},
+	} {
+		if err := s.addColumn(c.table, c.name, c.decl); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// rewriteTable rebuilds a table with old replaced by new in its schema, for
+// changes SQLite can't make with ALTER TABLE (such as CHECK constraints).
+// Tables whose schema doesn't contain old are left alone. Foreign keys are
+// off during the rebuild so existing orphans are copied rather than
+// rejected; 'memorypilot doctor --fix' repairs them.
+func (s *Store) rewriteTable(table, old, new string) error {
+	var schema string
+	err := s.db.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&schema)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(schema, old) {
+		return nil
+	}
+
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Another process may have rebuilt it while this one waited for the lock
+	if err := tx.QueryRow(`SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&schema); err != nil {
+		return err
+	}
+	if !strings.Contains(schema, old) {
+		return nil
+	}
+
+	// Stored schemas start with CREATE TABLE <name> (...); swap in a temp name
+	open := strings.Index(schema, "(")
+	if open < 0 {
+		return fmt.Errorf("unexpected schema for %s", table)
+	}
+	rebuilt := "CREATE TABLE " + table + "_rebuild " + strings.Replace(schema[open:], old, new, 1)
+
+	statements := []string{
+		rebuilt,
+		"INSERT INTO " + table + "_rebuild SELECT * FROM " + table,
+		"DROP TABLE " + table,
+		"ALTER TABLE " + table + "_rebuild RENAME TO " + table,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("rebuild of %s failed: %w", table, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// addColumn adds a column to a table unless it already exists
+func (s *Store) addColumn(table, name, decl string) error {
+	rows, err := s.db.Query("PRAGMA table_info(" + table + ")")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var colName, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if colName == name {
+			return nil
+		}
+	}
+	rows.Close()
+
+	_, err = s.db.Exec("ALTER TABLE " + table + " ADD COLUMN " + name + " " + decl)
+	if err != nil && strings.Contains(err.Error(), "duplicate column name") {
+		// Another process opening the database added it first
+		return nil
+	}
+	return err
+}
+
 // GetStats returns store statistics
 func (s *Store) GetStats() (*Stats, error) {
 	stats := &Stats{
@@ -120,13 +429,13 @@ func (s *Store) GetStats() (*Stats, error) {
 	}
 
 	// Total memories
-	row := s.db.QueryRow("SELECT COUNT(*) FROM memories")
+	row := s.db.QueryRow("SELECT COUNT(*) FROM memories WHERE " + approved)
 	if err := row.Scan(&stats.TotalMemories); err != nil {
 		return nil, err
 	}
 
 	// By type
-	rows, err := s.db.Query("SELECT type, COUNT(*) FROM memories GROUP BY type")
+	rows, err := s.db.Query("SELECT type, COUNT(*) FROM memories WHERE " + approved + " GROUP BY type")
 	if err != nil {
 		return nil, err
 	}
@@ -141,84 +450,227 @@ func (s *Store) GetStats() (*Stats, error) {
 		stats.ByType[memType] = count
 	}
 
+	// Awaiting review
+	row = s.db.QueryRow("SELECT COUNT(*) FROM memories WHERE status = ?", models.MemoryStatusPending)
+	if err := row.Scan(&stats.PendingCount); err != nil {
+		return nil, err
+	}
+
+	// Expired and archived
+	row = s.db.QueryRow("SELECT COUNT(*) FROM memories WHERE status = ?", models.MemoryStatusArchived)
+	if err := row.Scan(&stats.ArchivedCount); err != nil {
+		return nil, err
+	}
+
+	// From older extraction prompts, awaiting reprocessing
+	row = s.db.QueryRow("SELECT COUNT(*) FROM memories WHERE reprocess_flagged = 1 AND " + approved)
+	if err := row.Scan(&stats.ReprocessCount); err != nil {
+		return nil, err
+	}
+
+	// Duplicate inserts absorbed by the content hash
+	row = s.db.QueryRow("SELECT IFNULL(SUM(merged_count), 0) FROM memories")
+	if err := row.Scan(&stats.MergedCount); err != nil {
+		return nil, err
+	}
+
+	// Identical events absorbed at ingest
+	row = s.db.QueryRow("SELECT IFNULL(SUM(repeat_count - 1), 0) FROM events")
+	if err := row.Scan(&stats.RepeatedEvents); err != nil {
+		return nil, err
+	}
+
+	// Events awaiting extraction
+	row = s.db.QueryRow("SELECT COUNT(*) FROM events WHERE processed_at IS NULL AND deferred_at IS NOT NULL")
+	if err := row.Scan(&stats.DeferredEvents); err != nil {
+		return nil, err
+	}
+
+	// Events whose extraction failed
+	row = s.db.QueryRow(`SELECT IFNULL(SUM(next_retry_at IS NOT NULL), 0), IFNULL(SUM(next_retry_at IS NULL), 0)
+		FROM events WHERE processed_at IS NULL AND attempts > 0`)
+	if err := row.Scan(&stats.RetryEvents, &stats.FailedEvents); err != nil {
+		return nil, err
+	}
+
 	// Project count
 	row = s.db.QueryRow("SELECT COUNT(*) FROM projects")
 	if err := row.Scan(&stats.ProjectCount); err != nil {
 		return nil, err
 	}
 
+	// Watchers the daemon found silent
+	alerts, err := s.CaptureAlerts()
+	if err != nil {
+		return nil, err
+	}
+	stats.CaptureAlerts = alerts
+
 	return stats, nil
 }
 
-// CreateMemory stores a new memory
+// CreateMemory stores a new memory. Returns ErrDuplicate if an identical
+// memory already exists for the same project and type.
 func (s *Store) CreateMemory(m *models.Memory) error {
+	hash := ContentHash(m.Content)
+	inserted, err := s.writeMemory("INSERT", "ON CONFLICT DO NOTHING", m, hash)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return s.mergeDuplicate(m, hash)
+	}
+	return nil
+}
+
+// UpdateMemory saves edits to a memory's type, content, summary, scope,
+// project, topics, expiry, maintainer, importance, confidence and
+// embedding. m.Version must be the
+// version the edits were based on: ErrConflict is returned if it has been
+// edited since, and m.Version is set to the new version on success.
+// Returns ErrDuplicate if the edit would make it identical to another
+// memory of the same project and type. The changed fields are recorded in
+// the memory's lineage, and stamped in its vector clock under DeviceID so
+// team sync merges them with edits made elsewhere.
+func (s *Store) UpdateMemory(m *models.Memory) error {
+	existing, err := s.GetMemory(m.ID)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("memory %s not found", m.ID)
+	}
+	if existing.Version != m.Version {
+		return &ConflictError{Current: existing.Version}
+	}
+	if err := s.stampEdit(existing, m); err != nil {
+		return err
+	}
+	clockJSON, stampsJSON := encodeClock(m)
+
+	topicsJSON, _ := json.Marshal(m.Topics)
+	var embedding []byte
+	if len(m.Embedding) > 0 {
+		embedding = encodeEmbedding(m.Embedding)
+	}
+	model, dim := s.embeddingLabel(m.Embedding)
+
+	// An unchanged embedding keeps the model it was labelled with
+	result, err := s.db.Exec(`
+		UPDATE OR IGNORE memories SET
+			type = ?, content = ?, summary = ?, scope = ?, project_id = ?,
+			topics = ?, expires_at = ?, maintainer = ?, importance = ?, confidence = ?,
+			embedding = ?, content_hash = ?,
+			embedding_model = CASE WHEN embedding IS ? THEN embedding_model ELSE ? END, embedding_dim = ?,
+			clock = ?, field_stamps = ?
+		WHERE id = ? AND version = ?
+	`, m.Type, m.Content, m.Summary, m.Scope, m.ProjectID,
+		string(topicsJSON), m.ExpiresAt, nullString(m.Maintainer), m.Importance, m.Confidence,
+		embedding, ContentHash(m.Content),
+		embedding, model, dim, clockJSON, stampsJSON, m.ID, m.Version)
+	if err != nil {
+		return heldErr(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// Nothing was written: either it was edited since it was read, or
+		// the unique content hash index rejected the edit
+		if current, err := s.memoryVersion(m.ID); err == nil && current != m.Version {
+			return &ConflictError{Current: current}
+		}
+		return ErrDuplicate
+	}
+	if m.Version, err = s.memoryVersion(m.ID); err != nil {
+		return err
+	}
+	if fields := changedFields(existing, m); len(fields) > 0 {
+		return s.RecordLineage(m.ID, LineageEntry{Action: LineageEdited, Note: strings.Join(fields, ", ")})
+	}
+	return nil
+}
+
+// writeMemory inserts a memory using the given verb (INSERT, INSERT OR
+// IGNORE, ...) and optional upsert clause, reporting whether a row was
+// written. hash may be nil to leave the memory out of duplicate detection.
+func (s *Store) writeMemory(verb, upsert string, m *models.Memory, hash interface{}) (bool, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(memoryRowColumns)), ", ")
+	result, err := s.db.Exec(verb+` INTO memories (`+strings.Join(memoryRowColumns, ", ")+`)
+		VALUES (`+placeholders+`) `+upsert, s.memoryRow(m, hash)...)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// memoryRowColumns are the columns memoryRow holds values for, in order
+var memoryRowColumns = []string{
+	"id", "type", "content", "summary", "scope", "project_id", "team_id",
+	"source_type", "source_reference", "source_timestamp",
+	"confidence", "importance", "topics", "related_memories", "embedding", "embedding_model", "embedding_dim",
+	"created_at", "last_accessed_at", "access_count", "expires_at",
+	"clock", "field_stamps", "signature", "signer", "status", "provider", "content_hash",
+	"author", "maintainer", "prompt_version", "version",
+}
+
+// memoryRow returns the values of memoryRowColumns for a memory, defaulting
+// its status and version
+func (s *Store) memoryRow(m *models.Memory, hash interface{}) []interface{} {
 	topicsJSON, _ := json.Marshal(m.Topics)
 	relatedJSON, _ := json.Marshal(m.RelatedMemories)
+	clockJSON, stampsJSON := encodeClock(m)
 
-	_, err := s.db.Exec(`
-		INSERT INTO memories (
-			id, type, content, summary, scope, project_id, team_id,
-			source_type, source_reference, source_timestamp,
-			confidence, importance, topics, related_memories, embedding,
-			created_at, last_accessed_at, access_count, expires_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
+	if m.Status == "" {
+		m.Status = models.MemoryStatusApproved
+	}
+	if m.Version < 1 {
+		m.Version = 1
+	}
+
+	var embedding []byte
+	if len(m.Embedding) > 0 {
+		embedding = encodeEmbedding(m.Embedding)
+	}
+	model, dim := s.embeddingLabel(m.Embedding)
+
+	return []interface{}{
 		m.ID, m.Type, m.Content, m.Summary, m.Scope, m.ProjectID, m.TeamID,
 		m.Source.Type, m.Source.Reference, m.Source.Timestamp,
-		m.Confidence, m.Importance, string(topicsJSON), string(relatedJSON), nil,
+		m.Confidence, m.Importance, string(topicsJSON), string(relatedJSON), embedding, model, dim,
 		m.CreatedAt, m.LastAccessedAt, m.AccessCount, m.ExpiresAt,
-	)
-
-	return err
+		clockJSON, stampsJSON, nullString(m.Signature), nullString(m.Signer), m.Status,
+		nullString(m.Provider), hash,
+		nullString(m.Author), nullString(m.Maintainer), nullInt(m.PromptVersion), m.Version,
+	}
 }
 
-// Recall searches memories based on the request
-func (s *Store) Recall(req models.RecallRequest) ([]models.Memory, error) {
-	// Build query
-	query := `
-		SELECT id, type, content, summary, scope, project_id, team_id,
-			   source_type, source_reference, source_timestamp,
-			   confidence, importance, topics, related_memories,
-			   created_at, last_accessed_at, access_count, expires_at
-		FROM memories
-		WHERE 1=1
-	`
-	args := []interface{}{}
-
-	// Add filters
-	if len(req.Scope) > 0 {
-		placeholders := ""
-		for i, scope := range req.Scope {
-			if i > 0 {
-				placeholders += ","
-			}
-			placeholders += "?"
-			args = append(args, scope)
-		}
-		query += " AND scope IN (" + placeholders + ")"
+// nullString maps empty strings to NULL
+func nullString(v string) interface{} {
+	if v == "" {
+		return nil
 	}
+	return v
+}
 
-	if len(req.Types) > 0 {
-		placeholders := ""
-		for i, t := range req.Types {
-			if i > 0 {
-				placeholders += ","
-			}
-			placeholders += "?"
-			args = append(args, t)
-		}
-		query += " AND type IN (" + placeholders + ")"
+// nullInt maps zero to NULL
+func nullInt(v int) interface{} {
+	if v == 0 {
+		return nil
 	}
+	return v
+}
 
-	if req.ProjectID != nil {
-		query += " AND (project_id = ? OR project_id IS NULL)"
-		args = append(args, *req.ProjectID)
-	}
+// Recall searches memories based on the request
+func (s *Store) Recall(req models.RecallRequest) ([]models.Memory, error) {
+	// "-term" words in the query are exclusions, not search text
+	text, _ := models.ParseQuery(req.Query)
+	where, args := recallConditions(req)
+	query := `SELECT ` + memoryColumns + ` FROM memories WHERE ` + where
 
 	// Text search (basic for now, will add vector search later)
-	if req.Query != "" {
+	if text != "" {
 		query += " AND (content LIKE ? OR summary LIKE ? OR topics LIKE ?)"
-		searchTerm := "%" + req.Query + "%"
+		searchTerm := "%" + text + "%"
 		args = append(args, searchTerm, searchTerm, searchTerm)
 	}
 
@@ -242,44 +694,155 @@ func (s *Store) Recall(req models.RecallRequest) ([]models.Memory, error) {
 
 	var memories []models.Memory
 	for rows.Next() {
-		var m models.Memory
-		var topicsJSON, relatedJSON sql.NullString
-		var projectID, teamID sql.NullString
-		var expiresAt sql.NullTime
-
-		err := rows.Scan(
-			&m.ID, &m.Type, &m.Content, &m.Summary, &m.Scope, &projectID, &teamID,
-			&m.Source.Type, &m.Source.Reference, &m.Source.Timestamp,
-			&m.Confidence, &m.Importance, &topicsJSON, &relatedJSON,
-			&m.CreatedAt, &m.LastAccessedAt, &m.AccessCount, &expiresAt,
-		)
+		m, err := scanMemory(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		if projectID.Valid {
-			m.ProjectID = &projectID.String
-		}
-		if teamID.Valid {
-			m.TeamID = &teamID.String
-		}
-		if expiresAt.Valid {
-			m.ExpiresAt = &expiresAt.Time
+		memories = append(memories, m)
+	}
+	rows.Close()
+
+	// Record access once the rows are read; in-memory stores have a
+	// single connection
+	for _, m := range memories {
+		s.recordAccess(m.ID)
+	}
+
+	return memories, nil
+}
+
+// recallConditions returns the SQL condition on memories, with its
+// arguments, for the approved, unexpired memories passing the request's
+// scope, type, project and exclusion filters (including "-term" words in
+// its query)
+func recallConditions(req models.RecallRequest) (string, []interface{}) {
+	query := approved + ` AND ` + unexpired
+	args := []interface{}{time.Now()}
+
+	if len(req.Scope) > 0 {
+		query += " AND scope IN (?" + strings.Repeat(",?", len(req.Scope)-1) + ")"
+		for _, scope := range req.Scope {
+			args = append(args, scope)
 		}
-		if topicsJSON.Valid {
-			json.Unmarshal([]byte(topicsJSON.String), &m.Topics)
+	}
+
+	if len(req.Types) > 0 {
+		query += " AND type IN (?" + strings.Repeat(",?", len(req.Types)-1) + ")"
+		for _, t := range req.Types {
+			args = append(args, t)
 		}
-		if relatedJSON.Valid {
-			json.Unmarshal([]byte(relatedJSON.String), &m.RelatedMemories)
+	}
+
+	if req.ProjectID != nil {
+		query += " AND (project_id = ? OR project_id IS NULL)"
+		args = append(args, *req.ProjectID)
+	}
+
+	if len(req.ExcludeTypes) > 0 {
+		query += " AND type NOT IN (?" + strings.Repeat(",?", len(req.ExcludeTypes)-1) + ")"
+		for _, t := range req.ExcludeTypes {
+			args = append(args, t)
 		}
+	}
 
-		memories = append(memories, m)
+	for _, topic := range req.ExcludeTopics {
+		query += " AND NOT EXISTS (SELECT 1 FROM json_each(IFNULL(memories.topics, '[]')) WHERE lower(value) = lower(?))"
+		args = append(args, topic)
+	}
 
-		// Record access
-		s.recordAccess(m.ID)
+	_, excludeTerms := models.ParseQuery(req.Query)
+	for _, term := range append(excludeTerms, req.ExcludeTerms...) {
+		query += " AND content NOT LIKE ? AND IFNULL(summary, '') NOT LIKE ? AND IFNULL(topics, '') NOT LIKE ?"
+		excludeTerm := "%" + term + "%"
+		args = append(args, excludeTerm, excludeTerm, excludeTerm)
 	}
 
-	return memories, nil
+	return query, args
+}
+
+// approved restricts a query to reviewed memories; pending and rejected
+// memories are only visible through the review queue
+const approved = `status = 'approved'`
+
+// memoryColumns lists the columns read by scanMemory, in order
+const memoryColumns = `id, type, content, summary, scope, project_id, team_id,
+	source_type, source_reference, source_timestamp,
+	confidence, importance, topics, related_memories,
+	created_at, last_accessed_at, access_count, expires_at,
+	clock, field_stamps, signature, signer, status, provider,
+	author, maintainer, prompt_version, version`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMemory reads a memory selected with memoryColumns
+func scanMemory(row rowScanner) (models.Memory, error) {
+	var m models.Memory
+	var topicsJSON, relatedJSON sql.NullString
+	var projectID, teamID sql.NullString
+	var expiresAt sql.NullTime
+	var clockJSON, stampsJSON sql.NullString
+	var signature, signer, provider sql.NullString
+	var author, maintainer sql.NullString
+	var promptVersion sql.NullInt64
+
+	err := row.Scan(
+		&m.ID, &m.Type, &m.Content, &m.Summary, &m.Scope, &projectID, &teamID,
+		&m.Source.Type, &m.Source.Reference, &m.Source.Timestamp,
+		&m.Confidence, &m.Importance, &topicsJSON, &relatedJSON,
+		&m.CreatedAt, &m.LastAccessedAt, &m.AccessCount, &expiresAt,
+		&clockJSON, &stampsJSON, &signature, &signer, &m.Status, &provider,
+		&author, &maintainer, &promptVersion, &m.Version,
+	)
+	if err != nil {
+		return m, err
+	}
+	m.PromptVersion = int(promptVersion.Int64)
+	m.Signature = signature.String
+	m.Signer = signer.String
+	m.Provider = provider.String
+	m.Author = author.String
+	m.Maintainer = maintainer.String
+
+	if projectID.Valid {
+		m.ProjectID = &projectID.String
+	}
+	if teamID.Valid {
+		m.TeamID = &teamID.String
+	}
+	if expiresAt.Valid {
+		m.ExpiresAt = &expiresAt.Time
+	}
+	if topicsJSON.Valid {
+		json.Unmarshal([]byte(topicsJSON.String), &m.Topics)
+	}
+	if relatedJSON.Valid {
+		json.Unmarshal([]byte(relatedJSON.String), &m.RelatedMemories)
+	}
+	if clockJSON.Valid {
+		json.Unmarshal([]byte(clockJSON.String), &m.Clock)
+	}
+	if stampsJSON.Valid {
+		json.Unmarshal([]byte(stampsJSON.String), &m.FieldStamps)
+	}
+
+	return m, nil
+}
+
+// GetMemory retrieves a single memory by ID, returning nil if it doesn't exist
+func (s *Store) GetMemory(id string) (*models.Memory, error) {
+	row := s.db.QueryRow(`SELECT `+memoryColumns+` FROM memories WHERE id = ?`, id)
+	m, err := scanMemory(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
 }
 
 // recordAccess updates access statistics for a memory
@@ -293,22 +856,28 @@ func (s *Store) recordAccess(memoryID string) {
 	`, time.Now(), memoryID)
 }
 
-// DecayImportance reduces importance of old memories
+// DecayImportance reduces importance of old memories. Preferences decay ten
+// times more slowly: they stay true long after they were last recalled.
+// Memories under legal hold don't decay.
 func (s *Store) DecayImportance() error {
 	_, err := s.db.Exec(`
 		UPDATE memories
-		SET importance = importance * 0.99
+		SET importance = importance * CASE type WHEN 'preference' THEN 0.999 ELSE 0.99 END
 		WHERE importance > 0.1
 		  AND last_accessed_at < datetime('now', '-1 day')
-	`)
+		  AND ` + notHeld)
 	return err
 }
 
-// CreateProject stores a new project
+// CreateProject stores a new project, updating it if the ID exists. An
+// upsert rather than a replace, which would detach the project's memories.
 func (s *Store) CreateProject(p *models.Project) error {
 	_, err := s.db.Exec(`
-		INSERT OR REPLACE INTO projects (id, name, path, git_remote, created_at, last_seen)
+		INSERT INTO projects (id, name, path, git_remote, created_at, last_seen)
 		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name, path = excluded.path,
+			git_remote = excluded.git_remote, last_seen = excluded.last_seen
 	`, p.ID, p.Name, p.Path, p.GitRemote, p.CreatedAt, p.LastSeen)
 	return err
 }
@@ -335,20 +904,81 @@ func (s *Store) GetProjectByPath(path string) (*models.Project, error) {
 	return &p, nil
 }
 
+// GetProjectByName retrieves a project by name, or nil if there's none
+func (s *Store) GetProjectByName(name string) (*models.Project, error) {
+	row := s.db.QueryRow(`
+		SELECT id, name, path, git_remote, created_at, last_seen
+		FROM projects WHERE name = ? ORDER BY last_seen DESC LIMIT 1
+	`, name)
+
+	var p models.Project
+	var gitRemote sql.NullString
+	err := row.Scan(&p.ID, &p.Name, &p.Path, &gitRemote, &p.CreatedAt, &p.LastSeen)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if gitRemote.Valid {
+		p.GitRemote = &gitRemote.String
+	}
+	return &p, nil
+}
+
 // CreateEvent stores a new event
 func (s *Store) CreateEvent(e *models.Event) error {
+	_, err := s.insertEvent("", e)
+	return err
+}
+
+// IngestEvent stores a captured event, reporting whether it was stored.
+// Events already stored (a journal replay) are skipped, and events
+// identical to one captured within EventDedupWindow before them (the same
+// command run again, a file saved unchanged) only bump its repeat count.
+func (s *Store) IngestEvent(e *models.Event) (bool, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM events WHERE id = ?`, e.ID).Scan(&n); err != nil || n > 0 {
+		return false, err
+	}
 	dataJSON, _ := json.Marshal(e.Data)
-	_, err := s.db.Exec(`
-		INSERT INTO events (id, type, timestamp, data, project_id)
-		VALUES (?, ?, ?, ?, ?)
-	`, e.ID, e.Type, e.Timestamp, string(dataJSON), e.ProjectID)
+	hash := eventHash(e, dataJSON)
+	folded, err := s.foldRepeat(e, hash)
+	if err != nil || folded {
+		return false, err
+	}
+	return s.storeEvent("ON CONFLICT(id) DO NOTHING", e, dataJSON, hash)
+}
+
+func (s *Store) insertEvent(upsert string, e *models.Event) (bool, error) {
+	dataJSON, _ := json.Marshal(e.Data)
+	return s.storeEvent(upsert, e, dataJSON, eventHash(e, dataJSON))
+}
+
+func (s *Store) storeEvent(upsert string, e *models.Event, dataJSON []byte, hash string) (bool, error) {
+	data, encoding, size := encodeEventData(dataJSON)
+	result, err := s.db.Exec(`
+		INSERT INTO events (id, type, timestamp, data, data_encoding, data_size, project_id, content_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) `+upsert,
+		e.ID, e.Type, e.Timestamp, data, encoding, size, e.ProjectID, hash)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// MarkEventSampled marks an event left out of extraction by sampling, so
+// activity stats can show how much of a noisy project was skipped
+func (s *Store) MarkEventSampled(eventID string) error {
+	_, err := s.db.Exec(`UPDATE events SET processed_at = ?, sampled = 1 WHERE id = ?`, time.Now(), eventID)
 	return err
 }
 
 // GetUnprocessedEvents retrieves events that haven't been processed yet
 func (s *Store) GetUnprocessedEvents(limit int) ([]models.Event, error) {
 	rows, err := s.db.Query(`
-		SELECT id, type, timestamp, data, project_id
+		SELECT `+eventColumns+`
 		FROM events
 		WHERE processed_at IS NULL
 		ORDER BY timestamp ASC
@@ -359,30 +989,179 @@ func (s *Store) GetUnprocessedEvents(limit int) ([]models.Event, error) {
 	}
 	defer rows.Close()
 
+	return scanEvents(rows)
+}
+
+// DeferEvents marks events as awaiting extraction until a provider is
+// available again
+func (s *Store) DeferEvents(events []models.Event) error {
+	now := time.Now()
+	for _, e := range events {
+		if _, err := s.db.Exec(`UPDATE events SET deferred_at = ? WHERE id = ?`, now, e.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeferUnprocessedEvents defers every event left unprocessed, e.g. by a
+// previous run that stopped mid-batch. Events whose extraction failed keep
+// their retry schedule.
+func (s *Store) DeferUnprocessedEvents() (int64, error) {
+	result, err := s.db.Exec(`
+		UPDATE events SET deferred_at = ? WHERE processed_at IS NULL AND deferred_at IS NULL AND attempts = 0
+	`, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetDeferredEvents retrieves events awaiting extraction, oldest first
+func (s *Store) GetDeferredEvents(limit int) ([]models.Event, error) {
+	rows, err := s.db.Query(`
+		SELECT `+eventColumns+`
+		FROM events
+		WHERE processed_at IS NULL AND deferred_at IS NOT NULL
+		ORDER BY timestamp ASC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// HasMemoryContent reports whether a memory with the same content exists,
+// ignoring case and surrounding whitespace
+func (s *Store) HasMemoryContent(content string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM memories
+		WHERE LOWER(TRIM(content)) = LOWER(TRIM(?)) AND status != 'rejected'
+	`, content).Scan(&n)
+	return n > 0, err
+}
+
+// IsDuplicate reports whether content is already stored, verbatim or,
+// when an embedding is available, as a memory at least minSimilarity
+// similar. Blank content counts as a duplicate.
+func (s *Store) IsDuplicate(content string, vec []float32, minSimilarity float32) (bool, error) {
+	if strings.TrimSpace(content) == "" {
+		return true, nil
+	}
+	exists, err := s.HasMemoryContent(content)
+	if err != nil || exists {
+		return exists, err
+	}
+	if len(vec) == 0 {
+		return false, nil
+	}
+	similar, err := s.SimilarMemories(vec, nil, minSimilarity, 1)
+	if err != nil {
+		return false, err
+	}
+	return len(similar) > 0, nil
+}
+
+// GetEventsBetween retrieves events captured in [since, until), oldest first
+func (s *Store) GetEventsBetween(since, until time.Time) ([]models.Event, error) {
+	rows, err := s.db.Query(`
+		SELECT `+eventColumns+`
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp ASC
+	`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanEvents(rows)
+}
+
+// eventColumns lists the columns read by scanEvents, in order
+const eventColumns = `id, type, timestamp, data, data_encoding, project_id, repeat_count`
+
+// scanEvents reads rows of eventColumns, decompressing payloads
+func scanEvents(rows *sql.Rows) ([]models.Event, error) {
 	var events []models.Event
 	for rows.Next() {
 		var e models.Event
-		var dataJSON sql.NullString
+		var data []byte
+		var encoding sql.NullString
 		var projectID sql.NullString
+		var count int
 
-		err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &dataJSON, &projectID)
-		if err != nil {
+		if err := rows.Scan(&e.ID, &e.Type, &e.Timestamp, &data, &encoding, &projectID, &count); err != nil {
 			return nil, err
 		}
+		e.Repeats = count - 1
 
 		if projectID.Valid {
 			e.ProjectID = &projectID.String
 		}
-		if dataJSON.Valid {
-			json.Unmarshal([]byte(dataJSON.String), &e.Data)
+		if data != nil {
+			dataJSON, err := decodeEventData(data, encoding.String)
+			if err != nil {
+				return nil, fmt.Errorf("event %s: %w", e.ID, err)
+			}
+			json.Unmarshal(dataJSON, &e.Data)
 		}
 
 		events = append(events, e)
 	}
-
 	return events, nil
 }
 
+// GetMemoriesCreatedBetween retrieves memories created in [since, until),
+// oldest first
+func (s *Store) GetMemoriesCreatedBetween(since, until time.Time) ([]models.Memory, error) {
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories
+		WHERE created_at >= ? AND created_at < ? AND `+approved+`
+		ORDER BY created_at ASC`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, nil
+}
+
+// GetMemoriesReferencingFile retrieves unexpired memories whose source is the
+// file or whose text mentions its project-relative path, most important first
+func (s *Store) GetMemoriesReferencingFile(absPath, relPath string, limit int) ([]models.Memory, error) {
+	mention := "%" + relPath + "%"
+	rows, err := s.db.Query(`SELECT `+memoryColumns+` FROM memories
+		WHERE (source_reference = ? OR content LIKE ? OR summary LIKE ?)
+		AND `+unexpired+` AND `+approved+`
+		ORDER BY importance DESC LIMIT ?`, absPath, mention, mention, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []models.Memory
+	for rows.Next() {
+		m, err := scanMemory(rows)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	return memories, nil
+}
+
 // MarkEventProcessed marks an event as processed
 func (s *Store) MarkEventProcessed(eventID string) error {
 	_, err := s.db.Exec(`
@@ -391,90 +1170,71 @@ func (s *Store) MarkEventProcessed(eventID string) error {
 	return err
 }
 
-// UpdateMemoryEmbedding stores the embedding for a memory
+// UpdateMemoryEmbedding stores the embedding for a memory, labelled with
+// the store's embedding model
 func (s *Store) UpdateMemoryEmbedding(memoryID string, embedding []float32) error {
 	blob := encodeEmbedding(embedding)
+	model, dim := s.embeddingLabel(embedding)
 	_, err := s.db.Exec(`
-		UPDATE memories SET embedding = ? WHERE id = ?
-	`, blob, memoryID)
+		UPDATE memories SET embedding = ?, embedding_model = ?, embedding_dim = ? WHERE id = ?
+	`, blob, model, dim, memoryID)
 	return err
 }
 
+// MemoryEmbeddings returns the stored embeddings of the given memories,
+// leaving out memories that have none
+func (s *Store) MemoryEmbeddings(ids []string) (map[string][]float32, error) {
+	embeddings := make(map[string][]float32, len(ids))
+	if len(ids) == 0 {
+		return embeddings, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`SELECT id, embedding FROM memories
+		WHERE id IN (`+placeholders+`) AND embedding IS NOT NULL`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var blob []byte
+		if err := rows.Scan(&id, &blob); err != nil {
+			return nil, err
+		}
+		embeddings[id] = decodeEmbedding(blob)
+	}
+	return embeddings, rows.Err()
+}
+
 // SemanticSearch searches memories using vector similarity
 func (s *Store) SemanticSearch(queryEmbedding []float32, limit int) ([]models.Memory, error) {
-	// Get all memories with embeddings
-	rows, err := s.db.Query(`
-		SELECT id, type, content, summary, scope, project_id, team_id,
-			   source_type, source_reference, source_timestamp,
-			   confidence, importance, topics, related_memories, embedding,
-			   created_at, last_accessed_at, access_count, expires_at
-		FROM memories
-		WHERE embedding IS NOT NULL
-	`)
+	// Importance weighs into the ranking, so a wider pool of the most
+	// similar memories is scored
+	pool := limit * 5
+	if pool < vectorPool {
+		pool = vectorPool
+	}
+	nearest, err := s.nearestMemories(queryEmbedding, approved+` AND `+unexpired, []interface{}{time.Now()}, -1, pool, nil, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	type scoredMemory struct {
 		memory models.Memory
 		score  float32
 	}
-
-	var scored []scoredMemory
-	for rows.Next() {
-		var m models.Memory
-		var topicsJSON, relatedJSON sql.NullString
-		var projectID, teamID sql.NullString
-		var expiresAt sql.NullTime
-		var embeddingBlob []byte
-
-		err := rows.Scan(
-			&m.ID, &m.Type, &m.Content, &m.Summary, &m.Scope, &projectID, &teamID,
-			&m.Source.Type, &m.Source.Reference, &m.Source.Timestamp,
-			&m.Confidence, &m.Importance, &topicsJSON, &relatedJSON, &embeddingBlob,
-			&m.CreatedAt, &m.LastAccessedAt, &m.AccessCount, &expiresAt,
-		)
-		if err != nil {
-			continue
-		}
-
-		if len(embeddingBlob) == 0 {
-			continue
-		}
-
-		embedding := decodeEmbedding(embeddingBlob)
-		similarity := cosineSimilarity(queryEmbedding, embedding)
-
-		if projectID.Valid {
-			m.ProjectID = &projectID.String
-		}
-		if teamID.Valid {
-			m.TeamID = &teamID.String
-		}
-		if expiresAt.Valid {
-			m.ExpiresAt = &expiresAt.Time
-		}
-		if topicsJSON.Valid {
-			json.Unmarshal([]byte(topicsJSON.String), &m.Topics)
-		}
-		if relatedJSON.Valid {
-			json.Unmarshal([]byte(relatedJSON.String), &m.RelatedMemories)
-		}
-
+	scored := make([]scoredMemory, len(nearest))
+	for i, n := range nearest {
 		// Combine similarity with importance
-		score := similarity*0.7 + float32(m.Importance)*0.3
-		scored = append(scored, scoredMemory{memory: m, score: score})
-	}
-
-	// Sort by score (simple bubble sort for now, can optimize later)
-	for i := 0; i < len(scored); i++ {
-		for j := i + 1; j < len(scored); j++ {
-			if scored[j].score > scored[i].score {
-				scored[i], scored[j] = scored[j], scored[i]
-			}
-		}
+		scored[i] = scoredMemory{memory: n.Memory, score: n.Similarity*0.7 + float32(n.Importance)*0.3}
 	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
 
 	// Take top N
 	var results []models.Memory
@@ -486,51 +1246,70 @@ func (s *Store) SemanticSearch(queryEmbedding []float32, limit int) ([]models.Me
 	return results, nil
 }
 
-// HybridSearch combines semantic and keyword search
-func (s *Store) HybridSearch(query string, queryEmbedding []float32, limit int) ([]models.Memory, error) {
-	// Get semantic results
-	var semanticResults []models.Memory
-	if queryEmbedding != nil && len(queryEmbedding) > 0 {
-		var err error
-		semanticResults, err = s.SemanticSearch(queryEmbedding, limit*2)
-		if err != nil {
-			return nil, err
+// ScoredMemory is a memory with its similarity to a query
+type ScoredMemory struct {
+	models.Memory
+	Similarity float32 `json:"similarity"`
+	Vector     string  `json:"vector,omitempty"`  // the kind of vector matched, if not the content embedding
+	Excerpt    string  `json:"excerpt,omitempty"` // the chunk of long content matched
+}
+
+// SimilarMemories returns unexpired memories of the given types whose
+// embedding similarity to the query is at least minSimilarity, best first.
+// Unlike SemanticSearch it reports raw similarity and doesn't count as access.
+func (s *Store) SimilarMemories(queryEmbedding []float32, types []models.MemoryType, minSimilarity float32, limit int) ([]ScoredMemory, error) {
+	filter := ""
+	var args []interface{}
+	if len(types) > 0 {
+		filter = " AND type IN (?" + strings.Repeat(",?", len(types)-1) + ")"
+		for _, t := range types {
+			args = append(args, t)
 		}
 	}
+	return s.similarMemories(queryEmbedding, filter, args, minSimilarity, limit)
+}
+
+// SimilarProjectMemories is SimilarMemories restricted to one project
+func (s *Store) SimilarProjectMemories(queryEmbedding []float32, projectID string, minSimilarity float32, limit int) ([]ScoredMemory, error) {
+	return s.similarMemories(queryEmbedding, " AND project_id = ?", []interface{}{projectID}, minSimilarity, limit)
+}
+
+// ErrNoEmbedding is returned when a lookup needs a memory's embedding but
+// none has been computed for it yet
+var ErrNoEmbedding = errors.New("memory has no embedding")
 
-	// Get keyword results
-	keywordResults, err := s.Recall(models.RecallRequest{
-		Query: query,
-		Limit: limit * 2,
-	})
+// SimilarTo returns the approved memories nearest to an existing memory by
+// embedding, best first, excluding the memory itself. It returns nil if
+// the memory doesn't exist and ErrNoEmbedding if it has no embedding.
+func (s *Store) SimilarTo(id string, minSimilarity float32, limit int) ([]ScoredMemory, error) {
+	var blob []byte
+	err := s.db.QueryRow(`SELECT embedding FROM memories WHERE id = ?`, id).Scan(&blob)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-
-	// Merge results (semantic results first, then keyword results not in semantic)
-	seen := make(map[string]bool)
-	var merged []models.Memory
-
-	for _, m := range semanticResults {
-		if !seen[m.ID] {
-			seen[m.ID] = true
-			merged = append(merged, m)
-		}
+	if len(blob) == 0 {
+		return nil, ErrNoEmbedding
 	}
+	return s.similarMemories(decodeEmbedding(blob), " AND id != ?", []interface{}{id}, minSimilarity, limit)
+}
 
-	for _, m := range keywordResults {
-		if !seen[m.ID] {
-			seen[m.ID] = true
-			merged = append(merged, m)
-		}
-	}
+func (s *Store) similarMemories(queryEmbedding []float32, filter string, filterArgs []interface{}, minSimilarity float32, limit int) ([]ScoredMemory, error) {
+	where := unexpired + ` AND ` + approved + filter
+	args := append([]interface{}{time.Now()}, filterArgs...)
+	return s.nearestMemories(queryEmbedding, where, args, minSimilarity, limit, nil, nil)
+}
 
-	// Limit results
-	if len(merged) > limit {
-		merged = merged[:limit]
-	}
+// extraScanner scans memoryColumns followed by additional columns
+type extraScanner struct {
+	rowScanner
+	extra []interface{}
+}
 
-	return merged, nil
+func (e extraScanner) Scan(dest ...interface{}) error {
+	return e.rowScanner.Scan(append(dest, e.extra...)...)
 }
 
 // Helper functions for embedding storage
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// Annotation is a local, never-synced note attached to a memory
+type Annotation struct {
+	MemoryID  string    `json:"memoryId"`
+	Pinned    bool      `json:"pinned"`
+	Feedback  string    `json:"feedback,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// UpsertMemory inserts a memory or overwrites the existing row with the same
+// ID in place, so rows referencing it (such as sent expiry reminders) are
+// kept and no delete or insert triggers fire. Used when mirroring memories
+// from a sync endpoint. Mirrored memories are left out of duplicate
+// detection, and project IDs unknown to this store (the server's own) are
+// dropped.
+func (s *Store) UpsertMemory(m *models.Memory) error {
+	mirrored := *m
+	if m.ProjectID != nil {
+		var n int
+		if err := s.db.QueryRow(`SELECT COUNT(*) FROM projects WHERE id = ?`, *m.ProjectID).Scan(&n); err != nil {
+			return err
+		}
+		if n == 0 {
+			mirrored.ProjectID = nil
+		}
+	}
+	// Held copies are frozen; the update trigger would refuse it too
+	if held, err := s.IsHeld(m.ID); err != nil {
+		return err
+	} else if held {
+		return ErrHeld
+	}
+	// Replacing a local copy is an edit like any other: local editors
+	// holding the old version must re-read it
+	mirrored.Version = 1
+	current, err := s.memoryVersion(m.ID)
+	if err == sql.ErrNoRows {
+		_, err = s.writeMemory("INSERT", "", &mirrored, nil)
+		return err
+	} else if err != nil {
+		return err
+	}
+	mirrored.Version = current + 1
+
+	// An UPDATE rather than INSERT OR REPLACE, whose delete cascades, or
+	// an upsert, whose DO UPDATE makes the triggers' OR IGNORE inserts fail
+	sets := make([]string, len(memoryRowColumns)-1)
+	for i, col := range memoryRowColumns[1:] {
+		sets[i] = col + " = ?"
+	}
+	values := s.memoryRow(&mirrored, nil)
+	_, err = s.db.Exec(`UPDATE memories SET `+strings.Join(sets, ", ")+` WHERE id = ?`,
+		append(values[1:], mirrored.ID)...)
+	return heldErr(err)
+}
+
+// encodeClock serializes a memory's sync metadata, returning NULLs for
+// memories that have never been edited through sync
+func encodeClock(m *models.Memory) (clock, stamps interface{}) {
+	if len(m.Clock) > 0 {
+		data, _ := json.Marshal(m.Clock)
+		clock = string(data)
+	}
+	if len(m.FieldStamps) > 0 {
+		data, _ := json.Marshal(m.FieldStamps)
+		stamps = string(data)
+	}
+	return clock, stamps
+}
+
+// deviceIDKey holds the ID this store stamps its edits with, in sync_state
+const deviceIDKey = "device_id"
+
+// DeviceID returns the stable ID that identifies this store's edits in
+// vector clocks, creating it on first use
+func (s *Store) DeviceID() (string, error) {
+	if id, _, ok, err := s.GetSyncState(deviceIDKey); err != nil || ok {
+		return id, err
+	}
+	// Another process may create it first; theirs is kept
+	if _, err := s.db.Exec(`
+		INSERT OR IGNORE INTO sync_state (key, value, updated_at) VALUES (?, ?, ?)
+	`, deviceIDKey, ulid.Make().String(), time.Now()); err != nil {
+		return "", err
+	}
+	id, _, _, err := s.GetSyncState(deviceIDKey)
+	return id, err
+}
+
+// mergeableFields maps the fields changedFields reports to the fields
+// stamped for merging, leaving out those that aren't merged
+var mergeableFields = map[string]string{
+	"type":       models.FieldType,
+	"content":    models.FieldContent,
+	"summary":    models.FieldSummary,
+	"topics":     models.FieldTopics,
+	"expiry":     models.FieldExpiresAt,
+	"maintainer": models.FieldMaintainer,
+	"importance": models.FieldImportance,
+	"confidence": models.FieldConfidence,
+}
+
+// stampEdit records an edit of existing into m's vector clock and field
+// stamps, so it merges with concurrent edits on other devices
+func (s *Store) stampEdit(existing, m *models.Memory) error {
+	m.Clock, m.FieldStamps = existing.Clock, existing.FieldStamps
+	var fields []string
+	for _, f := range changedFields(existing, m) {
+		if field, ok := mergeableFields[f]; ok {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	device, err := s.DeviceID()
+	if err != nil {
+		return err
+	}
+	m.RecordEdit(device, fields...)
+	return nil
+}
+
+// GetSyncState returns a sync bookkeeping value and when it was last set.
+// ok is false if the key has never been set.
+func (s *Store) GetSyncState(key string) (value string, updatedAt time.Time, ok bool, err error) {
+	row := s.db.QueryRow(`SELECT value, updated_at FROM sync_state WHERE key = ?`, key)
+	err = row.Scan(&value, &updatedAt)
+	if err == sql.ErrNoRows {
+		return "", time.Time{}, false, nil
+	}
+	if err != nil {
+		return "", time.Time{}, false, err
+	}
+	return value, updatedAt, true, nil
+}
+
+// SetSyncState records a sync bookkeeping value
+func (s *Store) SetSyncState(key, value string) error {
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO sync_state (key, value, updated_at)
+		VALUES (?, ?, ?)
+	`, key, value, time.Now())
+	return err
+}
+
+// SetPinned pins or unpins a memory locally
+func (s *Store) SetPinned(memoryID string, pinned bool) error {
+	_, err := s.db.Exec(`
+		INSERT INTO annotations (memory_id, pinned, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(memory_id) DO UPDATE SET pinned = excluded.pinned, updated_at = excluded.updated_at
+	`, memoryID, pinned, time.Now())
+	return heldErr(err)
+}
+
+// SetFeedback attaches local feedback text to a memory
+func (s *Store) SetFeedback(memoryID, feedback string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO annotations (memory_id, feedback, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(memory_id) DO UPDATE SET feedback = excluded.feedback, updated_at = excluded.updated_at
+	`, memoryID, feedback, time.Now())
+	return heldErr(err)
+}
+
+// GetAnnotations returns local annotations keyed by memory ID
+func (s *Store) GetAnnotations() (map[string]Annotation, error) {
+	rows, err := s.db.Query(`SELECT memory_id, pinned, feedback, updated_at FROM annotations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	annotations := make(map[string]Annotation)
+	for rows.Next() {
+		var a Annotation
+		var feedback sql.NullString
+		if err := rows.Scan(&a.MemoryID, &a.Pinned, &feedback, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		a.Feedback = feedback.String
+		annotations[a.MemoryID] = a
+	}
+	return annotations, nil
+}
@@ -0,0 +1,408 @@
+package store
+
+import (
+	"container/heap"
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Vector search reads a compact copy of the embeddings instead of every
+// memory row. vector_index holds each embedding normalized and quantized to
+// int8 (a quarter of the size), the same flat layout sqlite-vec uses.
+// Triggers drop a memory's index row whenever its embedding is written or
+// the memory deleted, and queue it to be indexed again before the next
+// search, so every writer, including other processes, keeps it consistent.
+// Candidates found in the index are re-ranked with the exact embeddings.
+// A memory's summary, topics and chunk vectors are indexed by kind, next
+// to its content embedding (see memory_vectors).
+
+// vectorMigrations create the index tables and the triggers maintaining them
+var vectorMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS vector_index (
+		memory_id TEXT NOT NULL,
+		kind TEXT NOT NULL DEFAULT '',
+		dim INTEGER NOT NULL,
+		scale REAL NOT NULL,
+		vec BLOB NOT NULL,
+		PRIMARY KEY (memory_id, kind)
+	)`,
+	`CREATE TABLE IF NOT EXISTS vector_index_pending (
+		memory_id TEXT PRIMARY KEY
+	)`,
+
+	// Deletions force other processes' in-memory copies to reload
+	`CREATE TABLE IF NOT EXISTS vector_index_meta (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		deletes INTEGER NOT NULL DEFAULT 0
+	)`,
+
+	// Queue existing embeddings once, when the index is first created
+	`INSERT OR IGNORE INTO vector_index_pending (memory_id)
+		SELECT id FROM memories
+		WHERE embedding IS NOT NULL AND NOT EXISTS (SELECT 1 FROM vector_index_meta)`,
+	`INSERT OR IGNORE INTO vector_index_meta (id, deletes) VALUES (1, 0)`,
+
+	`CREATE TRIGGER IF NOT EXISTS vector_index_on_insert AFTER INSERT ON memories BEGIN
+		DELETE FROM vector_index WHERE memory_id = NEW.id;
+		INSERT OR IGNORE INTO vector_index_pending (memory_id) SELECT NEW.id WHERE NEW.embedding IS NOT NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS vector_index_on_update AFTER UPDATE OF embedding ON memories BEGIN
+		DELETE FROM vector_index WHERE memory_id = NEW.id;
+		INSERT OR IGNORE INTO vector_index_pending (memory_id) SELECT NEW.id WHERE NEW.embedding IS NOT NULL;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS vector_index_on_delete AFTER DELETE ON memories BEGIN
+		DELETE FROM vector_index WHERE memory_id = OLD.id;
+		DELETE FROM vector_index_pending WHERE memory_id = OLD.id;
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS vector_index_count_deletes AFTER DELETE ON vector_index BEGIN
+		UPDATE vector_index_meta SET deletes = deletes + 1 WHERE id = 1;
+	END`,
+}
+
+const (
+	// vectorMargin widens similarity thresholds applied to quantized
+	// vectors, which are accurate to about ±0.01
+	vectorMargin = 0.05
+
+	// vectorPool is the minimum number of index candidates re-ranked
+	vectorPool = 64
+
+	// vectorFetchBatch bounds the IDs bound in one query
+	vectorFetchBatch = 500
+)
+
+// vectorIndex is this process's copy of the vector_index table
+type vectorIndex struct {
+	mu      sync.Mutex
+	loaded  bool
+	lastRow int64 // highest rowid read
+	deletes int64 // vector_index_meta.deletes when loaded
+	entries []vectorEntry
+}
+
+type vectorEntry struct {
+	id    string
+	kind  string // "" for the content embedding; chunks by class alone
+	scale float32
+	vec   []int8
+}
+
+// vectorCandidate is an indexed memory and its approximate similarity
+type vectorCandidate struct {
+	id         string
+	similarity float32
+}
+
+// syncVectors brings the in-memory index up to date with the database,
+// first indexing queued embeddings
+func (s *Store) syncVectors() error {
+	if err := s.backfillVectors(); err != nil {
+		return err
+	}
+
+	idx := &s.vectors
+	var deletes int64
+	if err := s.db.QueryRow(`SELECT deletes FROM vector_index_meta WHERE id = 1`).Scan(&deletes); err != nil {
+		return err
+	}
+	if !idx.loaded || deletes != idx.deletes {
+		idx.entries, idx.lastRow, idx.loaded = nil, 0, true
+	}
+	idx.deletes = deletes
+
+	rows, err := s.db.Query(`SELECT rowid, memory_id, kind, scale, vec FROM vector_index WHERE rowid > ? ORDER BY rowid`, idx.lastRow)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e vectorEntry
+		var rowid int64
+		var blob []byte
+		if err := rows.Scan(&rowid, &e.id, &e.kind, &e.scale, &blob); err != nil {
+			return err
+		}
+		e.kind = vectorClass(e.kind)
+		e.vec = make([]int8, len(blob))
+		for i, b := range blob {
+			e.vec[i] = int8(b)
+		}
+		idx.entries = append(idx.entries, e)
+		idx.lastRow = rowid
+	}
+	return rows.Err()
+}
+
+// backfillVectors indexes the embeddings queued by the triggers. The
+// triggers drop stale index rows before queueing, so rows still indexed
+// are current and kept.
+func (s *Store) backfillVectors() error {
+	rows, err := s.db.Query(`
+		SELECT p.memory_id, '', m.embedding FROM vector_index_pending p
+		LEFT JOIN memories m ON m.id = p.memory_id
+		UNION ALL
+		SELECT p.memory_id, v.kind, v.embedding FROM vector_index_pending p
+		JOIN memory_vectors v ON v.memory_id = p.memory_id`)
+	if err != nil {
+		return err
+	}
+	type pending struct {
+		id, kind string
+		blob     []byte
+	}
+	var queued []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.kind, &p.blob); err != nil {
+			rows.Close()
+			return err
+		}
+		queued = append(queued, p)
+	}
+	rows.Close()
+	if len(queued) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, p := range queued {
+		if len(p.blob) > 0 {
+			scale, vec := quantize(decodeEmbedding(p.blob))
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO vector_index (memory_id, kind, dim, scale, vec) VALUES (?, ?, ?, ?, ?)`,
+				p.id, p.kind, len(vec), scale, vec); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`DELETE FROM vector_index_pending WHERE memory_id = ?`, p.id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// quantize normalizes v and maps it to int8, returning the scale that
+// converts back
+func quantize(v []float32) (float32, []byte) {
+	var norm, maxAbs float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	norm = math.Sqrt(norm)
+	if norm == 0 {
+		return 0, make([]byte, len(v))
+	}
+	for _, x := range v {
+		if a := math.Abs(float64(x) / norm); a > maxAbs {
+			maxAbs = a
+		}
+	}
+	scale := maxAbs / 127
+	out := make([]byte, len(v))
+	for i, x := range v {
+		out[i] = byte(int8(math.Round(float64(x) / norm / scale)))
+	}
+	return float32(scale), out
+}
+
+// nearest returns up to n indexed memories (all if n <= 0) whose
+// approximate similarity to the query is at least minSimilarity, best first.
+// Content embeddings count in full; other kinds of vector count if weights
+// gives them a weight, with their similarity scaled by it, and a memory's
+// best vector decides its similarity.
+func (idx *vectorIndex) nearest(query []float32, n int, minSimilarity float32, weights map[string]float32) []vectorCandidate {
+	q := normalized(query)
+	best := make(map[string]float32)
+	for _, e := range idx.entries {
+		if len(e.vec) != len(q) {
+			continue // another embedding model
+		}
+		weight := float32(1)
+		if e.kind != "" {
+			if weight = weights[e.kind]; weight <= 0 {
+				continue
+			}
+		}
+		var dot float32
+		for i, v := range e.vec {
+			dot += float32(v) * q[i]
+		}
+		sim := dot * e.scale * weight
+		if sim < minSimilarity {
+			continue
+		}
+		if b, ok := best[e.id]; !ok || sim > b {
+			best[e.id] = sim
+		}
+	}
+
+	h := &candidateHeap{}
+	for id, sim := range best {
+		if n > 0 && h.Len() == n {
+			if sim <= (*h)[0].similarity {
+				continue
+			}
+			heap.Pop(h)
+		}
+		heap.Push(h, vectorCandidate{id: id, similarity: sim})
+	}
+
+	out := []vectorCandidate(*h)
+	sort.Slice(out, func(i, j int) bool { return out[i].similarity > out[j].similarity })
+	return out
+}
+
+func normalized(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	inv := float32(1 / math.Sqrt(norm))
+	for i, x := range v {
+		out[i] = x * inv
+	}
+	return out
+}
+
+// candidateHeap is a min-heap on similarity, holding the best n seen
+type candidateHeap []vectorCandidate
+
+func (h candidateHeap) Len() int            { return len(h) }
+func (h candidateHeap) Less(i, j int) bool  { return h[i].similarity < h[j].similarity }
+func (h candidateHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x interface{}) { *h = append(*h, x.(vectorCandidate)) }
+func (h *candidateHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// nearestMemories returns memories nearest to the query by exact cosine
+// similarity, best first. Only memories passing the SQL condition where
+// (with args) and accept (if non-nil) with similarity of at least
+// minSimilarity are returned, up to want of them (all if want <= 0).
+// Index candidates are re-ranked in widening rounds until enough pass.
+// weights (nil for content embeddings alone) weighs other kinds of vector,
+// as for nearest.
+func (s *Store) nearestMemories(query []float32, where string, args []interface{}, minSimilarity float32, want int, accept func(models.Memory) bool, weights map[string]float32) ([]ScoredMemory, error) {
+	s.vectors.mu.Lock()
+	defer s.vectors.mu.Unlock()
+	if err := s.syncVectors(); err != nil {
+		return nil, err
+	}
+
+	var results []ScoredMemory
+	checked := make(map[string]bool)
+	n := want * 4
+	if n < vectorPool {
+		n = vectorPool
+	}
+	for {
+		limit := n
+		if want <= 0 {
+			limit = 0
+		}
+		candidates := s.vectors.nearest(query, limit, minSimilarity-vectorMargin, weights)
+
+		var ids []string
+		for _, c := range candidates {
+			if !checked[c.id] {
+				checked[c.id] = true
+				ids = append(ids, c.id)
+			}
+		}
+		found, err := s.fetchScored(query, ids, `embedding IS NOT NULL AND `+where, args, weights)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range found {
+			if m.Similarity >= minSimilarity && (accept == nil || accept(m.Memory)) {
+				results = append(results, m)
+			}
+		}
+
+		exhausted := limit == 0 || len(candidates) < limit
+		if exhausted || len(results) >= want {
+			break
+		}
+		n *= 4
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Similarity > results[j].Similarity })
+	if want > 0 && len(results) > want {
+		results = results[:want]
+	}
+	return results, nil
+}
+
+// fetchScored loads the memories with the given IDs that match where, with
+// their exact similarity to the query (0 for memories without an
+// embedding), the best of their vectors weighted as for nearest
+func (s *Store) fetchScored(query []float32, ids []string, where string, args []interface{}, weights map[string]float32) ([]ScoredMemory, error) {
+	var out []ScoredMemory
+	for start := 0; start < len(ids); start += vectorFetchBatch {
+		batch := ids[start:min(start+vectorFetchBatch, len(ids))]
+		q := `SELECT ` + memoryColumns + `, embedding FROM memories
+			WHERE ` + where + ` AND id IN (?` + strings.Repeat(",?", len(batch)-1) + `)`
+		batchArgs := append([]interface{}{}, args...)
+		for _, id := range batch {
+			batchArgs = append(batchArgs, id)
+		}
+
+		rows, err := s.db.Query(q, batchArgs...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var blob []byte
+			m, err := scanMemory(extraScanner{rows, []interface{}{&blob}})
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out = append(out, ScoredMemory{Memory: m, Similarity: cosineSimilarity(query, decodeEmbedding(blob))})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	if len(weights) == 0 || len(query) == 0 || len(out) == 0 {
+		return out, nil
+	}
+
+	found := make([]string, len(out))
+	for i, m := range out {
+		found[i] = m.ID
+	}
+	vectors, err := s.memoryVectors(found, weights)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		best := ""
+		for kind, vec := range vectors[out[i].ID] {
+			if sim := cosineSimilarity(query, vec) * weights[vectorClass(kind)]; sim > out[i].Similarity {
+				out[i].Similarity, best = sim, kind
+			}
+		}
+		out[i].Vector = vectorClass(best)
+		if c, ok := parseChunk(best); ok {
+			out[i].Excerpt = c.Excerpt(out[i].Content)
+		}
+	}
+	return out, nil
+}
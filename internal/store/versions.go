@@ -0,0 +1,43 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConflict is returned by UpdateMemory when the memory was edited after
+// the version the update was based on. The error is a *ConflictError.
+var ErrConflict = errors.New("memory was changed since it was read")
+
+// ConflictError carries the version an update lost to
+type ConflictError struct {
+	Current int
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v (now at version %d)", ErrConflict, e.Current)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+var versionMigrations = []string{
+	// Any change to the fields an edit can overwrite makes a new version,
+	// whichever code path or process makes it
+	`CREATE TRIGGER IF NOT EXISTS memory_version_on_update
+	AFTER UPDATE OF type, content, summary, scope, project_id, topics, expires_at, maintainer ON memories
+	WHEN OLD.type IS NOT NEW.type OR OLD.content IS NOT NEW.content OR OLD.summary IS NOT NEW.summary
+		OR OLD.scope IS NOT NEW.scope OR OLD.project_id IS NOT NEW.project_id OR OLD.topics IS NOT NEW.topics
+		OR OLD.expires_at IS NOT NEW.expires_at OR OLD.maintainer IS NOT NEW.maintainer
+	BEGIN
+		UPDATE memories SET version = OLD.version + 1 WHERE id = NEW.id;
+	END`,
+}
+
+// memoryVersion returns a memory's current version
+func (s *Store) memoryVersion(id string) (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT version FROM memories WHERE id = ?`, id).Scan(&version)
+	return version, err
+}
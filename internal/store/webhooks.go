@@ -0,0 +1,286 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook events, named as delivered
+const (
+	WebhookMemoryCreated  = "memory.created"
+	WebhookMemoryUpdated  = "memory.updated"
+	WebhookMemoryDeleted  = "memory.deleted"
+	WebhookMemoryPromoted = "memory.promoted" // approved in review, or shared more widely
+)
+
+// WebhookEvents lists every webhook event
+var WebhookEvents = []string{WebhookMemoryCreated, WebhookMemoryUpdated, WebhookMemoryDeleted, WebhookMemoryPromoted}
+
+// Lifecycle events are queued by triggers, like the vector index, so memories
+// written by any process (CLI, daemon, MCP server) are announced; the daemon
+// delivers them. Nothing is queued while no webhook is registered.
+
+// memorySnapshot is the JSON payload describing the memory row r
+const memorySnapshot = `json_object(
+	'id', r.id, 'type', r.type, 'content', r.content, 'summary', r.summary,
+	'scope', r.scope, 'projectId', r.project_id, 'status', r.status,
+	'topics', CASE WHEN json_valid(r.topics) THEN json(r.topics) ELSE json_array() END,
+	'confidence', r.confidence, 'importance', r.importance,
+	'sourceType', r.source_type, 'sourceReference', r.source_reference,
+	'createdAt', r.created_at)`
+
+// scopeRank orders scopes from narrowest to widest
+const scopeRank = `CASE %s WHEN 'project' THEN 1 WHEN 'team' THEN 2 WHEN 'org' THEN 3 ELSE 0 END`
+
+// queueWebhook is a trigger body queueing event for row (NEW or OLD) to
+// every webhook subscribed to it
+func queueWebhook(event, row string) string {
+	return `INSERT INTO webhook_deliveries (webhook_id, event, memory_id, payload, next_attempt_at)
+		SELECT w.id, '` + event + `', ` + row + `.id, ` + strings.ReplaceAll(memorySnapshot, "r.", row+".") + `, unixepoch()
+		FROM webhooks w
+		WHERE w.events = '' OR instr(',' || w.events || ',', ',` + event + `,') > 0;`
+}
+
+var webhookMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL DEFAULT '', -- comma-separated, empty for all
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// next_attempt_at is in Unix seconds so triggers and Go compare alike
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+		event TEXT NOT NULL,
+		memory_id TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at INTEGER NOT NULL,
+		last_error TEXT,
+		delivered_at DATETIME,
+		failed INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at)
+		WHERE delivered_at IS NULL AND failed = 0`,
+
+	`CREATE TRIGGER IF NOT EXISTS webhook_memory_created AFTER INSERT ON memories BEGIN
+		` + queueWebhook(WebhookMemoryCreated, "NEW") + `
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS webhook_memory_updated AFTER UPDATE OF type, content, summary, topics, project_id ON memories
+	WHEN OLD.type IS NOT NEW.type OR OLD.content IS NOT NEW.content OR OLD.summary IS NOT NEW.summary
+		OR OLD.topics IS NOT NEW.topics OR OLD.project_id IS NOT NEW.project_id
+	BEGIN
+		` + queueWebhook(WebhookMemoryUpdated, "NEW") + `
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS webhook_memory_promoted AFTER UPDATE OF status, scope ON memories
+	WHEN (NEW.status = 'approved' AND OLD.status IS NOT 'approved')
+		OR ` + fmt.Sprintf(scopeRank, "NEW.scope") + ` > ` + fmt.Sprintf(scopeRank, "OLD.scope") + `
+	BEGIN
+		` + queueWebhook(WebhookMemoryPromoted, "NEW") + `
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS webhook_memory_deleted AFTER DELETE ON memories BEGIN
+		` + queueWebhook(WebhookMemoryDeleted, "OLD") + `
+	END`,
+}
+
+// Webhook is a URL notified of memory lifecycle events
+type Webhook struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events,omitempty"` // empty for all
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Delivery counts
+	Pending   int `json:"pending"`
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+}
+
+// WebhookDelivery is a queued event awaiting delivery to a webhook
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID string
+	URL       string
+	Secret    string
+	Event     string
+	MemoryID  string
+	Payload   string // JSON snapshot of the memory
+	CreatedAt time.Time
+	Attempts  int // including this one
+}
+
+// AddWebhook registers a webhook
+func (s *Store) AddWebhook(w *Webhook) error {
+	_, err := s.db.Exec(`INSERT INTO webhooks (id, url, secret, events, created_at) VALUES (?, ?, ?, ?, ?)`,
+		w.ID, w.URL, w.Secret, strings.Join(w.Events, ","), w.CreatedAt)
+	return err
+}
+
+// RemoveWebhook unregisters a webhook, dropping its undelivered events
+func (s *Store) RemoveWebhook(id string) error {
+	result, err := s.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook %s not found", id)
+	}
+	return nil
+}
+
+// GetWebhook returns a webhook by ID
+func (s *Store) GetWebhook(id string) (*Webhook, error) {
+	hooks, err := s.listWebhooks(`WHERE w.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) == 0 {
+		return nil, fmt.Errorf("webhook %s not found", id)
+	}
+	return &hooks[0], nil
+}
+
+// ListWebhooks returns all webhooks with their delivery counts
+func (s *Store) ListWebhooks() ([]Webhook, error) {
+	return s.listWebhooks("")
+}
+
+func (s *Store) listWebhooks(where string, args ...interface{}) ([]Webhook, error) {
+	rows, err := s.db.Query(`
+		SELECT w.id, w.url, w.secret, w.events, w.created_at,
+			COUNT(CASE WHEN d.delivered_at IS NULL AND d.failed = 0 THEN 1 END),
+			COUNT(d.delivered_at),
+			COUNT(CASE WHEN d.failed = 1 THEN 1 END)
+		FROM webhooks w
+		LEFT JOIN webhook_deliveries d ON d.webhook_id = w.id
+		`+where+`
+		GROUP BY w.id
+		ORDER BY w.created_at`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []Webhook
+	for rows.Next() {
+		var w Webhook
+		var events string
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, &events, &w.CreatedAt, &w.Pending, &w.Delivered, &w.Failed); err != nil {
+			return nil, err
+		}
+		if events != "" {
+			w.Events = strings.Split(events, ",")
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+// ClaimWebhookDeliveries returns up to limit deliveries due at now, oldest
+// first, and holds them for lease so another process doesn't send them too.
+// A claim that is never completed (say, the daemon was killed) is retried
+// once the lease runs out.
+func (s *Store) ClaimWebhookDeliveries(now time.Time, lease time.Duration, limit int) ([]WebhookDelivery, error) {
+	rows, err := s.db.Query(`
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE delivered_at IS NULL AND failed = 0 AND next_attempt_at <= ?
+			ORDER BY id LIMIT ?
+		)
+		RETURNING id`, now.Add(lease).Unix(), now.Unix(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []interface{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(`
+		SELECT d.id, d.webhook_id, w.url, w.secret, d.event, d.memory_id, d.payload, d.created_at, d.attempts
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id IN (?`+strings.Repeat(",?", len(ids)-1)+`)
+		ORDER BY d.id`, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.Event, &d.MemoryID, &d.Payload, &d.CreatedAt, &d.Attempts); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// CompleteWebhookDelivery records a delivery attempt. A nil deliveryErr
+// marks it delivered; otherwise it is retried at retryAt, or given up on
+// if retryAt is zero.
+func (s *Store) CompleteWebhookDelivery(id int64, deliveryErr error, retryAt time.Time) error {
+	var err error
+	switch {
+	case deliveryErr == nil:
+		_, err = s.db.Exec(`UPDATE webhook_deliveries SET delivered_at = ?, last_error = NULL WHERE id = ?`, time.Now(), id)
+	case retryAt.IsZero():
+		_, err = s.db.Exec(`UPDATE webhook_deliveries SET failed = 1, last_error = ? WHERE id = ?`, deliveryErr.Error(), id)
+	default:
+		_, err = s.db.Exec(`UPDATE webhook_deliveries SET next_attempt_at = ?, last_error = ? WHERE id = ?`,
+			retryAt.Unix(), deliveryErr.Error(), id)
+	}
+	return err
+}
+
+// LastWebhookError returns the error of a webhook's most recent failed
+// attempt, if any
+func (s *Store) LastWebhookError(webhookID string) (string, error) {
+	var msg string
+	err := s.db.QueryRow(`
+		SELECT last_error FROM webhook_deliveries
+		WHERE webhook_id = ? AND last_error IS NOT NULL
+		ORDER BY id DESC LIMIT 1`, webhookID).Scan(&msg)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return msg, err
+}
+
+// RetryFailedWebhookDeliveries requeues deliveries to a webhook that were
+// given up on, returning how many
+func (s *Store) RetryFailedWebhookDeliveries(webhookID string) (int64, error) {
+	result, err := s.db.Exec(`
+		UPDATE webhook_deliveries SET failed = 0, attempts = 0, next_attempt_at = unixepoch()
+		WHERE webhook_id = ? AND failed = 1`, webhookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PruneWebhookDeliveries deletes deliveries that succeeded before the given
+// time
+func (s *Store) PruneWebhookDeliveries(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM webhook_deliveries WHERE delivered_at IS NOT NULL AND delivered_at < ?`, before)
+	return err
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"time"
+)
+
+var yieldMigrations = []string{
+	// File events per directory, and how many were in extraction batches
+	// that produced memories, for suggesting directories to ignore
+	`CREATE TABLE IF NOT EXISTS dir_yield (
+		dir TEXT PRIMARY KEY,
+		events INTEGER NOT NULL DEFAULT 0,
+		yielded INTEGER NOT NULL DEFAULT 0,
+		first_seen DATETIME NOT NULL,
+		last_seen DATETIME NOT NULL
+	)`,
+}
+
+// DirYield is how productive a watched directory has been
+type DirYield struct {
+	Dir       string    `json:"dir"`
+	Events    int       `json:"events"`
+	Yielded   int       `json:"yielded"` // events in batches that produced memories
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// RecordDirYield counts file events per directory; yielded says whether
+// they led to memories
+func (s *Store) RecordDirYield(events map[string]int, yielded bool) error {
+	if len(events) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for dir, n := range events {
+		y := 0
+		if yielded {
+			y = n
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO dir_yield (dir, events, yielded, first_seen, last_seen) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(dir) DO UPDATE SET
+				events = events + excluded.events,
+				yielded = yielded + excluded.yielded,
+				last_seen = excluded.last_seen
+		`, dir, n, y, now, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DirYields returns every directory's counts, busiest first
+func (s *Store) DirYields() ([]DirYield, error) {
+	rows, err := s.db.Query(`SELECT dir, events, yielded, first_seen, last_seen FROM dir_yield ORDER BY events DESC, dir`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var yields []DirYield
+	for rows.Next() {
+		var y DirYield
+		if err := rows.Scan(&y.Dir, &y.Events, &y.Yielded, &y.FirstSeen, &y.LastSeen); err != nil {
+			return nil, err
+		}
+		yields = append(yields, y)
+	}
+	return yields, rows.Err()
+}
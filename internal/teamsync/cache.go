@@ -0,0 +1,155 @@
+package teamsync
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// CacheFile is the team cache database name inside the data directory
+const CacheFile = "team-cache.db"
+
+// StaleAfter is how old a cache refresh can be before results are flagged stale
+const StaleAfter = 24 * time.Hour
+
+const lastRefreshKey = "last_refresh"
+
+// Cache is a local read-only mirror of team and org memories, so recall keeps
+// working while the sync endpoint is unreachable. Local pins and feedback live
+// in the annotations table and are never touched by a refresh.
+type Cache struct {
+	store   *store.Store
+	client  *Client
+	trusted []string
+}
+
+// OpenCache opens (or creates) the team cache database.
+// client may be nil when only reading the cache.
+func OpenCache(dbPath string, client *Client) (*Cache, error) {
+	s, err := store.New(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open team cache: %w", err)
+	}
+	return &Cache{store: s, client: client}, nil
+}
+
+// RequireSignatures makes Refresh reject team and org memories that aren't
+// signed by one of the trusted public keys
+func (c *Cache) RequireSignatures(trusted []string) {
+	c.trusted = trusted
+}
+
+// Close closes the cache database
+func (c *Cache) Close() error {
+	return c.store.Close()
+}
+
+// Store returns the underlying store for recall and annotations
+func (c *Cache) Store() *store.Store {
+	return c.store
+}
+
+// Refresh pulls memories changed since the last refresh and mirrors them
+// locally, returning the number of memories updated
+func (c *Cache) Refresh() (int, error) {
+	if c.client == nil {
+		return 0, fmt.Errorf("no sync endpoint configured")
+	}
+
+	last, _, err := c.LastRefresh()
+	if err != nil {
+		return 0, err
+	}
+
+	started := time.Now()
+	memories, err := c.client.Pull(last)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for i := range memories {
+		m := &memories[i]
+		if m.Scope != models.MemoryScopeTeam && m.Scope != models.MemoryScopeOrg {
+			continue
+		}
+
+		// Reject forged or unsigned memories before they reach recall
+		if c.trusted != nil {
+			if err := Verify(m, c.trusted); err != nil {
+				log.Printf("Rejected team memory %s: %v", m.ID, err)
+				continue
+			}
+		}
+
+		// Merge with the cached copy instead of clobbering concurrent edits
+		local, err := c.store.GetMemory(m.ID)
+		if err != nil {
+			return updated, fmt.Errorf("failed to read cached memory %s: %w", m.ID, err)
+		}
+		if local != nil {
+			// GetMemory leaves out the embedding; keep it unless the
+			// merged content needs a new one
+			embeddings, err := c.store.MemoryEmbeddings([]string{local.ID})
+			if err != nil {
+				return updated, fmt.Errorf("failed to read cached memory %s: %w", m.ID, err)
+			}
+			local.Embedding = embeddings[local.ID]
+			merged := Merge(*local, *m)
+			if len(merged.Embedding) == 0 && merged.Content == local.Content {
+				merged.Embedding = local.Embedding
+			}
+			m = &merged
+		}
+
+		if err := c.store.UpsertMemory(m); errors.Is(err, store.ErrHeld) {
+			// The cached copy is frozen under legal hold
+			continue
+		} else if err != nil {
+			return updated, fmt.Errorf("failed to cache memory %s: %w", m.ID, err)
+		}
+		updated++
+	}
+
+	if err := c.store.SetSyncState(lastRefreshKey, started.UTC().Format(time.RFC3339)); err != nil {
+		return updated, err
+	}
+
+	return updated, nil
+}
+
+// LastRefresh returns when the cache was last refreshed successfully
+func (c *Cache) LastRefresh() (time.Time, bool, error) {
+	return lastRefresh(c.store)
+}
+
+func lastRefresh(s *store.Store) (time.Time, bool, error) {
+	value, _, ok, err := s.GetSyncState(lastRefreshKey)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	return t, true, nil
+}
+
+// IsStale reports whether the cache hasn't been refreshed within StaleAfter
+func (c *Cache) IsStale() bool {
+	return IsStale(c.store)
+}
+
+// IsStale reports whether a team cache store hasn't been refreshed within
+// StaleAfter
+func IsStale(s *store.Store) bool {
+	last, ok, err := lastRefresh(s)
+	if err != nil || !ok {
+		return true
+	}
+	return time.Since(last) > StaleAfter
+}
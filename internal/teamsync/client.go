@@ -0,0 +1,147 @@
+package teamsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/insights"
+	"github.com/memorypilot/memorypilot/internal/privacy"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Client talks to a team sync endpoint
+type Client struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewClient creates a new sync client. In local-only mode the endpoint
+// must be on this machine.
+func NewClient(endpoint, token string) (*Client, error) {
+	if err := privacy.CheckURL("team sync", endpoint); err != nil {
+		return nil, err
+	}
+	return &Client{
+		endpoint: endpoint,
+		token:    token,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+type pullResponse struct {
+	Memories []models.Memory `json:"memories"`
+}
+
+// Pull fetches team and org memories changed since the given time.
+// A zero time fetches everything.
+func (c *Client) Pull(since time.Time) ([]models.Memory, error) {
+	q := url.Values{}
+	q.Set("scope", "team,org")
+	if !since.IsZero() {
+		q.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.endpoint+"/v1/memories?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sync request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("sync error: %s", string(body))
+	}
+
+	var result pullResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Memories, nil
+}
+
+type recallResponse struct {
+	Memories []models.Memory `json:"memories"`
+}
+
+// Recall runs a search on the server, for machines with no local database
+func (c *Client) Recall(recall models.RecallRequest) ([]models.Memory, error) {
+	body, err := json.Marshal(recall)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint+"/v1/recall", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("recall request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("recall error: %s", string(body))
+	}
+
+	var result recallResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Memories, nil
+}
+
+// Insights fetches the server's latest anonymized insights report
+func (c *Client) Insights() (*insights.Report, error) {
+	req, err := http.NewRequest(http.MethodGet, c.endpoint+"/v1/insights", nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("insights request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("insights error: %s", string(body))
+	}
+
+	var report insights.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &report, nil
+}
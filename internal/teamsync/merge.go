@@ -0,0 +1,142 @@
+package teamsync
+
+import (
+	"sort"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// ordering is the causal relationship between two vector clocks
+type ordering int
+
+const (
+	orderEqual ordering = iota
+	orderBefore
+	orderAfter
+	orderConcurrent
+)
+
+// compareClocks reports how clock a relates to clock b
+func compareClocks(a, b models.VectorClock) ordering {
+	aAhead, bAhead := false, false
+	for device, n := range a {
+		if n > b[device] {
+			aAhead = true
+		}
+	}
+	for device, n := range b {
+		if n > a[device] {
+			bAhead = true
+		}
+	}
+
+	switch {
+	case aAhead && bAhead:
+		return orderConcurrent
+	case aAhead:
+		return orderAfter
+	case bAhead:
+		return orderBefore
+	default:
+		return orderEqual
+	}
+}
+
+// mergeClocks returns the element-wise maximum of two vector clocks
+func mergeClocks(a, b models.VectorClock) models.VectorClock {
+	merged := make(models.VectorClock, len(a)+len(b))
+	for device, n := range a {
+		merged[device] = n
+	}
+	for device, n := range b {
+		if n > merged[device] {
+			merged[device] = n
+		}
+	}
+	return merged
+}
+
+// newer reports whether stamp a wins over stamp b under last-writer-wins.
+// Equal timestamps are broken by device ID so every replica agrees.
+func newer(a, b models.FieldStamp) bool {
+	if !a.At.Equal(b.At) {
+		return a.At.After(b.At)
+	}
+	return a.Device > b.Device
+}
+
+// Merge reconciles a local and a remote copy of the same memory.
+//
+// If one copy causally follows the other (by vector clock) it wins outright.
+// Equal clocks, including the empty clocks of memories never edited here,
+// mean the local copy has nothing the remote one lacks, so the remote copy
+// wins. Concurrent edits are merged field by field: each field takes the
+// value with the latest FieldStamp, except topics, which are unioned so
+// neither side's tags are lost. Local-only bookkeeping (access stats) is
+// always kept.
+func Merge(local, remote models.Memory) models.Memory {
+	var merged models.Memory
+
+	switch compareClocks(local.Clock, remote.Clock) {
+	case orderAfter:
+		merged = local
+	case orderBefore, orderEqual:
+		merged = remote
+	default:
+		merged = mergeFields(local, remote)
+	}
+
+	merged.Clock = mergeClocks(local.Clock, remote.Clock)
+	merged.LastAccessedAt = local.LastAccessedAt
+	merged.AccessCount = local.AccessCount
+	merged.Version = local.Version
+	return merged
+}
+
+// mergeFields applies field-level last-writer-wins to concurrent copies
+func mergeFields(local, remote models.Memory) models.Memory {
+	merged := local
+	merged.FieldStamps = make(map[string]models.FieldStamp)
+
+	pick := func(field string) bool {
+		l, r := local.FieldStamps[field], remote.FieldStamps[field]
+		if newer(r, l) {
+			merged.FieldStamps[field] = r
+			return true
+		}
+		if !l.At.IsZero() {
+			merged.FieldStamps[field] = l
+		}
+		return false
+	}
+
+	if pick(models.FieldType) {
+		merged.Type = remote.Type
+	}
+	if pick(models.FieldContent) {
+		merged.Content = remote.Content
+		merged.Embedding = remote.Embedding
+	}
+	if pick(models.FieldSummary) {
+		merged.Summary = remote.Summary
+	}
+	if pick(models.FieldImportance) {
+		merged.Importance = remote.Importance
+	}
+	if pick(models.FieldConfidence) {
+		merged.Confidence = remote.Confidence
+	}
+	if pick(models.FieldExpiresAt) {
+		merged.ExpiresAt = remote.ExpiresAt
+	}
+	if pick(models.FieldMaintainer) {
+		merged.Maintainer = remote.Maintainer
+	}
+
+	pick(models.FieldTopics)
+	// Sorted so every replica converges on the same order
+	merged.Topics = models.UnionTopics(local.Topics, remote.Topics)
+	sort.Strings(merged.Topics)
+
+	return merged
+}
@@ -0,0 +1,275 @@
+package teamsync
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	sshKeyType      = "ssh-ed25519"
+	signatureDomain = "memorypilot-memory-v1\n"
+)
+
+// ErrUnsigned is returned when verifying a memory that carries no signature
+var ErrUnsigned = errors.New("memory is not signed")
+
+// Signer signs memories with an ed25519 SSH key
+type Signer struct {
+	key       ed25519.PrivateKey
+	publicKey string // authorized_keys form: "ssh-ed25519 AAAA..."
+}
+
+// LoadSigner reads an unencrypted OpenSSH ed25519 private key
+// (e.g. ~/.ssh/id_ed25519)
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "OPENSSH PRIVATE KEY" {
+		return nil, fmt.Errorf("%s is not an OpenSSH private key", path)
+	}
+
+	key, err := parseOpenSSHPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return &Signer{
+		key:       key,
+		publicKey: formatPublicKey(key.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// PublicKey returns the signer's public key in authorized_keys form
+func (s *Signer) PublicKey() string {
+	return s.publicKey
+}
+
+// Sign attaches a signature over the memory's shared fields
+func (s *Signer) Sign(m *models.Memory) {
+	sig := ed25519.Sign(s.key, signingPayload(m))
+	m.Signature = base64.StdEncoding.EncodeToString(sig)
+	m.Signer = s.publicKey
+}
+
+// Verify checks a memory's signature and that its signer is trusted
+func Verify(m *models.Memory, trusted []string) error {
+	if m.Signature == "" || m.Signer == "" {
+		return ErrUnsigned
+	}
+
+	signer := normalizePublicKey(m.Signer)
+	allowed := false
+	for _, t := range trusted {
+		if normalizePublicKey(t) == signer {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("signer %s is not trusted", truncateKey(signer))
+	}
+
+	pub, err := parsePublicKey(signer)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+	if !ed25519.Verify(pub, signingPayload(m), sig) {
+		return fmt.Errorf("signature does not match memory content")
+	}
+	return nil
+}
+
+// LoadAllowedSigners reads trusted public keys, one authorized_keys-style
+// line per key. Blank lines and # comments are ignored.
+func LoadAllowedSigners(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, normalizePublicKey(line))
+	}
+	return keys, scanner.Err()
+}
+
+// signingPayload is the canonical encoding of the fields a signature covers.
+// Local bookkeeping (importance decay, access stats) is deliberately excluded.
+func signingPayload(m *models.Memory) []byte {
+	topics := append([]string{}, m.Topics...)
+	sort.Strings(topics)
+
+	payload, _ := json.Marshal(struct {
+		ID      string             `json:"id"`
+		Type    models.MemoryType  `json:"type"`
+		Content string             `json:"content"`
+		Summary string             `json:"summary"`
+		Scope   models.MemoryScope `json:"scope"`
+		TeamID  *string            `json:"teamId"`
+		Topics  []string           `json:"topics"`
+		Author  string             `json:"author,omitempty"` // left out when unset, so older signatures verify
+	}{m.ID, m.Type, m.Content, m.Summary, m.Scope, m.TeamID, topics, m.Author})
+
+	return append([]byte(signatureDomain), payload...)
+}
+
+// normalizePublicKey strips the comment from an authorized_keys line
+func normalizePublicKey(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return line
+	}
+	return fields[0] + " " + fields[1]
+}
+
+func truncateKey(key string) string {
+	if len(key) <= 32 {
+		return key
+	}
+	return key[:29] + "..."
+}
+
+func formatPublicKey(pub ed25519.PublicKey) string {
+	var buf bytes.Buffer
+	writeString(&buf, []byte(sshKeyType))
+	writeString(&buf, pub)
+	return sshKeyType + " " + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func parsePublicKey(key string) (ed25519.PublicKey, error) {
+	fields := strings.Fields(key)
+	if len(fields) < 2 || fields[0] != sshKeyType {
+		return nil, fmt.Errorf("unsupported key type (only %s is supported)", sshKeyType)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed public key: %w", err)
+	}
+
+	r := bytes.NewReader(blob)
+	keyType, err := readString(r)
+	if err != nil || string(keyType) != sshKeyType {
+		return nil, fmt.Errorf("malformed public key")
+	}
+	pub, err := readString(r)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("malformed public key")
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// parseOpenSSHPrivateKey decodes the "openssh-key-v1" container described in
+// OpenSSH's PROTOCOL.key. Only unencrypted ed25519 keys are supported.
+func parseOpenSSHPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	const magic = "openssh-key-v1\x00"
+	if !bytes.HasPrefix(data, []byte(magic)) {
+		return nil, fmt.Errorf("bad magic")
+	}
+	r := bytes.NewReader(data[len(magic):])
+
+	cipher, err := readString(r)
+	if err != nil {
+		return nil, err
+	}
+	if string(cipher) != "none" {
+		return nil, fmt.Errorf("encrypted keys are not supported (cipher %s)", cipher)
+	}
+	if _, err := readString(r); err != nil { // kdfname
+		return nil, err
+	}
+	if _, err := readString(r); err != nil { // kdfoptions
+		return nil, err
+	}
+
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("expected 1 key, found %d", count)
+	}
+	if _, err := readString(r); err != nil { // public key
+		return nil, err
+	}
+
+	private, err := readString(r)
+	if err != nil {
+		return nil, err
+	}
+	pr := bytes.NewReader(private)
+
+	var check1, check2 uint32
+	binary.Read(pr, binary.BigEndian, &check1)
+	binary.Read(pr, binary.BigEndian, &check2)
+	if check1 != check2 {
+		return nil, fmt.Errorf("corrupt private key")
+	}
+
+	keyType, err := readString(pr)
+	if err != nil {
+		return nil, err
+	}
+	if string(keyType) != sshKeyType {
+		return nil, fmt.Errorf("unsupported key type %s (only %s is supported)", keyType, sshKeyType)
+	}
+	if _, err := readString(pr); err != nil { // public half
+		return nil, err
+	}
+	priv, err := readString(pr)
+	if err != nil {
+		return nil, err
+	}
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("malformed ed25519 key")
+	}
+
+	return ed25519.PrivateKey(priv), nil
+}
+
+func readString(r *bytes.Reader) ([]byte, error) {
+	var n uint32
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if int(n) > r.Len() {
+		return nil, fmt.Errorf("truncated key data")
+	}
+	buf := make([]byte, n)
+	if _, err := r.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func writeString(buf *bytes.Buffer, data []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
@@ -0,0 +1,312 @@
+package teamsync
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// The keys in testdata were made with ssh-keygen for these tests only
+
+func loadTestSigner(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := LoadSigner(filepath.Join("testdata", "id_ed25519"))
+	if err != nil {
+		t.Fatalf("load signer: %v", err)
+	}
+	return signer
+}
+
+func signedMemory(t *testing.T, signer *Signer) models.Memory {
+	t.Helper()
+	team := "platform"
+	m := models.Memory{
+		ID:      "01JTEST0000000000000000000",
+		Type:    models.MemoryTypeDecision,
+		Content: "Use pgx instead of lib/pq for Postgres access",
+		Summary: "Use pgx",
+		Scope:   models.MemoryScopeTeam,
+		TeamID:  &team,
+		Topics:  []string{"postgres", "go"},
+		Author:  "Alice <alice@example.com>",
+	}
+	signer.Sign(&m)
+	return m
+}
+
+func TestLoadSignerMatchesSSHKeygenPublicKey(t *testing.T) {
+	signer := loadTestSigner(t)
+	pub, err := os.ReadFile(filepath.Join("testdata", "id_ed25519.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := normalizePublicKey(string(pub)); signer.PublicKey() != want {
+		t.Fatalf("public key = %q, want %q", signer.PublicKey(), want)
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	signer := loadTestSigner(t)
+	m := signedMemory(t, signer)
+
+	// Trusted keys usually carry a comment, as in authorized_keys
+	trusted := []string{signer.PublicKey() + " alice@example.com"}
+	if err := Verify(&m, trusted); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// Topic order and local bookkeeping aren't covered by the signature
+	m.Topics = []string{"go", "postgres"}
+	m.Importance, m.AccessCount = 0.1, 42
+	if err := Verify(&m, trusted); err != nil {
+		t.Fatalf("verify after reordering topics and touching local stats: %v", err)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	signer := loadTestSigner(t)
+	trusted := []string{signer.PublicKey()}
+
+	tests := []struct {
+		name   string
+		tamper func(m *models.Memory)
+	}{
+		{"content", func(m *models.Memory) { m.Content = "Use lib/pq for Postgres access" }},
+		{"summary", func(m *models.Memory) { m.Summary = "Use lib/pq" }},
+		{"type", func(m *models.Memory) { m.Type = models.MemoryTypeFact }},
+		{"scope", func(m *models.Memory) { m.Scope = models.MemoryScopeOrg }},
+		{"team", func(m *models.Memory) { other := "security"; m.TeamID = &other }},
+		{"topic added", func(m *models.Memory) { m.Topics = append(m.Topics, "mysql") }},
+		{"topic removed", func(m *models.Memory) { m.Topics = m.Topics[:1] }},
+		{"author", func(m *models.Memory) { m.Author = "Mallory <mallory@example.com>" }},
+		{"author removed", func(m *models.Memory) { m.Author = "" }},
+		{"id", func(m *models.Memory) { m.ID = "01JTEST0000000000000000001" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := signedMemory(t, signer)
+			tt.tamper(&m)
+			if err := Verify(&m, trusted); err == nil {
+				t.Fatal("verify accepted a tampered memory")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsBadSignatures(t *testing.T) {
+	signer := loadTestSigner(t)
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	impostor := &Signer{key: other, publicKey: formatPublicKey(other.Public().(ed25519.PublicKey))}
+
+	tests := []struct {
+		name    string
+		mutate  func(m *models.Memory)
+		trusted func() []string
+		want    error
+	}{
+		{
+			name:    "unsigned",
+			mutate:  func(m *models.Memory) { m.Signature, m.Signer = "", "" },
+			trusted: func() []string { return []string{signer.PublicKey()} },
+			want:    ErrUnsigned,
+		},
+		{
+			name:    "untrusted signer",
+			mutate:  func(m *models.Memory) {},
+			trusted: func() []string { return []string{impostor.PublicKey()} },
+		},
+		{
+			name:    "no trusted signers",
+			mutate:  func(m *models.Memory) {},
+			trusted: func() []string { return nil },
+		},
+		{
+			name:    "signer swapped for a trusted key",
+			mutate:  func(m *models.Memory) { m.Signer = impostor.PublicKey() },
+			trusted: func() []string { return []string{signer.PublicKey(), impostor.PublicKey()} },
+		},
+		{
+			name:    "signature not base64",
+			mutate:  func(m *models.Memory) { m.Signature = "not base64!" },
+			trusted: func() []string { return []string{signer.PublicKey()} },
+		},
+		{
+			name:    "signature truncated",
+			mutate:  func(m *models.Memory) { m.Signature = m.Signature[:20] },
+			trusted: func() []string { return []string{signer.PublicKey()} },
+		},
+		{
+			name:    "malformed signer key",
+			mutate:  func(m *models.Memory) { m.Signer = "ssh-ed25519 AAAA" },
+			trusted: func() []string { return []string{"ssh-ed25519 AAAA"} },
+		},
+		{
+			name:    "unsupported signer key type",
+			mutate:  func(m *models.Memory) { m.Signer = "ssh-rsa AAAAB3NzaC1yc2E" },
+			trusted: func() []string { return []string{"ssh-rsa AAAAB3NzaC1yc2E"} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := signedMemory(t, signer)
+			tt.mutate(&m)
+			err := Verify(&m, tt.trusted())
+			if err == nil {
+				t.Fatal("verify succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAllowedSigners(t *testing.T) {
+	signer := loadTestSigner(t)
+	path := filepath.Join(t.TempDir(), "allowed_signers")
+	content := "# team keys\n\n" + signer.PublicKey() + " alice@example.com\n  \n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := LoadAllowedSigners(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != signer.PublicKey() {
+		t.Fatalf("keys = %q, want [%q]", keys, signer.PublicKey())
+	}
+}
+
+func TestLoadSignerRejectsUnsupportedKeys(t *testing.T) {
+	tests := []struct {
+		file, want string
+	}{
+		{"id_ed25519_encrypted", "encrypted keys are not supported"},
+		{"id_ecdsa", "unsupported key type"},
+		{"id_ed25519.pub", "not an OpenSSH private key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			_, err := LoadSigner(filepath.Join("testdata", tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("load = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// openSSHKey builds an "openssh-key-v1" container for an ed25519 key,
+// letting tests break it in specific ways
+type openSSHKey struct {
+	cipher         string
+	keys           uint32
+	check1, check2 uint32
+	keyType        string
+	priv           []byte
+}
+
+func validOpenSSHKey() openSSHKey {
+	priv := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	return openSSHKey{cipher: "none", keys: 1, check1: 42, check2: 42, keyType: sshKeyType, priv: priv}
+}
+
+func (k openSSHKey) encode() []byte {
+	pub := []byte(k.priv)[32:]
+	var pubBlob bytes.Buffer
+	writeString(&pubBlob, []byte(k.keyType))
+	writeString(&pubBlob, pub)
+
+	var private bytes.Buffer
+	binary.Write(&private, binary.BigEndian, k.check1)
+	binary.Write(&private, binary.BigEndian, k.check2)
+	writeString(&private, []byte(k.keyType))
+	writeString(&private, pub)
+	writeString(&private, k.priv)
+	writeString(&private, []byte("comment"))
+
+	var buf bytes.Buffer
+	buf.WriteString("openssh-key-v1\x00")
+	writeString(&buf, []byte(k.cipher))
+	writeString(&buf, []byte("none"))
+	writeString(&buf, nil)
+	binary.Write(&buf, binary.BigEndian, k.keys)
+	writeString(&buf, pubBlob.Bytes())
+	writeString(&buf, private.Bytes())
+	return buf.Bytes()
+}
+
+func TestParseOpenSSHPrivateKey(t *testing.T) {
+	valid := validOpenSSHKey()
+	key, err := parseOpenSSHPrivateKey(valid.encode())
+	if err != nil {
+		t.Fatalf("parse valid key: %v", err)
+	}
+	if !bytes.Equal(key, valid.priv) {
+		t.Fatal("parsed key differs from the encoded one")
+	}
+
+	tests := []struct {
+		name string
+		data func() []byte
+	}{
+		{"empty", func() []byte { return nil }},
+		{"bad magic", func() []byte { return append([]byte("openssh-key-v2\x00"), valid.encode()[15:]...) }},
+		{"encrypted", func() []byte { k := valid; k.cipher = "aes256-ctr"; return k.encode() }},
+		{"two keys", func() []byte { k := valid; k.keys = 2; return k.encode() }},
+		{"no keys", func() []byte { k := valid; k.keys = 0; return k.encode() }},
+		{"check mismatch", func() []byte { k := valid; k.check2++; return k.encode() }},
+		{"rsa", func() []byte { k := valid; k.keyType = "ssh-rsa"; return k.encode() }},
+		{"short private key", func() []byte { k := valid; k.priv = k.priv[:40]; return k.encode() }},
+		{"length past the end", func() []byte {
+			data := valid.encode()
+			binary.BigEndian.PutUint32(data[15:], 1<<31)
+			return data
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseOpenSSHPrivateKey(tt.data()); err == nil {
+				t.Fatal("parse succeeded")
+			}
+		})
+	}
+
+	// Every truncation of a valid key must fail cleanly, not panic
+	data := valid.encode()
+	for n := 0; n < len(data); n++ {
+		if _, err := parseOpenSSHPrivateKey(data[:n]); err == nil {
+			t.Fatalf("parse of the first %d of %d bytes succeeded", n, len(data))
+		}
+	}
+}
+
+func TestLoadSignerRejectsMalformedPEM(t *testing.T) {
+	dir := t.TempDir()
+	k := validOpenSSHKey()
+	k.check2++
+	files := map[string][]byte{
+		"garbage":      []byte("not a key at all"),
+		"wrong block":  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: k.encode()}),
+		"corrupt body": pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: k.encode()}),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_"))
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSigner(path); err == nil {
+			t.Errorf("%s: load succeeded", name)
+		}
+	}
+	if _, err := LoadSigner(filepath.Join(dir, "missing")); err == nil {
+		t.Error("loading a missing key succeeded")
+	}
+}
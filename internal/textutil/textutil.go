@@ -0,0 +1,25 @@
+// Package textutil holds small string helpers shared across packages
+package textutil
+
+import "strings"
+
+// Truncate shortens s to at most maxLen bytes, ending it with "..." when
+// cut. It never splits a UTF-8 character.
+func Truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	if maxLen < 3 {
+		return ""
+	}
+	cut := maxLen - 3
+	for cut > 0 && s[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return s[:cut] + "..."
+}
+
+// ShellQuote single-quotes s for POSIX shells
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
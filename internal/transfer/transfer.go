@@ -0,0 +1,291 @@
+// Package transfer exports memories, with their projects and embeddings,
+// to portable files and imports them back, for backups, moving to a new
+// machine, or sharing a curated set.
+//
+// Two formats are written. A bundle is a single JSON document:
+//
+//	{"version": 1, "exportedAt": "...", "projects": [...], "memories": [...]}
+//
+// JSON Lines puts one record per line, projects first, so large exports
+// can be streamed and filtered with line tools:
+//
+//	{"project": {...}}
+//	{"memory": {...}}
+//
+// Read accepts either.
+package transfer
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/internal/teamsync"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// Version is the bundle format version
+const Version = 1
+
+// Formats
+const (
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+)
+
+// Bundle is a full export
+type Bundle struct {
+	Version    int              `json:"version"`
+	ExportedAt time.Time        `json:"exportedAt"`
+	Projects   []models.Project `json:"projects"`
+	Memories   []models.Memory  `json:"memories"`
+}
+
+// record is one line of a JSON Lines export
+type record struct {
+	Project *models.Project `json:"project,omitempty"`
+	Memory  *models.Memory  `json:"memory,omitempty"`
+}
+
+// Export collects every project and memory in the store
+func Export(s *store.Store, now time.Time) (*Bundle, error) {
+	projects, err := s.ListProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	memories, err := s.ExportMemories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+	if projects == nil {
+		projects = []models.Project{}
+	}
+	if memories == nil {
+		memories = []models.Memory{}
+	}
+	return &Bundle{Version: Version, ExportedAt: now, Projects: projects, Memories: memories}, nil
+}
+
+// Write writes a bundle in the given format
+func Write(w io.Writer, b *Bundle, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for i := range b.Projects {
+			if err := enc.Encode(record{Project: &b.Projects[i]}); err != nil {
+				return err
+			}
+		}
+		for i := range b.Memories {
+			if err := enc.Encode(record{Memory: &b.Memories[i]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (json|jsonl)", format)
+}
+
+// Read reads a bundle or JSON Lines export
+func Read(r io.Reader) (*Bundle, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("empty export")
+		}
+		return nil, fmt.Errorf("not a MemoryPilot export: %w", err)
+	}
+
+	var probe struct {
+		Version *int `json:"version"`
+	}
+	if err := json.Unmarshal(first, &probe); err != nil {
+		return nil, fmt.Errorf("not a MemoryPilot export: %w", err)
+	}
+	if probe.Version != nil {
+		var b Bundle
+		if err := json.Unmarshal(first, &b); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+		if b.Version > Version {
+			return nil, fmt.Errorf("bundle version %d is newer than this MemoryPilot supports (%d)", b.Version, Version)
+		}
+		return &b, nil
+	}
+
+	b := &Bundle{Version: Version}
+	raw := first
+	for n := 1; ; n++ {
+		var rec record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, fmt.Errorf("record %d: %w", n, err)
+		}
+		switch {
+		case rec.Project != nil:
+			b.Projects = append(b.Projects, *rec.Project)
+		case rec.Memory != nil:
+			b.Memories = append(b.Memories, *rec.Memory)
+		default:
+			return nil, fmt.Errorf("record %d: neither a project nor a memory", n)
+		}
+
+		raw = nil
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return b, nil
+			}
+			return nil, fmt.Errorf("record %d: %w", n+1, err)
+		}
+	}
+}
+
+// Conflict says what happens to an imported memory whose ID already exists
+type Conflict string
+
+const (
+	ConflictSkip      Conflict = "skip"      // keep the local memory
+	ConflictOverwrite Conflict = "overwrite" // replace it with the imported one
+	ConflictMerge     Conflict = "merge"     // keep the newer edits and all topics
+)
+
+// ParseConflict validates a conflict mode
+func ParseConflict(v string) (Conflict, error) {
+	switch c := Conflict(v); c {
+	case ConflictSkip, ConflictOverwrite, ConflictMerge:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown conflict mode %q (skip|overwrite|merge)", v)
+}
+
+// Result counts what an import did, or would do
+type Result struct {
+	Projects    int `json:"projects"`    // projects added
+	Added       int `json:"added"`       // new memories
+	Duplicates  int `json:"duplicates"`  // new IDs whose content was already stored
+	Skipped     int `json:"skipped"`     // existing IDs left alone
+	Overwritten int `json:"overwritten"` // existing IDs replaced
+	Merged      int `json:"merged"`      // existing IDs merged
+	Held        int `json:"held"`        // existing IDs under legal hold, left alone
+}
+
+// Import adds a bundle's projects and memories to the store. With dryRun
+// nothing is written and the result counts what would happen.
+func Import(s *store.Store, b *Bundle, conflict Conflict, dryRun bool) (Result, error) {
+	var res Result
+
+	// Map exported project IDs to local ones; a project at a path already
+	// known here is the same project
+	projectIDs := make(map[string]string, len(b.Projects))
+	for i := range b.Projects {
+		p := b.Projects[i]
+		existing, err := s.GetProjectByPath(p.Path)
+		if err != nil {
+			return res, err
+		}
+		if existing != nil {
+			projectIDs[p.ID] = existing.ID
+			continue
+		}
+		res.Projects++
+		projectIDs[p.ID] = p.ID
+		if dryRun {
+			continue
+		}
+		id, err := s.ImportProject(&p)
+		if err != nil {
+			return res, fmt.Errorf("failed to import project %s: %w", p.Name, err)
+		}
+		projectIDs[p.ID] = id
+	}
+
+	for i := range b.Memories {
+		m := b.Memories[i]
+		if m.ProjectID != nil {
+			if id, ok := projectIDs[*m.ProjectID]; ok {
+				m.ProjectID = &id
+			}
+		}
+		if err := importMemory(s, &m, conflict, dryRun, &res); err != nil {
+			return res, fmt.Errorf("failed to import memory %s: %w", m.ID, err)
+		}
+	}
+	return res, nil
+}
+
+func importMemory(s *store.Store, m *models.Memory, conflict Conflict, dryRun bool, res *Result) error {
+	local, err := s.GetMemory(m.ID)
+	if err != nil {
+		return err
+	}
+
+	if local == nil {
+		if dryRun {
+			res.Added++
+			return nil
+		}
+		err := s.CreateMemory(m)
+		switch {
+		case errors.Is(err, store.ErrDuplicate):
+			res.Duplicates++
+		case err != nil:
+			return err
+		default:
+			res.Added++
+		}
+		return nil
+	}
+
+	if conflict == ConflictSkip {
+		res.Skipped++
+		return nil
+	}
+	if held, err := s.IsHeld(m.ID); err != nil {
+		return err
+	} else if held {
+		res.Held++
+		return nil
+	}
+	if dryRun {
+		if conflict == ConflictOverwrite {
+			res.Overwritten++
+		} else {
+			res.Merged++
+		}
+		return nil
+	}
+
+	if conflict == ConflictOverwrite {
+		if err := s.UpsertMemory(m); err != nil {
+			return err
+		}
+		res.Overwritten++
+		return nil
+	}
+
+	merged := teamsync.Merge(*local, *m)
+	merged.Topics = models.UnionTopics(local.Topics, m.Topics)
+	if merged.Content == m.Content && len(m.Embedding) > 0 {
+		merged.Embedding = m.Embedding
+	} else {
+		// GetMemory doesn't load embeddings; keep the local one
+		embeddings, err := s.MemoryEmbeddings([]string{m.ID})
+		if err != nil {
+			return err
+		}
+		merged.Embedding = embeddings[m.ID]
+	}
+	if err := s.UpdateMemory(&merged); err != nil && !errors.Is(err, store.ErrDuplicate) {
+		return err
+	}
+	res.Merged++
+	return nil
+}
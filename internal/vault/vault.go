@@ -0,0 +1,315 @@
+// Package vault writes memories as Markdown notes with YAML frontmatter
+// into a directory that opens as an Obsidian vault:
+//
+//	<vault>/<project>/<type>/<summary> (<id>).md
+//
+// Memories without a project go under "Personal". Related memories are
+// linked with [[wikilinks]] by note name, which Obsidian resolves anywhere
+// in the vault. A manifest under .memorypilot/ records the notes written,
+// so a later sync rewrites only what changed and removes only its own
+// notes, never ones the user added.
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+// manifestPath is where the manifest lives, relative to the vault
+const manifestPath = ".memorypilot/vault.json"
+
+// personalFolder holds memories that belong to no project
+const personalFolder = "Personal"
+
+// idLength is how much of a memory ID goes in its note name to keep names
+// with the same summary apart. It is taken from the end, since ULIDs start
+// with their timestamp.
+const idLength = 8
+
+// maxTitle caps the summary part of a note name, in runes
+const maxTitle = 80
+
+// Result counts what a sync changed
+type Result struct {
+	Written   int // new notes
+	Updated   int
+	Removed   int // notes of memories no longer exported
+	Unchanged int
+}
+
+// Changed reports whether the sync touched the vault
+func (r Result) Changed() bool {
+	return r.Written+r.Updated+r.Removed > 0
+}
+
+// entry is a note recorded in the manifest
+type entry struct {
+	Path string `json:"path"` // relative to the vault, slash-separated
+	Hash string `json:"hash"`
+}
+
+type manifest struct {
+	Notes map[string]entry `json:"notes"` // by memory ID
+}
+
+// Sync brings the vault at dir in line with the store's approved memories
+func Sync(s *store.Store, dir string) (Result, error) {
+	memories, err := s.ListMemories(models.RecallRequest{})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to list memories: %w", err)
+	}
+	projects, err := s.ListProjects()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to list projects: %w", err)
+	}
+	names := make(map[string]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+	return Write(dir, memories, names)
+}
+
+// Write brings the vault at dir in line with memories; projectNames maps
+// project IDs to the folder their memories go in
+func Write(dir string, memories []models.Memory, projectNames map[string]string) (Result, error) {
+	var res Result
+	old, err := readManifest(dir)
+	if err != nil {
+		return res, err
+	}
+
+	notes := make(map[string]string, len(memories)) // memory ID -> note name
+	for _, m := range memories {
+		notes[m.ID] = noteName(m)
+	}
+
+	next := manifest{Notes: make(map[string]entry, len(memories))}
+	for _, m := range memories {
+		rel := filepath.ToSlash(filepath.Join(folder(m, projectNames), string(m.Type), notes[m.ID]+".md"))
+		data := render(m, projectNames, notes)
+		hash := hashOf(data)
+		next.Notes[m.ID] = entry{Path: rel, Hash: hash}
+
+		prev, known := old.Notes[m.ID]
+		if known && prev.Path == rel && prev.Hash == hash && exists(filepath.Join(dir, filepath.FromSlash(rel))) {
+			res.Unchanged++
+			continue
+		}
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return res, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return res, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		if known && prev.Path != rel {
+			// Renamed or moved: drop the note at its old path
+			removeNote(dir, prev.Path)
+		}
+		if known {
+			res.Updated++
+		} else {
+			res.Written++
+		}
+	}
+
+	for id, prev := range old.Notes {
+		if _, ok := next.Notes[id]; ok {
+			continue
+		}
+		removeNote(dir, prev.Path)
+		res.Removed++
+	}
+
+	return res, writeManifest(dir, next)
+}
+
+// render writes a memory as a note
+func render(m models.Memory, projectNames map[string]string, notes map[string]string) []byte {
+	var b strings.Builder
+	field := func(name string, value interface{}) {
+		data, _ := json.Marshal(value)
+		fmt.Fprintf(&b, "%s: %s\n", name, data)
+	}
+	list := func(name string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		b.WriteString(name + ":\n")
+		for _, v := range values {
+			data, _ := json.Marshal(v)
+			fmt.Fprintf(&b, "  - %s\n", data)
+		}
+	}
+
+	var related []string
+	for _, id := range m.RelatedMemories {
+		if name, ok := notes[id]; ok {
+			related = append(related, "[["+name+"]]")
+		}
+	}
+
+	b.WriteString("---\n")
+	field("id", m.ID)
+	field("type", string(m.Type))
+	field("scope", string(m.Scope))
+	if m.ProjectID != nil {
+		if name, ok := projectNames[*m.ProjectID]; ok {
+			field("project", name)
+		}
+	}
+	list("topics", m.Topics)
+	list("tags", tags(m.Topics))
+	field("confidence", m.Confidence)
+	field("source", string(m.Source.Type))
+	if m.Source.Reference != "" {
+		field("source_ref", m.Source.Reference)
+	}
+	if m.Author != "" {
+		field("author", m.Author)
+	}
+	field("created", m.CreatedAt.UTC().Format(time.RFC3339))
+	if m.ExpiresAt != nil {
+		field("expires", m.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	list("aliases", []string{m.Summary})
+	list("related", related)
+	b.WriteString("---\n\n")
+
+	fmt.Fprintf(&b, "# %s\n\n", m.Summary)
+	b.WriteString(strings.TrimSpace(m.Content))
+	b.WriteString("\n")
+	if len(related) > 0 {
+		b.WriteString("\n## Related\n\n")
+		for _, link := range related {
+			b.WriteString("- " + link + "\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+// noteName is a memory's summary, made safe for a file name, with the
+// end of its ID
+func noteName(m models.Memory) string {
+	title := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', '#', '^', '[', ']':
+			return '-'
+		}
+		if r < ' ' {
+			return ' '
+		}
+		return r
+	}, m.Summary)
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > maxTitle {
+		title = strings.TrimSpace(string(runes[:maxTitle]))
+	}
+	title = strings.Trim(title, ".")
+	id := m.ID
+	if len(id) > idLength {
+		id = id[len(id)-idLength:]
+	}
+	if title == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", title, id)
+}
+
+// folder is the top-level folder of a memory's note
+func folder(m models.Memory, projectNames map[string]string) string {
+	if m.ProjectID != nil {
+		if name := safeName(projectNames[*m.ProjectID]); name != "" {
+			return name
+		}
+	}
+	return personalFolder
+}
+
+func safeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '-'
+		}
+		return r
+	}, name)
+	return strings.Trim(strings.TrimSpace(name), ".")
+}
+
+// tags turns topics into Obsidian tags, which can't contain spaces
+func tags(topics []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range topics {
+		tag := strings.Join(strings.Fields(strings.ToLower(t)), "-")
+		tag = strings.Trim(tag, "#")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func readManifest(dir string) (manifest, error) {
+	m := manifest{Notes: map[string]entry{}}
+	data, err := os.ReadFile(filepath.Join(dir, manifestPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("invalid vault manifest: %w", err)
+	}
+	if m.Notes == nil {
+		m.Notes = map[string]entry{}
+	}
+	return m, nil
+}
+
+func writeManifest(dir string, m manifest) error {
+	path := filepath.Join(dir, manifestPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, _ := json.MarshalIndent(m, "", "  ")
+	return os.WriteFile(path, data, 0644)
+}
+
+// removeNote deletes a note and any folders it leaves empty
+func removeNote(dir, rel string) {
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.Remove(path); err != nil {
+		return
+	}
+	for d := filepath.Dir(path); d != filepath.Clean(dir); d = filepath.Dir(d) {
+		if os.Remove(d) != nil {
+			break
+		}
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+// Package vectors embeds the summaries, topics and long content chunks of
+// memories as vectors of their own, which hybrid search matches queries
+// against alongside content embeddings
+package vectors
+
+import (
+	"slices"
+
+	"github.com/memorypilot/memorypilot/internal/embedding"
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+// DefaultBatchSize is how many memories are embedded per batch
+const DefaultBatchSize = 32
+
+// Fill embeds the missing summary, topics and chunk vectors of up to limit
+// memories (0 for all) whose content embedding came from emb's model,
+// returning how many vectors it stored. Vectors emb can't produce, or of
+// another dimension than the content embedding, are left missing.
+func Fill(s *store.Store, emb embedding.Embedder, limit, batch int) (int, error) {
+	if batch <= 0 {
+		batch = DefaultBatchSize
+	}
+	model := embedding.ModelOf(emb)
+
+	stored, seen := 0, 0
+	after := ""
+	for limit <= 0 || seen < limit {
+		n := batch
+		if limit > 0 {
+			n = min(batch, limit-seen)
+		}
+		memories, err := s.MemoriesMissingVectors(model, after, n)
+		if err != nil {
+			return stored, err
+		}
+		if len(memories) == 0 {
+			break
+		}
+		after = memories[len(memories)-1].ID
+		seen += len(memories)
+
+		type target struct {
+			id, kind string
+			dims     int
+		}
+		var texts []string
+		var targets []target
+		for _, m := range memories {
+			for kind, text := range store.VectorTexts(m.Memory) {
+				if slices.Contains(m.Kinds, store.VectorClass(kind)) {
+					texts = append(texts, text)
+					targets = append(targets, target{m.ID, kind, m.Dimensions})
+				}
+			}
+		}
+		if len(texts) == 0 {
+			continue
+		}
+		vecs, err := emb.EmbedBatch(texts)
+		if err != nil {
+			return stored, err
+		}
+		for i, t := range targets {
+			if len(vecs[i]) == 0 || len(vecs[i]) != t.dims {
+				continue
+			}
+			if err := s.SetMemoryVector(t.id, t.kind, vecs[i]); err != nil {
+				return stored, err
+			}
+			stored++
+		}
+	}
+	return stored, nil
+}
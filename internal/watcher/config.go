@@ -0,0 +1,69 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// preferenceConfigNames are editor, formatter, linter and AI tool settings
+// whose changes reveal personal preferences
+var preferenceConfigNames = map[string]bool{
+	".editorconfig":  true,
+	".gitconfig":     true,
+	".vimrc":         true,
+	"init.vim":       true,
+	"init.lua":       true,
+	".golangci.yml":  true,
+	".golangci.yaml": true,
+	"rustfmt.toml":   true,
+	".rustfmt.toml":  true,
+	"ruff.toml":      true,
+	".ruff.toml":     true,
+	".rubocop.yml":   true,
+	".clang-format":  true,
+	"biome.json":     true,
+	".stylelintrc":   true,
+	// AI tool settings
+	".cursorrules":            true,
+	"CLAUDE.md":               true,
+	".aider.conf.yml":         true,
+	"copilot-instructions.md": true,
+}
+
+// preferenceConfigPrefixes match config families with many spellings
+// (.prettierrc.json, .eslintrc.cjs, eslint.config.js, ...)
+var preferenceConfigPrefixes = []string{".prettierrc", "prettier.config.", ".eslintrc", "eslint.config."}
+
+// IsPreferenceConfig reports whether a file holds settings that express
+// personal preferences (tabs vs spaces, lint rules, preferred tools)
+func IsPreferenceConfig(path string) bool {
+	name := filepath.Base(path)
+	if preferenceConfigNames[name] {
+		return true
+	}
+	for _, prefix := range preferenceConfigPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	// Editor settings live in generic file names under known directories
+	slashed := filepath.ToSlash(path)
+	return name == "settings.json" && (strings.Contains(slashed, "/Code/User/") || strings.Contains(slashed, "/.vscode/"))
+}
+
+// preferenceConfigDirs are directories outside code folders that hold
+// personal dotfiles and editor settings. They are watched non-recursively.
+func preferenceConfigDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		home,
+		filepath.Join(home, ".config", "nvim"),
+		filepath.Join(home, ".config", "Code", "User"),
+		filepath.Join(home, "Library", "Application Support", "Code", "User"),
+	}
+}
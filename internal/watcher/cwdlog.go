@@ -0,0 +1,165 @@
+package watcher
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CwdLogName is the shell hook's log file in the data directory
+const CwdLogName = "cwd.log"
+
+const (
+	// cwdRetention is how long logged directories wait for their command
+	// to show up in the history file, which some shells only write on exit
+	cwdRetention = 24 * time.Hour
+
+	// cwdMatchWindow is how far apart the logged and recorded times of the
+	// same command may be
+	cwdMatchWindow = 2 * time.Minute
+
+	// cwdLogMaxSize is the size at which the log is rotated away
+	cwdLogMaxSize = 1 << 20
+)
+
+// cwdRecord is one command logged by the shell hook with its directory
+type cwdRecord struct {
+	at      time.Time
+	cwd     string
+	command string
+}
+
+// cwdLog reads the "<unix time>\t<cwd>\t<command>" lines appended by the
+// shell hook (see 'memorypilot shell-hook'), so commands read from history
+// can be attributed to the directory they ran in
+type cwdLog struct {
+	path    string
+	pos     int64
+	records []cwdRecord
+}
+
+// refresh reads lines appended since the last call and forgets old ones
+func (l *cwdLog) refresh(now time.Time) {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return
+	}
+	if info.Size() < l.pos {
+		l.pos = 0 // truncated or replaced
+	}
+	l.pos += l.read(l.path, l.pos)
+
+	// Rotate the log once it is large; shells append by path, so lines
+	// written while rotating land in the new file or are read from the old
+	if l.pos > cwdLogMaxSize {
+		old := l.path + ".old"
+		if os.Rename(l.path, old) == nil {
+			l.read(old, l.pos)
+			os.Remove(old)
+			l.pos = 0
+		}
+	}
+
+	cutoff := now.Add(-cwdRetention)
+	kept := l.records[:0]
+	for _, r := range l.records {
+		if r.at.After(cutoff) {
+			kept = append(kept, r)
+		}
+	}
+	l.records = kept
+}
+
+// read parses complete lines from path starting at pos, returning the
+// number of bytes consumed
+func (l *cwdLog) read(path string, pos int64) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.Size() <= pos {
+		return 0
+	}
+	data := make([]byte, info.Size()-pos)
+	n, _ := file.ReadAt(data, pos)
+	data = data[:n]
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return 0
+	}
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		secs, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		l.records = append(l.records, cwdRecord{
+			at:      time.Unix(secs, 0),
+			cwd:     parts[1],
+			command: strings.TrimSpace(unescapeCommand(parts[2])),
+		})
+	}
+	return int64(end + 1)
+}
+
+// lookup returns the directory a command ran in, consuming the matching
+// record so repeated commands match their own runs. ranAt is when the
+// history says it ran; when it is zero (no timestamps in the history),
+// the oldest record of the command is used, as history is read in order.
+func (l *cwdLog) lookup(command string, ranAt time.Time) string {
+	command = strings.TrimSpace(command)
+	best := -1
+	var bestGap time.Duration
+	for i, r := range l.records {
+		if r.command != command {
+			continue
+		}
+		if ranAt.IsZero() {
+			best = i
+			break
+		}
+		gap := r.at.Sub(ranAt)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= cwdMatchWindow && (best < 0 || gap < bestGap) {
+			best, bestGap = i, gap
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	cwd := l.records[best].cwd
+	l.records = append(l.records[:best], l.records[best+1:]...)
+	return cwd
+}
+
+// unescapeCommand reverses the hook's escaping of backslashes and newlines
+func unescapeCommand(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				sb.WriteByte('\n')
+			} else {
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the work UnifiedDiff does (lines before × lines
+// after); larger files aren't diffed
+const maxDiffCells = 4_000_000
+
+// diffContext is how many unchanged lines surround each change
+const diffContext = 3
+
+// UnifiedDiff returns a unified diff from prev to next with a few lines of
+// context, "" if they are the same, or ok=false if the files are too large
+// to diff
+func UnifiedDiff(path, prev, next string) (diff string, ok bool) {
+	a := fileLines(prev)
+	b := fileLines(next)
+	if len(a)*len(b) > maxDiffCells {
+		return "", false
+	}
+	ops := diffLines(a, b)
+
+	var hunks []string
+	for start := 0; start < len(ops); {
+		// Find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// Extend the hunk while changes are within two contexts of each other
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from := max(0, start-diffContext)
+		to := min(len(ops), end+diffContext)
+		hunks = append(hunks, formatHunk(ops[from:to]))
+		start = to
+	}
+	if len(hunks) == 0 {
+		return "", true
+	}
+	return fmt.Sprintf("--- a/%s\n+++ b/%s\n%s", path, path, strings.Join(hunks, "")), true
+}
+
+// diffOp is one line of a diff: ' ' kept, '-' removed or '+' added, with
+// its line numbers (1-based) in the old and new file
+type diffOp struct {
+	kind         byte
+	line         string
+	aLine, bLine int
+}
+
+// diffLines computes a line diff from the longest common subsequence
+func diffLines(a, b []string) []diffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i + 1, j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i + 1, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+func formatHunk(ops []diffOp) string {
+	var aStart, bStart, aCount, bCount int
+	var body strings.Builder
+	for _, op := range ops {
+		if op.kind != '+' {
+			if aCount == 0 {
+				aStart = op.aLine
+			}
+			aCount++
+		}
+		if op.kind != '-' {
+			if bCount == 0 {
+				bStart = op.bLine
+			}
+			bCount++
+		}
+		body.WriteByte(op.kind)
+		body.WriteString(op.line)
+		body.WriteByte('\n')
+	}
+	// An empty side is numbered by the line before it
+	if aCount == 0 {
+		aStart = ops[0].aLine
+	}
+	if bCount == 0 {
+		bStart = ops[0].bLine
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", aStart, aCount, bStart, bCount, body.String())
+}
+
+// fileLines splits content into lines, keeping blank and indented ones
+func fileLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -1,6 +1,8 @@
 package watcher
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"log"
 	"os"
 	"path/filepath"
@@ -21,18 +23,70 @@ type FileWatcher struct {
 	stopChan   chan struct{}
 	pending    map[string]time.Time
 	pendingMux sync.Mutex
+
+	// Directories that couldn't be watched because the OS watch limit was
+	// reached are polled for mtime changes instead
+	poller *dirPoller
+
+	// tree watches code directories recursively when supported
+	tree treeWatcher
+
+	// ignore holds directory names skipped besides the built-in ones
+	ignore []string
+
+	// previous holds the version last captured per file, to diff the next
+	// change against and score how significant it is
+	previous map[string]fileVersion
+
+	// Without a recursive watch, code directories are walked a budget of
+	// directories per scanTick, each one watched as it is read
+	scanBudget int
+	scans      ScanStore
 }
 
+// fileDepth is the deepest directory below a code directory that is watched
+const fileDepth = 4
+
+// scanSaveEvery is how many steps of the file tree scan pass between saves
+const scanSaveEvery = 30
+
+// maxPrevious caps how many files' versions are kept
+const maxPrevious = 2000
+
 // NewFileWatcher creates a new file watcher
 func NewFileWatcher(debounce time.Duration, sink EventSink) *FileWatcher {
 	return &FileWatcher{
-		debounce:  debounce,
-		eventSink: sink,
-		stopChan:  make(chan struct{}),
-		pending:   make(map[string]time.Time),
+		debounce:   debounce,
+		eventSink:  sink,
+		stopChan:   make(chan struct{}),
+		pending:    make(map[string]time.Time),
+		previous:   make(map[string]fileVersion),
+		poller:     newDirPoller(),
+		scanBudget: DefaultScanBudget,
+	}
+}
+
+// SetScanBudget caps how many directories are read per second while
+// setting up watches. Call before Start.
+func (w *FileWatcher) SetScanBudget(dirs int) {
+	if dirs > 0 {
+		w.scanBudget = dirs
 	}
 }
 
+// UseScanStore persists the watched tree, so a restart watches it again
+// right away while a fresh scan looks for new directories. Call before
+// Start.
+func (w *FileWatcher) UseScanStore(store ScanStore) {
+	w.scans = store
+}
+
+// Ignore skips directories with these names, in addition to the built-in
+// list (node_modules, .git, ...). Call before Start.
+func (w *FileWatcher) Ignore(names []string) {
+	w.ignore = append(w.ignore, names...)
+}
+
 // Start begins watching for file events
 func (w *FileWatcher) Start() error {
 	watcher, err := fsnotify.NewWatcher()
@@ -51,45 +105,118 @@ func (w *FileWatcher) Start() error {
 		filepath.Join(home, "Projects"),
 	}
 
-	for _, dir := range codeDirs {
-		w.addDirRecursive(dir)
+	// Watch code trees with one recursive watch each where the platform
+	// supports it (FSEvents on macOS): fewer descriptors, and new
+	// subdirectories are covered automatically
+	if tree := newTreeWatcher(w.treeChange); tree != nil {
+		if err := tree.Watch(codeDirs); err == nil {
+			w.tree = tree
+		} else {
+			log.Printf("Recursive file watching unavailable, watching directories one by one: %v", err)
+		}
+	}
+	if w.tree == nil {
+		go w.scanLoop(codeDirs)
+	}
+
+	// Dotfiles and editor settings, for preference extraction
+	for _, dir := range preferenceConfigDirs() {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			w.addDir(dir)
+		}
 	}
 
+	go w.pollLoop()
+
 	return nil
 }
 
+// scanLoop watches the code directories one by one, reading a budget of
+// directories per tick. The tree saved by an earlier run is watched first
+// without reading anything; an unfinished scan is resumed, otherwise a
+// fresh one picks up directories created since.
+func (w *FileWatcher) scanLoop(codeDirs []string) {
+	scan := loadScan(w.scans, ScanFileTree)
+	if scan != nil {
+		for _, dir := range scan.Dirs {
+			w.addDir(dir)
+		}
+	}
+	if scan == nil || scan.Done() {
+		scan = NewScan(codeDirs, fileDepth, time.Now())
+	}
+
+	ticker := time.NewTicker(scanTick)
+	defer ticker.Stop()
+
+	for steps := 1; ; steps++ {
+		for _, dir := range scan.Step(w.scanBudget, w.shouldIgnore, nil) {
+			w.addDir(dir)
+		}
+		if scan.Done() || steps%scanSaveEvery == 0 {
+			if err := scan.save(w.scans, ScanFileTree); err != nil {
+				log.Printf("Failed to save file tree scan: %v", err)
+			}
+		}
+		if scan.Done() {
+			if n := w.poller.count(); n > 0 {
+				log.Printf("File watcher: polling %d directories every %s instead", n, pollInterval)
+			}
+			return
+		}
+
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// addDir watches a directory, falling back to polling it once the OS
+// watch limit has been reached
+func (w *FileWatcher) addDir(path string) {
+	if w.poller.limitHit() {
+		w.poller.add(path)
+		return
+	}
+
+	err := w.watcher.Add(path)
+	if err == nil {
+		return
+	}
+	if isWatchLimit(err) {
+		log.Print(watchLimitGuidance(err))
+		w.poller.setLimitHit()
+		w.poller.add(path)
+	}
+}
+
 // Stop stops the watcher
 func (w *FileWatcher) Stop() {
 	close(w.stopChan)
+	if w.tree != nil {
+		w.tree.Close()
+	}
 	if w.watcher != nil {
 		w.watcher.Close()
 	}
 }
 
-func (w *FileWatcher) addDirRecursive(root string) {
-	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// Skip ignored directories
-		if info.IsDir() {
-			name := info.Name()
-			if w.shouldIgnore(name) {
-				return filepath.SkipDir
-			}
-
-			// Limit depth
-			depth := strings.Count(strings.TrimPrefix(path, root), string(os.PathSeparator))
-			if depth > 4 {
-				return filepath.SkipDir
-			}
-
-			w.watcher.Add(path)
+// treeChange receives a file change from the recursive watcher, dropping
+// anything inside an ignored directory
+func (w *FileWatcher) treeChange(path string) {
+	for _, part := range strings.Split(filepath.Dir(path), string(os.PathSeparator)) {
+		if w.shouldIgnore(part) {
+			return
 		}
-
-		return nil
-	})
+	}
+	if !w.isInteresting(fsnotify.Event{Name: path, Op: fsnotify.Write}) {
+		return
+	}
+	w.pendingMux.Lock()
+	w.pending[path] = time.Now()
+	w.pendingMux.Unlock()
 }
 
 func (w *FileWatcher) shouldIgnore(name string) bool {
@@ -109,7 +236,7 @@ func (w *FileWatcher) shouldIgnore(name string) bool {
 		".cache",
 	}
 
-	for _, ignore := range ignoreList {
+	for _, ignore := range append(ignoreList, w.ignore...) {
 		if name == ignore {
 			return true
 		}
@@ -199,7 +326,22 @@ func (w *FileWatcher) isInteresting(event fsnotify.Event) bool {
 		"Cargo.toml": true, "pom.xml": true, "build.gradle": true,
 	}
 
-	return interestingExts[ext] || interestingNames[name]
+	return interestingExts[ext] || interestingNames[name] || IsPreferenceConfig(event.Name)
+}
+
+// Files up to maxContentSize are captured in full (and diffed against
+// their previous version); files up to maxHashSize are only hashed, to
+// tell unchanged saves apart
+const (
+	maxContentSize = 10000
+	maxHashSize    = 1 << 20
+)
+
+// fileVersion is the last captured version of a file: its hash, and its
+// content when small enough to keep
+type fileVersion struct {
+	hash    string
+	content string
 }
 
 func (w *FileWatcher) emitEvent(path string) {
@@ -208,28 +350,67 @@ func (w *FileWatcher) emitEvent(path string) {
 		return
 	}
 
-	// Read content for small files
-	var content string
-	if info.Size() < 10000 {
-		data, err := os.ReadFile(path)
-		if err == nil {
-			content = string(data)
+	var content, hash string
+	if info.Size() < maxHashSize {
+		if data, err := os.ReadFile(path); err == nil {
+			sum := sha256.Sum256(data)
+			hash = hex.EncodeToString(sum[:])
+			if len(data) < maxContentSize {
+				content = string(data)
+			}
 		}
 	}
 
+	// Config changes get a preference-focused extraction
+	eventType := "file_change"
+	if IsPreferenceConfig(path) {
+		eventType = "config_change"
+	}
+
 	event := models.Event{
 		ID:        ulid.Make().String(),
-		Type:      "file_change",
+		Type:      eventType,
 		Timestamp: time.Now(),
 		Data: map[string]interface{}{
 			"path":     path,
 			"filename": filepath.Base(path),
 			"ext":      filepath.Ext(path),
 			"size":     info.Size(),
-			"content":  content,
 		},
 	}
 
+	// Code changes carry a diff against the last captured version rather
+	// than the whole file; settings files are always sent whole
+	prev := w.previous[path]
+	diffed := false
+	if hash != "" {
+		event.Data["hash"] = hash
+	}
+	switch {
+	case hash != "" && hash == prev.hash:
+		event.Data["unchanged"] = true
+		event.Data["significance"] = 0.0
+		event.Data["linesChanged"] = 0
+		diffed = true
+	case content != "":
+		score, lines := Significance(path, prev.content, content)
+		event.Data["significance"] = score
+		event.Data["linesChanged"] = lines
+		if prev.content != "" {
+			if diff, ok := UnifiedDiff(filepath.Base(path), prev.content, content); ok {
+				event.Data["diff"] = diff
+				event.Data["previousHash"] = prev.hash
+				diffed = true
+			}
+		}
+	}
+	if content != "" && (!diffed || eventType == "config_change") {
+		event.Data["content"] = content
+	}
+	if hash != "" {
+		w.remember(path, fileVersion{hash: hash, content: content})
+	}
+
 	log.Printf("File event: %s", filepath.Base(path))
 
 	select {
@@ -238,3 +419,15 @@ func (w *FileWatcher) emitEvent(path string) {
 		log.Printf("Event queue full, dropping file event")
 	}
 }
+
+// remember keeps a file's last captured version, forgetting an arbitrary
+// file once maxPrevious are kept. Called with pendingMux held.
+func (w *FileWatcher) remember(path string, v fileVersion) {
+	if _, ok := w.previous[path]; !ok && len(w.previous) >= maxPrevious {
+		for p := range w.previous {
+			delete(w.previous, p)
+			break
+		}
+	}
+	w.previous[path] = v
+}
@@ -13,24 +13,101 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
+// RepoRegistry persists discovered git repositories between runs
+type RepoRegistry interface {
+	ListRepos() ([]string, error)
+	SaveRepos(paths []string) error
+	LastCommit(path string) (string, error)
+	SetLastCommit(path, hash string) error
+}
+
+// DiscoveryInterval is how often code directories are re-walked for new repos
+const DiscoveryInterval = time.Hour
+
 // GitWatcher watches git repositories for new commits
 type GitWatcher struct {
-	interval   time.Duration
-	eventSink  EventSink
-	stopChan   chan struct{}
-	lastCommit map[string]string // repo path -> last commit hash
+	interval     time.Duration
+	eventSink    EventSink
+	registry     RepoRegistry
+	stopChan     chan struct{}
+	intervalChan chan time.Duration
+	lastCommit   map[string]string    // repo path -> last commit hash
+	headMtimes   map[string]time.Time // repo path -> last seen HEAD/reflog mtime
+	repos        []string
+
+	// Repo discovery walks the code directories a budget of directories
+	// per scanTick, persisted in scans when set
+	scan       *Scan
+	scanBudget int
+	scans      ScanStore
+
+	// Author filtering: only commits by these identities (plus the repo's
+	// git config user) are captured, unless captureAll is set
+	authors    []string
+	captureAll bool
+	repoIdents map[string][]string
+
+	// Tag and stash tracking (repo path -> known tags / stash count)
+	knownTags      map[string]map[string]bool
+	stashCounts    map[string]int
+	captureStashes bool
 }
 
-// NewGitWatcher creates a new git watcher
-func NewGitWatcher(interval time.Duration, sink EventSink) *GitWatcher {
+// NewGitWatcher creates a new git watcher. registry may be nil, in which case
+// discovered repos are only kept in memory.
+func NewGitWatcher(interval time.Duration, registry RepoRegistry, sink EventSink) *GitWatcher {
 	return &GitWatcher{
-		interval:   interval,
-		eventSink:  sink,
-		stopChan:   make(chan struct{}),
-		lastCommit: make(map[string]string),
+		interval:     interval,
+		eventSink:    sink,
+		registry:     registry,
+		stopChan:     make(chan struct{}),
+		intervalChan: make(chan time.Duration, 1),
+		lastCommit:   make(map[string]string),
+		headMtimes:   make(map[string]time.Time),
+		repoIdents:   make(map[string][]string),
+		knownTags:    make(map[string]map[string]bool),
+		stashCounts:  make(map[string]int),
+		scanBudget:   DefaultScanBudget,
 	}
 }
 
+// SetScanBudget caps how many directories repo discovery reads per second.
+// Call before Start.
+func (w *GitWatcher) SetScanBudget(dirs int) {
+	if dirs > 0 {
+		w.scanBudget = dirs
+	}
+}
+
+// UseScanStore persists repo discovery, so a restart resumes an unfinished
+// walk and skips one that finished within DiscoveryInterval. Call before
+// Start.
+func (w *GitWatcher) UseScanStore(store ScanStore) {
+	w.scans = store
+}
+
+// FilterAuthors restricts capture to commits authored by the given names or
+// emails, in addition to each repo's configured git user. With captureAll,
+// commits from every author are captured (team capture).
+func (w *GitWatcher) FilterAuthors(authors []string, captureAll bool) {
+	w.authors = nil
+	for _, a := range authors {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			w.authors = append(w.authors, a)
+		}
+	}
+	w.captureAll = captureAll
+}
+
+// SetInterval changes the polling interval of a running watcher
+func (w *GitWatcher) SetInterval(interval time.Duration) {
+	select {
+	case <-w.intervalChan:
+	default:
+	}
+	w.intervalChan <- interval
+}
+
 // Start begins watching for git events
 func (w *GitWatcher) Start() error {
 	go w.watch()
@@ -45,6 +122,10 @@ func (w *GitWatcher) Stop() {
 func (w *GitWatcher) watch() {
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
+	scanTicker := time.NewTicker(scanTick)
+	defer scanTicker.Stop()
+
+	w.scan = loadScan(w.scans, ScanRepos)
 
 	// Initial scan
 	w.scanGitRepos()
@@ -53,122 +134,454 @@ func (w *GitWatcher) watch() {
 		select {
 		case <-w.stopChan:
 			return
+		case interval := <-w.intervalChan:
+			w.interval = interval
+			ticker.Reset(interval)
 		case <-ticker.C:
 			w.scanGitRepos()
+		case <-scanTicker.C:
+			w.discoverStep()
 		}
 	}
 }
 
+// scanGitRepos checks registered repos for new commits, starting a new
+// discovery walk when the registry is empty or DiscoveryInterval has passed
 func (w *GitWatcher) scanGitRepos() {
-	// Get home directory
-	home, err := os.UserHomeDir()
+	w.loadRepos()
+
+	if w.scan == nil || w.scan.Done() && time.Since(w.scan.Finished) >= DiscoveryInterval {
+		w.scan = NewScan(DefaultCodeDirs(), repoDepth, time.Now())
+		w.discoverStep()
+	}
+
+	for _, repoPath := range w.repos {
+		if !w.headChanged(repoPath) {
+			continue
+		}
+		w.checkRepo(repoPath)
+		w.checkTags(repoPath)
+		if w.captureStashes {
+			w.checkStash(repoPath)
+		}
+	}
+}
+
+// loadRepos refreshes the repo list from the registry, picking up repos
+// registered on demand (e.g. by 'memorypilot repos scan')
+func (w *GitWatcher) loadRepos() {
+	if w.registry == nil {
+		return
+	}
+	repos, err := w.registry.ListRepos()
 	if err != nil {
+		log.Printf("Failed to load repo registry: %v", err)
 		return
 	}
+	w.repos = repos
+}
 
-	// Common code directories
-	codeDirs := []string{
-		filepath.Join(home, "Documents", "source-code"),
-		filepath.Join(home, "Projects"),
-		filepath.Join(home, "code"),
-		filepath.Join(home, "dev"),
+// discoverStep advances an unfinished discovery walk by one budget of
+// directories. Repos are registered as they are found; once the walk is
+// done, the registry is replaced so repos that disappeared are forgotten.
+func (w *GitWatcher) discoverStep() {
+	if w.scan == nil || w.scan.Done() {
+		return
 	}
+	found := len(w.scan.Found)
+	w.scan.Step(w.scanBudget, nil, isRepo)
 
-	for _, codeDir := range codeDirs {
-		if _, err := os.Stat(codeDir); os.IsNotExist(err) {
+	switch {
+	case w.scan.Done():
+		w.repos = append([]string(nil), w.scan.Found...)
+		log.Printf("Repo discovery finished: %d repos in %d directories", len(w.scan.Found), len(w.scan.Dirs))
+	case len(w.scan.Found) > found:
+		w.repos = mergeRepos(w.repos, w.scan.Found[found:])
+	default:
+		w.saveScan()
+		return
+	}
+	if w.registry != nil {
+		if err := w.registry.SaveRepos(w.repos); err != nil {
+			log.Printf("Failed to save repo registry: %v", err)
+		}
+	}
+	w.saveScan()
+}
+
+func (w *GitWatcher) saveScan() {
+	if err := w.scan.save(w.scans, ScanRepos); err != nil {
+		log.Printf("Failed to save repo discovery: %v", err)
+	}
+}
+
+// mergeRepos adds the repos in found missing from repos
+func mergeRepos(repos, found []string) []string {
+	known := make(map[string]bool, len(repos))
+	for _, r := range repos {
+		known[r] = true
+	}
+	for _, r := range found {
+		if !known[r] {
+			known[r] = true
+			repos = append(repos, r)
+		}
+	}
+	return repos
+}
+
+// watchedRefs are the files under .git whose mtimes signal a change worth
+// running git for: commits, checkouts, merges and resets touch HEAD or its
+// reflog, new tags touch refs/tags or packed-refs, stashes touch the stash log
+var watchedRefs = []string{
+	"HEAD",
+	filepath.Join("logs", "HEAD"),
+	filepath.Join("refs", "tags"),
+	"packed-refs",
+	filepath.Join("logs", "refs", "stash"),
+}
+
+// headChanged reports whether any of the watchedRefs moved since the last
+// check, so unchanged repos are skipped without running git at all
+func (w *GitWatcher) headChanged(repoPath string) bool {
+	var latest time.Time
+	for _, name := range watchedRefs {
+		info, err := os.Stat(filepath.Join(repoPath, ".git", name))
+		if err != nil {
 			continue
 		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	if latest.IsZero() {
+		return false
+	}
 
-		// Find git repos
-		filepath.Walk(codeDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
+	last, seen := w.headMtimes[repoPath]
+	w.headMtimes[repoPath] = latest
+	return !seen || latest.After(last)
+}
 
-			// Skip deep directories
-			depth := strings.Count(strings.TrimPrefix(path, codeDir), string(os.PathSeparator))
-			if depth > 3 {
-				return filepath.SkipDir
-			}
+// DefaultCodeDirs returns the directories searched for git repositories
+func DefaultCodeDirs() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
 
-			// Check for .git directory
-			if info.IsDir() && info.Name() == ".git" {
-				repoPath := filepath.Dir(path)
-				w.checkRepo(repoPath)
-				return filepath.SkipDir
-			}
+	return []string{
+		filepath.Join(home, "Documents", "source-code"),
+		filepath.Join(home, "Projects"),
+		filepath.Join(home, "code"),
+		filepath.Join(home, "dev"),
+	}
+}
 
-			return nil
-		})
+// repoDepth is the deepest directory below a code directory checked for
+// being a repo
+const repoDepth = 2
+
+// isRepo matches directories holding a .git directory
+func isRepo(dir string, entries []os.DirEntry) bool {
+	for _, e := range entries {
+		if e.IsDir() && e.Name() == ".git" {
+			return true
+		}
 	}
+	return false
+}
+
+// DiscoverRepos walks the given directories (up to 3 levels deep) and returns
+// the git repositories found
+func DiscoverRepos(codeDirs []string) []string {
+	scan := NewScan(codeDirs, repoDepth, time.Now())
+	scan.Run(nil, isRepo)
+	return scan.Found
 }
 
 func (w *GitWatcher) checkRepo(repoPath string) {
 	// Get latest commit
-	cmd := exec.Command("git", "-C", repoPath, "log", "-1", "--format=%H|%s|%an|%ae|%ai")
+	cmd := exec.Command("git", "-C", repoPath, "log", "-1", "--format=%H")
 	output, err := cmd.Output()
 	if err != nil {
 		return
 	}
-
-	parts := strings.SplitN(strings.TrimSpace(string(output)), "|", 5)
-	if len(parts) < 5 {
+	hash := strings.TrimSpace(string(output))
+	if hash == "" {
 		return
 	}
 
-	hash := parts[0]
-	message := parts[1]
-	author := parts[2]
-	// email := parts[3]
-	// dateStr := parts[4]
-
-	// Check if this is a new commit
+	// Check if this is a new commit. On first sight in this run, fall back to
+	// the commit persisted by a previous run so gaps are picked up.
 	lastHash, seen := w.lastCommit[repoPath]
+	if !seen && w.registry != nil {
+		if persisted, err := w.registry.LastCommit(repoPath); err == nil && persisted != "" {
+			lastHash, seen = persisted, true
+		}
+	}
 	if seen && lastHash == hash {
+		w.lastCommit[repoPath] = hash
 		return
 	}
 
 	w.lastCommit[repoPath] = hash
+	if w.registry != nil {
+		if err := w.registry.SetLastCommit(repoPath, hash); err != nil {
+			log.Printf("Failed to persist last commit for %s: %v", repoPath, err)
+		}
+	}
 
-	// Skip if this is the first time we're seeing this repo
+	// Skip if this is the first time we've ever seen this repo
 	if !seen {
 		return
 	}
 
-	// Get diff stats
-	diffCmd := exec.Command("git", "-C", repoPath, "diff", "--stat", lastHash+".."+hash)
-	diffOutput, _ := diffCmd.Output()
+	w.emitRange(repoPath, lastHash, hash)
+}
 
-	// Get changed files
-	filesCmd := exec.Command("git", "-C", repoPath, "diff", "--name-only", lastHash+".."+hash)
-	filesOutput, _ := filesCmd.Output()
+// MaxGapCommits is the most commits emitted individually for one range;
+// larger gaps (e.g. a night of work with the daemon off, or a big pull) are
+// summarized into a single git_commit_range event
+const MaxGapCommits = 20
 
-	var files []string
-	scanner := bufio.NewScanner(strings.NewReader(string(filesOutput)))
-	for scanner.Scan() {
-		files = append(files, scanner.Text())
+// gitCommit is one line of 'git log' output
+type gitCommit struct {
+	hash    string
+	message string
+	author  string
+	email   string
+	date    time.Time
+}
+
+// emitRange emits events for the commits in from..to
+func (w *GitWatcher) emitRange(repoPath, from, to string) {
+	commits, err := logRange(repoPath, from+".."+to)
+	if err != nil {
+		// from is unknown to git (rebased away or gc'd); just emit the tip
+		commits, err = logRange(repoPath, "-1", to)
+		if err != nil {
+			return
+		}
+		from = ""
+	}
+	commits = w.ownCommits(repoPath, commits)
+	if len(commits) == 0 {
+		return
+	}
+
+	if len(commits) > MaxGapCommits {
+		w.emitRangeSummary(repoPath, from, to, commits)
+		return
+	}
+
+	// Oldest first so extraction sees history in order
+	for i := len(commits) - 1; i >= 0; i-- {
+		w.emitCommit(repoPath, commits[i])
+	}
+}
+
+// ownCommits drops commits authored by someone other than the user, so
+// pulling a colleague's branch doesn't fill personal memory with their work
+func (w *GitWatcher) ownCommits(repoPath string, commits []gitCommit) []gitCommit {
+	if w.captureAll {
+		return commits
+	}
+
+	idents := w.identities(repoPath)
+	if len(idents) == 0 {
+		// No identity known at all; better to capture than to drop everything
+		return commits
 	}
 
-	// Create event
+	var own []gitCommit
+	for _, c := range commits {
+		author, email := strings.ToLower(c.author), strings.ToLower(c.email)
+		for _, ident := range idents {
+			if ident == email || ident == author {
+				own = append(own, c)
+				break
+			}
+		}
+	}
+
+	if skipped := len(commits) - len(own); skipped > 0 {
+		log.Printf("Git: skipped %d commits by other authors in %s", skipped, filepath.Base(repoPath))
+	}
+	return own
+}
+
+// identities returns the configured authors plus the repo's git user
+// (which includes the global git config)
+func (w *GitWatcher) identities(repoPath string) []string {
+	if idents, ok := w.repoIdents[repoPath]; ok {
+		return idents
+	}
+
+	idents := append([]string{}, w.authors...)
+	for _, key := range []string{"user.email", "user.name"} {
+		output, err := exec.Command("git", "-C", repoPath, "config", key).Output()
+		if err != nil {
+			continue
+		}
+		if v := strings.ToLower(strings.TrimSpace(string(output))); v != "" {
+			idents = append(idents, v)
+		}
+	}
+
+	w.repoIdents[repoPath] = idents
+	return idents
+}
+
+// emitCommit emits a git_commit event for a single commit
+func (w *GitWatcher) emitCommit(repoPath string, c gitCommit) {
+	diffCmd := exec.Command("git", "-C", repoPath, "show", "--stat", "--format=", c.hash)
+	diffOutput, _ := diffCmd.Output()
+
+	filesCmd := exec.Command("git", "-C", repoPath, "show", "--name-only", "--format=", c.hash)
+	filesOutput, _ := filesCmd.Output()
+	files := splitLines(string(filesOutput))
+
 	event := models.Event{
 		ID:        ulid.Make().String(),
 		Type:      "git_commit",
-		Timestamp: time.Now(),
+		Timestamp: c.date,
 		Data: map[string]interface{}{
 			"repo":    repoPath,
-			"hash":    hash,
-			"message": message,
-			"author":  author,
+			"hash":    c.hash,
+			"message": c.message,
+			"author":  c.author,
+			"email":   c.email,
 			"diff":    string(diffOutput),
 			"files":   files,
 		},
 	}
+	if diff := manifestDiff(repoPath, c.hash, files); diff != "" {
+		event.Data["manifestDiff"] = diff
+	}
 
-	log.Printf("Git event: %s - %s", filepath.Base(repoPath), message)
+	log.Printf("Git event: %s - %s", filepath.Base(repoPath), c.message)
+	w.send(event)
+}
+
+// dependencyManifests are the files whose changes in a commit are sent in
+// full, so the dependencies it adds or removes can be read from the diff
+var dependencyManifests = map[string]bool{
+	"go.mod": true, "package.json": true, "requirements.txt": true,
+	"Cargo.toml": true, "Gemfile": true,
+}
 
+// maxManifestDiff is the most of a commit's manifest diff an event carries
+const maxManifestDiff = 8000
+
+// manifestDiff returns the commit's changes to dependency manifests among
+// files, or "" when it touched none
+func manifestDiff(repoPath, hash string, files []string) string {
+	args := []string{"-C", repoPath, "show", "--format=", hash, "--"}
+	var manifests []string
+	for _, f := range files {
+		if dependencyManifests[filepath.Base(f)] {
+			manifests = append(manifests, f)
+		}
+	}
+	if len(manifests) == 0 {
+		return ""
+	}
+	out, err := exec.Command("git", append(args, manifests...)...).Output()
+	if err != nil {
+		return ""
+	}
+	diff := string(out)
+	if len(diff) > maxManifestDiff {
+		// Cut at a line end, so no line is read half
+		diff = diff[:strings.LastIndexByte(diff[:maxManifestDiff], '\n')+1]
+	}
+	return diff
+}
+
+// emitRangeSummary emits one git_commit_range event summarizing a large gap
+func (w *GitWatcher) emitRangeSummary(repoPath, from, to string, commits []gitCommit) {
+	var messages []string
+	for i := len(commits) - 1; i >= 0; i-- {
+		messages = append(messages, commits[i].message)
+	}
+	if len(messages) > 50 {
+		messages = messages[len(messages)-50:]
+	}
+
+	var diffOutput, filesOutput []byte
+	if from != "" {
+		diffOutput, _ = exec.Command("git", "-C", repoPath, "diff", "--shortstat", from+".."+to).Output()
+		filesOutput, _ = exec.Command("git", "-C", repoPath, "diff", "--name-only", from+".."+to).Output()
+	}
+	files := splitLines(string(filesOutput))
+	if len(files) > 100 {
+		files = files[:100]
+	}
+
+	event := models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "git_commit_range",
+		Timestamp: commits[0].date,
+		Data: map[string]interface{}{
+			"repo":     repoPath,
+			"from":     from,
+			"to":       to,
+			"count":    len(commits),
+			"messages": messages,
+			"diff":     strings.TrimSpace(string(diffOutput)),
+			"files":    files,
+		},
+	}
+
+	log.Printf("Git event: %s - %d commits summarized", filepath.Base(repoPath), len(commits))
+	w.send(event)
+}
+
+func (w *GitWatcher) send(event models.Event) {
 	select {
 	case w.eventSink <- event:
 	default:
 		log.Printf("Event queue full, dropping git event")
 	}
 }
+
+// logRange lists commits (newest first) for the given 'git log' arguments
+func logRange(repoPath string, args ...string) ([]gitCommit, error) {
+	cmdArgs := append([]string{"-C", repoPath, "log", "--format=%H|%an|%ae|%aI|%s"}, args...)
+	output, err := exec.Command("git", cmdArgs...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []gitCommit
+	for _, line := range splitLines(string(output)) {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, parts[3])
+		if err != nil {
+			date = time.Now()
+		}
+		commits = append(commits, gitCommit{
+			hash:    parts[0],
+			author:  parts[1],
+			email:   parts[2],
+			date:    date,
+			message: parts[4],
+		})
+	}
+	return commits, nil
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
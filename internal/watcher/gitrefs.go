@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"log"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// releaseTag matches version-like tags (v1.2.3, 2.0.0-rc1, release-4.1)
+var releaseTag = regexp.MustCompile(`^(v|release-)?\d+\.\d+(\.\d+)?([-+.].*)?$`)
+
+// CaptureStashes enables git_stash events. Stash messages are often
+// throwaway, so this is off by default.
+func (w *GitWatcher) CaptureStashes(enabled bool) {
+	w.captureStashes = enabled
+}
+
+// checkTags emits a git_tag event for each tag created since the last check.
+// Tags present the first time a repo is seen are recorded, not emitted.
+func (w *GitWatcher) checkTags(repoPath string) {
+	output, err := exec.Command("git", "-C", repoPath, "for-each-ref",
+		"--sort=creatordate", "--format=%(refname:short)", "refs/tags").Output()
+	if err != nil {
+		return
+	}
+	tags := splitLines(string(output))
+
+	known, seen := w.knownTags[repoPath]
+	if !seen {
+		known = make(map[string]bool)
+		w.knownTags[repoPath] = known
+	}
+
+	previous := ""
+	for _, tag := range tags {
+		if !known[tag] {
+			known[tag] = true
+			if seen {
+				w.emitTag(repoPath, tag, previous)
+			}
+		}
+		previous = tag
+	}
+}
+
+// emitTag emits a git_tag event with the changelog since the previous tag
+func (w *GitWatcher) emitTag(repoPath, tag, previous string) {
+	annotation, _ := exec.Command("git", "-C", repoPath, "tag", "-l", "--format=%(contents)", tag).Output()
+	commit, _ := exec.Command("git", "-C", repoPath, "rev-list", "-n", "1", tag).Output()
+
+	var changes []string
+	if previous != "" {
+		if commits, err := logRange(repoPath, previous+".."+tag); err == nil {
+			for _, c := range commits {
+				changes = append(changes, c.message)
+			}
+		}
+	}
+	if len(changes) > 30 {
+		changes = changes[:30]
+	}
+
+	event := models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "git_tag",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"repo":        repoPath,
+			"tag":         tag,
+			"previousTag": previous,
+			"commit":      strings.TrimSpace(string(commit)),
+			"annotation":  strings.TrimSpace(string(annotation)),
+			"release":     releaseTag.MatchString(tag),
+			"changes":     changes,
+		},
+	}
+
+	log.Printf("Git event: %s - tagged %s", filepath.Base(repoPath), tag)
+	w.send(event)
+}
+
+// checkStash emits a git_stash event when a new stash is pushed
+func (w *GitWatcher) checkStash(repoPath string) {
+	output, err := exec.Command("git", "-C", repoPath, "stash", "list", "--format=%gs").Output()
+	if err != nil {
+		return
+	}
+	stashes := splitLines(string(output))
+
+	last, seen := w.stashCounts[repoPath]
+	w.stashCounts[repoPath] = len(stashes)
+	if !seen || len(stashes) <= last {
+		return
+	}
+
+	// stash@{0} is the newest
+	event := models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "git_stash",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"repo":    repoPath,
+			"message": stashes[0],
+		},
+	}
+
+	log.Printf("Git event: %s - stashed %s", filepath.Base(repoPath), stashes[0])
+	w.send(event)
+}
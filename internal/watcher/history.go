@@ -0,0 +1,154 @@
+package watcher
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zshMeta marks a metafied byte in zsh history: the byte after it is the
+// original XOR 0x20 (zsh escapes bytes it uses internally this way)
+const zshMeta = 0x83
+
+// historyEntry is one command from a shell history file
+type historyEntry struct {
+	Command string
+	Time    time.Time // zero when the history has no timestamps
+}
+
+// parseHistory parses complete entries from the start of data, which must
+// begin at an entry boundary. It returns how many bytes those entries
+// span; an entry still being written at the end is left for later.
+func parseHistory(path string, data []byte) ([]historyEntry, int) {
+	if strings.Contains(filepath.Base(path), "zsh") {
+		return parseZshHistory(data)
+	}
+	return parseBashHistory(data)
+}
+
+// parseZshHistory handles plain and extended (": <start>:<elapsed>;cmd")
+// zsh history. Multi-line commands are stored with each embedded newline
+// escaped by a trailing backslash.
+func parseZshHistory(data []byte) ([]historyEntry, int) {
+	var entries []historyEntry
+	var current []string
+	var started time.Time
+	consumed := 0
+
+	pos := 0
+	for {
+		nl := bytes.IndexByte(data[pos:], '\n')
+		if nl < 0 {
+			break
+		}
+		line := string(unmetafy(data[pos : pos+nl]))
+		pos += nl + 1
+
+		if current == nil {
+			started = time.Time{}
+			if t, cmd, ok := parseZshExtended(line); ok {
+				started, line = t, cmd
+			}
+		}
+
+		// An odd number of trailing backslashes escapes the newline
+		if trailingBackslashes(line)%2 == 1 {
+			current = append(current, line[:len(line)-1])
+			continue
+		}
+
+		current = append(current, line)
+		entries = append(entries, historyEntry{Command: strings.Join(current, "\n"), Time: started})
+		current = nil
+		consumed = pos
+	}
+	return entries, consumed
+}
+
+// parseZshExtended splits an extended history line into its start time
+// and command
+func parseZshExtended(line string) (time.Time, string, bool) {
+	if !strings.HasPrefix(line, ": ") {
+		return time.Time{}, "", false
+	}
+	header, cmd, ok := strings.Cut(line[2:], ";")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	start, _, ok := strings.Cut(header, ":")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	secs, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(secs, 0), cmd, true
+}
+
+// parseBashHistory handles bash history, where HISTTIMEFORMAT adds a
+// "#<unix time>" comment line before each command
+func parseBashHistory(data []byte) ([]historyEntry, int) {
+	var entries []historyEntry
+	var stamp time.Time
+	consumed := 0
+
+	pos := 0
+	for {
+		nl := bytes.IndexByte(data[pos:], '\n')
+		if nl < 0 {
+			break
+		}
+		line := string(data[pos : pos+nl])
+		pos += nl + 1
+
+		if t, ok := parseBashTimestamp(line); ok {
+			stamp = t
+			continue // the command follows on the next line
+		}
+
+		entries = append(entries, historyEntry{Command: line, Time: stamp})
+		stamp = time.Time{}
+		consumed = pos
+	}
+	return entries, consumed
+}
+
+// parseBashTimestamp recognizes a "#1700000000" timestamp comment
+func parseBashTimestamp(line string) (time.Time, bool) {
+	if len(line) < 2 || line[0] != '#' {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(line[1:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// unmetafy reverses zsh's escaping of special bytes
+func unmetafy(b []byte) []byte {
+	if bytes.IndexByte(b, zshMeta) < 0 {
+		return b
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == zshMeta && i+1 < len(b) {
+			i++
+			out = append(out, b[i]^0x20)
+			continue
+		}
+		out = append(out, b[i])
+	}
+	return out
+}
+
+func trailingBackslashes(s string) int {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,208 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseZshHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     []historyEntry
+		consumed int // -1 for all of data
+	}{
+		{
+			name:     "plain",
+			data:     "ls -la\ngit status\n",
+			want:     []historyEntry{{Command: "ls -la"}, {Command: "git status"}},
+			consumed: -1,
+		},
+		{
+			name: "extended",
+			data: ": 1700000000:0;go test ./...\n: 1700000060:12;make build\n",
+			want: []historyEntry{
+				{Command: "go test ./...", Time: time.Unix(1700000000, 0)},
+				{Command: "make build", Time: time.Unix(1700000060, 0)},
+			},
+			consumed: -1,
+		},
+		{
+			name: "multi-line command",
+			data: ": 1700000000:3;for f in *.go; do\\\n  gofmt -l $f\\\ndone\nls\n",
+			want: []historyEntry{
+				{Command: "for f in *.go; do\n  gofmt -l $f\ndone", Time: time.Unix(1700000000, 0)},
+				{Command: "ls"},
+			},
+			consumed: -1,
+		},
+		{
+			name: "escaped backslash doesn't continue the line",
+			data: "echo foo\\\\\necho bar\n",
+			want: []historyEntry{
+				{Command: "echo foo\\\\"},
+				{Command: "echo bar"},
+			},
+			consumed: -1,
+		},
+		{
+			name: "escaped backslash then escaped newline",
+			data: "echo a\\\\\\\nb\n",
+			want: []historyEntry{
+				{Command: "echo a\\\\\nb"},
+			},
+			consumed: -1,
+		},
+		{
+			name: "colon in extended command",
+			data: ": 1700000000:0;echo a:b;c\n",
+			want: []historyEntry{
+				{Command: "echo a:b;c", Time: time.Unix(1700000000, 0)},
+			},
+			consumed: -1,
+		},
+		{
+			name:     "not an extended header",
+			data:     ": not-a-time:0;echo\n",
+			want:     []historyEntry{{Command: ": not-a-time:0;echo"}},
+			consumed: -1,
+		},
+		{
+			// "→" is E2 86 92; zsh stores 0x86 and 0x92 as Meta, byte^0x20
+			name:     "metafied bytes",
+			data:     "echo \xe2\x83\xa6\x83\xb2 done\n",
+			want:     []historyEntry{{Command: "echo → done"}},
+			consumed: -1,
+		},
+		{
+			name: "metafied bytes in a continued line",
+			data: ": 1700000000:0;echo \xe2\x83\xa6\x83\xb2\\\nnext\n",
+			want: []historyEntry{
+				{Command: "echo →\nnext", Time: time.Unix(1700000000, 0)},
+			},
+			consumed: -1,
+		},
+		{
+			name:     "entry still being written",
+			data:     "ls\necho partial",
+			want:     []historyEntry{{Command: "ls"}},
+			consumed: len("ls\n"),
+		},
+		{
+			name:     "continuation still being written",
+			data:     "ls\nfor x in a b; do\\\n  echo $x\\\n",
+			want:     []historyEntry{{Command: "ls"}},
+			consumed: len("ls\n"),
+		},
+		{
+			name:     "empty",
+			data:     "",
+			consumed: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkHistory(t, "/home/me/.zsh_history", tt.data, tt.want, tt.consumed)
+		})
+	}
+}
+
+func TestParseBashHistory(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     []historyEntry
+		consumed int // -1 for all of data
+	}{
+		{
+			name:     "plain",
+			data:     "ls\ncd /tmp\n",
+			want:     []historyEntry{{Command: "ls"}, {Command: "cd /tmp"}},
+			consumed: -1,
+		},
+		{
+			name: "timestamp comments",
+			data: "#1700000000\ngo build\n#1700000100\ngo test\n",
+			want: []historyEntry{
+				{Command: "go build", Time: time.Unix(1700000000, 0)},
+				{Command: "go test", Time: time.Unix(1700000100, 0)},
+			},
+			consumed: -1,
+		},
+		{
+			name: "timestamps on some commands only",
+			data: "ls\n#1700000000\ngo build\npwd\n",
+			want: []historyEntry{
+				{Command: "ls"},
+				{Command: "go build", Time: time.Unix(1700000000, 0)},
+				{Command: "pwd"},
+			},
+			consumed: -1,
+		},
+		{
+			name: "comments that aren't timestamps are commands",
+			data: "# just a note\n#12ab\n",
+			want: []historyEntry{
+				{Command: "# just a note"},
+				{Command: "#12ab"},
+			},
+			consumed: -1,
+		},
+		{
+			name:     "timestamp awaiting its command",
+			data:     "ls\n#1700000000\n",
+			want:     []historyEntry{{Command: "ls"}},
+			consumed: len("ls\n"),
+		},
+		{
+			name:     "command still being written",
+			data:     "#1700000000\ngo bu",
+			consumed: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkHistory(t, "/home/me/.bash_history", tt.data, tt.want, tt.consumed)
+		})
+	}
+}
+
+// A partial read resumes where the last complete entry ended
+func TestParseHistoryResumes(t *testing.T) {
+	data := []byte(": 1700000000:0;first\\\nline\n: 1700000005:0;second\n")
+	split := len(": 1700000000:0;first\\\nli")
+
+	entries, consumed := parseHistory(".zsh_history", data[:split])
+	if len(entries) != 0 || consumed != 0 {
+		t.Fatalf("partial read: %d entries, %d bytes consumed; want none", len(entries), consumed)
+	}
+	entries, consumed = parseHistory(".zsh_history", data[consumed:])
+	if len(entries) != 2 || consumed != len(data) {
+		t.Fatalf("full read: %d entries, %d of %d bytes consumed", len(entries), consumed, len(data))
+	}
+	if entries[0].Command != "first\nline" || entries[1].Command != "second" {
+		t.Fatalf("entries = %q, %q", entries[0].Command, entries[1].Command)
+	}
+}
+
+func checkHistory(t *testing.T, path, data string, want []historyEntry, consumed int) {
+	t.Helper()
+	if consumed < 0 {
+		consumed = len(data)
+	}
+	got, n := parseHistory(path, []byte(data))
+	if n != consumed {
+		t.Errorf("consumed %d bytes, want %d", n, consumed)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries %q, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i].Command != want[i].Command {
+			t.Errorf("entry %d command = %q, want %q", i, got[i].Command, want[i].Command)
+		}
+		if !got[i].Time.Equal(want[i].Time) {
+			t.Errorf("entry %d time = %v, want %v", i, got[i].Time, want[i].Time)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// pollInterval is how often directories without a watch are scanned
+const pollInterval = 30 * time.Second
+
+// isWatchLimit reports whether adding a watch failed because the OS limit
+// was reached: ENOSPC from inotify (max_user_watches), or EMFILE where
+// each watch holds a file descriptor (kqueue)
+func isWatchLimit(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// watchLimitGuidance explains how to lift the watch limit on this platform
+func watchLimitGuidance(err error) string {
+	if runtime.GOOS == "linux" && errors.Is(err, syscall.ENOSPC) {
+		current := "unknown"
+		if data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches"); err == nil {
+			current = strings.TrimSpace(string(data))
+		}
+		return fmt.Sprintf("⚠️  File watcher hit the inotify watch limit (fs.inotify.max_user_watches = %s); "+
+			"falling back to polling for the remaining directories. To watch everything, raise the limit:\n"+
+			"   echo fs.inotify.max_user_watches=524288 | sudo tee /etc/sysctl.d/60-memorypilot.conf && sudo sysctl --system", current)
+	}
+	return fmt.Sprintf("⚠️  File watcher hit the open file limit (%v); falling back to polling for the "+
+		"remaining directories. Raise the limit (ulimit -n) to watch everything.", err)
+}
+
+// dirPoller detects changed files in directories by comparing mtimes
+// between scans. Each directory is scanned non-recursively; subdirectories
+// are registered on their own.
+type dirPoller struct {
+	mu     sync.Mutex
+	dirs   []string
+	mtimes map[string]time.Time
+	hit    bool
+
+	// fresh holds directories added since the last scan, whose files are
+	// recorded rather than reported on their first scan
+	fresh map[string]bool
+}
+
+func newDirPoller() *dirPoller {
+	return &dirPoller{mtimes: make(map[string]time.Time), fresh: make(map[string]bool)}
+}
+
+func (p *dirPoller) add(dir string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.dirs = append(p.dirs, dir)
+	p.fresh[dir] = true
+}
+
+func (p *dirPoller) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.dirs)
+}
+
+func (p *dirPoller) limitHit() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hit
+}
+
+func (p *dirPoller) setLimitHit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.hit = true
+}
+
+// scan returns files that are new or modified since the previous scan. The
+// first scan of a directory only records mtimes, so existing files aren't
+// reported.
+func (p *dirPoller) scan() []string {
+	p.mu.Lock()
+	dirs := append([]string(nil), p.dirs...)
+	p.mu.Unlock()
+
+	var changed []string
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		p.mu.Lock()
+		record := p.fresh[dir]
+		delete(p.fresh, dir)
+		p.mu.Unlock()
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			seen[path] = true
+
+			p.mu.Lock()
+			last, known := p.mtimes[path]
+			p.mtimes[path] = info.ModTime()
+			p.mu.Unlock()
+
+			if !record && (!known || info.ModTime().After(last)) {
+				changed = append(changed, path)
+			}
+		}
+	}
+
+	// Forget deleted files so the map doesn't grow without bound
+	p.mu.Lock()
+	for path := range p.mtimes {
+		if !seen[path] {
+			delete(p.mtimes, path)
+		}
+	}
+	p.mu.Unlock()
+
+	return changed
+}
+
+// pollLoop feeds changes found by the poller into the same debounce path
+// as fsnotify events
+func (w *FileWatcher) pollLoop() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			for _, path := range w.poller.scan() {
+				if !w.isInteresting(fsnotify.Event{Name: path, Op: fsnotify.Write}) {
+					continue
+				}
+				w.pendingMux.Lock()
+				w.pending[path] = time.Now()
+				w.pendingMux.Unlock()
+			}
+		}
+	}
+}
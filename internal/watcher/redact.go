@@ -0,0 +1,41 @@
+package watcher
+
+import "regexp"
+
+// redacted replaces secrets removed from captured text
+const redacted = "[REDACTED]"
+
+// secretPatterns match credentials that show up in terminal sessions. Each
+// pattern's first group, if any, is kept so the redacted text still reads
+// naturally ("password=[REDACTED]").
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(?:-----END [A-Z ]*PRIVATE KEY-----|$)`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{20,}\b`),
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\b`),
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/=-]{8,}`),
+	regexp.MustCompile(`([a-z][a-z0-9+.-]*://[^\s:/@]+:)[^\s@/]+(@)`),
+	regexp.MustCompile(`(?i)(\b[\w.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)["']?\s*[=:]\s*["']?)[^\s"']+`),
+	regexp.MustCompile(`(\s-p)[^\s-][^\s]*`), // mysql -pPASSWORD
+}
+
+// Redact removes credentials from captured terminal text
+func Redact(text string) string {
+	for _, re := range secretPatterns {
+		text = re.ReplaceAllStringFunc(text, func(match string) string {
+			groups := re.FindStringSubmatch(match)
+			switch len(groups) {
+			case 2:
+				return groups[1] + redacted
+			case 3:
+				return groups[1] + redacted + groups[2]
+			default:
+				return redacted
+			}
+		})
+	}
+	return text
+}
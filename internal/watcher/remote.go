@@ -0,0 +1,186 @@
+package watcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/pkg/models"
+)
+
+const (
+	// remoteRetryMin and remoteRetryMax bound the wait before reconnecting
+	// to a remote agent whose connection dropped
+	remoteRetryMin = 5 * time.Second
+	remoteRetryMax = 5 * time.Minute
+
+	// remoteAgentCommand runs the agent on the remote host
+	remoteAgentCommand = "memorypilot remote-agent"
+)
+
+// remoteEventPrefixes are the event types a remote agent may forward
+var remoteEventPrefixes = []string{"git_", "terminal_"}
+
+// RemoteWatcher receives events captured by 'memorypilot remote-agent' on
+// other hosts. The agent is run over ssh, which authenticates both ends and
+// carries its stream of events back, so nothing listens on the network.
+type RemoteWatcher struct {
+	eventSink EventSink
+	stopChan  chan struct{}
+	hosts     []string
+
+	mu   sync.Mutex
+	cmds map[string]*exec.Cmd
+}
+
+// NewRemoteWatcher creates a watcher for remote agents on the given ssh
+// destinations (user@host or ~/.ssh/config aliases)
+func NewRemoteWatcher(hosts []string, sink EventSink) *RemoteWatcher {
+	return &RemoteWatcher{
+		eventSink: sink,
+		stopChan:  make(chan struct{}),
+		hosts:     hosts,
+		cmds:      make(map[string]*exec.Cmd),
+	}
+}
+
+// Start connects to every remote host
+func (w *RemoteWatcher) Start() error {
+	for _, host := range w.hosts {
+		go w.follow(host)
+	}
+	return nil
+}
+
+// Stop disconnects from the remote hosts
+func (w *RemoteWatcher) Stop() {
+	close(w.stopChan)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, cmd := range w.cmds {
+		cmd.Process.Kill()
+	}
+}
+
+// follow keeps a connection to one host's agent, reconnecting with backoff
+func (w *RemoteWatcher) follow(host string) {
+	wait := remoteRetryMin
+	for {
+		started := time.Now()
+		if err := w.connect(host); err != nil {
+			log.Printf("Remote agent on %s disconnected: %v", host, err)
+		}
+		if time.Since(started) > remoteRetryMax {
+			wait = remoteRetryMin // it was up for a while; retry promptly
+		}
+
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(wait):
+		}
+		if wait *= 2; wait > remoteRetryMax {
+			wait = remoteRetryMax
+		}
+	}
+}
+
+// connect runs the remote agent and forwards its events until it exits
+func (w *RemoteWatcher) connect(host string) error {
+	ssh := os.Getenv("MEMORYPILOT_SSH")
+	if ssh == "" {
+		ssh = "ssh"
+	}
+	agentCmd := os.Getenv("MEMORYPILOT_REMOTE_AGENT_CMD")
+	if agentCmd == "" {
+		agentCmd = remoteAgentCommand
+	}
+
+	// BatchMode fails instead of prompting: the daemon has no terminal
+	cmd := exec.Command(ssh, "-T", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30", host, agentCmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	w.mu.Lock()
+	select {
+	case <-w.stopChan:
+		w.mu.Unlock()
+		return nil
+	default:
+	}
+	if err := cmd.Start(); err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	w.cmds[host] = cmd
+	w.mu.Unlock()
+	log.Printf("Connected to remote agent on %s", host)
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var event models.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			log.Printf("Ignoring malformed event from %s: %v", host, err)
+			continue
+		}
+		if !remoteEventAllowed(event) {
+			continue
+		}
+		if event.Data == nil {
+			event.Data = make(map[string]interface{})
+		}
+		event.Data["host"] = host
+		event.ProjectID = nil // remote paths don't map to local projects
+
+		select {
+		case w.eventSink <- event:
+		default:
+			log.Printf("Event queue full, dropping remote event from %s", host)
+		}
+	}
+
+	err = cmd.Wait()
+	w.mu.Lock()
+	delete(w.cmds, host)
+	w.mu.Unlock()
+	if msg := strings.TrimSpace(stderr.String()); err != nil && msg != "" {
+		return &remoteError{err: err, stderr: msg}
+	}
+	return err
+}
+
+// remoteEventAllowed accepts the kinds of events a remote agent captures
+func remoteEventAllowed(e models.Event) bool {
+	if e.ID == "" || e.Timestamp.IsZero() {
+		return false
+	}
+	for _, prefix := range remoteEventPrefixes {
+		if strings.HasPrefix(e.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteError adds what ssh or the agent printed to a failed connection
+type remoteError struct {
+	err    error
+	stderr string
+}
+
+func (e *remoteError) Error() string {
+	return e.err.Error() + ": " + textutil.Truncate(e.stderr, 200)
+}
+
+func (e *remoteError) Unwrap() error { return e.err }
@@ -0,0 +1,164 @@
+package watcher
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultScanBudget is how many directories discovery reads per tick
+const DefaultScanBudget = 200
+
+// scanTick is how often an unfinished scan takes its next step
+const scanTick = time.Second
+
+// ScanStore persists scan state, so a restarted daemon resumes a walk
+// instead of starting over and remembers the tree it already discovered
+type ScanStore interface {
+	LoadScan(name string) ([]byte, error)
+	SaveScan(name string, state []byte) error
+}
+
+// Scan is a breadth-first walk of directory trees that reads at most a
+// budget of directories per step. Discovery over a large home directory
+// used to block capture for minutes; stepping it from a ticker keeps every
+// step short, and the state round-trips through JSON to survive restarts.
+type Scan struct {
+	Roots    []string  `json:"roots"`
+	MaxDepth int       `json:"maxDepth"`        // deepest directory read, roots being 0
+	Queue    []ScanDir `json:"queue,omitempty"` // directories still to read
+	Dirs     []string  `json:"dirs,omitempty"`  // directories read: the discovered tree
+	Found    []string  `json:"found,omitempty"` // directories matched, e.g. repos
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"` // zero while in progress
+}
+
+// ScanDir is a queued directory
+type ScanDir struct {
+	Path  string `json:"path"`
+	Depth int    `json:"depth"`
+}
+
+// NewScan starts a walk of roots, reading directories down to maxDepth
+func NewScan(roots []string, maxDepth int, now time.Time) *Scan {
+	s := &Scan{Roots: roots, MaxDepth: maxDepth, Started: now}
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			s.Queue = append(s.Queue, ScanDir{Path: root})
+		}
+	}
+	if len(s.Queue) == 0 {
+		s.Finished = now
+	}
+	return s
+}
+
+// loadScan restores a saved scan; nil if there is none or it is unreadable
+func loadScan(store ScanStore, name string) *Scan {
+	if store == nil {
+		return nil
+	}
+	data, err := store.LoadScan(name)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	return ParseScan(data)
+}
+
+// ParseScan decodes a saved scan, returning nil if it is unreadable
+func ParseScan(data []byte) *Scan {
+	var s Scan
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil
+	}
+	return &s
+}
+
+// save persists the scan, if there is somewhere to persist it
+func (s *Scan) save(store ScanStore, name string) error {
+	if store == nil {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return store.SaveScan(name, data)
+}
+
+// Done reports whether the walk has finished
+func (s *Scan) Done() bool {
+	return !s.Finished.IsZero()
+}
+
+// Step reads up to budget directories and returns them. Subdirectories
+// named so that skip returns true are left out; a directory for which
+// match returns true is recorded in Found and not descended into.
+func (s *Scan) Step(budget int, skip func(name string) bool, match func(dir string, entries []os.DirEntry) bool) []string {
+	var walked []string
+	for len(s.Queue) > 0 && len(walked) < budget {
+		dir := s.Queue[0]
+		s.Queue = s.Queue[1:]
+
+		entries, err := os.ReadDir(dir.Path)
+		if err != nil {
+			continue
+		}
+		walked = append(walked, dir.Path)
+		s.Dirs = append(s.Dirs, dir.Path)
+
+		if match != nil && match(dir.Path, entries) {
+			s.Found = append(s.Found, dir.Path)
+			continue
+		}
+		if dir.Depth >= s.MaxDepth {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || (skip != nil && skip(entry.Name())) {
+				continue
+			}
+			s.Queue = append(s.Queue, ScanDir{Path: filepath.Join(dir.Path, entry.Name()), Depth: dir.Depth + 1})
+		}
+	}
+	if len(s.Queue) == 0 && s.Finished.IsZero() {
+		s.Finished = time.Now()
+	}
+	return walked
+}
+
+// Run finishes the walk in one go
+func (s *Scan) Run(skip func(name string) bool, match func(dir string, entries []os.DirEntry) bool) {
+	for !s.Done() {
+		s.Step(DefaultScanBudget, skip, match)
+	}
+}
+
+// ScanProgress summarizes a scan for status
+type ScanProgress struct {
+	Name     string    `json:"name"`
+	Dirs     int       `json:"dirs"`   // directories read so far
+	Queued   int       `json:"queued"` // directories known but not yet read
+	Found    int       `json:"found"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// Progress summarizes the scan
+func (s *Scan) Progress(name string) ScanProgress {
+	return ScanProgress{
+		Name:     name,
+		Dirs:     len(s.Dirs),
+		Queued:   len(s.Queue),
+		Found:    len(s.Found),
+		Started:  s.Started,
+		Finished: s.Finished,
+	}
+}
+
+// Names under which the watchers persist their scans
+const (
+	ScanRepos    = "repos"
+	ScanFileTree = "files"
+)
@@ -0,0 +1,105 @@
+package watcher
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+// Significance scores a file change from 0 (trivial: an unchanged save, a
+// whitespace or formatting tweak) to 1 (a substantial edit), comparing the
+// new content with the content last captured. Without earlier content the
+// change is fully significant.
+//
+// The score grows with the number of lines changed, both outright and as
+// a share of the file, and is scaled down when the changed text is
+// repetitive (low character entropy, e.g. a reindented block or a run of
+// separators) and for test files.
+func Significance(path, prev, next string) (score float64, linesChanged int) {
+	if prev == "" {
+		return 1, 0
+	}
+	changed := changedLines(prev, next)
+	if len(changed) == 0 {
+		return 0, 0
+	}
+
+	total := max(1, strings.Count(prev, "\n")+1)
+	size := math.Min(1, float64(len(changed))/20)
+	share := math.Min(1, float64(len(changed))/float64(total))
+	score = 0.5*size + 0.5*share
+
+	score *= math.Min(1, entropy(strings.Join(changed, "\n"))/3.5)
+	if IsTestFile(path) {
+		score *= 0.5
+	}
+	return math.Round(score*100) / 100, len(changed)
+}
+
+// changedLines returns the lines added or removed between two versions,
+// ignoring whitespace and line order
+func changedLines(prev, next string) []string {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(prev, "\n") {
+		if line = normalizeLine(line); line != "" {
+			counts[line]++
+		}
+	}
+	var changed []string
+	for _, line := range strings.Split(next, "\n") {
+		if line = normalizeLine(line); line == "" {
+			continue
+		}
+		if counts[line] > 0 {
+			counts[line]--
+		} else {
+			changed = append(changed, line)
+		}
+	}
+	for line, n := range counts {
+		for ; n > 0; n-- {
+			changed = append(changed, line)
+		}
+	}
+	return changed
+}
+
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// entropy returns the Shannon entropy of text in bits per character;
+// ordinary code is around 4 to 5
+func entropy(text string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range text {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// IsTestFile reports whether a path looks like a test
+func IsTestFile(path string) bool {
+	base := filepath.Base(path)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	lower := strings.ToLower(stem)
+	switch {
+	case strings.HasSuffix(lower, "_test"), strings.HasPrefix(lower, "test_"),
+		strings.HasSuffix(lower, ".test"), strings.HasSuffix(lower, ".spec"),
+		strings.HasSuffix(stem, "Test"), strings.HasSuffix(stem, "Tests"):
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" {
+			return true
+		}
+	}
+	return false
+}
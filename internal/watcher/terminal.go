@@ -1,23 +1,36 @@
 package watcher
 
 import (
-	"bufio"
+	"bytes"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/memorypilot/memorypilot/internal/textutil"
 	"github.com/memorypilot/memorypilot/pkg/models"
 	"github.com/oklog/ulid/v2"
 )
 
+// historyTail is how many bytes before the read position are remembered to
+// find the position again after the history file is rewritten
+const historyTail = 512
+
 // TerminalWatcher watches shell history for commands
 type TerminalWatcher struct {
-	eventSink     EventSink
-	stopChan      chan struct{}
-	historyFiles  []string
-	lastPositions map[string]int64
+	eventSink    EventSink
+	stopChan     chan struct{}
+	historyFiles []string
+	history      map[string]*historyState
+	cwds         *cwdLog // nil unless the shell hook is installed
+}
+
+// historyState tracks how far a history file has been read
+type historyState struct {
+	pos  int64
+	info os.FileInfo // identifies the file, to notice rotation
+	tail []byte      // the bytes just before pos
 }
 
 // NewTerminalWatcher creates a new terminal watcher
@@ -30,16 +43,39 @@ func NewTerminalWatcher(sink EventSink) *TerminalWatcher {
 			filepath.Join(home, ".zsh_history"),
 			filepath.Join(home, ".bash_history"),
 		},
-		lastPositions: make(map[string]int64),
+		history: make(map[string]*historyState),
 	}
 }
 
+// SetHistoryFiles replaces the shell history files that are read. Call
+// before Start.
+func (w *TerminalWatcher) SetHistoryFiles(paths []string) {
+	w.historyFiles = paths
+}
+
+// UseCwdLog attributes commands to the directory they ran in, using the
+// log written by the shell hook at path
+func (w *TerminalWatcher) UseCwdLog(path string) {
+	w.cwds = &cwdLog{path: path}
+}
+
 // Start begins watching for terminal events
 func (w *TerminalWatcher) Start() error {
-	// Initialize positions
+	// Start at the end: only commands run from now on are captured
 	for _, path := range w.historyFiles {
 		if info, err := os.Stat(path); err == nil {
-			w.lastPositions[path] = info.Size()
+			w.history[path] = &historyState{
+				pos:  info.Size(),
+				info: info,
+				tail: readTail(path, info.Size()),
+			}
+		}
+	}
+
+	// Skip directories logged before start, like the history itself
+	if w.cwds != nil {
+		if info, err := os.Stat(w.cwds.path); err == nil {
+			w.cwds.pos = info.Size()
 		}
 	}
 
@@ -67,16 +103,38 @@ func (w *TerminalWatcher) watch() {
 }
 
 func (w *TerminalWatcher) checkHistory() {
+	if w.cwds != nil {
+		w.cwds.refresh(time.Now())
+	}
+
 	for _, path := range w.historyFiles {
 		info, err := os.Stat(path)
 		if err != nil {
 			continue
 		}
 
-		lastPos := w.lastPositions[path]
-		currentSize := info.Size()
+		// A history file created after start is read from the beginning
+		st := w.history[path]
+		if st == nil {
+			st = &historyState{}
+			w.history[path] = st
+		}
 
-		if currentSize <= lastPos {
+		// Trimming (HISTSIZE), imports and new shells rewrite or replace
+		// the file, leaving pos pointing past its end or mid-line
+		if st.info != nil {
+			switch {
+			case !os.SameFile(st.info, info):
+				w.resync(path, st, info, "replaced")
+			case info.Size() < st.pos:
+				w.resync(path, st, info, "truncated")
+			case info.Size() > st.pos && !bytes.HasSuffix(readTail(path, st.pos), st.tail):
+				w.resync(path, st, info, "rewritten")
+			}
+		}
+		st.info = info
+
+		if info.Size() <= st.pos {
 			continue
 		}
 
@@ -85,32 +143,86 @@ func (w *TerminalWatcher) checkHistory() {
 		if err != nil {
 			continue
 		}
+		data := make([]byte, info.Size()-st.pos)
+		n, _ := file.ReadAt(data, st.pos)
+		file.Close()
 
-		file.Seek(lastPos, 0)
-		scanner := bufio.NewScanner(file)
-
-		for scanner.Scan() {
-			line := scanner.Text()
-			cmd := w.parseHistoryLine(line, path)
+		// A partly written last entry is left for the next check
+		entries, consumed := parseHistory(path, data[:n])
+		for _, entry := range entries {
+			cmd := strings.TrimSpace(entry.Command)
 			if cmd != "" && w.isInteresting(cmd) {
-				w.emitEvent(cmd)
+				cwd := ""
+				if w.cwds != nil {
+					cwd = w.cwds.lookup(cmd, entry.Time)
+				}
+				w.emitEvent(cmd, cwd, entry.Time)
 			}
 		}
 
-		file.Close()
-		w.lastPositions[path] = currentSize
+		st.pos += int64(consumed)
+		st.tail = appendTail(st.tail, data[:consumed])
 	}
 }
 
-func (w *TerminalWatcher) parseHistoryLine(line, historyFile string) string {
-	// Handle zsh history format: : timestamp:0;command
-	if strings.Contains(historyFile, "zsh") && strings.HasPrefix(line, ":") {
-		parts := strings.SplitN(line, ";", 2)
-		if len(parts) == 2 {
-			return strings.TrimSpace(parts[1])
+// resync finds the read position again in a history file that was
+// replaced or rewritten: just after the last lines read, if they are still
+// there, otherwise at the end, so old history is never emitted again.
+// Trimming drops the oldest lines, so shorter runs of the most recent
+// lines are tried down to the last one.
+func (w *TerminalWatcher) resync(path string, st *historyState, info os.FileInfo, how string) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		lines := bytes.SplitAfter(st.tail, []byte("\n"))
+		for k := 0; k < len(lines); k++ {
+			suffix := bytes.Join(lines[k:], nil)
+			if len(bytes.TrimSpace(suffix)) == 0 {
+				break
+			}
+			if i := bytes.LastIndex(data, suffix); i >= 0 {
+				st.pos = int64(i + len(suffix))
+				st.tail = append([]byte(nil), suffix...)
+				log.Printf("Terminal history %s was %s; resuming after the last command seen", filepath.Base(path), how)
+				return
+			}
 		}
 	}
-	return strings.TrimSpace(line)
+
+	st.pos = info.Size()
+	if err == nil {
+		st.pos = int64(bytes.LastIndexByte(data, '\n') + 1)
+	}
+	st.tail = readTail(path, st.pos)
+	log.Printf("Terminal history %s was %s; skipping its existing contents", filepath.Base(path), how)
+}
+
+// readTail returns up to historyTail bytes of a file ending at pos
+func readTail(path string, pos int64) []byte {
+	start := pos - historyTail
+	if start < 0 {
+		start = 0
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	tail := make([]byte, pos-start)
+	n, _ := file.ReadAt(tail, start)
+	return tail[:n]
+}
+
+// appendTail keeps the last historyTail bytes of tail followed by data
+func appendTail(tail, data []byte) []byte {
+	if len(data) >= historyTail {
+		return append([]byte(nil), data[len(data)-historyTail:]...)
+	}
+	combined := append(append([]byte(nil), tail...), data...)
+	if len(combined) > historyTail {
+		combined = combined[len(combined)-historyTail:]
+	}
+	return combined
 }
 
 func (w *TerminalWatcher) isInteresting(cmd string) bool {
@@ -166,17 +278,25 @@ func (w *TerminalWatcher) isInteresting(cmd string) bool {
 	return false
 }
 
-func (w *TerminalWatcher) emitEvent(cmd string) {
+// emitEvent sends a command event, timestamped with when the command ran
+// if the history recorded it. cwd is empty when it isn't known.
+func (w *TerminalWatcher) emitEvent(cmd, cwd string, ranAt time.Time) {
+	if ranAt.IsZero() {
+		ranAt = time.Now()
+	}
 	event := models.Event{
 		ID:        ulid.Make().String(),
 		Type:      "terminal_cmd",
-		Timestamp: time.Now(),
+		Timestamp: ranAt,
 		Data: map[string]interface{}{
 			"command": cmd,
 		},
 	}
+	if cwd != "" {
+		event.Data["cwd"] = cwd
+	}
 
-	log.Printf("Terminal event: %s", truncate(cmd, 50))
+	log.Printf("Terminal event: %s", textutil.Truncate(cmd, 50))
 
 	select {
 	case w.eventSink <- event:
@@ -184,10 +304,3 @@ func (w *TerminalWatcher) emitEvent(cmd string) {
 		log.Printf("Event queue full, dropping terminal event")
 	}
 }
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
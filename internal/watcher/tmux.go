@@ -0,0 +1,260 @@
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/textutil"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// tmuxLogMaxSize is the size at which a pane log is emptied after reading
+const tmuxLogMaxSize = 1 << 20
+
+// ansiEscape matches terminal control sequences in piped pane output
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-9;?<>=]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[()][0-9A-Za-z]|[@-Z\\-_])`)
+
+// replPrompt recognizes a command typed at an interactive prompt
+type replPrompt struct {
+	name string
+	re   *regexp.Regexp // the command is the last group
+}
+
+// replPrompts are the prompts whose input is captured. Remote shells are
+// included since commands run over ssh never reach local history.
+var replPrompts = []replPrompt{
+	{"python", regexp.MustCompile(`^(>>>) (.+)$`)},
+	{"ipython", regexp.MustCompile(`^(In \[\d+\]:) (.+)$`)},
+	{"irb", regexp.MustCompile(`^(irb\([^)]*\):\d+(?::\d+)?[>*]) (.+)$`)},
+	{"psql", regexp.MustCompile(`^([A-Za-z_][\w-]*=[#>]) (.+)$`)},
+	{"mysql", regexp.MustCompile(`^(mysql>|MariaDB \[[^\]]*\]>) (.+)$`)},
+	{"sqlite", regexp.MustCompile(`^(sqlite>) (.+)$`)},
+	{"redis", regexp.MustCompile(`^([\w.-]+:\d+(?:\[\d+\])?>) (.+)$`)},
+	{"iex", regexp.MustCompile(`^(iex\(\d+\)>) (.+)$`)},
+	{"ghci", regexp.MustCompile(`^(ghci>) (.+)$`)},
+	{"pdb", regexp.MustCompile(`^(\((?:Pdb|gdb)\)) (.+)$`)},
+	{"node", regexp.MustCompile(`^(>) (.+)$`)},
+	{"shell", regexp.MustCompile(`^([\w.-]+@[\w.-]+[^$#]*[$#]) (.+)$`)},
+}
+
+// TmuxWatcher captures commands typed into designated tmux sessions by
+// piping their panes' output to logs (tmux pipe-pane) and picking out
+// prompt lines. This covers REPLs and ssh sessions, whose input never
+// lands in local shell history. Captured commands are redacted.
+type TmuxWatcher struct {
+	eventSink EventSink
+	stopChan  chan struct{}
+	dir       string
+	sessions  []string
+	panes     map[string]*paneLog // by log path
+}
+
+// paneLog tracks how far a pane's log has been read
+type paneLog struct {
+	session string
+	pane    string
+	pos     int64
+}
+
+// NewTmuxWatcher creates a watcher for the named tmux sessions, keeping
+// pane logs in dir
+func NewTmuxWatcher(sessions []string, dir string, sink EventSink) *TmuxWatcher {
+	return &TmuxWatcher{
+		eventSink: sink,
+		stopChan:  make(chan struct{}),
+		dir:       dir,
+		sessions:  sessions,
+		panes:     make(map[string]*paneLog),
+	}
+}
+
+// Start begins piping and watching the sessions' panes
+func (w *TmuxWatcher) Start() error {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return fmt.Errorf("tmux not found: %w", err)
+	}
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return err
+	}
+	w.attachPanes()
+	go w.watch()
+	return nil
+}
+
+// Stop closes the pipes so pane logs stop growing while nothing reads them
+func (w *TmuxWatcher) Stop() {
+	close(w.stopChan)
+	for _, p := range w.panes {
+		exec.Command("tmux", "pipe-pane", "-t", p.pane).Run()
+	}
+}
+
+func (w *TmuxWatcher) watch() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			// Pipe panes opened since the last check, then read all logs
+			w.attachPanes()
+			for path, p := range w.panes {
+				w.readPane(path, p)
+			}
+		}
+	}
+}
+
+// attachPanes pipes every pane of the designated sessions to its log.
+// Sessions that don't exist (yet) are skipped; -o leaves existing pipes.
+func (w *TmuxWatcher) attachPanes() {
+	for _, session := range w.sessions {
+		out, err := exec.Command("tmux", "list-panes", "-s", "-t", session, "-F", "#{pane_id}").Output()
+		if err != nil {
+			continue
+		}
+		for _, pane := range strings.Fields(string(out)) {
+			path := filepath.Join(w.dir, paneLogName(session, pane))
+			if _, ok := w.panes[path]; ok {
+				continue
+			}
+			cmd := "cat >> " + textutil.ShellQuote(path)
+			if err := exec.Command("tmux", "pipe-pane", "-o", "-t", pane, cmd).Run(); err != nil {
+				log.Printf("Warning: could not pipe tmux pane %s: %v", pane, err)
+				continue
+			}
+
+			// Only what is typed from now on is captured
+			p := &paneLog{session: session, pane: pane}
+			if info, err := os.Stat(path); err == nil {
+				p.pos = info.Size()
+			}
+			w.panes[path] = p
+			log.Printf("Capturing tmux pane %s of session %s", pane, session)
+		}
+	}
+}
+
+// readPane emits the commands in complete lines appended to a pane log
+func (w *TmuxWatcher) readPane(path string, p *paneLog) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if info.Size() < p.pos {
+		p.pos = 0
+	}
+	if info.Size() == p.pos {
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	data := make([]byte, info.Size()-p.pos)
+	n, _ := file.ReadAt(data, p.pos)
+	file.Close()
+
+	end := bytes.LastIndexByte(data[:n], '\n')
+	if end < 0 {
+		return
+	}
+	for _, raw := range strings.Split(string(data[:end]), "\n") {
+		if repl, cmd, ok := parsePromptLine(cleanTerminalLine(raw)); ok {
+			w.emitEvent(p, repl, cmd)
+		}
+	}
+	p.pos += int64(end + 1)
+
+	// tmux appends through O_APPEND, so emptying the log is safe
+	if p.pos > tmuxLogMaxSize && os.Truncate(path, 0) == nil {
+		p.pos = 0
+	}
+}
+
+// cleanTerminalLine reduces a line of raw terminal output to the text shown:
+// escape sequences are dropped, carriage returns redraw the line and
+// backspaces erase
+func cleanTerminalLine(raw string) string {
+	raw = ansiEscape.ReplaceAllString(raw, "")
+	if i := strings.LastIndexByte(strings.TrimRight(raw, "\r"), '\r'); i >= 0 {
+		raw = raw[i+1:]
+	}
+	var out []rune
+	for _, r := range raw {
+		switch {
+		case r == '\b' || r == 0x7f:
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case r == '\t':
+			out = append(out, ' ')
+		case r < 0x20:
+		default:
+			out = append(out, r)
+		}
+	}
+	return strings.TrimRight(string(out), " ")
+}
+
+// parsePromptLine returns the REPL and command of a line starting with a
+// known prompt
+func parsePromptLine(line string) (string, string, bool) {
+	for _, p := range replPrompts {
+		m := p.re.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		cmd := strings.TrimSpace(m[len(m)-1])
+		if len(cmd) < 2 {
+			return "", "", false
+		}
+		return p.name, cmd, true
+	}
+	return "", "", false
+}
+
+func (w *TmuxWatcher) emitEvent(p *paneLog, repl, cmd string) {
+	cmd = Redact(cmd)
+	event := models.Event{
+		ID:        ulid.Make().String(),
+		Type:      "tmux_cmd",
+		Timestamp: time.Now(),
+		Data: map[string]interface{}{
+			"command": cmd,
+			"repl":    repl,
+			"session": p.session,
+			"pane":    p.pane,
+		},
+	}
+
+	log.Printf("Tmux event (%s): %s", repl, textutil.Truncate(cmd, 50))
+
+	select {
+	case w.eventSink <- event:
+	default:
+		log.Printf("Event queue full, dropping tmux event")
+	}
+}
+
+// paneLogName names the log of a pane ("%3" in session "work" is work-3.log)
+func paneLogName(session, pane string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '/' || r == os.PathSeparator || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, session)
+	return fmt.Sprintf("%s-%s.log", safe, strings.TrimPrefix(pane, "%"))
+}
@@ -0,0 +1,8 @@
+package watcher
+
+// treeWatcher watches whole directory trees with a single OS-level watch
+// per root, calling back with the path of each changed file
+type treeWatcher interface {
+	Watch(roots []string) error
+	Close()
+}
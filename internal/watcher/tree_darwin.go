@@ -0,0 +1,138 @@
+//go:build darwin && cgo
+
+package watcher
+
+/*
+#cgo LDFLAGS: -framework CoreServices
+#include <stdint.h>
+#include <stdlib.h>
+#include <CoreServices/CoreServices.h>
+
+extern void goFSEventsCallback(uintptr_t handle, size_t n, char **paths, FSEventStreamEventFlags *flags);
+
+static void fseventsCallback(ConstFSEventStreamRef stream, void *info, size_t n, void *paths,
+		const FSEventStreamEventFlags flags[], const FSEventStreamEventId ids[]) {
+	goFSEventsCallback((uintptr_t)info, n, (char **)paths, (FSEventStreamEventFlags *)flags);
+}
+
+static FSEventStreamRef createStream(uintptr_t handle, char **roots, int n, double latency) {
+	CFMutableArrayRef paths = CFArrayCreateMutable(NULL, n, &kCFTypeArrayCallBacks);
+	for (int i = 0; i < n; i++) {
+		CFStringRef path = CFStringCreateWithCString(NULL, roots[i], kCFStringEncodingUTF8);
+		CFArrayAppendValue(paths, path);
+		CFRelease(path);
+	}
+	FSEventStreamContext ctx = {0, (void *)handle, NULL, NULL, NULL};
+	FSEventStreamRef stream = FSEventStreamCreate(NULL, fseventsCallback, &ctx, paths,
+		kFSEventStreamEventIdSinceNow, latency,
+		kFSEventStreamCreateFlagFileEvents | kFSEventStreamCreateFlagNoDefer);
+	CFRelease(paths);
+	return stream;
+}
+
+static dispatch_queue_t createQueue(void) {
+	return dispatch_queue_create("memorypilot.fsevents", DISPATCH_QUEUE_SERIAL);
+}
+
+static int startStream(FSEventStreamRef stream, dispatch_queue_t queue) {
+	FSEventStreamSetDispatchQueue(stream, queue);
+	return FSEventStreamStart(stream) ? 1 : 0;
+}
+
+static void stopStream(FSEventStreamRef stream, dispatch_queue_t queue) {
+	FSEventStreamStop(stream);
+	FSEventStreamInvalidate(stream);
+	FSEventStreamRelease(stream);
+	dispatch_release(queue);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"os"
+	"runtime/cgo"
+	"unsafe"
+)
+
+// fseventsLatency is how long FSEvents coalesces changes, in seconds
+const fseventsLatency = 0.3
+
+// fileChangeFlags are the FSEvents flags that mean a file was written
+const fileChangeFlags = C.kFSEventStreamEventFlagItemCreated |
+	C.kFSEventStreamEventFlagItemModified |
+	C.kFSEventStreamEventFlagItemRenamed
+
+// fsEventsWatcher watches directory trees recursively with FSEvents
+type fsEventsWatcher struct {
+	onChange func(path string)
+	handle   cgo.Handle
+	stream   C.FSEventStreamRef
+	queue    C.dispatch_queue_t
+}
+
+// newTreeWatcher returns an FSEvents watcher. MEMORYPILOT_FILE_BACKEND=fsnotify
+// selects per-directory fsnotify watches instead.
+func newTreeWatcher(onChange func(path string)) treeWatcher {
+	if os.Getenv("MEMORYPILOT_FILE_BACKEND") == "fsnotify" {
+		return nil
+	}
+	return &fsEventsWatcher{onChange: onChange}
+}
+
+func (w *fsEventsWatcher) Watch(roots []string) error {
+	var existing []string
+	for _, root := range roots {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			existing = append(existing, root)
+		}
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	cRoots := C.malloc(C.size_t(len(existing)) * C.size_t(unsafe.Sizeof(uintptr(0))))
+	defer C.free(cRoots)
+	paths := unsafe.Slice((**C.char)(cRoots), len(existing))
+	for i, root := range existing {
+		paths[i] = C.CString(root)
+		defer C.free(unsafe.Pointer(paths[i]))
+	}
+
+	w.handle = cgo.NewHandle(w)
+	w.stream = C.createStream(C.uintptr_t(w.handle), (**C.char)(cRoots), C.int(len(existing)), C.double(fseventsLatency))
+	if w.stream == nil {
+		w.handle.Delete()
+		return errors.New("FSEventStreamCreate failed")
+	}
+	w.queue = C.createQueue()
+	if C.startStream(w.stream, w.queue) == 0 {
+		C.stopStream(w.stream, w.queue)
+		w.stream = nil
+		w.handle.Delete()
+		return errors.New("FSEventStreamStart failed")
+	}
+	return nil
+}
+
+func (w *fsEventsWatcher) Close() {
+	if w.stream == nil {
+		return
+	}
+	C.stopStream(w.stream, w.queue)
+	w.stream = nil
+	w.handle.Delete()
+}
+
+//export goFSEventsCallback
+func goFSEventsCallback(handle C.uintptr_t, n C.size_t, paths **C.char, flags *C.FSEventStreamEventFlags) {
+	w := cgo.Handle(handle).Value().(*fsEventsWatcher)
+	pathList := unsafe.Slice(paths, int(n))
+	flagList := unsafe.Slice(flags, int(n))
+	for i := range pathList {
+		if flagList[i]&C.kFSEventStreamEventFlagItemIsFile == 0 || flagList[i]&fileChangeFlags == 0 {
+			continue
+		}
+		w.onChange(C.GoString(pathList[i]))
+	}
+}
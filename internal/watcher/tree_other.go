@@ -0,0 +1,9 @@
+//go:build !darwin || !cgo
+
+package watcher
+
+// newTreeWatcher returns nil: without FSEvents, directories are watched
+// one by one through fsnotify
+func newTreeWatcher(onChange func(path string)) treeWatcher {
+	return nil
+}
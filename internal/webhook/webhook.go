@@ -0,0 +1,199 @@
+// Package webhook delivers memory lifecycle events to registered URLs.
+//
+// Each request is a POST of a JSON body:
+//
+//	{"id": "42", "event": "memory.created", "createdAt": "...", "memory": {...}}
+//
+// signed with the webhook's secret. The signature header is
+//
+//	X-MemoryPilot-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// so receivers can reject forged and replayed requests (see Verify).
+// Deliveries that fail are retried with exponential backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/privacy"
+	"github.com/memorypilot/memorypilot/internal/store"
+)
+
+// Delivery headers
+const (
+	SignatureHeader = "X-MemoryPilot-Signature"
+	EventHeader     = "X-MemoryPilot-Event"
+	DeliveryHeader  = "X-MemoryPilot-Delivery"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before giving up
+	MaxAttempts = 8
+
+	// firstRetry doubles after each failed attempt: 30s, 1m, 2m ... ~1h
+	firstRetry = 30 * time.Second
+
+	// lease is how long a claimed delivery is held by one sender
+	lease = 2 * time.Minute
+
+	// batchSize bounds the deliveries sent per flush
+	batchSize = 50
+
+	// Tolerance is how far a signature's timestamp may be from now
+	Tolerance = 5 * time.Minute
+)
+
+// Payload is the body of a webhook request
+type Payload struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Memory    json.RawMessage `json:"memory"`
+}
+
+// NewSecret generates a signing secret for a new webhook
+func NewSecret() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Sign returns the signature header value for body sent at t
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify checks a signature header against the body, rejecting
+// signatures more than Tolerance from now
+func Verify(secret, header string, body []byte, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return fmt.Errorf("malformed signature header")
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > Tolerance || d < -Tolerance {
+		return fmt.Errorf("signature timestamp outside tolerance")
+	}
+	if !hmac.Equal([]byte(sig), []byte(mac(secret, ts, body))) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts + "."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Dispatcher sends queued deliveries
+type Dispatcher struct {
+	store  *store.Store
+	client *http.Client
+}
+
+// NewDispatcher creates a dispatcher for the store's delivery queue
+func NewDispatcher(s *store.Store) *Dispatcher {
+	return &Dispatcher{
+		store:  s,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Flush sends every delivery that is due, returning how many succeeded
+// and failed
+func (d *Dispatcher) Flush(ctx context.Context) (sent, failed int, err error) {
+	for ctx.Err() == nil {
+		due, err := d.store.ClaimWebhookDeliveries(time.Now(), lease, batchSize)
+		if err != nil {
+			return sent, failed, err
+		}
+		if len(due) == 0 {
+			break
+		}
+		for _, delivery := range due {
+			sendErr := d.deliver(ctx, delivery)
+			var retryAt time.Time
+			if sendErr == nil {
+				sent++
+			} else {
+				failed++
+				if delivery.Attempts < MaxAttempts {
+					retryAt = time.Now().Add(firstRetry << (delivery.Attempts - 1))
+				} else {
+					log.Printf("Webhook %s: giving up on delivery %d (%s) after %d attempts: %v",
+						delivery.WebhookID, delivery.ID, delivery.Event, delivery.Attempts, sendErr)
+				}
+			}
+			if err := d.store.CompleteWebhookDelivery(delivery.ID, sendErr, retryAt); err != nil {
+				return sent, failed, err
+			}
+		}
+	}
+	return sent, failed, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery store.WebhookDelivery) error {
+	body, err := json.Marshal(Payload{
+		ID:        strconv.FormatInt(delivery.ID, 10),
+		Event:     delivery.Event,
+		CreatedAt: delivery.CreatedAt.UTC(),
+		Memory:    json.RawMessage(delivery.Payload),
+	})
+	if err != nil {
+		return err
+	}
+	return d.Send(ctx, delivery.URL, delivery.Secret, delivery.Event, strconv.FormatInt(delivery.ID, 10), body)
+}
+
+// Send posts a signed body to url, succeeding on any 2xx response
+func (d *Dispatcher) Send(ctx context.Context, url, secret, event, id string, body []byte) error {
+	if err := privacy.CheckURL("webhook", url); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MemoryPilot-Webhooks/1")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, id)
+	req.Header.Set(SignatureHeader, Sign(secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Package fixtures builds models and in-process stores for tests, so code
+// using MemoryPilot can be exercised without touching ~/.memorypilot.
+//
+//	s := fixtures.NewStore(t)
+//	fixtures.Seed(t, s,
+//		fixtures.NewMemory("Use pgx instead of lib/pq").Type(models.MemoryTypeDecision).Topics("postgres").Build(),
+//	)
+package fixtures
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/memorypilot/memorypilot/internal/store"
+	"github.com/memorypilot/memorypilot/pkg/models"
+	"github.com/oklog/ulid/v2"
+)
+
+// Epoch is the default creation time of built models, fixed so results
+// ordered by time are reproducible
+var Epoch = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+// NewStore returns an empty in-memory store that is closed when the test
+// ends
+func NewStore(tb testing.TB) *store.Store {
+	tb.Helper()
+	s, err := store.New(store.MemoryPath)
+	if err != nil {
+		tb.Fatalf("fixtures: open store: %v", err)
+	}
+	tb.Cleanup(func() { s.Close() })
+	return s
+}
+
+// NewTempStore returns an empty store in a temporary directory, for code
+// that needs a real file (backups, recovery, several connections)
+func NewTempStore(tb testing.TB) *store.Store {
+	tb.Helper()
+	s, err := store.New(filepath.Join(tb.TempDir(), "memories.db"))
+	if err != nil {
+		tb.Fatalf("fixtures: open store: %v", err)
+	}
+	tb.Cleanup(func() { s.Close() })
+	return s
+}
+
+// Seed stores projects, memories and events, failing the test on error
+func Seed(tb testing.TB, s *store.Store, items ...interface{}) {
+	tb.Helper()
+	for _, item := range items {
+		var err error
+		switch v := item.(type) {
+		case models.Project:
+			err = s.CreateProject(&v)
+		case models.Memory:
+			err = s.CreateMemory(&v)
+		case models.Event:
+			err = s.CreateEvent(&v)
+		default:
+			tb.Fatalf("fixtures: can't seed %T", item)
+		}
+		if err != nil {
+			tb.Fatalf("fixtures: seed %T: %v", item, err)
+		}
+	}
+}
+
+// NewProject returns a project rooted at path, named after its last element
+func NewProject(path string) models.Project {
+	return models.Project{
+		ID:        ulid.Make().String(),
+		Name:      filepath.Base(path),
+		Path:      path,
+		CreatedAt: Epoch,
+		LastSeen:  Epoch,
+	}
+}
+
+// MemoryBuilder builds a memory with sensible defaults: an approved,
+// personal fact from a manual source, created at Epoch
+type MemoryBuilder struct {
+	m models.Memory
+}
+
+// NewMemory starts building a memory with the given content
+func NewMemory(content string) *MemoryBuilder {
+	return &MemoryBuilder{m: models.Memory{
+		ID:             ulid.Make().String(),
+		Type:           models.MemoryTypeFact,
+		Content:        content,
+		Summary:        content,
+		Scope:          models.MemoryScopePersonal,
+		Source:         models.Source{Type: models.SourceTypeManual, Timestamp: Epoch},
+		Confidence:     0.9,
+		Importance:     0.5,
+		CreatedAt:      Epoch,
+		LastAccessedAt: Epoch,
+		Status:         models.MemoryStatusApproved,
+	}}
+}
+
+// ID sets the memory's ID
+func (b *MemoryBuilder) ID(id string) *MemoryBuilder { b.m.ID = id; return b }
+
+// Type sets the memory type
+func (b *MemoryBuilder) Type(t models.MemoryType) *MemoryBuilder { b.m.Type = t; return b }
+
+// Summary sets the one-line summary
+func (b *MemoryBuilder) Summary(summary string) *MemoryBuilder { b.m.Summary = summary; return b }
+
+// Scope sets the visibility
+func (b *MemoryBuilder) Scope(scope models.MemoryScope) *MemoryBuilder { b.m.Scope = scope; return b }
+
+// Project attaches the memory to a project and scopes it to the project
+func (b *MemoryBuilder) Project(p models.Project) *MemoryBuilder {
+	id := p.ID
+	b.m.ProjectID = &id
+	b.m.Scope = models.MemoryScopeProject
+	return b
+}
+
+// Source sets where the memory came from
+func (b *MemoryBuilder) Source(t models.SourceType, reference string) *MemoryBuilder {
+	b.m.Source.Type, b.m.Source.Reference = t, reference
+	return b
+}
+
+// Topics sets the topics
+func (b *MemoryBuilder) Topics(topics ...string) *MemoryBuilder { b.m.Topics = topics; return b }
+
+// Confidence sets the extraction confidence
+func (b *MemoryBuilder) Confidence(c float64) *MemoryBuilder { b.m.Confidence = c; return b }
+
+// Importance sets the importance
+func (b *MemoryBuilder) Importance(i float64) *MemoryBuilder { b.m.Importance = i; return b }
+
+// Embedding sets the embedding vector
+func (b *MemoryBuilder) Embedding(v []float32) *MemoryBuilder { b.m.Embedding = v; return b }
+
+// Status sets the review status
+func (b *MemoryBuilder) Status(s models.MemoryStatus) *MemoryBuilder { b.m.Status = s; return b }
+
+// CreatedAt sets the creation (and last access) time
+func (b *MemoryBuilder) CreatedAt(t time.Time) *MemoryBuilder {
+	b.m.CreatedAt, b.m.LastAccessedAt, b.m.Source.Timestamp = t, t, t
+	return b
+}
+
+// Build returns the memory
+func (b *MemoryBuilder) Build() models.Memory { return b.m }
+
+// EventBuilder builds a captured event
+type EventBuilder struct {
+	e models.Event
+}
+
+// NewEvent starts building an event of the given type (git_commit,
+// file_change, terminal_cmd, ...) at Epoch
+func NewEvent(eventType string) *EventBuilder {
+	return &EventBuilder{e: models.Event{
+		ID:        ulid.Make().String(),
+		Type:      eventType,
+		Timestamp: Epoch,
+		Data:      map[string]interface{}{},
+	}}
+}
+
+// At sets when the event happened
+func (b *EventBuilder) At(t time.Time) *EventBuilder { b.e.Timestamp = t; return b }
+
+// Project attaches the event to a project
+func (b *EventBuilder) Project(p models.Project) *EventBuilder {
+	id := p.ID
+	b.e.ProjectID = &id
+	return b
+}
+
+// With sets a data field
+func (b *EventBuilder) With(key string, value interface{}) *EventBuilder {
+	b.e.Data[key] = value
+	return b
+}
+
+// Build returns the event
+func (b *EventBuilder) Build() models.Event { return b.e }
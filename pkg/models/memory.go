@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -14,8 +15,19 @@ const (
 	MemoryTypePreference MemoryType = "preference"
 	MemoryTypeMistake    MemoryType = "mistake"
 	MemoryTypeLearning   MemoryType = "learning"
+	MemoryTypeContext    MemoryType = "context" // short-lived working state
 )
 
+// Valid reports whether t is one of the memory types
+func (t MemoryType) Valid() bool {
+	switch t {
+	case MemoryTypeDecision, MemoryTypePattern, MemoryTypeFact, MemoryTypePreference,
+		MemoryTypeMistake, MemoryTypeLearning, MemoryTypeContext:
+		return true
+	}
+	return false
+}
+
 // MemoryScope represents the visibility of a memory
 type MemoryScope string
 
@@ -26,6 +38,16 @@ const (
 	MemoryScopeOrg      MemoryScope = "org"
 )
 
+// MemoryStatus tracks whether a memory has been reviewed
+type MemoryStatus string
+
+const (
+	MemoryStatusApproved MemoryStatus = "approved"
+	MemoryStatusPending  MemoryStatus = "pending" // proposed, awaiting review
+	MemoryStatusRejected MemoryStatus = "rejected"
+	MemoryStatusArchived MemoryStatus = "archived" // expired, kept out of recall
+)
+
 // SourceType represents where a memory came from
 type SourceType string
 
@@ -36,6 +58,7 @@ const (
 	SourceTypeChat     SourceType = "chat"
 	SourceTypeManual   SourceType = "manual"
 	SourceTypeImport   SourceType = "import"
+	SourceTypeDocument SourceType = "document"
 )
 
 // Source tracks where a memory originated
@@ -74,6 +97,122 @@ type Memory struct {
 	LastAccessedAt time.Time  `json:"lastAccessedAt"`
 	AccessCount    int        `json:"accessCount"`
 	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+
+	// Review state; only approved memories are recalled
+	Status MemoryStatus `json:"status,omitempty"`
+
+	// Version counts edits to this copy, starting at 1; an update names the
+	// version it was based on so concurrent edits can't overwrite each other
+	Version int `json:"version,omitempty"`
+
+	// Provider is the LLM provider that extracted the memory, if any
+	Provider string `json:"provider,omitempty"`
+
+	// PromptVersion is the version of the extraction prompts that produced
+	// the memory; 0 if it wasn't extracted or predates versioning
+	PromptVersion int `json:"promptVersion,omitempty"`
+
+	// Ownership: who the memory came from ("Name <email>") and who keeps
+	// it up to date now, which starts out as the author
+	Author     string `json:"author,omitempty"`
+	Maintainer string `json:"maintainer,omitempty"`
+
+	// Sync metadata
+	Clock       VectorClock           `json:"clock,omitempty"`
+	FieldStamps map[string]FieldStamp `json:"fieldStamps,omitempty"`
+
+	// Author signature for team-scoped memories
+	Signature string `json:"signature,omitempty"` // base64 ed25519 signature
+	Signer    string `json:"signer,omitempty"`    // "ssh-ed25519 AAAA..."
+
+	// Score explains how a search ranked the memory; only set on
+	// HybridSearch results
+	Score *SearchScore `json:"score,omitempty"`
+}
+
+// SessionMemory is scratch context an AI tool keeps during one
+// conversation. Session memories are stored apart from durable memories,
+// expire within hours, and are only recalled within their session.
+type SessionMemory struct {
+	ID        string     `json:"id"`
+	SessionID string     `json:"sessionId"`
+	Type      MemoryType `json:"type"`
+	Content   string     `json:"content"`
+	Topics    []string   `json:"topics,omitempty"`
+	Embedding []float32  `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+}
+
+// VectorClock counts edits per device (device ID -> counter)
+type VectorClock map[string]uint64
+
+// FieldStamp records the last write to a single memory field
+type FieldStamp struct {
+	At     time.Time `json:"at"`
+	Device string    `json:"device"`
+}
+
+// Mergeable memory fields, used as keys in Memory.FieldStamps
+const (
+	FieldType       = "type"
+	FieldContent    = "content"
+	FieldSummary    = "summary"
+	FieldImportance = "importance"
+	FieldConfidence = "confidence"
+	FieldTopics     = "topics"
+	FieldExpiresAt  = "expiresAt"
+	FieldMaintainer = "maintainer"
+)
+
+// RecordEdit bumps the memory's vector clock for device and stamps the
+// edited fields, so the edit merges correctly with concurrent remote edits
+func (m *Memory) RecordEdit(device string, fields ...string) {
+	if m.Clock == nil {
+		m.Clock = make(VectorClock)
+	}
+	m.Clock[device]++
+
+	if m.FieldStamps == nil {
+		m.FieldStamps = make(map[string]FieldStamp)
+	}
+	now := time.Now().UTC()
+	for _, field := range fields {
+		m.FieldStamps[field] = FieldStamp{At: now, Device: device}
+	}
+}
+
+// UnionTopics returns a's topics followed by those of b missing from a,
+// ignoring case
+func UnionTopics(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var topics []string
+	for _, t := range append(append([]string{}, a...), b...) {
+		key := strings.ToLower(t)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+// SummaryLength is the longest summary Summarize derives from content
+const SummaryLength = 100
+
+// Summarize derives a summary from content: its whitespace collapsed,
+// cut at SummaryLength without splitting a UTF-8 character
+func Summarize(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if len(content) <= SummaryLength {
+		return content
+	}
+	cut := SummaryLength - 3
+	for cut > 0 && content[cut]&0xC0 == 0x80 {
+		cut--
+	}
+	return content[:cut] + "..."
 }
 
 // Project represents a tracked project/repository
@@ -93,6 +232,34 @@ type Event struct {
 	Timestamp time.Time              `json:"timestamp"`
 	Data      map[string]interface{} `json:"data"`
 	ProjectID *string                `json:"projectId,omitempty"`
+
+	// Repeats counts identical events captured shortly after this one and
+	// folded into it
+	Repeats int `json:"repeats,omitempty"`
+}
+
+// ExtractionRun records one prompt sent to an extraction model and what
+// came back
+type ExtractionRun struct {
+	ID            string        `json:"id"`
+	StartedAt     time.Time     `json:"startedAt"`
+	Latency       time.Duration `json:"latency"`
+	Provider      string        `json:"provider"`
+	Model         string        `json:"model,omitempty"`
+	PromptHash    string        `json:"promptHash"`
+	PromptVersion int           `json:"promptVersion"`
+	EventIDs      []string      `json:"eventIds"`
+	ResponseHash  string        `json:"responseHash,omitempty"`
+	Accepted      []RunMemory   `json:"accepted,omitempty"`
+	Rejected      []RunMemory   `json:"rejected,omitempty"` // below the confidence threshold
+	Error         string        `json:"error,omitempty"`
+}
+
+// RunMemory is a memory proposed in an extraction run
+type RunMemory struct {
+	Type       string  `json:"type"`
+	Summary    string  `json:"summary"`
+	Confidence float64 `json:"confidence"`
 }
 
 // RecallRequest represents a search query
@@ -102,6 +269,12 @@ type RecallRequest struct {
 	ProjectID *string       `json:"projectId,omitempty"`
 	Types     []MemoryType  `json:"types,omitempty"`
 	Limit     int           `json:"limit,omitempty"`
+
+	// Exclusions drop memories mentioning a term, tagged with a topic, or
+	// of a type, e.g. "everything about auth except the OAuth migration"
+	ExcludeTerms  []string     `json:"excludeTerms,omitempty"`
+	ExcludeTopics []string     `json:"excludeTopics,omitempty"`
+	ExcludeTypes  []MemoryType `json:"excludeTypes,omitempty"`
 }
 
 // RecallResponse represents search results
@@ -110,3 +283,19 @@ type RecallResponse struct {
 	Total    int      `json:"total"`
 	Query    string   `json:"query"`
 }
+
+// BatchRecallRequest asks several questions at once. The results share one
+// token budget, and a memory matching several queries is returned once.
+type BatchRecallRequest struct {
+	Queries []RecallRequest `json:"queries"`
+	Budget  int             `json:"budget,omitempty"` // tokens across all results
+}
+
+// BatchRecallResponse holds one result per query, in order
+type BatchRecallResponse struct {
+	Results    []RecallResponse `json:"results"`
+	Tokens     int              `json:"tokens"`
+	Budget     int              `json:"budget"`
+	Duplicates int              `json:"duplicates"` // matches returned for another query instead
+	Dropped    int              `json:"dropped"`    // matches left out to fit the budget
+}
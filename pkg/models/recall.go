@@ -0,0 +1,162 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchWeights tune HybridSearch's ranking. A result's score is the
+// weighted sum of its keyword relevance (BM25, relative to the best keyword
+// match), cosine similarity to the query, importance, and recency, which
+// halves every RecencyHalfLife. Similarity is to the best of a memory's
+// vectors: its content embedding, and its summary and topics vectors
+// scaled by SummaryVector and TopicsVector (0 ignores them).
+type SearchWeights struct {
+	Keyword         float64       `json:"keyword"`
+	Semantic        float64       `json:"semantic"`
+	Importance      float64       `json:"importance"`
+	Recency         float64       `json:"recency"`
+	RecencyHalfLife time.Duration `json:"recencyHalfLife"`
+	SummaryVector   float64       `json:"summaryVector"`
+	TopicsVector    float64       `json:"topicsVector"`
+}
+
+// DefaultSearchWeights returns the built-in ranking
+func DefaultSearchWeights() SearchWeights {
+	return SearchWeights{
+		Keyword:         0.3,
+		Semantic:        0.5,
+		Importance:      0.15,
+		Recency:         0.05,
+		RecencyHalfLife: 90 * 24 * time.Hour,
+		SummaryVector:   1,
+		TopicsVector:    0.8,
+	}
+}
+
+// SearchScore breaks a search result's score into its components, each
+// between 0 and 1 before weighting, for debugging relevance
+type SearchScore struct {
+	Total      float64 `json:"total"`
+	Keyword    float64 `json:"keyword"`
+	Semantic   float64 `json:"semantic"`
+	Importance float64 `json:"importance"`
+	Recency    float64 `json:"recency"`
+	Vector     string  `json:"vector,omitempty"`  // "summary", "topics" or "chunk" when that vector matched best
+	Excerpt    string  `json:"excerpt,omitempty"` // the passage of long content matched, when a chunk matched best
+}
+
+// ParseQuery splits "-term" words out of a search query, so
+// "auth -oauth" searches for "auth" and excludes memories mentioning "oauth"
+func ParseQuery(query string) (string, []string) {
+	var words, exclude []string
+	for _, w := range strings.Fields(query) {
+		if len(w) > 1 && w[0] == '-' {
+			exclude = append(exclude, w[1:])
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), exclude
+}
+
+// Highlight marks the words of a query in text with **, as in Markdown,
+// for showing which part of an excerpt matched. Excluded and short words
+// aren't marked.
+func Highlight(text, query string) string {
+	words, _ := ParseQuery(query)
+	var terms []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(words), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) >= 3 {
+			terms = append(terms, w)
+		}
+	}
+	if len(terms) == 0 {
+		return text
+	}
+
+	// Case folding can change byte lengths, so matches are found rune by rune
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		n := 0
+		for _, t := range terms {
+			tr := []rune(t)
+			if i+len(tr) <= len(runes) && strings.ToLower(string(runes[i:i+len(tr)])) == t && len(tr) > n {
+				n = len(tr)
+			}
+		}
+		if n == 0 {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		b.WriteString("**" + string(runes[i:i+n]) + "**")
+		i += n
+	}
+	return b.String()
+}
+
+// Matches reports whether a memory passes the request's type, scope, project
+// and exclusion filters. Query text is not checked; this is for filtering
+// results that were ranked some other way, such as by vector similarity.
+func (r RecallRequest) Matches(m Memory) bool {
+	if len(r.Types) > 0 && !containsType(r.Types, m.Type) {
+		return false
+	}
+	if len(r.Scope) > 0 {
+		found := false
+		for _, sc := range r.Scope {
+			if sc == m.Scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.ProjectID != nil && m.ProjectID != nil && *m.ProjectID != *r.ProjectID {
+		return false
+	}
+	return !r.Excludes(m)
+}
+
+// Excludes reports whether a memory is ruled out by the request's exclusions
+func (r RecallRequest) Excludes(m Memory) bool {
+	if containsType(r.ExcludeTypes, m.Type) {
+		return true
+	}
+	for _, topic := range r.ExcludeTopics {
+		for _, t := range m.Topics {
+			if strings.EqualFold(t, topic) {
+				return true
+			}
+		}
+	}
+	for _, term := range r.ExcludeTerms {
+		term = strings.ToLower(term)
+		if strings.Contains(strings.ToLower(m.Content), term) ||
+			strings.Contains(strings.ToLower(m.Summary), term) {
+			return true
+		}
+		for _, t := range m.Topics {
+			if strings.Contains(strings.ToLower(t), term) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsType(types []MemoryType, t MemoryType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}